@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/netip"
 	"os"
+	"os/exec"
 	"os/user"
 	"path/filepath"
 	"slices"
@@ -39,11 +40,13 @@ import (
 	"github.com/coder/clistat"
 	"github.com/coder/coder/v2/agent/agentcontainers"
 	"github.com/coder/coder/v2/agent/agentexec"
+	"github.com/coder/coder/v2/agent/agentproc"
 	"github.com/coder/coder/v2/agent/agentscripts"
 	"github.com/coder/coder/v2/agent/agentssh"
 	"github.com/coder/coder/v2/agent/proto"
 	"github.com/coder/coder/v2/agent/proto/resourcesmonitor"
 	"github.com/coder/coder/v2/agent/reconnectingpty"
+	"github.com/coder/coder/v2/agent/sessionrecording"
 	"github.com/coder/coder/v2/buildinfo"
 	"github.com/coder/coder/v2/cli/gitauth"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
@@ -95,8 +98,8 @@ type Options struct {
 }
 
 type Client interface {
-	ConnectRPC24(ctx context.Context) (
-		proto.DRPCAgentClient24, tailnetproto.DRPCTailnetClient24, error,
+	ConnectRPC25(ctx context.Context) (
+		proto.DRPCAgentClient25, tailnetproto.DRPCTailnetClient25, error,
 	)
 	RewriteDERPMap(derpMap *tailcfg.DERPMap)
 }
@@ -180,6 +183,8 @@ func New(options Options) Agent {
 		lifecycleReported:                  make(chan codersdk.WorkspaceAgentLifecycle, 1),
 		lifecycleStates:                    []agentsdk.PostLifecycleRequest{{State: codersdk.WorkspaceAgentLifecycleCreated}},
 		reportConnectionsUpdate:            make(chan struct{}, 1),
+		sessionRecordingsUpdate:            make(chan struct{}, 1),
+		blockedActionsUpdate:               make(chan struct{}, 1),
 		ignorePorts:                        options.IgnorePorts,
 		portCacheDuration:                  options.PortCacheDuration,
 		reportMetadataInterval:             options.ReportMetadataInterval,
@@ -250,6 +255,7 @@ type agent struct {
 	manifest                           atomic.Pointer[agentsdk.Manifest] // manifest is atomic because values can change after reconnection.
 	reportMetadataInterval             time.Duration
 	scriptRunner                       *agentscripts.Runner
+	processAPI                         *agentproc.API
 	announcementBanners                atomic.Pointer[[]codersdk.BannerConfig] // announcementBanners is atomic because it is periodically updated.
 	announcementBannersRefreshInterval time.Duration
 	sessionToken                       atomic.Pointer[string]
@@ -267,6 +273,15 @@ type agent struct {
 	reportConnectionsMu     sync.Mutex
 	reportConnections       []*proto.ReportConnectionRequest
 
+	sessionRecordingsUpdate chan struct{}
+	sessionRecordingsMu     sync.Mutex
+	sessionRecordings       []*proto.UploadSessionRecordingRequest
+	sessionRecordingsSize   int
+
+	blockedActionsUpdate chan struct{}
+	blockedActionsMu     sync.Mutex
+	blockedActions       []*proto.ReportBlockedActionRequest
+
 	logSender *agentsdk.LogSender
 
 	prometheusRegistry *prometheus.Registry
@@ -312,6 +327,9 @@ func (a *agent) init() {
 
 			return a.reportConnection(id, connectionType, ip)
 		},
+		RecordSession:       a.recordSession(proto.Connection_SSH),
+		DLPPolicy:           a.dlpPolicy,
+		ReportBlockedAction: a.reportBlockedAction,
 
 		ExperimentalDevContainersEnabled: a.experimentalDevcontainersEnabled,
 	})
@@ -333,6 +351,18 @@ func (a *agent) init() {
 	// will not report anywhere.
 	a.scriptRunner.RegisterMetrics(a.prometheusRegistry)
 
+	a.processAPI = agentproc.NewAPI(
+		a.logger.Named("processes"),
+		agentproc.WithLogDir(a.logDir),
+		agentproc.WithCommandCreator(func(ctx context.Context, command string, env []string) (*exec.Cmd, error) {
+			cmd, err := a.sshServer.CreateCommand(ctx, command, env, nil)
+			if err != nil {
+				return nil, err
+			}
+			return cmd.AsExec(), nil
+		}),
+	)
+
 	a.reconnectingPTYServer = reconnectingpty.NewServer(
 		a.logger.Named("reconnecting-pty"),
 		a.sshServer,
@@ -343,6 +373,9 @@ func (a *agent) init() {
 		a.reconnectingPTYTimeout,
 		func(s *reconnectingpty.Server) {
 			s.ExperimentalDevcontainersEnabled = a.experimentalDevcontainersEnabled
+			s.RecordSession = a.recordSession(proto.Connection_RECONNECTING_PTY)
+			s.DLPPolicy = a.dlpPolicy
+			s.ReportBlockedAction = a.reportBlockedAction
 		},
 	)
 	go a.runLoop()
@@ -456,7 +489,7 @@ func (t *trySingleflight) Do(key string, fn func()) {
 	fn()
 }
 
-func (a *agent) reportMetadata(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+func (a *agent) reportMetadata(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 	tickerDone := make(chan struct{})
 	collectDone := make(chan struct{})
 	ctx, cancel := context.WithCancel(ctx)
@@ -672,7 +705,7 @@ func (a *agent) reportMetadata(ctx context.Context, aAPI proto.DRPCAgentClient24
 
 // reportLifecycle reports the current lifecycle state once. All state
 // changes are reported in order.
-func (a *agent) reportLifecycle(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+func (a *agent) reportLifecycle(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 	for {
 		select {
 		case <-a.lifecycleUpdate:
@@ -752,7 +785,7 @@ func (a *agent) setLifecycle(state codersdk.WorkspaceAgentLifecycle) {
 }
 
 // reportConnectionsLoop reports connections to the agent for auditing.
-func (a *agent) reportConnectionsLoop(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+func (a *agent) reportConnectionsLoop(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 	for {
 		select {
 		case <-a.reportConnectionsUpdate:
@@ -869,10 +902,180 @@ func (a *agent) reportConnection(id uuid.UUID, connectionType proto.Connection_T
 	}
 }
 
+// dlpPolicy returns the data loss prevention policy of the workspace.
+func (a *agent) dlpPolicy() codersdk.DLPPolicy {
+	manifest := a.manifest.Load()
+	if manifest == nil {
+		return codersdk.DLPPolicy{}
+	}
+	return manifest.DLPPolicy
+}
+
+// reportBlockedActionsLoop reports actions blocked by the DLP policy to
+// coderd for auditing.
+func (a *agent) reportBlockedActionsLoop(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
+	for {
+		select {
+		case <-a.blockedActionsUpdate:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		for {
+			a.blockedActionsMu.Lock()
+			if len(a.blockedActions) == 0 {
+				a.blockedActionsMu.Unlock()
+				break
+			}
+			payload := a.blockedActions[0]
+			// Release lock while we send the payload, this is safe
+			// since we only append to the slice.
+			a.blockedActionsMu.Unlock()
+
+			_, err := aAPI.ReportBlockedAction(ctx, payload)
+			if err != nil {
+				return xerrors.Errorf("failed to report blocked action: %w", err)
+			}
+
+			// Remove the payload we sent.
+			a.blockedActionsMu.Lock()
+			a.blockedActions[0] = nil // Release the pointer from the underlying array.
+			a.blockedActions = a.blockedActions[1:]
+			a.blockedActionsMu.Unlock()
+		}
+	}
+}
+
+// reportBlockedAction queues a report of an action blocked by the DLP
+// policy. id is the connection the action was attempted in, or uuid.Nil.
+func (a *agent) reportBlockedAction(id uuid.UUID, action codersdk.DLPAction, ip, detail string) {
+	typ, err := agentsdk.ProtoFromDLPAction(action)
+	if err != nil {
+		a.logger.Error(a.hardCtx, "convert blocked action", slog.Error(err))
+		return
+	}
+	// Remove the port from the IP because ports are not supported in coderd.
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	var connectionID []byte
+	if id != uuid.Nil {
+		connectionID = id[:]
+	}
+
+	a.blockedActionsMu.Lock()
+	defer a.blockedActionsMu.Unlock()
+	// Blocked actions share the buffer limit of connection reports, they're
+	// about the same size.
+	if len(a.blockedActions) >= reportConnectionBufferLimit {
+		a.logger.Warn(a.hardCtx, "blocked action report buffer limit reached, dropping report",
+			slog.F("limit", reportConnectionBufferLimit),
+			slog.F("connection_id", id),
+			slog.F("action", action),
+		)
+		return
+	}
+	a.blockedActions = append(a.blockedActions, &proto.ReportBlockedActionRequest{
+		BlockedAction: &proto.BlockedAction{
+			Type:         typ,
+			ConnectionId: connectionID,
+			Timestamp:    timestamppb.New(time.Now()),
+			Ip:           ip,
+			Detail:       detail,
+		},
+	})
+	select {
+	case a.blockedActionsUpdate <- struct{}{}:
+	default:
+	}
+}
+
+// uploadSessionRecordingsLoop uploads recorded sessions to coderd.
+func (a *agent) uploadSessionRecordingsLoop(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
+	for {
+		select {
+		case <-a.sessionRecordingsUpdate:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		for {
+			a.sessionRecordingsMu.Lock()
+			if len(a.sessionRecordings) == 0 {
+				a.sessionRecordingsMu.Unlock()
+				break
+			}
+			payload := a.sessionRecordings[0]
+			// Release lock while we send the payload, this is safe
+			// since we only append to the slice.
+			a.sessionRecordingsMu.Unlock()
+
+			_, err := aAPI.UploadSessionRecording(ctx, payload)
+			if err != nil {
+				return xerrors.Errorf("failed to upload session recording: %w", err)
+			}
+
+			// Remove the payload we sent.
+			a.sessionRecordingsMu.Lock()
+			a.sessionRecordings[0] = nil // Release the pointer from the underlying array.
+			a.sessionRecordings = a.sessionRecordings[1:]
+			a.sessionRecordingsSize -= len(payload.Data)
+			a.sessionRecordingsMu.Unlock()
+		}
+	}
+}
+
+// sessionRecordingBufferLimit limits the size of the session recordings we
+// buffer while they are waiting to be uploaded. Chunks that exceed the limit
+// are dropped, which leaves a gap in the recording.
+const sessionRecordingBufferLimit = 64 << 20 // 64 MiB
+
+// recordSession returns a function that starts a recording of a session if
+// the template of the workspace requires it.
+func (a *agent) recordSession(connectionType proto.Connection_Type) func(id uuid.UUID, width, height uint16) *sessionrecording.Recorder {
+	return func(id uuid.UUID, width, height uint16) *sessionrecording.Recorder {
+		manifest := a.manifest.Load()
+		if manifest == nil || !manifest.SessionRecordingEnabled {
+			return nil
+		}
+		return sessionrecording.New(width, height, sessionrecording.Options{
+			Upload: func(chunk sessionrecording.Chunk) {
+				req := &proto.UploadSessionRecordingRequest{
+					Id:         id[:],
+					Type:       connectionType,
+					StartedAt:  timestamppb.New(chunk.StartedAt),
+					ChunkIndex: chunk.Index,
+					Data:       chunk.Data,
+				}
+				if chunk.Final {
+					req.EndedAt = timestamppb.New(chunk.EndedAt)
+				}
+
+				a.sessionRecordingsMu.Lock()
+				defer a.sessionRecordingsMu.Unlock()
+				if a.sessionRecordingsSize+len(chunk.Data) > sessionRecordingBufferLimit {
+					a.logger.Warn(a.hardCtx, "session recording buffer limit reached, dropping chunk",
+						slog.F("limit", sessionRecordingBufferLimit),
+						slog.F("connection_id", id),
+						slog.F("chunk_index", chunk.Index),
+					)
+					return
+				}
+				a.sessionRecordings = append(a.sessionRecordings, req)
+				a.sessionRecordingsSize += len(chunk.Data)
+				select {
+				case a.sessionRecordingsUpdate <- struct{}{}:
+				default:
+				}
+			},
+		})
+	}
+}
+
 // fetchServiceBannerLoop fetches the service banner on an interval.  It will
 // not be fetched immediately; the expectation is that it is primed elsewhere
 // (and must be done before the session actually starts).
-func (a *agent) fetchServiceBannerLoop(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+func (a *agent) fetchServiceBannerLoop(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 	ticker := time.NewTicker(a.announcementBannersRefreshInterval)
 	defer ticker.Stop()
 	for {
@@ -908,7 +1111,7 @@ func (a *agent) run() (retErr error) {
 	a.sessionToken.Store(&sessionToken)
 
 	// ConnectRPC returns the dRPC connection we use for the Agent and Tailnet v2+ APIs
-	aAPI, tAPI, err := a.client.ConnectRPC24(a.hardCtx)
+	aAPI, tAPI, err := a.client.ConnectRPC25(a.hardCtx)
 	if err != nil {
 		return err
 	}
@@ -925,7 +1128,7 @@ func (a *agent) run() (retErr error) {
 	connMan := newAPIConnRoutineManager(a.gracefulCtx, a.hardCtx, a.logger, aAPI, tAPI)
 
 	connMan.startAgentAPI("init notification banners", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+		func(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 			bannersProto, err := aAPI.GetAnnouncementBanners(ctx, &proto.GetAnnouncementBannersRequest{})
 			if err != nil {
 				return xerrors.Errorf("fetch service banner: %w", err)
@@ -942,7 +1145,7 @@ func (a *agent) run() (retErr error) {
 	// sending logs gets gracefulShutdownBehaviorRemain because we want to send logs generated by
 	// shutdown scripts.
 	connMan.startAgentAPI("send logs", gracefulShutdownBehaviorRemain,
-		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+		func(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 			err := a.logSender.SendLoop(ctx, aAPI)
 			if xerrors.Is(err, agentsdk.ErrLogLimitExceeded) {
 				// we don't want this error to tear down the API connection and propagate to the
@@ -961,7 +1164,7 @@ func (a *agent) run() (retErr error) {
 	connMan.startAgentAPI("report metadata", gracefulShutdownBehaviorStop, a.reportMetadata)
 
 	// resources monitor can cease as soon as we start gracefully shutting down.
-	connMan.startAgentAPI("resources monitor", gracefulShutdownBehaviorStop, func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+	connMan.startAgentAPI("resources monitor", gracefulShutdownBehaviorStop, func(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 		logger := a.logger.Named("resources_monitor")
 		clk := quartz.NewReal()
 		config, err := aAPI.GetResourcesMonitoringConfiguration(ctx, &proto.GetResourcesMonitoringConfigurationRequest{})
@@ -986,6 +1189,12 @@ func (a *agent) run() (retErr error) {
 	// gracefulShutdownBehaviorRemain.
 	connMan.startAgentAPI("report connections", gracefulShutdownBehaviorRemain, a.reportConnectionsLoop)
 
+	// Session recordings are part of auditing as well.
+	connMan.startAgentAPI("upload session recordings", gracefulShutdownBehaviorRemain, a.uploadSessionRecordingsLoop)
+
+	// Blocked actions are audited too.
+	connMan.startAgentAPI("report blocked actions", gracefulShutdownBehaviorRemain, a.reportBlockedActionsLoop)
+
 	// channels to sync goroutines below
 	//  handle manifest
 	//       |
@@ -1008,7 +1217,7 @@ func (a *agent) run() (retErr error) {
 	connMan.startAgentAPI("handle manifest", gracefulShutdownBehaviorStop, a.handleManifest(manifestOK))
 
 	connMan.startAgentAPI("app health reporter", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+		func(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 			if err := manifestOK.wait(ctx); err != nil {
 				return xerrors.Errorf("no manifest: %w", err)
 			}
@@ -1023,7 +1232,7 @@ func (a *agent) run() (retErr error) {
 		a.createOrUpdateNetwork(manifestOK, networkOK))
 
 	connMan.startTailnetAPI("coordination", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient24) error {
+		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient25) error {
 			if err := networkOK.wait(ctx); err != nil {
 				return xerrors.Errorf("no network: %w", err)
 			}
@@ -1032,7 +1241,7 @@ func (a *agent) run() (retErr error) {
 	)
 
 	connMan.startTailnetAPI("derp map subscriber", gracefulShutdownBehaviorStop,
-		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient24) error {
+		func(ctx context.Context, tAPI tailnetproto.DRPCTailnetClient25) error {
 			if err := networkOK.wait(ctx); err != nil {
 				return xerrors.Errorf("no network: %w", err)
 			}
@@ -1041,7 +1250,7 @@ func (a *agent) run() (retErr error) {
 
 	connMan.startAgentAPI("fetch service banner loop", gracefulShutdownBehaviorStop, a.fetchServiceBannerLoop)
 
-	connMan.startAgentAPI("stats report loop", gracefulShutdownBehaviorStop, func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+	connMan.startAgentAPI("stats report loop", gracefulShutdownBehaviorStop, func(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 		if err := networkOK.wait(ctx); err != nil {
 			return xerrors.Errorf("no network: %w", err)
 		}
@@ -1052,8 +1261,8 @@ func (a *agent) run() (retErr error) {
 }
 
 // handleManifest returns a function that fetches and processes the manifest
-func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
-	return func(ctx context.Context, aAPI proto.DRPCAgentClient24) error {
+func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
+	return func(ctx context.Context, aAPI proto.DRPCAgentClient25) error {
 		var (
 			sentResult = false
 			err        error
@@ -1123,6 +1332,15 @@ func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context,
 				}
 			}
 
+			// Secrets are written before scripts run so that scripts can
+			// rely on them.
+			for _, file := range manifest.SecretFiles {
+				err := writeSecretFile(a.filesystem, file)
+				if err != nil {
+					a.logger.Warn(ctx, "failed to write secret file", slog.F("path", file.Path), slog.Error(err))
+				}
+			}
+
 			var (
 				scripts          = manifest.Scripts
 				scriptRunnerOpts []agentscripts.InitOption
@@ -1181,8 +1399,8 @@ func (a *agent) handleManifest(manifestOK *checkpoint) func(ctx context.Context,
 
 // createOrUpdateNetwork waits for the manifest to be set using manifestOK, then creates or updates
 // the tailnet using the information in the manifest
-func (a *agent) createOrUpdateNetwork(manifestOK, networkOK *checkpoint) func(context.Context, proto.DRPCAgentClient24) error {
-	return func(ctx context.Context, _ proto.DRPCAgentClient24) (retErr error) {
+func (a *agent) createOrUpdateNetwork(manifestOK, networkOK *checkpoint) func(context.Context, proto.DRPCAgentClient25) error {
+	return func(ctx context.Context, _ proto.DRPCAgentClient25) (retErr error) {
 		if err := manifestOK.wait(ctx); err != nil {
 			return xerrors.Errorf("no manifest: %w", err)
 		}
@@ -1370,6 +1588,7 @@ func (a *agent) createTailnet(
 		Logger:              a.logger.Named("net.tailnet"),
 		ListenPort:          a.tailnetListenPort,
 		BlockEndpoints:      disableDirectConnections,
+		RecordPathHistory:   true,
 	})
 	if err != nil {
 		return nil, xerrors.Errorf("create tailnet: %w", err)
@@ -1509,7 +1728,7 @@ func (a *agent) createTailnet(
 
 // runCoordinator runs a coordinator and returns whether a reconnect
 // should occur.
-func (a *agent) runCoordinator(ctx context.Context, tClient tailnetproto.DRPCTailnetClient24, network *tailnet.Conn) error {
+func (a *agent) runCoordinator(ctx context.Context, tClient tailnetproto.DRPCTailnetClient25, network *tailnet.Conn) error {
 	defer a.logger.Debug(ctx, "disconnected from coordination RPC")
 	// we run the RPC on the hardCtx so that we have a chance to send the disconnect message if we
 	// gracefully shut down.
@@ -1564,7 +1783,7 @@ func (a *agent) setCoordDisconnected() chan struct{} {
 }
 
 // runDERPMapSubscriber runs a coordinator and returns if a reconnect should occur.
-func (a *agent) runDERPMapSubscriber(ctx context.Context, tClient tailnetproto.DRPCTailnetClient24, network *tailnet.Conn) error {
+func (a *agent) runDERPMapSubscriber(ctx context.Context, tClient tailnetproto.DRPCTailnetClient25, network *tailnet.Conn) error {
 	defer a.logger.Debug(ctx, "disconnected from derp map RPC")
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -1808,6 +2027,13 @@ func (a *agent) Close() error {
 		}
 	}
 
+	// Detached processes are stopped along with SSH sessions, before the
+	// shutdown scripts run.
+	err = a.processAPI.Close()
+	if err != nil {
+		a.logger.Error(a.hardCtx, "process api close", slog.Error(err))
+	}
+
 	// wait for SSH to shut down before the general graceful cancel, because
 	// this triggers a disconnect in the tailnet layer, telling all clients to
 	// shut down their wireguard tunnels to us. If SSH sessions are still up,
@@ -1921,6 +2147,25 @@ func expandPathToAbs(path string) (string, error) {
 	return path, nil
 }
 
+// writeSecretFile writes a secret of the workspace owner to a file that only
+// the owner can read.
+func writeSecretFile(fs afero.Fs, file agentsdk.SecretFile) error {
+	path, err := expandPathToAbs(file.Path)
+	if err != nil {
+		return xerrors.Errorf("expand path: %w", err)
+	}
+	err = fs.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return xerrors.Errorf("create directory: %w", err)
+	}
+	err = afero.WriteFile(fs, path, file.Content, 0o600)
+	if err != nil {
+		return xerrors.Errorf("write file: %w", err)
+	}
+	// WriteFile doesn't change the mode of existing files.
+	return fs.Chmod(path, 0o600)
+}
+
 // EnvAgentSubsystem is the environment variable used to denote the
 // specialized environment in which the agent is running
 // (e.g. envbox, envbuilder).
@@ -1948,8 +2193,8 @@ const (
 
 type apiConnRoutineManager struct {
 	logger    slog.Logger
-	aAPI      proto.DRPCAgentClient24
-	tAPI      tailnetproto.DRPCTailnetClient24
+	aAPI      proto.DRPCAgentClient25
+	tAPI      tailnetproto.DRPCTailnetClient25
 	eg        *errgroup.Group
 	stopCtx   context.Context
 	remainCtx context.Context
@@ -1957,7 +2202,7 @@ type apiConnRoutineManager struct {
 
 func newAPIConnRoutineManager(
 	gracefulCtx, hardCtx context.Context, logger slog.Logger,
-	aAPI proto.DRPCAgentClient24, tAPI tailnetproto.DRPCTailnetClient24,
+	aAPI proto.DRPCAgentClient25, tAPI tailnetproto.DRPCTailnetClient25,
 ) *apiConnRoutineManager {
 	// routines that remain in operation during graceful shutdown use the remainCtx.  They'll still
 	// exit if the errgroup hits an error, which usually means a problem with the conn.
@@ -1990,7 +2235,7 @@ func newAPIConnRoutineManager(
 // but for Tailnet.
 func (a *apiConnRoutineManager) startAgentAPI(
 	name string, behavior gracefulShutdownBehavior,
-	f func(context.Context, proto.DRPCAgentClient24) error,
+	f func(context.Context, proto.DRPCAgentClient25) error,
 ) {
 	logger := a.logger.With(slog.F("name", name))
 	var ctx context.Context
@@ -2027,7 +2272,7 @@ func (a *apiConnRoutineManager) startAgentAPI(
 // but for the Agent API.
 func (a *apiConnRoutineManager) startTailnetAPI(
 	name string, behavior gracefulShutdownBehavior,
-	f func(context.Context, tailnetproto.DRPCTailnetClient24) error,
+	f func(context.Context, tailnetproto.DRPCTailnetClient25) error,
 ) {
 	logger := a.logger.With(slog.F("name", name))
 	var ctx context.Context
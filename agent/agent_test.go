@@ -51,6 +51,7 @@ import (
 	"github.com/coder/coder/v2/agent/agentssh"
 	"github.com/coder/coder/v2/agent/agenttest"
 	"github.com/coder/coder/v2/agent/proto"
+	"github.com/coder/coder/v2/agent/sessionrecording"
 	"github.com/coder/coder/v2/agent/usershell"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/agentsdk"
@@ -1158,6 +1159,210 @@ func TestAgent_FileTransferBlocked(t *testing.T) {
 	})
 }
 
+func TestAgent_DLPPolicy(t *testing.T) {
+	t.Parallel()
+
+	requireBlockedAction := func(t *testing.T, agentClient *agenttest.Client, typ proto.BlockedAction_Type) *proto.BlockedAction {
+		t.Helper()
+		var reports []*proto.ReportBlockedActionRequest
+		require.Eventually(t, func() bool {
+			reports = agentClient.GetBlockedActionReports()
+			return len(reports) > 0
+		}, testutil.WaitMedium, testutil.IntervalFast)
+		require.Len(t, reports, 1)
+		action := reports[0].GetBlockedAction()
+		require.Equal(t, typ, action.GetType())
+		require.NotEmpty(t, action.GetIp())
+		return action
+	}
+
+	t.Run("SFTP", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:dogsled
+		conn, agentClient, _, _, _ := setupAgent(t, agentsdk.Manifest{
+			DLPPolicy: codersdk.DLPPolicy{BlockSFTP: true},
+		}, 0)
+		sshClient, err := conn.SSHClient(ctx)
+		require.NoError(t, err)
+		defer sshClient.Close()
+		_, err = sftp.NewClient(sshClient)
+		require.Error(t, err)
+
+		action := requireBlockedAction(t, agentClient, proto.BlockedAction_SFTP)
+		// The attempt is correlated with the connection it was made in.
+		assertConnectionReport(t, agentClient, proto.Connection_SSH, agentssh.BlockedFileTransferErrorCode, "")
+		require.Equal(t, agentClient.GetConnectionReports()[0].GetConnection().GetId(), action.GetConnectionId())
+	})
+
+	t.Run("SFTPAllowed", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:dogsled
+		conn, agentClient, _, _, _ := setupAgent(t, agentsdk.Manifest{
+			DLPPolicy: codersdk.DLPPolicy{BlockSCP: true},
+		}, 0)
+		sshClient, err := conn.SSHClient(ctx)
+		require.NoError(t, err)
+		defer sshClient.Close()
+		client, err := sftp.NewClient(sshClient)
+		require.NoError(t, err)
+		_ = client.Close()
+		require.Empty(t, agentClient.GetBlockedActionReports())
+	})
+
+	t.Run("LocalPortForwarding", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		rl, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer rl.Close()
+
+		//nolint:dogsled
+		conn, agentClient, _, _, _ := setupAgent(t, agentsdk.Manifest{
+			DLPPolicy: codersdk.DLPPolicy{BlockLocalPortForwarding: true},
+		}, 0)
+		sshClient, err := conn.SSHClient(ctx)
+		require.NoError(t, err)
+		defer sshClient.Close()
+		_, err = sshClient.Dial("tcp", rl.Addr().String())
+		require.Error(t, err)
+
+		action := requireBlockedAction(t, agentClient, proto.BlockedAction_LOCAL_PORT_FORWARDING)
+		require.Equal(t, rl.Addr().String(), action.GetDetail())
+		require.Empty(t, action.GetConnectionId())
+	})
+
+	t.Run("FilesAPI", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		path := filepath.Join(t.TempDir(), "file")
+		//nolint:dogsled
+		conn, agentClient, _, _, _ := setupAgent(t, agentsdk.Manifest{
+			DLPPolicy: codersdk.DLPPolicy{BlockSFTP: true},
+		}, 0)
+		err := conn.WriteFile(ctx, workspacesdk.WriteFileRequest{Path: path}, strings.NewReader("hello"))
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusForbidden, sdkErr.StatusCode())
+		require.NoFileExists(t, path)
+
+		action := requireBlockedAction(t, agentClient, proto.BlockedAction_SFTP)
+		require.Equal(t, "file system api write", action.GetDetail())
+	})
+}
+
+func TestAgent_Files(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+
+	events, closer, err := conn.WatchFiles(ctx, workspacesdk.WatchFilesRequest{Path: dir})
+	require.NoError(t, err)
+	defer closer.Close()
+
+	err = conn.WriteFile(ctx, workspacesdk.WriteFileRequest{Path: path, Atomic: true}, strings.NewReader("hello world"))
+	require.NoError(t, err)
+	for {
+		event := testutil.RequireReceive(ctx, t, events)
+		if event.Op == workspacesdk.FileEventOpCreate || event.Op == workspacesdk.FileEventOpRename {
+			if event.Path == path {
+				break
+			}
+		}
+	}
+
+	info, err := conn.StatFile(ctx, workspacesdk.StatFileRequest{Path: path})
+	require.NoError(t, err)
+	require.EqualValues(t, 11, info.Size)
+	require.False(t, info.IsDir)
+
+	r, err := conn.ReadFile(ctx, workspacesdk.ReadFileRequest{Path: path, Offset: 6, Limit: 3})
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	_ = r.Close()
+	require.NoError(t, err)
+	require.Equal(t, "wor", string(got))
+
+	moved := filepath.Join(dir, "sub", "moved")
+	err = conn.MakeDirectory(ctx, workspacesdk.MakeDirectoryRequest{Path: filepath.Dir(moved)})
+	require.NoError(t, err)
+	err = conn.MoveFile(ctx, workspacesdk.MoveFileRequest{Source: path, Destination: moved})
+	require.NoError(t, err)
+	err = conn.DeleteFile(ctx, workspacesdk.DeleteFileRequest{Path: filepath.Dir(moved), Recursive: true})
+	require.NoError(t, err)
+
+	_, err = conn.StatFile(ctx, workspacesdk.StatFileRequest{Path: moved})
+	var sdkErr *codersdk.Error
+	require.ErrorAs(t, err, &sdkErr)
+	require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+}
+
+func TestAgent_Processes(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses sh.")
+	}
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+
+	started, err := conn.StartProcess(ctx, workspacesdk.StartProcessRequest{
+		Command: "echo started; exec sleep 60",
+	})
+	require.NoError(t, err)
+
+	resp, err := conn.ListProcesses(ctx)
+	require.NoError(t, err)
+	idx := slices.IndexFunc(resp.Processes, func(p workspacesdk.Process) bool {
+		return p.PID == started.PID
+	})
+	require.NotEqual(t, -1, idx, "started process is listed")
+	require.Equal(t, uuid.NullUUID{UUID: started.ID, Valid: true}, resp.Processes[idx].SessionID)
+
+	// The output of the process can be read with the file system API.
+	require.Eventually(t, func() bool {
+		r, err := conn.ReadFile(ctx, workspacesdk.ReadFileRequest{Path: started.LogPath})
+		if !assert.NoError(t, err) {
+			return false
+		}
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		return assert.NoError(t, err) && string(got) == "started\n"
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	err = conn.SignalProcess(ctx, started.PID, workspacesdk.SignalProcessRequest{Signal: "KILL"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		resp, err := conn.ListStartedProcesses(ctx)
+		if !assert.NoError(t, err) || !assert.Len(t, resp.Processes, 1) {
+			return false
+		}
+		return resp.Processes[0].ExitedAt != nil
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	// Processes started in SSH sessions know the ID of their session.
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+	session, err := sshClient.NewSession()
+	require.NoError(t, err)
+	defer session.Close()
+	out, err := session.Output("echo $" + agentssh.SessionIDEnvironmentVariable)
+	require.NoError(t, err)
+	_, err = uuid.Parse(strings.TrimSpace(string(out)))
+	require.NoError(t, err)
+}
+
 func TestAgent_EnvironmentVariables(t *testing.T) {
 	t.Parallel()
 	key := "EXAMPLE"
@@ -1720,6 +1925,87 @@ func TestAgent_Lifecycle(t *testing.T) {
 	})
 }
 
+func TestAgent_SecretFiles(t *testing.T) {
+	t.Parallel()
+
+	homeDir, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	//nolint:dogsled
+	_, _, _, fs, _ := setupAgent(t, agentsdk.Manifest{
+		SecretFiles: []agentsdk.SecretFile{
+			{Path: "/etc/secrets/token", Content: []byte("absolute")},
+			{Path: ".config/npm/npmrc", Content: []byte("relative")},
+		},
+	}, 0)
+
+	for path, want := range map[string]string{
+		"/etc/secrets/token":                        "absolute",
+		filepath.Join(homeDir, ".config/npm/npmrc"): "relative",
+	} {
+		require.Eventually(t, func() bool {
+			got, err := afero.ReadFile(fs, path)
+			return err == nil && string(got) == want
+		}, testutil.WaitShort, testutil.IntervalFast)
+		info, err := fs.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+}
+
+func TestAgent_SessionRecording(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	//nolint:dogsled
+	conn, agentClient, _, _, _ := setupAgent(t, agentsdk.Manifest{
+		SessionRecordingEnabled: true,
+	}, 0)
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+
+	session, err := sshClient.NewSession()
+	require.NoError(t, err)
+	defer session.Close()
+	err = session.RequestPty("xterm", 24, 80, ssh.TerminalModes{})
+	require.NoError(t, err)
+	var stdout bytes.Buffer
+	session.Stdout = &stdout
+	err = session.Run("echo recorded")
+	require.NoError(t, err)
+	require.Contains(t, stdout.String(), "recorded")
+
+	var uploads []*proto.UploadSessionRecordingRequest
+	require.Eventually(t, func() bool {
+		uploads = agentClient.GetSessionRecordingUploads()
+		return len(uploads) > 0 && uploads[len(uploads)-1].EndedAt != nil
+	}, testutil.WaitMedium, testutil.IntervalFast)
+
+	var recording bytes.Buffer
+	for i, upload := range uploads {
+		require.Equal(t, proto.Connection_SSH, upload.GetType())
+		require.Equal(t, int32(i), upload.GetChunkIndex())
+		_, _ = recording.Write(upload.GetData())
+	}
+	dec, err := sessionrecording.NewDecoder(&recording)
+	require.NoError(t, err)
+	require.Equal(t, 80, dec.Header().Width)
+	require.Equal(t, 24, dec.Header().Height)
+	var output strings.Builder
+	for {
+		ev, err := dec.Next()
+		if xerrors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if ev.Type == sessionrecording.EventTypeOutput {
+			_, _ = output.WriteString(ev.Data)
+		}
+	}
+	require.Contains(t, output.String(), "recorded")
+}
+
 func TestAgent_Startup(t *testing.T) {
 	t.Parallel()
 
@@ -1783,32 +2069,55 @@ func TestAgent_ReconnectingPTY(t *testing.T) {
 		t.Skip("ConPTY appears to be inconsistent on Windows.")
 	}
 
-	backends := []string{"Buffered", "Screen"}
+	backends := []string{"Buffered", "Screen", "Tmux"}
 
 	_, err := exec.LookPath("screen")
-	hasScreen := err == nil
+	hasScreen := err == nil && runtime.GOOS == "linux"
+	_, err = exec.LookPath("tmux")
+	hasTmux := err == nil && runtime.GOOS == "linux"
 
 	// Make sure UTF-8 works even with LANG set to something like C.
 	t.Setenv("LANG", "C")
 
+	// setPath sets up a PATH that only has the given binaries in it, which
+	// controls the backend that is picked automatically.
+	setPath := func(t *testing.T, binaries ...string) {
+		dir, err := os.MkdirTemp("/tmp", "coder-test-reconnecting-pty-PATH")
+		require.NoError(t, err, "create temp dir for reconnecting pty PATH")
+		for _, binary := range binaries {
+			binaryPath, err := exec.LookPath(binary)
+			require.NoError(t, err)
+			err = os.Symlink(binaryPath, filepath.Join(dir, binary))
+			require.NoError(t, err, "symlink %s into reconnecting pty PATH", binary)
+		}
+		t.Setenv("PATH", dir)
+	}
+
 	for _, backendType := range backends {
 		backendType := backendType
 		t.Run(backendType, func(t *testing.T) {
-			if backendType == "Screen" {
+			switch backendType {
+			case "Screen":
 				if runtime.GOOS != "linux" {
 					t.Skipf("`screen` is not supported on %s", runtime.GOOS)
 				} else if !hasScreen {
 					t.Skip("`screen` not found")
 				}
-			} else if hasScreen && runtime.GOOS == "linux" {
-				// Set up a PATH that does not have screen in it.
-				bashPath, err := exec.LookPath("bash")
-				require.NoError(t, err)
-				dir, err := os.MkdirTemp("/tmp", "coder-test-reconnecting-pty-PATH")
-				require.NoError(t, err, "create temp dir for reconnecting pty PATH")
-				err = os.Symlink(bashPath, filepath.Join(dir, "bash"))
-				require.NoError(t, err, "symlink bash into reconnecting pty PATH")
-				t.Setenv("PATH", dir)
+			case "Tmux":
+				if runtime.GOOS != "linux" {
+					t.Skipf("`tmux` is not supported on %s", runtime.GOOS)
+				} else if !hasTmux {
+					t.Skip("`tmux` not found")
+				}
+				if hasScreen {
+					// Set up a PATH that does not have screen in it.
+					setPath(t, "bash", "tmux")
+				}
+			default:
+				if hasScreen || hasTmux {
+					// Set up a PATH that does not have screen or tmux in it.
+					setPath(t, "bash")
+				}
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
@@ -1923,6 +2232,38 @@ func TestAgent_ReconnectingPTY(t *testing.T) {
 	}
 }
 
+func TestAgent_ReconnectingPTYTmuxScrollback(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS != "linux" {
+		t.Skipf("`tmux` is not supported on %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("`tmux` not found")
+	}
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+	id := uuid.New()
+	withTmux := func(init *workspacesdk.AgentReconnectingPTYInit) {
+		init.BackendType = "tmux"
+	}
+
+	// Print more lines than fit on the screen so the first ones end up in the
+	// history of the pane.  The trailing dot keeps the matcher from matching
+	// partially drawn lines ("line1" while "line122" is being drawn).
+	netConn1, err := conn.ReconnectingPTY(ctx, id, 80, 80, "seq -f line%g. 200; sleep 60", withTmux)
+	require.NoError(t, err)
+	require.NoError(t, testutil.NewTerminalReader(t, netConn1).ReadUntilString(ctx, "line200."))
+	_ = netConn1.Close()
+
+	// Reattaching replays the history before the visible part is redrawn.
+	netConn2, err := conn.ReconnectingPTY(ctx, id, 80, 80, "seq -f line%g. 200; sleep 60", withTmux)
+	require.NoError(t, err)
+	defer netConn2.Close()
+	require.NoError(t, testutil.NewTerminalReader(t, netConn2).ReadUntilString(ctx, "line1."))
+}
+
 // This tests end-to-end functionality of connecting to a running container
 // and executing a command. It creates a real Docker container and runs a
 // command. As such, it does not run by default in CI.
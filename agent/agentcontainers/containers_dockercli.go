@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"os/exec"
 	"os/user"
 	"slices"
 	"sort"
@@ -41,6 +42,7 @@ func NewDocker(execer agentexec.Execer) Lister {
 // information about a container.
 type DockerEnvInfoer struct {
 	usershell.SystemEnvInfo
+	execer    agentexec.Execer
 	container string
 	user      *user.User
 	userShell string
@@ -50,6 +52,7 @@ type DockerEnvInfoer struct {
 // EnvInfo returns information about the environment of a container.
 func EnvInfo(ctx context.Context, execer agentexec.Execer, container, containerUser string) (*DockerEnvInfoer, error) {
 	var dei DockerEnvInfoer
+	dei.execer = execer
 	dei.container = container
 
 	if containerUser == "" {
@@ -137,20 +140,74 @@ func (dei *DockerEnvInfoer) Shell(string) (string, error) {
 }
 
 func (dei *DockerEnvInfoer) ModifyCommand(cmd string, args ...string) (string, []string) {
-	// Wrap the command with `docker exec` and run it as the container user.
-	// There is some additional munging here regarding the container user and environment.
-	dockerArgs := []string{
-		"exec",
-		// The assumption is that this command will be a shell command, so allocate a PTY.
-		"--interactive",
-		"--tty",
+	// The assumption is that this command will be a shell command, so allocate a PTY.
+	return dei.dockerExec(true, cmd, args...)
+}
+
+// sftpServerPaths are the locations of the OpenSSH sftp-server binary on
+// common Linux distributions.
+var sftpServerPaths = []string{
+	"/usr/lib/openssh/sftp-server",     // Debian, Ubuntu
+	"/usr/libexec/openssh/sftp-server", // Fedora, RHEL
+	"/usr/lib/ssh/sftp-server",         // Alpine, Arch
+	"/usr/libexec/sftp-server",
+}
+
+// ErrNoSFTPServer is returned by FindSFTPServer when the container has no
+// sftp-server binary.
+var ErrNoSFTPServer = xerrors.New("no sftp-server binary found in the container")
+
+// FindSFTPServer returns the path of the OpenSSH sftp-server binary in the
+// container, or ErrNoSFTPServer if there is none.
+func (dei *DockerEnvInfoer) FindSFTPServer(ctx context.Context) (string, error) {
+	var script strings.Builder
+	_, _ = script.WriteString("for p in")
+	for _, p := range sftpServerPaths {
+		_, _ = script.WriteString(" " + p)
+	}
+	_, _ = script.WriteString(`; do if [ -x "$p" ]; then echo "$p"; exit 0; fi; done; `)
+	_, _ = script.WriteString(`command -v sftp-server || exit 127`)
+	cmd, args := dei.dockerExec(false, "/bin/sh", "-c", script.String())
+	stdout, stderr, err := run(ctx, dei.execer, cmd, args...)
+	var exitErr *exec.ExitError
+	// The container may not even have a shell, in which case docker exits
+	// with 127 too.
+	if xerrors.As(err, &exitErr) && exitErr.ExitCode() == 127 {
+		return "", xerrors.Errorf("%w, install OpenSSH's sftp-server in the container or add it to the PATH (looked in %s)", ErrNoSFTPServer, strings.Join(sftpServerPaths, ", "))
+	}
+	if err != nil {
+		return "", xerrors.Errorf("find sftp-server: %w: %q", err, stderr)
+	}
+	if stdout == "" {
+		return "", xerrors.Errorf("find sftp-server: empty output")
+	}
+	return stdout, nil
+}
+
+// SFTPServerCommand returns the command that runs the sftp-server binary at
+// path in the container as the container user. The server speaks the SFTP
+// protocol over stdin and stdout, so unlike ModifyCommand no PTY is
+// allocated.
+func (dei *DockerEnvInfoer) SFTPServerCommand(path string) (string, []string) {
+	return dei.dockerExec(false, path)
+}
+
+// dockerExec wraps the given command with `docker exec` and runs it as the
+// container user. There is some additional munging here regarding the
+// container user and environment.
+func (dei *DockerEnvInfoer) dockerExec(tty bool, cmd string, args ...string) (string, []string) {
+	dockerArgs := []string{"exec", "--interactive"}
+	if tty {
+		dockerArgs = append(dockerArgs, "--tty")
+	}
+	dockerArgs = append(dockerArgs,
 		// Run the command as the user in the container.
 		"--user",
 		dei.user.Username,
 		// Set the working directory to the user's home directory as a sane default.
 		"--workdir",
 		dei.user.HomeDir,
-	}
+	)
 
 	// Append the environment variables from the container.
 	for _, e := range dei.env {
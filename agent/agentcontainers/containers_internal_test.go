@@ -2,6 +2,7 @@ package agentcontainers
 
 import (
 	"os"
+	"os/user"
 	"path/filepath"
 	"testing"
 	"time"
@@ -51,6 +52,31 @@ func TestWrapDockerExec(t *testing.T) {
 	}
 }
 
+func TestDockerEnvInfoerSFTPServerCommand(t *testing.T) {
+	t.Parallel()
+
+	dei := &DockerEnvInfoer{
+		container: "my-container",
+		user:      &user.User{Username: "my-user", HomeDir: "/home/my-user"},
+		env:       []string{"FOO=bar"},
+	}
+	cmd, args := dei.SFTPServerCommand("/usr/lib/openssh/sftp-server")
+	require.Equal(t, "docker", cmd)
+	// The SFTP protocol is spoken over stdin and stdout, so no TTY may be
+	// allocated.
+	require.Equal(t, []string{
+		"exec", "--interactive",
+		"--user", "my-user",
+		"--workdir", "/home/my-user",
+		"--env", "FOO=bar",
+		"my-container", "/usr/lib/openssh/sftp-server",
+	}, args)
+
+	// Shell sessions still get a TTY.
+	_, args = dei.ModifyCommand("/bin/bash")
+	require.Contains(t, args, "--tty")
+}
+
 func TestConvertDockerPort(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,416 @@
+// Package agentfiles implements the file system API of the agent, which lets
+// clients read, write and watch files in the workspace over the agent
+// connection.
+package agentfiles
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+)
+
+const (
+	defaultFileMode      os.FileMode = 0o644
+	defaultDirectoryMode os.FileMode = 0o755
+)
+
+// API serves the file system API of the agent.
+type API struct {
+	logger slog.Logger
+	// transferBlocked returns whether reading or writing the contents of
+	// files is blocked for a request.
+	transferBlocked func(r *http.Request) bool
+}
+
+// Option is a functional option for API.
+type Option func(*API)
+
+// WithTransferBlocked sets the function that decides whether reading and
+// writing the contents of files is blocked, for example because file
+// transfers are disabled in the workspace. Describing, moving and deleting
+// files is always allowed.
+func WithTransferBlocked(fn func(r *http.Request) bool) Option {
+	return func(api *API) {
+		api.transferBlocked = fn
+	}
+}
+
+// NewAPI returns a new API with the given options applied.
+func NewAPI(logger slog.Logger, options ...Option) *API {
+	api := &API{
+		logger:          logger,
+		transferBlocked: func(*http.Request) bool { return false },
+	}
+	for _, opt := range options {
+		opt(api)
+	}
+	return api
+}
+
+// Routes returns the HTTP handler for file system routes.
+func (api *API) Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Post("/stat", api.handleStat)
+	r.Get("/read", api.handleRead)
+	r.Put("/write", api.handleWrite)
+	r.Post("/mkdir", api.handleMkdir)
+	r.Post("/delete", api.handleDelete)
+	r.Post("/move", api.handleMove)
+	r.Get("/watch", api.handleWatch)
+	return r
+}
+
+func (*API) handleStat(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req workspacesdk.StatFileRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	path, ok := resolvePath(rw, r, req.Path)
+	if !ok {
+		return
+	}
+	info, err := statFile(path)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, info)
+}
+
+func (api *API) handleRead(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	path, ok := resolvePath(rw, r, query.Get("path"))
+	if !ok {
+		return
+	}
+	offset, ok := parseInt(rw, r, "offset")
+	if !ok {
+		return
+	}
+	limit, ok := parseInt(rw, r, "limit")
+	if !ok {
+		return
+	}
+	if offset < 0 || limit < 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Offset and limit must not be negative.",
+		})
+		return
+	}
+	if api.transferBlocked(r) {
+		writeTransferBlocked(rw, r)
+		return
+	}
+
+	// codeql[go/path-injection] - The intent is to allow the user to read any file in their workspace.
+	f, err := os.Open(path)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+	if stat.IsDir() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("%q is a directory.", path),
+		})
+		return
+	}
+
+	var reader io.Reader = io.NewSectionReader(f, offset, max(stat.Size()-offset, 0))
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit)
+	}
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	rw.WriteHeader(http.StatusOK)
+	_, err = io.Copy(rw, reader)
+	if err != nil {
+		api.logger.Debug(ctx, "copy file to response", slog.F("path", path), slog.Error(err))
+	}
+}
+
+func (api *API) handleWrite(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	path, ok := resolvePath(rw, r, query.Get("path"))
+	if !ok {
+		return
+	}
+	mode, ok := parseInt(rw, r, "mode")
+	if !ok {
+		return
+	}
+	atomic, err := strconv.ParseBool(query.Get("atomic"))
+	if err != nil && query.Get("atomic") != "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid atomic query parameter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if api.transferBlocked(r) {
+		writeTransferBlocked(rw, r)
+		return
+	}
+
+	perm := os.FileMode(mode).Perm()
+	if perm == 0 {
+		perm = defaultFileMode
+	}
+	if atomic {
+		err = writeFileAtomic(path, perm, r.Body)
+	} else {
+		err = writeFile(path, perm, r.Body)
+	}
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (*API) handleMkdir(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req workspacesdk.MakeDirectoryRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	path, ok := resolvePath(rw, r, req.Path)
+	if !ok {
+		return
+	}
+	perm := req.Mode.Perm()
+	if perm == 0 {
+		perm = defaultDirectoryMode
+	}
+	var err error
+	if req.Parents {
+		err = os.MkdirAll(path, perm)
+	} else {
+		err = os.Mkdir(path, perm)
+	}
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (*API) handleDelete(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req workspacesdk.DeleteFileRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	path, ok := resolvePath(rw, r, req.Path)
+	if !ok {
+		return
+	}
+	// os.RemoveAll doesn't fail if the path doesn't exist, but a client
+	// deleting a file that's already gone should know about it.
+	_, err := os.Lstat(path)
+	if err == nil {
+		if req.Recursive {
+			err = os.RemoveAll(path)
+		} else {
+			err = os.Remove(path)
+		}
+	}
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (*API) handleMove(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req workspacesdk.MoveFileRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	source, ok := resolvePath(rw, r, req.Source)
+	if !ok {
+		return
+	}
+	destination, ok := resolvePath(rw, r, req.Destination)
+	if !ok {
+		return
+	}
+	if !req.Overwrite {
+		_, err := os.Lstat(destination)
+		if err == nil {
+			writeError(rw, r, &fs.PathError{Op: "move", Path: destination, Err: fs.ErrExist})
+			return
+		}
+	}
+	err := os.Rename(source, destination)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func statFile(path string) (workspacesdk.FileInfo, error) {
+	stat, err := os.Lstat(path)
+	if err != nil {
+		return workspacesdk.FileInfo{}, err
+	}
+	info := workspacesdk.FileInfo{
+		Name:         stat.Name(),
+		AbsolutePath: path,
+		Size:         stat.Size(),
+		Mode:         stat.Mode(),
+		ModTime:      stat.ModTime(),
+		IsDir:        stat.IsDir(),
+	}
+	if stat.Mode()&os.ModeSymlink != 0 {
+		info.SymlinkTarget, err = os.Readlink(path)
+		if err != nil {
+			return workspacesdk.FileInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+// writeFile writes r to path in place, truncating the file if it exists.
+func writeFile(path string, perm os.FileMode, r io.Reader) error {
+	// codeql[go/path-injection] - The intent is to allow the user to write any file in their workspace.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if err != nil {
+		_ = f.Close()
+		return xerrors.Errorf("write %q: %w", path, err)
+	}
+	return f.Close()
+}
+
+// writeFileAtomic writes r to a temporary file next to path, and renames it
+// over path. Existing files keep their permissions.
+func writeFileAtomic(path string, perm os.FileMode, r io.Reader) (err error) {
+	if stat, err := os.Stat(path); err == nil {
+		if stat.IsDir() {
+			return &fs.PathError{Op: "write", Path: path, Err: errIsDir}
+		}
+		perm = stat.Mode().Perm()
+	}
+
+	dir, name := filepath.Split(path)
+	// The temporary file must be in the same directory for the rename to be
+	// atomic.
+	f, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return xerrors.Errorf("write %q: %w", f.Name(), err)
+	}
+	err = f.Chmod(perm)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if err != nil {
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+var errIsDir = errors.New("is a directory")
+
+// resolvePath returns the absolute path of a path sent by the client.
+// Relative paths are relative to the home directory of the user.
+func resolvePath(rw http.ResponseWriter, r *http.Request, path string) (string, bool) {
+	if path == "" {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: "A path is required.",
+		})
+		return "", false
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), true
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to get user home directory.",
+			Detail:  err.Error(),
+		})
+		return "", false
+	}
+	return filepath.Join(home, path), true
+}
+
+func parseInt(rw http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid %s query parameter.", name),
+			Detail:  err.Error(),
+		})
+		return 0, false
+	}
+	return i, true
+}
+
+func writeTransferBlocked(rw http.ResponseWriter, r *http.Request) {
+	httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+		Message: "File transfers are blocked in this workspace.",
+	})
+}
+
+// writeError writes a file system error with a status code that matches it.
+func writeError(rw http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		status = http.StatusNotFound
+	case errors.Is(err, fs.ErrPermission):
+		status = http.StatusForbidden
+	case errors.Is(err, fs.ErrExist):
+		status = http.StatusConflict
+	case errors.Is(err, errIsDir):
+		status = http.StatusBadRequest
+	}
+	httpapi.Write(r.Context(), rw, status, codersdk.Response{
+		Message: err.Error(),
+	})
+}
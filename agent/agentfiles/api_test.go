@@ -0,0 +1,302 @@
+package agentfiles_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/agent/agentfiles"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/codersdk/wsjson"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestStat(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Symlink(path, link))
+
+	handler := newHandler(t)
+
+	rec := doJSON(t, handler, "/stat", workspacesdk.StatFileRequest{Path: path})
+	require.Equal(t, http.StatusOK, rec.Code)
+	var info workspacesdk.FileInfo
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&info))
+	assert.Equal(t, "file", info.Name)
+	assert.Equal(t, path, info.AbsolutePath)
+	assert.EqualValues(t, 5, info.Size)
+	assert.Equal(t, os.FileMode(0o600), info.Mode)
+	assert.False(t, info.IsDir)
+	assert.Empty(t, info.SymlinkTarget)
+
+	rec = doJSON(t, handler, "/stat", workspacesdk.StatFileRequest{Path: link})
+	require.Equal(t, http.StatusOK, rec.Code)
+	info = workspacesdk.FileInfo{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&info))
+	assert.Equal(t, path, info.SymlinkTarget)
+
+	rec = doJSON(t, handler, "/stat", workspacesdk.StatFileRequest{Path: filepath.Join(dir, "missing")})
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSON(t, handler, "/stat", workspacesdk.StatFileRequest{})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello world"), 0o600))
+
+	handler := newHandler(t)
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   string
+	}{
+		{name: "All", query: "path=" + path, status: http.StatusOK, want: "hello world"},
+		{name: "Offset", query: "path=" + path + "&offset=6", status: http.StatusOK, want: "world"},
+		{name: "Range", query: "path=" + path + "&offset=2&limit=3", status: http.StatusOK, want: "llo"},
+		{name: "PastEnd", query: "path=" + path + "&offset=100", status: http.StatusOK, want: ""},
+		{name: "Negative", query: "path=" + path + "&offset=-1", status: http.StatusBadRequest},
+		{name: "Directory", query: "path=" + dir, status: http.StatusBadRequest},
+		{name: "Missing", query: "path=" + filepath.Join(dir, "missing"), status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/read?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.status, rec.Code, rec.Body.String())
+			if tt.status == http.StatusOK {
+				require.Equal(t, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	for _, atomic := range []bool{false, true} {
+		name := "InPlace"
+		if atomic {
+			name = "Atomic"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file")
+			handler := newHandler(t)
+			write := func(body, mode string) *httptest.ResponseRecorder {
+				query := "path=" + path + "&mode=" + mode
+				if atomic {
+					query += "&atomic=true"
+				}
+				req := httptest.NewRequest(http.MethodPut, "/write?"+query, strings.NewReader(body))
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec
+			}
+
+			rec := write("hello", "384") // 0600
+			require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+			got, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, "hello", string(got))
+			stat, err := os.Stat(path)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+			// Replacing the file keeps its permissions.
+			rec = write("bye", "420") // 0644
+			require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+			got, err = os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, "bye", string(got))
+			stat, err = os.Stat(path)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o600), stat.Mode().Perm())
+
+			// No temporary files are left behind.
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestTransferBlocked(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+	handler := agentfiles.NewAPI(logger, agentfiles.WithTransferBlocked(func(*http.Request) bool {
+		return true
+	})).Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/read?path="+path, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/write?path="+path, strings.NewReader("bye"))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(got))
+
+	// Describing files is still allowed.
+	rec = doJSON(t, handler, "/stat", workspacesdk.StatFileRequest{Path: path})
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestMkdirDeleteMove(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	handler := newHandler(t)
+
+	nested := filepath.Join(dir, "a", "b")
+	rec := doJSON(t, handler, "/mkdir", workspacesdk.MakeDirectoryRequest{Path: nested})
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doJSON(t, handler, "/mkdir", workspacesdk.MakeDirectoryRequest{Path: nested, Parents: true})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.DirExists(t, nested)
+	rec = doJSON(t, handler, "/mkdir", workspacesdk.MakeDirectoryRequest{Path: nested})
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	file := filepath.Join(nested, "file")
+	require.NoError(t, os.WriteFile(file, []byte("hello"), 0o600))
+	other := filepath.Join(dir, "other")
+	require.NoError(t, os.WriteFile(other, []byte("other"), 0o600))
+
+	rec = doJSON(t, handler, "/move", workspacesdk.MoveFileRequest{Source: file, Destination: other})
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = doJSON(t, handler, "/move", workspacesdk.MoveFileRequest{Source: file, Destination: other, Overwrite: true})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.NoFileExists(t, file)
+	got, err := os.ReadFile(other)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(got))
+
+	require.NoError(t, os.WriteFile(file, []byte("hello"), 0o600))
+	root := filepath.Join(dir, "a")
+	rec = doJSON(t, handler, "/delete", workspacesdk.DeleteFileRequest{Path: root})
+	require.Equal(t, http.StatusConflict, rec.Code)
+	rec = doJSON(t, handler, "/delete", workspacesdk.DeleteFileRequest{Path: root, Recursive: true})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.NoDirExists(t, root)
+	rec = doJSON(t, handler, "/delete", workspacesdk.DeleteFileRequest{Path: root, Recursive: true})
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Recursive", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		events := watch(t, dir, true)
+
+		sub := filepath.Join(dir, "sub")
+		require.NoError(t, os.Mkdir(sub, 0o755))
+		requireEvent(t, events, workspacesdk.FileEvent{Path: sub, Op: workspacesdk.FileEventOpCreate})
+
+		// Directories created while watching are watched too.
+		file := filepath.Join(sub, "file")
+		require.NoError(t, os.WriteFile(file, []byte("hello"), 0o600))
+		requireEvent(t, events, workspacesdk.FileEvent{Path: file, Op: workspacesdk.FileEventOpCreate})
+
+		require.NoError(t, os.Remove(file))
+		requireEvent(t, events, workspacesdk.FileEvent{Path: file, Op: workspacesdk.FileEventOpRemove})
+	})
+
+	t.Run("File", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		file := filepath.Join(dir, "file")
+		require.NoError(t, os.WriteFile(file, []byte("hello"), 0o600))
+		events := watch(t, file, false)
+
+		// Changes to other files in the directory aren't sent.
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "other"), []byte("other"), 0o600))
+		require.NoError(t, os.WriteFile(file, []byte("bye"), 0o600))
+		requireEvent(t, events, workspacesdk.FileEvent{Path: file, Op: workspacesdk.FileEventOpWrite})
+	})
+}
+
+func newHandler(t *testing.T) http.Handler {
+	t.Helper()
+	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+	return agentfiles.NewAPI(logger).Routes()
+}
+
+func doJSON(t *testing.T, handler http.Handler, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func watch(t *testing.T, path string, recursive bool) <-chan workspacesdk.FileEvent {
+	t.Helper()
+	ctx := testutil.Context(t, testutil.WaitShort)
+	srv := httptest.NewServer(newHandler(t))
+	t.Cleanup(srv.Close)
+
+	query := "path=" + path
+	if recursive {
+		query += "&recursive=true"
+	}
+	//nolint:bodyclose // The websocket library closes the body.
+	conn, _, err := websocket.Dial(ctx, srv.URL+"/watch?"+query, nil)
+	require.NoError(t, err)
+	decoder := wsjson.NewDecoder[workspacesdk.FileEvent](conn, websocket.MessageText, slogtest.Make(t, nil))
+	t.Cleanup(func() { _ = decoder.Close() })
+	return decoder.Chan()
+}
+
+// requireEvent waits for want, skipping other events. The events fsnotify
+// sends for an operation vary between platforms.
+func requireEvent(t *testing.T, events <-chan workspacesdk.FileEvent, want workspacesdk.FileEvent) {
+	t.Helper()
+	ctx := testutil.Context(t, testutil.WaitShort)
+	for {
+		event := testutil.RequireReceive(ctx, t, events)
+		if event == want {
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package agentfiles
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/coder/websocket"
+	"github.com/fsnotify/fsnotify"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/codersdk/wsjson"
+)
+
+// fileEventOps maps fsnotify operations to the operations sent to clients.
+// An fsnotify event may have more than one operation.
+var fileEventOps = []struct {
+	fsnotify fsnotify.Op
+	op       workspacesdk.FileEventOp
+}{
+	{fsnotify.Create, workspacesdk.FileEventOpCreate},
+	{fsnotify.Write, workspacesdk.FileEventOpWrite},
+	{fsnotify.Remove, workspacesdk.FileEventOpRemove},
+	{fsnotify.Rename, workspacesdk.FileEventOpRename},
+	{fsnotify.Chmod, workspacesdk.FileEventOpChmod},
+}
+
+// handleWatch streams changes to a file or directory over a websocket. It
+// uses inotify on Linux, and the native API of the OS elsewhere.
+func (api *API) handleWatch(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	path, ok := resolvePath(rw, r, query.Get("path"))
+	if !ok {
+		return
+	}
+	recursive, err := strconv.ParseBool(query.Get("recursive"))
+	if err != nil && query.Get("recursive") != "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid recursive query parameter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to create watcher.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer watcher.Close()
+
+	// Files are replaced by renaming over them, which ends a watch on the
+	// file itself, so the directory of the file is watched instead.
+	var onlyPath string
+	switch {
+	case !stat.IsDir():
+		onlyPath = path
+		err = watcher.Add(filepath.Dir(path))
+	case recursive:
+		err = addRecursive(watcher, path)
+	default:
+		err = watcher.Add(path)
+	}
+	if err != nil {
+		writeError(rw, r, err)
+		return
+	}
+
+	conn, err := websocket.Accept(rw, r, nil)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to accept websocket.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go httpapi.HeartbeatClose(ctx, api.logger, cancel, conn)
+
+	encoder := wsjson.NewEncoder[workspacesdk.FileEvent](conn, websocket.MessageText)
+	defer encoder.Close(websocket.StatusNormalClosure)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if onlyPath != "" && event.Name != onlyPath {
+				continue
+			}
+			if recursive && event.Has(fsnotify.Create) {
+				if stat, err := os.Lstat(event.Name); err == nil && stat.IsDir() {
+					err = addRecursive(watcher, event.Name)
+					if err != nil {
+						api.logger.Warn(ctx, "watch created directory", slog.F("path", event.Name), slog.Error(err))
+					}
+				}
+			}
+			for _, op := range fileEventOps {
+				if !event.Has(op.fsnotify) {
+					continue
+				}
+				err = encoder.Encode(workspacesdk.FileEvent{
+					Path: event.Name,
+					Op:   op.op,
+				})
+				if err != nil {
+					return
+				}
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				api.logger.Warn(ctx, "watch files", slog.F("path", path), slog.Error(err))
+				return
+			}
+			err = encoder.Encode(workspacesdk.FileEvent{
+				Path: path,
+				Op:   workspacesdk.FileEventOpOverflow,
+			})
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// addRecursive watches dir and every directory below it.
+func addRecursive(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories can be removed while walking.
+			if errors.Is(err, fs.ErrNotExist) && path != dir {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return watcher.Add(path)
+	})
+}
@@ -0,0 +1,397 @@
+// Package agentproc implements the process API of the agent, which lists and
+// signals processes in the workspace, and starts detached processes.
+package agentproc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shirou/gopsutil/v4/process"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/v2/agent/agentssh"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+)
+
+// defaultMaxExited is how many exited detached processes are kept by default.
+const defaultMaxExited = 100
+
+// CommandCreator creates the command for a detached process. The command is
+// canceled when ctx is.
+type CommandCreator func(ctx context.Context, command string, env []string) (*exec.Cmd, error)
+
+// API serves the process API of the agent.
+type API struct {
+	logger         slog.Logger
+	commandCreator CommandCreator
+	logDir         string
+	maxExited      int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	started []*workspacesdk.StartedProcess
+}
+
+// Option is a functional option for API.
+type Option func(*API)
+
+// WithCommandCreator sets how detached processes are created. By default
+// commands are run with sh.
+func WithCommandCreator(fn CommandCreator) Option {
+	return func(api *API) {
+		api.commandCreator = fn
+	}
+}
+
+// WithLogDir sets the directory the output of detached processes is written
+// to. Defaults to the temporary directory.
+func WithLogDir(dir string) Option {
+	return func(api *API) {
+		api.logDir = dir
+	}
+}
+
+// WithMaxExited sets how many exited detached processes are listed. Once
+// more processes have exited, the oldest ones are forgotten and their logs
+// removed.
+func WithMaxExited(n int) Option {
+	return func(api *API) {
+		api.maxExited = n
+	}
+}
+
+// NewAPI returns a new API with the given options applied.
+func NewAPI(logger slog.Logger, options ...Option) *API {
+	ctx, cancel := context.WithCancel(context.Background())
+	api := &API{
+		logger: logger,
+		commandCreator: func(ctx context.Context, command string, env []string) (*exec.Cmd, error) {
+			cmd := exec.CommandContext(ctx, "sh", "-c", command)
+			cmd.Env = append(os.Environ(), env...)
+			return cmd, nil
+		},
+		logDir:    os.TempDir(),
+		maxExited: defaultMaxExited,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	for _, opt := range options {
+		opt(api)
+	}
+	return api
+}
+
+// Routes returns the HTTP handler for process routes.
+func (api *API) Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", api.handleList)
+	r.Post("/", api.handleStart)
+	r.Get("/started", api.handleListStarted)
+	r.Post("/{pid}/signal", api.handleSignal)
+	return r
+}
+
+// Close stops the detached processes and waits for them to exit.
+func (api *API) Close() error {
+	api.cancel()
+	api.wg.Wait()
+	return nil
+}
+
+func (api *API) handleList(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	processes, err := listProcesses(ctx)
+	if err != nil {
+		api.logger.Error(ctx, "list processes", slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to list processes.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, workspacesdk.ListProcessesResponse{
+		Processes: processes,
+	})
+}
+
+func (api *API) handleStart(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req workspacesdk.StartProcessRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Command) == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "A command is required.",
+		})
+		return
+	}
+	for key := range req.Env {
+		if key == "" || strings.Contains(key, "=") {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Invalid environment variable name %q.", key),
+			})
+			return
+		}
+	}
+
+	started, err := api.start(req)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to start process.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.logger.Info(ctx, "started detached process",
+		slog.F("id", started.ID),
+		slog.F("pid", started.PID),
+		slog.F("log_path", started.LogPath),
+	)
+	httpapi.Write(ctx, rw, http.StatusCreated, started)
+}
+
+func (api *API) handleListStarted(rw http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	processes := make([]workspacesdk.StartedProcess, 0, len(api.started))
+	for _, started := range api.started {
+		processes = append(processes, *started)
+	}
+	api.mu.Unlock()
+	httpapi.Write(r.Context(), rw, http.StatusOK, workspacesdk.ListStartedProcessesResponse{
+		Processes: processes,
+	})
+}
+
+func (api *API) handleSignal(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pid, err := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid process ID.",
+		})
+		return
+	}
+	if int(pid) == os.Getpid() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The agent can't be signaled through its API.",
+		})
+		return
+	}
+	var req workspacesdk.SignalProcessRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	sig, err := parseSignal(req.Signal)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	err = signalProcess(int(pid), sig)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, os.ErrProcessDone):
+			status = http.StatusNotFound
+		case errors.Is(err, os.ErrPermission):
+			status = http.StatusForbidden
+		}
+		httpapi.Write(ctx, rw, status, codersdk.Response{
+			Message: fmt.Sprintf("Failed to signal process %d.", pid),
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.logger.Info(ctx, "signaled process", slog.F("pid", pid), slog.F("signal", sig))
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// start starts a detached process, and waits for it to exit in the
+// background.
+func (api *API) start(req workspacesdk.StartProcessRequest) (*workspacesdk.StartedProcess, error) {
+	id := uuid.New()
+	env := []string{fmt.Sprintf("%s=%s", agentssh.SessionIDEnvironmentVariable, id)}
+	keys := make([]string, 0, len(req.Env))
+	for key := range req.Env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+req.Env[key])
+	}
+
+	cmd, err := api.commandCreator(api.ctx, req.Command, env)
+	if err != nil {
+		return nil, xerrors.Errorf("create command: %w", err)
+	}
+	if req.Directory != "" {
+		cmd.Dir = req.Directory
+	}
+	// The process must not share the session of the agent, or it would get
+	// the signals sent to the agent.
+	cmd.SysProcAttr = cmdSysProcAttr()
+	cmd.Cancel = cmdCancel(cmd)
+	cmd.WaitDelay = 10 * time.Second
+
+	logPath := filepath.Join(api.logDir, fmt.Sprintf("coder-process-%s.log", id))
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, xerrors.Errorf("open log file: %w", err)
+	}
+	// The process gets its own copy of the file, so it's closed here
+	// whether or not the process started.
+	defer logFile.Close()
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.ctx.Err() != nil {
+		return nil, xerrors.New("agent is shutting down")
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, xerrors.Errorf("start command: %w", err)
+	}
+	started := &workspacesdk.StartedProcess{
+		ID:        id,
+		PID:       int32(cmd.Process.Pid), //nolint:gosec // PIDs fit in an int32.
+		Command:   req.Command,
+		LogPath:   logPath,
+		StartedAt: time.Now(),
+	}
+	api.started = append(api.started, started)
+
+	api.wg.Add(1)
+	go func() {
+		defer api.wg.Done()
+		err := cmd.Wait()
+		exitCode := 0
+		if err != nil {
+			exitCode = 255 // Unknown status.
+			var exitError *exec.ExitError
+			if errors.As(err, &exitError) {
+				exitCode = exitError.ExitCode()
+			}
+		}
+		api.logger.Info(api.ctx, "detached process exited",
+			slog.F("id", id),
+			slog.F("exit_code", exitCode),
+			slog.Error(err),
+		)
+		exitedAt := time.Now()
+		api.mu.Lock()
+		started.ExitedAt = &exitedAt
+		started.ExitCode = &exitCode
+		pruned := api.pruneLocked()
+		api.mu.Unlock()
+
+		for _, p := range pruned {
+			err := os.Remove(p.LogPath)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				api.logger.Warn(api.ctx, "remove log of pruned process",
+					slog.F("id", p.ID),
+					slog.F("log_path", p.LogPath),
+					slog.Error(err),
+				)
+			}
+		}
+	}()
+	return started, nil
+}
+
+// pruneLocked forgets the oldest exited processes once there are more than
+// maxExited of them, and returns them so their logs can be removed.
+func (api *API) pruneLocked() []*workspacesdk.StartedProcess {
+	exited := 0
+	for _, started := range api.started {
+		if started.ExitedAt != nil {
+			exited++
+		}
+	}
+	var pruned []*workspacesdk.StartedProcess
+	api.started = slices.DeleteFunc(api.started, func(started *workspacesdk.StartedProcess) bool {
+		if exited <= api.maxExited || started.ExitedAt == nil {
+			return false
+		}
+		exited--
+		pruned = append(pruned, started)
+		return true
+	})
+	return pruned
+}
+
+// listProcesses lists every process the agent can see. Processes can exit
+// while they're listed, and the details of processes owned by other users
+// may not be readable, so errors about a single process are ignored.
+func listProcesses(ctx context.Context) ([]workspacesdk.Process, error) {
+	procs, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	processes := make([]workspacesdk.Process, 0, len(procs))
+	for _, proc := range procs {
+		createTime, err := proc.CreateTimeWithContext(ctx)
+		if err != nil {
+			// The process exited.
+			continue
+		}
+		p := workspacesdk.Process{
+			PID:       proc.Pid,
+			CreatedAt: time.UnixMilli(createTime),
+		}
+		p.PPID, _ = proc.PpidWithContext(ctx)
+		p.User, _ = proc.UsernameWithContext(ctx)
+		p.Name, _ = proc.NameWithContext(ctx)
+		p.Cmdline, _ = proc.CmdlineSliceWithContext(ctx)
+		p.CPUPercent, _ = proc.CPUPercentWithContext(ctx)
+		p.MemoryPercent, _ = proc.MemoryPercentWithContext(ctx)
+		if mem, err := proc.MemoryInfoWithContext(ctx); err == nil {
+			p.MemoryRSS = mem.RSS
+		}
+		if env, err := proc.EnvironWithContext(ctx); err == nil {
+			p.SessionID = sessionID(env)
+		}
+		processes = append(processes, p)
+	}
+	slices.SortFunc(processes, func(a, b workspacesdk.Process) int {
+		return int(a.PID - b.PID)
+	})
+	return processes, nil
+}
+
+func sessionID(env []string) uuid.NullUUID {
+	for _, kv := range env {
+		value, ok := strings.CutPrefix(kv, agentssh.SessionIDEnvironmentVariable+"=")
+		if !ok {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return uuid.NullUUID{}
+		}
+		return uuid.NullUUID{UUID: id, Valid: true}
+	}
+	return uuid.NullUUID{}
+}
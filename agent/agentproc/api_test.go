@@ -0,0 +1,190 @@
+package agentproc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/agent/agentproc"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestList(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses sh.")
+	}
+
+	handler := newHandler(t)
+	started := start(t, handler, workspacesdk.StartProcessRequest{Command: "sleep 30"})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp workspacesdk.ListProcessesResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+
+	var self, child *workspacesdk.Process
+	for i, p := range resp.Processes {
+		switch int(p.PID) {
+		case os.Getpid():
+			self = &resp.Processes[i]
+		case int(started.PID):
+			child = &resp.Processes[i]
+		}
+	}
+	require.NotNil(t, self, "test process is listed")
+	assert.NotEmpty(t, self.Cmdline)
+	assert.NotZero(t, self.MemoryRSS)
+	assert.False(t, self.CreatedAt.IsZero())
+	require.NotNil(t, child, "started process is listed")
+	assert.Equal(t, started.ID, child.SessionID.UUID)
+	assert.True(t, child.SessionID.Valid)
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses sh.")
+	}
+
+	handler := newHandler(t)
+	dir := t.TempDir()
+	started := start(t, handler, workspacesdk.StartProcessRequest{
+		Command:   `echo "$GREETING from $(pwd)"; echo oops >&2; exit 3`,
+		Directory: dir,
+		Env:       map[string]string{"GREETING": "hello"},
+	})
+	require.NotZero(t, started.PID)
+
+	exited := waitExited(t, handler)
+	assert.Equal(t, started.ID, exited.ID)
+	assert.Equal(t, 3, *exited.ExitCode)
+	assert.NotNil(t, exited.ExitedAt)
+
+	out, err := os.ReadFile(started.LogPath)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("hello from %s\noops\n", dir), string(out))
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		rec := doJSON(t, handler, "/", workspacesdk.StartProcessRequest{})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = doJSON(t, handler, "/", workspacesdk.StartProcessRequest{
+			Command: "true",
+			Env:     map[string]string{"A=B": "C"},
+		})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestSignal(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses sh.")
+	}
+
+	handler := newHandler(t)
+	started := start(t, handler, workspacesdk.StartProcessRequest{Command: "sleep 30"})
+	signal := func(pid int, sig string) *httptest.ResponseRecorder {
+		return doJSON(t, handler, fmt.Sprintf("/%d/signal", pid), workspacesdk.SignalProcessRequest{Signal: sig})
+	}
+
+	rec := signal(int(started.PID), "BOGUS")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = signal(os.Getpid(), "TERM")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = signal(int(started.PID), "sigterm")
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	exited := waitExited(t, handler)
+	require.NotNil(t, exited.ExitCode)
+
+	// The process has been reaped, so it no longer exists.
+	rec = signal(int(started.PID), "KILL")
+	require.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestPruneExited(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses sh.")
+	}
+
+	handler := newHandler(t, agentproc.WithMaxExited(1))
+	first := start(t, handler, workspacesdk.StartProcessRequest{Command: "true"})
+	exited := waitExited(t, handler)
+	require.Equal(t, first.ID, exited.ID)
+	require.FileExists(t, first.LogPath)
+
+	// Once the second process exits, the first one is forgotten and its log
+	// removed.
+	second := start(t, handler, workspacesdk.StartProcessRequest{Command: "true"})
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(first.LogPath)
+		return os.IsNotExist(err)
+	}, testutil.WaitShort, testutil.IntervalFast)
+	exited = waitExited(t, handler)
+	require.Equal(t, second.ID, exited.ID)
+	require.FileExists(t, second.LogPath)
+}
+
+func newHandler(t *testing.T, options ...agentproc.Option) http.Handler {
+	t.Helper()
+	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+	options = append([]agentproc.Option{agentproc.WithLogDir(t.TempDir())}, options...)
+	api := agentproc.NewAPI(logger, options...)
+	t.Cleanup(func() { _ = api.Close() })
+	return api.Routes()
+}
+
+func start(t *testing.T, handler http.Handler, req workspacesdk.StartProcessRequest) workspacesdk.StartedProcess {
+	t.Helper()
+	rec := doJSON(t, handler, "/", req)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var started workspacesdk.StartedProcess
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&started))
+	require.Equal(t, req.Command, started.Command)
+	return started
+}
+
+// waitExited waits for the only process started through handler to exit.
+func waitExited(t *testing.T, handler http.Handler) workspacesdk.StartedProcess {
+	t.Helper()
+	var exited workspacesdk.StartedProcess
+	require.Eventually(t, func() bool {
+		req := httptest.NewRequest(http.MethodGet, "/started", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var resp workspacesdk.ListStartedProcessesResponse
+		if !assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp)) || !assert.Len(t, resp.Processes, 1) {
+			return false
+		}
+		exited = resp.Processes[0]
+		return exited.ExitCode != nil
+	}, testutil.WaitShort, testutil.IntervalFast)
+	return exited
+}
+
+func doJSON(t *testing.T, handler http.Handler, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package agentproc
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/xerrors"
+)
+
+// parseSignal parses a signal name like "TERM" or "SIGTERM".
+func parseSignal(name string) (syscall.Signal, error) {
+	name = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+	sig := unix.SignalNum("SIG" + name)
+	if sig == 0 {
+		return 0, xerrors.Errorf("unknown signal %q", name)
+	}
+	return sig, nil
+}
+
+func signalProcess(pid int, sig syscall.Signal) error {
+	err := unix.Kill(pid, sig)
+	if errors.Is(err, unix.ESRCH) {
+		return xerrors.Errorf("process %d: %w", pid, os.ErrProcessDone)
+	}
+	return err
+}
+
+func cmdSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setsid: true,
+	}
+}
+
+// cmdCancel hangs up the process group of the process, so that the children
+// of the shell exit too.
+func cmdCancel(cmd *exec.Cmd) func() error {
+	return func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGHUP)
+	}
+}
@@ -0,0 +1,39 @@
+package agentproc
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"golang.org/x/xerrors"
+)
+
+// parseSignal parses a signal name like "KILL" or "SIGKILL". Processes can
+// only be killed on Windows.
+func parseSignal(name string) (syscall.Signal, error) {
+	name = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+	if name != "KILL" {
+		return 0, xerrors.Errorf("signal %q is not supported on Windows", name)
+	}
+	return syscall.SIGKILL, nil
+}
+
+func signalProcess(pid int, _ syscall.Signal) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return xerrors.Errorf("process %d: %w", pid, os.ErrProcessDone)
+	}
+	defer p.Release()
+	return p.Kill()
+}
+
+func cmdSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{}
+}
+
+func cmdCancel(cmd *exec.Cmd) func() error {
+	return func() error {
+		return cmd.Process.Kill()
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/coder/coder/v2/agent/agentcontainers"
 	"github.com/coder/coder/v2/agent/agentexec"
 	"github.com/coder/coder/v2/agent/agentrsa"
+	"github.com/coder/coder/v2/agent/sessionrecording"
 	"github.com/coder/coder/v2/agent/usershell"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/pty"
@@ -69,6 +70,10 @@ const (
 	// an SSH connection.
 	// Only available if CODER_AGENT_DEVCONTAINERS_ENABLE=true.
 	ContainerUserEnvironmentVariable = "CODER_CONTAINER_USER"
+	// SessionIDEnvironmentVariable is set to the ID of the session that
+	// started a process. It's inherited by child processes, and used to find
+	// the session that owns a process.
+	SessionIDEnvironmentVariable = "CODER_SESSION_ID"
 )
 
 // MagicSessionType enums.
@@ -113,6 +118,15 @@ type Config struct {
 	BlockFileTransfer bool
 	// ReportConnection.
 	ReportConnection reportConnectionFunc
+	// RecordSession returns a recorder for the PTY session with the given
+	// ID, or nil if the session should not be recorded.
+	RecordSession func(id uuid.UUID, width, height uint16) *sessionrecording.Recorder
+	// DLPPolicy returns the data loss prevention policy to enforce. Unlike
+	// BlockFileTransfer, it can block individual kinds of transfers, and
+	// blocked attempts are reported with ReportBlockedAction.
+	DLPPolicy func() codersdk.DLPPolicy
+	// ReportBlockedAction is called when the DLP policy blocks an action.
+	ReportBlockedAction reportBlockedActionFunc
 	// Experimental: allow connecting to running containers if
 	// CODER_AGENT_DEVCONTAINERS_ENABLE=true.
 	ExperimentalDevContainersEnabled bool
@@ -171,6 +185,15 @@ func NewServer(ctx context.Context, logger slog.Logger, prometheusRegistry *prom
 	if config.ReportConnection == nil {
 		config.ReportConnection = func(uuid.UUID, MagicSessionType, string) func(int, string) { return func(int, string) {} }
 	}
+	if config.RecordSession == nil {
+		config.RecordSession = func(uuid.UUID, uint16, uint16) *sessionrecording.Recorder { return nil }
+	}
+	if config.DLPPolicy == nil {
+		config.DLPPolicy = func() codersdk.DLPPolicy { return codersdk.DLPPolicy{} }
+	}
+	if config.ReportBlockedAction == nil {
+		config.ReportBlockedAction = func(uuid.UUID, codersdk.DLPAction, string, string) {}
+	}
 
 	forwardHandler := &ssh.ForwardedTCPHandler{}
 	unixForwardHandler := newForwardedUnixHandler(logger)
@@ -196,7 +219,7 @@ func NewServer(ctx context.Context, logger slog.Logger, prometheusRegistry *prom
 				wrapped := NewJetbrainsChannelWatcher(ctx, s.logger, s.config.ReportConnection, newChan, &s.connCountJetBrains)
 				ssh.DirectTCPIPHandler(srv, conn, wrapped, ctx)
 			},
-			"direct-streamlocal@openssh.com": directStreamLocalHandler,
+			"direct-streamlocal@openssh.com": s.dlpDirectStreamLocalHandler,
 			"session":                        ssh.DefaultSessionHandler,
 		},
 		ConnectionFailedCallback: func(conn net.Conn, err error) {
@@ -217,26 +240,26 @@ func NewServer(ctx context.Context, logger slog.Logger, prometheusRegistry *prom
 		// be set before we start listening.
 		HostSigners: []ssh.Signer{},
 		LocalPortForwardingCallback: func(ctx ssh.Context, destinationHost string, destinationPort uint32) bool {
-			// Allow local port forwarding all!
 			s.logger.Debug(ctx, "local port forward",
 				slog.F("destination_host", destinationHost),
 				slog.F("destination_port", destinationPort))
-			return true
+			// Allow local port forwarding all, unless the DLP policy blocks it!
+			return !s.dlpBlocked(ctx, uuid.Nil, codersdk.DLPActionLocalPortForwarding, ctx.RemoteAddr(), hostPort(destinationHost, destinationPort))
 		},
 		PtyCallback: func(_ ssh.Context, _ ssh.Pty) bool {
 			return true
 		},
 		ReversePortForwardingCallback: func(ctx ssh.Context, bindHost string, bindPort uint32) bool {
-			// Allow reverse port forwarding all!
 			s.logger.Debug(ctx, "reverse port forward",
 				slog.F("bind_host", bindHost),
 				slog.F("bind_port", bindPort))
-			return true
+			// Allow reverse port forwarding all, unless the DLP policy blocks it!
+			return !s.dlpBlocked(ctx, uuid.Nil, codersdk.DLPActionRemotePortForwarding, ctx.RemoteAddr(), hostPort(bindHost, bindPort))
 		},
 		RequestHandlers: map[string]ssh.RequestHandler{
 			"tcpip-forward":                          forwardHandler.HandleSSHRequest,
 			"cancel-tcpip-forward":                   forwardHandler.HandleSSHRequest,
-			"streamlocal-forward@openssh.com":        unixForwardHandler.HandleSSHRequest,
+			"streamlocal-forward@openssh.com":        s.dlpStreamLocalForwardHandler(unixForwardHandler.HandleSSHRequest),
 			"cancel-streamlocal-forward@openssh.com": unixForwardHandler.HandleSSHRequest,
 		},
 		X11Callback: s.x11Callback,
@@ -413,14 +436,13 @@ func (s *Server) sessionHandler(session ssh.Session) {
 
 	if s.fileTransferBlocked(session) {
 		s.logger.Warn(ctx, "file transfer blocked", slog.F("session_subsystem", session.Subsystem()), slog.F("raw_command", session.RawCommand()))
-
-		if session.Subsystem() == "" { // sftp does not expect error, otherwise it fails with "package too long"
-			// Response format: <status_code><message body>\n
-			errorMessage := fmt.Sprintf("\x02%s\n", BlockedFileTransferErrorMessage)
-			_, _ = session.Write([]byte(errorMessage))
-		}
 		closeCause("file transfer blocked")
-		_ = session.Exit(BlockedFileTransferErrorCode)
+		exitFileTransferBlocked(session)
+		return
+	}
+	if action, detail, ok := sessionDLPAction(session); ok && s.dlpBlocked(ctx, id, action, session.RemoteAddr(), detail) {
+		closeCause(fmt.Sprintf("%s %s", action, BlockedByPolicyMessage))
+		exitFileTransferBlocked(session)
 		return
 	}
 
@@ -435,12 +457,12 @@ func (s *Server) sessionHandler(session ssh.Session) {
 	switch ss := session.Subsystem(); ss {
 	case "":
 	case "sftp":
+		var err error
 		if s.config.ExperimentalDevContainersEnabled && container != "" {
-			closeCause("sftp not yet supported with containers")
-			_ = session.Exit(1)
-			return
+			err = s.containerSFTPHandler(logger, session, container, containerUser)
+		} else {
+			err = s.sftpHandler(logger, session)
 		}
-		err := s.sftpHandler(logger, session)
 		if err != nil {
 			closeCause(err.Error())
 		}
@@ -464,7 +486,7 @@ func (s *Server) sessionHandler(session ssh.Session) {
 		env = append(env, fmt.Sprintf("DISPLAY=localhost:%d.%d", display, x11.ScreenNumber))
 	}
 
-	err := s.sessionStart(logger, session, env, magicType, container, containerUser)
+	err := s.sessionStart(logger, session, id, env, magicType, container, containerUser)
 	var exitError *exec.ExitError
 	if xerrors.As(err, &exitError) {
 		code := exitError.ExitCode()
@@ -506,6 +528,17 @@ func (s *Server) sessionHandler(session ssh.Session) {
 	_ = session.Exit(0)
 }
 
+// exitFileTransferBlocked tells the client that its file transfer was blocked
+// and ends the session.
+func exitFileTransferBlocked(session ssh.Session) {
+	if session.Subsystem() == "" { // sftp does not expect error, otherwise it fails with "package too long"
+		// Response format: <status_code><message body>\n
+		errorMessage := fmt.Sprintf("\x02%s\n", BlockedFileTransferErrorMessage)
+		_, _ = session.Write([]byte(errorMessage))
+	}
+	_ = session.Exit(BlockedFileTransferErrorCode)
+}
+
 // fileTransferBlocked method checks if the file transfer commands should be blocked.
 //
 // Warning: consider this mechanism as "Do not trespass" sign, as a violator can still ssh to the host,
@@ -537,7 +570,7 @@ func (s *Server) fileTransferBlocked(session ssh.Session) bool {
 	return false
 }
 
-func (s *Server) sessionStart(logger slog.Logger, session ssh.Session, env []string, magicType MagicSessionType, container, containerUser string) (retErr error) {
+func (s *Server) sessionStart(logger slog.Logger, session ssh.Session, id uuid.UUID, env []string, magicType MagicSessionType, container, containerUser string) (retErr error) {
 	ctx := session.Context()
 
 	magicTypeLabel := magicTypeMetricLabel(magicType)
@@ -556,6 +589,7 @@ func (s *Server) sessionStart(logger slog.Logger, session ssh.Session, env []str
 			return err
 		}
 	}
+	env = append(env, fmt.Sprintf("%s=%s", SessionIDEnvironmentVariable, id))
 	cmd, err := s.CreateCommand(ctx, session.RawCommand(), env, ei)
 	if err != nil {
 		s.metrics.sessionErrors.WithLabelValues(magicTypeLabel, ptyLabel, "create_command").Add(1)
@@ -574,7 +608,7 @@ func (s *Server) sessionStart(logger slog.Logger, session ssh.Session, env []str
 	}
 
 	if isPty {
-		return s.startPTYSession(logger, session, magicTypeLabel, cmd, sshPty, windowSize)
+		return s.startPTYSession(logger, session, id, magicTypeLabel, cmd, sshPty, windowSize)
 	}
 	return s.startNonPTYSession(logger, session, magicTypeLabel, cmd.AsExec())
 }
@@ -633,7 +667,7 @@ type ptySession interface {
 	Signals(chan<- ssh.Signal)
 }
 
-func (s *Server) startPTYSession(logger slog.Logger, session ptySession, magicTypeLabel string, cmd *pty.Cmd, sshPty ssh.Pty, windowSize <-chan ssh.Window) (retErr error) {
+func (s *Server) startPTYSession(logger slog.Logger, session ptySession, id uuid.UUID, magicTypeLabel string, cmd *pty.Cmd, sshPty ssh.Pty, windowSize <-chan ssh.Window) (retErr error) {
 	s.metrics.sessionsTotal.WithLabelValues(magicTypeLabel, "yes").Add(1)
 
 	ctx := session.Context()
@@ -684,6 +718,15 @@ func (s *Server) startPTYSession(logger slog.Logger, session ptySession, magicTy
 			}
 		}
 	}()
+
+	var output io.Writer = session
+	// #nosec G115 - Safe conversions for terminal dimensions which are expected to be within uint16 range
+	recorder := s.config.RecordSession(id, uint16(sshPty.Window.Width), uint16(sshPty.Window.Height))
+	if recorder != nil {
+		defer recorder.Close()
+		output = io.MultiWriter(session, recorder)
+	}
+
 	sigs := make(chan ssh.Signal, 1)
 	session.Signals(sigs)
 	defer func() {
@@ -710,6 +753,10 @@ func (s *Server) startPTYSession(logger slog.Logger, session ptySession, magicTy
 				}
 				// #nosec G115 - Safe conversions for terminal dimensions which are expected to be within uint16 range
 				resizeErr := ptty.Resize(uint16(win.Height), uint16(win.Width))
+				if recorder != nil {
+					// #nosec G115 - Safe conversions for terminal dimensions which are expected to be within uint16 range
+					recorder.Resize(uint16(win.Width), uint16(win.Height))
+				}
 				// If the pty is closed, then command has exited, no need to log.
 				if resizeErr != nil && !errors.Is(resizeErr, pty.ErrClosed) {
 					logger.Warn(ctx, "failed to resize tty", slog.Error(resizeErr))
@@ -734,7 +781,7 @@ func (s *Server) startPTYSession(logger slog.Logger, session ptySession, magicTy
 	//    after we've Read() all the buffered data from the PTY.
 	// 2. The client hangs up, which cancels the command's Context, and go will
 	//    kill the command's process.  This then has the same effect as (1).
-	n, err := io.Copy(session, ptty.OutputReader())
+	n, err := io.Copy(output, ptty.OutputReader())
 	logger.Debug(ctx, "copy output done", slog.F("bytes", n), slog.Error(err))
 	if err != nil {
 		s.metrics.sessionErrors.WithLabelValues(magicTypeLabel, "yes", "output_io_copy").Add(1)
@@ -816,6 +863,74 @@ func (s *Server) sftpHandler(logger slog.Logger, session ssh.Session) error {
 	return xerrors.Errorf("sftp server closed with error: %w", err)
 }
 
+// containerSFTPHandler serves SFTP for a container by running the container's
+// own sftp-server, so that paths and file ownership are those of the
+// container and not of the agent. The session fails with a descriptive error
+// if the container has no sftp-server.
+func (s *Server) containerSFTPHandler(logger slog.Logger, session ssh.Session, container, containerUser string) error {
+	s.metrics.sftpConnectionsTotal.Add(1)
+
+	ctx := session.Context()
+
+	// See sftpHandler.
+	session.DisablePTYEmulation()
+
+	ei, err := agentcontainers.EnvInfo(ctx, s.Execer, container, containerUser)
+	if err != nil {
+		logger.Warn(ctx, "get container env info for sftp failed", slog.Error(err))
+		s.metrics.sftpServerErrors.Add(1)
+		_ = session.Exit(1)
+		return xerrors.Errorf("get container env info: %w", err)
+	}
+
+	sftpServer, err := ei.FindSFTPServer(ctx)
+	if err != nil {
+		logger.Warn(ctx, "find container sftp server failed", slog.Error(err))
+		s.metrics.sftpServerErrors.Add(1)
+		// SFTP clients don't report why a subsystem failed, but some show
+		// its stderr.
+		_, _ = fmt.Fprintf(session.Stderr(), "Unable to start SFTP: %s.\n", err)
+		code := 1
+		if xerrors.Is(err, agentcontainers.ErrNoSFTPServer) {
+			code = 127
+		}
+		_ = session.Exit(code)
+		return xerrors.Errorf("find sftp server: %w", err)
+	}
+
+	name, args := ei.SFTPServerCommand(sftpServer)
+	cmd := s.Execer.CommandContext(ctx, name, args...)
+	cmd.Stdout = session
+	cmd.Stderr = session.Stderr()
+	// Use a pipe for stdin so that Wait doesn't block on reading from the
+	// session after the server exits.
+	stdinPipe, err := cmd.StdinPipe()
+	if err != nil {
+		s.metrics.sftpServerErrors.Add(1)
+		_ = session.Exit(1)
+		return xerrors.Errorf("create stdin pipe: %w", err)
+	}
+	go func() {
+		_, _ = io.Copy(stdinPipe, session)
+		_ = stdinPipe.Close()
+	}()
+
+	err = cmd.Run()
+	if err == nil {
+		_ = session.Exit(0)
+		return nil
+	}
+	logger.Warn(ctx, "container sftp server closed with error", slog.Error(err))
+	s.metrics.sftpServerErrors.Add(1)
+	code := 1
+	var exitErr *exec.ExitError
+	if xerrors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		code = exitErr.ExitCode()
+	}
+	_ = session.Exit(code)
+	return xerrors.Errorf("container sftp server closed with error: %w", err)
+}
+
 // CreateCommand processes raw command input with OpenSSH-like behavior.
 // If the script provided is empty, it will default to the users shell.
 // This injects environment variables specified by the user at launch too.
@@ -7,14 +7,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net"
+	"os"
+	"os/exec"
 	"os/user"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/pkg/sftp"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -25,13 +30,34 @@ import (
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
 
+	"github.com/coder/coder/v2/agent/agentcontainers"
 	"github.com/coder/coder/v2/agent/agentexec"
 	"github.com/coder/coder/v2/agent/agentssh"
+	"github.com/coder/coder/v2/pty"
 	"github.com/coder/coder/v2/pty/ptytest"
 	"github.com/coder/coder/v2/testutil"
 )
 
+// fakeSFTPServerEnv makes the test binary act as the sftp-server of a fake
+// container, see fakeContainerExecer.
+const fakeSFTPServerEnv = "AGENTSSH_TEST_FAKE_SFTP_SERVER"
+
 func TestMain(m *testing.M) {
+	if os.Getenv(fakeSFTPServerEnv) != "" {
+		server, err := sftp.NewServer(struct {
+			io.Reader
+			io.WriteCloser
+		}{os.Stdin, os.Stdout})
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := server.Serve(); err != nil && err != io.EOF {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	goleak.VerifyTestMain(m, testutil.GoleakOptions...)
 }
 
@@ -399,6 +425,143 @@ func TestNewServer_Signal(t *testing.T) {
 	})
 }
 
+func TestNewServer_ContainerSFTP(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS != "linux" {
+		t.Skip("fake container commands are only supported on Linux")
+	}
+
+	// sftpSession requests the SFTP subsystem in a container served by a
+	// fake docker CLI.
+	sftpSession := func(t *testing.T, execer agentexec.Execer) *ssh.Session {
+		t.Helper()
+
+		ctx := context.Background()
+		logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+		s, err := agentssh.NewServer(ctx, logger, prometheus.NewRegistry(), afero.NewMemMapFs(), execer, &agentssh.Config{
+			ExperimentalDevContainersEnabled: true,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = s.Close()
+		})
+		err = s.UpdateHostSigner(42)
+		require.NoError(t, err)
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		go func() {
+			_ = s.Serve(ln)
+		}()
+
+		c := sshClient(t, ln.Addr().String())
+		sess, err := c.NewSession()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = sess.Close()
+		})
+		err = sess.Setenv(agentssh.ContainerEnvironmentVariable, "my-container")
+		require.NoError(t, err)
+		return sess
+	}
+
+	t.Run("NoSFTPServer", func(t *testing.T) {
+		t.Parallel()
+
+		sess := sftpSession(t, &fakeContainerExecer{})
+		stderr, err := sess.StderrPipe()
+		require.NoError(t, err)
+		err = sess.RequestSubsystem("sftp")
+		require.NoError(t, err)
+
+		// The session is closed after the error is written.
+		out, err := io.ReadAll(stderr)
+		require.NoError(t, err)
+		require.Contains(t, string(out), agentcontainers.ErrNoSFTPServer.Error())
+	})
+
+	t.Run("SFTPServer", func(t *testing.T) {
+		t.Parallel()
+
+		sess := sftpSession(t, &fakeContainerExecer{sftpServer: "/usr/lib/openssh/sftp-server"})
+		stdin, err := sess.StdinPipe()
+		require.NoError(t, err)
+		stdout, err := sess.StdoutPipe()
+		require.NoError(t, err)
+		err = sess.RequestSubsystem("sftp")
+		require.NoError(t, err)
+
+		client, err := sftp.NewClientPipe(stdout, stdin)
+		require.NoError(t, err)
+		defer client.Close()
+
+		dir := t.TempDir()
+		err = os.WriteFile(filepath.Join(dir, "file"), []byte("hello"), 0o600)
+		require.NoError(t, err)
+		files, err := client.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		require.Equal(t, "file", files[0].Name())
+	})
+}
+
+// fakeContainerExecer fakes the docker CLI for a container that has an
+// sftp-server binary at sftpServer, or none if it is empty. Commands exec'd
+// in the container run on the host, and the sftp-server is the test binary.
+type fakeContainerExecer struct {
+	sftpServer string
+}
+
+func (f *fakeContainerExecer) CommandContext(ctx context.Context, cmd string, args ...string) *exec.Cmd {
+	cmd, args = f.command(cmd, args...)
+	if cmd == f.sftpServer {
+		c := exec.CommandContext(ctx, os.Args[0]) //nolint:gosec // This is a test.
+		c.Env = append(os.Environ(), fakeSFTPServerEnv+"=1")
+		return c
+	}
+	return agentexec.DefaultExecer.CommandContext(ctx, cmd, args...)
+}
+
+func (f *fakeContainerExecer) PTYCommandContext(ctx context.Context, cmd string, args ...string) *pty.Cmd {
+	cmd, args = f.command(cmd, args...)
+	return agentexec.DefaultExecer.PTYCommandContext(ctx, cmd, args...)
+}
+
+// command returns the command to run on the host in place of cmd.
+func (f *fakeContainerExecer) command(cmd string, args ...string) (string, []string) {
+	if cmd != "docker" || len(args) == 0 {
+		return cmd, args
+	}
+	switch args[0] {
+	case "inspect":
+		return "echo", []string{`[{"Id":"abc"}]`}
+	case "exec":
+	default:
+		return "false", nil
+	}
+	// Skip the flags and the container name.
+	args = args[1:]
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		switch args[0] {
+		case "--user", "--workdir", "--env":
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return "false", nil
+	}
+	cmd, args = args[1], args[2:]
+	if cmd == "/bin/sh" && len(args) == 2 && strings.Contains(args[1], "sftp-server") {
+		// The container is probed for its sftp-server binary.
+		if f.sftpServer == "" {
+			return "/bin/sh", []string{"-c", "exit 127"}
+		}
+		return "echo", []string{f.sftpServer}
+	}
+	return cmd, args
+}
+
 func sshClient(t *testing.T, addr string) *ssh.Client {
 	conn, err := net.Dial("tcp", addr)
 	require.NoError(t, err)
@@ -0,0 +1,98 @@
+package agentssh
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gliderlabs/ssh"
+	"github.com/google/uuid"
+	gossh "golang.org/x/crypto/ssh"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// BlockedByPolicyMessage is sent to clients when an action is blocked by the
+// DLP policy of the workspace.
+const BlockedByPolicyMessage = "blocked by the data loss prevention policy of the workspace"
+
+type reportBlockedActionFunc func(id uuid.UUID, action codersdk.DLPAction, ip, detail string)
+
+// dlpBlocked returns whether the DLP policy blocks the action, and reports
+// the attempt if it does. id is the ID of the session the attempt was made
+// in, or uuid.Nil if it wasn't made in a session.
+//
+// Like fileTransferBlocked, this is a "Do not trespass" sign: a user with a
+// shell can still move data with other tools.
+func (s *Server) dlpBlocked(ctx context.Context, id uuid.UUID, action codersdk.DLPAction, remoteAddr net.Addr, detail string) bool {
+	if !s.config.DLPPolicy().Blocks(action) {
+		return false
+	}
+	s.logger.Warn(ctx, "action blocked by dlp policy",
+		slog.F("id", id),
+		slog.F("action", action),
+		slog.F("detail", detail),
+		slog.F("remote_addr", remoteAddr),
+	)
+	var ip string
+	if remoteAddr != nil {
+		ip = remoteAddr.String()
+	}
+	s.config.ReportBlockedAction(id, action, ip, detail)
+	return true
+}
+
+// sessionDLPAction returns the file transfer a session makes, if any, and a
+// description of it. OpenSSH 9.0 and later use SFTP for scp, so only older
+// clients are reported as scp.
+func sessionDLPAction(session ssh.Session) (action codersdk.DLPAction, detail string, ok bool) {
+	if session.Subsystem() == "sftp" {
+		return codersdk.DLPActionSFTP, "sftp subsystem", true
+	}
+	cmd := session.Command()
+	if len(cmd) == 0 {
+		return "", "", false
+	}
+	switch filepath.Base(cmd[0]) {
+	case "sftp", "sftp-server":
+		return codersdk.DLPActionSFTP, session.RawCommand(), true
+	case "scp":
+		return codersdk.DLPActionSCP, session.RawCommand(), true
+	}
+	return "", "", false
+}
+
+// dlpDirectStreamLocalHandler blocks forwarding of unix sockets in the
+// workspace to the client if local port forwarding is blocked.
+func (s *Server) dlpDirectStreamLocalHandler(srv *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+	var reqPayload directStreamLocalPayload
+	// Errors are handled by directStreamLocalHandler.
+	_ = gossh.Unmarshal(newChan.ExtraData(), &reqPayload)
+	if s.dlpBlocked(ctx, uuid.Nil, codersdk.DLPActionLocalPortForwarding, ctx.RemoteAddr(), reqPayload.SocketPath) {
+		_ = newChan.Reject(gossh.Prohibited, BlockedByPolicyMessage)
+		return
+	}
+	directStreamLocalHandler(srv, conn, newChan, ctx)
+}
+
+// dlpStreamLocalForwardHandler blocks forwarding of unix sockets on the
+// client to the workspace if remote port forwarding is blocked.
+func (s *Server) dlpStreamLocalForwardHandler(handler ssh.RequestHandler) ssh.RequestHandler {
+	return func(ctx ssh.Context, srv *ssh.Server, req *gossh.Request) (bool, []byte) {
+		if req.Type == "streamlocal-forward@openssh.com" {
+			var reqPayload streamLocalForwardPayload
+			_ = gossh.Unmarshal(req.Payload, &reqPayload)
+			if s.dlpBlocked(ctx, uuid.Nil, codersdk.DLPActionRemotePortForwarding, ctx.RemoteAddr(), reqPayload.SocketPath) {
+				return false, nil
+			}
+		}
+		return handler(ctx, srv, req)
+	}
+}
+
+func hostPort(host string, port uint32) string {
+	return net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+}
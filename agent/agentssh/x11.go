@@ -16,11 +16,14 @@ import (
 
 	"github.com/gliderlabs/ssh"
 	"github.com/gofrs/flock"
+	"github.com/google/uuid"
 	"github.com/spf13/afero"
 	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/codersdk"
 )
 
 const (
@@ -32,9 +35,9 @@ const (
 )
 
 // x11Callback is called when the client requests X11 forwarding.
-func (*Server) x11Callback(_ ssh.Context, _ ssh.X11) bool {
-	// Always allow.
-	return true
+func (s *Server) x11Callback(ctx ssh.Context, _ ssh.X11) bool {
+	// Allow unless the DLP policy blocks it.
+	return !s.dlpBlocked(ctx, uuid.Nil, codersdk.DLPActionX11Forwarding, ctx.RemoteAddr(), "")
 }
 
 // x11Handler is called when a session has requested X11 forwarding.
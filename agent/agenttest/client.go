@@ -97,8 +97,8 @@ func (c *Client) Close() {
 	c.derpMapOnce.Do(func() { close(c.derpMapUpdates) })
 }
 
-func (c *Client) ConnectRPC24(ctx context.Context) (
-	agentproto.DRPCAgentClient24, proto.DRPCTailnetClient24, error,
+func (c *Client) ConnectRPC25(ctx context.Context) (
+	agentproto.DRPCAgentClient25, proto.DRPCTailnetClient25, error,
 ) {
 	conn, lis := drpcsdk.MemTransportPipe()
 	c.LastWorkspaceAgent = func() {
@@ -162,6 +162,14 @@ func (c *Client) GetConnectionReports() []*agentproto.ReportConnectionRequest {
 	return c.fakeAgentAPI.GetConnectionReports()
 }
 
+func (c *Client) GetSessionRecordingUploads() []*agentproto.UploadSessionRecordingRequest {
+	return c.fakeAgentAPI.GetSessionRecordingUploads()
+}
+
+func (c *Client) GetBlockedActionReports() []*agentproto.ReportBlockedActionRequest {
+	return c.fakeAgentAPI.GetBlockedActionReports()
+}
+
 type FakeAgentAPI struct {
 	sync.Mutex
 	t      testing.TB
@@ -176,6 +184,8 @@ type FakeAgentAPI struct {
 	metadata          map[string]agentsdk.Metadata
 	timings           []*agentproto.Timing
 	connectionReports []*agentproto.ReportConnectionRequest
+	recordingUploads  []*agentproto.UploadSessionRecordingRequest
+	blockedActions    []*agentproto.ReportBlockedActionRequest
 
 	getAnnouncementBannersFunc              func() ([]codersdk.BannerConfig, error)
 	getResourcesMonitoringConfigurationFunc func() (*agentproto.GetResourcesMonitoringConfigurationResponse, error)
@@ -364,6 +374,34 @@ func (f *FakeAgentAPI) GetConnectionReports() []*agentproto.ReportConnectionRequ
 	return slices.Clone(f.connectionReports)
 }
 
+func (f *FakeAgentAPI) UploadSessionRecording(_ context.Context, req *agentproto.UploadSessionRecordingRequest) (*emptypb.Empty, error) {
+	f.Lock()
+	f.recordingUploads = append(f.recordingUploads, req)
+	f.Unlock()
+
+	return &emptypb.Empty{}, nil
+}
+
+func (f *FakeAgentAPI) GetSessionRecordingUploads() []*agentproto.UploadSessionRecordingRequest {
+	f.Lock()
+	defer f.Unlock()
+	return slices.Clone(f.recordingUploads)
+}
+
+func (f *FakeAgentAPI) ReportBlockedAction(_ context.Context, req *agentproto.ReportBlockedActionRequest) (*emptypb.Empty, error) {
+	f.Lock()
+	f.blockedActions = append(f.blockedActions, req)
+	f.Unlock()
+
+	return &emptypb.Empty{}, nil
+}
+
+func (f *FakeAgentAPI) GetBlockedActionReports() []*agentproto.ReportBlockedActionRequest {
+	f.Lock()
+	defer f.Unlock()
+	return slices.Clone(f.blockedActions)
+}
+
 func NewFakeAgentAPI(t testing.TB, logger slog.Logger, manifest *agentproto.Manifest, statsCh chan *agentproto.Stats) *FakeAgentAPI {
 	return &FakeAgentAPI{
 		t:           t,
@@ -2,12 +2,16 @@ package agent
 
 import (
 	"net/http"
+	"path"
 	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
+	"cdr.dev/slog"
 	"github.com/coder/coder/v2/agent/agentcontainers"
+	"github.com/coder/coder/v2/agent/agentfiles"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/codersdk"
 )
@@ -51,11 +55,17 @@ func (a *agent) apiHandler() http.Handler {
 	}
 	containerAPI := agentcontainers.NewAPI(a.logger.Named("containers"), containerAPIOpts...)
 
+	filesAPI := agentfiles.NewAPI(a.logger.Named("files"), agentfiles.WithTransferBlocked(a.fileTransferBlocked))
+
 	promHandler := PrometheusMetricsHandler(a.prometheusRegistry, a.logger)
 
 	r.Mount("/api/v0/containers", containerAPI.Routes())
+	r.Mount("/api/v0/files", filesAPI.Routes())
+	r.Mount("/api/v0/processes", a.processAPI.Routes())
 	r.Get("/api/v0/listening-ports", lp.handler)
 	r.Get("/api/v0/netcheck", a.HandleNetcheck)
+	r.Get("/api/v0/path-history", a.HandlePathHistory)
+	r.Get("/api/v0/reconnecting-pty/{id}/presence", a.handleReconnectingPTYPresence)
 	r.Post("/api/v0/list-directory", a.HandleLS)
 	r.Get("/debug/logs", a.HandleHTTPDebugLogs)
 	r.Get("/debug/magicsock", a.HandleHTTPDebugMagicsock)
@@ -66,6 +76,23 @@ func (a *agent) apiHandler() http.Handler {
 	return r
 }
 
+// handleReconnectingPTYPresence returns the connections attached to a
+// reconnecting PTY.
+func (a *agent) handleReconnectingPTYPresence(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid reconnecting PTY ID.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspaceAgentPTYPresence{
+		Attachments: a.reconnectingPTYServer.Attachments(id),
+	})
+}
+
 type listeningPortsHandler struct {
 	ignorePorts   map[int]string
 	cacheDuration time.Duration
@@ -94,3 +121,22 @@ func (lp *listeningPortsHandler) handler(rw http.ResponseWriter, r *http.Request
 		Ports: ports,
 	})
 }
+
+// fileTransferBlocked returns whether the file system API may read or write
+// the contents of files. The API transfers files like SFTP does, so it's
+// blocked whenever SFTP is.
+func (a *agent) fileTransferBlocked(r *http.Request) bool {
+	if a.blockFileTransfer {
+		return true
+	}
+	if !a.dlpPolicy().BlockSFTP {
+		return false
+	}
+	a.logger.Warn(r.Context(), "action blocked by dlp policy",
+		slog.F("action", codersdk.DLPActionSFTP),
+		slog.F("detail", r.URL.Path),
+		slog.F("remote_addr", r.RemoteAddr),
+	)
+	a.reportBlockedAction(uuid.Nil, codersdk.DLPActionSFTP, r.RemoteAddr, "file system api "+path.Base(r.URL.Path))
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/healthsdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
 )
 
 func (a *agent) HandleNetcheck(rw http.ResponseWriter, r *http.Request) {
@@ -29,3 +30,9 @@ func (a *agent) HandleNetcheck(rw http.ResponseWriter, r *http.Request) {
 		Interfaces: ifReport,
 	})
 }
+
+func (a *agent) HandlePathHistory(rw http.ResponseWriter, r *http.Request) {
+	httpapi.Write(r.Context(), rw, http.StatusOK, workspacesdk.AgentPathHistoryResponse{
+		Events: a.TailnetConn().PathHistory(),
+	})
+}
@@ -50,3 +50,12 @@ type DRPCAgentClient24 interface {
 	PushResourcesMonitoringUsage(ctx context.Context, in *PushResourcesMonitoringUsageRequest) (*PushResourcesMonitoringUsageResponse, error)
 	ReportConnection(ctx context.Context, in *ReportConnectionRequest) (*emptypb.Empty, error)
 }
+
+// DRPCAgentClient25 is the Agent API at v2.5. It adds the UploadSessionRecording and
+// ReportBlockedAction RPCs, and secret files, session recording and the DLP policy to the
+// Manifest.
+type DRPCAgentClient25 interface {
+	DRPCAgentClient24
+	UploadSessionRecording(ctx context.Context, in *UploadSessionRecordingRequest) (*emptypb.Empty, error)
+	ReportBlockedAction(ctx context.Context, in *ReportBlockedActionRequest) (*emptypb.Empty, error)
+}
@@ -0,0 +1,203 @@
+package reconnectingpty
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+)
+
+var (
+	// osc52Start starts an OSC 52 sequence, which programs like tmux and vim
+	// use to set the clipboard of the terminal.
+	osc52Start = []byte("\x1b]52;")
+	// OSC sequences end with BEL or ST.
+	osc52Ends = [][]byte{[]byte("\x07"), []byte("\x1b\\")}
+
+	// Text pasted into a terminal with bracketed paste mode enabled is
+	// wrapped in these sequences. Shells and editors enable the mode, so
+	// this catches pastes into almost every program.
+	bracketedPasteStart = []byte("\x1b[200~")
+	bracketedPasteEnd   = [][]byte{[]byte("\x1b[201~")}
+)
+
+// dlpConn enforces the clipboard rules of a DLP policy on a reconnecting PTY
+// connection. It removes OSC 52 sequences from the output if copying is
+// blocked, and pasted text from the input if pasting is blocked.
+type dlpConn struct {
+	net.Conn
+	report func(action codersdk.DLPAction, detail string)
+
+	writeMu sync.Mutex
+	output  *sequenceFilter
+
+	// input is where filtered input is read from, nil if input isn't
+	// filtered.
+	input *io.PipeReader
+}
+
+func newDLPConn(conn net.Conn, policy codersdk.DLPPolicy, report func(action codersdk.DLPAction, detail string)) net.Conn {
+	if !policy.BlockClipboardCopy && !policy.BlockClipboardPaste {
+		return conn
+	}
+	c := &dlpConn{
+		Conn:   conn,
+		report: report,
+	}
+	if policy.BlockClipboardCopy {
+		c.output = &sequenceFilter{start: osc52Start, ends: osc52Ends}
+	}
+	if policy.BlockClipboardPaste {
+		pr, pw := io.Pipe()
+		c.input = pr
+		go c.filterInput(pw)
+	}
+	return c
+}
+
+// filterInput decodes the requests sent by the client, and writes them to
+// pw without pasted text.
+func (c *dlpConn) filterInput(pw *io.PipeWriter) {
+	filter := &sequenceFilter{start: bracketedPasteStart, ends: bracketedPasteEnd}
+	decoder := json.NewDecoder(c.Conn)
+	encoder := json.NewEncoder(pw)
+	for {
+		var req workspacesdk.ReconnectingPTYRequest
+		err := decoder.Decode(&req)
+		if err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+		if req.Data != "" {
+			data, started := filter.filter([]byte(req.Data))
+			// Keystrokes are sent as they're typed, so nothing is held
+			// back between requests. Otherwise pressing escape would do
+			// nothing until the next key.
+			data = append(data, filter.flush()...)
+			for range started {
+				c.report(codersdk.DLPActionClipboardPaste, "bracketed paste")
+			}
+			req.Data = string(data)
+			if req.Data == "" && req.Height == 0 && req.Width == 0 {
+				continue
+			}
+		}
+		err = encoder.Encode(req)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *dlpConn) Read(p []byte) (int, error) {
+	if c.input == nil {
+		return c.Conn.Read(p)
+	}
+	return c.input.Read(p)
+}
+
+func (c *dlpConn) Write(p []byte) (int, error) {
+	if c.output == nil {
+		return c.Conn.Write(p)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	data, started := c.output.filter(p)
+	for range started {
+		c.report(codersdk.DLPActionClipboardCopy, "OSC 52")
+	}
+	if len(data) > 0 {
+		_, err := c.Conn.Write(data)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (c *dlpConn) Close() error {
+	if c.input != nil {
+		_ = c.input.Close()
+	}
+	return c.Conn.Close()
+}
+
+// sequenceFilter removes sequences that begin with start and end with one of
+// ends from a stream. Sequences may be split across calls to filter.
+type sequenceFilter struct {
+	start []byte
+	ends  [][]byte
+
+	inside bool
+	// pending holds the bytes at the end of the last call that may be the
+	// beginning of start or of an end.
+	pending []byte
+}
+
+// filter returns p without sequences, and the number of sequences that
+// started in p.
+func (f *sequenceFilter) filter(p []byte) (out []byte, started int) {
+	data := append(f.pending, p...)
+	f.pending = nil
+	for len(data) > 0 {
+		if !f.inside {
+			i := bytes.Index(data, f.start)
+			if i < 0 {
+				keep := partialSuffix(data, f.start)
+				out = append(out, data[:len(data)-keep]...)
+				f.pending = bytes.Clone(data[len(data)-keep:])
+				return out, started
+			}
+			out = append(out, data[:i]...)
+			data = data[i+len(f.start):]
+			f.inside = true
+			started++
+			continue
+		}
+
+		end, n := -1, 0
+		for _, e := range f.ends {
+			if i := bytes.Index(data, e); i >= 0 && (end < 0 || i < end) {
+				end, n = i, len(e)
+			}
+		}
+		if end < 0 {
+			keep := 0
+			for _, e := range f.ends {
+				keep = max(keep, partialSuffix(data, e))
+			}
+			f.pending = bytes.Clone(data[len(data)-keep:])
+			return out, started
+		}
+		data = data[end+n:]
+		f.inside = false
+	}
+	return out, started
+}
+
+// flush returns the bytes held back by filter because they may have been
+// the beginning of a sequence. Inside a sequence, they're kept to find its
+// end.
+func (f *sequenceFilter) flush() []byte {
+	if f.inside {
+		return nil
+	}
+	pending := f.pending
+	f.pending = nil
+	return pending
+}
+
+// partialSuffix returns the length of the longest suffix of data that's a
+// prefix of seq, but not all of it.
+func partialSuffix(data, seq []byte) int {
+	for n := min(len(data), len(seq)-1); n > 0; n-- {
+		if bytes.HasSuffix(data, seq[:n]) {
+			return n
+		}
+	}
+	return 0
+}
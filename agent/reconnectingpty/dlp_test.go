@@ -0,0 +1,98 @@
+package reconnectingpty
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequenceFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		chunks  []string
+		want    string
+		started int
+	}{
+		{
+			name:   "NoSequence",
+			chunks: []string{"hello ", "world"},
+			want:   "hello world",
+		},
+		{
+			name:    "BEL",
+			chunks:  []string{"a\x1b]52;c;aGVsbG8=\x07b"},
+			want:    "ab",
+			started: 1,
+		},
+		{
+			name:    "ST",
+			chunks:  []string{"a\x1b]52;c;aGVsbG8=\x1b\\b"},
+			want:    "ab",
+			started: 1,
+		},
+		{
+			name:    "SplitStart",
+			chunks:  []string{"a\x1b]5", "2;c;aGVsbG8=\x07b"},
+			want:    "ab",
+			started: 1,
+		},
+		{
+			name:    "SplitEnd",
+			chunks:  []string{"a\x1b]52;c;aGVs", "bG8=\x1b", "\\b"},
+			want:    "ab",
+			started: 1,
+		},
+		{
+			name:   "OtherEscapeSequence",
+			chunks: []string{"\x1b]0;title\x07", "\x1b", "[1m"},
+			want:   "\x1b]0;title\x07\x1b[1m",
+		},
+		{
+			name:    "Multiple",
+			chunks:  []string{"\x1b]52;c;YQ==\x07x\x1b]52;c;Yg==\x07"},
+			want:    "x",
+			started: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &sequenceFilter{start: osc52Start, ends: osc52Ends}
+			var got []byte
+			var started int
+			for _, chunk := range tt.chunks {
+				out, n := f.filter([]byte(chunk))
+				got = append(got, out...)
+				started += n
+			}
+			got = append(got, f.flush()...)
+			require.Equal(t, tt.want, string(got))
+			require.Equal(t, tt.started, started)
+		})
+	}
+
+	t.Run("Flush", func(t *testing.T) {
+		t.Parallel()
+
+		// A lone escape is held back in case it starts a paste, and
+		// returned by flush.
+		f := &sequenceFilter{start: bracketedPasteStart, ends: bracketedPasteEnd}
+		out, _ := f.filter([]byte("\x1b"))
+		require.Empty(t, out)
+		require.Equal(t, "\x1b", string(f.flush()))
+
+		// A paste that's split across calls is dropped entirely.
+		out, started := f.filter([]byte("a\x1b[200~secret"))
+		require.Equal(t, "a", string(out))
+		require.Equal(t, 1, started)
+		require.Empty(t, f.flush())
+		out, _ = f.filter([]byte("more\x1b[201"))
+		require.Empty(t, out)
+		require.Empty(t, f.flush())
+		out, _ = f.filter([]byte("~b"))
+		require.Equal(t, "b", string(out))
+	})
+}
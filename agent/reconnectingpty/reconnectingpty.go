@@ -37,8 +37,8 @@ type Options struct {
 }
 
 // ReconnectingPTY is a pty that can be reconnected within a timeout and to
-// simultaneous connections.  The reconnecting pty can be backed by screen if
-// installed or a (buggy) buffer replay fallback.
+// simultaneous connections.  The reconnecting pty can be backed by screen or
+// tmux if installed or a (buggy) buffer replay fallback.
 type ReconnectingPTY interface {
 	// Attach pipes the connection and pty, spawning it if necessary, replays
 	// history, then blocks until EOF, an error, or the context's end.  The
@@ -65,12 +65,14 @@ func New(ctx context.Context, logger slog.Logger, execer agentexec.Execer, cmd *
 	// Screen seems flaky on Darwin.  Locally the tests pass 100% of the time (100
 	// runs) but in CI screen often incorrectly claims the session name does not
 	// exist even though screen -list shows it.  For now, restrict screen to
-	// Linux.
+	// Linux.  Many images ship tmux but not screen, so fall back to it.  tmux
+	// hasn't been tested on Darwin either, so it's restricted the same way.
 	autoBackendType := "buffered"
 	if runtime.GOOS == "linux" {
-		_, err := exec.LookPath("screen")
-		if err == nil {
+		if _, err := exec.LookPath("screen"); err == nil {
 			autoBackendType = "screen"
+		} else if _, err := exec.LookPath("tmux"); err == nil {
+			autoBackendType = "tmux"
 		}
 	}
 	var backendType string
@@ -86,6 +88,8 @@ func New(ctx context.Context, logger slog.Logger, execer agentexec.Execer, cmd *
 	switch backendType {
 	case "screen":
 		return newScreen(ctx, logger, execer, cmd, options)
+	case "tmux":
+		return newTmux(ctx, logger, execer, cmd, options)
 	default:
 		return newBuffered(ctx, logger, execer, cmd, options)
 	}
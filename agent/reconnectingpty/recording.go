@@ -0,0 +1,63 @@
+package reconnectingpty
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+
+	"github.com/coder/coder/v2/agent/sessionrecording"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+)
+
+// recordingConn records the output written to a reconnecting PTY connection
+// and the terminal resizes requested by the client.
+type recordingConn struct {
+	net.Conn
+	recorder *sessionrecording.Recorder
+	requests *io.PipeWriter
+}
+
+func newRecordingConn(conn net.Conn, recorder *sessionrecording.Recorder) *recordingConn {
+	pr, pw := io.Pipe()
+	go func() {
+		decoder := json.NewDecoder(pr)
+		for {
+			var req workspacesdk.ReconnectingPTYRequest
+			if err := decoder.Decode(&req); err != nil {
+				break
+			}
+			if req.Height > 0 && req.Width > 0 {
+				recorder.Resize(req.Width, req.Height)
+			}
+		}
+		// Keep draining so reads from the connection never block on us.
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+	return &recordingConn{
+		Conn:     conn,
+		recorder: recorder,
+		requests: pw,
+	}
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		_, _ = c.requests.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *recordingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		_, _ = c.recorder.Write(p[:n])
+	}
+	return n, err
+}
+
+func (c *recordingConn) Close() error {
+	_ = c.requests.Close()
+	_ = c.recorder.Close()
+	return c.Conn.Close()
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"net"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,7 +18,9 @@ import (
 	"cdr.dev/slog"
 	"github.com/coder/coder/v2/agent/agentcontainers"
 	"github.com/coder/coder/v2/agent/agentssh"
+	"github.com/coder/coder/v2/agent/sessionrecording"
 	"github.com/coder/coder/v2/agent/usershell"
+	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/workspacesdk"
 )
 
@@ -32,7 +36,20 @@ type Server struct {
 	reconnectingPTYs sync.Map
 	timeout          time.Duration
 
+	attachmentsMu sync.Mutex
+	// attachments holds the connections attached to each reconnecting PTY,
+	// by connection ID.
+	attachments map[uuid.UUID]map[string]codersdk.WorkspaceAgentPTYAttachment
+
 	ExperimentalDevcontainersEnabled bool
+	// RecordSession returns a recorder for the connection with the given ID,
+	// or nil if the connection should not be recorded.
+	RecordSession func(id uuid.UUID, width, height uint16) *sessionrecording.Recorder
+	// DLPPolicy returns the data loss prevention policy, of which the
+	// clipboard rules are enforced on connections.
+	DLPPolicy func() codersdk.DLPPolicy
+	// ReportBlockedAction is called when the DLP policy blocks an action.
+	ReportBlockedAction func(id uuid.UUID, action codersdk.DLPAction, ip, detail string)
 }
 
 // NewServer returns a new ReconnectingPTY server
@@ -52,10 +69,20 @@ func NewServer(logger slog.Logger, commandCreator *agentssh.Server, reportConnec
 		connectionsTotal: connectionsTotal,
 		errorsTotal:      errorsTotal,
 		timeout:          timeout,
+		attachments:      make(map[uuid.UUID]map[string]codersdk.WorkspaceAgentPTYAttachment),
 	}
 	for _, o := range opts {
 		o(s)
 	}
+	if s.RecordSession == nil {
+		s.RecordSession = func(uuid.UUID, uint16, uint16) *sessionrecording.Recorder { return nil }
+	}
+	if s.DLPPolicy == nil {
+		s.DLPPolicy = func() codersdk.DLPPolicy { return codersdk.DLPPolicy{} }
+	}
+	if s.ReportBlockedAction == nil {
+		s.ReportBlockedAction = func(uuid.UUID, codersdk.DLPAction, string, string) {}
+	}
 	return s
 }
 
@@ -76,7 +103,8 @@ func (s *Server) Serve(ctx, hardCtx context.Context, l net.Listener) (retErr err
 			slog.F("local", conn.LocalAddr().String()))
 		clog.Info(ctx, "accepted conn")
 		wg.Add(1)
-		disconnected := s.reportConnection(uuid.New(), conn.RemoteAddr().String())
+		id := uuid.New()
+		disconnected := s.reportConnection(id, conn.RemoteAddr().String())
 		closed := make(chan struct{})
 		go func() {
 			defer wg.Done()
@@ -91,7 +119,7 @@ func (s *Server) Serve(ctx, hardCtx context.Context, l net.Listener) (retErr err
 		go func() {
 			defer close(closed)
 			defer wg.Done()
-			err := s.handleConn(ctx, clog, conn)
+			err := s.handleConn(ctx, clog, id, conn)
 			if err != nil {
 				if ctx.Err() != nil {
 					disconnected(1, "server shutting down")
@@ -111,7 +139,45 @@ func (s *Server) ConnCount() int64 {
 	return s.connCount.Load()
 }
 
-func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Conn) (retErr error) {
+// Attachments returns the connections attached to the reconnecting PTY with
+// the given ID, in the order they attached.
+func (s *Server) Attachments(id uuid.UUID) []codersdk.WorkspaceAgentPTYAttachment {
+	s.attachmentsMu.Lock()
+	defer s.attachmentsMu.Unlock()
+	attachments := make([]codersdk.WorkspaceAgentPTYAttachment, 0, len(s.attachments[id]))
+	for _, attachment := range s.attachments[id] {
+		attachments = append(attachments, attachment)
+	}
+	slices.SortFunc(attachments, func(a, b codersdk.WorkspaceAgentPTYAttachment) int {
+		return a.AttachedAt.Compare(b.AttachedAt)
+	})
+	return attachments
+}
+
+// attach records a connection as attached to a reconnecting PTY until the
+// returned function is called.
+func (s *Server) attach(msg workspacesdk.AgentReconnectingPTYInit, connectionID string) (detach func()) {
+	s.attachmentsMu.Lock()
+	defer s.attachmentsMu.Unlock()
+	if s.attachments[msg.ID] == nil {
+		s.attachments[msg.ID] = make(map[string]codersdk.WorkspaceAgentPTYAttachment)
+	}
+	s.attachments[msg.ID][connectionID] = codersdk.WorkspaceAgentPTYAttachment{
+		Username:   msg.Username,
+		ReadOnly:   msg.ReadOnly,
+		AttachedAt: time.Now(),
+	}
+	return func() {
+		s.attachmentsMu.Lock()
+		defer s.attachmentsMu.Unlock()
+		delete(s.attachments[msg.ID], connectionID)
+		if len(s.attachments[msg.ID]) == 0 {
+			delete(s.attachments, msg.ID)
+		}
+	}
+}
+
+func (s *Server) handleConn(ctx context.Context, logger slog.Logger, id uuid.UUID, conn net.Conn) (retErr error) {
 	defer conn.Close()
 	s.connectionsTotal.Add(1)
 	s.connCount.Add(1)
@@ -143,8 +209,20 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 		return nil
 	}
 
+	if recorder := s.RecordSession(id, msg.Width, msg.Height); recorder != nil {
+		rconn := newRecordingConn(conn, recorder)
+		defer rconn.Close()
+		conn = rconn
+	}
+	// The recording has what the client sees, so the DLP filter wraps it.
+	remoteAddr := conn.RemoteAddr().String()
+	conn = newDLPConn(conn, s.DLPPolicy(), func(action codersdk.DLPAction, detail string) {
+		logger.Warn(ctx, "action blocked by dlp policy", slog.F("action", action))
+		s.ReportBlockedAction(id, action, remoteAddr, detail)
+	})
+
 	connectionID := uuid.NewString()
-	connLogger := logger.With(slog.F("message_id", msg.ID), slog.F("connection_id", connectionID), slog.F("container", msg.Container), slog.F("container_user", msg.ContainerUser))
+	connLogger := logger.With(slog.F("message_id", msg.ID), slog.F("connection_id", connectionID), slog.F("container", msg.Container), slog.F("container_user", msg.ContainerUser), slog.F("username", msg.Username), slog.F("read_only", msg.ReadOnly))
 	connLogger.Debug(ctx, "starting handler")
 
 	defer func() {
@@ -159,10 +237,26 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 		connLogger.Info(ctx, "reconnecting pty connection closed")
 	}()
 
-	var rpty ReconnectingPTY
-	sendConnected := make(chan ReconnectingPTY, 1)
-	// On store, reserve this ID to prevent multiple concurrent new connections.
-	waitReady, ok := s.reconnectingPTYs.LoadOrStore(msg.ID, sendConnected)
+	var (
+		rpty          ReconnectingPTY
+		waitReady     any
+		ok            bool
+		sendConnected = make(chan ReconnectingPTY, 1)
+	)
+	if msg.AttachOnly {
+		// Attach-only connections never create the PTY, so they must not
+		// reserve its ID either; an owner connecting at the same time would
+		// find the reservation closed.
+		waitReady, ok = s.reconnectingPTYs.Load(msg.ID)
+		if !ok {
+			close(sendConnected)
+			connLogger.Warn(ctx, "reconnecting pty does not exist for attach-only connection")
+			return nil
+		}
+	} else {
+		// On store, reserve this ID to prevent multiple concurrent new connections.
+		waitReady, ok = s.reconnectingPTYs.LoadOrStore(msg.ID, sendConnected)
+	}
 	if ok {
 		close(sendConnected) // Unused.
 		connLogger.Debug(ctx, "connecting to existing reconnecting pty")
@@ -196,7 +290,8 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 			s.logger.Info(ctx, "got container env info", slog.F("container", msg.Container))
 		}
 		// Empty command will default to the users shell!
-		cmd, err := s.commandCreator.CreateCommand(ctx, msg.Command, nil, ei)
+		env := []string{fmt.Sprintf("%s=%s", agentssh.SessionIDEnvironmentVariable, msg.ID)}
+		cmd, err := s.commandCreator.CreateCommand(ctx, msg.Command, env, ei)
 		if err != nil {
 			s.errorsTotal.WithLabelValues("create_command").Add(1)
 			return xerrors.Errorf("create command: %w", err)
@@ -230,5 +325,7 @@ func (s *Server) handleConn(ctx context.Context, logger slog.Logger, conn net.Co
 		connected = true
 		sendConnected <- rpty
 	}
+	detach := s.attach(msg, connectionID)
+	defer detach()
 	return rpty.Attach(ctx, connectionID, conn, msg.Height, msg.Width, connLogger)
 }
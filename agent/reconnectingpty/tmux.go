@@ -0,0 +1,429 @@
+package reconnectingpty
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/v2/agent/agentexec"
+	"github.com/coder/coder/v2/pty"
+)
+
+// tmuxReconnectingPTY provides a reconnectable PTY via `tmux`.
+type tmuxReconnectingPTY struct {
+	execer  agentexec.Execer
+	command *pty.Cmd
+
+	// id is the name of the tmux session.  Each reconnecting pty also gets its
+	// own tmux server (named after the id) so our config never leaks into the
+	// user's own tmux sessions and closing the reconnecting pty is as simple as
+	// killing the server.
+	id string
+
+	// mutex prevents concurrent attaches from racing to create the session.
+	mutex sync.Mutex
+
+	configFile string
+
+	metrics *prometheus.CounterVec
+
+	state *ptyState
+	// timer will close the reconnecting pty when it expires.  The timer will be
+	// reset as long as there are active connections.
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+// newTmux creates a new tmux-backed reconnecting PTY.  Like screen, the session
+// is not created here but by the first attach so that it spawns with the size
+// of the connection instead of tmux's default 80x24.
+func newTmux(ctx context.Context, logger slog.Logger, execer agentexec.Execer, cmd *pty.Cmd, options *Options) *tmuxReconnectingPTY {
+	rpty := &tmuxReconnectingPTY{
+		execer:  execer,
+		command: cmd,
+		metrics: options.Metrics,
+		state:   newState(),
+		timeout: options.Timeout,
+	}
+
+	// The server socket lives in a directory under the temporary directory
+	// whose path is limited to around 100 characters, so keep the ID short.
+	buf := make([]byte, 4)
+	_, err := rand.Read(buf)
+	if err != nil {
+		rpty.state.setState(StateDone, xerrors.Errorf("generate tmux id: %w", err))
+		return rpty
+	}
+	rpty.id = hex.EncodeToString(buf)
+
+	settings := []string{
+		// The terminal should look like a plain shell, so hide the status bar.
+		"set -g status off",
+		// Remap the prefix to C-s for the same reasons the screen backend remaps
+		// its escape key: C-b is used by applications (readline for example)
+		// while C-s would only pause the terminal.
+		"set -g prefix C-s",
+		"unbind C-b",
+		"bind C-s send-prefix",
+		// Do not wait for escape sequences after the escape key, which makes
+		// editors like vim feel sluggish.
+		"set -s escape-time 0",
+		// Keep more history than the default 2000 lines since it is also replayed
+		// to connections when they attach.
+		"set -g history-limit 10000",
+		// Disable the alternate screen in the outer terminal so output stays in
+		// its scrollback and the mouse wheel or scroll bar keep working, the same
+		// as `termcapinfo xterm* ti@:te@` does for screen.
+		"set -ga terminal-overrides ',xterm*:smcup@:rmcup@'",
+	}
+
+	rpty.configFile = filepath.Join(os.TempDir(), "coder-tmux", "config")
+	err = os.MkdirAll(filepath.Dir(rpty.configFile), 0o700)
+	if err != nil {
+		rpty.state.setState(StateDone, xerrors.Errorf("make tmux config dir: %w", err))
+		return rpty
+	}
+
+	err = os.WriteFile(rpty.configFile, []byte(strings.Join(settings, "\n")), 0o600)
+	if err != nil {
+		rpty.state.setState(StateDone, xerrors.Errorf("create config file: %w", err))
+		return rpty
+	}
+
+	go rpty.lifecycle(ctx, logger)
+
+	return rpty
+}
+
+// lifecycle manages the lifecycle of the reconnecting pty.  If the context ends
+// the reconnecting pty will be closed.
+func (rpty *tmuxReconnectingPTY) lifecycle(ctx context.Context, logger slog.Logger) {
+	rpty.timer = time.AfterFunc(attachTimeout, func() {
+		rpty.Close(xerrors.New("reconnecting pty timeout"))
+	})
+
+	logger.Debug(ctx, "reconnecting pty ready")
+	rpty.state.setState(StateReady, nil)
+
+	state, reasonErr := rpty.state.waitForStateOrContext(ctx, StateClosing)
+	if state < StateClosing {
+		// If we have not closed yet then the context is what unblocked us (which
+		// means the agent is shutting down) so move into the closing phase.
+		rpty.Close(reasonErr)
+	}
+	rpty.timer.Stop()
+
+	// The server exits on its own once the command exits, in which case there
+	// is nothing left to kill.
+	err := rpty.sendCommand(context.Background(), []string{"kill-server"}, []string{"no server running", "error connecting to"})
+	if err != nil {
+		logger.Error(ctx, "close tmux server", slog.Error(err))
+	}
+
+	logger.Info(ctx, "closed reconnecting pty")
+	rpty.state.setState(StateDone, reasonErr)
+}
+
+func (rpty *tmuxReconnectingPTY) Attach(ctx context.Context, _ string, conn net.Conn, height, width uint16, logger slog.Logger) error {
+	logger.Info(ctx, "attach to reconnecting pty")
+
+	// This will kill the heartbeat once we hit EOF or an error.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	state, err := rpty.state.waitForStateOrContext(ctx, StateReady)
+	if state != StateReady {
+		return err
+	}
+
+	go heartbeat(ctx, rpty.timer, rpty.timeout)
+
+	ptty, process, err := rpty.doAttach(ctx, conn, height, width, logger)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			// Likely the process was too short-lived and canceled the wait for
+			// the session.
+			return nil
+		}
+		return err
+	}
+
+	defer func() {
+		// Log only for debugging since the process might have already exited on its
+		// own.
+		err := ptty.Close()
+		if err != nil {
+			logger.Debug(ctx, "closed ptty with error", slog.Error(err))
+		}
+		err = process.Kill()
+		if err != nil {
+			logger.Debug(ctx, "killed process with error", slog.Error(err))
+		}
+	}()
+
+	// Pipe conn -> pty and block.  The tmux client picks up resizes of its pty
+	// and resizes the session accordingly.
+	readConnLoop(ctx, conn, ptty, rpty.metrics, logger)
+	return nil
+}
+
+// doAttach replays the session's scrollback and spawns the tmux client.  It
+// exists separately only so we can defer the mutex unlock which is not
+// possible in Attach since it blocks.
+func (rpty *tmuxReconnectingPTY) doAttach(ctx context.Context, conn net.Conn, height, width uint16, logger slog.Logger) (pty.PTYCmd, pty.Process, error) {
+	// Ensure another attach does not come in while the session is created.
+	rpty.mutex.Lock()
+	defer rpty.mutex.Unlock()
+
+	// Attaching only redraws the visible part of the session, so write the
+	// history above it first.  The session will not exist yet on the first
+	// attach, in which case there is nothing to replay.
+	err := rpty.replayScrollback(ctx, conn)
+	if err != nil {
+		logger.Warn(ctx, "unable to replay tmux scrollback", slog.Error(err))
+		rpty.metrics.WithLabelValues("tmux_scrollback").Add(1)
+	}
+
+	logger.Debug(ctx, "spawning tmux client", slog.F("tmux_id", rpty.id))
+
+	args := []string{
+		// -u tells tmux to use UTF-8 encoding.
+		"-u",
+		// new-session -A attaches to the session or creates it if missing.
+		"new-session", "-A", "-s", rpty.id,
+	}
+	if rpty.command.Dir != "" {
+		args = append(args, "-c", rpty.command.Dir)
+	}
+	// pty.Cmd duplicates Path as the first argument so remove it.
+	args = append(append(args, "--", rpty.command.Path), rpty.command.Args[1:]...)
+
+	// Wrap the command with tmux and tie it to the connection's context.
+	cmd := rpty.execer.PTYCommandContext(ctx, "tmux", rpty.args(args...)...)
+	cmd.Env = rpty.env()
+	cmd.Dir = rpty.command.Dir
+	ptty, process, err := pty.Start(cmd, pty.WithPTYOption(
+		pty.WithSSHRequest(ssh.Pty{
+			Window: ssh.Window{
+				Height: int(height),
+				Width:  int(width),
+			},
+		}),
+	))
+	if err != nil {
+		rpty.metrics.WithLabelValues("tmux_spawn").Add(1)
+		return nil, nil, err
+	}
+
+	// This context lets us abort waiting for the session if the process dies.
+	waitCtx, waitCancel := context.WithCancel(ctx)
+	defer waitCancel()
+
+	// Pipe pty -> conn and close the connection when the process exits.  When
+	// the client exits, our ptty.OutputReader() will return EOF after reading
+	// all process output.
+	go func() {
+		defer waitCancel()
+		defer func() {
+			err := conn.Close()
+			if err != nil {
+				// Log only for debugging since the connection might have already closed
+				// on its own.
+				logger.Debug(ctx, "closed connection with error", slog.Error(err))
+			}
+		}()
+		buffer := make([]byte, 1024)
+		for {
+			read, err := ptty.OutputReader().Read(buffer)
+			if err != nil {
+				// Error is typically a benign EOF, so only log for debugging.
+				if errors.Is(err, io.EOF) {
+					logger.Debug(ctx, "unable to read pty output; tmux might have exited", slog.Error(err))
+				} else {
+					logger.Warn(ctx, "unable to read pty output; tmux might have exited", slog.Error(err))
+					rpty.metrics.WithLabelValues("tmux_output_reader").Add(1)
+				}
+				// Either the session ended, which also stops the server, or only
+				// this client was killed.  In the latter case the session stays
+				// up until the timer or context closes the reconnecting pty.
+				break
+			}
+			part := buffer[:read]
+			_, err = conn.Write(part)
+			if err != nil {
+				// Connection might have been closed.
+				if errors.Unwrap(err).Error() != "endpoint is closed for send" {
+					logger.Warn(ctx, "error writing to active conn", slog.Error(err))
+					rpty.metrics.WithLabelValues("tmux_write").Add(1)
+				}
+				break
+			}
+		}
+	}()
+
+	// Wait for the session to come up before releasing the mutex so the next
+	// attach finds it instead of racing to create it.
+	err = rpty.sendCommand(waitCtx, []string{"has-session", "-t", "=" + rpty.id}, nil)
+	if err != nil {
+		// Log only for debugging since the process might already have closed.
+		closeErr := ptty.Close()
+		if closeErr != nil {
+			logger.Debug(ctx, "closed ptty with error", slog.Error(closeErr))
+		}
+		killErr := process.Kill()
+		if killErr != nil {
+			logger.Debug(ctx, "killed process with error", slog.Error(killErr))
+		}
+		rpty.metrics.WithLabelValues("tmux_wait").Add(1)
+		return nil, nil, err
+	}
+
+	return ptty, process, nil
+}
+
+// replayScrollback writes the history of the session, if it exists, to the
+// connection.
+func (rpty *tmuxReconnectingPTY) replayScrollback(ctx context.Context, conn net.Conn) error {
+	// The trailing colon targets the active pane of the session.
+	target := "=" + rpty.id + ":"
+	out, err := rpty.output(ctx, "display-message", "-p", "-t", target, "#{history_size}")
+	if err != nil {
+		// The session has not been created yet.
+		return nil
+	}
+	lines, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return xerrors.Errorf("parse history size %q: %w", out, err)
+	}
+	if lines == 0 {
+		return nil
+	}
+
+	// -e keeps colors and other attributes, -E -1 stops at the last line of the
+	// history right above the visible part of the pane.
+	out, err = rpty.output(ctx, "capture-pane", "-p", "-e", "-t", target, "-S", strconv.Itoa(-lines), "-E", "-1")
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write([]byte(strings.ReplaceAll(out, "\n", "\r\n")))
+	if err != nil {
+		return xerrors.Errorf("write scrollback to conn: %w", err)
+	}
+	return nil
+}
+
+// sendCommand runs a tmux command against the session's server.  It behaves
+// like the screen backend's sendCommand: the command is retried until it
+// succeeds or fails with an error matching anything in successErrors, the
+// timeout is reached, or the context ends.
+func (rpty *tmuxReconnectingPTY) sendCommand(ctx context.Context, command []string, successErrors []string) error {
+	ctx, cancel := context.WithTimeout(ctx, attachTimeout)
+	defer cancel()
+
+	var lastErr error
+	run := func() bool {
+		out, err := rpty.output(ctx, command...)
+		if err == nil {
+			return true
+		}
+		for _, se := range successErrors {
+			if strings.Contains(out, se) {
+				return true
+			}
+		}
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			lastErr = err
+		}
+		return false
+	}
+
+	// Run immediately.
+	if run() {
+		return nil
+	}
+
+	// Then run on an interval.
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return ctx.Err()
+			}
+			return errors.Join(ctx.Err(), lastErr)
+		case <-ticker.C:
+			if run() {
+				return nil
+			}
+		}
+	}
+}
+
+// output runs a tmux command once against the session's server and returns its
+// combined output.
+func (rpty *tmuxReconnectingPTY) output(ctx context.Context, command ...string) (string, error) {
+	var out bytes.Buffer
+	//nolint:gosec
+	cmd := rpty.execer.CommandContext(ctx, "tmux", rpty.args(command...)...)
+	cmd.Env = rpty.env()
+	cmd.Dir = rpty.command.Dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if err != nil {
+		// Things like "exit status 1" are imprecise so include the output as it
+		// may contain more information ("no server running" for example).
+		return out.String(), xerrors.Errorf("`tmux %s`: %w: %s", strings.Join(command, " "), err, out.String())
+	}
+	return out.String(), nil
+}
+
+// args prefixes the arguments with the flags that select the session's server.
+func (rpty *tmuxReconnectingPTY) args(args ...string) []string {
+	return append([]string{
+		// -L is the name of the server socket.
+		"-L", "coder-" + rpty.id,
+		// -f is the config file, which is only read when the server starts.
+		"-f", rpty.configFile,
+	}, args...)
+}
+
+// env returns the environment for tmux commands.  TMUX is removed since tmux
+// refuses to create sessions when it believes it is running inside of one,
+// which is the case when the agent itself was started from tmux.
+func (rpty *tmuxReconnectingPTY) env() []string {
+	env := make([]string, 0, len(rpty.command.Env)+1)
+	for _, e := range rpty.command.Env {
+		if strings.HasPrefix(e, "TMUX=") {
+			continue
+		}
+		env = append(env, e)
+	}
+	return append(env, "TERM=xterm-256color")
+}
+
+func (rpty *tmuxReconnectingPTY) Wait() {
+	_, _ = rpty.state.waitForState(StateClosing)
+}
+
+func (rpty *tmuxReconnectingPTY) Close(err error) {
+	// The closing state change will be handled by the lifecycle.
+	rpty.state.setState(StateClosing, err)
+}
@@ -0,0 +1,79 @@
+package sessionrecording
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Decoder reads the events of an asciicast v2 recording.
+type Decoder struct {
+	scanner *bufio.Scanner
+	header  Header
+}
+
+// NewDecoder reads the header of the recording from r.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 2*DefaultChunkSize)
+	d := &Decoder{scanner: scanner}
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, xerrors.Errorf("read header: %w", err)
+		}
+		return nil, xerrors.New("recording is empty")
+	}
+	err := json.Unmarshal(scanner.Bytes(), &d.header)
+	if err != nil {
+		return nil, xerrors.Errorf("decode header: %w", err)
+	}
+	if d.header.Version != 2 {
+		return nil, xerrors.Errorf("unsupported asciicast version %d", d.header.Version)
+	}
+	return d, nil
+}
+
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Next returns the next event of the recording, or io.EOF once all events
+// have been read.
+func (d *Decoder) Next() (Event, error) {
+	for d.scanner.Scan() {
+		line := d.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var raw []json.RawMessage
+		err := json.Unmarshal(line, &raw)
+		if err != nil {
+			return Event{}, xerrors.Errorf("decode event: %w", err)
+		}
+		if len(raw) != 3 {
+			return Event{}, xerrors.Errorf("decode event: expected 3 elements, got %d", len(raw))
+		}
+		var (
+			seconds float64
+			event   Event
+		)
+		if err := json.Unmarshal(raw[0], &seconds); err != nil {
+			return Event{}, xerrors.Errorf("decode event time: %w", err)
+		}
+		if err := json.Unmarshal(raw[1], &event.Type); err != nil {
+			return Event{}, xerrors.Errorf("decode event type: %w", err)
+		}
+		if err := json.Unmarshal(raw[2], &event.Data); err != nil {
+			return Event{}, xerrors.Errorf("decode event data: %w", err)
+		}
+		event.Time = time.Duration(seconds * float64(time.Second))
+		return event, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return Event{}, xerrors.Errorf("read event: %w", err)
+	}
+	return Event{}, io.EOF
+}
@@ -0,0 +1,211 @@
+// Package sessionrecording records terminal sessions in the asciicast v2
+// format, see https://docs.asciinema.org/manual/asciicast/v2/.
+package sessionrecording
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/coder/quartz"
+)
+
+const (
+	// DefaultChunkSize is the size at which the recording is split into
+	// chunks for uploading. It is kept well below the maximum message size
+	// of the agent API.
+	DefaultChunkSize = 512 * 1024
+
+	// maxEventSize limits the amount of output stored in a single event so
+	// a chunk never grows much larger than the chunk size.
+	maxEventSize = 32 * 1024
+)
+
+// EventType is the type of an asciicast event.
+type EventType string
+
+const (
+	EventTypeOutput EventType = "o"
+	EventTypeResize EventType = "r"
+)
+
+// Header is the first line of an asciicast v2 recording.
+type Header struct {
+	Version   int   `json:"version"`
+	Width     int   `json:"width"`
+	Height    int   `json:"height"`
+	Timestamp int64 `json:"timestamp,omitempty"`
+}
+
+// Event is a single event of an asciicast v2 recording.
+type Event struct {
+	// Time is the time since the start of the recording.
+	Time time.Duration
+	Type EventType
+	// Data is the output for output events, and "{width}x{height}" for
+	// resize events.
+	Data string
+}
+
+// Chunk is a part of a recording that is ready to be uploaded.
+type Chunk struct {
+	Index     int32
+	StartedAt time.Time
+	Data      []byte
+	// Final is set for the last chunk of a recording, EndedAt is only set
+	// on the final chunk.
+	Final   bool
+	EndedAt time.Time
+}
+
+type Options struct {
+	Clock     quartz.Clock
+	ChunkSize int
+	// Upload is called with the chunks of the recording in order. It is
+	// called while holding the recorder lock and must not block.
+	Upload func(Chunk)
+}
+
+// Recorder records the output of a terminal session. It is safe for
+// concurrent use.
+type Recorder struct {
+	opts      Options
+	startedAt time.Time
+
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	partial []byte
+	index   int32
+	closed  bool
+}
+
+// New starts a recording of a terminal with the given size.
+func New(width, height uint16, opts Options) *Recorder {
+	if opts.Clock == nil {
+		opts.Clock = quartz.NewReal()
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+	if opts.Upload == nil {
+		opts.Upload = func(Chunk) {}
+	}
+	r := &Recorder{
+		opts:      opts,
+		startedAt: opts.Clock.Now(),
+	}
+	header, _ := json.Marshal(Header{
+		Version:   2,
+		Width:     int(width),
+		Height:    int(height),
+		Timestamp: r.startedAt.Unix(),
+	})
+	_, _ = r.buf.Write(header)
+	_ = r.buf.WriteByte('\n')
+	return r
+}
+
+// Write records output of the terminal. Incomplete UTF-8 sequences at the
+// end of p are held back until the next write.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return len(p), nil
+	}
+
+	data := append(r.partial, p...)
+	n := completeUTF8(data)
+	r.partial = bytes.Clone(data[n:])
+	data = data[:n]
+	for len(data) > 0 {
+		size := min(len(data), maxEventSize)
+		if size < len(data) {
+			// Avoid splitting a rune between two events.
+			size = completeUTF8(data[:size])
+			if size == 0 {
+				size = min(len(data), maxEventSize)
+			}
+		}
+		r.writeEvent(EventTypeOutput, string(data[:size]))
+		data = data[size:]
+	}
+	return len(p), nil
+}
+
+// Resize records a change of the terminal size.
+func (r *Recorder) Resize(width, height uint16) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.writeEvent(EventTypeResize, fmt.Sprintf("%dx%d", width, height))
+}
+
+// Close ends the recording and uploads the final chunk. It is safe to call
+// multiple times.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	if len(r.partial) > 0 {
+		r.writeEvent(EventTypeOutput, string(r.partial))
+		r.partial = nil
+	}
+	r.closed = true
+	r.flush(true)
+	return nil
+}
+
+func (r *Recorder) writeEvent(typ EventType, data string) {
+	elapsed := r.opts.Clock.Since(r.startedAt)
+	// Strings with invalid UTF-8 are coerced to valid UTF-8 by the encoder.
+	encoded, _ := json.Marshal(data)
+	_ = r.buf.WriteByte('[')
+	_, _ = r.buf.WriteString(strconv.FormatFloat(elapsed.Seconds(), 'f', 6, 64))
+	_, _ = r.buf.WriteString(`, "`)
+	_, _ = r.buf.WriteString(string(typ))
+	_, _ = r.buf.WriteString(`", `)
+	_, _ = r.buf.Write(encoded)
+	_, _ = r.buf.WriteString("]\n")
+	if r.buf.Len() >= r.opts.ChunkSize {
+		r.flush(false)
+	}
+}
+
+func (r *Recorder) flush(final bool) {
+	chunk := Chunk{
+		Index:     r.index,
+		StartedAt: r.startedAt,
+		Data:      bytes.Clone(r.buf.Bytes()),
+		Final:     final,
+	}
+	if final {
+		chunk.EndedAt = r.opts.Clock.Now()
+	}
+	r.buf.Reset()
+	r.index++
+	r.opts.Upload(chunk)
+}
+
+// completeUTF8 returns the length of the longest prefix of b that does not
+// end with an incomplete UTF-8 sequence.
+func completeUTF8(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if utf8.FullRune(b[i:]) {
+			return len(b)
+		}
+		return i
+	}
+	return len(b)
+}
@@ -0,0 +1,107 @@
+package sessionrecording_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/agent/sessionrecording"
+	"github.com/coder/quartz"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		clock := quartz.NewMock(t)
+		var chunks []sessionrecording.Chunk
+		rec := sessionrecording.New(80, 24, sessionrecording.Options{
+			Clock:  clock,
+			Upload: func(c sessionrecording.Chunk) { chunks = append(chunks, c) },
+		})
+
+		clock.Advance(time.Second)
+		_, err := rec.Write([]byte("hello\r\n"))
+		require.NoError(t, err)
+		clock.Advance(500 * time.Millisecond)
+		rec.Resize(120, 40)
+		// "é" is split across two writes.
+		_, err = rec.Write([]byte("caf\xc3"))
+		require.NoError(t, err)
+		_, err = rec.Write([]byte("\xa9"))
+		require.NoError(t, err)
+		require.NoError(t, rec.Close())
+		require.NoError(t, rec.Close())
+
+		require.Len(t, chunks, 1)
+		require.True(t, chunks[0].Final)
+		require.Equal(t, int32(0), chunks[0].Index)
+		require.Equal(t, clock.Now(), chunks[0].EndedAt)
+
+		dec, err := sessionrecording.NewDecoder(bytes.NewReader(chunks[0].Data))
+		require.NoError(t, err)
+		require.Equal(t, 80, dec.Header().Width)
+		require.Equal(t, 24, dec.Header().Height)
+
+		var events []sessionrecording.Event
+		for {
+			ev, err := dec.Next()
+			if xerrors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			events = append(events, ev)
+		}
+		require.Equal(t, []sessionrecording.Event{
+			{Time: time.Second, Type: sessionrecording.EventTypeOutput, Data: "hello\r\n"},
+			{Time: 1500 * time.Millisecond, Type: sessionrecording.EventTypeResize, Data: "120x40"},
+			{Time: 1500 * time.Millisecond, Type: sessionrecording.EventTypeOutput, Data: "caf"},
+			{Time: 1500 * time.Millisecond, Type: sessionrecording.EventTypeOutput, Data: "é"},
+		}, events)
+	})
+
+	t.Run("Chunks", func(t *testing.T) {
+		t.Parallel()
+
+		var chunks []sessionrecording.Chunk
+		rec := sessionrecording.New(80, 24, sessionrecording.Options{
+			ChunkSize: 1024,
+			Upload:    func(c sessionrecording.Chunk) { chunks = append(chunks, c) },
+		})
+		for i := 0; i < 100; i++ {
+			_, err := rec.Write([]byte(strings.Repeat("x", 100)))
+			require.NoError(t, err)
+		}
+		require.NoError(t, rec.Close())
+
+		require.Greater(t, len(chunks), 1)
+		var all bytes.Buffer
+		for i, c := range chunks {
+			require.Equal(t, int32(i), c.Index)
+			require.Equal(t, i == len(chunks)-1, c.Final)
+			// Chunks are split on event boundaries.
+			require.True(t, len(c.Data) == 0 || bytes.HasSuffix(c.Data, []byte("\n")))
+			_, _ = all.Write(c.Data)
+		}
+
+		dec, err := sessionrecording.NewDecoder(&all)
+		require.NoError(t, err)
+		var count int
+		for {
+			_, err := dec.Next()
+			if xerrors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			count++
+		}
+		require.Equal(t, 100, count)
+	})
+}
@@ -67,6 +67,19 @@ func (r Root) PostgresPort() File {
 	return File(filepath.Join(r.PostgresPath(), "port"))
 }
 
+// SyncPath is the directory that stores the state of `coder sync` sessions.
+func (r Root) SyncPath() string {
+	r.mustNotEmpty()
+	return filepath.Join(string(r), "sync")
+}
+
+// SSHDaemonPath is the directory that stores the socket and logs of the
+// `coder ssh --daemon` background process.
+func (r Root) SSHDaemonPath() string {
+	r.mustNotEmpty()
+	return filepath.Join(string(r), "ssh-daemon")
+}
+
 // File provides convenience methods for interacting with *os.File.
 type File string
 
@@ -51,6 +51,7 @@ type sshConfigOptions struct {
 	hostnameSuffix   string
 	sshOptions       []string
 	disableAutostart bool
+	daemon           bool
 	header           []string
 	headerCommand    string
 	removedKeys      map[string]bool
@@ -102,6 +103,7 @@ func (o sshConfigOptions) equal(other sshConfigOptions) bool {
 	return o.waitEnum == other.waitEnum &&
 		o.userHostPrefix == other.userHostPrefix &&
 		o.disableAutostart == other.disableAutostart &&
+		o.daemon == other.daemon &&
 		o.headerCommand == other.headerCommand &&
 		o.hostnameSuffix == other.hostnameSuffix
 }
@@ -131,6 +133,9 @@ func (o sshConfigOptions) asList() (list []string) {
 	if o.disableAutostart {
 		list = append(list, fmt.Sprintf("disable-autostart: %v", o.disableAutostart))
 	}
+	if o.daemon {
+		list = append(list, fmt.Sprintf("daemon: %v", o.daemon))
+	}
 	for _, opt := range o.sshOptions {
 		list = append(list, fmt.Sprintf("ssh-option: %s", opt))
 	}
@@ -356,6 +361,9 @@ func (r *RootCmd) configSSH() *serpent.Command {
 				if sshConfigOpts.disableAutostart {
 					flags += " --disable-autostart=true"
 				}
+				if sshConfigOpts.daemon {
+					flags += " --daemon"
+				}
 				if coderdConfig.HostnamePrefix != "" {
 					flags += " --ssh-host-prefix " + coderdConfig.HostnamePrefix
 				}
@@ -557,6 +565,13 @@ func (r *RootCmd) configSSH() *serpent.Command {
 			Value:       serpent.BoolOf(&sshConfigOpts.disableAutostart),
 			Default:     "false",
 		},
+		{
+			Flag:        "daemon",
+			Description: "Make the proxy command reuse workspace connections kept open by a background process, so only the first connection to a workspace has to wait for it to be established.",
+			Env:         "CODER_CONFIGSSH_DAEMON",
+			Value:       serpent.BoolOf(&sshConfigOpts.daemon),
+			Default:     "false",
+		},
 		{
 			Flag: "force-unix-filepaths",
 			Env:  "CODER_CONFIGSSH_UNIX_FILEPATHS",
@@ -599,6 +614,9 @@ func sshConfigWriteSectionHeader(w io.Writer, addNewline bool, o sshConfigOption
 	if o.disableAutostart {
 		_, _ = fmt.Fprintf(&ow, "# :%s=%v\n", "disable-autostart", o.disableAutostart)
 	}
+	if o.daemon {
+		_, _ = fmt.Fprintf(&ow, "# :%s=%v\n", "daemon", o.daemon)
+	}
 	for _, opt := range o.sshOptions {
 		_, _ = fmt.Fprintf(&ow, "# :%s=%s\n", "ssh-option", opt)
 	}
@@ -641,6 +659,8 @@ func sshConfigParseLastOptions(r io.Reader) (o sshConfigOptions) {
 				o.sshOptions = append(o.sshOptions, parts[1])
 			case "disable-autostart":
 				o.disableAutostart, _ = strconv.ParseBool(parts[1])
+			case "daemon":
+				o.daemon, _ = strconv.ParseBool(parts[1])
 			case "header":
 				o.header = append(o.header, parts[1])
 			case "header-command":
@@ -638,6 +638,19 @@ func TestConfigSSH_FileWriteAndOptionsFlow(t *testing.T) {
 				regexMatch: `ProxyCommand .* ssh .* --ssh-host-prefix presto\. --hostname-suffix testy %h`,
 			},
 		},
+		{
+			name: "Daemon",
+			args: []string{
+				"--yes",
+				"--daemon",
+			},
+			wantErr:  false,
+			hasAgent: true,
+			wantConfig: wantConfig{
+				ssh:        []string{"# :daemon=true"},
+				regexMatch: `ProxyCommand .* ssh .* --daemon --ssh-host-prefix coder\. %h`,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -0,0 +1,653 @@
+package cli
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pkg/sftp"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+
+	"github.com/coder/coder/v2/agent/agentssh"
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/serpent"
+)
+
+func (r *RootCmd) cp() *serpent.Command {
+	var (
+		recursive        bool
+		resume           bool
+		appearanceConfig codersdk.AppearanceConfig
+	)
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "cp <source> [sources...] <destination>",
+		Short:       "Copy files between your machine and a workspace",
+		Long: "Remote paths are written as <workspace>:<path>, and are relative to the home directory of the workspace user. " +
+			"Either the sources or the destination must be remote. Sources may contain glob patterns. " +
+			"Prefix local paths that contain a colon with \"./\".\n" + FormatExamples(
+			Example{
+				Description: "Copy a file to the home directory of a workspace",
+				Command:     "coder cp ./notes.txt my-workspace:",
+			},
+			Example{
+				Description: "Copy a directory from a workspace",
+				Command:     "coder cp -r my-workspace:project/build ./build",
+			},
+			Example{
+				Description: "Copy all log files from a workspace, resuming interrupted transfers",
+				Command:     "coder cp --resume 'my-workspace:logs/*.log' ./logs",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireRangeArgs(2, -1),
+			r.InitClient(client),
+			initAppearance(client, &appearanceConfig),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			sources := make([]cpPath, 0, len(inv.Args)-1)
+			for _, arg := range inv.Args[:len(inv.Args)-1] {
+				sources = append(sources, parseCpPath(arg))
+			}
+			dest := parseCpPath(inv.Args[len(inv.Args)-1])
+
+			// Exactly one side of the copy must be in the workspace.
+			workspaceName := dest.workspace
+			for _, src := range sources {
+				switch {
+				case src.workspace != "" && dest.workspace != "":
+					return xerrors.New("copying between workspaces isn't supported, either the sources or the destination must be local")
+				case src.workspace == "" && dest.workspace == "":
+					return xerrors.Errorf("either the sources or the destination must be a workspace path, like %q", "my-workspace:"+dest.path)
+				case src.workspace != "" && workspaceName != "" && src.workspace != workspaceName:
+					return xerrors.New("all sources must be in the same workspace")
+				case src.workspace != "":
+					workspaceName = src.workspace
+				}
+			}
+
+			_, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, false, workspaceName)
+			if err != nil {
+				return err
+			}
+			err = cliui.Agent(ctx, inv.Stderr, workspaceAgent.ID, cliui.AgentOptions{
+				Fetch:   client.WorkspaceAgent,
+				Wait:    false,
+				DocsURL: appearanceConfig.DocsURL,
+			})
+			if err != nil {
+				return xerrors.Errorf("await agent: %w", err)
+			}
+
+			opts := &workspacesdk.DialAgentOptions{}
+			if r.verbose {
+				opts.Logger = inv.Logger.AppendSinks(sloghuman.Sink(inv.Stderr)).Leveled(slog.LevelDebug)
+			}
+			if r.disableDirect {
+				opts.BlockEndpoints = true
+			}
+			if !r.disableNetworkTelemetry {
+				opts.EnableTelemetry = true
+			}
+			conn, err := workspacesdk.New(client).DialAgent(ctx, workspaceAgent.ID, opts)
+			if err != nil {
+				return xerrors.Errorf("dial workspace agent: %w", err)
+			}
+			defer conn.Close()
+
+			sshClient, err := conn.SSHClient(ctx)
+			if err != nil {
+				return xerrors.Errorf("connect to workspace agent: %w", err)
+			}
+			defer sshClient.Close()
+
+			sftpClient, err := newCpSFTPClient(sshClient)
+			if err != nil {
+				return err
+			}
+			defer sftpClient.Close()
+
+			local, remote := cpLocalFS{}, cpRemoteFS{client: sftpClient}
+			srcFS, destFS := cpFS(local), cpFS(remote)
+			if dest.workspace == "" {
+				srcFS, destFS = remote, local
+			}
+
+			var srcPaths []string
+			for _, src := range sources {
+				matches, err := expandCpGlob(srcFS, src.path)
+				if err != nil {
+					return err
+				}
+				srcPaths = append(srcPaths, matches...)
+			}
+
+			c := &copier{
+				src:       srcFS,
+				dest:      destFS,
+				destRoot:  dest.path,
+				recursive: recursive,
+				resume:    resume,
+				progress:  newCpProgress(inv.Stderr, isTTYErr(inv)),
+			}
+
+			// Like cp, sources are copied into the destination if it's a
+			// directory, and the destination is created otherwise.
+			destInfo, err := destFS.Stat(dest.path)
+			destIsDir := err == nil && destInfo.IsDir()
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return xerrors.Errorf("stat %s: %w", dest.path, err)
+			}
+			if len(srcPaths) > 1 && !destIsDir {
+				return xerrors.Errorf("destination %q must be an existing directory when copying multiple sources", dest.path)
+			}
+			for _, src := range srcPaths {
+				target := dest.path
+				if destIsDir {
+					target, err = c.destPath(dest.path, srcFS.Base(src))
+					if err != nil {
+						return err
+					}
+				}
+				err := c.copy(ctx, src, target)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:          "recursive",
+			FlagShorthand: "r",
+			Description:   "Copy directories recursively.",
+			Value:         serpent.BoolOf(&recursive),
+		},
+		{
+			Flag:        "resume",
+			Description: "Resume interrupted transfers by appending to destination files that are smaller than their source and were written after the source was last modified. Destination files with the same size and modification time as their source are skipped, and all other files are copied again. Resuming assumes that sources weren't changed in place while the interrupted copy ran.",
+			Value:       serpent.BoolOf(&resume),
+		},
+	}
+	return cmd
+}
+
+// cpPath is a source or destination argument of coder cp.
+type cpPath struct {
+	// workspace is empty for local paths.
+	workspace string
+	path      string
+}
+
+// parseCpPath parses a path argument. Arguments of the form
+// <workspace>:<path> refer to a path in a workspace, where workspace may be
+// written as owner/name.agent.
+func parseCpPath(arg string) cpPath {
+	workspace, p, ok := strings.Cut(arg, ":")
+	if !ok || workspace == "" ||
+		strings.HasPrefix(workspace, ".") || strings.HasPrefix(workspace, "/") ||
+		strings.Count(workspace, "/") > 1 || strings.Contains(workspace, `\`) ||
+		// Windows drive letters, like C:\Users.
+		(runtime.GOOS == "windows" && len(workspace) == 1) {
+		return cpPath{path: arg}
+	}
+	// The SFTP server starts in the home directory, so paths relative to the
+	// home directory are simply relative paths.
+	if p == "~" {
+		p = ""
+	}
+	p = strings.TrimPrefix(p, "~/")
+	if p == "" {
+		p = "."
+	}
+	return cpPath{workspace: workspace, path: p}
+}
+
+// newCpSFTPClient starts an SFTP client on the agent's SFTP subsystem. The
+// session channel is opened by hand rather than with ssh.Session, because the
+// exit status of a subsystem session can't be read from an ssh.Session.
+func newCpSFTPClient(sshClient *gossh.Client) (*sftp.Client, error) {
+	channel, requests, err := sshClient.OpenChannel("session", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("open ssh session: %w", err)
+	}
+	exitStatus := make(chan uint32, 1)
+	go func() {
+		defer close(exitStatus)
+		for req := range requests {
+			if req.Type == "exit-status" && len(req.Payload) >= 4 {
+				select {
+				case exitStatus <- binary.BigEndian.Uint32(req.Payload):
+				default:
+				}
+			}
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+	}()
+
+	ok, err := channel.SendRequest("subsystem", true, gossh.Marshal(struct{ Name string }{Name: "sftp"}))
+	if err == nil && !ok {
+		err = xerrors.New("request rejected")
+	}
+	if err != nil {
+		_ = channel.Close()
+		return nil, xerrors.Errorf("request sftp subsystem: %w", err)
+	}
+	sftpClient, err := sftp.NewClientPipe(channel, channel)
+	if err != nil {
+		_ = channel.Close()
+		// The agent closes the session with a well known exit code if
+		// file transfers are blocked.
+		if status, ok := <-exitStatus; ok && status == agentssh.BlockedFileTransferErrorCode {
+			return nil, xerrors.New("file transfer has been disabled for this workspace by your administrator")
+		}
+		return nil, xerrors.Errorf("start sftp client: %w", err)
+	}
+	return sftpClient, nil
+}
+
+// expandCpGlob expands a source path that contains glob patterns.
+func expandCpGlob(fsys cpFS, pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+	matches, err := fsys.Glob(pattern)
+	if err != nil {
+		return nil, xerrors.Errorf("expand %q: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, xerrors.Errorf("no files match %q", pattern)
+	}
+	return matches, nil
+}
+
+type copier struct {
+	src  cpFS
+	dest cpFS
+	// destRoot is the destination argument. Nothing is written outside of
+	// it.
+	destRoot  string
+	recursive bool
+	resume    bool
+	progress  *cpProgress
+}
+
+func (c *copier) copy(ctx context.Context, src, dest string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	info, err := c.src.Stat(src)
+	if err != nil {
+		return xerrors.Errorf("stat %s: %w", src, err)
+	}
+	if !info.IsDir() {
+		return c.copyFile(src, dest, info)
+	}
+	if !c.recursive {
+		return xerrors.Errorf("%s is a directory, pass --recursive to copy directories", src)
+	}
+
+	err = c.dest.MkdirAll(dest)
+	if err != nil {
+		return xerrors.Errorf("create directory %s: %w", dest, err)
+	}
+	entries, err := c.src.ReadDir(src)
+	if err != nil {
+		return xerrors.Errorf("read directory %s: %w", src, err)
+	}
+	for _, entry := range entries {
+		// Symlinks are skipped rather than followed, to avoid loops and
+		// copying files from outside the source directory.
+		if entry.Mode()&fs.ModeSymlink != 0 {
+			continue
+		}
+		target, err := c.destPath(dest, entry.Name())
+		if err != nil {
+			return err
+		}
+		err = c.copy(ctx, c.src.Join(src, entry.Name()), target)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destPath returns the destination path of the source file name in the
+// destination directory dir. Names come from the source file system, which
+// may be an untrusted workspace, so names that would write outside of the
+// destination are refused (like CVE-2019-6111 in scp).
+func (c *copier) destPath(dir, name string) (string, error) {
+	if name == "" || name == "." || name == ".." ||
+		strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator) {
+		return "", xerrors.Errorf("refusing to copy invalid file name %q", name)
+	}
+	target := c.dest.Join(dir, name)
+	if !c.dest.Within(c.destRoot, target) {
+		return "", xerrors.Errorf("refusing to copy %q outside of the destination %q", target, c.destRoot)
+	}
+	return target, nil
+}
+
+func (c *copier) copyFile(src, dest string, info fs.FileInfo) error {
+	var offset int64
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if c.resume {
+		// SFTP only transfers modification times in seconds.
+		srcModTime := info.ModTime().Truncate(time.Second)
+		destInfo, err := c.dest.Stat(dest)
+		switch {
+		case err != nil:
+		case destInfo.Size() == info.Size() && destInfo.ModTime().Truncate(time.Second).Equal(srcModTime):
+			c.progress.skip(src)
+			return nil
+		// A partial copy was written after the source was last modified.
+		// Anything else means the source changed, and is copied again.
+		case destInfo.Size() < info.Size() && !destInfo.ModTime().Before(srcModTime):
+			offset = destInfo.Size()
+			flags = os.O_WRONLY
+		}
+	}
+
+	srcFile, err := c.src.Open(src)
+	if err != nil {
+		return xerrors.Errorf("open %s: %w", src, err)
+	}
+	defer srcFile.Close()
+	destFile, err := c.dest.OpenFile(dest, flags)
+	if err != nil {
+		return xerrors.Errorf("create %s: %w", dest, err)
+	}
+	defer destFile.Close()
+
+	if offset > 0 {
+		if _, err := srcFile.Seek(offset, io.SeekStart); err != nil {
+			return xerrors.Errorf("seek %s: %w", src, err)
+		}
+		if _, err := destFile.Seek(offset, io.SeekStart); err != nil {
+			return xerrors.Errorf("seek %s: %w", dest, err)
+		}
+	}
+
+	// Progress is counted on the local side of the copy, so that the SFTP
+	// file can use its concurrent implementation of ReadFrom or WriteTo.
+	bar := c.progress.start(src, offset, info.Size())
+	var (
+		r io.Reader = srcFile
+		w io.Writer = destFile
+	)
+	if _, ok := c.dest.(cpRemoteFS); ok {
+		r = io.TeeReader(r, bar)
+	} else {
+		w = io.MultiWriter(w, bar)
+	}
+	_, err = io.Copy(w, r)
+	bar.done()
+	if err != nil {
+		return xerrors.Errorf("copy %s to %s: %w", src, dest, err)
+	}
+	err = destFile.Close()
+	if err != nil {
+		return xerrors.Errorf("close %s: %w", dest, err)
+	}
+	err = c.dest.Chmod(dest, info.Mode().Perm())
+	if err != nil {
+		return xerrors.Errorf("chmod %s: %w", dest, err)
+	}
+	// The modification time is preserved so that --resume can tell whether
+	// the source changed since.
+	err = c.dest.Chtimes(dest, info.ModTime())
+	if err != nil {
+		return xerrors.Errorf("set modification time of %s: %w", dest, err)
+	}
+	return nil
+}
+
+// cpFile is an open file on either side of a copy.
+type cpFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+}
+
+// cpFS abstracts the local and the workspace file system.
+type cpFS interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.FileInfo, error)
+	Open(name string) (cpFile, error)
+	OpenFile(name string, flags int) (cpFile, error)
+	MkdirAll(name string) error
+	Chmod(name string, mode fs.FileMode) error
+	Chtimes(name string, mtime time.Time) error
+	Glob(pattern string) ([]string, error)
+	Join(elem ...string) string
+	Base(name string) string
+	// Within reports whether name is root or inside of it.
+	Within(root, name string) bool
+}
+
+type cpLocalFS struct{}
+
+func (cpLocalFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (cpLocalFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (cpLocalFS) Open(name string) (cpFile, error) {
+	return os.Open(name)
+}
+
+func (cpLocalFS) OpenFile(name string, flags int) (cpFile, error) {
+	return os.OpenFile(name, flags, 0o644)
+}
+
+func (cpLocalFS) MkdirAll(name string) error {
+	return os.MkdirAll(name, 0o755)
+}
+
+func (cpLocalFS) Chmod(name string, mode fs.FileMode) error {
+	if runtime.GOOS == "windows" {
+		// Windows only supports the read-only bit, which is more
+		// confusing than useful here.
+		return nil
+	}
+	return os.Chmod(name, mode)
+}
+
+func (cpLocalFS) Chtimes(name string, mtime time.Time) error {
+	return os.Chtimes(name, mtime, mtime)
+}
+
+func (cpLocalFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (cpLocalFS) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (cpLocalFS) Base(name string) string {
+	return filepath.Base(name)
+}
+
+func (cpLocalFS) Within(root, name string) bool {
+	rel, err := filepath.Rel(root, name)
+	if err != nil {
+		return false
+	}
+	return !filepath.IsAbs(rel) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// cpRemoteFS is the file system of the workspace, accessed over the SFTP
+// subsystem of the agent.
+type cpRemoteFS struct {
+	client *sftp.Client
+}
+
+func (f cpRemoteFS) Stat(name string) (fs.FileInfo, error) {
+	return f.client.Stat(name)
+}
+
+func (f cpRemoteFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	return f.client.ReadDir(name)
+}
+
+func (f cpRemoteFS) Open(name string) (cpFile, error) {
+	return f.client.Open(name)
+}
+
+func (f cpRemoteFS) OpenFile(name string, flags int) (cpFile, error) {
+	return f.client.OpenFile(name, flags)
+}
+
+func (f cpRemoteFS) MkdirAll(name string) error {
+	return f.client.MkdirAll(name)
+}
+
+func (f cpRemoteFS) Chmod(name string, mode fs.FileMode) error {
+	return f.client.Chmod(name, mode)
+}
+
+func (f cpRemoteFS) Chtimes(name string, mtime time.Time) error {
+	return f.client.Chtimes(name, mtime, mtime)
+}
+
+func (f cpRemoteFS) Glob(pattern string) ([]string, error) {
+	return f.client.Glob(pattern)
+}
+
+func (cpRemoteFS) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (cpRemoteFS) Base(name string) string {
+	return path.Base(name)
+}
+
+func (cpRemoteFS) Within(root, name string) bool {
+	root, name = path.Clean(root), path.Clean(name)
+	switch {
+	case root == name:
+		return true
+	case root == "/":
+		return path.IsAbs(name)
+	case root == ".":
+		return !path.IsAbs(name) && name != ".." && !strings.HasPrefix(name, "../")
+	default:
+		return strings.HasPrefix(name, root+"/")
+	}
+}
+
+// cpProgress renders a progress bar for each copied file. Nothing is
+// rendered if the output isn't a terminal.
+type cpProgress struct {
+	w       io.Writer
+	enabled bool
+}
+
+func newCpProgress(w io.Writer, enabled bool) *cpProgress {
+	return &cpProgress{w: w, enabled: enabled}
+}
+
+func (p *cpProgress) skip(name string) {
+	if !p.enabled {
+		return
+	}
+	_, _ = fmt.Fprintf(p.w, "%s: already copied, skipping\n", name)
+}
+
+func (p *cpProgress) start(name string, offset, size int64) *cpProgressBar {
+	return &cpProgressBar{
+		progress: p,
+		name:     name,
+		offset:   offset,
+		copied:   offset,
+		size:     size,
+		started:  time.Now(),
+	}
+}
+
+type cpProgressBar struct {
+	progress *cpProgress
+	name     string
+	offset   int64
+	copied   int64
+	size     int64
+	started  time.Time
+	rendered time.Time
+}
+
+func (b *cpProgressBar) Write(p []byte) (int, error) {
+	b.copied += int64(len(p))
+	if time.Since(b.rendered) >= 100*time.Millisecond {
+		b.render(false)
+	}
+	return len(p), nil
+}
+
+func (b *cpProgressBar) done() {
+	b.render(true)
+}
+
+func (b *cpProgressBar) render(final bool) {
+	if !b.progress.enabled {
+		return
+	}
+	b.rendered = time.Now()
+	percent := int64(100)
+	if b.size > 0 {
+		percent = b.copied * 100 / b.size
+	}
+	var rate string
+	if elapsed := time.Since(b.started).Seconds(); elapsed > 0 {
+		rate = humanize.IBytes(uint64(float64(b.copied-b.offset)/elapsed)) + "/s" //nolint:gosec // Never negative.
+	}
+	end := ""
+	if final {
+		end = "\n"
+	}
+	// \r and \033[K rewrite the current line.
+	_, _ = fmt.Fprintf(b.progress.w, "\r\033[K%s  %3d%%  %s / %s  %s%s",
+		b.name, percent,
+		humanize.IBytes(uint64(b.copied)), //nolint:gosec // Never negative.
+		humanize.IBytes(uint64(b.size)),   //nolint:gosec // Never negative.
+		rate, end,
+	)
+}
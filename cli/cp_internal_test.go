@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopierDestPath(t *testing.T) {
+	t.Parallel()
+
+	c := &copier{dest: cpRemoteFS{}, destRoot: "dest"}
+	for _, name := range []string{"", ".", "..", "a/b", "../x", "/etc"} {
+		_, err := c.destPath("dest/dir", name)
+		require.Error(t, err, "name %q", name)
+	}
+	target, err := c.destPath("dest/dir", "file.txt")
+	require.NoError(t, err)
+	require.Equal(t, "dest/dir/file.txt", target)
+
+	// The joined path must stay in the destination.
+	_, err = c.destPath("other", "file.txt")
+	require.Error(t, err)
+}
+
+func TestCpRemoteFSWithin(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		root, name string
+		within     bool
+	}{
+		{"dest", "dest", true},
+		{"dest", "dest/a/b", true},
+		{"dest", "destination", false},
+		{"dest", "dest/../x", false},
+		{".", "a", true},
+		{".", "../a", false},
+		{".", "/a", false},
+		{"/", "/a", true},
+		{"/home/coder", "/home/coder/a", true},
+		{"/home/coder", "/home/other", false},
+	} {
+		require.Equal(t, tc.within, cpRemoteFS{}.Within(tc.root, tc.name), "%q in %q", tc.name, tc.root)
+	}
+}
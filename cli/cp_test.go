@@ -0,0 +1,184 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/agent"
+	"github.com/coder/coder/v2/agent/agenttest"
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestCp(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Remote paths in these tests are Unix paths")
+	}
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t)
+	_ = agenttest.New(t, client.URL, agentToken)
+	coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	// The agent runs on the same machine as the test, so "remote" paths are
+	// in temporary directories too.
+	remote := func(p string) string {
+		return workspace.Name + ":" + p
+	}
+	run := func(t *testing.T, args ...string) error {
+		t.Helper()
+		inv, root := clitest.New(t, append([]string{"cp"}, args...)...)
+		clitest.SetupConfig(t, client, root)
+		return inv.WithContext(testutil.Context(t, testutil.WaitLong)).Run()
+	}
+
+	t.Run("Upload", func(t *testing.T) {
+		t.Parallel()
+
+		src := filepath.Join(t.TempDir(), "hello.txt")
+		require.NoError(t, os.WriteFile(src, []byte("hello"), 0o600))
+		destDir := t.TempDir()
+
+		err := run(t, src, remote(destDir))
+		require.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(destDir, "hello.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(data))
+	})
+
+	t.Run("DownloadRecursive", func(t *testing.T) {
+		t.Parallel()
+
+		src := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(src, "a", "b"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "a", "b", "c.txt"), []byte("c"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "d.txt"), []byte("d"), 0o600))
+		dest := filepath.Join(t.TempDir(), "copy")
+
+		err := run(t, remote(src), dest)
+		require.ErrorContains(t, err, "pass --recursive")
+
+		err = run(t, "-r", remote(src), dest)
+		require.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(dest, "a", "b", "c.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "c", string(data))
+		data, err = os.ReadFile(filepath.Join(dest, "d.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "d", string(data))
+	})
+
+	t.Run("Glob", func(t *testing.T) {
+		t.Parallel()
+
+		src := t.TempDir()
+		for _, name := range []string{"one.log", "two.log", "three.txt"} {
+			require.NoError(t, os.WriteFile(filepath.Join(src, name), []byte(name), 0o600))
+		}
+		dest := t.TempDir()
+
+		err := run(t, remote(filepath.Join(src, "*.log")), dest)
+		require.NoError(t, err)
+		entries, err := os.ReadDir(dest)
+		require.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		require.ElementsMatch(t, []string{"one.log", "two.log"}, names)
+
+		err = run(t, remote(filepath.Join(src, "*.md")), dest)
+		require.ErrorContains(t, err, "no files match")
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		t.Parallel()
+
+		src := filepath.Join(t.TempDir(), "data.bin")
+		require.NoError(t, os.WriteFile(src, []byte("0123456789"), 0o600))
+		// Pretend an earlier transfer was interrupted halfway. The differing
+		// prefix shows that only the rest of the file was transferred.
+		dest := filepath.Join(t.TempDir(), "data.bin")
+		require.NoError(t, os.WriteFile(dest, []byte("abcde"), 0o600))
+
+		err := run(t, "--resume", src, remote(dest))
+		require.NoError(t, err)
+		data, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, "abcde56789", string(data))
+
+		// Without --resume the file is copied again.
+		err = run(t, src, remote(dest))
+		require.NoError(t, err)
+		data, err = os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, "0123456789", string(data))
+	})
+
+	t.Run("ResumeChangedSource", func(t *testing.T) {
+		t.Parallel()
+
+		src := filepath.Join(t.TempDir(), "data.bin")
+		require.NoError(t, os.WriteFile(src, []byte("0123456789"), 0o600))
+		dest := filepath.Join(t.TempDir(), "data.bin")
+
+		err := run(t, src, remote(dest))
+		require.NoError(t, err)
+		srcInfo, err := os.Stat(src)
+		require.NoError(t, err)
+		destInfo, err := os.Stat(dest)
+		require.NoError(t, err)
+		require.Equal(t, srcInfo.ModTime().Unix(), destInfo.ModTime().Unix())
+
+		// A source of the same size that changed since is copied again.
+		require.NoError(t, os.WriteFile(src, []byte("abcdefghij"), 0o600))
+		later := srcInfo.ModTime().Add(time.Minute)
+		require.NoError(t, os.Chtimes(src, later, later))
+		err = run(t, "--resume", src, remote(dest))
+		require.NoError(t, err)
+		data, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, "abcdefghij", string(data))
+
+		// A partial copy that is older than the source isn't appended to.
+		require.NoError(t, os.WriteFile(dest, []byte("01234"), 0o600))
+		earlier := later.Add(-time.Hour)
+		require.NoError(t, os.Chtimes(dest, earlier, earlier))
+		err = run(t, "--resume", src, remote(dest))
+		require.NoError(t, err)
+		data, err = os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, "abcdefghij", string(data))
+	})
+
+	t.Run("BothLocal", func(t *testing.T) {
+		t.Parallel()
+
+		err := run(t, "a.txt", "b.txt")
+		require.ErrorContains(t, err, "must be a workspace path")
+	})
+}
+
+func TestCp_FileTransferBlocked(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t)
+	_ = agenttest.New(t, client.URL, agentToken, func(o *agent.Options) {
+		o.BlockFileTransfer = true
+	})
+	coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	src := filepath.Join(t.TempDir(), "hello.txt")
+	require.NoError(t, os.WriteFile(src, []byte("hello"), 0o600))
+
+	inv, root := clitest.New(t, "cp", src, workspace.Name+":"+t.TempDir())
+	clitest.SetupConfig(t, client, root)
+	err := inv.WithContext(testutil.Context(t, testutil.WaitLong)).Run()
+	require.ErrorContains(t, err, "file transfer has been disabled")
+}
@@ -147,7 +147,7 @@ func handleRPTY(inv *serpent.Invocation, client *codersdk.Client, args handleRPT
 	var backend string
 	if isOneShotCommand(args.Command) {
 		// If the user specified a command, we'll prefer to use the buffered method.
-		// The screen backend is not well suited for one-shot commands.
+		// The screen and tmux backends are not well suited for one-shot commands.
 		backend = "buffered"
 	}
 
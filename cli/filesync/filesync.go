@@ -0,0 +1,444 @@
+// Package filesync keeps a local directory and a directory in a workspace in
+// sync in both directions.
+//
+// Each sync scans both sides and compares them with the state both sides had
+// after the previous sync, the base. A path that changed on one side only is
+// copied or deleted on the other side. A path that changed on both sides is a
+// conflict, and is left alone until both sides have the same content again.
+// Deleting a file never wins against modifying it, so no changes are lost.
+package filesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// tempSuffix is appended to files while they are copied, so that a partial
+// copy never replaces a file. Files with this suffix aren't synced.
+const tempSuffix = ".coder-sync-tmp"
+
+// ErrUnsafeDeletion is returned by syncs that would delete more than the
+// Engine allows. Neither side is changed by them.
+var ErrUnsafeDeletion = xerrors.New("refusing to delete files")
+
+// Entry is the state of a file or directory on one side.
+type Entry struct {
+	Dir     bool        `json:"dir,omitempty"`
+	Size    int64       `json:"size,omitempty"`
+	ModTime time.Time   `json:"mod_time"`
+	Mode    fs.FileMode `json:"mode"`
+}
+
+func entryFromInfo(info fs.FileInfo) Entry {
+	return Entry{
+		Dir:     info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Mode:    info.Mode(),
+	}
+}
+
+// BaseEntry is the state of a path on both sides after it was last synced.
+type BaseEntry struct {
+	Local  Entry `json:"local"`
+	Remote Entry `json:"remote"`
+}
+
+// Conflict is a path that changed on both sides.
+type Conflict struct {
+	Path string `json:"path"`
+}
+
+// Problem is a path that couldn't be synced.
+type Problem struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Result summarizes a sync.
+type Result struct {
+	// Transferred is the number of files copied.
+	Transferred int `json:"transferred"`
+	// Deleted is the number of files and directories deleted.
+	Deleted   int        `json:"deleted"`
+	Conflicts []Conflict `json:"conflicts"`
+	Problems  []Problem  `json:"problems"`
+}
+
+// Engine syncs two directories.
+type Engine struct {
+	Local  FS
+	Remote FS
+	// Ignore excludes paths from the sync. It may be nil.
+	Ignore *Ignore
+	// Base is the state of both sides after the last sync, by path. It's
+	// updated by Sync and should be persisted between runs, otherwise the
+	// first sync treats every file that differs as a conflict.
+	Base map[string]BaseEntry
+	// AllowEmpty allows syncing a side that is empty, but had files after
+	// the last sync. Otherwise such a sync fails, because it would delete
+	// everything on the other side, and the directory is more likely to have
+	// been lost, e.g. with a rebuilt workspace, than to have been emptied.
+	AllowEmpty bool
+	// MaxDeletions is the number of files and directories a sync may delete.
+	// A sync that would delete more fails without changing either side. Zero
+	// means no limit.
+	MaxDeletions int
+}
+
+type side struct {
+	fs      FS
+	entries map[string]Entry
+	local   bool
+}
+
+func (s side) name() string {
+	if s.local {
+		return "local"
+	}
+	return "remote"
+}
+
+// action copies or deletes a path.
+type action struct {
+	path     string
+	from, to side
+}
+
+// Sync runs a single sync. An error is only returned if either side couldn't
+// be scanned, problems with individual paths are reported in the result.
+func (e *Engine) Sync(ctx context.Context) (Result, error) {
+	var res Result
+	if e.Base == nil {
+		e.Base = map[string]BaseEntry{}
+	}
+	localEntries, err := e.scan(e.Local)
+	if err != nil {
+		return res, xerrors.Errorf("scan local directory: %w", err)
+	}
+	remoteEntries, err := e.scan(e.Remote)
+	if err != nil {
+		return res, xerrors.Errorf("scan remote directory: %w", err)
+	}
+	local := side{fs: e.Local, entries: localEntries, local: true}
+	remote := side{fs: e.Remote, entries: remoteEntries}
+
+	paths := make([]string, 0, len(e.Base))
+	seen := map[string]bool{}
+	for _, entries := range []map[string]Entry{localEntries, remoteEntries} {
+		for p := range entries {
+			if !seen[p] {
+				seen[p] = true
+				paths = append(paths, p)
+			}
+		}
+	}
+	for p := range e.Base {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	// Parents sort before their children, so directories are created before
+	// their contents.
+	sort.Strings(paths)
+
+	// Nothing is changed until all actions are known, so that syncs deleting
+	// too much can be refused.
+	var (
+		actions []action
+		// deletions counts the paths to delete, by whether they are deleted
+		// from the local side.
+		deletions = map[bool]int{}
+	)
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		l, lok := localEntries[p]
+		r, rok := remoteEntries[p]
+		b, bok := e.Base[p]
+		lChanged := changed(l, lok, b.Local, bok)
+		rChanged := changed(r, rok, b.Remote, bok)
+
+		var from, to side
+		switch {
+		case !lChanged && !rChanged:
+			continue
+		case !rChanged || (lChanged && lok && !rok):
+			// Only the local side changed, or the local side was modified
+			// while the remote side was deleted.
+			from, to = local, remote
+		case !lChanged || (rok && !lok):
+			from, to = remote, local
+		case !lok && !rok:
+			delete(e.Base, p)
+			continue
+		case l.Dir && r.Dir:
+			e.Base[p] = BaseEntry{Local: l, Remote: r}
+			continue
+		default:
+			// Both sides changed. That's fine as long as they ended up
+			// with the same content.
+			same := false
+			if !l.Dir && !r.Dir && l.Size == r.Size {
+				same, err = sameContent(e.Local, e.Remote, p)
+				if err != nil {
+					res.Problems = append(res.Problems, Problem{Path: p, Error: err.Error()})
+					continue
+				}
+			}
+			if same {
+				e.Base[p] = BaseEntry{Local: l, Remote: r}
+				continue
+			}
+			res.Conflicts = append(res.Conflicts, Conflict{Path: p})
+			continue
+		}
+		if _, ok := from.entries[p]; !ok {
+			deletions[to.local]++
+		}
+		actions = append(actions, action{path: p, from: from, to: to})
+	}
+	for _, sd := range []side{local, remote} {
+		if !e.AllowEmpty && len(sd.entries) == 0 && deletions[!sd.local] > 0 {
+			return res, xerrors.Errorf("%w: the %s directory is empty, but had %d files and directories after the last sync", ErrUnsafeDeletion, sd.name(), len(e.Base))
+		}
+	}
+	if total := deletions[true] + deletions[false]; e.MaxDeletions > 0 && total > e.MaxDeletions {
+		return res, xerrors.Errorf("%w: the sync would delete %d files and directories, more than the limit of %d", ErrUnsafeDeletion, total, e.MaxDeletions)
+	}
+
+	// Directories are removed after their contents, and files that replace a
+	// directory are copied after the directory was removed.
+	var (
+		dirRemovals []func()
+		deferred    []func()
+	)
+	for _, a := range actions {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		p, from, to := a.path, a.from, a.to
+		src, srcOK := from.entries[p]
+		dst, dstOK := to.entries[p]
+		problem := func(err error) {
+			res.Problems = append(res.Problems, Problem{Path: p, Error: err.Error()})
+		}
+		switch {
+		case !srcOK && dstOK && dst.Dir:
+			dirRemovals = append(dirRemovals, func() {
+				err := to.fs.Remove(p)
+				if err != nil {
+					// The directory contains files that were copied to
+					// it in this sync, so it's kept on both sides.
+					err = from.fs.MkdirAll(p)
+					if err != nil {
+						problem(err)
+						return
+					}
+					info, err := from.fs.Stat(p)
+					if err != nil {
+						problem(err)
+						return
+					}
+					from.entries[p] = entryFromInfo(info)
+					e.setBase(p, from, to)
+					return
+				}
+				res.Deleted++
+				delete(e.Base, p)
+			})
+		case !srcOK:
+			err := to.fs.Remove(p)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				problem(err)
+				continue
+			}
+			res.Deleted++
+			delete(e.Base, p)
+		case src.Dir:
+			if dstOK && !dst.Dir {
+				err := to.fs.Remove(p)
+				if err != nil {
+					problem(err)
+					continue
+				}
+			}
+			err := to.fs.MkdirAll(p)
+			if err != nil {
+				problem(err)
+				continue
+			}
+			e.setBase(p, from, to)
+		default:
+			copyFile := func() {
+				err := copyFile(from.fs, to.fs, p, src.Mode)
+				if err != nil {
+					problem(err)
+					return
+				}
+				res.Transferred++
+				e.setBase(p, from, to)
+			}
+			if dstOK && dst.Dir {
+				// The contents of the directory are deleted in this
+				// sync, so the directory is removed before the copy.
+				dirRemovals = append(dirRemovals, func() {
+					err := to.fs.Remove(p)
+					if err != nil {
+						problem(err)
+						return
+					}
+					deferred = append(deferred, copyFile)
+				})
+				continue
+			}
+			copyFile()
+		}
+	}
+	for i := len(dirRemovals) - 1; i >= 0; i-- {
+		dirRemovals[i]()
+	}
+	for _, fn := range deferred {
+		fn()
+	}
+	return res, nil
+}
+
+// setBase records the state of a path after it was synced. The state of the
+// source side is taken from the scan, so that changes made while the path was
+// copied are picked up by the next sync.
+func (e *Engine) setBase(p string, from, to side) {
+	src := from.entries[p]
+	dst := src
+	if info, err := to.fs.Stat(p); err == nil {
+		dst = entryFromInfo(info)
+	}
+	if from.local {
+		e.Base[p] = BaseEntry{Local: src, Remote: dst}
+	} else {
+		e.Base[p] = BaseEntry{Local: dst, Remote: src}
+	}
+}
+
+func (e *Engine) scan(fsys FS) (map[string]Entry, error) {
+	entries := map[string]Entry{}
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		infos, err := fsys.ReadDir(dir)
+		if err != nil {
+			return xerrors.Errorf("read directory %q: %w", dir, err)
+		}
+		for _, info := range infos {
+			// Names may come from an untrusted workspace, and must not
+			// refer to paths outside of the synced directory.
+			if n := info.Name(); n == "" || n == "." || n == ".." || strings.ContainsRune(n, '/') {
+				return xerrors.Errorf("read directory %q: invalid file name %q", dir, n)
+			}
+			name := path.Join(dir, info.Name())
+			// Symlinks and special files are skipped.
+			if !info.Mode().IsRegular() && !info.IsDir() {
+				continue
+			}
+			if strings.HasSuffix(name, tempSuffix) || e.Ignore.Match(name, info.IsDir()) {
+				continue
+			}
+			entries[name] = entryFromInfo(info)
+			if info.IsDir() {
+				err := walk(name)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return entries, walk("")
+}
+
+// changed reports whether an entry differs from its base. Only the
+// existence of directories is compared, because their modification time
+// changes with their contents.
+func changed(e Entry, ok bool, base Entry, baseOK bool) bool {
+	if ok != baseOK {
+		return true
+	}
+	if !ok {
+		return false
+	}
+	if e.Dir || base.Dir {
+		return e.Dir != base.Dir
+	}
+	return e.Size != base.Size || !e.ModTime.Equal(base.ModTime)
+}
+
+// copyFile copies a file through a temporary file, so that the destination is
+// replaced at once.
+func copyFile(from, to FS, p string, mode fs.FileMode) error {
+	err := to.MkdirAll(path.Dir(p))
+	if err != nil {
+		return xerrors.Errorf("create parent directory: %w", err)
+	}
+	r, err := from.Open(p)
+	if err != nil {
+		return xerrors.Errorf("open: %w", err)
+	}
+	defer r.Close()
+	tmp := path.Join(path.Dir(p), "."+path.Base(p)+tempSuffix)
+	w, err := to.Create(tmp)
+	if err != nil {
+		return xerrors.Errorf("create: %w", err)
+	}
+	_, err = io.Copy(w, r)
+	closeErr := w.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = to.Chmod(tmp, mode.Perm())
+	}
+	if err == nil {
+		err = to.Rename(tmp, p)
+	}
+	if err != nil {
+		_ = to.Remove(tmp)
+		return xerrors.Errorf("copy: %w", err)
+	}
+	return nil
+}
+
+func sameContent(a, b FS, p string) (bool, error) {
+	hashA, err := hashFile(a, p)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := hashFile(b, p)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(hashA, hashB), nil
+}
+
+func hashFile(fsys FS, p string) ([]byte, error) {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
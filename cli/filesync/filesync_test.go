@@ -0,0 +1,312 @@
+package filesync_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/cli/filesync"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestEngine(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (engine *filesync.Engine, local, remote string) {
+		local, remote = t.TempDir(), t.TempDir()
+		return &filesync.Engine{
+			Local:  filesync.LocalFS{Root: local},
+			Remote: filesync.LocalFS{Root: remote},
+		}, local, remote
+	}
+	write := func(t *testing.T, root, name, content string) {
+		t.Helper()
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+		// Make sure the modification time changes, even on file systems
+		// with a coarse resolution.
+		mtime := time.Now().Add(time.Duration(len(content)) * time.Second)
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+	}
+	read := func(t *testing.T, root, name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		return string(data)
+	}
+	sync := func(t *testing.T, engine *filesync.Engine) filesync.Result {
+		t.Helper()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		res, err := engine.Sync(ctx)
+		require.NoError(t, err)
+		require.Empty(t, res.Problems)
+		return res
+	}
+
+	t.Run("BothDirections", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+
+		write(t, local, "a/local.txt", "local")
+		write(t, remote, "b/remote.txt", "remote")
+		res := sync(t, engine)
+		require.Equal(t, 2, res.Transferred)
+		require.Equal(t, "local", read(t, remote, "a/local.txt"))
+		require.Equal(t, "remote", read(t, local, "b/remote.txt"))
+
+		// Nothing changed, so nothing is copied.
+		res = sync(t, engine)
+		require.Zero(t, res.Transferred)
+
+		write(t, remote, "a/local.txt", "changed remotely")
+		res = sync(t, engine)
+		require.Equal(t, 1, res.Transferred)
+		require.Equal(t, "changed remotely", read(t, local, "a/local.txt"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+
+		write(t, local, "dir/sub/file.txt", "file")
+		write(t, local, "keep.txt", "keep")
+		sync(t, engine)
+
+		require.NoError(t, os.RemoveAll(filepath.Join(local, "dir")))
+		res := sync(t, engine)
+		require.Equal(t, 3, res.Deleted)
+		require.NoDirExists(t, filepath.Join(remote, "dir"))
+		require.FileExists(t, filepath.Join(remote, "keep.txt"))
+	})
+
+	t.Run("EmptyDirectory", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+
+		write(t, local, "dir/file.txt", "file")
+		write(t, local, "keep.txt", "keep")
+		sync(t, engine)
+
+		// A remote directory that lost its contents, e.g. because the
+		// workspace was rebuilt, doesn't empty the local one.
+		require.NoError(t, os.RemoveAll(remote))
+		require.NoError(t, os.Mkdir(remote, 0o755))
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := engine.Sync(ctx)
+		require.ErrorIs(t, err, filesync.ErrUnsafeDeletion)
+		require.ErrorContains(t, err, "remote directory is empty")
+		require.FileExists(t, filepath.Join(local, "dir", "file.txt"))
+
+		engine.AllowEmpty = true
+		res := sync(t, engine)
+		require.Equal(t, 3, res.Deleted)
+		require.NoFileExists(t, filepath.Join(local, "keep.txt"))
+	})
+
+	t.Run("MaxDeletions", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+		engine.MaxDeletions = 2
+
+		write(t, local, "dir/a.txt", "a")
+		write(t, local, "dir/b.txt", "b")
+		write(t, local, "keep.txt", "keep")
+		sync(t, engine)
+
+		require.NoError(t, os.RemoveAll(filepath.Join(local, "dir")))
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := engine.Sync(ctx)
+		require.ErrorIs(t, err, filesync.ErrUnsafeDeletion)
+		require.ErrorContains(t, err, "would delete 3 files and directories")
+		require.FileExists(t, filepath.Join(remote, "dir", "a.txt"))
+
+		engine.MaxDeletions = 3
+		res := sync(t, engine)
+		require.Equal(t, 3, res.Deleted)
+		require.NoDirExists(t, filepath.Join(remote, "dir"))
+	})
+
+	t.Run("DeleteLosesToModify", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+		// Everything is deleted locally.
+		engine.AllowEmpty = true
+
+		write(t, local, "dir/file.txt", "file")
+		sync(t, engine)
+
+		require.NoError(t, os.RemoveAll(filepath.Join(local, "dir")))
+		write(t, remote, "dir/file.txt", "modified")
+		write(t, remote, "dir/new.txt", "new")
+		res := sync(t, engine)
+		require.Empty(t, res.Conflicts)
+		require.Equal(t, "modified", read(t, local, "dir/file.txt"))
+		require.Equal(t, "new", read(t, local, "dir/new.txt"))
+
+		// The next sync doesn't change anything.
+		res = sync(t, engine)
+		require.Zero(t, res.Transferred)
+		require.Zero(t, res.Deleted)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+
+		write(t, local, "file.txt", "base")
+		sync(t, engine)
+
+		write(t, local, "file.txt", "local change")
+		write(t, remote, "file.txt", "remote change!")
+		res := sync(t, engine)
+		require.Equal(t, []filesync.Conflict{{Path: "file.txt"}}, res.Conflicts)
+		require.Equal(t, "local change", read(t, local, "file.txt"))
+		require.Equal(t, "remote change!", read(t, remote, "file.txt"))
+
+		// Deleting one of the copies resolves the conflict.
+		require.NoError(t, os.Remove(filepath.Join(remote, "file.txt")))
+		res = sync(t, engine)
+		require.Empty(t, res.Conflicts)
+		require.Equal(t, "local change", read(t, remote, "file.txt"))
+	})
+
+	t.Run("InitialIdenticalFiles", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+
+		write(t, local, "same.txt", "same")
+		write(t, remote, "same.txt", "same")
+		write(t, local, "different.txt", "local")
+		write(t, remote, "different.txt", "remote")
+		res := sync(t, engine)
+		require.Equal(t, []filesync.Conflict{{Path: "different.txt"}}, res.Conflicts)
+		require.Zero(t, res.Transferred)
+	})
+
+	t.Run("FileReplacesDirectory", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+
+		write(t, local, "thing/file.txt", "file")
+		sync(t, engine)
+
+		require.NoError(t, os.RemoveAll(filepath.Join(local, "thing")))
+		write(t, local, "thing", "now a file")
+		sync(t, engine)
+		require.Equal(t, "now a file", read(t, remote, "thing"))
+	})
+
+	t.Run("Ignore", func(t *testing.T) {
+		t.Parallel()
+		engine, local, remote := setup(t)
+
+		ignore, err := filesync.ReadIgnore(strings.NewReader("# Build output\nnode_modules/\n*.log\n/build/out\n"))
+		require.NoError(t, err)
+		engine.Ignore = ignore
+
+		write(t, local, "node_modules/pkg/index.js", "js")
+		write(t, local, "src/debug.log", "log")
+		write(t, local, "build/out", "out")
+		write(t, local, "src/build/out", "nested")
+		write(t, local, "src/main.go", "go")
+		sync(t, engine)
+
+		require.NoDirExists(t, filepath.Join(remote, "node_modules"))
+		require.NoFileExists(t, filepath.Join(remote, "src", "debug.log"))
+		require.NoFileExists(t, filepath.Join(remote, "build", "out"))
+		require.FileExists(t, filepath.Join(remote, "src", "build", "out"))
+		require.FileExists(t, filepath.Join(remote, "src", "main.go"))
+	})
+
+	t.Run("InvalidRemoteName", func(t *testing.T) {
+		t.Parallel()
+		local, remote := t.TempDir(), t.TempDir()
+		write(t, remote, "escape.txt", "escape")
+		engine := &filesync.Engine{
+			Local:  filesync.LocalFS{Root: filepath.Join(local, "sync")},
+			Remote: renameFS{FS: filesync.LocalFS{Root: remote}, names: map[string]string{"escape.txt": "../escape.txt"}},
+		}
+		require.NoError(t, os.Mkdir(filepath.Join(local, "sync"), 0o755))
+
+		_, err := engine.Sync(testutil.Context(t, testutil.WaitShort))
+		require.ErrorContains(t, err, "invalid file name")
+		require.NoFileExists(t, filepath.Join(local, "escape.txt"))
+	})
+
+	t.Run("LocalPathOutsideRoot", func(t *testing.T) {
+		t.Parallel()
+		local := t.TempDir()
+		fsys := filesync.LocalFS{Root: filepath.Join(local, "sync")}
+		require.NoError(t, os.Mkdir(fsys.Root, 0o755))
+
+		for _, name := range []string{"../escape.txt", "a/../../escape.txt", "/escape.txt"} {
+			_, err := fsys.Create(name)
+			require.Error(t, err, name)
+			require.Error(t, fsys.MkdirAll(name), name)
+		}
+		require.NoFileExists(t, filepath.Join(local, "escape.txt"))
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+		engine, local, _ := setup(t)
+
+		write(t, local, "file.txt", "file")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := engine.Sync(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestNewIgnore(t *testing.T) {
+	t.Parallel()
+
+	_, err := filesync.NewIgnore("!keep.txt")
+	require.ErrorContains(t, err, "isn't supported")
+	_, err = filesync.NewIgnore("[")
+	require.ErrorContains(t, err, "invalid ignore pattern")
+
+	ignore, err := filesync.NewIgnore("tmp/")
+	require.NoError(t, err)
+	require.True(t, ignore.Match("a/tmp", true))
+	require.False(t, ignore.Match("a/tmp", false))
+
+	var nilIgnore *filesync.Ignore
+	require.False(t, nilIgnore.Match("anything", false))
+}
+
+// renameFS renames directory entries, like a malicious SFTP server might.
+type renameFS struct {
+	filesync.FS
+	names map[string]string
+}
+
+func (r renameFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	infos, err := r.FS.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range infos {
+		if newName, ok := r.names[info.Name()]; ok {
+			infos[i] = renamedInfo{FileInfo: info, name: newName}
+		}
+	}
+	return infos, nil
+}
+
+type renamedInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (r renamedInfo) Name() string {
+	return r.name
+}
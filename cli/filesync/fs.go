@@ -0,0 +1,173 @@
+package filesync
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/xerrors"
+)
+
+// FS is one side of a sync. Paths are slash separated and relative to the
+// root of the side.
+type FS interface {
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir must not follow symlinks, so that they can be skipped.
+	ReadDir(name string) ([]fs.FileInfo, error)
+	Open(name string) (io.ReadCloser, error)
+	Create(name string) (io.WriteCloser, error)
+	// Rename must replace newname if it exists.
+	Rename(oldname, newname string) error
+	Remove(name string) error
+	MkdirAll(name string) error
+	Chmod(name string, mode fs.FileMode) error
+}
+
+// LocalFS is a directory on the local machine.
+type LocalFS struct {
+	Root string
+}
+
+var _ FS = LocalFS{}
+
+// path returns the path of name on the local machine. Names come from both
+// sides of the sync, so names that leave Root are refused.
+func (l LocalFS) path(name string) (string, error) {
+	if name == "" {
+		return l.Root, nil
+	}
+	p := filepath.FromSlash(name)
+	if !filepath.IsLocal(p) {
+		return "", xerrors.Errorf("path %q is outside of %q", name, l.Root)
+	}
+	return filepath.Join(l.Root, p), nil
+}
+
+func (l LocalFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(p)
+}
+
+func (l LocalFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	p, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// The file was removed since reading the directory.
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (l LocalFS) Open(name string) (io.ReadCloser, error) {
+	p, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (l LocalFS) Create(name string) (io.WriteCloser, error) {
+	p, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(p)
+}
+
+func (l LocalFS) Rename(oldname, newname string) error {
+	oldpath, err := l.path(oldname)
+	if err != nil {
+		return err
+	}
+	newpath, err := l.path(newname)
+	if err != nil {
+		return err
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+func (l LocalFS) Remove(name string) error {
+	p, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+func (l LocalFS) MkdirAll(name string) error {
+	p, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(p, 0o755)
+}
+
+func (l LocalFS) Chmod(name string, mode fs.FileMode) error {
+	p, err := l.path(name)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(p, mode)
+}
+
+// SFTPFS is a directory in a workspace, accessed over the SFTP subsystem of
+// the agent.
+type SFTPFS struct {
+	Client *sftp.Client
+	Root   string
+}
+
+var _ FS = SFTPFS{}
+
+func (s SFTPFS) path(name string) string {
+	return path.Join(s.Root, name)
+}
+
+func (s SFTPFS) Stat(name string) (fs.FileInfo, error) {
+	return s.Client.Stat(s.path(name))
+}
+
+func (s SFTPFS) ReadDir(name string) ([]fs.FileInfo, error) {
+	return s.Client.ReadDir(s.path(name))
+}
+
+func (s SFTPFS) Open(name string) (io.ReadCloser, error) {
+	return s.Client.Open(s.path(name))
+}
+
+func (s SFTPFS) Create(name string) (io.WriteCloser, error) {
+	return s.Client.Create(s.path(name))
+}
+
+func (s SFTPFS) Rename(oldname, newname string) error {
+	return s.Client.PosixRename(s.path(oldname), s.path(newname))
+}
+
+func (s SFTPFS) Remove(name string) error {
+	return s.Client.Remove(s.path(name))
+}
+
+func (s SFTPFS) MkdirAll(name string) error {
+	return s.Client.MkdirAll(s.path(name))
+}
+
+func (s SFTPFS) Chmod(name string, mode fs.FileMode) error {
+	return s.Client.Chmod(s.path(name), mode)
+}
@@ -0,0 +1,94 @@
+package filesync
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// IgnoreFile is the name of the file in the root of the local directory that
+// lists patterns of paths that aren't synced.
+const IgnoreFile = ".codersyncignore"
+
+// Ignore matches paths against a list of patterns. The syntax is a subset of
+// .gitignore: patterns without a slash match the name of a file or directory
+// at any depth, patterns with a slash match the path relative to the root,
+// and patterns ending with a slash only match directories. Negation isn't
+// supported.
+type Ignore struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	pattern  string
+	anchored bool
+	dirOnly  bool
+}
+
+// NewIgnore returns an Ignore for the given patterns. Empty patterns and
+// comments starting with # are skipped.
+func NewIgnore(patterns ...string) (*Ignore, error) {
+	ig := &Ignore{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		if strings.HasPrefix(p, "!") {
+			return nil, xerrors.Errorf("negated ignore pattern %q isn't supported", p)
+		}
+		var ip ignorePattern
+		if strings.HasSuffix(p, "/") {
+			ip.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		if strings.Contains(p, "/") {
+			ip.anchored = true
+			p = strings.TrimPrefix(p, "/")
+		}
+		// Validate the pattern once, so that Match can ignore errors.
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, xerrors.Errorf("invalid ignore pattern %q: %w", p, err)
+		}
+		ip.pattern = p
+		ig.patterns = append(ig.patterns, ip)
+	}
+	return ig, nil
+}
+
+// ReadIgnore parses an ignore file.
+func ReadIgnore(r io.Reader, extra ...string) (*Ignore, error) {
+	patterns := append([]string{}, extra...)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("read ignore file: %w", err)
+	}
+	return NewIgnore(patterns...)
+}
+
+// Match reports whether the slash separated path, relative to the root,
+// is ignored.
+func (ig *Ignore) Match(name string, isDir bool) bool {
+	if ig == nil {
+		return false
+	}
+	for _, p := range ig.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		subject := path.Base(name)
+		if p.anchored {
+			subject = name
+		}
+		if ok, _ := path.Match(p.pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
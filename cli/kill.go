@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/serpent"
+)
+
+func (r *RootCmd) kill() *serpent.Command {
+	var (
+		signal           string
+		appearanceConfig codersdk.AppearanceConfig
+	)
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "kill <workspace> <pid> [pids...]",
+		Short:       "Send a signal to processes in a workspace",
+		Long: "Use \"coder ps\" to find the IDs of processes. Only KILL is supported in Windows workspaces.\n" + FormatExamples(
+			Example{
+				Description: "Ask a process to exit",
+				Command:     "coder kill my-workspace 4242",
+			},
+			Example{
+				Description: "Kill processes that don't exit",
+				Command:     "coder kill my-workspace 4242 4243 --signal KILL",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireRangeArgs(2, -1),
+			r.InitClient(client),
+			initAppearance(client, &appearanceConfig),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			pids := make([]int32, 0, len(inv.Args)-1)
+			for _, arg := range inv.Args[1:] {
+				pid, err := strconv.ParseInt(arg, 10, 32)
+				if err != nil || pid <= 0 {
+					return xerrors.Errorf("invalid process ID %q", arg)
+				}
+				pids = append(pids, int32(pid)) //nolint:gosec // Parsed as a 32-bit integer.
+			}
+
+			conn, err := r.dialWorkspaceAgent(ctx, inv, client, appearanceConfig, inv.Args[0])
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			// Signal every process before failing, like kill does.
+			var failed int
+			for _, pid := range pids {
+				err := conn.SignalProcess(ctx, pid, workspacesdk.SignalProcessRequest{Signal: signal})
+				if err != nil {
+					failed++
+					cliui.Errorf(inv.Stderr, "Failed to signal process %d: %s", pid, err)
+					continue
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "Sent %s to process %d\n", signal, pid)
+			}
+			if failed > 0 {
+				return xerrors.Errorf("failed to signal %d of %d processes", failed, len(pids))
+			}
+			return nil
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:          "signal",
+			FlagShorthand: "s",
+			Description:   "The signal to send, like TERM, INT or KILL.",
+			Default:       "TERM",
+			Value:         serpent.StringOf(&signal),
+		},
+	}
+	return cmd
+}
@@ -60,6 +60,13 @@ func (r *RootCmd) logout() *serpent.Command {
 				errors = append(errors, xerrors.Errorf("remove organization file: %w", err))
 			}
 
+			// The SSH daemon keeps connections open with the session that was
+			// just removed.
+			err = stopSSHDaemon(inv.Context(), config)
+			if err != nil {
+				errors = append(errors, xerrors.Errorf("stop ssh daemon: %w", err))
+			}
+
 			if len(errors) > 0 {
 				var errorStringBuilder strings.Builder
 				for _, err := range errors {
@@ -29,6 +29,7 @@ import (
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/healthsdk"
 	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/tailnet"
 )
 
 type pingSummary struct {
@@ -82,6 +83,58 @@ func (s *pingSummary) Write(w io.Writer) {
 	_, _ = fmt.Fprint(w, out)
 }
 
+type pathHistoryRow struct {
+	Time    string `table:"time,nosort"`
+	Peer    string `table:"peer"`
+	Event   string `table:"event"`
+	Path    string `table:"path"`
+	Latency string `table:"latency"`
+	NAT     string `table:"nat"`
+}
+
+// writePathHistory writes the history of the paths used by the agent to reach
+// its peers, oldest first.
+func writePathHistory(w io.Writer, events []tailnet.PathEvent, utc bool) error {
+	if len(events) == 0 {
+		_, _ = fmt.Fprintln(w, "The workspace agent has not recorded any connections yet.")
+		return nil
+	}
+	rows := make([]pathHistoryRow, 0, len(events))
+	for _, e := range events {
+		t := e.Time.Local()
+		if utc {
+			t = t.UTC()
+		}
+		row := pathHistoryRow{
+			Time:  t.Format(time.RFC3339),
+			Peer:  e.PeerID.String(),
+			Event: string(e.Type),
+			NAT:   string(e.NATType),
+		}
+		switch {
+		case e.Type == tailnet.PathEventTypeGone:
+		case e.P2P:
+			row.Path = fmt.Sprintf("p2p via %s", e.Endpoint)
+		default:
+			derpName := e.DERPRegionName
+			if derpName == "" {
+				derpName = "unknown"
+			}
+			row.Path = fmt.Sprintf("proxied via DERP(%s)", derpName)
+		}
+		if e.Type == tailnet.PathEventTypeLatency {
+			row.Latency = time.Duration(e.LatencyMilliseconds * float64(time.Millisecond)).Round(time.Millisecond).String()
+		}
+		rows = append(rows, row)
+	}
+	out, err := cliui.DisplayTable(rows, "", nil)
+	if err != nil {
+		return xerrors.Errorf("display path history: %w", err)
+	}
+	_, _ = fmt.Fprintln(w, out)
+	return nil
+}
+
 func (r *RootCmd) ping() *serpent.Command {
 	var (
 		pingNum          int64
@@ -89,6 +142,7 @@ func (r *RootCmd) ping() *serpent.Command {
 		pingWait         time.Duration
 		pingTimeLocal    bool
 		pingTimeUTC      bool
+		pingHistory      bool
 		appearanceConfig codersdk.AppearanceConfig
 	)
 
@@ -147,6 +201,19 @@ func (r *RootCmd) ping() *serpent.Command {
 			}
 			defer conn.Close()
 
+			if pingHistory {
+				spin.Stop()
+				history, err := conn.PathHistory(ctx)
+				if err != nil {
+					var sdkErr *codersdk.Error
+					if errors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+						return xerrors.New("The workspace agent is outdated and doesn't record its connection history")
+					}
+					return xerrors.Errorf("get path history from agent: %w", err)
+				}
+				return writePathHistory(inv.Stdout, history.Events, pingTimeUTC)
+			}
+
 			derpMap := conn.DERPMap()
 
 			diagCtx, diagCancel := context.WithTimeout(inv.Context(), 30*time.Second)
@@ -344,6 +411,11 @@ func (r *RootCmd) ping() *serpent.Command {
 			Description: "Show the response time of each pong in UTC (implies --time).",
 			Value:       serpent.BoolOf(&pingTimeUTC),
 		},
+		{
+			Flag:        "history",
+			Description: "Show the history of the paths used by the workspace agent to reach its peers, including whether they were direct (p2p) or relayed through DERP, latency samples and the NAT type of the agent, instead of pinging.",
+			Value:       serpent.BoolOf(&pingHistory),
+		},
 	}
 	return cmd
 }
@@ -1,10 +1,14 @@
 package cli_test
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/v2/agent/agenttest"
 	"github.com/coder/coder/v2/cli/clitest"
@@ -125,4 +129,44 @@ func TestPing(t *testing.T) {
 			})
 		}
 	})
+	t.Run("History", func(t *testing.T) {
+		t.Parallel()
+
+		client, workspace, agentToken := setupWorkspaceForAgent(t)
+		_ = agenttest.New(t, client.URL, agentToken)
+		_ = coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		// Keep a connection open so the agent records a path to it.
+		inv, root := clitest.New(t, "ping", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		inv.Stdin = pty.Input()
+		inv.Stderr = pty.Output()
+		inv.Stdout = pty.Output()
+		cmdDone := tGo(t, func() {
+			err := inv.WithContext(ctx).Run()
+			assert.NoError(t, err)
+		})
+		pty.ExpectMatch("pong from " + workspace.Name)
+
+		require.Eventually(t, func() bool {
+			inv, root := clitest.New(t, "ping", "--history", workspace.Name)
+			clitest.SetupConfig(t, client, root)
+			var stdout bytes.Buffer
+			inv.Stdout = &stdout
+			inv.Stderr = io.Discard
+			err := inv.WithContext(ctx).Run()
+			if !assert.NoError(t, err) {
+				return false
+			}
+			out := stdout.String()
+			return strings.Contains(out, "PATH") && strings.Contains(out, "via")
+		}, testutil.WaitLong, testutil.IntervalSlow)
+
+		cancel()
+		<-cmdDone
+	})
 }
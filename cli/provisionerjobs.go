@@ -41,7 +41,7 @@ func (r *RootCmd) provisionerJobsList() *serpent.Command {
 		client     = new(codersdk.Client)
 		orgContext = NewOrganizationContext()
 		formatter  = cliui.NewOutputFormatter(
-			cliui.TableFormat([]provisionerJobRow{}, []string{"created at", "id", "type", "template display name", "status", "queue", "tags"}),
+			cliui.TableFormat([]provisionerJobRow{}, []string{"created at", "id", "type", "template display name", "status", "priority", "queue", "tags"}),
 			cliui.JSONFormat(),
 		)
 		status []string
@@ -167,6 +167,8 @@ func (r *RootCmd) provisionerJobsCancel() *serpent.Command {
 			case codersdk.ProvisionerJobTypeWorkspaceBuild:
 				_, _ = fmt.Fprintf(inv.Stdout, "Canceling workspace build job %s...\n", job.ID)
 				err = client.CancelWorkspaceBuild(ctx, ptr.NilToEmpty(job.Input.WorkspaceBuildID))
+			case codersdk.ProvisionerJobTypeWorkspaceDriftCheck:
+				return xerrors.Errorf("workspace drift check jobs cannot be canceled")
 			}
 			if err != nil {
 				return xerrors.Errorf("cancel provisioner job: %w", err)
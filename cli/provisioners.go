@@ -2,7 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/cli/cliui"
@@ -21,6 +23,7 @@ func (r *RootCmd) Provisioners() *serpent.Command {
 		Children: []*serpent.Command{
 			r.provisionerList(),
 			r.provisionerJobs(),
+			r.provisionerDrain(),
 		},
 	}
 
@@ -105,3 +108,99 @@ func (r *RootCmd) provisionerList() *serpent.Command {
 
 	return cmd
 }
+
+func (r *RootCmd) provisionerDrain() *serpent.Command {
+	var (
+		client     = new(codersdk.Client)
+		orgContext = NewOrganizationContext()
+		wait       bool
+	)
+
+	cmd := &serpent.Command{
+		Use:   "drain <name>",
+		Short: "Stop a provisioner daemon from acquiring jobs, so it exits once its current job completes",
+		Long: "The daemon stops acquiring new jobs straight away. Its current job, if any, runs to completion before the daemon exits. " +
+			"A daemon that reconnects afterwards, for example because it was restarted, acquires jobs again.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+
+			org, err := orgContext.Selected(inv, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+
+			daemons, err := client.OrganizationProvisionerDaemons(ctx, org.ID, &codersdk.OrganizationProvisionerDaemonsOptions{
+				Name: inv.Args[0],
+			})
+			if err != nil {
+				return xerrors.Errorf("get provisioner daemon: %w", err)
+			}
+			switch len(daemons) {
+			case 0:
+				return xerrors.Errorf("provisioner daemon %q not found in organization %q", inv.Args[0], org.HumanName())
+			case 1:
+			default:
+				return xerrors.Errorf("found %d provisioner daemons named %q in organization %q", len(daemons), inv.Args[0], org.HumanName())
+			}
+			daemon := daemons[0]
+
+			err = client.DrainProvisionerDaemon(ctx, org.ID, daemon.ID)
+			if err != nil {
+				return xerrors.Errorf("drain provisioner daemon: %w", err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Provisioner daemon %s is draining\n", cliui.Keyword(daemon.Name))
+			if !wait {
+				return nil
+			}
+
+			// The daemon is given no new jobs once drained, so it is done
+			// as soon as it has no current job.
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			var waitingFor uuid.UUID
+			for {
+				if daemon.CurrentJob == nil || (daemon.Status != nil && *daemon.Status == codersdk.ProvisionerDaemonOffline) {
+					_, _ = fmt.Fprintf(inv.Stdout, "Provisioner daemon %s has drained\n", cliui.Keyword(daemon.Name))
+					return nil
+				}
+				if daemon.CurrentJob.ID != waitingFor {
+					waitingFor = daemon.CurrentJob.ID
+					_, _ = fmt.Fprintf(inv.Stdout, "Waiting for job %s to complete...\n", waitingFor)
+				}
+
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-ticker.C:
+				}
+				daemons, err = client.OrganizationProvisionerDaemons(ctx, org.ID, &codersdk.OrganizationProvisionerDaemonsOptions{
+					IDs: []uuid.UUID{daemon.ID},
+				})
+				if err != nil {
+					return xerrors.Errorf("get provisioner daemon: %w", err)
+				}
+				if len(daemons) == 0 {
+					return xerrors.Errorf("provisioner daemon %q no longer exists", daemon.Name)
+				}
+				daemon = daemons[0]
+			}
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "wait",
+			Env:         "CODER_PROVISIONER_DRAIN_WAIT",
+			Description: "Wait for the current job of the daemon to complete.",
+			Default:     "true",
+			Value:       serpent.BoolOf(&wait),
+		},
+	}
+	orgContext.AttachOptions(cmd)
+
+	return cmd
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/serpent"
+)
+
+type processRow struct {
+	// For JSON format:
+	workspacesdk.Process `table:"-"`
+
+	// For table format:
+	PID     int32  `json:"-" table:"pid,nosort"`
+	User    string `json:"-" table:"user"`
+	CPU     string `json:"-" table:"cpu"`
+	Memory  string `json:"-" table:"memory"`
+	Session string `json:"-" table:"session"`
+	Started string `json:"-" table:"started"`
+	Command string `json:"-" table:"command"`
+}
+
+func (r *RootCmd) ps() *serpent.Command {
+	var (
+		user             string
+		appearanceConfig codersdk.AppearanceConfig
+	)
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]processRow{}, []string{"pid", "user", "cpu", "memory", "started", "command"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "ps <workspace>",
+		Short:       "List the processes running in a workspace",
+		Long: "The session column shows the ID of the SSH or reconnecting PTY session that started a process. " +
+			"CPU usage is averaged over the lifetime of a process.\n" + FormatExamples(
+			Example{
+				Description: "List the processes of the workspace user",
+				Command:     "coder ps my-workspace --user coder",
+			},
+			Example{
+				Description: "Show the session that started each process",
+				Command:     "coder ps my-workspace -c pid,session,command",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+			initAppearance(client, &appearanceConfig),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			conn, err := r.dialWorkspaceAgent(ctx, inv, client, appearanceConfig, inv.Args[0])
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			resp, err := conn.ListProcesses(ctx)
+			if err != nil {
+				return xerrors.Errorf("list processes: %w", err)
+			}
+			processes := resp.Processes
+			if user != "" {
+				processes = slices.DeleteFunc(processes, func(p workspacesdk.Process) bool {
+					return p.User != user
+				})
+			}
+			slices.SortFunc(processes, func(a, b workspacesdk.Process) int {
+				return int(a.PID - b.PID)
+			})
+
+			rows := make([]processRow, 0, len(processes))
+			for _, p := range processes {
+				session := ""
+				if p.SessionID.Valid {
+					session = p.SessionID.UUID.String()
+				}
+				command := strings.Join(p.Cmdline, " ")
+				if command == "" {
+					command = "[" + p.Name + "]"
+				}
+				rows = append(rows, processRow{
+					Process: p,
+					PID:     p.PID,
+					User:    p.User,
+					CPU:     fmt.Sprintf("%.1f%%", p.CPUPercent),
+					Memory:  humanize.IBytes(p.MemoryRSS),
+					Session: session,
+					Started: p.CreatedAt.Local().Format(time.DateTime),
+					Command: command,
+				})
+			}
+
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "user",
+			Description: "Only list the processes owned by this user in the workspace.",
+			Value:       serpent.StringOf(&user),
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+// dialWorkspaceAgent waits for the agent of a workspace to be reachable, and
+// connects to it.
+func (r *RootCmd) dialWorkspaceAgent(ctx context.Context, inv *serpent.Invocation, client *codersdk.Client, appearanceConfig codersdk.AppearanceConfig, name string) (*workspacesdk.AgentConn, error) {
+	_, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, false, name)
+	if err != nil {
+		return nil, err
+	}
+	err = cliui.Agent(ctx, inv.Stderr, workspaceAgent.ID, cliui.AgentOptions{
+		Fetch:   client.WorkspaceAgent,
+		Wait:    false,
+		DocsURL: appearanceConfig.DocsURL,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("await agent: %w", err)
+	}
+
+	opts := &workspacesdk.DialAgentOptions{}
+	if r.verbose {
+		opts.Logger = inv.Logger.AppendSinks(sloghuman.Sink(inv.Stderr)).Leveled(slog.LevelDebug)
+	}
+	if r.disableDirect {
+		opts.BlockEndpoints = true
+	}
+	if !r.disableNetworkTelemetry {
+		opts.EnableTelemetry = true
+	}
+	conn, err := workspacesdk.New(client).DialAgent(ctx, workspaceAgent.ID, opts)
+	if err != nil {
+		return nil, xerrors.Errorf("dial workspace agent: %w", err)
+	}
+	if !conn.AwaitReachable(ctx) {
+		_ = conn.Close()
+		return nil, xerrors.Errorf("workspace agent is unreachable: %w", ctx.Err())
+	}
+	return conn, nil
+}
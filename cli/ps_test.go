@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/agent/agenttest"
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestPs(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t)
+	_ = agenttest.New(t, client.URL, agentToken)
+	coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	inv, root := clitest.New(t, "ps", workspace.Name, "--output", "json")
+	clitest.SetupConfig(t, client, root)
+	var stdout bytes.Buffer
+	inv.Stdout = &stdout
+	err := inv.WithContext(testutil.Context(t, testutil.WaitLong)).Run()
+	require.NoError(t, err)
+
+	// The agent runs in the test process, so it lists itself.
+	var processes []workspacesdk.Process
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &processes))
+	require.Condition(t, func() bool {
+		for _, p := range processes {
+			if int(p.PID) == os.Getpid() {
+				return true
+			}
+		}
+		return false
+	}, "test process is listed")
+}
+
+func TestKill(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test sends SIGTERM")
+	}
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t)
+	_ = agenttest.New(t, client.URL, agentToken)
+	coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	cmd := exec.Command("sleep", "60")
+	require.NoError(t, cmd.Start())
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- cmd.Wait() }()
+
+	inv, root := clitest.New(t, "kill", workspace.Name, strconv.Itoa(cmd.Process.Pid))
+	clitest.SetupConfig(t, client, root)
+	ctx := testutil.Context(t, testutil.WaitLong)
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	err = testutil.RequireReceive(ctx, t, waitErr)
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, "signal: terminated", exitErr.Error())
+
+	// Signaling a process that doesn't exist fails.
+	inv, root = clitest.New(t, "kill", workspace.Name, strconv.Itoa(cmd.Process.Pid), "--signal", "KILL")
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.ErrorContains(t, err, "failed to signal 1 of 1 processes")
+}
@@ -93,6 +93,8 @@ func (r *RootCmd) CoreSubcommands() []*serpent.Command {
 		r.portForward(),
 		r.publickey(),
 		r.resetPassword(),
+		r.secrets(),
+		r.sessions(),
 		r.state(),
 		r.templates(),
 		r.tokens(),
@@ -102,12 +104,15 @@ func (r *RootCmd) CoreSubcommands() []*serpent.Command {
 		// Workspace Commands
 		r.autoupdate(),
 		r.configSSH(),
+		r.cp(),
 		r.create(),
 		r.deleteWorkspace(),
 		r.favorite(),
+		r.kill(),
 		r.list(),
 		r.open(),
 		r.ping(),
+		r.ps(),
 		r.rename(),
 		r.restart(),
 		r.schedules(),
@@ -117,6 +122,7 @@ func (r *RootCmd) CoreSubcommands() []*serpent.Command {
 		r.start(),
 		r.stat(),
 		r.stop(),
+		r.sync(),
 		r.unfavorite(),
 		r.update(),
 		r.whoami(),
@@ -124,6 +130,7 @@ func (r *RootCmd) CoreSubcommands() []*serpent.Command {
 		// Hidden
 		r.expCmd(),
 		r.gitssh(),
+		r.sshDaemon(),
 		r.support(),
 		r.vpnDaemon(),
 		r.vscodeSSH(),
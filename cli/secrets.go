@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/serpent"
+)
+
+func (r *RootCmd) secrets() *serpent.Command {
+	cmd := &serpent.Command{
+		Use:   "secrets",
+		Short: "Manage secrets that are injected into your workspaces",
+		Long: "Secrets are encrypted when database encryption is enabled, and are delivered to your workspaces when they start. " +
+			"Each secret can be injected as an environment variable, written to a file, or both.\n" + FormatExamples(
+			Example{
+				Description: "Inject an npm token into every workspace as an environment variable",
+				Command:     "coder secrets set npm-token --env NPM_TOKEN < token.txt",
+			},
+			Example{
+				Description: "Write a credentials file into the workspaces of a template",
+				Command:     "coder secrets set aws --file .aws/credentials --template dev < creds",
+			},
+			Example{
+				Description: "List your secrets",
+				Command:     "coder secrets ls",
+			},
+			Example{
+				Description: "Delete a secret",
+				Command:     "coder secrets rm npm-token",
+			},
+		),
+		Aliases: []string{"secret"},
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.setSecret(),
+			r.listSecrets(),
+			r.deleteSecret(),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) setSecret() *serpent.Command {
+	var (
+		value       string
+		description string
+		envName     string
+		filePath    string
+		templates   []string
+	)
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "set <name>",
+		Short: "Create or update a secret",
+		Long: "The value is read from --value, or from stdin if it isn't set. " +
+			"Options that aren't given keep their current value when a secret is updated.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+			name := inv.Args[0]
+			changed := func(flag string) bool {
+				return inv.ParsedFlags().Changed(flag)
+			}
+
+			if !changed("value") {
+				if isTTYIn(inv) {
+					var err error
+					value, err = cliui.Prompt(inv, cliui.PromptOptions{
+						Text:   "Enter the value of the secret:",
+						Secret: true,
+					})
+					if err != nil {
+						return err
+					}
+				} else {
+					raw, err := io.ReadAll(inv.Stdin)
+					if err != nil {
+						return xerrors.Errorf("read value from stdin: %w", err)
+					}
+					// Files almost always end with a newline that isn't
+					// part of the secret.
+					value = strings.TrimSuffix(strings.TrimSuffix(string(raw), "\n"), "\r")
+				}
+			}
+
+			var templateIDs []uuid.UUID
+			if changed("template") {
+				var err error
+				templateIDs, err = secretTemplateIDs(inv, client, templates)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err := client.UserSecretByName(ctx, codersdk.Me, name)
+			var sdkErr *codersdk.Error
+			if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+				_, err = client.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+					Name:        name,
+					Description: description,
+					Value:       value,
+					EnvName:     envName,
+					FilePath:    filePath,
+					TemplateIDs: templateIDs,
+				})
+				if err != nil {
+					return xerrors.Errorf("create secret: %w", err)
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "Secret %s has been created.\n", cliui.Keyword(name))
+				return nil
+			}
+			if err != nil {
+				return xerrors.Errorf("get secret: %w", err)
+			}
+
+			req := codersdk.UpdateUserSecretRequest{
+				Value: &value,
+			}
+			if changed("description") {
+				req.Description = &description
+			}
+			if changed("env") {
+				req.EnvName = &envName
+			}
+			if changed("file") {
+				req.FilePath = &filePath
+			}
+			if changed("template") {
+				req.TemplateIDs = &templateIDs
+			}
+			_, err = client.UpdateUserSecret(ctx, codersdk.Me, name, req)
+			if err != nil {
+				return xerrors.Errorf("update secret: %w", err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Secret %s has been updated.\n", cliui.Keyword(name))
+			return nil
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "value",
+			Env:         "CODER_SECRET_VALUE",
+			Description: "The value of the secret. Read from stdin if not set.",
+			Value:       serpent.StringOf(&value),
+		},
+		{
+			Flag:        "description",
+			Description: "A description of the secret.",
+			Value:       serpent.StringOf(&description),
+		},
+		{
+			Flag:        "env",
+			Description: "The environment variable to inject the secret into workspaces as. Pass an empty value to stop injecting it.",
+			Value:       serpent.StringOf(&envName),
+		},
+		{
+			Flag:        "file",
+			Description: "The file to write the secret to in workspaces. Relative paths are relative to the home directory. Pass an empty value to stop writing it.",
+			Value:       serpent.StringOf(&filePath),
+		},
+		{
+			Flag:        "template",
+			Description: "Only inject the secret into workspaces of the given templates, by name or ID. Injected into every workspace if not set.",
+			Value:       serpent.StringArrayOf(&templates),
+		},
+	}
+	return cmd
+}
+
+// secretTemplateIDs resolves template names or IDs to IDs.
+func secretTemplateIDs(inv *serpent.Invocation, client *codersdk.Client, templates []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(templates))
+	for _, template := range templates {
+		if template == "" {
+			continue
+		}
+		if id, err := uuid.Parse(template); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		found, err := client.Templates(inv.Context(), codersdk.TemplateFilter{
+			ExactName: template,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("get template %q: %w", template, err)
+		}
+		switch len(found) {
+		case 0:
+			return nil, xerrors.Errorf("template %q not found", template)
+		case 1:
+			ids = append(ids, found[0].ID)
+		default:
+			return nil, xerrors.Errorf("template name %q is ambiguous, use the template ID instead", template)
+		}
+	}
+	return ids, nil
+}
+
+type secretListRow struct {
+	// For JSON format:
+	codersdk.UserSecret `table:"-"`
+
+	// For table format:
+	Name        string    `json:"-" table:"name,default_sort"`
+	Description string    `json:"-" table:"description"`
+	EnvName     string    `json:"-" table:"env"`
+	FilePath    string    `json:"-" table:"file"`
+	Templates   string    `json:"-" table:"templates"`
+	UpdatedAt   time.Time `json:"-" table:"updated at"`
+}
+
+func (r *RootCmd) listSecrets() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]secretListRow{}, []string{"name", "env", "file", "templates", "updated at"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List your secrets",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			secrets, err := client.UserSecrets(inv.Context(), codersdk.Me)
+			if err != nil {
+				return xerrors.Errorf("list secrets: %w", err)
+			}
+
+			if len(secrets) == 0 {
+				_, _ = fmt.Fprintln(inv.Stdout, "No secrets found")
+				return nil
+			}
+
+			rows := make([]secretListRow, 0, len(secrets))
+			for _, secret := range secrets {
+				templates := "all"
+				if len(secret.TemplateIDs) > 0 {
+					ids := make([]string, 0, len(secret.TemplateIDs))
+					for _, id := range secret.TemplateIDs {
+						ids = append(ids, id.String())
+					}
+					templates = strings.Join(ids, ", ")
+				}
+				rows = append(rows, secretListRow{
+					UserSecret:  secret,
+					Name:        secret.Name,
+					Description: secret.Description,
+					EnvName:     secret.EnvName,
+					FilePath:    secret.FilePath,
+					Templates:   templates,
+					UpdatedAt:   secret.UpdatedAt,
+				})
+			}
+
+			out, err := formatter.Format(inv.Context(), rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) deleteSecret() *serpent.Command {
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:     "delete <name>",
+		Aliases: []string{"remove"},
+		Short:   "Delete a secret",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			err := client.DeleteUserSecret(inv.Context(), codersdk.Me, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("delete secret: %w", err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Secret %s has been deleted.\n", cliui.Keyword(inv.Args[0]))
+			return nil
+		},
+	}
+	return cmd
+}
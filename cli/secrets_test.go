@@ -0,0 +1,71 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestSecrets(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	member, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	// Create a secret, reading the value from stdin.
+	inv, root := clitest.New(t, "secrets", "set", "npm-token", "--env", "NPM_TOKEN")
+	clitest.SetupConfig(t, member, root)
+	inv.Stdin = strings.NewReader("hunter2\n")
+	buf := new(bytes.Buffer)
+	inv.Stdout = buf
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "has been created")
+
+	secret, err := member.UserSecretByName(ctx, codersdk.Me, "npm-token")
+	require.NoError(t, err)
+	require.Equal(t, "NPM_TOKEN", secret.EnvName)
+	require.Empty(t, secret.FilePath)
+
+	// Update the secret. Options that aren't given are kept.
+	inv, root = clitest.New(t, "secrets", "set", "npm-token", "--value", "hunter3", "--file", ".npmrc")
+	clitest.SetupConfig(t, member, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "has been updated")
+
+	secret, err = member.UserSecretByName(ctx, codersdk.Me, "npm-token")
+	require.NoError(t, err)
+	require.Equal(t, "NPM_TOKEN", secret.EnvName)
+	require.Equal(t, ".npmrc", secret.FilePath)
+
+	inv, root = clitest.New(t, "secrets", "ls")
+	clitest.SetupConfig(t, member, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "npm-token")
+	require.Contains(t, buf.String(), "NPM_TOKEN")
+	require.NotContains(t, buf.String(), "hunter")
+
+	inv, root = clitest.New(t, "secrets", "rm", "npm-token")
+	clitest.SetupConfig(t, member, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	secrets, err := member.UserSecrets(ctx, codersdk.Me)
+	require.NoError(t, err)
+	require.Empty(t, secrets)
+}
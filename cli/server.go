@@ -82,6 +82,7 @@ import (
 	"github.com/coder/coder/v2/coderd/database/migrations"
 	"github.com/coder/coder/v2/coderd/database/pubsub"
 	"github.com/coder/coder/v2/coderd/devtunnel"
+	"github.com/coder/coder/v2/coderd/driftdetect"
 	"github.com/coder/coder/v2/coderd/externalauth"
 	"github.com/coder/coder/v2/coderd/gitsshkey"
 	"github.com/coder/coder/v2/coderd/httpmw"
@@ -104,6 +105,7 @@ import (
 	"github.com/coder/coder/v2/codersdk/drpc"
 	"github.com/coder/coder/v2/cryptorand"
 	"github.com/coder/coder/v2/provisioner/echo"
+	"github.com/coder/coder/v2/provisioner/pulumi"
 	"github.com/coder/coder/v2/provisioner/terraform"
 	"github.com/coder/coder/v2/provisionerd"
 	"github.com/coder/coder/v2/provisionerd/proto"
@@ -242,6 +244,12 @@ func enablePrometheus(
 	}
 	afterCtx(ctx, closeWorkspacesFunc)
 
+	closeProvisionerJobQueueFunc, err := prometheusmetrics.ProvisionerJobQueue(ctx, options.Logger.Named("provisioner_job_queue_metrics"), options.PrometheusRegistry, options.Database, 0)
+	if err != nil {
+		return nil, xerrors.Errorf("register provisioner job queue prometheus metric: %w", err)
+	}
+	afterCtx(ctx, closeProvisionerJobQueueFunc)
+
 	insightsMetricsCollector, err := insights.NewMetricsCollector(options.Database, options.Logger, 0, 0)
 	if err != nil {
 		return nil, xerrors.Errorf("unable to initialize insights metrics collector: %w", err)
@@ -740,7 +748,7 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				}()
 
 				options.Database = database.New(sqlDB)
-				ps, err := pubsub.New(ctx, logger.Named("pubsub"), sqlDB, dbURL)
+				ps, err := newPubsub(ctx, logger.Named("pubsub"), vals, sqlDB, dbURL)
 				if err != nil {
 					return xerrors.Errorf("create pubsub: %w", err)
 				}
@@ -997,6 +1005,7 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			var provisionerdWaitGroup sync.WaitGroup
 			defer provisionerdWaitGroup.Wait()
 			provisionerdMetrics := provisionerd.NewMetrics(options.PrometheusRegistry)
+			terraformCacheMetrics := terraform.NewCacheMetrics(options.PrometheusRegistry)
 
 			// Built in provisioner daemons will support the same types.
 			// By default, this is the slice {"terraform"}
@@ -1012,7 +1021,7 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				name := fmt.Sprintf("%s-%s", hostname, suffix)
 				daemonCacheDir := filepath.Join(cacheDir, fmt.Sprintf("provisioner-%d", i))
 				daemon, err := newProvisionerDaemon(
-					ctx, coderAPI, provisionerdMetrics, logger, vals, daemonCacheDir, errCh, &provisionerdWaitGroup, name, provisionerTypes,
+					ctx, coderAPI, provisionerdMetrics, terraformCacheMetrics, logger, vals, daemonCacheDir, errCh, &provisionerdWaitGroup, name, provisionerTypes,
 				)
 				if err != nil {
 					return xerrors.Errorf("create provisioner daemon: %w", err)
@@ -1100,6 +1109,11 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				ctx, options.Database, options.Pubsub, options.PrometheusRegistry, coderAPI.TemplateScheduleStore, &coderAPI.Auditor, coderAPI.AccessControlStore, logger, autobuildTicker.C, options.NotificationsEnqueuer)
 			autobuildExecutor.Run()
 
+			driftDetectionTicker := time.NewTicker(vals.AutobuildPollInterval.Value())
+			defer driftDetectionTicker.Stop()
+			driftDetector := driftdetect.NewExecutor(ctx, options.Database, options.Pubsub, logger, driftDetectionTicker.C)
+			driftDetector.Run()
+
 			hangDetectorTicker := time.NewTicker(vals.JobHangDetectorInterval.Value())
 			defer hangDetectorTicker.Stop()
 			hangDetector := unhanger.New(ctx, options.Database, options.Pubsub, logger, hangDetectorTicker.C)
@@ -1385,6 +1399,7 @@ func newProvisionerDaemon(
 	ctx context.Context,
 	coderAPI *coderd.API,
 	metrics provisionerd.Metrics,
+	terraformCacheMetrics *terraform.CacheMetrics,
 	logger slog.Logger,
 	cfg *codersdk.DeploymentValues,
 	cacheDir string,
@@ -1473,8 +1488,12 @@ func newProvisionerDaemon(
 						Logger:        provisionerLogger,
 						WorkDirectory: workDir,
 					},
-					CachePath: tfDir,
-					Tracer:    tracer,
+					CachePath:          tfDir,
+					CacheSizeLimit:     cfg.Provisioner.TerraformCacheSize.Value() << 20,
+					DisableModuleCache: cfg.Provisioner.TerraformDisableModuleCache.Value(),
+					CacheMetrics:       terraformCacheMetrics,
+					Tracer:             tracer,
+					Engine:             codersdk.TerraformEngine(cfg.Provisioner.TerraformEngine.String()),
 				})
 				if err != nil && !xerrors.Is(err, context.Canceled) {
 					select {
@@ -1485,6 +1504,45 @@ func newProvisionerDaemon(
 			}()
 
 			connector[string(database.ProvisionerTypeTerraform)] = sdkproto.NewDRPCProvisionerClient(terraformClient)
+		case codersdk.ProvisionerTypePulumi:
+			pulumiDir := filepath.Join(cacheDir, "pulumi")
+			err = os.MkdirAll(pulumiDir, 0o700)
+			if err != nil {
+				return nil, xerrors.Errorf("mkdir pulumi dir: %w", err)
+			}
+
+			tracer := coderAPI.TracerProvider.Tracer(tracing.TracerName)
+			pulumiClient, pulumiServer := drpc.MemTransportPipe()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-ctx.Done()
+				_ = pulumiClient.Close()
+				_ = pulumiServer.Close()
+			}()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer cancel()
+
+				err := pulumi.Serve(ctx, &pulumi.ServeOptions{
+					ServeOptions: &provisionersdk.ServeOptions{
+						Listener:      pulumiServer,
+						Logger:        provisionerLogger,
+						WorkDirectory: workDir,
+					},
+					CachePath: pulumiDir,
+					Tracer:    tracer,
+				})
+				if err != nil && !xerrors.Is(err, context.Canceled) {
+					select {
+					case errCh <- err:
+					default:
+					}
+				}
+			}()
+
+			connector[string(database.ProvisionerTypePulumi)] = sdkproto.NewDRPCProvisionerClient(pulumiClient)
 		default:
 			return nil, xerrors.Errorf("unknown provisioner type %q", provisionerType)
 		}
@@ -2704,6 +2762,14 @@ func parseExternalAuthProvidersFromEnv(prefix string, environ []string) ([]coder
 			provider.DisplayName = v.Value
 		case "DISPLAY_ICON":
 			provider.DisplayIcon = v.Value
+		case "BROKER_TYPE":
+			provider.BrokerType = codersdk.ExternalAuthBrokerType(v.Value)
+		case "BROKER_URL":
+			provider.BrokerURL = v.Value
+		case "BROKER_TOKEN":
+			provider.BrokerToken = v.Value
+		case "BROKER_TOKEN_FIELD":
+			provider.BrokerTokenField = v.Value
 		}
 		providers[providerNum] = provider
 	}
@@ -2763,6 +2829,25 @@ func signalNotifyContext(ctx context.Context, inv *serpent.Invocation, sig ...os
 	return inv.SignalNotifyContext(ctx, sig...)
 }
 
+// pubsubCollector is a pubsub that reports its own metrics.
+type pubsubCollector interface {
+	pubsub.Pubsub
+	prometheus.Collector
+}
+
+// newPubsub creates the pubsub selected by the deployment config.
+func newPubsub(ctx context.Context, logger slog.Logger, vals *codersdk.DeploymentValues, sqlDB *sql.DB, dbURL string) (pubsubCollector, error) {
+	switch codersdk.PubsubBackend(vals.PubsubBackend) {
+	case codersdk.PubsubBackendNATS:
+		if vals.PubsubNATSURL.String() == "" {
+			return nil, xerrors.Errorf("--pubsub-nats-url is required when the pubsub backend is %q", codersdk.PubsubBackendNATS)
+		}
+		return pubsub.NewNATS(ctx, logger, vals.PubsubNATSURL.String())
+	default:
+		return pubsub.New(ctx, logger, sqlDB, dbURL)
+	}
+}
+
 func getAndMigratePostgresDB(ctx context.Context, logger slog.Logger, postgresURL string, auth codersdk.PostgresAuth, sqlDriver string) (*sql.DB, string, error) {
 	dbURL, err := escapePostgresURLUserInfo(postgresURL)
 	if err != nil {
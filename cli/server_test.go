@@ -74,9 +74,14 @@ func TestReadExternalAuthProvidersFromEnv(t *testing.T) {
 			"CODER_EXTERNAL_AUTH_1_NO_REFRESH=true",
 			"CODER_EXTERNAL_AUTH_1_DISPLAY_NAME=Google",
 			"CODER_EXTERNAL_AUTH_1_DISPLAY_ICON=/icon/google.svg",
+			"CODER_EXTERNAL_AUTH_2_ID=3",
+			"CODER_EXTERNAL_AUTH_2_BROKER_TYPE=vault",
+			"CODER_EXTERNAL_AUTH_2_BROKER_URL=https://vault.example.com/v1/github/token",
+			"CODER_EXTERNAL_AUTH_2_BROKER_TOKEN=hunter13",
+			"CODER_EXTERNAL_AUTH_2_BROKER_TOKEN_FIELD=github_token",
 		})
 		require.NoError(t, err)
-		require.Len(t, providers, 2)
+		require.Len(t, providers, 3)
 
 		// Validate the first provider.
 		assert.Equal(t, "1", providers[0].ID)
@@ -92,6 +97,13 @@ func TestReadExternalAuthProvidersFromEnv(t *testing.T) {
 		assert.Equal(t, true, providers[1].NoRefresh)
 		assert.Equal(t, "Google", providers[1].DisplayName)
 		assert.Equal(t, "/icon/google.svg", providers[1].DisplayIcon)
+
+		// Validate the third provider.
+		assert.Equal(t, "3", providers[2].ID)
+		assert.Equal(t, codersdk.ExternalAuthBrokerTypeVault, providers[2].BrokerType)
+		assert.Equal(t, "https://vault.example.com/v1/github/token", providers[2].BrokerURL)
+		assert.Equal(t, "hunter13", providers[2].BrokerToken)
+		assert.Equal(t, "github_token", providers[2].BrokerTokenField)
 	})
 }
 
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/agent/sessionrecording"
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/serpent"
+)
+
+func (r *RootCmd) sessions() *serpent.Command {
+	cmd := &serpent.Command{
+		Use:   "sessions",
+		Short: "List and replay recorded workspace sessions",
+		Long: "SSH and reconnecting PTY sessions are recorded for templates that have session recording enabled. " +
+			"Accessing recordings requires permission to read the audit log.\n" + FormatExamples(
+			Example{
+				Description: "List the recorded sessions of a workspace",
+				Command:     "coder sessions list --workspace alice/dev",
+			},
+			Example{
+				Description: "Replay a recorded session at twice the speed",
+				Command:     "coder sessions replay 6f2ac8f5-2d4c-4b4e-9e0e-8d3a6a2b0f1c --speed 2",
+			},
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.listSessions(),
+			r.replaySession(),
+		},
+	}
+	return cmd
+}
+
+type sessionListRow struct {
+	// For JSON format:
+	codersdk.SessionRecording `table:"-"`
+
+	// For table format:
+	ID        string    `json:"-" table:"id"`
+	Workspace string    `json:"-" table:"workspace"`
+	Type      string    `json:"-" table:"type"`
+	StartedAt time.Time `json:"-" table:"started at,default_sort"`
+	Duration  string    `json:"-" table:"duration"`
+	Size      string    `json:"-" table:"size"`
+}
+
+func (r *RootCmd) listSessions() *serpent.Command {
+	var (
+		workspaceName string
+		limit         int64
+	)
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]sessionListRow{}, []string{"id", "workspace", "type", "started at", "duration", "size"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List recorded sessions",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+
+			req := codersdk.SessionRecordingsRequest{
+				Pagination: codersdk.Pagination{Limit: int(limit)},
+			}
+			if workspaceName != "" {
+				workspace, err := namedWorkspace(ctx, client, workspaceName)
+				if err != nil {
+					return xerrors.Errorf("get workspace: %w", err)
+				}
+				req.WorkspaceID = workspace.ID
+			}
+
+			recordings, err := client.SessionRecordings(ctx, req)
+			if err != nil {
+				return xerrors.Errorf("list session recordings: %w", err)
+			}
+			if len(recordings) == 0 {
+				_, _ = fmt.Fprintln(inv.Stderr, "No recorded sessions found")
+				return nil
+			}
+
+			rows := make([]sessionListRow, 0, len(recordings))
+			for _, recording := range recordings {
+				duration := "active"
+				if recording.EndedAt != nil {
+					duration = recording.EndedAt.Sub(recording.StartedAt).Round(time.Second).String()
+				}
+				rows = append(rows, sessionListRow{
+					SessionRecording: recording,
+					ID:               recording.ID.String(),
+					Workspace:        recording.WorkspaceOwnerUsername + "/" + recording.WorkspaceName,
+					Type:             string(recording.Type),
+					StartedAt:        recording.StartedAt,
+					Duration:         duration,
+					Size:             humanize.IBytes(uint64(recording.Size)), //nolint:gosec // Sizes are never negative.
+				})
+			}
+
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "workspace",
+			Description: "Only list the sessions of this workspace, as owner/name.",
+			Value:       serpent.StringOf(&workspaceName),
+		},
+		{
+			Flag:        "limit",
+			Description: "Maximum number of sessions to list, starting with the most recent.",
+			Default:     "50",
+			Value:       serpent.Int64Of(&limit),
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) replaySession() *serpent.Command {
+	var (
+		speed         float64
+		idleTimeLimit time.Duration
+	)
+
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "replay <id>",
+		Short: "Replay a recorded session in the terminal",
+		Long:  "The recording is written to stdout with its original timing. Use \"coder sessions list\" to find the ID of a session.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+
+			if speed <= 0 {
+				return xerrors.New("--speed must be greater than 0")
+			}
+			id, err := uuid.Parse(inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("invalid session ID %q: %w", inv.Args[0], err)
+			}
+
+			cast, err := client.SessionRecordingCast(ctx, id)
+			if err != nil {
+				return xerrors.Errorf("get session recording: %w", err)
+			}
+			defer cast.Close()
+
+			dec, err := sessionrecording.NewDecoder(cast)
+			if err != nil {
+				return xerrors.Errorf("read session recording: %w", err)
+			}
+			header := dec.Header()
+			_, _ = fmt.Fprintf(inv.Stderr, "Replaying session recorded in a %dx%d terminal.\n", header.Width, header.Height)
+
+			var last time.Duration
+			for {
+				event, err := dec.Next()
+				if xerrors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return xerrors.Errorf("read session recording: %w", err)
+				}
+
+				delay := event.Time - last
+				last = event.Time
+				if idleTimeLimit > 0 && delay > idleTimeLimit {
+					delay = idleTimeLimit
+				}
+				delay = time.Duration(float64(delay) / speed)
+				if delay > 0 {
+					timer := time.NewTimer(delay)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return ctx.Err()
+					case <-timer.C:
+					}
+				}
+
+				if event.Type != sessionrecording.EventTypeOutput {
+					continue
+				}
+				_, err = io.WriteString(inv.Stdout, event.Data)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "speed",
+			Description: "Playback speed multiplier.",
+			Default:     "1",
+			Value:       serpent.Float64Of(&speed),
+		},
+		{
+			Flag:        "idle-time-limit",
+			Description: "Limit pauses between output to this duration. Pass 0 to keep the original pauses.",
+			Default:     "2s",
+			Value:       serpent.DurationOf(&idleTimeLimit),
+		},
+	}
+	return cmd
+}
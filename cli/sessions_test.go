@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
+	"github.com/coder/coder/v2/coderd/database/dbgen"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	client, db := coderdtest.NewWithDatabase(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	member, memberUser := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        memberUser.ID,
+	}).WithAgent().Do()
+	agents, err := db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(dbauthz.AsSystemRestricted(ctx), r.Workspace.ID)
+	require.NoError(t, err)
+	recording := dbgen.WorkspaceSessionRecording(t, db, database.WorkspaceSessionRecording{
+		WorkspaceID: r.Workspace.ID,
+		AgentID:     agents[0].ID,
+	})
+	err = db.InsertWorkspaceSessionRecordingChunk(dbauthz.AsSystemRestricted(ctx), database.InsertWorkspaceSessionRecordingChunkParams{
+		RecordingID: recording.ID,
+		ChunkIndex:  0,
+		Data:        []byte("{\"version\": 2, \"width\": 80, \"height\": 24}\n[0.5, \"o\", \"hello \"]\n[1.0, \"r\", \"100x30\"]\n[60.0, \"o\", \"world\"]\n"),
+	})
+	require.NoError(t, err)
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "sessions", "ls", "--workspace", memberUser.Username+"/"+r.Workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		inv.Stdout = buf
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		require.Contains(t, buf.String(), recording.ID.String())
+		require.Contains(t, buf.String(), memberUser.Username+"/"+r.Workspace.Name)
+		require.Contains(t, buf.String(), "active")
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		t.Parallel()
+
+		// The idle time limit keeps the 59 second pause from slowing down
+		// the test.
+		inv, root := clitest.New(t, "sessions", "replay", recording.ID.String(), "--speed", "100", "--idle-time-limit", "1s")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		inv.Stdout = buf
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		require.Equal(t, "hello world", buf.String())
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "sessions", "replay", recording.ID.String())
+		clitest.SetupConfig(t, member, root)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, "Forbidden")
+	})
+}
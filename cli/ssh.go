@@ -29,7 +29,6 @@ import (
 	gosshagent "golang.org/x/crypto/ssh/agent"
 	"golang.org/x/term"
 	"golang.org/x/xerrors"
-	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/netlogtype"
 
@@ -80,6 +79,9 @@ func (r *RootCmd) ssh() *serpent.Command {
 		appearanceConfig    codersdk.AppearanceConfig
 		networkInfoDir      string
 		networkInfoInterval time.Duration
+		daemon              bool
+		daemonIdleTimeout   time.Duration
+		daemonStatus        bool
 
 		containerName string
 		containerUser string
@@ -90,6 +92,14 @@ func (r *RootCmd) ssh() *serpent.Command {
 		Use:         "ssh <workspace>",
 		Short:       "Start a shell into a workspace",
 		Middleware: serpent.Chain(
+			func(next serpent.HandlerFunc) serpent.HandlerFunc {
+				return func(inv *serpent.Invocation) error {
+					if daemonStatus {
+						return r.sshDaemonStatusHandler(inv)
+					}
+					return next(inv)
+				}
+			},
 			serpent.RequireNArgs(1),
 			r.InitClient(client),
 			initAppearance(client, &appearanceConfig),
@@ -184,6 +194,10 @@ func (r *RootCmd) ssh() *serpent.Command {
 			stack := newCloserStack(ctx, logger, quartz.NewReal())
 			defer stack.close(nil)
 
+			if daemon && daemonIdleTimeout <= 0 {
+				return xerrors.New("--daemon-idle-timeout must be greater than zero")
+			}
+
 			for _, remoteForward := range remoteForwards {
 				isValid := validateRemoteForward(remoteForward)
 				if !isValid {
@@ -278,19 +292,34 @@ func (r *RootCmd) ssh() *serpent.Command {
 			if r.disableDirect {
 				_, _ = fmt.Fprintln(inv.Stderr, "Direct connections disabled.")
 			}
-			conn, err := workspacesdk.New(client).
-				DialAgent(ctx, workspaceAgent.ID, &workspacesdk.DialAgentOptions{
-					Logger:          logger,
-					BlockEndpoints:  r.disableDirect,
-					EnableTelemetry: !r.disableNetworkTelemetry,
-				})
-			if err != nil {
-				return xerrors.Errorf("dial agent: %w", err)
+			// The daemon only proxies raw SSH streams, and network info is
+			// collected from our own connection.
+			var daemonSSH *sshDaemonStream
+			if daemon && stdio && networkInfoDir == "" {
+				daemonSSH, err = r.dialSSHDaemon(ctx, client, workspace, workspaceAgent, daemonIdleTimeout)
+				if err != nil {
+					logger.Warn(ctx, "failed to connect through the ssh daemon, connecting directly", slog.Error(err))
+					daemonSSH = nil
+				} else if err = stack.push("ssh daemon stream", daemonSSH); err != nil {
+					return err
+				}
 			}
-			if err = stack.push("agent conn", conn); err != nil {
-				return err
+			var conn *workspacesdk.AgentConn
+			if daemonSSH == nil {
+				conn, err = workspacesdk.New(client).
+					DialAgent(ctx, workspaceAgent.ID, &workspacesdk.DialAgentOptions{
+						Logger:          logger,
+						BlockEndpoints:  r.disableDirect,
+						EnableTelemetry: !r.disableNetworkTelemetry,
+					})
+				if err != nil {
+					return xerrors.Errorf("dial agent: %w", err)
+				}
+				if err = stack.push("agent conn", conn); err != nil {
+					return err
+				}
+				conn.AwaitReachable(ctx)
 			}
-			conn.AwaitReachable(ctx)
 
 			if containerName != "" {
 				cts, err := client.WorkspaceAgentListContainers(ctx, workspaceAgent.ID, nil)
@@ -333,9 +362,14 @@ func (r *RootCmd) ssh() *serpent.Command {
 			}
 
 			if stdio {
-				rawSSH, err := conn.SSH(ctx)
-				if err != nil {
-					return xerrors.Errorf("connect SSH: %w", err)
+				var rawSSH rawSSHConn
+				if daemonSSH != nil {
+					rawSSH = daemonSSH
+				} else {
+					rawSSH, err = conn.SSH(ctx)
+					if err != nil {
+						return xerrors.Errorf("connect SSH: %w", err)
+					}
 				}
 				copier := newRawSSHCopier(logger, rawSSH, stdioReader, stdioWriter)
 				if err = stack.push("rawSSHCopier", copier); err != nil {
@@ -662,6 +696,24 @@ func (r *RootCmd) ssh() *serpent.Command {
 			Value:       serpent.StringOf(&containerUser),
 			Hidden:      true, // Hidden until this features is at least in beta.
 		},
+		{
+			Flag:        "daemon",
+			Env:         "CODER_SSH_DAEMON",
+			Description: "Reuse the connection to the workspace kept open by a background process, starting it if needed. Only applies with --stdio. Connecting directly is used as a fallback.",
+			Value:       serpent.BoolOf(&daemon),
+		},
+		{
+			Flag:        "daemon-idle-timeout",
+			Env:         "CODER_SSH_DAEMON_IDLE_TIMEOUT",
+			Description: "How long the background process started by --daemon keeps an unused connection to a workspace open. It exits once no connections are left.",
+			Default:     "10m",
+			Value:       serpent.DurationOf(&daemonIdleTimeout),
+		},
+		{
+			Flag:        "daemon-status",
+			Description: "Show the connections kept open by the background process started by --daemon, and exit.",
+			Value:       serpent.BoolOf(&daemonStatus),
+		},
 		sshDisableAutostartOption(serpent.BoolOf(&disableAutostart)),
 	}
 	return cmd
@@ -1194,9 +1246,16 @@ func (c *closerStack) push(name string, closer io.Closer) error {
 	return nil
 }
 
+// rawSSHConn is a connection to the SSH server of an agent, either direct or
+// proxied by the SSH daemon.
+type rawSSHConn interface {
+	io.ReadWriteCloser
+	CloseWrite() error
+}
+
 // rawSSHCopier handles copying raw SSH data between the conn and the pair (r, w).
 type rawSSHCopier struct {
-	conn   *gonet.TCPConn
+	conn   rawSSHConn
 	logger slog.Logger
 	r      io.Reader
 	w      io.Writer
@@ -1204,7 +1263,7 @@ type rawSSHCopier struct {
 	done chan struct{}
 }
 
-func newRawSSHCopier(logger slog.Logger, conn *gonet.TCPConn, r io.Reader, w io.Writer) *rawSSHCopier {
+func newRawSSHCopier(logger slog.Logger, conn rawSSHConn, r io.Reader, w io.Writer) *rawSSHCopier {
 	return &rawSSHCopier{conn: conn, logger: logger, r: r, w: w, done: make(chan struct{})}
 }
 
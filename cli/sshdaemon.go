@@ -0,0 +1,727 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/cli/config"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/retry"
+	"github.com/coder/serpent"
+)
+
+const (
+	sshDaemonSocketName = "daemon.sock"
+	sshDaemonLockName   = "daemon.lock"
+	sshDaemonLogName    = "daemon.log"
+
+	sshDaemonRequestDial     = "dial"
+	sshDaemonRequestStatus   = "status"
+	sshDaemonRequestShutdown = "shutdown"
+)
+
+var (
+	// sshDaemonStartTimeout is how long `coder ssh --daemon` waits for a
+	// daemon it started to accept connections.
+	sshDaemonStartTimeout = 10 * time.Second
+	// sshDaemonDialTimeout bounds how long the daemon tries to reach an agent
+	// before failing the sessions waiting for it.
+	sshDaemonDialTimeout = time.Minute
+)
+
+// sshDaemonRequest is the first line sent by a client of the daemon socket.
+type sshDaemonRequest struct {
+	Type string `json:"type"`
+	// URL is the deployment the client is logged into, and TokenHash the
+	// hash of its session token. They must match the credentials the daemon
+	// was started with.
+	URL       string    `json:"url"`
+	TokenHash string    `json:"token_hash"`
+	AgentID   uuid.UUID `json:"agent_id"`
+	Workspace string    `json:"workspace"`
+}
+
+// sshDaemonResponse is the first line sent back by the daemon. For dial
+// requests without an error, it's followed by the raw SSH stream.
+type sshDaemonResponse struct {
+	Error  string           `json:"error,omitempty"`
+	Status *sshDaemonStatus `json:"status,omitempty"`
+}
+
+type sshDaemonStatus struct {
+	PID         int                   `json:"pid"`
+	URL         string                `json:"url"`
+	StartedAt   time.Time             `json:"started_at"`
+	IdleTimeout time.Duration         `json:"idle_timeout"`
+	Connections []sshDaemonStatusConn `json:"connections"`
+}
+
+type sshDaemonStatusConn struct {
+	Workspace   string    `json:"workspace" table:"workspace,default_sort"`
+	AgentID     uuid.UUID `json:"agent_id" table:"agent id"`
+	Sessions    int       `json:"sessions" table:"sessions"`
+	ConnectedAt time.Time `json:"connected_at" table:"connected at"`
+	IdleSince   string    `json:"idle_since" table:"idle since"`
+}
+
+// sshDaemon is the command run in the background by `coder ssh --daemon`.
+// It keeps a tailnet connection open per agent, and proxies the SSH streams
+// of later `coder ssh --daemon` invocations over them, so that only the first
+// invocation pays for establishing the connection.
+func (r *RootCmd) sshDaemon() *serpent.Command {
+	var idleTimeout time.Duration
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:    "ssh-daemon",
+		Short:  "Run the background process used by \"coder ssh --daemon\".",
+		Hidden: true,
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			if idleTimeout <= 0 {
+				return xerrors.New("--idle-timeout must be greater than zero")
+			}
+			ctx, stop := inv.SignalNotifyContext(inv.Context(), StopSignals...)
+			defer stop()
+
+			logger := inv.Logger.AppendSinks(sloghuman.Sink(inv.Stderr))
+			if r.verbose {
+				logger = logger.Leveled(slog.LevelDebug)
+			}
+
+			dir := r.createConfig().SSHDaemonPath()
+			err := os.MkdirAll(dir, 0o700)
+			if err != nil {
+				return xerrors.Errorf("create daemon directory: %w", err)
+			}
+			lock := flock.New(filepath.Join(dir, sshDaemonLockName))
+			locked, err := lock.TryLock()
+			if err != nil {
+				return xerrors.Errorf("lock daemon directory: %w", err)
+			}
+			if !locked {
+				// Two invocations raced to start the daemon, the other one won.
+				logger.Info(ctx, "ssh daemon is already running")
+				return nil
+			}
+			defer func() {
+				_ = lock.Unlock()
+			}()
+
+			// We hold the lock, so a leftover socket belongs to a daemon that
+			// didn't shut down cleanly.
+			socket := filepath.Join(dir, sshDaemonSocketName)
+			_ = os.Remove(socket)
+			ln, err := net.Listen("unix", socket)
+			if err != nil {
+				return xerrors.Errorf("listen on %s: %w", socket, err)
+			}
+			defer ln.Close()
+
+			d := &sshDaemonServer{
+				logger:    logger,
+				client:    client,
+				tokenHash: sshDaemonTokenHash(client.SessionToken()),
+				dialOptions: &workspacesdk.DialAgentOptions{
+					Logger:          logger,
+					BlockEndpoints:  r.disableDirect,
+					EnableTelemetry: !r.disableNetworkTelemetry,
+				},
+				idleTimeout: idleTimeout,
+				startedAt:   time.Now(),
+				conns:       map[uuid.UUID]*sshDaemonConn{},
+			}
+			logger.Info(ctx, "ssh daemon listening",
+				slog.F("socket", socket),
+				slog.F("url", client.URL.String()),
+				slog.F("idle_timeout", idleTimeout),
+			)
+			return d.serve(ctx, ln)
+		},
+		Options: serpent.OptionSet{
+			{
+				Flag:        "idle-timeout",
+				Description: "Close connections to workspaces that haven't been used for this long, and exit once none are left.",
+				Default:     "10m",
+				Value:       serpent.DurationOf(&idleTimeout),
+			},
+		},
+	}
+	return cmd
+}
+
+type sshDaemonServer struct {
+	logger      slog.Logger
+	client      *codersdk.Client
+	tokenHash   string
+	dialOptions *workspacesdk.DialAgentOptions
+	idleTimeout time.Duration
+	startedAt   time.Time
+
+	// ctx is canceled when the daemon shuts down. Agent connections are
+	// dialed with it, so they outlive the sessions that started them.
+	ctx      context.Context
+	shutdown context.CancelFunc
+
+	mu    sync.Mutex
+	conns map[uuid.UUID]*sshDaemonConn
+	// idle shuts the daemon down once it has had no connections for
+	// idleTimeout.
+	idle *time.Timer
+}
+
+type sshDaemonConn struct {
+	workspace string
+	agentID   uuid.UUID
+	// ready is closed once the agent has been dialed. If that failed, err is
+	// set and conn is nil.
+	ready chan struct{}
+	conn  *workspacesdk.AgentConn
+	err   error
+
+	// The fields below are guarded by sshDaemonServer.mu.
+	connectedAt time.Time
+	sessions    int
+	idleSince   time.Time
+	idle        *time.Timer
+}
+
+func (d *sshDaemonServer) serve(ctx context.Context, ln net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.ctx = ctx
+	d.shutdown = cancel
+
+	d.mu.Lock()
+	d.idle = time.AfterFunc(d.idleTimeout, func() {
+		d.logger.Info(ctx, "ssh daemon is idle, shutting down")
+		cancel()
+	})
+	d.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	var wg sync.WaitGroup
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			cancel()
+			wg.Wait()
+			d.closeAll()
+			return xerrors.Errorf("accept: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.handle(ctx, conn.(*net.UnixConn))
+		}()
+	}
+	wg.Wait()
+	d.closeAll()
+	return nil
+}
+
+func (d *sshDaemonServer) handle(ctx context.Context, conn *net.UnixConn) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	// The client may send the SSH stream right after the request, so the
+	// buffered reader must be used from here on.
+	br := bufio.NewReader(conn)
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		d.logger.Debug(ctx, "read ssh daemon request", slog.Error(err))
+		return
+	}
+	var req sshDaemonRequest
+	err = json.Unmarshal(line, &req)
+	if err != nil {
+		_ = writeSSHDaemonResponse(conn, sshDaemonResponse{Error: fmt.Sprintf("invalid request: %s", err)})
+		return
+	}
+
+	switch req.Type {
+	case sshDaemonRequestStatus:
+		status := d.status()
+		_ = writeSSHDaemonResponse(conn, sshDaemonResponse{Status: &status})
+	case sshDaemonRequestDial:
+		d.proxy(ctx, conn, br, req)
+	case sshDaemonRequestShutdown:
+		d.logger.Info(ctx, "ssh daemon shutdown requested")
+		_ = writeSSHDaemonResponse(conn, sshDaemonResponse{})
+		d.shutdown()
+	default:
+		_ = writeSSHDaemonResponse(conn, sshDaemonResponse{Error: fmt.Sprintf("unknown request type %q", req.Type)})
+	}
+}
+
+// proxy pipes the client connection to the SSH server of the requested agent.
+func (d *sshDaemonServer) proxy(ctx context.Context, conn *net.UnixConn, br *bufio.Reader, req sshDaemonRequest) {
+	logger := d.logger.With(slog.F("workspace", req.Workspace), slog.F("agent_id", req.AgentID))
+	if req.URL != d.client.URL.String() || req.TokenHash != d.tokenHash {
+		// The client logged in again since the daemon started. The open
+		// connections were made with credentials that may not be valid
+		// anymore, so shut down and let the next client start a new daemon.
+		logger.Info(ctx, "client credentials changed, shutting down")
+		_ = writeSSHDaemonResponse(conn, sshDaemonResponse{
+			Error: "the daemon was started with different credentials and is shutting down",
+		})
+		d.shutdown()
+		return
+	}
+
+	c, err := d.acquire(ctx, req)
+	if err != nil {
+		logger.Warn(ctx, "connect to agent", slog.Error(err))
+		_ = writeSSHDaemonResponse(conn, sshDaemonResponse{Error: err.Error()})
+		return
+	}
+	defer d.release(c)
+
+	rawSSH, err := c.conn.SSH(ctx)
+	if err != nil {
+		// The connection is unusable, the next session dials a new one.
+		logger.Warn(ctx, "connect to agent ssh server", slog.Error(err))
+		d.remove(c)
+		_ = writeSSHDaemonResponse(conn, sshDaemonResponse{Error: fmt.Sprintf("connect SSH: %s", err)})
+		return
+	}
+	defer rawSSH.Close()
+	err = writeSSHDaemonResponse(conn, sshDaemonResponse{})
+	if err != nil {
+		return
+	}
+	logger.Debug(ctx, "proxying ssh session")
+
+	// Like rawSSHCopier, half-close each direction once it's done so the SSH
+	// server and client both get to shut down cleanly.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(rawSSH, br)
+		_ = rawSSH.CloseWrite()
+	}()
+	_, _ = io.Copy(conn, rawSSH)
+	_ = conn.CloseWrite()
+
+	t := time.NewTimer(5 * time.Second)
+	defer t.Stop()
+	select {
+	case <-done:
+	case <-t.C:
+	}
+	logger.Debug(ctx, "ssh session ended")
+}
+
+// acquire returns the connection to the agent, dialing it if there isn't one
+// yet. The connection is kept open until release has been called for each
+// call to acquire.
+func (d *sshDaemonServer) acquire(ctx context.Context, req sshDaemonRequest) (*sshDaemonConn, error) {
+	d.mu.Lock()
+	c, ok := d.conns[req.AgentID]
+	if !ok {
+		c = &sshDaemonConn{
+			workspace: req.Workspace,
+			agentID:   req.AgentID,
+			ready:     make(chan struct{}),
+		}
+		d.conns[req.AgentID] = c
+		go d.dial(c)
+	}
+	c.sessions++
+	if c.idle != nil {
+		c.idle.Stop()
+		c.idle = nil
+	}
+	d.idle.Stop()
+	d.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		d.release(c)
+		return nil, ctx.Err()
+	case <-c.ready:
+	}
+	if c.err != nil {
+		d.release(c)
+		return nil, c.err
+	}
+	return c, nil
+}
+
+func (d *sshDaemonServer) dial(c *sshDaemonConn) {
+	defer close(c.ready)
+	ctx, cancel := context.WithTimeout(d.ctx, sshDaemonDialTimeout)
+	defer cancel()
+
+	conn, err := workspacesdk.New(d.client).DialAgent(ctx, c.agentID, d.dialOptions)
+	if err != nil {
+		c.err = xerrors.Errorf("dial agent: %w", err)
+		d.remove(c)
+		return
+	}
+	if !conn.AwaitReachable(ctx) {
+		_ = conn.Close()
+		c.err = xerrors.Errorf("workspace agent not reachable in time: %w", ctx.Err())
+		d.remove(c)
+		return
+	}
+	d.logger.Info(ctx, "connected to agent", slog.F("workspace", c.workspace), slog.F("agent_id", c.agentID))
+
+	d.mu.Lock()
+	c.conn = conn
+	c.connectedAt = time.Now()
+	d.mu.Unlock()
+}
+
+func (d *sshDaemonServer) release(c *sshDaemonConn) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c.sessions--
+	if c.sessions > 0 || d.conns[c.agentID] != c {
+		return
+	}
+	c.idleSince = time.Now()
+	c.idle = time.AfterFunc(d.idleTimeout, func() {
+		d.mu.Lock()
+		if c.sessions > 0 || d.conns[c.agentID] != c {
+			d.mu.Unlock()
+			return
+		}
+		conn := d.removeLocked(c)
+		d.mu.Unlock()
+
+		d.logger.Info(d.ctx, "closed idle agent connection", slog.F("workspace", c.workspace), slog.F("agent_id", c.agentID))
+		if conn != nil {
+			_ = conn.Close()
+		}
+	})
+}
+
+// remove forgets and closes the connection.
+func (d *sshDaemonServer) remove(c *sshDaemonConn) {
+	d.mu.Lock()
+	conn := d.removeLocked(c)
+	d.mu.Unlock()
+
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
+
+// removeLocked forgets the connection and returns the agent connection the
+// caller must close.
+func (d *sshDaemonServer) removeLocked(c *sshDaemonConn) *workspacesdk.AgentConn {
+	if d.conns[c.agentID] == c {
+		delete(d.conns, c.agentID)
+	}
+	if c.idle != nil {
+		c.idle.Stop()
+		c.idle = nil
+	}
+	if len(d.conns) == 0 {
+		d.idle.Reset(d.idleTimeout)
+	}
+	return c.conn
+}
+
+func (d *sshDaemonServer) closeAll() {
+	d.mu.Lock()
+	conns := make([]*sshDaemonConn, 0, len(d.conns))
+	for _, c := range d.conns {
+		conns = append(conns, c)
+	}
+	d.mu.Unlock()
+
+	for _, c := range conns {
+		<-c.ready
+		d.remove(c)
+	}
+	d.idle.Stop()
+}
+
+func (d *sshDaemonServer) status() sshDaemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := sshDaemonStatus{
+		PID:         os.Getpid(),
+		URL:         d.client.URL.String(),
+		StartedAt:   d.startedAt,
+		IdleTimeout: d.idleTimeout,
+		Connections: make([]sshDaemonStatusConn, 0, len(d.conns)),
+	}
+	for _, c := range d.conns {
+		if c.conn == nil {
+			// Still dialing.
+			continue
+		}
+		row := sshDaemonStatusConn{
+			Workspace:   c.workspace,
+			AgentID:     c.agentID,
+			Sessions:    c.sessions,
+			ConnectedAt: c.connectedAt,
+			IdleSince:   "-",
+		}
+		if c.sessions == 0 {
+			row.IdleSince = c.idleSince.Format(time.RFC3339)
+		}
+		status.Connections = append(status.Connections, row)
+	}
+	sort.Slice(status.Connections, func(i, j int) bool {
+		return status.Connections[i].Workspace < status.Connections[j].Workspace
+	})
+	return status
+}
+
+func sshDaemonTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeSSHDaemonResponse(w io.Writer, resp sshDaemonResponse) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// sshDaemonStream is an SSH stream proxied by the daemon.
+type sshDaemonStream struct {
+	conn *net.UnixConn
+	// br holds any data the daemon sent after its response.
+	br *bufio.Reader
+}
+
+func (s *sshDaemonStream) Read(p []byte) (int, error) {
+	return s.br.Read(p)
+}
+
+func (s *sshDaemonStream) Write(p []byte) (int, error) {
+	return s.conn.Write(p)
+}
+
+func (s *sshDaemonStream) CloseWrite() error {
+	return s.conn.CloseWrite()
+}
+
+func (s *sshDaemonStream) Close() error {
+	return s.conn.Close()
+}
+
+// sshDaemonRoundTrip sends a request to the daemon listening on socket, and
+// returns its response along with the connection, which carries the SSH
+// stream for dial requests.
+func sshDaemonRoundTrip(ctx context.Context, socket string, req sshDaemonRequest) (*sshDaemonStream, sshDaemonResponse, error) {
+	var resp sshDaemonResponse
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", socket)
+	if err != nil {
+		return nil, resp, err
+	}
+	stream := &sshDaemonStream{conn: conn.(*net.UnixConn), br: bufio.NewReader(conn)}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, resp, err
+	}
+	_, err = conn.Write(append(b, '\n'))
+	if err != nil {
+		_ = conn.Close()
+		return nil, resp, xerrors.Errorf("write request: %w", err)
+	}
+	line, err := stream.br.ReadBytes('\n')
+	if err != nil {
+		_ = conn.Close()
+		return nil, resp, xerrors.Errorf("read response: %w", err)
+	}
+	err = json.Unmarshal(line, &resp)
+	if err != nil {
+		_ = conn.Close()
+		return nil, resp, xerrors.Errorf("decode response: %w", err)
+	}
+	if resp.Error != "" {
+		_ = conn.Close()
+		return nil, resp, xerrors.New(resp.Error)
+	}
+	if !stop() {
+		// The context was canceled after the response was read.
+		return nil, resp, ctx.Err()
+	}
+	return stream, resp, nil
+}
+
+// dialSSHDaemon returns the SSH stream of the agent, proxied by the daemon.
+// The daemon is started if it isn't running yet.
+func (r *RootCmd) dialSSHDaemon(ctx context.Context, client *codersdk.Client, workspace codersdk.Workspace, agent codersdk.WorkspaceAgent, idleTimeout time.Duration) (*sshDaemonStream, error) {
+	socket := filepath.Join(r.createConfig().SSHDaemonPath(), sshDaemonSocketName)
+	req := sshDaemonRequest{
+		Type:      sshDaemonRequestDial,
+		URL:       client.URL.String(),
+		TokenHash: sshDaemonTokenHash(client.SessionToken()),
+		AgentID:   agent.ID,
+		Workspace: fmt.Sprintf("%s/%s.%s", workspace.OwnerName, workspace.Name, agent.Name),
+	}
+	stream, _, err := sshDaemonRoundTrip(ctx, socket, req)
+	if err == nil {
+		return stream, nil
+	}
+	var opErr *net.OpError
+	if !xerrors.As(err, &opErr) || opErr.Op != "dial" {
+		// The daemon is running, but couldn't serve the request.
+		return nil, err
+	}
+
+	err = r.startSSHDaemon(idleTimeout)
+	if err != nil {
+		return nil, xerrors.Errorf("start ssh daemon: %w", err)
+	}
+	startCtx, cancel := context.WithTimeout(ctx, sshDaemonStartTimeout)
+	defer cancel()
+	for retrier := retry.New(10*time.Millisecond, 500*time.Millisecond); retrier.Wait(startCtx); {
+		stream, _, err = sshDaemonRoundTrip(ctx, socket, req)
+		if err == nil {
+			return stream, nil
+		}
+		if !xerrors.As(err, &opErr) || opErr.Op != "dial" {
+			return nil, err
+		}
+	}
+	return nil, xerrors.Errorf("ssh daemon did not start in time: %w", err)
+}
+
+// startSSHDaemon starts the daemon as a detached background process using
+// the same global configuration as this invocation.
+func (r *RootCmd) startSSHDaemon(idleTimeout time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return xerrors.Errorf("get executable path: %w", err)
+	}
+	dir := r.createConfig().SSHDaemonPath()
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return xerrors.Errorf("create daemon directory: %w", err)
+	}
+
+	args := []string{"--global-config", string(r.createConfig())}
+	for _, h := range r.header {
+		args = append(args, "--"+varHeader, h)
+	}
+	if r.headerCommand != "" {
+		args = append(args, "--"+varHeaderCommand, r.headerCommand)
+	}
+	if r.disableDirect {
+		args = append(args, "--"+varDisableDirect)
+	}
+	if r.disableNetworkTelemetry {
+		args = append(args, "--"+varDisableNetworkTelemetry)
+	}
+	if r.verbose {
+		args = append(args, "--"+varVerbose)
+	}
+	args = append(args, "ssh-daemon", "--idle-timeout", idleTimeout.String())
+
+	logFile, err := os.OpenFile(filepath.Join(dir, sshDaemonLogName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return xerrors.Errorf("open daemon log: %w", err)
+	}
+	defer logFile.Close()
+
+	// The daemon must outlive this process, so it isn't tied to a context.
+	//nolint:gosec // The executable is this binary.
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = sshDaemonSysProcAttr()
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// stopSSHDaemon shuts down the daemon using the given configuration, if it's
+// running.
+func stopSSHDaemon(ctx context.Context, root config.Root) error {
+	socket := filepath.Join(root.SSHDaemonPath(), sshDaemonSocketName)
+	stream, _, err := sshDaemonRoundTrip(ctx, socket, sshDaemonRequest{Type: sshDaemonRequestShutdown})
+	if err != nil {
+		var opErr *net.OpError
+		if xerrors.As(err, &opErr) && opErr.Op == "dial" {
+			return nil
+		}
+		return err
+	}
+	return stream.Close()
+}
+
+// sshDaemonStatusHandler prints the state of the daemon for
+// `coder ssh --daemon-status`.
+func (r *RootCmd) sshDaemonStatusHandler(inv *serpent.Invocation) error {
+	socket := filepath.Join(r.createConfig().SSHDaemonPath(), sshDaemonSocketName)
+	_, resp, err := sshDaemonRoundTrip(inv.Context(), socket, sshDaemonRequest{Type: sshDaemonRequestStatus})
+	if err != nil {
+		var opErr *net.OpError
+		if xerrors.As(err, &opErr) && opErr.Op == "dial" {
+			_, _ = fmt.Fprintln(inv.Stdout, "The SSH daemon is not running.")
+			return nil
+		}
+		return xerrors.Errorf("get ssh daemon status: %w", err)
+	}
+	if resp.Status == nil {
+		return xerrors.New("ssh daemon did not return its status")
+	}
+	status := resp.Status
+
+	_, _ = fmt.Fprintf(inv.Stdout, "PID:          %d\n", status.PID)
+	_, _ = fmt.Fprintf(inv.Stdout, "URL:          %s\n", status.URL)
+	_, _ = fmt.Fprintf(inv.Stdout, "Started at:   %s\n", status.StartedAt.Format(time.RFC3339))
+	_, _ = fmt.Fprintf(inv.Stdout, "Idle timeout: %s\n", status.IdleTimeout)
+	if len(status.Connections) == 0 {
+		_, _ = fmt.Fprintln(inv.Stdout, "\nNo open connections.")
+		return nil
+	}
+	out, err := cliui.DisplayTable(status.Connections, "", nil)
+	if err != nil {
+		return xerrors.Errorf("display table: %w", err)
+	}
+	_, _ = fmt.Fprintln(inv.Stdout, "\n"+out)
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package cli
+
+import "syscall"
+
+// sshDaemonSysProcAttr starts the daemon in its own session, so it isn't
+// killed along with the terminal or ssh process that started it.
+func sshDaemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setsid: true,
+	}
+}
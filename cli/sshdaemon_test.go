@@ -0,0 +1,207 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/coder/coder/v2/agent/agenttest"
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/cli/config"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestSSHDaemon(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses Unix sockets.")
+	}
+
+	t.Run("ReuseConnection", func(t *testing.T) {
+		t.Parallel()
+
+		client, workspace, agentToken := setupWorkspaceForAgent(t)
+		_ = agenttest.New(t, client.URL, agentToken)
+		coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		// The daemon must not exit between sessions, or the second one
+		// would try to start a new daemon.
+		root, daemonDone := startSSHDaemon(ctx, t, client, testutil.WaitLong)
+
+		var connectedAt time.Time
+		for i := 0; i < 2; i++ {
+			closeSession := sshDaemonSession(ctx, t, root, workspace.Name)
+
+			// The session is proxied over the connection opened for the
+			// first one.
+			conns := sshDaemonConnections(ctx, t, root)
+			require.Len(t, conns, 1)
+			assert.Equal(t, 1, conns[0].Sessions)
+			if i == 0 {
+				connectedAt = conns[0].ConnectedAt
+			} else {
+				assert.True(t, connectedAt.Equal(conns[0].ConnectedAt), "connection was not reused")
+			}
+
+			inv, _ := clitest.New(t, "--global-config", string(root), "ssh", "--daemon-status")
+			var out bytes.Buffer
+			inv.Stdout = &out
+			err := inv.WithContext(ctx).Run()
+			require.NoError(t, err)
+			assert.Contains(t, out.String(), "myuser/myworkspace.")
+			assert.Contains(t, out.String(), client.URL.String())
+
+			closeSession()
+		}
+
+		// Logging out stops the daemon.
+		inv, _ := clitest.New(t, "--global-config", string(root), "logout", "-y")
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		testutil.TryReceive(ctx, t, daemonDone)
+	})
+
+	t.Run("IdleTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		root, daemonDone := startSSHDaemon(ctx, t, client, 100*time.Millisecond)
+		testutil.TryReceive(ctx, t, daemonDone)
+
+		inv, _ := clitest.New(t, "--global-config", string(root), "ssh", "--daemon-status")
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		require.Contains(t, out.String(), "The SSH daemon is not running.")
+	})
+
+	t.Run("CredentialsChanged", func(t *testing.T) {
+		t.Parallel()
+
+		client, workspace, agentToken := setupWorkspaceForAgent(t)
+		_ = agenttest.New(t, client.URL, agentToken)
+		coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		root, daemonDone := startSSHDaemon(ctx, t, client, testutil.WaitLong)
+		sshDaemonSession(ctx, t, root, workspace.Name)()
+		require.Len(t, sshDaemonConnections(ctx, t, root), 1)
+
+		// Logging in again makes the daemon shut down, and the session falls
+		// back to connecting directly.
+		token, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+		err = root.Session().Write(token.Key)
+		require.NoError(t, err)
+		sshDaemonSession(ctx, t, root, workspace.Name)()
+		testutil.TryReceive(ctx, t, daemonDone)
+	})
+}
+
+// startSSHDaemon runs the SSH daemon in the background, and waits for it to
+// listen.
+func startSSHDaemon(ctx context.Context, t *testing.T, client *codersdk.Client, idleTimeout time.Duration) (config.Root, <-chan struct{}) {
+	t.Helper()
+
+	inv, root := clitest.New(t, "ssh-daemon", "--idle-timeout", idleTimeout.String())
+	clitest.SetupConfig(t, client, root)
+	done := tGo(t, func() {
+		err := inv.WithContext(ctx).Run()
+		assert.NoError(t, err)
+	})
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(root.SSHDaemonPath(), "daemon.sock"))
+		return err == nil
+	}, testutil.WaitShort, testutil.IntervalFast)
+	return root, done
+}
+
+// sshDaemonSession starts an SSH session with `coder ssh --stdio --daemon`,
+// and returns a function that ends it.
+func sshDaemonSession(ctx context.Context, t *testing.T, root config.Root, workspaceName string) func() {
+	t.Helper()
+
+	clientOutput, clientInput := io.Pipe()
+	serverOutput, serverInput := io.Pipe()
+	closePipes := func() {
+		for _, c := range []io.Closer{clientOutput, clientInput, serverOutput, serverInput} {
+			_ = c.Close()
+		}
+	}
+	t.Cleanup(closePipes)
+
+	inv, _ := clitest.New(t, "--global-config", string(root), "ssh", "--stdio", "--daemon", workspaceName)
+	inv.Stdin = clientOutput
+	inv.Stdout = serverInput
+	inv.Stderr = io.Discard
+	cmdDone := tGo(t, func() {
+		err := inv.WithContext(ctx).Run()
+		assert.NoError(t, err)
+	})
+
+	conn, channels, requests, err := ssh.NewClientConn(&stdioConn{
+		Reader: serverOutput,
+		Writer: clientInput,
+	}, "", &ssh.ClientConfig{
+		// #nosec
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(t, err)
+	sshClient := ssh.NewClient(conn, channels, requests)
+	session, err := sshClient.NewSession()
+	require.NoError(t, err)
+	err = session.Run("sh -c exit")
+	require.NoError(t, err)
+
+	return func() {
+		err := sshClient.Close()
+		require.NoError(t, err)
+		_ = clientOutput.Close()
+		testutil.TryReceive(ctx, t, cmdDone)
+		closePipes()
+	}
+}
+
+type sshDaemonConnection struct {
+	Sessions    int       `json:"sessions"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// sshDaemonConnections asks the daemon for the connections it has open.
+func sshDaemonConnections(ctx context.Context, t *testing.T, root config.Root) []sshDaemonConnection {
+	t.Helper()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", filepath.Join(root.SSHDaemonPath(), "daemon.sock"))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(`{"type":"status"}` + "\n"))
+	require.NoError(t, err)
+
+	var resp struct {
+		Status struct {
+			Connections []sshDaemonConnection `json:"connections"`
+		} `json:"status"`
+	}
+	err = json.NewDecoder(conn).Decode(&resp)
+	require.NoError(t, err)
+	return resp.Status.Connections
+}
@@ -0,0 +1,16 @@
+package cli
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// sshDaemonSysProcAttr starts the daemon without a console, so it isn't
+// killed along with the terminal or ssh process that started it.
+func sshDaemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+		HideWindow:    true,
+	}
+}
@@ -0,0 +1,708 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/cli/config"
+	"github.com/coder/coder/v2/cli/filesync"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/serpent"
+)
+
+const (
+	// syncHeartbeatInterval is how often a running sync session records that
+	// it's alive. Sessions that haven't done so for syncStaleAfter are
+	// reported as stopped.
+	syncHeartbeatInterval = 5 * time.Second
+	syncStaleAfter        = 3 * syncHeartbeatInterval
+	// syncMaxDeletions is the number of files and directories a sync may
+	// delete without --force.
+	syncMaxDeletions = 100
+)
+
+var syncNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+func (r *RootCmd) sync() *serpent.Command {
+	cmd := &serpent.Command{
+		Annotations: workspaceCommand,
+		Use:         "sync",
+		Short:       "Keep a local directory in sync with a directory in a workspace",
+		Long: "Changes on either side are copied to the other side. Files that changed on both sides since the last sync " +
+			"are reported as conflicts and left alone until one of the copies is deleted, or both copies are identical. " +
+			"Paths listed in a " + filesync.IgnoreFile + " file in the local directory aren't synced, using a subset of the .gitignore syntax.\n" + FormatExamples(
+			Example{
+				Description: "Sync a project with a workspace",
+				Command:     "coder sync start my-workspace ./project project",
+			},
+			Example{
+				Description: "Show the status of all sync sessions",
+				Command:     "coder sync status",
+			},
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.syncStart(),
+			r.syncStatus(),
+			r.syncPause(),
+			r.syncResume(),
+			r.syncStop(),
+		},
+	}
+	return cmd
+}
+
+// syncSession is the configuration of a sync session.
+type syncSession struct {
+	Name      string        `json:"name"`
+	Workspace string        `json:"workspace"`
+	Local     string        `json:"local"`
+	Remote    string        `json:"remote"`
+	Ignore    []string      `json:"ignore"`
+	Interval  time.Duration `json:"interval"`
+	Paused    bool          `json:"paused"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// syncState is written by the process running a sync session.
+type syncState struct {
+	PID       int                 `json:"pid"`
+	Heartbeat time.Time           `json:"heartbeat"`
+	Syncing   bool                `json:"syncing"`
+	LastSync  time.Time           `json:"last_sync"`
+	Error     string              `json:"error"`
+	Conflicts []filesync.Conflict `json:"conflicts"`
+	Problems  []filesync.Problem  `json:"problems"`
+}
+
+type syncStore struct {
+	dir string
+}
+
+func newSyncStore(root config.Root, name string) syncStore {
+	return syncStore{dir: filepath.Join(root.SyncPath(), name)}
+}
+
+func (s syncStore) session() (syncSession, error) {
+	var session syncSession
+	err := readSyncFile(filepath.Join(s.dir, "session.json"), &session)
+	return session, err
+}
+
+func (s syncStore) writeSession(session syncSession) error {
+	return writeSyncFile(filepath.Join(s.dir, "session.json"), session)
+}
+
+func (s syncStore) state() (syncState, error) {
+	var state syncState
+	err := readSyncFile(filepath.Join(s.dir, "state.json"), &state)
+	return state, err
+}
+
+func (s syncStore) writeState(state syncState) error {
+	return writeSyncFile(filepath.Join(s.dir, "state.json"), state)
+}
+
+func (s syncStore) base() (map[string]filesync.BaseEntry, error) {
+	base := map[string]filesync.BaseEntry{}
+	err := readSyncFile(filepath.Join(s.dir, "base.json"), &base)
+	if errors.Is(err, fs.ErrNotExist) {
+		return base, nil
+	}
+	return base, err
+}
+
+func (s syncStore) writeBase(base map[string]filesync.BaseEntry) error {
+	return writeSyncFile(filepath.Join(s.dir, "base.json"), base)
+}
+
+// running reports whether a process is running the session.
+func (s syncStore) running() bool {
+	state, err := s.state()
+	return err == nil && time.Since(state.Heartbeat) < syncStaleAfter
+}
+
+func readSyncFile(name string, v any) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeSyncFile replaces a file at once, so that readers never see a partial
+// write.
+func writeSyncFile(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(name), 0o700)
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	err = os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+func (r *RootCmd) syncStart() *serpent.Command {
+	var (
+		name             string
+		ignore           []string
+		interval         time.Duration
+		force            bool
+		appearanceConfig codersdk.AppearanceConfig
+	)
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "start <workspace> <local> <remote>",
+		Short: "Start syncing a local directory with a directory in a workspace",
+		Long: "Runs in the foreground until interrupted or stopped with \"coder sync stop\". The remote directory is relative " +
+			"to the home directory of the workspace user. Starting a session with the name of a stopped session resumes it " +
+			"without treating earlier changes as conflicts.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(3),
+			r.InitClient(client),
+			initAppearance(client, &appearanceConfig),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			if interval <= 0 {
+				return xerrors.New("--interval must be greater than zero")
+			}
+			local, err := filepath.Abs(inv.Args[1])
+			if err != nil {
+				return xerrors.Errorf("resolve local directory: %w", err)
+			}
+			remote := inv.Args[2]
+			if remote == "~" || remote == "" {
+				remote = "."
+			}
+			remote = strings.TrimPrefix(remote, "~/")
+			if name == "" {
+				name = filepath.Base(local)
+			}
+			if !syncNameRegex.MatchString(name) {
+				return xerrors.Errorf("invalid sync session name %q, pass a name with --name", name)
+			}
+			_, err = filesync.NewIgnore(ignore...)
+			if err != nil {
+				return err
+			}
+
+			store := newSyncStore(r.createConfig(), name)
+			session, err := store.session()
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				session = syncSession{
+					Name:      name,
+					Workspace: inv.Args[0],
+					Local:     local,
+					Remote:    remote,
+					CreatedAt: time.Now(),
+				}
+			case err != nil:
+				return xerrors.Errorf("read sync session: %w", err)
+			case store.running():
+				return xerrors.Errorf("sync session %q is already running", name)
+			case session.Workspace != inv.Args[0] || session.Local != local || session.Remote != remote:
+				return xerrors.Errorf("sync session %q already exists for %s and %s:%s, stop it first or pass a different --name", name, session.Local, session.Workspace, session.Remote)
+			}
+			session.Ignore = ignore
+			session.Interval = interval
+			session.Paused = false
+			err = store.writeSession(session)
+			if err != nil {
+				return xerrors.Errorf("write sync session: %w", err)
+			}
+			base, err := store.base()
+			if err != nil {
+				return xerrors.Errorf("read sync state: %w", err)
+			}
+
+			_, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, false, session.Workspace)
+			if err != nil {
+				return err
+			}
+			err = cliui.Agent(ctx, inv.Stderr, workspaceAgent.ID, cliui.AgentOptions{
+				Fetch:   client.WorkspaceAgent,
+				Wait:    false,
+				DocsURL: appearanceConfig.DocsURL,
+			})
+			if err != nil {
+				return xerrors.Errorf("await agent: %w", err)
+			}
+
+			opts := &workspacesdk.DialAgentOptions{}
+			if r.verbose {
+				opts.Logger = inv.Logger.AppendSinks(sloghuman.Sink(inv.Stderr)).Leveled(slog.LevelDebug)
+			}
+			if r.disableDirect {
+				opts.BlockEndpoints = true
+			}
+			if !r.disableNetworkTelemetry {
+				opts.EnableTelemetry = true
+			}
+			conn, err := workspacesdk.New(client).DialAgent(ctx, workspaceAgent.ID, opts)
+			if err != nil {
+				return xerrors.Errorf("dial workspace agent: %w", err)
+			}
+			defer conn.Close()
+
+			err = os.MkdirAll(local, 0o755)
+			if err != nil {
+				return xerrors.Errorf("create local directory: %w", err)
+			}
+
+			s := &syncRunner{
+				store:   store,
+				session: session,
+				conn:    conn,
+				engine: &filesync.Engine{
+					Local:      filesync.LocalFS{Root: local},
+					Base:       base,
+					AllowEmpty: force,
+				},
+				state: syncState{PID: os.Getpid()},
+			}
+			if !force {
+				s.engine.MaxDeletions = syncMaxDeletions
+			}
+			defer s.close()
+			go s.heartbeat(ctx)
+
+			cliui.Infof(inv.Stderr, "Syncing %s with %s:%s. Press Ctrl+C to stop.", local, session.Workspace, session.Remote)
+			return s.run(ctx, inv)
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "name",
+			Description: "The name of the sync session. Defaults to the name of the local directory.",
+			Value:       serpent.StringOf(&name),
+		},
+		{
+			Flag:        "ignore",
+			Description: "Patterns of paths to exclude from the sync, in addition to those in the " + filesync.IgnoreFile + " file.",
+			Value:       serpent.StringArrayOf(&ignore),
+		},
+		{
+			Flag:        "interval",
+			Description: "How often to check both directories for changes.",
+			Default:     "2s",
+			Value:       serpent.DurationOf(&interval),
+		},
+		{
+			Flag: "force",
+			Description: fmt.Sprintf("Sync even if a directory that was synced before is now missing or empty, or the sync would delete more "+
+				"than %d files and directories. These syncs fail by default, so that a lost directory doesn't delete the files on the other side.", syncMaxDeletions),
+			Value: serpent.BoolOf(&force),
+		},
+	}
+	return cmd
+}
+
+type syncRunner struct {
+	store   syncStore
+	session syncSession
+	conn    *workspacesdk.AgentConn
+	engine  *filesync.Engine
+
+	sshClient  *gossh.Client
+	sftpClient *sftp.Client
+
+	mu    sync.Mutex
+	state syncState
+}
+
+func (s *syncRunner) updateState(fn func(state *syncState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.state)
+	s.state.Heartbeat = time.Now()
+	// The state is informational, and a failed write is retried by the next
+	// heartbeat.
+	_ = s.store.writeState(s.state)
+}
+
+func (s *syncRunner) heartbeat(ctx context.Context) {
+	ticker := time.NewTicker(syncHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		s.updateState(func(*syncState) {})
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *syncRunner) connect(ctx context.Context) error {
+	if s.sftpClient != nil {
+		return nil
+	}
+	sshClient, err := s.conn.SSHClient(ctx)
+	if err != nil {
+		return xerrors.Errorf("connect to workspace agent: %w", err)
+	}
+	sftpClient, err := newCpSFTPClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return err
+	}
+	remote := filesync.SFTPFS{Client: sftpClient, Root: s.session.Remote}
+	if len(s.engine.Base) > 0 && !s.engine.AllowEmpty {
+		// The directory was synced before, so it's only missing if it was
+		// lost, and recreating it would delete the local files.
+		_, err = remote.Stat("")
+		if errors.Is(err, fs.ErrNotExist) {
+			err = xerrors.Errorf("the remote directory %q no longer exists, pass --force to create it and sync anyway", s.session.Remote)
+		} else if err != nil {
+			err = xerrors.Errorf("stat remote directory: %w", err)
+		}
+	} else {
+		err = remote.MkdirAll("")
+		if err != nil {
+			err = xerrors.Errorf("create remote directory: %w", err)
+		}
+	}
+	if err != nil {
+		_ = sftpClient.Close()
+		_ = sshClient.Close()
+		return err
+	}
+	s.sshClient, s.sftpClient = sshClient, sftpClient
+	s.engine.Remote = remote
+	return nil
+}
+
+func (s *syncRunner) close() {
+	if s.sftpClient != nil {
+		_ = s.sftpClient.Close()
+		_ = s.sshClient.Close()
+		s.sftpClient, s.sshClient = nil, nil
+	}
+}
+
+func (s *syncRunner) run(ctx context.Context, inv *serpent.Invocation) error {
+	var (
+		lastErr       string
+		lastConflicts = map[string]bool{}
+	)
+	for {
+		session, err := s.store.session()
+		if errors.Is(err, fs.ErrNotExist) {
+			cliui.Infof(inv.Stderr, "Sync session %q was stopped.", s.session.Name)
+			return nil
+		}
+		if err == nil {
+			s.session.Paused = session.Paused
+		}
+
+		if !s.session.Paused {
+			err = s.syncOnce(ctx)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				// Connection errors are retried with a new connection.
+				s.close()
+				if err.Error() != lastErr {
+					cliui.Warnf(inv.Stderr, "Sync failed, retrying: %s", err)
+				}
+				lastErr = err.Error()
+			} else {
+				lastErr = ""
+			}
+
+			s.mu.Lock()
+			conflicts := s.state.Conflicts
+			s.mu.Unlock()
+			current := map[string]bool{}
+			for _, c := range conflicts {
+				current[c.Path] = true
+				if !lastConflicts[c.Path] {
+					cliui.Warnf(inv.Stderr, "Conflict: %s changed on both sides. Delete one of the copies or make them identical to resolve it.", c.Path)
+				}
+			}
+			lastConflicts = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.session.Interval):
+		}
+	}
+}
+
+func (s *syncRunner) syncOnce(ctx context.Context) error {
+	s.updateState(func(state *syncState) {
+		state.Syncing = true
+	})
+	res, err := s.sync(ctx)
+	s.updateState(func(state *syncState) {
+		state.Syncing = false
+		if err != nil {
+			state.Error = err.Error()
+			return
+		}
+		state.Error = ""
+		state.LastSync = time.Now()
+		state.Conflicts = res.Conflicts
+		state.Problems = res.Problems
+	})
+	return err
+}
+
+func (s *syncRunner) sync(ctx context.Context) (filesync.Result, error) {
+	err := s.connect(ctx)
+	if err != nil {
+		return filesync.Result{}, err
+	}
+	// The ignore file is read on every sync, so that changes to it apply
+	// without restarting the session.
+	ignore, err := filesync.NewIgnore(s.session.Ignore...)
+	if f, openErr := os.Open(filepath.Join(s.session.Local, filesync.IgnoreFile)); openErr == nil {
+		ignore, err = filesync.ReadIgnore(f, s.session.Ignore...)
+		_ = f.Close()
+	}
+	if err != nil {
+		return filesync.Result{}, err
+	}
+	s.engine.Ignore = ignore
+
+	res, err := s.engine.Sync(ctx)
+	if errors.Is(err, filesync.ErrUnsafeDeletion) {
+		err = xerrors.Errorf("%s, pass --force to sync anyway", err)
+	}
+	// Paths synced before an error are recorded, so they aren't synced
+	// again.
+	if writeErr := s.store.writeBase(s.engine.Base); writeErr != nil && err == nil {
+		err = xerrors.Errorf("write sync state: %w", writeErr)
+	}
+	return res, err
+}
+
+type syncStatusRow struct {
+	syncSession `table:"-"`
+	State       syncState `json:"state" table:"-"`
+
+	// For table format:
+	Name           string `json:"-" table:"name,default_sort"`
+	Workspace      string `json:"-" table:"workspace"`
+	Local          string `json:"-" table:"local"`
+	Remote         string `json:"-" table:"remote"`
+	Status         string `json:"status" table:"status"`
+	LastSync       string `json:"-" table:"last sync"`
+	ConflictsCount int    `json:"-" table:"conflicts"`
+}
+
+func syncStatus(store syncStore, session syncSession) syncStatusRow {
+	row := syncStatusRow{
+		syncSession: session,
+		Status:      "stopped",
+		Name:        session.Name,
+		Workspace:   session.Workspace,
+		Local:       session.Local,
+		Remote:      session.Remote,
+		LastSync:    "never",
+	}
+	state, err := store.state()
+	if err != nil {
+		return row
+	}
+	row.State = state
+	row.ConflictsCount = len(state.Conflicts)
+	if !state.LastSync.IsZero() {
+		row.LastSync = state.LastSync.Format(time.DateTime)
+	}
+	switch {
+	case !store.running():
+	case session.Paused:
+		row.Status = "paused"
+	case state.Error != "":
+		row.Status = "error"
+	case state.Syncing:
+		row.Status = "syncing"
+	default:
+		row.Status = "watching"
+	}
+	return row
+}
+
+func (r *RootCmd) syncStatus() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]syncStatusRow{}, []string{"name", "workspace", "local", "remote", "status", "last sync", "conflicts"}),
+		cliui.JSONFormat(),
+	)
+	cmd := &serpent.Command{
+		Use:   "status [name]",
+		Short: "Show the status of sync sessions",
+		Middleware: serpent.Chain(
+			serpent.RequireRangeArgs(0, 1),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			root := r.createConfig()
+			var names []string
+			if len(inv.Args) == 1 {
+				names = []string{inv.Args[0]}
+			} else {
+				entries, err := os.ReadDir(root.SyncPath())
+				if err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return xerrors.Errorf("read sync sessions: %w", err)
+				}
+				for _, entry := range entries {
+					if entry.IsDir() {
+						names = append(names, entry.Name())
+					}
+				}
+			}
+
+			rows := make([]syncStatusRow, 0, len(names))
+			for _, name := range names {
+				store := newSyncStore(root, name)
+				session, err := store.session()
+				if errors.Is(err, fs.ErrNotExist) && len(inv.Args) == 1 {
+					return xerrors.Errorf("sync session %q does not exist", name)
+				}
+				if err != nil {
+					continue
+				}
+				rows = append(rows, syncStatus(store, session))
+			}
+			if len(rows) == 0 && formatter.FormatID() == "table" {
+				cliui.Info(inv.Stderr, "No sync sessions found.")
+				return nil
+			}
+
+			out, err := formatter.Format(inv.Context(), rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			if err != nil || formatter.FormatID() != "table" {
+				return err
+			}
+			// Details are only shown in the table format, JSON includes
+			// them in the state.
+			sort.Slice(rows, func(i, j int) bool {
+				return rows[i].Name < rows[j].Name
+			})
+			for _, row := range rows {
+				if row.State.Error != "" && row.Status != "stopped" {
+					cliui.Warnf(inv.Stderr, "%s: %s", row.Name, row.State.Error)
+				}
+				if len(row.State.Conflicts) > 0 {
+					lines := make([]string, 0, len(row.State.Conflicts))
+					for _, c := range row.State.Conflicts {
+						lines = append(lines, c.Path)
+					}
+					cliui.Warn(inv.Stderr, fmt.Sprintf("%s: files changed on both sides", row.Name), lines...)
+				}
+				for _, p := range row.State.Problems {
+					cliui.Warnf(inv.Stderr, "%s: failed to sync %s: %s", row.Name, p.Path, p.Error)
+				}
+			}
+			return nil
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) syncPause() *serpent.Command {
+	return r.syncSetPaused("pause", "Pause a sync session", true)
+}
+
+func (r *RootCmd) syncResume() *serpent.Command {
+	return r.syncSetPaused("resume", "Resume a paused sync session", false)
+}
+
+func (r *RootCmd) syncSetPaused(use, short string, paused bool) *serpent.Command {
+	return &serpent.Command{
+		Use:   use + " <name>",
+		Short: short,
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			name := inv.Args[0]
+			store := newSyncStore(r.createConfig(), name)
+			session, err := store.session()
+			if errors.Is(err, fs.ErrNotExist) {
+				return xerrors.Errorf("sync session %q does not exist", name)
+			}
+			if err != nil {
+				return xerrors.Errorf("read sync session: %w", err)
+			}
+			session.Paused = paused
+			err = store.writeSession(session)
+			if err != nil {
+				return xerrors.Errorf("write sync session: %w", err)
+			}
+			if paused {
+				cliui.Infof(inv.Stdout, "Paused sync session %q.", name)
+			} else {
+				cliui.Infof(inv.Stdout, "Resumed sync session %q.", name)
+			}
+			if !store.running() {
+				cliui.Warnf(inv.Stderr, "The session isn't running. Run \"coder sync start %s %s %s --name %s\" to start it.", session.Workspace, session.Local, session.Remote, name)
+			}
+			return nil
+		},
+	}
+}
+
+func (r *RootCmd) syncStop() *serpent.Command {
+	return &serpent.Command{
+		Use:   "stop <name>",
+		Short: "Stop and remove a sync session",
+		Long:  "Files that were synced are kept on both sides.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			name := inv.Args[0]
+			store := newSyncStore(r.createConfig(), name)
+			if _, err := store.session(); errors.Is(err, fs.ErrNotExist) {
+				return xerrors.Errorf("sync session %q does not exist", name)
+			}
+			// A running session notices that it was removed and exits.
+			err := os.RemoveAll(store.dir)
+			if err != nil {
+				return xerrors.Errorf("remove sync session: %w", err)
+			}
+			cliui.Infof(inv.Stdout, "Stopped sync session %q.", name)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/agent/agenttest"
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Remote paths in this test are Unix paths")
+	}
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t)
+	_ = agenttest.New(t, client.URL, agentToken)
+	coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	local, remote := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(local, "local.txt"), []byte("local"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(local, "debug.log"), []byte("log"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(remote, "remote.txt"), []byte("remote"), 0o600))
+
+	inv, root := clitest.New(t, "sync", "start", workspace.Name, local, remote, "--interval", "0")
+	clitest.SetupConfig(t, client, root)
+	require.ErrorContains(t, inv.WithContext(ctx).Run(), "--interval must be greater than zero")
+
+	startInv, root := clitest.New(t, "sync", "start", workspace.Name, local, remote, "--name", "project", "--ignore", "*.log", "--interval", "100ms")
+	clitest.SetupConfig(t, client, root)
+	done := make(chan error, 1)
+	go func() {
+		done <- startInv.WithContext(ctx).Run()
+	}()
+
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(filepath.Join(remote, "local.txt"))
+		return err == nil && string(data) == "local"
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(filepath.Join(local, "remote.txt"))
+		return err == nil && string(data) == "remote"
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.NoFileExists(t, filepath.Join(remote, "debug.log"))
+
+	status := func() string {
+		var out bytes.Buffer
+		inv, _ := clitest.New(t, "sync", "status", "project", "--output", "json", "--global-config", string(root))
+		inv.Stdout = &out
+		if err := inv.WithContext(ctx).Run(); err != nil {
+			return err.Error()
+		}
+		var rows []struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(out.Bytes(), &rows); err != nil || len(rows) != 1 {
+			return "unexpected output: " + out.String()
+		}
+		return rows[0].Status
+	}
+	require.Eventually(t, func() bool {
+		return status() == "watching"
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	inv, _ = clitest.New(t, "sync", "pause", "project", "--global-config", string(root))
+	require.NoError(t, inv.WithContext(ctx).Run())
+	assert.Equal(t, "paused", status())
+
+	inv, _ = clitest.New(t, "sync", "resume", "project", "--global-config", string(root))
+	require.NoError(t, inv.WithContext(ctx).Run())
+
+	// A lost remote directory isn't recreated, and doesn't delete the local
+	// files.
+	require.NoError(t, os.RemoveAll(remote))
+	require.Eventually(t, func() bool {
+		return status() == "error"
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.NoDirExists(t, remote)
+	require.FileExists(t, filepath.Join(local, "local.txt"))
+
+	inv, _ = clitest.New(t, "sync", "stop", "project", "--global-config", string(root))
+	require.NoError(t, inv.WithContext(ctx).Run())
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("sync session didn't exit after it was stopped")
+	}
+}
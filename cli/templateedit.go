@@ -11,6 +11,7 @@ import (
 	"github.com/coder/serpent"
 
 	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/coderd/util/ptr"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -29,6 +30,9 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 		failureTTL                     time.Duration
 		dormancyThreshold              time.Duration
 		dormancyAutoDeletion           time.Duration
+		driftDetectionInterval         time.Duration
+		sessionRecording               bool
+		sessionRecordingRetention      time.Duration
 		allowUserCancelWorkspaceJobs   bool
 		allowUserAutostart             bool
 		allowUserAutostop              bool
@@ -162,6 +166,21 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 				deprecated = &deprecationMessage
 			}
 
+			var driftDetectionIntervalMillis *int64
+			if userSetOption(inv, "drift-detection-interval") {
+				driftDetectionIntervalMillis = ptr.Ref(driftDetectionInterval.Milliseconds())
+			}
+
+			var sessionRecordingEnabled *bool
+			if userSetOption(inv, "session-recording") {
+				sessionRecordingEnabled = ptr.Ref(sessionRecording)
+			}
+
+			var sessionRecordingRetentionMillis *int64
+			if userSetOption(inv, "session-recording-retention") {
+				sessionRecordingRetentionMillis = ptr.Ref(sessionRecordingRetention.Milliseconds())
+			}
+
 			var disableEveryoneGroup bool
 			if userSetOption(inv, "private") {
 				disableEveryoneGroup = disableEveryone
@@ -190,6 +209,10 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 				RequireActiveVersion:           requireActiveVersion,
 				DeprecationMessage:             deprecated,
 				DisableEveryoneGroupAccess:     disableEveryoneGroup,
+				DriftDetectionIntervalMillis:   driftDetectionIntervalMillis,
+
+				SessionRecordingEnabled:         sessionRecordingEnabled,
+				SessionRecordingRetentionMillis: sessionRecordingRetentionMillis,
 			}
 
 			_, err = client.UpdateTemplateMeta(inv.Context(), template.ID, req)
@@ -271,6 +294,21 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 			Default:     "0h",
 			Value:       serpent.DurationOf(&dormancyAutoDeletion),
 		},
+		{
+			Flag:        "drift-detection-interval",
+			Description: "Specify how often running workspaces are checked for changes made to their resources outside of Coder. Must be at least 1h. Pass 0 to disable drift detection.",
+			Value:       serpent.DurationOf(&driftDetectionInterval),
+		},
+		{
+			Flag:        "session-recording",
+			Description: "Record SSH and reconnecting PTY sessions of workspaces on this template. Recordings can be viewed with \"coder sessions\".",
+			Value:       serpent.BoolOf(&sessionRecording),
+		},
+		{
+			Flag:        "session-recording-retention",
+			Description: "Specify how long session recordings are kept. Must be at least 1h. Pass 0 to keep recordings forever.",
+			Value:       serpent.DurationOf(&sessionRecordingRetention),
+		},
 		{
 			Flag:        "allow-user-cancel-workspace-jobs",
 			Description: "Allow users to cancel in-progress workspace jobs.",
@@ -87,6 +87,12 @@ func (r *RootCmd) templatePush() *serpent.Command {
 
 			message := uploadFlags.templateMessage(inv)
 
+			// Pulumi projects are detected by their project file, since
+			// the provisioner flag is only meant for tests.
+			if !inv.ParsedFlags().Changed("test.provisioner") && !uploadFlags.stdin(inv) && isPulumiProject(uploadFlags.directory) {
+				provisioner = string(codersdk.ProvisionerTypePulumi)
+			}
+
 			var varsFiles []string
 			if !uploadFlags.stdin(inv) {
 				varsFiles, err = codersdk.DiscoverVarsFiles(uploadFlags.directory)
@@ -499,3 +505,13 @@ func prettyDirectoryPath(dir string) string {
 	}
 	return prettyDir
 }
+
+// isPulumiProject reports whether dir contains a Pulumi project file.
+func isPulumiProject(dir string) bool {
+	for _, name := range []string{"Pulumi.yaml", "Pulumi.yml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
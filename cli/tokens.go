@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/cli/cliui"
@@ -23,6 +24,10 @@ func (r *RootCmd) tokens() *serpent.Command {
 				Description: "Create a token for automation",
 				Command:     "coder tokens create",
 			},
+			Example{
+				Description: "Create a token that can only start the given workspace",
+				Command:     "coder tokens create --scope workspace:start --allow <workspace-id>",
+			},
 			Example{
 				Description: "List your tokens",
 				Command:     "coder tokens ls",
@@ -50,6 +55,8 @@ func (r *RootCmd) createToken() *serpent.Command {
 		tokenLifetime string
 		name          string
 		user          string
+		scope         string
+		allowList     []string
 	)
 	client := new(codersdk.Client)
 	cmd := &serpent.Command{
@@ -86,9 +93,20 @@ func (r *RootCmd) createToken() *serpent.Command {
 				}
 			}
 
+			allowIDs := make([]uuid.UUID, 0, len(allowList))
+			for _, s := range allowList {
+				id, err := uuid.Parse(s)
+				if err != nil {
+					return xerrors.Errorf("parse allowed resource ID %q: %w", s, err)
+				}
+				allowIDs = append(allowIDs, id)
+			}
+
 			res, err := client.CreateToken(inv.Context(), userID, codersdk.CreateTokenRequest{
 				Lifetime:  parsedLifetime,
 				TokenName: name,
+				Scope:     codersdk.APIKeyScope(scope),
+				AllowList: allowIDs,
 			})
 			if err != nil {
 				return xerrors.Errorf("create tokens: %w", err)
@@ -121,11 +139,32 @@ func (r *RootCmd) createToken() *serpent.Command {
 			Description:   "Specify the user to create the token for (Only works if logged in user is admin).",
 			Value:         serpent.StringOf(&user),
 		},
+		{
+			Flag:        "scope",
+			Env:         "CODER_TOKEN_SCOPE",
+			Description: "Restrict the token to the given operations.",
+			Default:     string(codersdk.APIKeyScopeAll),
+			Value:       serpent.EnumOf(&scope, apiKeyScopeNames()...),
+		},
+		{
+			Flag:        "allow",
+			Env:         "CODER_TOKEN_ALLOW",
+			Description: "Restrict the token to the workspaces and templates with the given IDs. The templates of the allowed workspaces are allowed too.",
+			Value:       serpent.StringArrayOf(&allowList),
+		},
 	}
 
 	return cmd
 }
 
+func apiKeyScopeNames() []string {
+	names := make([]string, 0, len(codersdk.APIKeyScopes))
+	for _, scope := range codersdk.APIKeyScopes {
+		names = append(names, string(scope))
+	}
+	return names
+}
+
 // tokenListRow is the type provided to the OutputFormatter.
 type tokenListRow struct {
 	// For JSON format:
@@ -134,6 +173,7 @@ type tokenListRow struct {
 	// For table format:
 	ID        string    `json:"-" table:"id,default_sort"`
 	TokenName string    `json:"token_name" table:"name"`
+	Scope     string    `json:"-" table:"scope"`
 	LastUsed  time.Time `json:"-" table:"last used"`
 	ExpiresAt time.Time `json:"-" table:"expires at"`
 	CreatedAt time.Time `json:"-" table:"created at"`
@@ -145,6 +185,7 @@ func tokenListRowFromToken(token codersdk.APIKeyWithOwner) tokenListRow {
 		APIKey:    token.APIKey,
 		ID:        token.ID,
 		TokenName: token.TokenName,
+		Scope:     string(token.Scope),
 		LastUsed:  token.LastUsed,
 		ExpiresAt: token.ExpiresAt,
 		CreatedAt: token.CreatedAt,
@@ -154,7 +195,7 @@ func tokenListRowFromToken(token codersdk.APIKeyWithOwner) tokenListRow {
 
 func (r *RootCmd) listTokens() *serpent.Command {
 	// we only display the 'owner' column if the --all argument is passed in
-	defaultCols := []string{"id", "name", "last used", "expires at", "created at"}
+	defaultCols := []string{"id", "name", "scope", "last used", "expires at", "created at"}
 	if slices.Contains(os.Args, "-a") || slices.Contains(os.Args, "--all") {
 		defaultCols = append(defaultCols, "owner")
 	}
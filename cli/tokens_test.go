@@ -157,3 +157,37 @@ func TestTokens(t *testing.T) {
 	require.NotEmpty(t, res)
 	require.Contains(t, res, "deleted")
 }
+
+func TestTokensScope(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	inv, root := clitest.New(t, "tokens", "create", "--name", "ci", "--scope", "workspace:read")
+	//nolint:gocritic // This should be run as the owner user.
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	inv.Stdout = buf
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	id := buf.String()[:10]
+
+	inv, root = clitest.New(t, "tokens", "ls")
+	//nolint:gocritic // This should be run as the owner user.
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "SCOPE")
+	require.Contains(t, buf.String(), id)
+	require.Contains(t, buf.String(), "workspace:read")
+
+	inv, root = clitest.New(t, "tokens", "create", "--name", "invalid", "--scope", "workspace:read", "--allow", "not-a-uuid")
+	//nolint:gocritic // This should be run as the owner user.
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.ErrorContains(t, err, "parse allowed resource ID")
+}
@@ -18,13 +18,14 @@ import (
 
 func (r *RootCmd) userCreate() *serpent.Command {
 	var (
-		email        string
-		username     string
-		name         string
-		password     string
-		disableLogin bool
-		loginType    string
-		orgContext   = NewOrganizationContext()
+		email          string
+		username       string
+		name           string
+		password       string
+		disableLogin   bool
+		loginType      string
+		serviceAccount bool
+		orgContext     = NewOrganizationContext()
 	)
 	client := new(codersdk.Client)
 	cmd := &serpent.Command{
@@ -87,7 +88,10 @@ func (r *RootCmd) userCreate() *serpent.Command {
 			if disableLogin && loginType != "" {
 				return xerrors.New("You cannot specify both --disable-login and --login-type")
 			}
-			if disableLogin {
+			if serviceAccount && (disableLogin || (loginType != "" && loginType != string(codersdk.LoginTypeNone))) {
+				return xerrors.New("Service accounts cannot log in interactively, so --login-type cannot be set")
+			}
+			if disableLogin || serviceAccount {
 				userLoginType = codersdk.LoginTypeNone
 			} else if loginType != "" {
 				userLoginType = codersdk.LoginType(loginType)
@@ -108,11 +112,19 @@ func (r *RootCmd) userCreate() *serpent.Command {
 				Password:        password,
 				OrganizationIDs: []uuid.UUID{organization.ID},
 				UserLoginType:   userLoginType,
+				ServiceAccount:  serviceAccount,
 			})
 			if err != nil {
 				return err
 			}
 
+			if serviceAccount {
+				_, _ = fmt.Fprintf(inv.Stderr, "A new service account has been created in the %s organization!\n"+
+					"Create a scoped token to authenticate as it:\n\n  %s\n", organization.Name,
+					pretty.Sprint(cliui.DefaultStyles.Code, "coder tokens create --user "+username+" --scope workspace:read"))
+				return nil
+			}
+
 			authenticationMethod := ""
 			switch codersdk.LoginType(strings.ToLower(string(userLoginType))) {
 			case codersdk.LoginTypePassword:
@@ -191,6 +203,12 @@ Create a workspace  `+pretty.Sprint(cliui.DefaultStyles.Code, "coder create")+`!
 				)),
 			Value: serpent.StringOf(&loginType),
 		},
+		{
+			Flag: "service-account",
+			Description: "Create a service account owned by the selected organization. Service accounts cannot log in interactively, " +
+				"and authenticate only with scoped API tokens.",
+			Value: serpent.BoolOf(&serviceAccount),
+		},
 	}
 
 	orgContext.AttachOptions(cmd)
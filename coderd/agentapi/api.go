@@ -50,6 +50,7 @@ type API struct {
 	*LogsAPI
 	*ScriptsAPI
 	*AuditAPI
+	*SessionRecordingsAPI
 	*tailnet.DRPCService
 
 	mu sync.Mutex
@@ -184,6 +185,13 @@ func New(opts Options) *API {
 		Log:      opts.Log,
 	}
 
+	api.SessionRecordingsAPI = &SessionRecordingsAPI{
+		AgentFn:     api.agent,
+		WorkspaceID: opts.WorkspaceID,
+		Database:    opts.Database,
+		Log:         opts.Log,
+	}
+
 	api.DRPCService = &tailnet.DRPCService{
 		CoordPtr:                opts.TailnetCoordinator,
 		Logger:                  opts.Log,
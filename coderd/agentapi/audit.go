@@ -3,6 +3,7 @@ package agentapi
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"strconv"
 	"sync/atomic"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/db2sdk"
+	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/agentsdk"
 )
 
@@ -43,18 +45,9 @@ func (a *AuditAPI) ReportConnection(ctx context.Context, req *agentproto.ReportC
 		return nil, err
 	}
 
-	// Fetch contextual data for this audit event.
-	workspaceAgent, err := a.AgentFn(ctx)
-	if err != nil {
-		return nil, xerrors.Errorf("get agent: %w", err)
-	}
-	workspace, err := a.Database.GetWorkspaceByAgentID(ctx, workspaceAgent.ID)
-	if err != nil {
-		return nil, xerrors.Errorf("get workspace by agent id: %w", err)
-	}
-	build, err := a.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	workspaceAgent, workspace, workspaceFields, err := a.auditContext(ctx)
 	if err != nil {
-		return nil, xerrors.Errorf("get latest workspace build by workspace id: %w", err)
+		return nil, err
 	}
 
 	// We pass the below information to the Auditor so that it
@@ -66,15 +59,9 @@ func (a *AuditAPI) ReportConnection(ctx context.Context, req *agentproto.ReportC
 		Reason         string                  `json:"reason,omitempty"`
 	}
 	resourceInfo := additionalFields{
-		AdditionalFields: audit.AdditionalFields{
-			WorkspaceID:    workspace.ID,
-			WorkspaceName:  workspace.Name,
-			WorkspaceOwner: workspace.OwnerUsername,
-			BuildNumber:    strconv.FormatInt(int64(build.BuildNumber), 10),
-			BuildReason:    database.BuildReason(string(build.Reason)),
-		},
-		ConnectionType: connectionType,
-		Reason:         req.GetConnection().GetReason(),
+		AdditionalFields: workspaceFields,
+		ConnectionType:   connectionType,
+		Reason:           req.GetConnection().GetReason(),
 	}
 
 	riBytes, err := json.Marshal(resourceInfo)
@@ -103,3 +90,87 @@ func (a *AuditAPI) ReportConnection(ctx context.Context, req *agentproto.ReportC
 
 	return &emptypb.Empty{}, nil
 }
+
+// ReportBlockedAction audits an attempt to move data into or out of the
+// workspace that was blocked by its DLP policy.
+func (a *AuditAPI) ReportBlockedAction(ctx context.Context, req *agentproto.ReportBlockedActionRequest) (*emptypb.Empty, error) {
+	blocked := req.GetBlockedAction()
+	dlpAction, err := agentsdk.DLPActionFromProto(blocked.GetType())
+	if err != nil {
+		return nil, err
+	}
+	// Attempts made in a reported connection share its request ID, so they
+	// can be correlated with the connection in the audit log.
+	requestID := uuid.New()
+	if len(blocked.GetConnectionId()) > 0 {
+		requestID, err = uuid.FromBytes(blocked.GetConnectionId())
+		if err != nil {
+			return nil, xerrors.Errorf("connection id from bytes: %w", err)
+		}
+	}
+
+	workspaceAgent, workspace, workspaceFields, err := a.auditContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type additionalFields struct {
+		audit.AdditionalFields
+
+		DLPAction codersdk.DLPAction `json:"dlp_action"`
+		Detail    string             `json:"detail,omitempty"`
+	}
+	riBytes, err := json.Marshal(additionalFields{
+		AdditionalFields: workspaceFields,
+		DLPAction:        dlpAction,
+		Detail:           blocked.GetDetail(),
+	})
+	if err != nil {
+		a.Log.Error(ctx, "marshal resource info for blocked agent action failed", slog.Error(err))
+		riBytes = []byte("{}")
+	}
+
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.WorkspaceAgent]{
+		Audit:            *a.Auditor.Load(),
+		Log:              a.Log,
+		Time:             blocked.GetTimestamp().AsTime(),
+		OrganizationID:   workspace.OrganizationID,
+		RequestID:        requestID,
+		Action:           database.AuditActionBlocked,
+		New:              workspaceAgent,
+		Old:              workspaceAgent,
+		IP:               blocked.GetIp(),
+		Status:           http.StatusForbidden,
+		AdditionalFields: riBytes,
+
+		// Like connections, it's not possible to tell which user made the
+		// attempt.
+		UserID: uuid.Nil,
+	})
+
+	return &emptypb.Empty{}, nil
+}
+
+// auditContext fetches the agent and workspace an audit event is about, and
+// the fields that describe the workspace in the audit log.
+func (a *AuditAPI) auditContext(ctx context.Context) (database.WorkspaceAgent, database.Workspace, audit.AdditionalFields, error) {
+	workspaceAgent, err := a.AgentFn(ctx)
+	if err != nil {
+		return database.WorkspaceAgent{}, database.Workspace{}, audit.AdditionalFields{}, xerrors.Errorf("get agent: %w", err)
+	}
+	workspace, err := a.Database.GetWorkspaceByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		return database.WorkspaceAgent{}, database.Workspace{}, audit.AdditionalFields{}, xerrors.Errorf("get workspace by agent id: %w", err)
+	}
+	build, err := a.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		return database.WorkspaceAgent{}, database.Workspace{}, audit.AdditionalFields{}, xerrors.Errorf("get latest workspace build by workspace id: %w", err)
+	}
+	return workspaceAgent, workspace, audit.AdditionalFields{
+		WorkspaceID:    workspace.ID,
+		WorkspaceName:  workspace.Name,
+		WorkspaceOwner: workspace.OwnerUsername,
+		BuildNumber:    strconv.FormatInt(int64(build.BuildNumber), 10),
+		BuildReason:    database.BuildReason(string(build.Reason)),
+	}, nil
+}
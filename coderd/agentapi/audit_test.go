@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"net/http"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -160,6 +161,60 @@ func TestAuditReport(t *testing.T) {
 	}
 }
 
+func TestAuditReportBlockedAction(t *testing.T) {
+	t.Parallel()
+
+	workspace := database.Workspace{
+		ID:             uuid.New(),
+		OrganizationID: uuid.New(),
+		Name:           "cool-workspace",
+	}
+	agent := database.WorkspaceAgent{
+		ID: uuid.New(),
+	}
+	connectionID := uuid.New()
+	now := time.Now()
+
+	mAudit := audit.NewMock()
+	mDB := dbmock.NewMockStore(gomock.NewController(t))
+	mDB.EXPECT().GetWorkspaceByAgentID(gomock.Any(), agent.ID).Return(workspace, nil)
+	mDB.EXPECT().GetLatestWorkspaceBuildByWorkspaceID(gomock.Any(), workspace.ID).Return(database.WorkspaceBuild{}, nil)
+
+	api := &agentapi.AuditAPI{
+		Auditor:  asAtomicPointer[audit.Auditor](mAudit),
+		Database: mDB,
+		AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+			return agent, nil
+		},
+	}
+	_, err := api.ReportBlockedAction(context.Background(), &agentproto.ReportBlockedActionRequest{
+		BlockedAction: &agentproto.BlockedAction{
+			Type:         agentproto.BlockedAction_SFTP,
+			ConnectionId: connectionID[:],
+			Timestamp:    timestamppb.New(now),
+			Ip:           "127.0.0.1",
+			Detail:       "sftp subsystem",
+		},
+	})
+	require.NoError(t, err)
+
+	mAudit.Contains(t, database.AuditLog{
+		Time:           dbtime.Time(now).In(time.UTC),
+		Action:         database.AuditActionBlocked,
+		OrganizationID: workspace.OrganizationID,
+		RequestID:      connectionID,
+		ResourceType:   database.ResourceTypeWorkspaceAgent,
+		ResourceID:     agent.ID,
+		StatusCode:     http.StatusForbidden,
+	})
+
+	var m map[string]any
+	err = json.Unmarshal(mAudit.AuditLogs()[0].AdditionalFields, &m)
+	require.NoError(t, err)
+	require.Equal(t, "sftp", m["dlp_action"])
+	require.Equal(t, "sftp subsystem", m["detail"])
+}
+
 func agentProtoConnectionActionToAudit(t *testing.T, action agentproto.Connection_Action) database.AuditAction {
 	a, err := db2sdk.AuditActionFromAgentProtoConnectionAction(action)
 	require.NoError(t, err)
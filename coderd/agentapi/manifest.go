@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/coder/coder/v2/coderd/externalauth"
 	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
 	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/agentsdk"
 	"github.com/coder/coder/v2/tailnet"
 )
 
@@ -47,8 +49,11 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 		scripts       []database.WorkspaceAgentScript
 		metadata      []database.WorkspaceAgentMetadatum
 		workspace     database.Workspace
+		template      database.Template
 		owner         database.User
 		devcontainers []database.WorkspaceAgentDevcontainer
+		secrets       []database.UserSecret
+		groups        []database.GetGroupsRow
 	)
 
 	var eg errgroup.Group
@@ -76,10 +81,29 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 		if err != nil {
 			return xerrors.Errorf("getting workspace by id: %w", err)
 		}
+		// nolint:gocritic // The agent needs to know whether the template
+		// requires sessions to be recorded.
+		template, err = a.Database.GetTemplateByID(dbauthz.AsSystemRestricted(ctx), workspace.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("getting template by id: %w", err)
+		}
 		owner, err = a.Database.GetUserByID(ctx, workspace.OwnerID)
 		if err != nil {
 			return xerrors.Errorf("getting workspace owner by id: %w", err)
 		}
+		// nolint:gocritic // The agent enforces the DLP policies of the
+		// groups of the workspace owner.
+		groups, err = a.Database.GetGroups(dbauthz.AsSystemRestricted(ctx), database.GetGroupsParams{
+			OrganizationID: workspace.OrganizationID,
+			HasMemberID:    workspace.OwnerID,
+		})
+		if err != nil {
+			return xerrors.Errorf("getting workspace owner groups: %w", err)
+		}
+		secrets, err = a.Database.GetUserSecrets(ctx, workspace.OwnerID)
+		if err != nil {
+			return xerrors.Errorf("getting workspace owner secrets: %w", err)
+		}
 		return err
 	})
 	eg.Go(func() (err error) {
@@ -107,6 +131,7 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 	if err != nil {
 		return nil, err
 	}
+	envs, secretFiles := injectUserSecrets(secrets, workspace.TemplateID, envs)
 
 	var gitAuthConfigs uint32
 	for _, cfg := range a.ExternalAuthConfigs {
@@ -120,6 +145,13 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 		return nil, xerrors.Errorf("converting workspace apps: %w", err)
 	}
 
+	// Policies only restrict, so an action is blocked if the template or any
+	// group of the owner blocks it.
+	dlpPolicy := codersdk.DLPPolicy(template.DLPPolicy)
+	for _, group := range groups {
+		dlpPolicy = dlpPolicy.Merge(codersdk.DLPPolicy(group.Group.DLPPolicy))
+	}
+
 	return &agentproto.Manifest{
 		AgentId:                  workspaceAgent.ID[:],
 		AgentName:                workspaceAgent.Name,
@@ -139,9 +171,41 @@ func (a *ManifestAPI) GetManifest(ctx context.Context, _ *agentproto.GetManifest
 		Apps:          apps,
 		Metadata:      dbAgentMetadataToProtoDescription(metadata),
 		Devcontainers: dbAgentDevcontainersToProto(devcontainers),
+		SecretFiles:   secretFiles,
+
+		SessionRecordingEnabled: template.SessionRecordingEnabled,
+		DlpPolicy:               agentsdk.ProtoFromDLPPolicy(dlpPolicy),
 	}, nil
 }
 
+// injectUserSecrets adds the secrets of the workspace owner that are scoped
+// to the template of the workspace to the environment of the agent, and
+// returns the secrets that should be written to files. Variables set by the
+// template take precedence over secrets.
+func injectUserSecrets(secrets []database.UserSecret, templateID uuid.UUID, envs map[string]string) (map[string]string, []*agentproto.SecretFile) {
+	var files []*agentproto.SecretFile
+	for _, secret := range secrets {
+		if len(secret.TemplateIDs) > 0 && !slices.Contains(secret.TemplateIDs, templateID) {
+			continue
+		}
+		if secret.EnvName != "" {
+			if envs == nil {
+				envs = map[string]string{}
+			}
+			if _, ok := envs[secret.EnvName]; !ok {
+				envs[secret.EnvName] = secret.Value
+			}
+		}
+		if secret.FilePath != "" {
+			files = append(files, &agentproto.SecretFile{
+				Path:    secret.FilePath,
+				Content: []byte(secret.Value),
+			})
+		}
+	}
+	return envs, files
+}
+
 func vscodeProxyURI(app appurl.ApplicationURL, accessURL *url.URL, appHost string) string {
 	// Proxying by port only works for subdomains. If subdomain support is not
 	// available, return an empty string.
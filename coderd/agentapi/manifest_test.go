@@ -45,10 +45,16 @@ func TestGetManifest(t *testing.T) {
 			ID:       uuid.New(),
 			Username: "cool-user",
 		}
+		template = database.Template{
+			ID:                      uuid.New(),
+			SessionRecordingEnabled: true,
+			DLPPolicy:               database.DLPPolicy{BlockSFTP: true},
+		}
 		workspace = database.Workspace{
-			ID:      uuid.New(),
-			OwnerID: owner.ID,
-			Name:    "cool-workspace",
+			ID:         uuid.New(),
+			OwnerID:    owner.ID,
+			TemplateID: template.ID,
+			Name:       "cool-workspace",
 		}
 		agent = database.WorkspaceAgent{
 			ID:   uuid.New(),
@@ -329,7 +335,16 @@ func TestGetManifest(t *testing.T) {
 		}).Return(metadata, nil)
 		mDB.EXPECT().GetWorkspaceAgentDevcontainersByAgentID(gomock.Any(), agent.ID).Return(devcontainers, nil)
 		mDB.EXPECT().GetWorkspaceByID(gomock.Any(), workspace.ID).Return(workspace, nil)
+		mDB.EXPECT().GetTemplateByID(gomock.Any(), workspace.TemplateID).Return(template, nil)
 		mDB.EXPECT().GetUserByID(gomock.Any(), workspace.OwnerID).Return(owner, nil)
+		mDB.EXPECT().GetGroups(gomock.Any(), database.GetGroupsParams{
+			OrganizationID: workspace.OrganizationID,
+			HasMemberID:    owner.ID,
+		}).Return([]database.GetGroupsRow{
+			{Group: database.Group{DLPPolicy: database.DLPPolicy{BlockClipboardPaste: true}}},
+			{Group: database.Group{DLPPolicy: database.DLPPolicy{}}},
+		}, nil)
+		mDB.EXPECT().GetUserSecrets(gomock.Any(), workspace.OwnerID).Return(nil, nil)
 
 		got, err := api.GetManifest(context.Background(), &agentproto.GetManifestRequest{})
 		require.NoError(t, err)
@@ -355,6 +370,14 @@ func TestGetManifest(t *testing.T) {
 			Apps:          protoApps,
 			Metadata:      protoMetadata,
 			Devcontainers: protoDevcontainers,
+
+			SessionRecordingEnabled: true,
+			// The policies of the template and the groups of the owner are
+			// merged.
+			DlpPolicy: &agentproto.DLPPolicy{
+				BlockSftp:           true,
+				BlockClipboardPaste: true,
+			},
 		}
 
 		// Log got and expected with spew.
@@ -396,7 +419,10 @@ func TestGetManifest(t *testing.T) {
 		}).Return(metadata, nil)
 		mDB.EXPECT().GetWorkspaceAgentDevcontainersByAgentID(gomock.Any(), agent.ID).Return(devcontainers, nil)
 		mDB.EXPECT().GetWorkspaceByID(gomock.Any(), workspace.ID).Return(workspace, nil)
+		mDB.EXPECT().GetTemplateByID(gomock.Any(), workspace.TemplateID).Return(template, nil)
 		mDB.EXPECT().GetUserByID(gomock.Any(), workspace.OwnerID).Return(owner, nil)
+		mDB.EXPECT().GetGroups(gomock.Any(), gomock.Any()).Return(nil, nil)
+		mDB.EXPECT().GetUserSecrets(gomock.Any(), workspace.OwnerID).Return(nil, nil)
 
 		got, err := api.GetManifest(context.Background(), &agentproto.GetManifestRequest{})
 		require.NoError(t, err)
@@ -422,6 +448,9 @@ func TestGetManifest(t *testing.T) {
 			Apps:          protoApps,
 			Metadata:      protoMetadata,
 			Devcontainers: protoDevcontainers,
+
+			SessionRecordingEnabled: true,
+			DlpPolicy:               &agentproto.DLPPolicy{BlockSftp: true},
 		}
 
 		// Log got and expected with spew.
@@ -430,4 +459,67 @@ func TestGetManifest(t *testing.T) {
 
 		require.Equal(t, expected, got)
 	})
+	t.Run("UserSecrets", func(t *testing.T) {
+		t.Parallel()
+
+		mDB := dbmock.NewMockStore(gomock.NewController(t))
+
+		workspace := workspace
+		workspace.TemplateID = uuid.New()
+		secrets := []database.UserSecret{
+			{
+				Name:        "foo",
+				Value:       "secret-foo",
+				EnvName:     "FOO",
+				TemplateIDs: []uuid.UUID{},
+			},
+			{
+				Name:        "npm-token",
+				Value:       "secret-npm",
+				EnvName:     "NPM_TOKEN",
+				FilePath:    ".npmrc",
+				TemplateIDs: []uuid.UUID{workspace.TemplateID},
+			},
+			{
+				Name:        "other-template",
+				Value:       "secret-other",
+				EnvName:     "OTHER",
+				TemplateIDs: []uuid.UUID{uuid.New()},
+			},
+		}
+
+		api := &agentapi.ManifestAPI{
+			AccessURL: &url.URL{Scheme: "https", Host: "example.com"},
+			AgentFn: func(ctx context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			WorkspaceID: workspace.ID,
+			Database:    mDB,
+			DerpMapFn:   derpMapFn,
+		}
+
+		mDB.EXPECT().GetWorkspaceAppsByAgentID(gomock.Any(), agent.ID).Return(nil, nil)
+		mDB.EXPECT().GetWorkspaceAgentScriptsByAgentIDs(gomock.Any(), []uuid.UUID{agent.ID}).Return(nil, nil)
+		mDB.EXPECT().GetWorkspaceAgentMetadata(gomock.Any(), gomock.Any()).Return(nil, nil)
+		mDB.EXPECT().GetWorkspaceAgentDevcontainersByAgentID(gomock.Any(), agent.ID).Return(nil, nil)
+		mDB.EXPECT().GetWorkspaceByID(gomock.Any(), workspace.ID).Return(workspace, nil)
+		mDB.EXPECT().GetTemplateByID(gomock.Any(), workspace.TemplateID).Return(template, nil)
+		mDB.EXPECT().GetUserByID(gomock.Any(), workspace.OwnerID).Return(owner, nil)
+		mDB.EXPECT().GetGroups(gomock.Any(), gomock.Any()).Return(nil, nil)
+		mDB.EXPECT().GetUserSecrets(gomock.Any(), workspace.OwnerID).Return(secrets, nil)
+
+		got, err := api.GetManifest(context.Background(), &agentproto.GetManifestRequest{})
+		require.NoError(t, err)
+
+		// Variables set by the template take precedence over secrets, and
+		// secrets scoped to other templates are not injected.
+		require.Equal(t, map[string]string{
+			"FOO":       "bar",
+			"COOL_ENV":  "dean was here",
+			"NPM_TOKEN": "secret-npm",
+		}, got.EnvironmentVariables)
+		require.Len(t, got.SecretFiles, 1)
+		require.Equal(t, ".npmrc", got.SecretFiles[0].Path)
+		require.Equal(t, []byte("secret-npm"), got.SecretFiles[0].Content)
+	})
 }
@@ -0,0 +1,79 @@
+package agentapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"cdr.dev/slog"
+
+	agentproto "github.com/coder/coder/v2/agent/proto"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+)
+
+type SessionRecordingsAPI struct {
+	AgentFn     func(context.Context) (database.WorkspaceAgent, error)
+	WorkspaceID uuid.UUID
+	Database    database.Store
+	Log         slog.Logger
+}
+
+func (a *SessionRecordingsAPI) UploadSessionRecording(ctx context.Context, req *agentproto.UploadSessionRecordingRequest) (*emptypb.Empty, error) {
+	recordingID, err := uuid.FromBytes(req.GetId())
+	if err != nil {
+		return nil, xerrors.Errorf("recording id from bytes: %w", err)
+	}
+
+	var recordingType database.SessionRecordingType
+	switch req.GetType() {
+	case agentproto.Connection_SSH:
+		recordingType = database.SessionRecordingTypeSsh
+	case agentproto.Connection_RECONNECTING_PTY:
+		recordingType = database.SessionRecordingTypeReconnectingPty
+	default:
+		return nil, xerrors.Errorf("unsupported session recording type %q", req.GetType())
+	}
+	if req.GetChunkIndex() < 0 {
+		return nil, xerrors.Errorf("invalid chunk index %d", req.GetChunkIndex())
+	}
+
+	workspaceAgent, err := a.AgentFn(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("get agent: %w", err)
+	}
+
+	var endedAt sql.NullTime
+	if req.EndedAt != nil {
+		endedAt = sql.NullTime{Time: dbtime.Time(req.GetEndedAt().AsTime()), Valid: true}
+	}
+	err = a.Database.UpsertWorkspaceSessionRecording(ctx, database.UpsertWorkspaceSessionRecordingParams{
+		ID:          recordingID,
+		WorkspaceID: a.WorkspaceID,
+		AgentID:     workspaceAgent.ID,
+		Type:        recordingType,
+		StartedAt:   dbtime.Time(req.GetStartedAt().AsTime()),
+		EndedAt:     endedAt,
+		CreatedAt:   dbtime.Now(),
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("upsert session recording: %w", err)
+	}
+
+	if len(req.GetData()) > 0 {
+		err = a.Database.InsertWorkspaceSessionRecordingChunk(ctx, database.InsertWorkspaceSessionRecordingChunkParams{
+			RecordingID: recordingID,
+			AgentID:     workspaceAgent.ID,
+			ChunkIndex:  req.GetChunkIndex(),
+			Data:        req.GetData(),
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("insert session recording chunk: %w", err)
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
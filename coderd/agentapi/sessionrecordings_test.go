@@ -0,0 +1,140 @@
+package agentapi_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	agentproto "github.com/coder/coder/v2/agent/proto"
+	"github.com/coder/coder/v2/coderd/agentapi"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbmem"
+	"github.com/coder/coder/v2/coderd/database/dbmock"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+)
+
+func TestUploadSessionRecording(t *testing.T) {
+	t.Parallel()
+
+	var (
+		agent       = database.WorkspaceAgent{ID: uuid.New()}
+		workspaceID = uuid.New()
+		startedAt   = dbtime.Now().Add(-time.Minute)
+		endedAt     = dbtime.Now()
+	)
+	newAPI := func(db database.Store) *agentapi.SessionRecordingsAPI {
+		return &agentapi.SessionRecordingsAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return agent, nil
+			},
+			WorkspaceID: workspaceID,
+			Database:    db,
+		}
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		id := uuid.New()
+		mDB := dbmock.NewMockStore(gomock.NewController(t))
+		mDB.EXPECT().UpsertWorkspaceSessionRecording(gomock.Any(), gomock.Cond(func(arg database.UpsertWorkspaceSessionRecordingParams) bool {
+			return arg.ID == id &&
+				arg.WorkspaceID == workspaceID &&
+				arg.AgentID == agent.ID &&
+				arg.Type == database.SessionRecordingTypeReconnectingPty &&
+				arg.StartedAt.Equal(startedAt) &&
+				arg.EndedAt == sql.NullTime{Time: endedAt, Valid: true}
+		})).Return(nil)
+		mDB.EXPECT().InsertWorkspaceSessionRecordingChunk(gomock.Any(), database.InsertWorkspaceSessionRecordingChunkParams{
+			RecordingID: id,
+			AgentID:     agent.ID,
+			ChunkIndex:  3,
+			Data:        []byte("[0.1, \"o\", \"hi\"]\n"),
+		}).Return(nil)
+
+		_, err := newAPI(mDB).UploadSessionRecording(context.Background(), &agentproto.UploadSessionRecordingRequest{
+			Id:         id[:],
+			Type:       agentproto.Connection_RECONNECTING_PTY,
+			StartedAt:  timestamppb.New(startedAt),
+			EndedAt:    timestamppb.New(endedAt),
+			ChunkIndex: 3,
+			Data:       []byte("[0.1, \"o\", \"hi\"]\n"),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("EmptyChunk", func(t *testing.T) {
+		t.Parallel()
+
+		// The final chunk may be empty, in which case only the end time of
+		// the recording is updated.
+		id := uuid.New()
+		mDB := dbmock.NewMockStore(gomock.NewController(t))
+		mDB.EXPECT().UpsertWorkspaceSessionRecording(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := newAPI(mDB).UploadSessionRecording(context.Background(), &agentproto.UploadSessionRecordingRequest{
+			Id:        id[:],
+			Type:      agentproto.Connection_SSH,
+			StartedAt: timestamppb.New(startedAt),
+			EndedAt:   timestamppb.New(endedAt),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("OtherAgent", func(t *testing.T) {
+		t.Parallel()
+
+		// An agent can't append to the recording of another agent, even if it
+		// knows its ID.
+		id := uuid.New()
+		db := dbmem.New()
+		_, err := newAPI(db).UploadSessionRecording(context.Background(), &agentproto.UploadSessionRecordingRequest{
+			Id:        id[:],
+			Type:      agentproto.Connection_SSH,
+			StartedAt: timestamppb.New(startedAt),
+			Data:      []byte("{\"version\":2}\n"),
+		})
+		require.NoError(t, err)
+
+		other := &agentapi.SessionRecordingsAPI{
+			AgentFn: func(context.Context) (database.WorkspaceAgent, error) {
+				return database.WorkspaceAgent{ID: uuid.New()}, nil
+			},
+			WorkspaceID: uuid.New(),
+			Database:    db,
+		}
+		_, err = other.UploadSessionRecording(context.Background(), &agentproto.UploadSessionRecordingRequest{
+			Id:         id[:],
+			Type:       agentproto.Connection_SSH,
+			StartedAt:  timestamppb.New(startedAt),
+			ChunkIndex: 1,
+			Data:       []byte("[0.1, \"o\", \"injected\"]\n"),
+		})
+		require.NoError(t, err)
+
+		chunks, err := db.GetWorkspaceSessionRecordingChunks(context.Background(), id)
+		require.NoError(t, err)
+		require.Len(t, chunks, 1)
+		require.EqualValues(t, 0, chunks[0].ChunkIndex)
+	})
+
+	t.Run("UnsupportedType", func(t *testing.T) {
+		t.Parallel()
+
+		id := uuid.New()
+		mDB := dbmock.NewMockStore(gomock.NewController(t))
+
+		_, err := newAPI(mDB).UploadSessionRecording(context.Background(), &agentproto.UploadSessionRecordingRequest{
+			Id:        id[:],
+			Type:      agentproto.Connection_VSCODE,
+			StartedAt: timestamppb.New(startedAt),
+		})
+		require.ErrorContains(t, err, "unsupported session recording type")
+	})
+}
@@ -3169,6 +3169,12 @@ const docTemplate = `{
                         "description": "Provisioner tags to filter by (JSON of the form {'tag1':'value1','tag2':'value2'})",
                         "name": "tags",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter results by name",
+                        "name": "name",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3213,6 +3219,44 @@ const docTemplate = `{
                 }
             }
         },
+        "/organizations/{organization}/provisionerdaemons/{provisionerdaemon}/drain": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "The daemon stops acquiring jobs, and exits once its current job completes.",
+                "tags": [
+                    "Provisioning"
+                ],
+                "summary": "Drain provisioner daemon",
+                "operationId": "drain-provisioner-daemon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Provisioner daemon ID",
+                        "name": "provisionerdaemon",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
         "/organizations/{organization}/provisionerjobs": {
             "get": {
                 "security": [
@@ -4305,6 +4349,60 @@ const docTemplate = `{
                 }
             }
         },
+        "/replicas/coordinators": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get tailnet coordinators of replicas",
+                "operationId": "get-tailnet-coordinators-of-replicas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TailnetCoordinatorReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/replicas/{replica}/drain": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Drain tailnet peers of replica",
+                "operationId": "drain-tailnet-peers-of-replica",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Replica ID",
+                        "name": "replica",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
         "/scim/v2/ServiceProviderConfig": {
             "get": {
                 "produces": [
@@ -4494,6 +4592,120 @@ const docTemplate = `{
                 }
             }
         },
+        "/session-recordings": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get session recordings",
+                "operationId": "get-session-recordings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.SessionRecording"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/session-recordings/{sessionrecording}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get session recording by ID",
+                "operationId": "get-session-recording-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session recording ID",
+                        "name": "sessionrecording",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.SessionRecording"
+                        }
+                    }
+                }
+            }
+        },
+        "/session-recordings/{sessionrecording}/cast": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "The recording is returned in the asciicast v2 format.",
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get session recording cast",
+                "operationId": "get-session-recording-cast",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Session recording ID",
+                        "name": "sessionrecording",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/settings/idpsync/available-fields": {
             "get": {
                 "security": [
@@ -7502,8 +7714,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{user}/status/activate": {
-            "put": {
+        "/users/{user}/secrets": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -7515,8 +7727,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Activate user account",
-                "operationId": "activate-user-account",
+                "summary": "Get user secrets",
+                "operationId": "get-user-secrets",
                 "parameters": [
                     {
                         "type": "string",
@@ -7530,27 +7742,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.UserSecret"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/status/suspend": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Suspend user account",
-                "operationId": "suspend-user-account",
+                "summary": "Create user secret",
+                "operationId": "create-user-secret",
                 "parameters": [
                     {
                         "type": "string",
@@ -7558,58 +7774,65 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Create secret request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateUserSecretRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.UserSecret"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/webpush/subscription": {
-            "post": {
+        "/users/{user}/secrets/{name}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Notifications"
+                    "Users"
                 ],
-                "summary": "Create user webpush subscription",
-                "operationId": "create-user-webpush-subscription",
+                "summary": "Get user secret by name",
+                "operationId": "get-user-secret-by-name",
                 "parameters": [
-                    {
-                        "description": "Webpush subscription",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.WebpushSubscription"
-                        }
-                    },
                     {
                         "type": "string",
                         "description": "User ID, name, or me",
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserSecret"
+                        }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
             },
             "delete": {
@@ -7618,22 +7841,217 @@ const docTemplate = `{
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Notifications"
+                    "Users"
                 ],
-                "summary": "Delete user webpush subscription",
-                "operationId": "delete-user-webpush-subscription",
+                "summary": "Delete user secret",
+                "operationId": "delete-user-secret",
                 "parameters": [
                     {
-                        "description": "Webpush subscription",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.DeleteWebpushSubscription"
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user secret",
+                "operationId": "update-user-secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update secret request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserSecret"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/status/activate": {
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Activate user account",
+                "operationId": "activate-user-account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/status/suspend": {
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Suspend user account",
+                "operationId": "suspend-user-account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/webpush/subscription": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Create user webpush subscription",
+                "operationId": "create-user-webpush-subscription",
+                "parameters": [
+                    {
+                        "description": "Webpush subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WebpushSubscription"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Delete user webpush subscription",
+                "operationId": "delete-user-webpush-subscription",
+                "parameters": [
+                    {
+                        "description": "Webpush subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.DeleteWebpushSubscription"
                         }
                     },
                     {
@@ -8534,6 +8952,168 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaceagents/{workspaceagent}/pty-presence": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get workspace agent PTY presence",
+                "operationId": "get-workspace-agent-pty-presence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Reconnecting PTY ID",
+                        "name": "reconnect",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentPTYPresence"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/pty-shares": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get workspace agent PTY shares",
+                "operationId": "get-workspace-agent-pty-shares",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentPTYShares"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Upsert workspace agent PTY share",
+                "operationId": "upsert-workspace-agent-pty-share",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Upsert PTY share request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpsertWorkspaceAgentPTYShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentPTYShare"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Delete workspace agent PTY share",
+                "operationId": "delete-workspace-agent-pty-share",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Delete PTY share request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.DeleteWorkspaceAgentPTYShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/workspaceagents/{workspaceagent}/startup-logs": {
             "get": {
                 "security": [
@@ -9121,8 +9701,70 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "204": {
+                        "description": "No Content"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceproxies/me/files/{fileID}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get workspace proxy file",
+                "operationId": "get-workspace-proxy-file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "File ID",
+                        "name": "fileID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get workspace proxy file hash",
+                "operationId": "get-workspace-proxy-file-hash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "File ID",
+                        "name": "fileID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     }
                 },
                 "x-apidocgen": {
@@ -9172,6 +9814,28 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaceproxies/me/pty-share-events": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Workspace proxy PTY share events",
+                "operationId": "workspace-proxy-pty-share-events",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
         "/workspaceproxies/me/register": {
             "post": {
                 "security": [
@@ -10395,6 +11059,14 @@ const docTemplate = `{
                 "user_id"
             ],
             "properties": {
+                "allow_list": {
+                    "description": "AllowList contains the IDs of the only resources the key can access.\nIf empty, the key can access every resource allowed by its scope.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
@@ -10429,7 +11101,13 @@ const docTemplate = `{
                 "scope": {
                     "enum": [
                         "all",
-                        "application_connect"
+                        "application_connect",
+                        "workspace:read",
+                        "workspace:start",
+                        "workspace:stop",
+                        "workspace:ssh",
+                        "template:read",
+                        "template:push"
                     ],
                     "allOf": [
                         {
@@ -10454,11 +11132,23 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "all",
-                "application_connect"
+                "application_connect",
+                "workspace:read",
+                "workspace:start",
+                "workspace:stop",
+                "workspace:ssh",
+                "template:read",
+                "template:push"
             ],
             "x-enum-varnames": [
                 "APIKeyScopeAll",
-                "APIKeyScopeApplicationConnect"
+                "APIKeyScopeApplicationConnect",
+                "APIKeyScopeWorkspaceRead",
+                "APIKeyScopeWorkspaceStart",
+                "APIKeyScopeWorkspaceStop",
+                "APIKeyScopeWorkspaceSSH",
+                "APIKeyScopeTemplateRead",
+                "APIKeyScopeTemplatePush"
             ]
         },
         "codersdk.AddLicenseRequest": {
@@ -10646,7 +11336,8 @@ const docTemplate = `{
                 "connect",
                 "disconnect",
                 "open",
-                "close"
+                "close",
+                "blocked"
             ],
             "x-enum-varnames": [
                 "AuditActionCreate",
@@ -10661,7 +11352,8 @@ const docTemplate = `{
                 "AuditActionConnect",
                 "AuditActionDisconnect",
                 "AuditActionOpen",
-                "AuditActionClose"
+                "AuditActionClose",
+                "AuditActionBlocked"
             ]
         },
         "codersdk.AuditDiff": {
@@ -11252,6 +11944,7 @@ const docTemplate = `{
                     "type": "string",
                     "enum": [
                         "terraform",
+                        "pulumi",
                         "echo"
                     ]
                 },
@@ -11290,13 +11983,27 @@ const docTemplate = `{
         "codersdk.CreateTokenRequest": {
             "type": "object",
             "properties": {
+                "allow_list": {
+                    "description": "AllowList restricts the token to the workspaces and templates with the\ngiven IDs. The templates of allowed workspaces and the user the token\nbelongs to are allowed implicitly.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
                 "lifetime": {
                     "type": "integer"
                 },
                 "scope": {
                     "enum": [
                         "all",
-                        "application_connect"
+                        "application_connect",
+                        "workspace:read",
+                        "workspace:start",
+                        "workspace:stop",
+                        "workspace:ssh",
+                        "template:read",
+                        "template:push"
                     ],
                     "allOf": [
                         {
@@ -11342,6 +12049,10 @@ const docTemplate = `{
                 "password": {
                     "type": "string"
                 },
+                "service_account": {
+                    "description": "ServiceAccount creates a non-human user that cannot log in\ninteractively, and authenticates only with scoped API tokens. Service\naccounts are owned by the single organization in OrganizationIDs.",
+                    "type": "boolean"
+                },
                 "user_status": {
                     "description": "UserStatus defaults to UserStatusDormant.",
                     "allOf": [
@@ -11355,6 +12066,37 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateUserSecretRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "value"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "env_name": {
+                    "type": "string"
+                },
+                "file_path": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.CreateWorkspaceBuildRequest": {
             "type": "object",
             "required": [
@@ -11379,6 +12121,18 @@ const docTemplate = `{
                     "description": "Orphan may be set for the Destroy transition.",
                     "type": "boolean"
                 },
+                "priority": {
+                    "description": "Priority lowers the priority of the build's provisioner job, so it\ndoesn't hold up builds users are waiting on. Use \"bulk\" when building\nmany workspaces at once. Defaults to \"interactive\".",
+                    "enum": [
+                        "interactive",
+                        "bulk"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobPriority"
+                        }
+                    ]
+                },
                 "rich_parameter_values": {
                     "description": "ParameterValues are optional. It will write params to the 'workspace' scope.\nThis will overwrite any existing parameters with the same name.\nThis will not delete old params not included in this list.",
                     "type": "array",
@@ -11632,6 +12386,39 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.DLPPolicy": {
+            "type": "object",
+            "properties": {
+                "block_clipboard_copy": {
+                    "description": "BlockClipboardCopy removes OSC 52 sequences, which programs use to set\nthe clipboard of the client, from the output of web terminals.",
+                    "type": "boolean"
+                },
+                "block_clipboard_paste": {
+                    "description": "BlockClipboardPaste drops text pasted into web terminals.",
+                    "type": "boolean"
+                },
+                "block_local_port_forwarding": {
+                    "description": "BlockLocalPortForwarding blocks SSH local port forwarding (ssh -L),\nwhich exposes ports of the workspace on the client.",
+                    "type": "boolean"
+                },
+                "block_remote_port_forwarding": {
+                    "description": "BlockRemotePortForwarding blocks SSH remote port forwarding (ssh -R),\nwhich exposes ports of the client in the workspace.",
+                    "type": "boolean"
+                },
+                "block_scp": {
+                    "description": "BlockSCP blocks the legacy scp protocol.",
+                    "type": "boolean"
+                },
+                "block_sftp": {
+                    "description": "BlockSFTP blocks the SFTP subsystem. OpenSSH 9.0 and later use SFTP\nfor scp, so this also blocks those clients.",
+                    "type": "boolean"
+                },
+                "block_x11_forwarding": {
+                    "description": "BlockX11Forwarding blocks SSH X11 forwarding.",
+                    "type": "boolean"
+                }
+            }
+        },
         "codersdk.DangerousConfig": {
             "type": "object",
             "properties": {
@@ -11654,6 +12441,19 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.DeleteWorkspaceAgentPTYShareRequest": {
+            "type": "object",
+            "properties": {
+                "reconnect_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.DeleteWorkspaceAgentPortShareRequest": {
             "type": "object",
             "properties": {
@@ -11852,6 +12652,12 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "pubsub_backend": {
+                    "type": "string"
+                },
+                "pubsub_nats_url": {
+                    "type": "string"
+                },
                 "rate_limit": {
                     "$ref": "#/definitions/codersdk.RateLimitConfig"
                 },
@@ -12064,6 +12870,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ExternalAuthBrokerType": {
+            "type": "string",
+            "enum": [
+                "vault",
+                "http"
+            ],
+            "x-enum-varnames": [
+                "ExternalAuthBrokerTypeVault",
+                "ExternalAuthBrokerTypeHTTP"
+            ]
+        },
         "codersdk.ExternalAuthConfig": {
             "type": "object",
             "properties": {
@@ -12076,6 +12893,25 @@ const docTemplate = `{
                 "auth_url": {
                     "type": "string"
                 },
+                "broker_token_field": {
+                    "description": "BrokerTokenField is the key of the access token in the secrets\nreturned by a vault broker. It defaults to \"token\".",
+                    "type": "string"
+                },
+                "broker_type": {
+                    "description": "BrokerType makes the provider issue credentials from a secrets\nbackend instead of OAuth2. The client and OAuth2 URLs are unused\nwhen it's set.",
+                    "enum": [
+                        "vault",
+                        "http"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ExternalAuthBrokerType"
+                        }
+                    ]
+                },
+                "broker_url": {
+                    "type": "string"
+                },
                 "client_id": {
                     "type": "string"
                 },
@@ -12296,6 +13132,14 @@ const docTemplate = `{
                 "display_name": {
                     "type": "string"
                 },
+                "dlp_policy": {
+                    "description": "DLPPolicy restricts how data can be moved into or out of the\nworkspaces of group members.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.DLPPolicy"
+                        }
+                    ]
+                },
                 "id": {
                     "type": "string",
                     "format": "uuid"
@@ -13442,6 +14286,14 @@ const docTemplate = `{
                 "display_name": {
                     "type": "string"
                 },
+                "dlp_policy": {
+                    "description": "DLPPolicy replaces the data loss prevention policy of the group. If\nnil, the policy is left unchanged.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.DLPPolicy"
+                        }
+                    ]
+                },
                 "name": {
                     "type": "string"
                 },
@@ -13718,6 +14570,16 @@ const docTemplate = `{
                 },
                 "force_cancel_interval": {
                     "type": "integer"
+                },
+                "terraform_cache_size": {
+                    "description": "TerraformCacheSize is the size in megabytes of each cache of the built-in terraform provisioners.",
+                    "type": "integer"
+                },
+                "terraform_disable_module_cache": {
+                    "type": "boolean"
+                },
+                "terraform_engine": {
+                    "type": "string"
                 }
             }
         },
@@ -13734,6 +14596,11 @@ const docTemplate = `{
                 "current_job": {
                     "$ref": "#/definitions/codersdk.ProvisionerDaemonJob"
                 },
+                "drain_requested_at": {
+                    "description": "DrainRequestedAt is set when the daemon has been asked to stop\nacquiring jobs and exit.",
+                    "type": "string",
+                    "format": "date-time"
+                },
                 "id": {
                     "type": "string",
                     "format": "uuid"
@@ -13784,6 +14651,18 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "terraform_engine": {
+                    "description": "TerraformEngine is the binary the terraform provisioner of the daemon\nruns. It's empty if the daemon has no terraform provisioner.",
+                    "enum": [
+                        "terraform",
+                        "opentofu"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TerraformEngine"
+                        }
+                    ]
+                },
                 "version": {
                     "type": "string"
                 }
@@ -13888,6 +14767,19 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "priority": {
+                    "enum": [
+                        "interactive",
+                        "autobuild",
+                        "bulk",
+                        "background"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobPriority"
+                        }
+                    ]
+                },
                 "queue_position": {
                     "type": "integer"
                 },
@@ -14007,6 +14899,21 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ProvisionerJobPriority": {
+            "type": "string",
+            "enum": [
+                "interactive",
+                "autobuild",
+                "bulk",
+                "background"
+            ],
+            "x-enum-varnames": [
+                "ProvisionerJobPriorityInteractive",
+                "ProvisionerJobPriorityAutobuild",
+                "ProvisionerJobPriorityBulk",
+                "ProvisionerJobPriorityBackground"
+            ]
+        },
         "codersdk.ProvisionerJobStatus": {
             "type": "string",
             "enum": [
@@ -14033,12 +14940,14 @@ const docTemplate = `{
             "enum": [
                 "template_version_import",
                 "workspace_build",
-                "template_version_dry_run"
+                "template_version_dry_run",
+                "workspace_drift_check"
             ],
             "x-enum-varnames": [
                 "ProvisionerJobTypeTemplateVersionImport",
                 "ProvisionerJobTypeWorkspaceBuild",
-                "ProvisionerJobTypeTemplateVersionDryRun"
+                "ProvisionerJobTypeTemplateVersionDryRun",
+                "ProvisionerJobTypeWorkspaceDriftCheck"
             ]
         },
         "codersdk.ProvisionerKey": {
@@ -14345,6 +15254,10 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "is_service_account": {
+                    "description": "IsServiceAccount is true for non-human users that are used by\nautomation. Service accounts cannot log in interactively.",
+                    "type": "boolean"
+                },
                 "last_seen_at": {
                     "type": "string",
                     "format": "date-time"
@@ -14706,6 +15619,68 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.SessionRecording": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "ended_at": {
+                    "description": "EndedAt is nil while the session is still active.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "size": {
+                    "description": "Size is the size of the recording in bytes.",
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "type": {
+                    "enum": [
+                        "ssh",
+                        "reconnecting_pty"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.SessionRecordingType"
+                        }
+                    ]
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_name": {
+                    "type": "string"
+                },
+                "workspace_owner_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_owner_username": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.SessionRecordingType": {
+            "type": "string",
+            "enum": [
+                "ssh",
+                "reconnecting_pty"
+            ],
+            "x-enum-varnames": [
+                "SessionRecordingTypeSSH",
+                "SessionRecordingTypeReconnectingPTY"
+            ]
+        },
         "codersdk.SlimRole": {
             "type": "object",
             "properties": {
@@ -14760,32 +15735,109 @@ const docTemplate = `{
                 "client_cert_file": {
                     "type": "string"
                 },
-                "client_key_file": {
-                    "type": "string"
+                "client_key_file": {
+                    "type": "string"
+                },
+                "enable": {
+                    "type": "boolean"
+                },
+                "key_file": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "min_version": {
+                    "type": "string"
+                },
+                "redirect_http": {
+                    "type": "boolean"
+                },
+                "supported_ciphers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "codersdk.TailnetCoordinator": {
+            "type": "object",
+            "properties": {
+                "healthy": {
+                    "description": "Healthy is true if the coordinator hasn't missed any heartbeats.",
+                    "type": "boolean"
+                },
+                "heartbeat_at": {
+                    "description": "HeartbeatAt is the time of the last heartbeat of the coordinator.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "heartbeat_lag_ms": {
+                    "description": "HeartbeatLagMS is the time in milliseconds since the last heartbeat.",
+                    "type": "integer"
+                },
+                "hostname": {
+                    "description": "Hostname is the hostname of the replica, or empty if the coordinator\ndoesn't belong to an active replica.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is the unique identifier of the coordinator, which is the ID of the\nreplica it runs on.",
+                    "type": "string",
+                    "format": "uuid"
                 },
-                "enable": {
-                    "type": "boolean"
+                "lost_peers": {
+                    "description": "LostPeers is the number of peers that lost their connection to the\ncoordinator and haven't reconnected to any coordinator yet.",
+                    "type": "integer"
                 },
-                "key_file": {
+                "peers": {
+                    "description": "Peers is the number of peers connected to the coordinator.",
+                    "type": "integer"
+                },
+                "tunnels": {
+                    "description": "Tunnels is the number of tunnels from peers of the coordinator.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.TailnetCoordinatorReport": {
+            "type": "object",
+            "properties": {
+                "coordinators": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/codersdk.TailnetCoordinator"
                     }
                 },
-                "min_version": {
-                    "type": "string"
-                },
-                "redirect_http": {
-                    "type": "boolean"
-                },
-                "supported_ciphers": {
+                "tunnels": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/codersdk.TailnetCoordinatorTunnel"
                     }
                 }
             }
         },
+        "codersdk.TailnetCoordinatorTunnel": {
+            "type": "object",
+            "properties": {
+                "coordinator_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "dst_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "src_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
         "codersdk.TelemetryConfig": {
             "type": "object",
             "properties": {
@@ -14864,6 +15916,18 @@ const docTemplate = `{
                 "display_name": {
                     "type": "string"
                 },
+                "dlp_policy": {
+                    "description": "DLPPolicy restricts how data can be moved into or out of workspaces\ncreated from this template.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.DLPPolicy"
+                        }
+                    ]
+                },
+                "drift_detection_interval_ms": {
+                    "description": "DriftDetectionIntervalMillis is how often running workspaces are\nchecked for infrastructure that changed outside of Coder. 0 means drift\ndetection is disabled.",
+                    "type": "integer"
+                },
                 "failure_ttl_ms": {
                     "description": "FailureTTLMillis, TimeTilDormantMillis, and TimeTilDormantAutoDeleteMillis are enterprise-only. Their\nvalues are used if your license is entitled to use the advanced\ntemplate scheduling feature.",
                     "type": "integer"
@@ -14898,13 +15962,22 @@ const docTemplate = `{
                 "provisioner": {
                     "type": "string",
                     "enum": [
-                        "terraform"
+                        "terraform",
+                        "pulumi"
                     ]
                 },
                 "require_active_version": {
                     "description": "RequireActiveVersion mandates that workspaces are built with the active\ntemplate version.",
                     "type": "boolean"
                 },
+                "session_recording_enabled": {
+                    "description": "SessionRecordingEnabled records SSH and reconnecting PTY sessions of\nworkspaces created from this template.",
+                    "type": "boolean"
+                },
+                "session_recording_retention_ms": {
+                    "description": "SessionRecordingRetentionMillis is how long session recordings are\nkept. 0 means recordings are kept forever.",
+                    "type": "integer"
+                },
                 "time_til_dormant_autodelete_ms": {
                     "type": "integer"
                 },
@@ -15216,6 +16289,10 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "is_service_account": {
+                    "description": "IsServiceAccount is true for non-human users that are used by\nautomation. Service accounts cannot log in interactively.",
+                    "type": "boolean"
+                },
                 "last_seen_at": {
                     "type": "string",
                     "format": "date-time"
@@ -15499,6 +16576,17 @@ const docTemplate = `{
                 "TerminalFontJetBrainsMono"
             ]
         },
+        "codersdk.TerraformEngine": {
+            "type": "string",
+            "enum": [
+                "terraform",
+                "opentofu"
+            ],
+            "x-enum-varnames": [
+                "TerraformEngineTerraform",
+                "TerraformEngineOpenTofu"
+            ]
+        },
         "codersdk.TimingStage": {
             "type": "string",
             "enum": [
@@ -15735,6 +16823,30 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateUserSecretRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "env_name": {
+                    "type": "string"
+                },
+                "file_path": {
+                    "type": "string"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.UpdateWorkspaceAutomaticUpdatesRequest": {
             "type": "object",
             "properties": {
@@ -15785,6 +16897,31 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpsertWorkspaceAgentPTYShareRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "enum": [
+                        "read_only",
+                        "read_write"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentPTYShareMode"
+                        }
+                    ]
+                },
+                "reconnect_id": {
+                    "description": "ReconnectID is the ID of the reconnecting PTY to share. It is the\n\"reconnect\" query parameter of the PTY endpoint.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "user": {
+                    "description": "User is the username or ID of the user to share the PTY with.",
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.UpsertWorkspaceAgentPortShareRequest": {
             "type": "object",
             "properties": {
@@ -15859,6 +16996,10 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "is_service_account": {
+                    "description": "IsServiceAccount is true for non-human users that are used by\nautomation. Service accounts cannot log in interactively.",
+                    "type": "boolean"
+                },
                 "last_seen_at": {
                     "type": "string",
                     "format": "date-time"
@@ -16097,6 +17238,49 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UserSecret": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "env_name": {
+                    "description": "EnvName is the environment variable the secret is injected into\nworkspaces as. Empty if it isn't injected as an environment variable.",
+                    "type": "string"
+                },
+                "file_path": {
+                    "description": "FilePath is the path of the file the secret is written to in\nworkspaces. Relative paths are relative to the home directory. Empty\nif it isn't written to a file.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "template_ids": {
+                    "description": "TemplateIDs scope the secret to workspaces of the given templates.\nEmpty if it is injected into every workspace.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.UserStatus": {
             "type": "string",
             "enum": [
@@ -16230,6 +17414,14 @@ const docTemplate = `{
                     "type": "string",
                     "format": "date-time"
                 },
+                "drift": {
+                    "description": "Drift is the result of the last drift check against the latest build,\nif its template has drift detection enabled and the build was checked.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceDrift"
+                        }
+                    ]
+                },
                 "favorite": {
                     "type": "boolean"
                 },
@@ -16660,6 +17852,98 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceAgentPTYAttachment": {
+            "type": "object",
+            "properties": {
+                "attached_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "read_only": {
+                    "type": "boolean"
+                },
+                "username": {
+                    "description": "Username is empty for connections that were not made through\ncoderd, such as ones made directly over the tailnet.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentPTYPresence": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAgentPTYAttachment"
+                    }
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentPTYShare": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "mode": {
+                    "enum": [
+                        "read_only",
+                        "read_write"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentPTYShareMode"
+                        }
+                    ]
+                },
+                "reconnect_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentPTYShareMode": {
+            "type": "string",
+            "enum": [
+                "read_only",
+                "read_write"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceAgentPTYShareModeReadOnly",
+                "WorkspaceAgentPTYShareModeReadWrite"
+            ]
+        },
+        "codersdk.WorkspaceAgentPTYShares": {
+            "type": "object",
+            "properties": {
+                "shares": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAgentPTYShare"
+                    }
+                }
+            }
+        },
         "codersdk.WorkspaceAgentPortShare": {
             "type": "object",
             "properties": {
@@ -17163,6 +18447,47 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceDrift": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceDriftResource"
+                    }
+                },
+                "workspace_build_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.WorkspaceDriftResource": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action is \"update\" if the resource was changed, or \"delete\" if it no\nlonger exists.",
+                    "type": "string",
+                    "enum": [
+                        "update",
+                        "delete"
+                    ]
+                },
+                "address": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.WorkspaceHealth": {
             "type": "object",
             "properties": {
@@ -18475,8 +19800,12 @@ const docTemplate = `{
                     "description": "BasePath of the app. For path apps, this is the path prefix in the router\nfor this particular app. For subdomain apps, this should be \"/\". This is\nused for setting the cookie path.",
                     "type": "string"
                 },
+                "reconnect_id": {
+                    "description": "ReconnectID is the ID of the reconnecting PTY for terminal requests. It\nis optional for the workspace owner, but is required to access a PTY\nthat was shared with the user.",
+                    "type": "string"
+                },
                 "username_or_id": {
-                    "description": "For the following fields, if the AccessMethod is AccessMethodTerminal,\nthen only AgentNameOrID may be set and it must be a UUID. The other\nfields must be left blank.",
+                    "description": "For the following fields, if the AccessMethod is AccessMethodTerminal,\nthen only AgentNameOrID and ReconnectID may be set and they must be\nUUIDs. The other fields must be left blank.",
                     "type": "string"
                 },
                 "workspace_name_or_id": {
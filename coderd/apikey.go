@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/moby/moby/pkg/namesgenerator"
+	"golang.org/x/exp/maps"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/coderd/apikey"
@@ -18,6 +20,7 @@ import (
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/coderd/telemetry"
 	"github.com/coder/coder/v2/codersdk"
@@ -56,9 +59,55 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	scope := database.APIKeyScopeAll
-	if scope != "" {
+	if createToken.Scope != "" {
 		scope = database.APIKeyScope(createToken.Scope)
 	}
+	if !scope.Valid() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid scope %q.", createToken.Scope),
+			Validations: []codersdk.ValidationError{{
+				Field:  "scope",
+				Detail: fmt.Sprintf("Must be one of %v.", codersdk.APIKeyScopes),
+			}},
+		})
+		return
+	}
+	if user.IsServiceAccount && scope == database.APIKeyScopeAll {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Service accounts can only create scoped tokens.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "scope",
+				Detail: fmt.Sprintf("Must be a scope other than %q.", database.APIKeyScopeAll),
+			}},
+		})
+		return
+	}
+
+	var allowList []string
+	if len(createToken.AllowList) > 0 {
+		if !rbac.ScopeSupportsAllowList(scope.ToRBAC()) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Tokens with the scope %q can't have an allow list.", scope),
+				Validations: []codersdk.ValidationError{{
+					Field:  "allow_list",
+					Detail: "Allow lists are only supported for workspace and template scopes.",
+				}},
+			})
+			return
+		}
+		var err error
+		allowList, err = api.tokenAllowList(ctx, user.ID, createToken.AllowList)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid allow list.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "allow_list",
+					Detail: err.Error(),
+				}},
+			})
+			return
+		}
+	}
 
 	tokenName := namesgenerator.GetRandomName(1)
 
@@ -72,6 +121,7 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 		DefaultLifetime: api.DeploymentValues.Sessions.DefaultTokenDuration.Value(),
 		Scope:           scope,
 		TokenName:       tokenName,
+		AllowList:       allowList,
 	}
 
 	if createToken.Lifetime != 0 {
@@ -109,6 +159,38 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.GenerateAPIKeyResponse{Key: cookie.Value})
 }
 
+// tokenAllowList returns the resource IDs a token restricted to the given
+// workspaces and templates can access. The templates of the workspaces and the
+// owner of the token are added so that the resources can be looked up.
+func (api *API) tokenAllowList(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]string, error) {
+	allowed := map[string]struct{}{
+		userID.String(): {},
+	}
+	for _, id := range ids {
+		workspace, err := api.Database.GetWorkspaceByID(ctx, id)
+		if err == nil {
+			allowed[workspace.ID.String()] = struct{}{}
+			allowed[workspace.TemplateID.String()] = struct{}{}
+			continue
+		}
+		if !httpapi.Is404Error(err) {
+			return nil, xerrors.Errorf("get workspace %s: %w", id, err)
+		}
+		template, err := api.Database.GetTemplateByID(ctx, id)
+		if err == nil {
+			allowed[template.ID.String()] = struct{}{}
+			continue
+		}
+		if !httpapi.Is404Error(err) {
+			return nil, xerrors.Errorf("get template %s: %w", id, err)
+		}
+		return nil, xerrors.Errorf("%s is not the ID of a workspace or template", id)
+	}
+	allowList := maps.Keys(allowed)
+	slices.Sort(allowList)
+	return allowList, nil
+}
+
 // Creates a new session key, used for logging in via the CLI.
 //
 // @Summary Create new session key
@@ -123,6 +205,13 @@ func (api *API) postAPIKey(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	user := httpmw.UserParam(r)
 
+	if user.IsServiceAccount {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Service accounts cannot create session keys. Create a scoped token instead.",
+		})
+		return
+	}
+
 	cookie, _, err := api.createAPIKey(ctx, apikey.CreateParams{
 		UserID:          user.ID,
 		DefaultLifetime: api.DeploymentValues.Sessions.DefaultTokenDuration.Value(),
@@ -28,6 +28,8 @@ type CreateParams struct {
 	Scope           database.APIKeyScope
 	TokenName       string
 	RemoteAddr      string
+	// AllowList contains the IDs of the only resources the key can access.
+	AllowList []string
 }
 
 // Generate generates an API key, returning the key as a string as well as the
@@ -66,11 +68,13 @@ func Generate(params CreateParams) (database.InsertAPIKeyParams, string, error)
 	if params.Scope != "" {
 		scope = params.Scope
 	}
-	switch scope {
-	case database.APIKeyScopeAll, database.APIKeyScopeApplicationConnect:
-	default:
+	if !scope.Valid() {
 		return database.InsertAPIKeyParams{}, "", xerrors.Errorf("invalid API key scope: %q", scope)
 	}
+	allowList := params.AllowList
+	if allowList == nil {
+		allowList = []string{}
+	}
 
 	token := fmt.Sprintf("%s-%s", keyID, keySecret)
 
@@ -94,6 +98,7 @@ func Generate(params CreateParams) (database.InsertAPIKeyParams, string, error)
 		LoginType:    params.LoginType,
 		Scope:        scope,
 		TokenName:    params.TokenName,
+		AllowList:    allowList,
 	}, token, nil
 }
 
@@ -1,12 +1,14 @@
 package coderd_test
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/provisioner/echo"
 	"github.com/coder/coder/v2/testutil"
 	"github.com/coder/serpent"
 )
@@ -85,6 +88,223 @@ func TestTokenScoped(t *testing.T) {
 	require.Equal(t, keys[0].Scope, codersdk.APIKeyScopeApplicationConnect)
 }
 
+func TestTokenResourceScoped(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	owner := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	allowed := coderdtest.CreateWorkspace(t, client, template.ID)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, allowed.LatestBuild.ID)
+	other := coderdtest.CreateWorkspace(t, client, template.ID)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, other.LatestBuild.ID)
+
+	t.Run("WorkspaceRead", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope: codersdk.APIKeyScopeWorkspaceRead,
+		})
+		require.NoError(t, err)
+		scoped := codersdk.New(client.URL)
+		scoped.SetSessionToken(res.Key)
+
+		_, err = scoped.Workspace(ctx, allowed.ID)
+		require.NoError(t, err)
+		_, err = scoped.Workspace(ctx, other.ID)
+		require.NoError(t, err)
+
+		// Builds are not allowed.
+		_, err = scoped.CreateWorkspaceBuild(ctx, allowed.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.Error(t, err)
+		// Neither are other resources.
+		_, err = scoped.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.Error(t, err)
+	})
+
+	t.Run("WorkspaceStop", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope: codersdk.APIKeyScopeWorkspaceStart,
+		})
+		require.NoError(t, err)
+		startOnly := codersdk.New(client.URL)
+		startOnly.SetSessionToken(res.Key)
+		_, err = startOnly.CreateWorkspaceBuild(ctx, other.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.Error(t, err)
+
+		res, err = client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope: codersdk.APIKeyScopeWorkspaceStop,
+		})
+		require.NoError(t, err)
+		stopOnly := codersdk.New(client.URL)
+		stopOnly.SetSessionToken(res.Key)
+		build, err := stopOnly.CreateWorkspaceBuild(ctx, other.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, build.ID)
+	})
+
+	t.Run("AllowList", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope:     codersdk.APIKeyScopeWorkspaceRead,
+			AllowList: []uuid.UUID{allowed.ID},
+		})
+		require.NoError(t, err)
+		scoped := codersdk.New(client.URL)
+		scoped.SetSessionToken(res.Key)
+
+		keys, err := client.Tokens(ctx, codersdk.Me, codersdk.TokensFilter{})
+		require.NoError(t, err)
+		for _, key := range keys {
+			if strings.HasPrefix(res.Key, key.ID) {
+				require.ElementsMatch(t, []uuid.UUID{owner.UserID, allowed.ID, template.ID}, key.AllowList)
+			}
+		}
+
+		_, err = scoped.Workspace(ctx, allowed.ID)
+		require.NoError(t, err)
+		_, err = scoped.Workspace(ctx, other.ID)
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+
+		// Lists are filtered by the allow list too.
+		workspaces, err := scoped.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Len(t, workspaces.Workspaces, 1)
+		require.Equal(t, allowed.ID, workspaces.Workspaces[0].ID)
+	})
+
+	t.Run("AllowListWorkspaceStart", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+		coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+
+		res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope:     codersdk.APIKeyScopeWorkspaceStart,
+			AllowList: []uuid.UUID{workspace.ID},
+		})
+		require.NoError(t, err)
+		scoped := codersdk.New(client.URL)
+		scoped.SetSessionToken(res.Key)
+
+		// Starting the workspace needs the user, organization, provisioner
+		// daemons and jobs, none of which are in the allow list.
+		build, err := scoped.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStart,
+		})
+		require.NoError(t, err)
+		build = coderdtest.AwaitWorkspaceBuildJobCompleted(t, scoped, build.ID)
+		require.Equal(t, codersdk.WorkspaceStatusRunning, build.Status)
+
+		_, err = scoped.CreateWorkspaceBuild(ctx, other.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStart,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("AllowListTemplatePush", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		pushVersion := coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, pushVersion.ID)
+		pushTemplate := coderdtest.CreateTemplate(t, client, owner.OrganizationID, pushVersion.ID)
+		res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope:     codersdk.APIKeyScopeTemplatePush,
+			AllowList: []uuid.UUID{pushTemplate.ID},
+		})
+		require.NoError(t, err)
+		scoped := codersdk.New(client.URL)
+		scoped.SetSessionToken(res.Key)
+
+		// Pushing uploads a file and waits for the provisioner job of the new
+		// version, neither of which can be in the allow list.
+		data, err := echo.Tar(nil)
+		require.NoError(t, err)
+		file, err := scoped.Upload(ctx, codersdk.ContentTypeTar, bytes.NewReader(data))
+		require.NoError(t, err)
+		pushed, err := scoped.CreateTemplateVersion(ctx, owner.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			TemplateID:    pushTemplate.ID,
+			FileID:        file.ID,
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+		})
+		require.NoError(t, err)
+		pushed = coderdtest.AwaitTemplateVersionJobCompleted(t, scoped, pushed.ID)
+		require.Equal(t, codersdk.ProvisionerJobSucceeded, pushed.Job.Status)
+		err = scoped.UpdateActiveTemplateVersion(ctx, pushTemplate.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: pushed.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = scoped.CreateTemplateVersion(ctx, owner.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			TemplateID:    template.ID,
+			FileID:        file.ID,
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidAllowList", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope:     codersdk.APIKeyScopeWorkspaceRead,
+			AllowList: []uuid.UUID{uuid.New()},
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+	})
+
+	t.Run("AllowListUnsupportedScope", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		for _, scope := range []codersdk.APIKeyScope{"", codersdk.APIKeyScopeAll, codersdk.APIKeyScopeApplicationConnect} {
+			_, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+				Scope:     scope,
+				AllowList: []uuid.UUID{allowed.ID},
+			})
+			var sdkErr *codersdk.Error
+			require.ErrorAs(t, err, &sdkErr, "scope %q", scope)
+			require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode(), "scope %q", scope)
+		}
+	})
+
+	t.Run("InvalidScope", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scope: "workspace:destroy",
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+	})
+}
+
 func TestUserSetTokenDuration(t *testing.T) {
 	t.Parallel()
 
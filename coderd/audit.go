@@ -207,6 +207,7 @@ func (api *API) convertAuditLog(ctx context.Context, dblog database.GetAuditLogs
 			LastSeenAt:         dblog.UserLastSeenAt.Time,
 			QuietHoursSchedule: dblog.UserQuietHoursSchedule.String,
 			Name:               dblog.UserName.String,
+			IsServiceAccount:   dblog.UserIsServiceAccount.Bool,
 		}, []uuid.UUID{})
 		user = &sdkUser
 	}
@@ -763,6 +763,10 @@ func New(options *Options) *API {
 		Cookies:                  options.DeploymentValues.HTTPCookies,
 		APIKeyEncryptionKeycache: options.AppEncryptionKeyCache,
 	}
+	api.cancelPTYShareSubscribe, err = options.Pubsub.Subscribe(workspaceapps.PTYShareEventChannel, api.handlePTYShareEvent)
+	if err != nil {
+		panic(xerrors.Errorf("subscribe to pty share events: %w", err))
+	}
 
 	apiKeyMiddleware := httpmw.ExtractAPIKeyMW(httpmw.ExtractAPIKeyConfig{
 		DB:                            options.Database,
@@ -881,8 +885,9 @@ func New(options *Options) *API {
 	for _, route := range []string{"gitauth", "external-auth"} {
 		r.Route("/"+route, func(r chi.Router) {
 			for _, externalAuthConfig := range options.ExternalAuthConfigs {
-				// We don't need to register a callback handler for device auth.
-				if externalAuthConfig.DeviceAuth != nil {
+				// We don't need to register a callback handler for device auth,
+				// or for brokers which don't use OAuth2.
+				if externalAuthConfig.DeviceAuth != nil || externalAuthConfig.Broker != nil {
 					continue
 				}
 				r.Route(fmt.Sprintf("/%s/callback", externalAuthConfig.ID), func(r chi.Router) {
@@ -987,6 +992,17 @@ func New(options *Options) *API {
 			r.Get("/", api.auditLogs)
 			r.Post("/testgenerate", api.generateFakeAuditLog)
 		})
+		r.Route("/session-recordings", func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				api.requireSessionRecordingRead,
+			)
+			r.Get("/", api.sessionRecordings)
+			r.Route("/{sessionrecording}", func(r chi.Router) {
+				r.Get("/", api.sessionRecording)
+				r.Get("/cast", api.sessionRecordingCast)
+			})
+		})
 		r.Route("/files", func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -1065,6 +1081,7 @@ func New(options *Options) *API {
 				})
 				r.Route("/provisionerdaemons", func(r chi.Router) {
 					r.Get("/", api.provisionerDaemons)
+					r.Post("/{provisionerdaemon}/drain", api.drainProvisionerDaemon)
 				})
 				r.Route("/provisionerjobs", func(r chi.Router) {
 					r.Get("/{job}", api.provisionerJob)
@@ -1228,6 +1245,15 @@ func New(options *Options) *API {
 						})
 						r.Get("/gitsshkey", api.gitSSHKey)
 						r.Put("/gitsshkey", api.regenerateGitSSHKey)
+						r.Route("/secrets", func(r chi.Router) {
+							r.Get("/", api.userSecrets)
+							r.Post("/", api.postUserSecret)
+							r.Route("/{name}", func(r chi.Router) {
+								r.Get("/", api.userSecretByName)
+								r.Patch("/", api.patchUserSecret)
+								r.Delete("/", api.deleteUserSecret)
+							})
+						})
 						r.Route("/notifications", func(r chi.Router) {
 							r.Route("/preferences", func(r chi.Router) {
 								r.Get("/", api.userNotificationPreferences)
@@ -1291,8 +1317,13 @@ func New(options *Options) *API {
 				r.Get("/connection", api.workspaceAgentConnection)
 				r.Get("/containers", api.workspaceAgentListContainers)
 				r.Get("/coordinate", api.workspaceAgentClientCoordinate)
+				r.Route("/pty-shares", func(r chi.Router) {
+					r.Get("/", api.workspaceAgentPTYShares)
+					r.Post("/", api.postWorkspaceAgentPTYShare)
+					r.Delete("/", api.deleteWorkspaceAgentPTYShare)
+				})
 
-				// PTY is part of workspaceAppServer.
+				// PTY and PTY presence are part of workspaceAppServer.
 			})
 		})
 		r.Route("/workspaces", func(r chi.Router) {
@@ -1584,6 +1615,9 @@ type API struct {
 	WorkspaceAppsProvider workspaceapps.SignedTokenProvider
 	workspaceAppServer    *workspaceapps.Server
 	agentProvider         workspaceapps.AgentProvider
+	// cancelPTYShareSubscribe stops closing PTY sessions when their share is
+	// revoked.
+	cancelPTYShareSubscribe func()
 
 	// Experiments contains the list of experiments currently enabled.
 	// This is used to gate features that are not yet ready for production.
@@ -1632,6 +1666,7 @@ func (api *API) Close() error {
 	if api.updateChecker != nil {
 		api.updateChecker.Close()
 	}
+	api.cancelPTYShareSubscribe()
 	_ = api.workspaceAppServer.Close()
 	_ = api.agentProvider.Close()
 	if api.derpCloseFunc != nil {
@@ -1701,8 +1736,15 @@ func (api *API) CreateInMemoryTaggedProvisionerDaemon(dialCtx context.Context, n
 	}
 
 	dbTypes := make([]database.ProvisionerType, 0, len(provisionerTypes))
+	terraformEngine := ""
 	for _, tp := range provisionerTypes {
 		dbTypes = append(dbTypes, database.ProvisionerType(tp))
+		if tp == codersdk.ProvisionerTypeTerraform {
+			terraformEngine = api.DeploymentValues.Provisioner.TerraformEngine.String()
+			if terraformEngine == "" {
+				terraformEngine = string(codersdk.TerraformEngineTerraform)
+			}
+		}
 	}
 
 	keyID, err := uuid.Parse(string(codersdk.ProvisionerKeyIDBuiltIn))
@@ -1712,15 +1754,16 @@ func (api *API) CreateInMemoryTaggedProvisionerDaemon(dialCtx context.Context, n
 
 	//nolint:gocritic // in-memory provisioners are owned by system
 	daemon, err := api.Database.UpsertProvisionerDaemon(dbauthz.AsSystemRestricted(dialCtx), database.UpsertProvisionerDaemonParams{
-		Name:           name,
-		OrganizationID: defaultOrg.ID,
-		CreatedAt:      dbtime.Now(),
-		Provisioners:   dbTypes,
-		Tags:           provisionersdk.MutateTags(uuid.Nil, provisionerTags),
-		LastSeenAt:     sql.NullTime{Time: dbtime.Now(), Valid: true},
-		Version:        buildinfo.Version(),
-		APIVersion:     proto.CurrentVersion.String(),
-		KeyID:          keyID,
+		Name:            name,
+		OrganizationID:  defaultOrg.ID,
+		CreatedAt:       dbtime.Now(),
+		Provisioners:    dbTypes,
+		Tags:            provisionersdk.MutateTags(uuid.Nil, provisionerTags),
+		LastSeenAt:      sql.NullTime{Time: dbtime.Now(), Valid: true},
+		Version:         buildinfo.Version(),
+		APIVersion:      proto.CurrentVersion.String(),
+		KeyID:           keyID,
+		TerraformEngine: terraformEngine,
 	})
 	if err != nil {
 		return nil, xerrors.Errorf("failed to create in-memory provisioner daemon: %w", err)
@@ -65,6 +65,7 @@ import (
 	"github.com/coder/coder/v2/coderd/database/dbrollup"
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
 	"github.com/coder/coder/v2/coderd/database/pubsub"
+	"github.com/coder/coder/v2/coderd/driftdetect"
 	"github.com/coder/coder/v2/coderd/externalauth"
 	"github.com/coder/coder/v2/coderd/gitsshkey"
 	"github.com/coder/coder/v2/coderd/httpmw"
@@ -112,6 +113,8 @@ type Options struct {
 	SSHKeygenAlgorithm             gitsshkey.Algorithm
 	AutobuildTicker                <-chan time.Time
 	AutobuildStats                 chan<- autobuild.Stats
+	DriftDetectionTicker           <-chan time.Time
+	DriftDetectionStats            chan<- driftdetect.Stats
 	Auditor                        audit.Auditor
 	TLSCertificates                []tls.Certificate
 	ExternalAuthConfigs            []*externalauth.Config
@@ -241,6 +244,16 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 			close(options.AutobuildStats)
 		})
 	}
+	if options.DriftDetectionTicker == nil {
+		ticker := make(chan time.Time)
+		options.DriftDetectionTicker = ticker
+		t.Cleanup(func() { close(ticker) })
+	}
+	if options.DriftDetectionStats != nil {
+		t.Cleanup(func() {
+			close(options.DriftDetectionStats)
+		})
+	}
 
 	if options.Authorizer == nil {
 		defAuth := rbac.NewStrictCachingAuthorizer(prometheus.NewRegistry())
@@ -365,6 +378,15 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 	).WithStatsChannel(options.AutobuildStats)
 	lifecycleExecutor.Run()
 
+	driftDetector := driftdetect.NewExecutor(
+		ctx,
+		options.Database,
+		options.Pubsub,
+		*options.Logger,
+		options.DriftDetectionTicker,
+	).WithStatsChannel(options.DriftDetectionStats)
+	driftDetector.Run()
+
 	hangDetectorTicker := time.NewTicker(options.DeploymentValues.JobHangDetectorInterval.Value())
 	defer hangDetectorTicker.Stop()
 	hangDetector := unhanger.New(ctx, options.Database, options.Pubsub, options.Logger.Named("unhanger.detector"), hangDetectorTicker.C)
@@ -157,6 +157,8 @@ func ReducedUser(user database.User) codersdk.ReducedUser {
 		LastSeenAt: user.LastSeenAt,
 		Status:     codersdk.UserStatus(user.Status),
 		LoginType:  codersdk.LoginType(user.LoginType),
+
+		IsServiceAccount: user.IsServiceAccount,
 	}
 }
 
@@ -221,6 +223,7 @@ func Group(row database.GetGroupsRow, members []database.GroupMember, totalMembe
 		Source:                  codersdk.GroupSource(row.Group.Source),
 		OrganizationName:        row.OrganizationName,
 		OrganizationDisplayName: row.OrganizationDisplayName,
+		DLPPolicy:               codersdk.DLPPolicy(row.Group.DLPPolicy),
 	}
 }
 
@@ -550,15 +553,17 @@ func WorkspaceAppStatus(status database.WorkspaceAppStatus) codersdk.WorkspaceAp
 
 func ProvisionerDaemon(dbDaemon database.ProvisionerDaemon) codersdk.ProvisionerDaemon {
 	result := codersdk.ProvisionerDaemon{
-		ID:             dbDaemon.ID,
-		OrganizationID: dbDaemon.OrganizationID,
-		CreatedAt:      dbDaemon.CreatedAt,
-		LastSeenAt:     codersdk.NullTime{NullTime: dbDaemon.LastSeenAt},
-		Name:           dbDaemon.Name,
-		Tags:           dbDaemon.Tags,
-		Version:        dbDaemon.Version,
-		APIVersion:     dbDaemon.APIVersion,
-		KeyID:          dbDaemon.KeyID,
+		ID:               dbDaemon.ID,
+		OrganizationID:   dbDaemon.OrganizationID,
+		CreatedAt:        dbDaemon.CreatedAt,
+		LastSeenAt:       codersdk.NullTime{NullTime: dbDaemon.LastSeenAt},
+		Name:             dbDaemon.Name,
+		Tags:             dbDaemon.Tags,
+		Version:          dbDaemon.Version,
+		APIVersion:       dbDaemon.APIVersion,
+		TerraformEngine:  codersdk.TerraformEngine(dbDaemon.TerraformEngine),
+		DrainRequestedAt: codersdk.NullTime{NullTime: dbDaemon.DrainRequestedAt},
+		KeyID:            dbDaemon.KeyID,
 	}
 	for _, provisionerType := range dbDaemon.Provisioners {
 		result.Provisioners = append(result.Provisioners, codersdk.ProvisionerType(provisionerType))
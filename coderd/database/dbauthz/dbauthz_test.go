@@ -601,6 +601,18 @@ func (s *MethodTestSuite) TestProvisionerJob() {
 		})
 		check.Args(j.ID).Asserts(v.RBACObject(tpl), policy.ActionRead).Returns(j)
 	}))
+	s.Run("DriftCheck/GetProvisionerJobByID", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		w := dbgen.Workspace(s.T(), db, database.WorkspaceTable{})
+		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{
+			Type: database.ProvisionerJobTypeWorkspaceDriftCheck,
+		})
+		_ = dbgen.WorkspaceDriftCheck(s.T(), db, database.WorkspaceDriftCheck{
+			JobID:       j.ID,
+			WorkspaceID: w.ID,
+		})
+		check.Args(j.ID).Asserts(w, policy.ActionRead).Returns(j)
+	}))
 	s.Run("Build/UpdateProvisionerJobWithCancelByID", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
 		o := dbgen.Organization(s.T(), db, database.Organization{})
@@ -1736,6 +1748,50 @@ func (s *MethodTestSuite) TestUser() {
 			UpdatedAt:         link.UpdatedAt,
 		}).Asserts(rbac.ResourceUserObject(link.UserID), policy.ActionUpdatePersonal).Returns(link)
 	}))
+	s.Run("GetUserSecrets", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{UserID: u.ID})
+		check.Args(u.ID).Asserts(secret, policy.ActionReadPersonal).Returns([]database.UserSecret{secret})
+	}))
+	s.Run("GetUserSecretByID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{UserID: u.ID})
+		check.Args(secret.ID).Asserts(secret, policy.ActionReadPersonal).Returns(secret)
+	}))
+	s.Run("GetUserSecretByUserIDAndName", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{UserID: u.ID})
+		check.Args(database.GetUserSecretByUserIDAndNameParams{
+			UserID: u.ID,
+			Name:   secret.Name,
+		}).Asserts(secret, policy.ActionReadPersonal).Returns(secret)
+	}))
+	s.Run("InsertUserSecret", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertUserSecretParams{
+			ID:          uuid.New(),
+			UserID:      u.ID,
+			Name:        "npm-token",
+			TemplateIDs: []uuid.UUID{},
+		}).Asserts(rbac.ResourceUserObject(u.ID), policy.ActionUpdatePersonal)
+	}))
+	s.Run("UpdateUserSecret", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{UserID: u.ID})
+		secret.Value = "updated"
+		check.Args(database.UpdateUserSecretParams{
+			ID:          secret.ID,
+			Description: secret.Description,
+			Value:       secret.Value,
+			TemplateIDs: secret.TemplateIDs,
+			UpdatedAt:   secret.UpdatedAt,
+		}).Asserts(secret, policy.ActionUpdatePersonal).Returns(secret)
+	}))
+	s.Run("DeleteUserSecret", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{UserID: u.ID})
+		check.Args(secret.ID).Asserts(secret, policy.ActionUpdatePersonal)
+	}))
 	s.Run("UpdateUserLink", s.Subtest(func(db database.Store, check *expects) {
 		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
 		link := dbgen.UserLink(s.T(), db, database.UserLink{})
@@ -3314,6 +3370,155 @@ func (s *MethodTestSuite) TestWorkspacePortSharing() {
 	}))
 }
 
+func (s *MethodTestSuite) TestWorkspacePTYSharing() {
+	setup := func(db database.Store) (database.WorkspaceTable, database.WorkspaceAgent, database.User) {
+		u := dbgen.User(s.T(), db, database.User{})
+		other := dbgen.User(s.T(), db, database.User{})
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		tpl := dbgen.Template(s.T(), db, database.Template{
+			OrganizationID: o.ID,
+			CreatedBy:      u.ID,
+		})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID:     uuid.NullUUID{UUID: tpl.ID, Valid: true},
+			OrganizationID: o.ID,
+			CreatedBy:      u.ID,
+		})
+		w := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
+			TemplateID:     tpl.ID,
+			OrganizationID: o.ID,
+			OwnerID:        u.ID,
+		})
+		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{
+			Type: database.ProvisionerJobTypeWorkspaceBuild,
+		})
+		b := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{
+			JobID:             j.ID,
+			WorkspaceID:       w.ID,
+			TemplateVersionID: tv.ID,
+		})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: b.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		return w, agt, other
+	}
+	s.Run("UpsertWorkspaceAgentPTYShare", s.Subtest(func(db database.Store, check *expects) {
+		w, agt, other := setup(db)
+		share := dbgen.WorkspaceAgentPTYShare(s.T(), db, database.WorkspaceAgentPTYShare{
+			WorkspaceID: w.ID,
+			AgentID:     agt.ID,
+			UserID:      other.ID,
+			CreatedBy:   w.OwnerID,
+		})
+		share.Mode = database.PTYShareModeReadWrite
+		//nolint:gosimple // casting is not a simplification
+		check.Args(database.UpsertWorkspaceAgentPTYShareParams{
+			WorkspaceID: share.WorkspaceID,
+			AgentID:     share.AgentID,
+			ReconnectID: share.ReconnectID,
+			UserID:      share.UserID,
+			Mode:        share.Mode,
+			CreatedBy:   share.CreatedBy,
+			CreatedAt:   share.CreatedAt,
+		}).Asserts(w, policy.ActionUpdate).Returns(share)
+	}))
+	s.Run("GetWorkspaceAgentPTYShare", s.Subtest(func(db database.Store, check *expects) {
+		w, agt, other := setup(db)
+		share := dbgen.WorkspaceAgentPTYShare(s.T(), db, database.WorkspaceAgentPTYShare{
+			WorkspaceID: w.ID,
+			AgentID:     agt.ID,
+			UserID:      other.ID,
+			CreatedBy:   w.OwnerID,
+		})
+		check.Args(database.GetWorkspaceAgentPTYShareParams{
+			AgentID:     share.AgentID,
+			ReconnectID: share.ReconnectID,
+			UserID:      share.UserID,
+		}).Asserts(w, policy.ActionRead).Returns(share)
+	}))
+	s.Run("ListWorkspaceAgentPTYShares", s.Subtest(func(db database.Store, check *expects) {
+		w, agt, other := setup(db)
+		share := dbgen.WorkspaceAgentPTYShare(s.T(), db, database.WorkspaceAgentPTYShare{
+			WorkspaceID: w.ID,
+			AgentID:     agt.ID,
+			UserID:      other.ID,
+			CreatedBy:   w.OwnerID,
+		})
+		check.Args(agt.ID).Asserts(w, policy.ActionRead).Returns([]database.WorkspaceAgentPTYShare{share})
+	}))
+	s.Run("DeleteWorkspaceAgentPTYShare", s.Subtest(func(db database.Store, check *expects) {
+		w, agt, other := setup(db)
+		share := dbgen.WorkspaceAgentPTYShare(s.T(), db, database.WorkspaceAgentPTYShare{
+			WorkspaceID: w.ID,
+			AgentID:     agt.ID,
+			UserID:      other.ID,
+			CreatedBy:   w.OwnerID,
+		})
+		check.Args(database.DeleteWorkspaceAgentPTYShareParams{
+			AgentID:     share.AgentID,
+			ReconnectID: share.ReconnectID,
+			UserID:      share.UserID,
+		}).Asserts(w, policy.ActionUpdate).Returns()
+	}))
+}
+
+func (s *MethodTestSuite) TestWorkspaceSessionRecordings() {
+	s.Run("UpsertWorkspaceSessionRecording", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		tpl := dbgen.Template(s.T(), db, database.Template{
+			OrganizationID: o.ID,
+			CreatedBy:      u.ID,
+		})
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
+			TemplateID:     tpl.ID,
+			OrganizationID: o.ID,
+			OwnerID:        u.ID,
+		})
+		check.Args(database.UpsertWorkspaceSessionRecordingParams{
+			ID:          uuid.New(),
+			WorkspaceID: ws.ID,
+			AgentID:     uuid.New(),
+			Type:        database.SessionRecordingTypeSsh,
+			StartedAt:   dbtime.Now(),
+			CreatedAt:   dbtime.Now(),
+		}).Asserts(ws, policy.ActionUpdate).Returns()
+	}))
+	s.Run("InsertWorkspaceSessionRecordingChunk", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		u := dbgen.User(s.T(), db, database.User{})
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		tpl := dbgen.Template(s.T(), db, database.Template{
+			OrganizationID: o.ID,
+			CreatedBy:      u.ID,
+		})
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{
+			TemplateID:     tpl.ID,
+			OrganizationID: o.ID,
+			OwnerID:        u.ID,
+		})
+		r := dbgen.WorkspaceSessionRecording(s.T(), db, database.WorkspaceSessionRecording{WorkspaceID: ws.ID})
+		check.Args(database.InsertWorkspaceSessionRecordingChunkParams{
+			RecordingID: r.ID,
+			AgentID:     r.AgentID,
+			ChunkIndex:  0,
+			Data:        []byte("{\"version\":2,\"width\":80,\"height\":24}\n"),
+		}).Asserts(ws, policy.ActionUpdate).Returns()
+	}))
+	s.Run("GetWorkspaceSessionRecordingByID", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		u := dbgen.User(s.T(), db, database.User{})
+		ws := dbgen.Workspace(s.T(), db, database.WorkspaceTable{OwnerID: u.ID})
+		r := dbgen.WorkspaceSessionRecording(s.T(), db, database.WorkspaceSessionRecording{WorkspaceID: ws.ID})
+		check.Args(r.ID).Asserts(rbac.ResourceAuditLog, policy.ActionRead)
+	}))
+	s.Run("GetWorkspaceSessionRecordingChunks", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceAuditLog, policy.ActionRead)
+	}))
+	s.Run("GetWorkspaceSessionRecordings", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetWorkspaceSessionRecordingsParams{}).Asserts(rbac.ResourceAuditLog, policy.ActionRead)
+	}))
+}
+
 func (s *MethodTestSuite) TestProvisionerKeys() {
 	s.Run("InsertProvisionerKey", s.Subtest(func(db database.Store, check *expects) {
 		org := dbgen.Organization(s.T(), db, database.Organization{})
@@ -3398,6 +3603,17 @@ func (s *MethodTestSuite) TestExtraMethods() {
 		s.NoError(err, "insert provisioner daemon")
 		check.Args().Asserts(d, policy.ActionRead)
 	}))
+	s.Run("GetProvisionerDaemonByID", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		d, err := db.UpsertProvisionerDaemon(context.Background(), database.UpsertProvisionerDaemonParams{
+			Provisioners: []database.ProvisionerType{},
+			Tags: database.StringMap(map[string]string{
+				provisionersdk.TagScope: provisionersdk.ScopeOrganization,
+			}),
+		})
+		s.NoError(err, "insert provisioner daemon")
+		check.Args(d.ID).Asserts(d, policy.ActionRead).Returns(d)
+	}))
 	s.Run("GetProvisionerDaemonsByOrganization", s.Subtest(func(db database.Store, check *expects) {
 		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
 		org := dbgen.Organization(s.T(), db, database.Organization{})
@@ -3444,6 +3660,7 @@ func (s *MethodTestSuite) TestExtraMethods() {
 			Provisioner:    database.ProvisionerTypeEcho,
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Input:          json.RawMessage("{}"),
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		s.NoError(err, "insert provisioner job")
 		d, err := db.UpsertProvisionerDaemon(context.Background(), database.UpsertProvisionerDaemonParams{
@@ -3481,6 +3698,20 @@ func (s *MethodTestSuite) TestExtraMethods() {
 			LastSeenAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
 		}).Asserts(rbac.ResourceProvisionerDaemon, policy.ActionUpdate)
 	}))
+	s.Run("UpdateProvisionerDaemonDrainRequestedAt", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		d, err := db.UpsertProvisionerDaemon(context.Background(), database.UpsertProvisionerDaemonParams{
+			Provisioners: []database.ProvisionerType{},
+			Tags: database.StringMap(map[string]string{
+				provisionersdk.TagScope: provisionersdk.ScopeOrganization,
+			}),
+		})
+		s.NoError(err, "insert provisioner daemon")
+		check.Args(database.UpdateProvisionerDaemonDrainRequestedAtParams{
+			ID:               d.ID,
+			DrainRequestedAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+		}).Asserts(d, policy.ActionUpdate)
+	}))
 	s.Run("GetProvisionerJobsByOrganizationAndStatusWithQueuePositionAndProvisioner", s.Subtest(func(db database.Store, check *expects) {
 		org := dbgen.Organization(s.T(), db, database.Organization{})
 		user := dbgen.User(s.T(), db, database.User{})
@@ -3890,6 +4121,13 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("DeleteOldWorkspaceAgentStats", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, policy.ActionDelete)
 	}))
+	s.Run("DeleteOldWorkspaceSessionRecordings", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(dbtime.Now()).Asserts(rbac.ResourceSystem, policy.ActionDelete)
+	}))
+	s.Run("GetPendingProvisionerJobCounts", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{})
+		check.Args().Asserts(rbac.ResourceProvisionerJobs, policy.ActionRead)
+	}))
 	s.Run("GetProvisionerJobsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		// TODO: add provisioner job resource type
 		_ = dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{CreatedAt: time.Now().Add(-time.Hour)})
@@ -4051,6 +4289,7 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
 			Input:         json.RawMessage("{}"),
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		}).Asserts( /*rbac.ResourceSystem, policy.ActionCreate*/ )
 	}))
 	s.Run("InsertProvisionerJobLogs", s.Subtest(func(db database.Store, check *expects) {
@@ -4403,6 +4642,35 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("GetWorkspaceModulesByJobID", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
+	s.Run("InsertWorkspaceDriftCheck", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		check.Args(database.InsertWorkspaceDriftCheckParams{
+			JobID:            uuid.New(),
+			WorkspaceID:      uuid.New(),
+			WorkspaceBuildID: uuid.New(),
+			CreatedAt:        dbtime.Now(),
+		}).Asserts(rbac.ResourceSystem, policy.ActionCreate)
+	}))
+	s.Run("UpdateWorkspaceDriftCheckByJobID", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		c := dbgen.WorkspaceDriftCheck(s.T(), db, database.WorkspaceDriftCheck{})
+		check.Args(database.UpdateWorkspaceDriftCheckByJobIDParams{
+			JobID:     c.JobID,
+			CheckedAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+			Resources: database.WorkspaceDriftResources{},
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate)
+	}))
+	s.Run("GetWorkspaceDriftCheckByJobID", s.Subtest(func(db database.Store, check *expects) {
+		dbtestutil.DisableForeignKeysAndTriggers(s.T(), db)
+		c := dbgen.WorkspaceDriftCheck(s.T(), db, database.WorkspaceDriftCheck{})
+		check.Args(c.JobID).Asserts(rbac.ResourceSystem, policy.ActionRead).Returns(c)
+	}))
+	s.Run("GetLatestWorkspaceDriftChecksByWorkspaceIDs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args([]uuid.UUID{}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("GetWorkspacesDueDriftCheck", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetWorkspacesDueDriftCheckParams{Now: dbtime.Now(), LimitCount: 10}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
 	s.Run("GetWorkspaceModulesCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(dbtime.Now()).Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
@@ -91,6 +91,7 @@ func (b OrganizationBuilder) Do() OrganizationResponse {
 			DisplayName:    everyone.DisplayName,
 			AvatarURL:      everyone.AvatarURL,
 			QuotaAllowance: b.allUsersAllowance,
+			DLPPolicy:      everyone.DLPPolicy,
 			ID:             everyone.ID,
 		})
 		require.NoError(b.t, err)
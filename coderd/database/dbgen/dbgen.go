@@ -137,6 +137,7 @@ func APIKey(t testing.TB, db database.Store, seed database.APIKey) (key database
 		LoginType:       takeFirst(seed.LoginType, database.LoginTypePassword),
 		Scope:           takeFirst(seed.Scope, database.APIKeyScopeAll),
 		TokenName:       takeFirst(seed.TokenName),
+		AllowList:       takeFirstSlice(seed.AllowList, []string{}),
 	})
 	require.NoError(t, err, "insert api key")
 	return key, fmt.Sprintf("%s-%s", key.ID, secret)
@@ -154,6 +155,20 @@ func WorkspaceAgentPortShare(t testing.TB, db database.Store, orig database.Work
 	return ps
 }
 
+func WorkspaceAgentPTYShare(t testing.TB, db database.Store, orig database.WorkspaceAgentPTYShare) database.WorkspaceAgentPTYShare {
+	share, err := db.UpsertWorkspaceAgentPTYShare(genCtx, database.UpsertWorkspaceAgentPTYShareParams{
+		WorkspaceID: takeFirst(orig.WorkspaceID, uuid.New()),
+		AgentID:     takeFirst(orig.AgentID, uuid.New()),
+		ReconnectID: takeFirst(orig.ReconnectID, uuid.New()),
+		UserID:      takeFirst(orig.UserID, uuid.New()),
+		Mode:        takeFirst(orig.Mode, database.PTYShareModeReadOnly),
+		CreatedBy:   takeFirst(orig.CreatedBy, uuid.New()),
+		CreatedAt:   takeFirst(orig.CreatedAt, dbtime.Now()),
+	})
+	require.NoError(t, err, "upsert workspace agent pty share")
+	return share
+}
+
 func WorkspaceAgent(t testing.TB, db database.Store, orig database.WorkspaceAgent) database.WorkspaceAgent {
 	agt, err := db.InsertWorkspaceAgent(genCtx, database.InsertWorkspaceAgentParams{
 		ID:         takeFirst(orig.ID, uuid.New()),
@@ -399,6 +414,9 @@ func User(t testing.TB, db database.Store, orig database.User) database.User {
 		RBACRoles:      takeFirstSlice(orig.RBACRoles, []string{}),
 		LoginType:      takeFirst(orig.LoginType, database.LoginTypePassword),
 		Status:         string(takeFirst(orig.Status, database.UserStatusDormant)),
+
+		IsServiceAccount:             orig.IsServiceAccount,
+		ServiceAccountOrganizationID: orig.ServiceAccountOrganizationID,
 	})
 	require.NoError(t, err, "insert user")
 
@@ -575,15 +593,16 @@ func ProvisionerDaemon(t testing.TB, db database.Store, orig database.Provisione
 	}
 
 	daemon := database.UpsertProvisionerDaemonParams{
-		Name:           takeFirst(orig.Name, testutil.GetRandomName(t)),
-		OrganizationID: takeFirst(orig.OrganizationID, defOrgID, uuid.New()),
-		CreatedAt:      takeFirst(orig.CreatedAt, dbtime.Now()),
-		Provisioners:   takeFirstSlice(orig.Provisioners, []database.ProvisionerType{database.ProvisionerTypeEcho}),
-		Tags:           takeFirstMap(orig.Tags, database.StringMap{}),
-		KeyID:          takeFirst(orig.KeyID, uuid.Nil),
-		LastSeenAt:     takeFirst(orig.LastSeenAt, sql.NullTime{Time: dbtime.Now(), Valid: true}),
-		Version:        takeFirst(orig.Version, "v0.0.0"),
-		APIVersion:     takeFirst(orig.APIVersion, "1.1"),
+		Name:            takeFirst(orig.Name, testutil.GetRandomName(t)),
+		OrganizationID:  takeFirst(orig.OrganizationID, defOrgID, uuid.New()),
+		CreatedAt:       takeFirst(orig.CreatedAt, dbtime.Now()),
+		Provisioners:    takeFirstSlice(orig.Provisioners, []database.ProvisionerType{database.ProvisionerTypeEcho}),
+		Tags:            takeFirstMap(orig.Tags, database.StringMap{}),
+		KeyID:           takeFirst(orig.KeyID, uuid.Nil),
+		LastSeenAt:      takeFirst(orig.LastSeenAt, sql.NullTime{Time: dbtime.Now(), Valid: true}),
+		Version:         takeFirst(orig.Version, "v0.0.0"),
+		APIVersion:      takeFirst(orig.APIVersion, "1.1"),
+		TerraformEngine: orig.TerraformEngine,
 	}
 
 	if daemon.KeyID == uuid.Nil {
@@ -640,6 +659,7 @@ func ProvisionerJob(t testing.TB, db database.Store, ps pubsub.Pubsub, orig data
 		Input:          takeFirstSlice(orig.Input, []byte("{}")),
 		Tags:           tags,
 		TraceMetadata:  pqtype.NullRawMessage{},
+		Priority:       takeFirst(orig.Priority, database.ProvisionerJobPriorityInteractive),
 	})
 	require.NoError(t, err, "insert job")
 	if ps != nil {
@@ -798,6 +818,50 @@ func WorkspaceModule(t testing.TB, db database.Store, orig database.WorkspaceMod
 	return module
 }
 
+func WorkspaceDriftCheck(t testing.TB, db database.Store, orig database.WorkspaceDriftCheck) database.WorkspaceDriftCheck {
+	check, err := db.InsertWorkspaceDriftCheck(genCtx, database.InsertWorkspaceDriftCheckParams{
+		JobID:            takeFirst(orig.JobID, uuid.New()),
+		WorkspaceID:      takeFirst(orig.WorkspaceID, uuid.New()),
+		WorkspaceBuildID: takeFirst(orig.WorkspaceBuildID, uuid.New()),
+		CreatedAt:        takeFirst(orig.CreatedAt, dbtime.Now()),
+	})
+	require.NoError(t, err, "insert workspace drift check")
+	if orig.CheckedAt.Valid {
+		err = db.UpdateWorkspaceDriftCheckByJobID(genCtx, database.UpdateWorkspaceDriftCheckByJobIDParams{
+			JobID:     check.JobID,
+			CheckedAt: orig.CheckedAt,
+			Resources: orig.Resources,
+		})
+		require.NoError(t, err, "update workspace drift check")
+		check.CheckedAt = orig.CheckedAt
+		check.Resources = orig.Resources
+	}
+	return check
+}
+
+func WorkspaceSessionRecording(t testing.TB, db database.Store, orig database.WorkspaceSessionRecording) database.WorkspaceSessionRecording {
+	recording := database.WorkspaceSessionRecording{
+		ID:          takeFirst(orig.ID, uuid.New()),
+		WorkspaceID: takeFirst(orig.WorkspaceID, uuid.New()),
+		AgentID:     takeFirst(orig.AgentID, uuid.New()),
+		Type:        takeFirst(orig.Type, database.SessionRecordingTypeSsh),
+		StartedAt:   takeFirst(orig.StartedAt, dbtime.Now()),
+		EndedAt:     orig.EndedAt,
+		CreatedAt:   takeFirst(orig.CreatedAt, dbtime.Now()),
+	}
+	err := db.UpsertWorkspaceSessionRecording(genCtx, database.UpsertWorkspaceSessionRecordingParams{
+		ID:          recording.ID,
+		WorkspaceID: recording.WorkspaceID,
+		AgentID:     recording.AgentID,
+		Type:        recording.Type,
+		StartedAt:   recording.StartedAt,
+		EndedAt:     recording.EndedAt,
+		CreatedAt:   recording.CreatedAt,
+	})
+	require.NoError(t, err, "upsert workspace session recording")
+	return recording
+}
+
 func WorkspaceResourceMetadatums(t testing.TB, db database.Store, seed database.WorkspaceResourceMetadatum) []database.WorkspaceResourceMetadatum {
 	meta, err := db.InsertWorkspaceResourceMetadata(genCtx, database.InsertWorkspaceResourceMetadataParams{
 		WorkspaceResourceID: takeFirst(seed.WorkspaceResourceID, uuid.New()),
@@ -888,6 +952,24 @@ func ExternalAuthLink(t testing.TB, db database.Store, orig database.ExternalAut
 	return link
 }
 
+func UserSecret(t testing.TB, db database.Store, orig database.UserSecret) database.UserSecret {
+	secret, err := db.InsertUserSecret(genCtx, database.InsertUserSecretParams{
+		ID:          takeFirst(orig.ID, uuid.New()),
+		UserID:      takeFirst(orig.UserID, uuid.New()),
+		Name:        takeFirst(orig.Name, testutil.GetRandomName(t)),
+		Description: orig.Description,
+		Value:       takeFirst(orig.Value, uuid.NewString()),
+		ValueKeyID:  takeFirst(orig.ValueKeyID, sql.NullString{}),
+		EnvName:     orig.EnvName,
+		FilePath:    orig.FilePath,
+		TemplateIDs: takeFirstSlice(orig.TemplateIDs, []uuid.UUID{}),
+		CreatedAt:   takeFirst(orig.CreatedAt, dbtime.Now()),
+		UpdatedAt:   takeFirst(orig.UpdatedAt, dbtime.Now()),
+	})
+	require.NoError(t, err, "insert user secret")
+	return secret
+}
+
 func TemplateVersion(t testing.TB, db database.Store, orig database.TemplateVersion) database.TemplateVersion {
 	var version database.TemplateVersion
 	err := db.InTx(func(db database.Store) error {
@@ -251,6 +251,7 @@ type data struct {
 	workspaceAgentLogs                   []database.WorkspaceAgentLog
 	workspaceAgentLogSources             []database.WorkspaceAgentLogSource
 	workspaceAgentPortShares             []database.WorkspaceAgentPortShare
+	workspaceAgentPTYShares              []database.WorkspaceAgentPTYShare
 	workspaceAgentScriptTimings          []database.WorkspaceAgentScriptTiming
 	workspaceAgentScripts                []database.WorkspaceAgentScript
 	workspaceAgentStats                  []database.WorkspaceAgentStat
@@ -267,6 +268,9 @@ type data struct {
 	workspaceResourceMetadata            []database.WorkspaceResourceMetadatum
 	workspaceResources                   []database.WorkspaceResource
 	workspaceModules                     []database.WorkspaceModule
+	workspaceDriftChecks                 []database.WorkspaceDriftCheck
+	workspaceSessionRecordings           []database.WorkspaceSessionRecording
+	workspaceSessionRecordingChunks      []database.WorkspaceSessionRecordingChunk
 	workspaces                           []database.WorkspaceTable
 	workspaceProxies                     []database.WorkspaceProxy
 	customRoles                          []database.CustomRole
@@ -292,6 +296,7 @@ type data struct {
 	defaultProxyIconURL              string
 	webpushVAPIDPublicKey            string
 	webpushVAPIDPrivateKey           string
+	userSecrets                      []database.UserSecret
 	userStatusChanges                []database.UserStatusChange
 	telemetryItems                   []database.TelemetryItem
 	presets                          []database.TemplateVersionPreset
@@ -463,6 +468,9 @@ func convertUsers(users []database.User, count int64) []database.GetUsersRow {
 			LastSeenAt:     u.LastSeenAt,
 			Count:          count,
 			IsSystem:       u.IsSystem,
+
+			IsServiceAccount:             u.IsServiceAccount,
+			ServiceAccountOrganizationID: u.ServiceAccountOrganizationID,
 		}
 	}
 
@@ -845,6 +853,34 @@ func (q *FakeQuerier) getWorkspaceResourcesByJobIDNoLock(_ context.Context, jobI
 	return resources, nil
 }
 
+// getWorkspaceSessionRecordingNoLock joins a session recording with its
+// workspace and owner. It returns false if either doesn't exist.
+func (q *FakeQuerier) getWorkspaceSessionRecordingNoLock(recording database.WorkspaceSessionRecording) (database.GetWorkspaceSessionRecordingsRow, bool) {
+	for _, workspace := range q.workspaces {
+		if workspace.ID != recording.WorkspaceID {
+			continue
+		}
+		owner, err := q.getUserByIDNoLock(workspace.OwnerID)
+		if err != nil {
+			return database.GetWorkspaceSessionRecordingsRow{}, false
+		}
+		return database.GetWorkspaceSessionRecordingsRow{
+			ID:                     recording.ID,
+			WorkspaceID:            recording.WorkspaceID,
+			AgentID:                recording.AgentID,
+			Type:                   recording.Type,
+			StartedAt:              recording.StartedAt,
+			EndedAt:                recording.EndedAt,
+			Size:                   recording.Size,
+			CreatedAt:              recording.CreatedAt,
+			WorkspaceName:          workspace.Name,
+			WorkspaceOwnerID:       workspace.OwnerID,
+			WorkspaceOwnerUsername: owner.Username,
+		}, true
+	}
+	return database.GetWorkspaceSessionRecordingsRow{}, false
+}
+
 func (q *FakeQuerier) getGroupByIDNoLock(_ context.Context, id uuid.UUID) (database.Group, error) {
 	for _, group := range q.groups {
 		if group.ID == id {
@@ -1186,6 +1222,13 @@ func provisionerTagsetContains(daemonTags, jobTags map[string]string) bool {
 	return true
 }
 
+// compareProvisionerJobPriority orders priorities the same way as the
+// provisioner_job_priority enum, which is declared from lowest to highest.
+func compareProvisionerJobPriority(a, b database.ProvisionerJobPriority) int {
+	values := database.AllProvisionerJobPriorityValues()
+	return slices.Index(values, a) - slices.Index(values, b)
+}
+
 // GetProvisionerJobsByIDsWithQueuePosition mimics the SQL logic in pure Go
 func (q *FakeQuerier) getProvisionerJobsByIDsWithQueuePositionLockedTagBasedQueue(_ context.Context, jobIDs []uuid.UUID) ([]database.GetProvisionerJobsByIDsWithQueuePositionRow, error) {
 	// Step 1: Filter provisionerJobs based on jobIDs
@@ -1227,10 +1270,14 @@ func (q *FakeQuerier) getProvisionerJobsByIDsWithQueuePositionLockedTagBasedQueu
 		}
 	}
 
-	// Sort jobs per provisioner by CreatedAt
+	// Sort jobs per provisioner by Priority and CreatedAt
 	for daemonID := range jobRanks {
 		sort.Slice(jobRanks[daemonID], func(i, j int) bool {
-			return jobRanks[daemonID][i].CreatedAt.Before(jobRanks[daemonID][j].CreatedAt)
+			a, b := jobRanks[daemonID][i], jobRanks[daemonID][j]
+			if a.Priority != b.Priority {
+				return compareProvisionerJobPriority(a.Priority, b.Priority) > 0
+			}
+			return a.CreatedAt.Before(b.CreatedAt)
 		})
 	}
 
@@ -1291,7 +1338,7 @@ func (q *FakeQuerier) getProvisionerJobsByIDsWithQueuePositionLockedTagBasedQueu
 func (q *FakeQuerier) getProvisionerJobsByIDsWithQueuePositionLockedGlobalQueue(_ context.Context, ids []uuid.UUID) ([]database.GetProvisionerJobsByIDsWithQueuePositionRow, error) {
 	//	WITH pending_jobs AS (
 	//		SELECT
-	//			id, created_at
+	//			id, created_at, priority
 	//		FROM
 	//			provisioner_jobs
 	//		WHERE
@@ -1306,6 +1353,7 @@ func (q *FakeQuerier) getProvisionerJobsByIDsWithQueuePositionLockedGlobalQueue(
 	type pendingJobRow struct {
 		ID        uuid.UUID
 		CreatedAt time.Time
+		Priority  database.ProvisionerJobPriority
 	}
 	pendingJobs := make([]pendingJobRow, 0)
 	for _, job := range q.provisionerJobs {
@@ -1318,17 +1366,21 @@ func (q *FakeQuerier) getProvisionerJobsByIDsWithQueuePositionLockedGlobalQueue(
 		pendingJobs = append(pendingJobs, pendingJobRow{
 			ID:        job.ID,
 			CreatedAt: job.CreatedAt,
+			Priority:  job.Priority,
 		})
 	}
 
 	//	queue_position AS (
 	//		SELECT
 	//			id,
-	//				ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+	//				ROW_NUMBER() OVER (ORDER BY priority DESC, created_at ASC) AS queue_position
 	//		FROM
 	//			pending_jobs
 	// 	),
 	slices.SortFunc(pendingJobs, func(a, b pendingJobRow) int {
+		if c := compareProvisionerJobPriority(b.Priority, a.Priority); c != 0 {
+			return c
+		}
 		c := a.CreatedAt.Compare(b.CreatedAt)
 		return c
 	})
@@ -1436,7 +1488,33 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	for index, provisionerJob := range q.provisionerJobs {
+	//	ORDER BY
+	//		potential_job.priority DESC,
+	//		(SELECT COUNT(*) ... running jobs of the initiator) ASC,
+	//		potential_job.created_at
+	running := make(map[uuid.UUID]int)
+	for _, job := range q.provisionerJobs {
+		if job.JobStatus == database.ProvisionerJobStatusRunning {
+			running[job.InitiatorID]++
+		}
+	}
+	order := make([]int, len(q.provisionerJobs))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		jobA, jobB := q.provisionerJobs[a], q.provisionerJobs[b]
+		if c := compareProvisionerJobPriority(jobB.Priority, jobA.Priority); c != 0 {
+			return c
+		}
+		if c := running[jobA.InitiatorID] - running[jobB.InitiatorID]; c != 0 {
+			return c
+		}
+		return jobA.CreatedAt.Compare(jobB.CreatedAt)
+	})
+
+	for _, index := range order {
+		provisionerJob := q.provisionerJobs[index]
 		if provisionerJob.OrganizationID != arg.OrganizationID {
 			continue
 		}
@@ -2348,6 +2426,42 @@ func (q *FakeQuerier) DeleteOldWorkspaceAgentStats(_ context.Context) error {
 	return nil
 }
 
+func (q *FakeQuerier) DeleteOldWorkspaceSessionRecordings(_ context.Context, before time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deleted := make(map[uuid.UUID]struct{})
+	recordings := q.workspaceSessionRecordings[:0]
+	for _, recording := range q.workspaceSessionRecordings {
+		var retention time.Duration
+		for _, workspace := range q.workspaces {
+			if workspace.ID != recording.WorkspaceID {
+				continue
+			}
+			for _, template := range q.templates {
+				if template.ID == workspace.TemplateID {
+					retention = time.Duration(template.SessionRecordingRetention)
+				}
+			}
+		}
+		if retention > 0 && recording.StartedAt.Before(before.Add(-retention)) {
+			deleted[recording.ID] = struct{}{}
+			continue
+		}
+		recordings = append(recordings, recording)
+	}
+	q.workspaceSessionRecordings = recordings
+
+	chunks := q.workspaceSessionRecordingChunks[:0]
+	for _, chunk := range q.workspaceSessionRecordingChunks {
+		if _, ok := deleted[chunk.RecordingID]; !ok {
+			chunks = append(chunks, chunk)
+		}
+	}
+	q.workspaceSessionRecordingChunks = chunks
+	return nil
+}
+
 func (q *FakeQuerier) DeleteOrganizationMember(ctx context.Context, arg database.DeleteOrganizationMemberParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -2444,6 +2558,20 @@ func (*FakeQuerier) DeleteTailnetTunnel(_ context.Context, arg database.DeleteTa
 	return database.DeleteTailnetTunnelRow{}, ErrUnimplemented
 }
 
+func (q *FakeQuerier) DeleteUserSecret(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, secret := range q.userSecrets {
+		if secret.ID != id {
+			continue
+		}
+		q.userSecrets = append(q.userSecrets[:i], q.userSecrets[i+1:]...)
+		return nil
+	}
+	return nil
+}
+
 func (q *FakeQuerier) DeleteWebpushSubscriptionByUserIDAndEndpoint(_ context.Context, arg database.DeleteWebpushSubscriptionByUserIDAndEndpointParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -2476,6 +2604,25 @@ func (q *FakeQuerier) DeleteWebpushSubscriptions(_ context.Context, ids []uuid.U
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) DeleteWorkspaceAgentPTYShare(_ context.Context, arg database.DeleteWorkspaceAgentPTYShareParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, share := range q.workspaceAgentPTYShares {
+		if share.AgentID == arg.AgentID && share.ReconnectID == arg.ReconnectID && share.UserID == arg.UserID {
+			q.workspaceAgentPTYShares = append(q.workspaceAgentPTYShares[:i], q.workspaceAgentPTYShares[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
 func (q *FakeQuerier) DeleteWorkspaceAgentPortShare(_ context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -2736,6 +2883,10 @@ func (q *FakeQuerier) GetActiveUserCount(_ context.Context, includeSystem bool)
 		if !includeSystem && u.IsSystem {
 			continue
 		}
+		// Service accounts don't consume seats.
+		if u.IsServiceAccount {
+			continue
+		}
 
 		if u.Status == database.UserStatusActive && !u.Deleted {
 			active++
@@ -3803,6 +3954,28 @@ func (q *FakeQuerier) GetLatestWorkspaceBuildsByWorkspaceIDs(_ context.Context,
 	return returnBuilds, nil
 }
 
+func (q *FakeQuerier) GetLatestWorkspaceDriftChecksByWorkspaceIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceDriftCheck, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	latestByWorkspace := make(map[uuid.UUID]database.WorkspaceDriftCheck)
+	for _, check := range q.workspaceDriftChecks {
+		if !check.CheckedAt.Valid || !slices.Contains(ids, check.WorkspaceID) {
+			continue
+		}
+		current, exists := latestByWorkspace[check.WorkspaceID]
+		if !exists || check.CheckedAt.Time.After(current.CheckedAt.Time) {
+			latestByWorkspace[check.WorkspaceID] = check
+		}
+	}
+
+	checks := make([]database.WorkspaceDriftCheck, 0, len(latestByWorkspace))
+	for _, check := range latestByWorkspace {
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
 func (q *FakeQuerier) GetLicenseByID(_ context.Context, id int32) (database.License, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -4212,6 +4385,38 @@ func (q *FakeQuerier) GetParameterSchemasByJobID(_ context.Context, jobID uuid.U
 	return parameters, nil
 }
 
+func (q *FakeQuerier) GetPendingProvisionerJobCounts(_ context.Context) ([]database.GetPendingProvisionerJobCountsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetPendingProvisionerJobCountsRow, 0)
+	for _, job := range q.provisionerJobs {
+		if job.JobStatus != database.ProvisionerJobStatusPending {
+			continue
+		}
+		org, err := q.getOrganizationByIDNoLock(job.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(rows, func(row database.GetPendingProvisionerJobCountsRow) bool {
+			return row.OrganizationName == org.Name &&
+				row.Provisioner == job.Provisioner &&
+				tagsEqual(row.Tags, job.Tags)
+		})
+		if idx >= 0 {
+			rows[idx].Count++
+			continue
+		}
+		rows = append(rows, database.GetPendingProvisionerJobCountsRow{
+			OrganizationName: org.Name,
+			Provisioner:      job.Provisioner,
+			Tags:             maps.Clone(job.Tags),
+			Count:            1,
+		})
+	}
+	return rows, nil
+}
+
 func (*FakeQuerier) GetPrebuildMetrics(_ context.Context) ([]database.GetPrebuildMetricsRow, error) {
 	return nil, ErrUnimplemented
 }
@@ -4374,6 +4579,20 @@ func (q *FakeQuerier) GetPreviousTemplateVersion(_ context.Context, arg database
 	return previousTemplateVersions[0], nil
 }
 
+func (q *FakeQuerier) GetProvisionerDaemonByID(_ context.Context, id uuid.UUID) (database.ProvisionerDaemon, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, daemon := range q.provisionerDaemons {
+		if daemon.ID != id {
+			continue
+		}
+		daemon.Tags = maps.Clone(daemon.Tags)
+		return daemon, nil
+	}
+	return database.ProvisionerDaemon{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetProvisionerDaemons(_ context.Context) ([]database.ProvisionerDaemon, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -4436,6 +4655,9 @@ func (q *FakeQuerier) GetProvisionerDaemonsWithStatusByOrganization(ctx context.
 		if len(arg.IDs) > 0 && !slices.Contains(arg.IDs, daemon.ID) {
 			continue
 		}
+		if arg.Name != "" && daemon.Name != arg.Name {
+			continue
+		}
 
 		if len(arg.Tags) > 0 {
 			// Special case for untagged provisioners: only match untagged jobs.
@@ -6616,6 +6838,50 @@ func (q *FakeQuerier) GetUserNotificationPreferences(_ context.Context, userID u
 	return out, nil
 }
 
+func (q *FakeQuerier) GetUserSecretByID(_ context.Context, id uuid.UUID) (database.UserSecret, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, secret := range q.userSecrets {
+		if secret.ID == id {
+			return secret, nil
+		}
+	}
+	return database.UserSecret{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetUserSecretByUserIDAndName(_ context.Context, arg database.GetUserSecretByUserIDAndNameParams) (database.UserSecret, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserSecret{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, secret := range q.userSecrets {
+		if secret.UserID == arg.UserID && secret.Name == arg.Name {
+			return secret, nil
+		}
+	}
+	return database.UserSecret{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetUserSecrets(_ context.Context, userID uuid.UUID) ([]database.UserSecret, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	secrets := make([]database.UserSecret, 0)
+	for _, secret := range q.userSecrets {
+		if secret.UserID == userID {
+			secrets = append(secrets, secret)
+		}
+	}
+	slices.SortFunc(secrets, func(a, b database.UserSecret) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return secrets, nil
+}
+
 func (q *FakeQuerier) GetUserStatusCounts(_ context.Context, arg database.GetUserStatusCountsParams) ([]database.GetUserStatusCountsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -7120,6 +7386,24 @@ func (q *FakeQuerier) GetWorkspaceAgentMetadata(_ context.Context, arg database.
 	return metadata, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceAgentPTYShare(_ context.Context, arg database.GetWorkspaceAgentPTYShareParams) (database.WorkspaceAgentPTYShare, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.WorkspaceAgentPTYShare{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, share := range q.workspaceAgentPTYShares {
+		if share.AgentID == arg.AgentID && share.ReconnectID == arg.ReconnectID && share.UserID == arg.UserID {
+			return share, nil
+		}
+	}
+
+	return database.WorkspaceAgentPTYShare{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetWorkspaceAgentPortShare(_ context.Context, arg database.GetWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -7959,6 +8243,18 @@ func (q *FakeQuerier) GetWorkspaceByWorkspaceAppID(_ context.Context, workspaceA
 	return database.Workspace{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceDriftCheckByJobID(_ context.Context, jobID uuid.UUID) (database.WorkspaceDriftCheck, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, check := range q.workspaceDriftChecks {
+		if check.JobID == jobID {
+			return check, nil
+		}
+	}
+	return database.WorkspaceDriftCheck{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetWorkspaceModulesByJobID(_ context.Context, jobID uuid.UUID) ([]database.WorkspaceModule, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -8149,6 +8445,74 @@ func (q *FakeQuerier) GetWorkspaceResourcesCreatedAfter(_ context.Context, after
 	return resources, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceSessionRecordingByID(_ context.Context, id uuid.UUID) (database.GetWorkspaceSessionRecordingByIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, recording := range q.workspaceSessionRecordings {
+		if recording.ID != id {
+			continue
+		}
+		row, ok := q.getWorkspaceSessionRecordingNoLock(recording)
+		if !ok {
+			return database.GetWorkspaceSessionRecordingByIDRow{}, sql.ErrNoRows
+		}
+		return database.GetWorkspaceSessionRecordingByIDRow(row), nil
+	}
+	return database.GetWorkspaceSessionRecordingByIDRow{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetWorkspaceSessionRecordingChunks(_ context.Context, recordingID uuid.UUID) ([]database.WorkspaceSessionRecordingChunk, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var chunks []database.WorkspaceSessionRecordingChunk
+	for _, chunk := range q.workspaceSessionRecordingChunks {
+		if chunk.RecordingID == recordingID {
+			chunks = append(chunks, chunk)
+		}
+	}
+	slices.SortFunc(chunks, func(a, b database.WorkspaceSessionRecordingChunk) int {
+		return slice.Ascending(a.ChunkIndex, b.ChunkIndex)
+	})
+	return chunks, nil
+}
+
+func (q *FakeQuerier) GetWorkspaceSessionRecordings(_ context.Context, arg database.GetWorkspaceSessionRecordingsParams) ([]database.GetWorkspaceSessionRecordingsRow, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetWorkspaceSessionRecordingsRow, 0)
+	for _, recording := range q.workspaceSessionRecordings {
+		if arg.WorkspaceID != uuid.Nil && recording.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		row, ok := q.getWorkspaceSessionRecordingNoLock(recording)
+		if !ok {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(a, b database.GetWorkspaceSessionRecordingsRow) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+
+	if arg.OffsetOpt > 0 {
+		if int(arg.OffsetOpt) > len(rows) {
+			return []database.GetWorkspaceSessionRecordingsRow{}, nil
+		}
+		rows = rows[arg.OffsetOpt:]
+	}
+	if arg.LimitOpt > 0 && int(arg.LimitOpt) < len(rows) {
+		rows = rows[:arg.LimitOpt]
+	}
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceUniqueOwnerCountByTemplateIDs(_ context.Context, templateIds []uuid.UUID) ([]database.GetWorkspaceUniqueOwnerCountByTemplateIDsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -8208,6 +8572,92 @@ func (q *FakeQuerier) GetWorkspacesByTemplateID(_ context.Context, templateID uu
 	return workspaces, nil
 }
 
+func (q *FakeQuerier) GetWorkspacesDueDriftCheck(ctx context.Context, arg database.GetWorkspacesDueDriftCheckParams) ([]database.GetWorkspacesDueDriftCheckRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	type dueRow struct {
+		row         database.GetWorkspacesDueDriftCheckRow
+		lastCheck   time.Time
+		completedAt time.Time
+	}
+	now := arg.Now
+	due := []dueRow{}
+	for _, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.DormantAt.Valid || workspace.OwnerID == prebuilds.SystemUserID {
+			continue
+		}
+		template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
+		if err != nil {
+			return nil, xerrors.Errorf("get template by ID: %w", err)
+		}
+		if template.Deleted || template.DriftDetectionInterval <= 0 {
+			continue
+		}
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if err != nil {
+			return nil, xerrors.Errorf("get workspace build by ID: %w", err)
+		}
+		if build.Transition != database.WorkspaceTransitionStart {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			return nil, xerrors.Errorf("get provisioner job by ID: %w", err)
+		}
+		since := now.Add(-time.Duration(template.DriftDetectionInterval))
+		if job.JobStatus != database.ProvisionerJobStatusSucceeded || !job.CompletedAt.Time.Before(since) {
+			continue
+		}
+		requested := slices.ContainsFunc(q.workspaceDriftChecks, func(check database.WorkspaceDriftCheck) bool {
+			return check.WorkspaceID == workspace.ID && check.CreatedAt.After(since)
+		})
+		if requested {
+			continue
+		}
+		var lastCheck time.Time
+		for _, check := range q.workspaceDriftChecks {
+			if check.WorkspaceID == workspace.ID && check.CreatedAt.After(lastCheck) {
+				lastCheck = check.CreatedAt
+			}
+		}
+		due = append(due, dueRow{
+			row: database.GetWorkspacesDueDriftCheckRow{
+				WorkspaceID:      workspace.ID,
+				OwnerID:          workspace.OwnerID,
+				WorkspaceBuildID: build.ID,
+			},
+			lastCheck:   lastCheck,
+			completedAt: job.CompletedAt.Time,
+		})
+	}
+
+	// Workspaces that were never checked have a zero lastCheck, so they
+	// come first like NULLS FIRST.
+	slices.SortFunc(due, func(a, b dueRow) int {
+		if c := a.lastCheck.Compare(b.lastCheck); c != 0 {
+			return c
+		}
+		if c := a.completedAt.Compare(b.completedAt); c != 0 {
+			return c
+		}
+		return slice.Ascending(a.row.WorkspaceID.String(), b.row.WorkspaceID.String())
+	})
+	rows := []database.GetWorkspacesDueDriftCheckRow{}
+	for _, d := range due {
+		if len(rows) >= int(arg.LimitCount) {
+			break
+		}
+		rows = append(rows, d.row)
+	}
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.GetWorkspacesEligibleForTransitionRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -8346,6 +8796,10 @@ func (q *FakeQuerier) InsertAPIKey(_ context.Context, arg database.InsertAPIKeyP
 		LoginType:       arg.LoginType,
 		Scope:           arg.Scope,
 		TokenName:       arg.TokenName,
+		AllowList:       arg.AllowList,
+	}
+	if key.AllowList == nil {
+		key.AllowList = []string{}
 	}
 	q.apiKeys = append(q.apiKeys, key)
 	return key, nil
@@ -8481,6 +8935,11 @@ func (q *FakeQuerier) InsertExternalAuthLink(_ context.Context, arg database.Ins
 
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
+	for _, link := range q.externalAuthLinks {
+		if link.ProviderID == arg.ProviderID && link.UserID == arg.UserID {
+			return database.ExternalAuthLink{}, newUniqueConstraintError(database.UniqueGitAuthLinksProviderIDUserIDKey)
+		}
+	}
 	// nolint:gosimple
 	gitAuthLink := database.ExternalAuthLink{
 		ProviderID:             arg.ProviderID,
@@ -8941,6 +9400,7 @@ func (q *FakeQuerier) InsertProvisionerJob(_ context.Context, arg database.Inser
 		Input:          arg.Input,
 		Tags:           maps.Clone(arg.Tags),
 		TraceMetadata:  arg.TraceMetadata,
+		Priority:       arg.Priority,
 	}
 	job.JobStatus = provisionerJobStatus(job)
 	q.provisionerJobs = append(q.provisionerJobs, job)
@@ -9278,6 +9738,9 @@ func (q *FakeQuerier) InsertUser(_ context.Context, arg database.InsertUserParam
 		RBACRoles:      arg.RBACRoles,
 		LoginType:      arg.LoginType,
 		IsSystem:       false,
+
+		IsServiceAccount:             arg.IsServiceAccount,
+		ServiceAccountOrganizationID: arg.ServiceAccountOrganizationID,
 	}
 	q.users = append(q.users, user)
 	sort.Slice(q.users, func(i, j int) bool {
@@ -9371,6 +9834,41 @@ func (q *FakeQuerier) InsertUserLink(_ context.Context, args database.InsertUser
 	return link, nil
 }
 
+func (q *FakeQuerier) InsertUserSecret(_ context.Context, arg database.InsertUserSecretParams) (database.UserSecret, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserSecret{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, secret := range q.userSecrets {
+		if secret.UserID == arg.UserID && secret.Name == arg.Name {
+			return database.UserSecret{}, newUniqueConstraintError(database.UniqueUserSecretsUserIDNameKey)
+		}
+	}
+
+	//nolint:gosimple
+	secret := database.UserSecret{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		Name:        arg.Name,
+		Description: arg.Description,
+		Value:       arg.Value,
+		ValueKeyID:  arg.ValueKeyID,
+		EnvName:     arg.EnvName,
+		FilePath:    arg.FilePath,
+		TemplateIDs: arg.TemplateIDs,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+	}
+	if secret.TemplateIDs == nil {
+		secret.TemplateIDs = []uuid.UUID{}
+	}
+	q.userSecrets = append(q.userSecrets, secret)
+	return secret, nil
+}
+
 func (q *FakeQuerier) InsertVolumeResourceMonitor(_ context.Context, arg database.InsertVolumeResourceMonitorParams) (database.WorkspaceAgentVolumeResourceMonitor, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -9833,6 +10331,31 @@ func (q *FakeQuerier) InsertWorkspaceBuildParameters(_ context.Context, arg data
 	return nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceDriftCheck(_ context.Context, arg database.InsertWorkspaceDriftCheckParams) (database.WorkspaceDriftCheck, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.WorkspaceDriftCheck{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, check := range q.workspaceDriftChecks {
+		if check.JobID == arg.JobID {
+			return database.WorkspaceDriftCheck{}, errUniqueConstraint
+		}
+	}
+	check := database.WorkspaceDriftCheck{
+		JobID:            arg.JobID,
+		WorkspaceID:      arg.WorkspaceID,
+		WorkspaceBuildID: arg.WorkspaceBuildID,
+		CreatedAt:        arg.CreatedAt,
+		Resources:        database.WorkspaceDriftResources{},
+	}
+	q.workspaceDriftChecks = append(q.workspaceDriftChecks, check)
+	return check, nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceModule(_ context.Context, arg database.InsertWorkspaceModuleParams) (database.WorkspaceModule, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -9934,6 +10457,37 @@ func (q *FakeQuerier) InsertWorkspaceResourceMetadata(_ context.Context, arg dat
 	return metadata, nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceSessionRecordingChunk(_ context.Context, arg database.InsertWorkspaceSessionRecordingChunkParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, chunk := range q.workspaceSessionRecordingChunks {
+		if chunk.RecordingID == arg.RecordingID && chunk.ChunkIndex == arg.ChunkIndex {
+			return nil
+		}
+	}
+	for i, recording := range q.workspaceSessionRecordings {
+		if recording.ID != arg.RecordingID {
+			continue
+		}
+		if recording.AgentID != arg.AgentID {
+			return nil
+		}
+		q.workspaceSessionRecordingChunks = append(q.workspaceSessionRecordingChunks, database.WorkspaceSessionRecordingChunk{
+			RecordingID: arg.RecordingID,
+			ChunkIndex:  arg.ChunkIndex,
+			Data:        slices.Clone(arg.Data),
+		})
+		q.workspaceSessionRecordings[i].Size += int64(len(arg.Data))
+		return nil
+	}
+	return nil
+}
+
 func (q *FakeQuerier) ListProvisionerKeysByOrganization(_ context.Context, organizationID uuid.UUID) ([]database.ProvisionerKey, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -9967,6 +10521,23 @@ func (q *FakeQuerier) ListProvisionerKeysByOrganizationExcludeReserved(_ context
 	return keys, nil
 }
 
+func (q *FakeQuerier) ListWorkspaceAgentPTYShares(_ context.Context, agentID uuid.UUID) ([]database.WorkspaceAgentPTYShare, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	shares := []database.WorkspaceAgentPTYShare{}
+	for _, share := range q.workspaceAgentPTYShares {
+		if share.AgentID == agentID {
+			shares = append(shares, share)
+		}
+	}
+	slices.SortFunc(shares, func(a, b database.WorkspaceAgentPTYShare) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return shares, nil
+}
+
 func (q *FakeQuerier) ListWorkspaceAgentPortShares(_ context.Context, workspaceID uuid.UUID) ([]database.WorkspaceAgentPortShare, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -10470,6 +11041,7 @@ func (q *FakeQuerier) UpdateGroupByID(_ context.Context, arg database.UpdateGrou
 			group.Name = arg.Name
 			group.AvatarURL = arg.AvatarURL
 			group.QuotaAllowance = arg.QuotaAllowance
+			group.DLPPolicy = arg.DLPPolicy
 			q.groups[i] = group
 			return group, nil
 		}
@@ -10483,7 +11055,7 @@ func (q *FakeQuerier) UpdateInactiveUsersToDormant(_ context.Context, params dat
 
 	var updated []database.UpdateInactiveUsersToDormantRow
 	for index, user := range q.users {
-		if user.Status == database.UserStatusActive && user.LastSeenAt.Before(params.LastSeenAfter) && !user.IsSystem {
+		if user.Status == database.UserStatusActive && user.LastSeenAt.Before(params.LastSeenAfter) && !user.IsSystem && !user.IsServiceAccount {
 			q.users[index].Status = database.UserStatusDormant
 			q.users[index].UpdatedAt = params.UpdatedAt
 			updated = append(updated, database.UpdateInactiveUsersToDormantRow{
@@ -10694,6 +11266,25 @@ func (q *FakeQuerier) UpdateOrganizationDeletedByID(_ context.Context, arg datab
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateProvisionerDaemonDrainRequestedAt(_ context.Context, arg database.UpdateProvisionerDaemonDrainRequestedAtParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx := range q.provisionerDaemons {
+		if q.provisionerDaemons[idx].ID != arg.ID {
+			continue
+		}
+		q.provisionerDaemons[idx].DrainRequestedAt = arg.DrainRequestedAt
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateProvisionerDaemonLastSeenAt(_ context.Context, arg database.UpdateProvisionerDaemonLastSeenAtParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -10913,6 +11504,10 @@ func (q *FakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.Upd
 		tpl.GroupACL = arg.GroupACL
 		tpl.AllowUserCancelWorkspaceJobs = arg.AllowUserCancelWorkspaceJobs
 		tpl.MaxPortSharingLevel = arg.MaxPortSharingLevel
+		tpl.DriftDetectionInterval = arg.DriftDetectionInterval
+		tpl.SessionRecordingEnabled = arg.SessionRecordingEnabled
+		tpl.SessionRecordingRetention = arg.SessionRecordingRetention
+		tpl.DLPPolicy = arg.DLPPolicy
 		q.templates[idx] = tpl
 		return nil
 	}
@@ -11193,7 +11788,7 @@ func (q *FakeQuerier) UpdateUserLoginType(_ context.Context, arg database.Update
 	defer q.mutex.Unlock()
 
 	for i, u := range q.users {
-		if u.ID == arg.UserID {
+		if u.ID == arg.UserID && !u.IsSystem && !u.IsServiceAccount {
 			u.LoginType = arg.NewLoginType
 			if arg.NewLoginType != database.LoginTypePassword {
 				u.HashedPassword = []byte{}
@@ -11331,6 +11926,34 @@ func (q *FakeQuerier) UpdateUserRoles(_ context.Context, arg database.UpdateUser
 	return database.User{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateUserSecret(_ context.Context, arg database.UpdateUserSecretParams) (database.UserSecret, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserSecret{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, secret := range q.userSecrets {
+		if secret.ID != arg.ID {
+			continue
+		}
+		secret.Description = arg.Description
+		secret.Value = arg.Value
+		secret.ValueKeyID = arg.ValueKeyID
+		secret.EnvName = arg.EnvName
+		secret.FilePath = arg.FilePath
+		secret.TemplateIDs = arg.TemplateIDs
+		if secret.TemplateIDs == nil {
+			secret.TemplateIDs = []uuid.UUID{}
+		}
+		secret.UpdatedAt = arg.UpdatedAt
+		q.userSecrets[i] = secret
+		return secret, nil
+	}
+	return database.UserSecret{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateUserStatus(_ context.Context, arg database.UpdateUserStatusParams) (database.User, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.User{}, err
@@ -11767,6 +12390,27 @@ func (q *FakeQuerier) UpdateWorkspaceDormantDeletingAt(_ context.Context, arg da
 	return database.WorkspaceTable{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateWorkspaceDriftCheckByJobID(_ context.Context, arg database.UpdateWorkspaceDriftCheckByJobIDParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, check := range q.workspaceDriftChecks {
+		if check.JobID != arg.JobID {
+			continue
+		}
+		check.CheckedAt = arg.CheckedAt
+		check.Resources = arg.Resources
+		q.workspaceDriftChecks[i] = check
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateWorkspaceLastUsedAt(_ context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -12056,21 +12700,24 @@ func (q *FakeQuerier) UpsertProvisionerDaemon(_ context.Context, arg database.Up
 			d.APIVersion = arg.APIVersion
 			d.OrganizationID = arg.OrganizationID
 			d.KeyID = arg.KeyID
+			d.TerraformEngine = arg.TerraformEngine
+			d.DrainRequestedAt = sql.NullTime{}
 			q.provisionerDaemons[i] = d
 			return d, nil
 		}
 	}
 	d := database.ProvisionerDaemon{
-		ID:             uuid.New(),
-		CreatedAt:      arg.CreatedAt,
-		Name:           arg.Name,
-		Provisioners:   arg.Provisioners,
-		Tags:           maps.Clone(arg.Tags),
-		LastSeenAt:     arg.LastSeenAt,
-		Version:        arg.Version,
-		APIVersion:     arg.APIVersion,
-		OrganizationID: arg.OrganizationID,
-		KeyID:          arg.KeyID,
+		ID:              uuid.New(),
+		CreatedAt:       arg.CreatedAt,
+		Name:            arg.Name,
+		Provisioners:    arg.Provisioners,
+		Tags:            maps.Clone(arg.Tags),
+		LastSeenAt:      arg.LastSeenAt,
+		Version:         arg.Version,
+		APIVersion:      arg.APIVersion,
+		OrganizationID:  arg.OrganizationID,
+		KeyID:           arg.KeyID,
+		TerraformEngine: arg.TerraformEngine,
 	}
 	q.provisionerDaemons = append(q.provisionerDaemons, d)
 	return d, nil
@@ -12762,6 +13409,38 @@ func (q *FakeQuerier) UpsertWebpushVAPIDKeys(_ context.Context, arg database.Ups
 	return nil
 }
 
+func (q *FakeQuerier) UpsertWorkspaceAgentPTYShare(_ context.Context, arg database.UpsertWorkspaceAgentPTYShareParams) (database.WorkspaceAgentPTYShare, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.WorkspaceAgentPTYShare{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, share := range q.workspaceAgentPTYShares {
+		if share.AgentID == arg.AgentID && share.ReconnectID == arg.ReconnectID && share.UserID == arg.UserID {
+			share.Mode = arg.Mode
+			q.workspaceAgentPTYShares[i] = share
+			return share, nil
+		}
+	}
+
+	//nolint:gosimple // casts are not a simplification
+	share := database.WorkspaceAgentPTYShare{
+		WorkspaceID: arg.WorkspaceID,
+		AgentID:     arg.AgentID,
+		ReconnectID: arg.ReconnectID,
+		UserID:      arg.UserID,
+		Mode:        arg.Mode,
+		CreatedBy:   arg.CreatedBy,
+		CreatedAt:   arg.CreatedAt,
+	}
+	q.workspaceAgentPTYShares = append(q.workspaceAgentPTYShares, share)
+
+	return share, nil
+}
+
 func (q *FakeQuerier) UpsertWorkspaceAgentPortShare(_ context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -12851,6 +13530,35 @@ func (q *FakeQuerier) UpsertWorkspaceAppAuditSession(_ context.Context, arg data
 	return true, nil
 }
 
+func (q *FakeQuerier) UpsertWorkspaceSessionRecording(_ context.Context, arg database.UpsertWorkspaceSessionRecordingParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, recording := range q.workspaceSessionRecordings {
+		if recording.ID != arg.ID {
+			continue
+		}
+		if recording.AgentID == arg.AgentID && arg.EndedAt.Valid {
+			q.workspaceSessionRecordings[i].EndedAt = arg.EndedAt
+		}
+		return nil
+	}
+	q.workspaceSessionRecordings = append(q.workspaceSessionRecordings, database.WorkspaceSessionRecording{
+		ID:          arg.ID,
+		WorkspaceID: arg.WorkspaceID,
+		AgentID:     arg.AgentID,
+		Type:        arg.Type,
+		StartedAt:   arg.StartedAt,
+		EndedAt:     arg.EndedAt,
+		CreatedAt:   arg.CreatedAt,
+	})
+	return nil
+}
+
 func (q *FakeQuerier) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, prepared rbac.PreparedAuthorized) ([]database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -13520,6 +14228,7 @@ func (q *FakeQuerier) GetAuthorizedAuditLogsOffset(ctx context.Context, arg data
 			UserLoginType:           database.NullLoginType{LoginType: user.LoginType, Valid: userValid},
 			UserDeleted:             sql.NullBool{Bool: user.Deleted, Valid: userValid},
 			UserQuietHoursSchedule:  sql.NullString{String: user.QuietHoursSchedule, Valid: userValid},
+			UserIsServiceAccount:    sql.NullBool{Bool: user.IsServiceAccount, Valid: userValid},
 			UserStatus:              database.NullUserStatus{UserStatus: user.Status, Valid: userValid},
 			UserRoles:               user.RBACRoles,
 			Count:                   0,
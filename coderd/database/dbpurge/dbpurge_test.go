@@ -388,6 +388,57 @@ func mustCreateAgentLogs(ctx context.Context, t *testing.T, db database.Store, a
 }
 
 //nolint:paralleltest // It uses LockIDDBPurge.
+func TestDeleteOldWorkspaceSessionRecordings(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	clk := quartz.NewMock(t)
+	now := dbtime.Now()
+	clk.Set(now).MustWait(ctx)
+
+	db, _ := dbtestutil.NewDB(t, dbtestutil.WithDumpOnFailure())
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	tv := dbgen.TemplateVersion(t, db, database.TemplateVersion{OrganizationID: org.ID, CreatedBy: user.ID})
+	logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+
+	// Template A keeps recordings for a day, template B keeps them forever.
+	tmplA := dbgen.Template(t, db, database.Template{OrganizationID: org.ID, ActiveVersionID: tv.ID, CreatedBy: user.ID})
+	err := db.UpdateTemplateMetaByID(ctx, database.UpdateTemplateMetaByIDParams{
+		ID:                        tmplA.ID,
+		UpdatedAt:                 now,
+		Name:                      tmplA.Name,
+		GroupACL:                  tmplA.GroupACL,
+		MaxPortSharingLevel:       tmplA.MaxPortSharingLevel,
+		SessionRecordingEnabled:   true,
+		SessionRecordingRetention: int64(24 * time.Hour),
+	})
+	require.NoError(t, err)
+	tmplB := dbgen.Template(t, db, database.Template{OrganizationID: org.ID, ActiveVersionID: tv.ID, CreatedBy: user.ID})
+
+	wsA := dbgen.Workspace(t, db, database.WorkspaceTable{Name: "a", OwnerID: user.ID, OrganizationID: org.ID, TemplateID: tmplA.ID})
+	wsB := dbgen.Workspace(t, db, database.WorkspaceTable{Name: "b", OwnerID: user.ID, OrganizationID: org.ID, TemplateID: tmplB.ID})
+	agentA := mustCreateAgent(t, db, mustCreateWorkspaceBuild(t, db, org, tv, wsA.ID, now, 1))
+	agentB := mustCreateAgent(t, db, mustCreateWorkspaceBuild(t, db, org, tv, wsB.ID, now, 1))
+	expired := dbgen.WorkspaceSessionRecording(t, db, database.WorkspaceSessionRecording{WorkspaceID: wsA.ID, AgentID: agentA.ID, StartedAt: now.Add(-25 * time.Hour)})
+	recent := dbgen.WorkspaceSessionRecording(t, db, database.WorkspaceSessionRecording{WorkspaceID: wsA.ID, AgentID: agentA.ID, StartedAt: now.Add(-23 * time.Hour)})
+	forever := dbgen.WorkspaceSessionRecording(t, db, database.WorkspaceSessionRecording{WorkspaceID: wsB.ID, AgentID: agentB.ID, StartedAt: now.Add(-365 * 24 * time.Hour)})
+
+	// when
+	done := awaitDoTick(ctx, t, clk)
+	closer := dbpurge.New(ctx, logger, db, clk)
+	defer closer.Close()
+	<-done // doTick() has now run.
+
+	// then
+	_, err = db.GetWorkspaceSessionRecordingByID(ctx, expired.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = db.GetWorkspaceSessionRecordingByID(ctx, recent.ID)
+	require.NoError(t, err)
+	_, err = db.GetWorkspaceSessionRecordingByID(ctx, forever.ID)
+	require.NoError(t, err)
+}
+
 func TestDeleteOldProvisionerDaemons(t *testing.T) {
 	// TODO: must refactor DeleteOldProvisionerDaemons to allow passing in cutoff
 	//       before using quartz.NewMock
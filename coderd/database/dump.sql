@@ -7,7 +7,13 @@ CREATE TYPE agent_id_name_pair AS (
 
 CREATE TYPE api_key_scope AS ENUM (
     'all',
-    'application_connect'
+    'application_connect',
+    'workspace:read',
+    'workspace:start',
+    'workspace:stop',
+    'workspace:ssh',
+    'template:read',
+    'template:push'
 );
 
 CREATE TYPE app_sharing_level AS ENUM (
@@ -29,7 +35,8 @@ CREATE TYPE audit_action AS ENUM (
     'connect',
     'disconnect',
     'open',
-    'close'
+    'close',
+    'blocked'
 );
 
 CREATE TYPE automatic_updates AS ENUM (
@@ -156,6 +163,15 @@ CREATE TYPE provisioner_daemon_status AS ENUM (
 
 COMMENT ON TYPE provisioner_daemon_status IS 'The status of a provisioner daemon.';
 
+CREATE TYPE provisioner_job_priority AS ENUM (
+    'background',
+    'bulk',
+    'autobuild',
+    'interactive'
+);
+
+COMMENT ON TYPE provisioner_job_priority IS 'Priority of a provisioner job. Jobs with a higher priority are acquired before jobs with a lower priority, regardless of age.';
+
 CREATE TYPE provisioner_job_status AS ENUM (
     'pending',
     'running',
@@ -178,7 +194,8 @@ CREATE TYPE provisioner_job_timing_stage AS ENUM (
 CREATE TYPE provisioner_job_type AS ENUM (
     'template_version_import',
     'workspace_build',
-    'template_version_dry_run'
+    'template_version_dry_run',
+    'workspace_drift_check'
 );
 
 CREATE TYPE provisioner_storage_method AS ENUM (
@@ -187,7 +204,13 @@ CREATE TYPE provisioner_storage_method AS ENUM (
 
 CREATE TYPE provisioner_type AS ENUM (
     'echo',
-    'terraform'
+    'terraform',
+    'pulumi'
+);
+
+CREATE TYPE pty_share_mode AS ENUM (
+    'read_only',
+    'read_write'
 );
 
 CREATE TYPE resource_type AS ENUM (
@@ -217,6 +240,11 @@ CREATE TYPE resource_type AS ENUM (
     'workspace_app'
 );
 
+CREATE TYPE session_recording_type AS ENUM (
+    'ssh',
+    'reconnecting_pty'
+);
+
 CREATE TYPE startup_script_behavior AS ENUM (
     'blocking',
     'non-blocking'
@@ -727,11 +755,14 @@ CREATE TABLE api_keys (
     lifetime_seconds bigint DEFAULT 86400 NOT NULL,
     ip_address inet DEFAULT '0.0.0.0'::inet NOT NULL,
     scope api_key_scope DEFAULT 'all'::api_key_scope NOT NULL,
-    token_name text DEFAULT ''::text NOT NULL
+    token_name text DEFAULT ''::text NOT NULL,
+    allow_list text[] DEFAULT '{}'::text[] NOT NULL
 );
 
 COMMENT ON COLUMN api_keys.hashed_secret IS 'hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.';
 
+COMMENT ON COLUMN api_keys.allow_list IS 'allow_list contains the IDs of the only resources the key can access. If empty, the key can access every resource allowed by its scope.';
+
 CREATE TABLE audit_logs (
     id uuid NOT NULL,
     "time" timestamp with time zone NOT NULL,
@@ -846,13 +877,16 @@ CREATE TABLE groups (
     avatar_url text DEFAULT ''::text NOT NULL,
     quota_allowance integer DEFAULT 0 NOT NULL,
     display_name text DEFAULT ''::text NOT NULL,
-    source group_source DEFAULT 'user'::group_source NOT NULL
+    source group_source DEFAULT 'user'::group_source NOT NULL,
+    dlp_policy jsonb DEFAULT '{}'::jsonb NOT NULL
 );
 
 COMMENT ON COLUMN groups.display_name IS 'Display name is a custom, human-friendly group name that user can set. This is not required to be unique and can be the empty string.';
 
 COMMENT ON COLUMN groups.source IS 'Source indicates how the group was created. It can be created by a user manually, or through some system process like OIDC group sync.';
 
+COMMENT ON COLUMN groups.dlp_policy IS 'Restricts how data can be transferred out of or into the workspaces of members of this group.';
+
 CREATE TABLE organization_members (
     user_id uuid NOT NULL,
     organization_id uuid NOT NULL,
@@ -880,7 +914,10 @@ CREATE TABLE users (
     hashed_one_time_passcode bytea,
     one_time_passcode_expires_at timestamp with time zone,
     is_system boolean DEFAULT false NOT NULL,
-    CONSTRAINT one_time_passcode_set CHECK ((((hashed_one_time_passcode IS NULL) AND (one_time_passcode_expires_at IS NULL)) OR ((hashed_one_time_passcode IS NOT NULL) AND (one_time_passcode_expires_at IS NOT NULL))))
+    is_service_account boolean DEFAULT false NOT NULL,
+    service_account_organization_id uuid,
+    CONSTRAINT one_time_passcode_set CHECK ((((hashed_one_time_passcode IS NULL) AND (one_time_passcode_expires_at IS NULL)) OR ((hashed_one_time_passcode IS NOT NULL) AND (one_time_passcode_expires_at IS NOT NULL)))),
+    CONSTRAINT service_account_organization_set CHECK ((is_service_account = (service_account_organization_id IS NOT NULL)))
 );
 
 COMMENT ON COLUMN users.quiet_hours_schedule IS 'Daily (!) cron schedule (with optional CRON_TZ) signifying the start of the user''s quiet hours. If empty, the default quiet hours on the instance is used instead.';
@@ -895,6 +932,10 @@ COMMENT ON COLUMN users.one_time_passcode_expires_at IS 'The time when the one-t
 
 COMMENT ON COLUMN users.is_system IS 'Determines if a user is a system user, and therefore cannot login or perform normal actions';
 
+COMMENT ON COLUMN users.is_service_account IS 'Determines if a user is a service account. Service accounts cannot log in interactively, and only authenticate with scoped API tokens.';
+
+COMMENT ON COLUMN users.service_account_organization_id IS 'The organization that owns the service account.';
+
 CREATE VIEW group_members_expanded AS
  WITH all_members AS (
          SELECT group_members.user_id,
@@ -1127,11 +1168,17 @@ CREATE TABLE provisioner_daemons (
     version text DEFAULT ''::text NOT NULL,
     api_version text DEFAULT '1.0'::text NOT NULL,
     organization_id uuid NOT NULL,
-    key_id uuid NOT NULL
+    key_id uuid NOT NULL,
+    terraform_engine text DEFAULT ''::text NOT NULL,
+    drain_requested_at timestamp with time zone
 );
 
 COMMENT ON COLUMN provisioner_daemons.api_version IS 'The API version of the provisioner daemon';
 
+COMMENT ON COLUMN provisioner_daemons.terraform_engine IS 'The binary the terraform provisioner of the daemon runs, terraform or opentofu. Empty if the daemon has no terraform provisioner.';
+
+COMMENT ON COLUMN provisioner_daemons.drain_requested_at IS 'When the daemon was asked to stop acquiring jobs and exit. Cleared when the daemon reconnects.';
+
 CREATE TABLE provisioner_job_logs (
     job_id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -1212,7 +1259,8 @@ CASE
         WHEN (started_at IS NULL) THEN 'pending'::provisioner_job_status
         ELSE 'running'::provisioner_job_status
     END
-END) STORED NOT NULL
+END) STORED NOT NULL,
+    priority provisioner_job_priority DEFAULT 'interactive'::provisioner_job_priority NOT NULL
 );
 
 COMMENT ON COLUMN provisioner_jobs.job_status IS 'Computed column to track the status of the job.';
@@ -1226,6 +1274,15 @@ CREATE TABLE provisioner_keys (
     tags jsonb NOT NULL
 );
 
+CREATE UNLOGGED TABLE pubsub_spillover (
+    id uuid NOT NULL,
+    event text NOT NULL,
+    payload bytea NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+COMMENT ON TABLE pubsub_spillover IS 'Payloads of pubsub messages that exceed the NOTIFY size limit. Rows are deleted shortly after they are published.';
+
 CREATE TABLE replicas (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -1520,7 +1577,11 @@ CREATE TABLE templates (
     require_active_version boolean DEFAULT false NOT NULL,
     deprecated text DEFAULT ''::text NOT NULL,
     activity_bump bigint DEFAULT '3600000000000'::bigint NOT NULL,
-    max_port_sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL
+    max_port_sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL,
+    drift_detection_interval bigint DEFAULT 0 NOT NULL,
+    session_recording_enabled boolean DEFAULT false NOT NULL,
+    session_recording_retention bigint DEFAULT 0 NOT NULL,
+    dlp_policy jsonb DEFAULT '{}'::jsonb NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for autostop for workspaces created from this template.';
@@ -1541,6 +1602,14 @@ COMMENT ON COLUMN templates.autostart_block_days_of_week IS 'A bitmap of days of
 
 COMMENT ON COLUMN templates.deprecated IS 'If set to a non empty string, the template will no longer be able to be used. The message will be displayed to the user.';
 
+COMMENT ON COLUMN templates.drift_detection_interval IS 'How often running workspaces are checked for drift from their Terraform state, in nanoseconds. 0 disables drift detection.';
+
+COMMENT ON COLUMN templates.session_recording_enabled IS 'Record the terminal sessions of workspaces created from this template.';
+
+COMMENT ON COLUMN templates.session_recording_retention IS 'How long terminal session recordings are kept, in nanoseconds. 0 keeps them forever.';
+
+COMMENT ON COLUMN templates.dlp_policy IS 'Restricts how data can be transferred out of or into workspaces created from this template.';
+
 CREATE VIEW template_with_names AS
  SELECT templates.id,
     templates.created_at,
@@ -1570,6 +1639,10 @@ CREATE VIEW template_with_names AS
     templates.deprecated,
     templates.activity_bump,
     templates.max_port_sharing_level,
+    templates.drift_detection_interval,
+    templates.session_recording_enabled,
+    templates.session_recording_retention,
+    templates.dlp_policy,
     COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS created_by_username,
     COALESCE(organizations.name, ''::text) AS organization_name,
@@ -1613,6 +1686,28 @@ COMMENT ON COLUMN user_links.oauth_refresh_token_key_id IS 'The ID of the key us
 
 COMMENT ON COLUMN user_links.claims IS 'Claims from the IDP for the linked user. Includes both id_token and userinfo claims. ';
 
+CREATE TABLE user_secrets (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    description text DEFAULT ''::text NOT NULL,
+    value text NOT NULL,
+    value_key_id text,
+    env_name text DEFAULT ''::text NOT NULL,
+    file_path text DEFAULT ''::text NOT NULL,
+    template_ids uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON COLUMN user_secrets.value_key_id IS 'The ID of the key used to encrypt the value. If this is NULL, the value is not encrypted';
+
+COMMENT ON COLUMN user_secrets.env_name IS 'The environment variable the secret is injected into workspaces as. Empty if it is not injected as an environment variable.';
+
+COMMENT ON COLUMN user_secrets.file_path IS 'The path of the file the secret is written to in workspaces. Relative paths are relative to the home directory. Empty if it is not written to a file.';
+
+COMMENT ON COLUMN user_secrets.template_ids IS 'The templates of the workspaces the secret is injected into. Empty if it is injected into every workspace.';
+
 CREATE TABLE user_status_changes (
     id uuid DEFAULT gen_random_uuid() NOT NULL,
     user_id uuid NOT NULL,
@@ -1704,6 +1799,20 @@ CREATE TABLE workspace_agent_port_share (
     protocol port_share_protocol DEFAULT 'http'::port_share_protocol NOT NULL
 );
 
+CREATE TABLE workspace_agent_pty_shares (
+    workspace_id uuid NOT NULL,
+    agent_id uuid NOT NULL,
+    reconnect_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    mode pty_share_mode NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE workspace_agent_pty_shares IS 'Users other than the workspace owner that may attach to a reconnecting PTY of a workspace agent.';
+
+COMMENT ON COLUMN workspace_agent_pty_shares.reconnect_id IS 'The ID of the reconnecting PTY, chosen by the client that creates it.';
+
 CREATE TABLE workspace_agent_script_timings (
     script_id uuid NOT NULL,
     started_at timestamp with time zone NOT NULL,
@@ -1991,6 +2100,19 @@ CREATE VIEW workspace_build_with_user AS
 
 COMMENT ON VIEW workspace_build_with_user IS 'Joins in the username + avatar url of the initiated by user.';
 
+CREATE TABLE workspace_drift_checks (
+    job_id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    workspace_build_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    checked_at timestamp with time zone,
+    resources jsonb DEFAULT '[]'::jsonb NOT NULL
+);
+
+COMMENT ON TABLE workspace_drift_checks IS 'Refresh-only plans run against running workspaces to detect infrastructure that changed outside of Coder.';
+
+COMMENT ON COLUMN workspace_drift_checks.resources IS 'The resources that drifted from the workspace build state.';
+
 CREATE VIEW workspace_latest_builds AS
  SELECT DISTINCT ON (wb.workspace_id) wb.id,
     wb.workspace_id,
@@ -2156,6 +2278,29 @@ CREATE SEQUENCE workspace_resource_metadata_id_seq
 
 ALTER SEQUENCE workspace_resource_metadata_id_seq OWNED BY workspace_resource_metadata.id;
 
+CREATE TABLE workspace_session_recording_chunks (
+    recording_id uuid NOT NULL,
+    chunk_index integer NOT NULL,
+    data bytea NOT NULL
+);
+
+COMMENT ON TABLE workspace_session_recording_chunks IS 'The contents of session recordings. Each chunk holds whole lines of the recording, so a recording stays readable if a chunk is lost.';
+
+CREATE TABLE workspace_session_recordings (
+    id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    agent_id uuid NOT NULL,
+    type session_recording_type NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    ended_at timestamp with time zone,
+    size bigint DEFAULT 0 NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE workspace_session_recordings IS 'Recordings of terminal sessions in workspaces, in the asciicast v2 format.';
+
+COMMENT ON COLUMN workspace_session_recordings.size IS 'The size of the recording in bytes, the sum of the size of its chunks.';
+
 CREATE VIEW workspaces_expanded AS
  SELECT workspaces.id,
     workspaces.created_at,
@@ -2328,6 +2473,9 @@ ALTER TABLE ONLY provisioner_jobs
 ALTER TABLE ONLY provisioner_keys
     ADD CONSTRAINT provisioner_keys_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY pubsub_spillover
+    ADD CONSTRAINT pubsub_spillover_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY site_configs
     ADD CONSTRAINT site_configs_key_key UNIQUE (key);
 
@@ -2391,6 +2539,12 @@ ALTER TABLE ONLY user_deleted
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_pkey PRIMARY KEY (user_id, login_type);
 
+ALTER TABLE ONLY user_secrets
+    ADD CONSTRAINT user_secrets_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY user_secrets
+    ADD CONSTRAINT user_secrets_user_id_name_key UNIQUE (user_id, name);
+
 ALTER TABLE ONLY user_status_changes
     ADD CONSTRAINT user_status_changes_pkey PRIMARY KEY (id);
 
@@ -2415,6 +2569,9 @@ ALTER TABLE ONLY workspace_agent_metadata
 ALTER TABLE ONLY workspace_agent_port_share
     ADD CONSTRAINT workspace_agent_port_share_pkey PRIMARY KEY (workspace_id, agent_name, port);
 
+ALTER TABLE ONLY workspace_agent_pty_shares
+    ADD CONSTRAINT workspace_agent_pty_shares_pkey PRIMARY KEY (agent_id, reconnect_id, user_id);
+
 ALTER TABLE ONLY workspace_agent_script_timings
     ADD CONSTRAINT workspace_agent_script_timings_script_id_started_at_key UNIQUE (script_id, started_at);
 
@@ -2463,6 +2620,9 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_build_number_key UNIQUE (workspace_id, build_number);
 
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_pkey PRIMARY KEY (job_id);
+
 ALTER TABLE ONLY workspace_proxies
     ADD CONSTRAINT workspace_proxies_pkey PRIMARY KEY (id);
 
@@ -2478,6 +2638,12 @@ ALTER TABLE ONLY workspace_resource_metadata
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_session_recording_chunks
+    ADD CONSTRAINT workspace_session_recording_chunks_pkey PRIMARY KEY (recording_id, chunk_index);
+
+ALTER TABLE ONLY workspace_session_recordings
+    ADD CONSTRAINT workspace_session_recordings_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_pkey PRIMARY KEY (id);
 
@@ -2519,6 +2685,8 @@ COMMENT ON INDEX idx_provisioner_daemons_org_name_owner_key IS 'Allow unique pro
 
 CREATE INDEX idx_provisioner_jobs_status ON provisioner_jobs USING btree (job_status);
 
+CREATE INDEX idx_pubsub_spillover_created_at ON pubsub_spillover USING btree (created_at);
+
 CREATE INDEX idx_tailnet_agents_coordinator ON tailnet_agents USING btree (coordinator_id);
 
 CREATE INDEX idx_tailnet_clients_coordinator ON tailnet_clients USING btree (coordinator_id);
@@ -2547,6 +2715,8 @@ CREATE UNIQUE INDEX organizations_single_default_org ON organizations USING btre
 
 CREATE INDEX provisioner_job_logs_id_job_id_idx ON provisioner_job_logs USING btree (job_id, id);
 
+CREATE INDEX provisioner_jobs_initiator_id_running_idx ON provisioner_jobs USING btree (initiator_id) WHERE (job_status = 'running'::provisioner_job_status);
+
 CREATE INDEX provisioner_jobs_started_at_idx ON provisioner_jobs USING btree (started_at) WHERE (started_at IS NULL);
 
 CREATE UNIQUE INDEX provisioner_keys_organization_id_name_idx ON provisioner_keys USING btree (organization_id, lower((name)::text));
@@ -2571,6 +2741,8 @@ CREATE INDEX workspace_agent_devcontainers_workspace_agent_id ON workspace_agent
 
 COMMENT ON INDEX workspace_agent_devcontainers_workspace_agent_id IS 'Workspace agent foreign key and query index';
 
+CREATE INDEX workspace_agent_pty_shares_user_id_idx ON workspace_agent_pty_shares USING btree (user_id);
+
 CREATE INDEX workspace_agent_scripts_workspace_agent_id_idx ON workspace_agent_scripts USING btree (workspace_agent_id);
 
 COMMENT ON INDEX workspace_agent_scripts_workspace_agent_id_idx IS 'Foreign key support index for faster lookups';
@@ -2591,6 +2763,8 @@ COMMENT ON INDEX workspace_app_audit_sessions_unique_index IS 'Unique index to e
 
 CREATE INDEX workspace_app_stats_workspace_id_idx ON workspace_app_stats USING btree (workspace_id);
 
+CREATE INDEX workspace_drift_checks_workspace_id_created_at_idx ON workspace_drift_checks USING btree (workspace_id, created_at DESC);
+
 CREATE INDEX workspace_modules_created_at_idx ON workspace_modules USING btree (created_at);
 
 CREATE INDEX workspace_next_start_at_idx ON workspaces USING btree (next_start_at) WHERE (deleted = false);
@@ -2599,6 +2773,10 @@ CREATE UNIQUE INDEX workspace_proxies_lower_name_idx ON workspace_proxies USING
 
 CREATE INDEX workspace_resources_job_id_idx ON workspace_resources USING btree (job_id);
 
+CREATE INDEX workspace_session_recordings_started_at_idx ON workspace_session_recordings USING btree (started_at DESC);
+
+CREATE INDEX workspace_session_recordings_workspace_id_started_at_idx ON workspace_session_recordings USING btree (workspace_id, started_at DESC);
+
 CREATE INDEX workspace_template_id_idx ON workspaces USING btree (template_id) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX workspaces_owner_id_lower_idx ON workspaces USING btree (owner_id, lower((name)::text)) WHERE (deleted = false);
@@ -2844,9 +3022,18 @@ ALTER TABLE ONLY user_links
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY user_secrets
+    ADD CONSTRAINT user_secrets_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY user_secrets
+    ADD CONSTRAINT user_secrets_value_key_id_fkey FOREIGN KEY (value_key_id) REFERENCES dbcrypt_keys(active_key_digest);
+
 ALTER TABLE ONLY user_status_changes
     ADD CONSTRAINT user_status_changes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
 
+ALTER TABLE ONLY users
+    ADD CONSTRAINT users_service_account_organization_id_fkey FOREIGN KEY (service_account_organization_id) REFERENCES organizations(id);
+
 ALTER TABLE ONLY webpush_subscriptions
     ADD CONSTRAINT webpush_subscriptions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
@@ -2865,6 +3052,18 @@ ALTER TABLE ONLY workspace_agent_metadata
 ALTER TABLE ONLY workspace_agent_port_share
     ADD CONSTRAINT workspace_agent_port_share_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_agent_pty_shares
+    ADD CONSTRAINT workspace_agent_pty_shares_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_pty_shares
+    ADD CONSTRAINT workspace_agent_pty_shares_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_pty_shares
+    ADD CONSTRAINT workspace_agent_pty_shares_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_pty_shares
+    ADD CONSTRAINT workspace_agent_pty_shares_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agent_script_timings
     ADD CONSTRAINT workspace_agent_script_timings_script_id_fkey FOREIGN KEY (script_id) REFERENCES workspace_agent_scripts(id) ON DELETE CASCADE;
 
@@ -2919,6 +3118,15 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_workspace_build_id_fkey FOREIGN KEY (workspace_build_id) REFERENCES workspace_builds(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_modules
     ADD CONSTRAINT workspace_modules_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
@@ -2928,6 +3136,15 @@ ALTER TABLE ONLY workspace_resource_metadata
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_session_recording_chunks
+    ADD CONSTRAINT workspace_session_recording_chunks_recording_id_fkey FOREIGN KEY (recording_id) REFERENCES workspace_session_recordings(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_session_recordings
+    ADD CONSTRAINT workspace_session_recordings_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_session_recordings
+    ADD CONSTRAINT workspace_session_recordings_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE RESTRICT;
 
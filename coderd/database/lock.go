@@ -14,6 +14,7 @@ const (
 	LockIDCryptoKeyRotation
 	LockIDReconcileTemplatePrebuilds
 	LockIDDeterminePrebuildsState
+	LockIDDriftDetection
 )
 
 // GenLockID generates a unique and consistent lock ID from a given string.
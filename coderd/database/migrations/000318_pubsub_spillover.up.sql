@@ -0,0 +1,13 @@
+-- Messages that are too large for a NOTIFY payload are stored here, and a
+-- reference to the row is sent instead. Rows are only needed until every
+-- subscriber has read them, so the table is UNLOGGED to speed up inserts.
+CREATE UNLOGGED TABLE pubsub_spillover (
+	id uuid NOT NULL PRIMARY KEY,
+	event text NOT NULL,
+	payload bytea NOT NULL,
+	created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE pubsub_spillover IS 'Payloads of pubsub messages that exceed the NOTIFY size limit. Rows are deleted shortly after they are published.';
+
+CREATE INDEX idx_pubsub_spillover_created_at ON pubsub_spillover USING btree (created_at);
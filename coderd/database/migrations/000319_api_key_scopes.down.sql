@@ -0,0 +1,5 @@
+ALTER TABLE api_keys DROP COLUMN allow_list;
+
+-- Values can't be removed from an enum, so the new api_key_scope values are
+-- left in place. The up migration uses ADD VALUE IF NOT EXISTS so it can be
+-- applied again.
@@ -0,0 +1,10 @@
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'workspace:read';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'workspace:start';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'workspace:stop';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'workspace:ssh';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'template:read';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'template:push';
+
+ALTER TABLE api_keys ADD COLUMN allow_list text[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN api_keys.allow_list IS 'allow_list contains the IDs of the only resources the key can access. If empty, the key can access every resource allowed by its scope.';
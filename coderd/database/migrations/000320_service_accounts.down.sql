@@ -0,0 +1,4 @@
+ALTER TABLE users
+	DROP CONSTRAINT IF EXISTS service_account_organization_set,
+	DROP COLUMN IF EXISTS service_account_organization_id,
+	DROP COLUMN IF EXISTS is_service_account;
@@ -0,0 +1,8 @@
+ALTER TABLE users
+	ADD COLUMN is_service_account boolean DEFAULT false NOT NULL,
+	ADD COLUMN service_account_organization_id uuid REFERENCES organizations (id),
+	ADD CONSTRAINT service_account_organization_set CHECK (is_service_account = (service_account_organization_id IS NOT NULL));
+
+COMMENT ON COLUMN users.is_service_account IS 'Determines if a user is a service account. Service accounts cannot log in interactively, and only authenticate with scoped API tokens.';
+
+COMMENT ON COLUMN users.service_account_organization_id IS 'The organization that owns the service account.';
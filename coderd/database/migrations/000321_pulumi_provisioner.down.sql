@@ -0,0 +1,2 @@
+-- Values can't be removed from an enum, so 'pulumi' is left in place. The up
+-- migration uses ADD VALUE IF NOT EXISTS so it can be applied again.
@@ -0,0 +1 @@
+ALTER TYPE provisioner_type ADD VALUE IF NOT EXISTS 'pulumi';
@@ -0,0 +1 @@
+ALTER TABLE provisioner_daemons DROP COLUMN terraform_engine;
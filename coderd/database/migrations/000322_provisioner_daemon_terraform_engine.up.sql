@@ -0,0 +1,3 @@
+ALTER TABLE provisioner_daemons ADD COLUMN terraform_engine text DEFAULT ''::text NOT NULL;
+
+COMMENT ON COLUMN provisioner_daemons.terraform_engine IS 'The binary the terraform provisioner of the daemon runs, terraform or opentofu. Empty if the daemon has no terraform provisioner.';
@@ -0,0 +1,34 @@
+DELETE FROM notification_templates WHERE id = 'e28f9729-7734-48b1-ad86-ba6668cb1b02';
+
+DROP TABLE workspace_drift_checks;
+
+DROP VIEW template_with_names;
+
+ALTER TABLE templates DROP COLUMN drift_detection_interval;
+
+CREATE VIEW
+	template_with_names
+AS
+SELECT
+	templates.*,
+	coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+	coalesce(visible_users.username, '') AS created_by_username,
+	coalesce(organizations.name, '') AS organization_name,
+	coalesce(organizations.display_name, '') AS organization_display_name,
+	coalesce(organizations.icon, '') AS organization_icon
+FROM
+	templates
+		LEFT JOIN
+	visible_users
+	ON
+		templates.created_by = visible_users.id
+		LEFT JOIN
+	organizations
+	ON templates.organization_id = organizations.id
+;
+
+COMMENT ON VIEW template_with_names IS 'Joins in the display name information such as username, avatar, and organization name.';
+
+-- Values can't be removed from an enum, so 'workspace_drift_check' is left in
+-- place. Drift check jobs are removed so none reference it.
+DELETE FROM provisioner_jobs WHERE type = 'workspace_drift_check';
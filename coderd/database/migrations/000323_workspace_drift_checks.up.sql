@@ -0,0 +1,68 @@
+ALTER TYPE provisioner_job_type ADD VALUE IF NOT EXISTS 'workspace_drift_check';
+
+ALTER TABLE templates ADD COLUMN drift_detection_interval bigint DEFAULT 0 NOT NULL;
+
+COMMENT ON COLUMN templates.drift_detection_interval IS 'How often running workspaces are checked for drift from their Terraform state, in nanoseconds. 0 disables drift detection.';
+
+-- Update the template_with_names view by recreating it.
+DROP VIEW template_with_names;
+CREATE VIEW
+	template_with_names
+AS
+SELECT
+	templates.*,
+	coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+	coalesce(visible_users.username, '') AS created_by_username,
+	coalesce(organizations.name, '') AS organization_name,
+	coalesce(organizations.display_name, '') AS organization_display_name,
+	coalesce(organizations.icon, '') AS organization_icon
+FROM
+	templates
+		LEFT JOIN
+	visible_users
+	ON
+		templates.created_by = visible_users.id
+		LEFT JOIN
+	organizations
+	ON templates.organization_id = organizations.id
+;
+
+COMMENT ON VIEW template_with_names IS 'Joins in the display name information such as username, avatar, and organization name.';
+
+CREATE TABLE workspace_drift_checks (
+	-- The workspace_drift_check provisioner job that performs the check.
+	job_id uuid PRIMARY KEY REFERENCES provisioner_jobs (id) ON DELETE CASCADE,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	-- The build whose state was checked.
+	workspace_build_id uuid NOT NULL REFERENCES workspace_builds (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	-- Set once the check completes successfully.
+	checked_at timestamp with time zone,
+	resources jsonb DEFAULT '[]'::jsonb NOT NULL
+);
+
+COMMENT ON TABLE workspace_drift_checks IS 'Refresh-only plans run against running workspaces to detect infrastructure that changed outside of Coder.';
+
+COMMENT ON COLUMN workspace_drift_checks.resources IS 'The resources that drifted from the workspace build state.';
+
+CREATE INDEX workspace_drift_checks_workspace_id_created_at_idx ON workspace_drift_checks (workspace_id, created_at DESC);
+
+INSERT INTO notification_templates
+	(id, name, title_template, body_template, "group", actions)
+VALUES (
+	'e28f9729-7734-48b1-ad86-ba6668cb1b02',
+	'Workspace Drift Detected',
+	E'Your workspace "{{.Labels.workspace}}" has drifted',
+	E'Resources of your workspace **{{.Labels.workspace}}** changed outside of Coder and no longer match its last build:\n\n'||
+		E'{{ range $resource := .Data.resources }}'||
+			E'- **`{{$resource.address}}`** was {{ if eq $resource.action "delete" }}deleted{{ else }}modified{{ end }}\n'||
+		E'{{ end }}\n'||
+		E'Rebuild the workspace to restore it.',
+	'Workspace Events',
+	'[
+		{
+			"label": "View workspace",
+			"url": "{{base_url}}/@{{.UserUsername}}/{{.Labels.workspace}}"
+		}
+	]'::jsonb
+);
@@ -0,0 +1,16 @@
+-- Values are declared from lowest to highest priority, so ordering by the
+-- column descending puts the most urgent jobs first.
+CREATE TYPE provisioner_job_priority AS ENUM (
+	'background',
+	'bulk',
+	'autobuild',
+	'interactive'
+);
+
+COMMENT ON TYPE provisioner_job_priority IS 'Priority of a provisioner job. Jobs with a higher priority are acquired before jobs with a lower priority, regardless of age.';
+
+ALTER TABLE provisioner_jobs ADD COLUMN priority provisioner_job_priority DEFAULT 'interactive'::provisioner_job_priority NOT NULL;
+
+-- Speeds up counting the jobs a user is currently running when acquiring
+-- jobs fairly.
+CREATE INDEX provisioner_jobs_initiator_id_running_idx ON provisioner_jobs (initiator_id) WHERE job_status = 'running'::provisioner_job_status;
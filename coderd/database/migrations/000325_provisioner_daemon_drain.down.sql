@@ -0,0 +1 @@
+ALTER TABLE provisioner_daemons DROP COLUMN drain_requested_at;
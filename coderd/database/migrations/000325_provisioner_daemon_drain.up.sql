@@ -0,0 +1,3 @@
+ALTER TABLE provisioner_daemons ADD COLUMN drain_requested_at timestamp with time zone;
+
+COMMENT ON COLUMN provisioner_daemons.drain_requested_at IS 'When the daemon was asked to stop acquiring jobs and exit. Cleared when the daemon reconnects.';
@@ -0,0 +1,19 @@
+CREATE TABLE user_secrets (
+	id uuid NOT NULL PRIMARY KEY,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name text NOT NULL,
+	description text NOT NULL DEFAULT '',
+	value text NOT NULL,
+	value_key_id text REFERENCES dbcrypt_keys(active_key_digest),
+	env_name text NOT NULL DEFAULT '',
+	file_path text NOT NULL DEFAULT '',
+	template_ids uuid[] NOT NULL DEFAULT '{}',
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	UNIQUE (user_id, name)
+);
+
+COMMENT ON COLUMN user_secrets.value_key_id IS 'The ID of the key used to encrypt the value. If this is NULL, the value is not encrypted';
+COMMENT ON COLUMN user_secrets.env_name IS 'The environment variable the secret is injected into workspaces as. Empty if it is not injected as an environment variable.';
+COMMENT ON COLUMN user_secrets.file_path IS 'The path of the file the secret is written to in workspaces. Relative paths are relative to the home directory. Empty if it is not written to a file.';
+COMMENT ON COLUMN user_secrets.template_ids IS 'The templates of the workspaces the secret is injected into. Empty if it is injected into every workspace.';
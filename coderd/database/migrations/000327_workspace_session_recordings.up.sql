@@ -0,0 +1,66 @@
+ALTER TABLE templates ADD COLUMN session_recording_enabled boolean DEFAULT false NOT NULL;
+ALTER TABLE templates ADD COLUMN session_recording_retention bigint DEFAULT 0 NOT NULL;
+
+COMMENT ON COLUMN templates.session_recording_enabled IS 'Record the terminal sessions of workspaces created from this template.';
+
+COMMENT ON COLUMN templates.session_recording_retention IS 'How long terminal session recordings are kept, in nanoseconds. 0 keeps them forever.';
+
+-- Update the template_with_names view by recreating it.
+DROP VIEW template_with_names;
+CREATE VIEW
+	template_with_names
+AS
+SELECT
+	templates.*,
+	coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+	coalesce(visible_users.username, '') AS created_by_username,
+	coalesce(organizations.name, '') AS organization_name,
+	coalesce(organizations.display_name, '') AS organization_display_name,
+	coalesce(organizations.icon, '') AS organization_icon
+FROM
+	templates
+		LEFT JOIN
+	visible_users
+	ON
+		templates.created_by = visible_users.id
+		LEFT JOIN
+	organizations
+	ON templates.organization_id = organizations.id
+;
+
+COMMENT ON VIEW template_with_names IS 'Joins in the display name information such as username, avatar, and organization name.';
+
+CREATE TYPE session_recording_type AS ENUM (
+	'ssh',
+	'reconnecting_pty'
+);
+
+CREATE TABLE workspace_session_recordings (
+	-- The ID of the connection reported by the agent for the session.
+	id uuid PRIMARY KEY,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	agent_id uuid NOT NULL REFERENCES workspace_agents (id) ON DELETE CASCADE,
+	type session_recording_type NOT NULL,
+	started_at timestamp with time zone NOT NULL,
+	-- Set once the agent has uploaded the whole recording.
+	ended_at timestamp with time zone,
+	size bigint DEFAULT 0 NOT NULL,
+	created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE workspace_session_recordings IS 'Recordings of terminal sessions in workspaces, in the asciicast v2 format.';
+
+COMMENT ON COLUMN workspace_session_recordings.size IS 'The size of the recording in bytes, the sum of the size of its chunks.';
+
+CREATE INDEX workspace_session_recordings_workspace_id_started_at_idx ON workspace_session_recordings (workspace_id, started_at DESC);
+
+CREATE INDEX workspace_session_recordings_started_at_idx ON workspace_session_recordings (started_at DESC);
+
+CREATE TABLE workspace_session_recording_chunks (
+	recording_id uuid NOT NULL REFERENCES workspace_session_recordings (id) ON DELETE CASCADE,
+	chunk_index integer NOT NULL,
+	data bytea NOT NULL,
+	PRIMARY KEY (recording_id, chunk_index)
+);
+
+COMMENT ON TABLE workspace_session_recording_chunks IS 'The contents of session recordings. Each chunk holds whole lines of the recording, so a recording stays readable if a chunk is lost.';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workspace_agent_pty_shares;
+
+DROP TYPE IF EXISTS pty_share_mode;
@@ -0,0 +1,21 @@
+CREATE TYPE pty_share_mode AS ENUM (
+	'read_only',
+	'read_write'
+);
+
+CREATE TABLE workspace_agent_pty_shares (
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	agent_id uuid NOT NULL REFERENCES workspace_agents (id) ON DELETE CASCADE,
+	reconnect_id uuid NOT NULL,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	mode pty_share_mode NOT NULL,
+	created_by uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (agent_id, reconnect_id, user_id)
+);
+
+COMMENT ON TABLE workspace_agent_pty_shares IS 'Users other than the workspace owner that may attach to a reconnecting PTY of a workspace agent.';
+
+COMMENT ON COLUMN workspace_agent_pty_shares.reconnect_id IS 'The ID of the reconnecting PTY, chosen by the client that creates it.';
+
+CREATE INDEX workspace_agent_pty_shares_user_id_idx ON workspace_agent_pty_shares (user_id);
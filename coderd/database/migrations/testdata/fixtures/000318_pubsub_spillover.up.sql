@@ -0,0 +1,2 @@
+INSERT INTO pubsub_spillover (id, event, payload, created_at)
+VALUES ('7bbcf8d3-3b36-4a79-9a5f-a3e1c6c6a8a1', 'workspace_owner:fixture', '\x7b7d', '2025-04-01 00:00:00+00');
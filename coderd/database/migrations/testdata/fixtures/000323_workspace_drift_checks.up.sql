@@ -0,0 +1,15 @@
+INSERT INTO workspace_drift_checks (
+	job_id,
+	workspace_id,
+	workspace_build_id,
+	created_at,
+	checked_at,
+	resources
+) VALUES (
+	'52a90399-a53d-4644-be3c-47ee18a5716e',
+	'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+	'a8c0b8c5-c9a8-4f33-93a4-8142e6858244',
+	'2022-11-03 13:04:19.044082+02',
+	'2022-11-03 13:04:25.044082+02',
+	'[{"address": "docker_container.workspace[0]", "type": "docker_container", "name": "workspace", "action": "delete"}]'::jsonb
+);
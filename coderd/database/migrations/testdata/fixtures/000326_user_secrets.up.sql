@@ -0,0 +1,2 @@
+INSERT INTO user_secrets (id, user_id, name, value, env_name, created_at, updated_at)
+VALUES (gen_random_uuid(), (SELECT id FROM users LIMIT 1), 'npm-token', 'npm_xxxxxx', 'NPM_TOKEN', NOW(), NOW());
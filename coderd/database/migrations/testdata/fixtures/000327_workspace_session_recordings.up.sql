@@ -0,0 +1,19 @@
+INSERT INTO workspace_session_recordings (id, workspace_id, agent_id, type, started_at, ended_at, size, created_at)
+SELECT
+	'b4b1e5d9-39a0-4d0f-8a4d-3c7a4f4f2d1e',
+	workspaces.id,
+	workspace_agents.id,
+	'ssh',
+	'2022-11-03 13:04:19.044082+02',
+	'2022-11-03 13:05:19.044082+02',
+	52,
+	'2022-11-03 13:04:19.044082+02'
+FROM workspaces, workspace_agents
+LIMIT 1;
+
+INSERT INTO workspace_session_recording_chunks (recording_id, chunk_index, data)
+SELECT id, 0, '{"version":2,"width":80,"height":24}
+[0.1,"o","$ "]
+'::bytea
+FROM workspace_session_recordings
+WHERE id = 'b4b1e5d9-39a0-4d0f-8a4d-3c7a4f4f2d1e';
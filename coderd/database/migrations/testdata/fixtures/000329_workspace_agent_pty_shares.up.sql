@@ -0,0 +1,12 @@
+INSERT INTO workspace_agent_pty_shares (workspace_id, agent_id, reconnect_id, user_id, mode, created_by, created_at)
+SELECT
+	workspaces.id,
+	workspace_agents.id,
+	'6d1a1f3e-4c0b-4a7e-9a53-2b8e5c1f7d20',
+	users.id,
+	'read_only',
+	workspaces.owner_id,
+	'2022-11-03 13:04:19.044082+02'
+FROM workspaces, workspace_agents, users
+WHERE users.id != workspaces.owner_id
+LIMIT 1;
@@ -123,11 +123,35 @@ func (s APIKeyScope) ToRBAC() rbac.ScopeName {
 		return rbac.ScopeAll
 	case APIKeyScopeApplicationConnect:
 		return rbac.ScopeApplicationConnect
+	case APIKeyScopeWorkspaceRead:
+		return rbac.ScopeWorkspaceRead
+	case APIKeyScopeWorkspaceStart:
+		return rbac.ScopeWorkspaceStart
+	case APIKeyScopeWorkspaceStop:
+		return rbac.ScopeWorkspaceStop
+	case APIKeyScopeWorkspaceSsh:
+		return rbac.ScopeWorkspaceSSH
+	case APIKeyScopeTemplateRead:
+		return rbac.ScopeTemplateRead
+	case APIKeyScopeTemplatePush:
+		return rbac.ScopeTemplatePush
 	default:
 		panic("developer error: unknown scope type " + string(s))
 	}
 }
 
+// RBACScope returns the scope of the key, restricted to its allow list if it
+// has one.
+func (k APIKey) RBACScope() rbac.ExpandableScope {
+	if len(k.AllowList) == 0 {
+		return rbac.ScopeName(k.Scope)
+	}
+	return rbac.AllowListScope{
+		Scope:       rbac.ScopeName(k.Scope),
+		AllowIDList: k.AllowList,
+	}
+}
+
 func (k APIKey) RBACObject() rbac.Object {
 	return rbac.ResourceApiKey.WithIDString(k.ID).
 		WithOwner(k.UserID.String())
@@ -323,6 +347,7 @@ func (u GetUsersRow) RBACObject() rbac.Object {
 func (u GitSSHKey) RBACObject() rbac.Object        { return rbac.ResourceUserObject(u.UserID) }
 func (u ExternalAuthLink) RBACObject() rbac.Object { return rbac.ResourceUserObject(u.UserID) }
 func (u UserLink) RBACObject() rbac.Object         { return rbac.ResourceUserObject(u.UserID) }
+func (u UserSecret) RBACObject() rbac.Object       { return rbac.ResourceUserObject(u.UserID) }
 
 func (u ExternalAuthLink) OAuthToken() *oauth2.Token {
 	return &oauth2.Token{
@@ -425,6 +450,9 @@ func ConvertUserRows(rows []GetUsersRow) []User {
 			Deleted:        r.Deleted,
 			LastSeenAt:     r.LastSeenAt,
 			IsSystem:       r.IsSystem,
+
+			IsServiceAccount:             r.IsServiceAccount,
+			ServiceAccountOrganizationID: r.ServiceAccountOrganizationID,
 		}
 	}
 
@@ -475,7 +503,7 @@ func (p ProvisionerJob) RBACObject() rbac.Object {
 	switch p.Type {
 	// Only acceptable for known job types at this time because template
 	// admins may not be allowed to view new types.
-	case ProvisionerJobTypeTemplateVersionImport, ProvisionerJobTypeTemplateVersionDryRun, ProvisionerJobTypeWorkspaceBuild:
+	case ProvisionerJobTypeTemplateVersionImport, ProvisionerJobTypeTemplateVersionDryRun, ProvisionerJobTypeWorkspaceBuild, ProvisionerJobTypeWorkspaceDriftCheck:
 		return rbac.ResourceProvisionerJobs.InOrg(p.OrganizationID)
 
 	default:
@@ -117,6 +117,10 @@ func (q *sqlQuerier) GetAuthorizedTemplates(ctx context.Context, arg GetTemplate
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			&i.DriftDetectionInterval,
+			&i.SessionRecordingEnabled,
+			&i.SessionRecordingRetention,
+			&i.DLPPolicy,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -425,6 +429,8 @@ func (q *sqlQuerier) GetAuthorizedUsers(ctx context.Context, arg GetUsersParams,
 			&i.HashedOneTimePasscode,
 			&i.OneTimePasscodeExpiresAt,
 			&i.IsSystem,
+			&i.IsServiceAccount,
+			&i.ServiceAccountOrganizationID,
 			&i.Count,
 		); err != nil {
 			return nil, err
@@ -509,6 +515,7 @@ func (q *sqlQuerier) GetAuthorizedAuditLogsOffset(ctx context.Context, arg GetAu
 			&i.UserAvatarUrl,
 			&i.UserDeleted,
 			&i.UserQuietHoursSchedule,
+			&i.UserIsServiceAccount,
 			&i.OrganizationName,
 			&i.OrganizationDisplayName,
 			&i.OrganizationIcon,
@@ -21,6 +21,12 @@ type APIKeyScope string
 const (
 	APIKeyScopeAll                APIKeyScope = "all"
 	APIKeyScopeApplicationConnect APIKeyScope = "application_connect"
+	APIKeyScopeWorkspaceRead      APIKeyScope = "workspace:read"
+	APIKeyScopeWorkspaceStart     APIKeyScope = "workspace:start"
+	APIKeyScopeWorkspaceStop      APIKeyScope = "workspace:stop"
+	APIKeyScopeWorkspaceSsh       APIKeyScope = "workspace:ssh"
+	APIKeyScopeTemplateRead       APIKeyScope = "template:read"
+	APIKeyScopeTemplatePush       APIKeyScope = "template:push"
 )
 
 func (e *APIKeyScope) Scan(src interface{}) error {
@@ -61,7 +67,13 @@ func (ns NullAPIKeyScope) Value() (driver.Value, error) {
 func (e APIKeyScope) Valid() bool {
 	switch e {
 	case APIKeyScopeAll,
-		APIKeyScopeApplicationConnect:
+		APIKeyScopeApplicationConnect,
+		APIKeyScopeWorkspaceRead,
+		APIKeyScopeWorkspaceStart,
+		APIKeyScopeWorkspaceStop,
+		APIKeyScopeWorkspaceSsh,
+		APIKeyScopeTemplateRead,
+		APIKeyScopeTemplatePush:
 		return true
 	}
 	return false
@@ -71,6 +83,12 @@ func AllAPIKeyScopeValues() []APIKeyScope {
 	return []APIKeyScope{
 		APIKeyScopeAll,
 		APIKeyScopeApplicationConnect,
+		APIKeyScopeWorkspaceRead,
+		APIKeyScopeWorkspaceStart,
+		APIKeyScopeWorkspaceStop,
+		APIKeyScopeWorkspaceSsh,
+		APIKeyScopeTemplateRead,
+		APIKeyScopeTemplatePush,
 	}
 }
 
@@ -151,6 +169,7 @@ const (
 	AuditActionDisconnect           AuditAction = "disconnect"
 	AuditActionOpen                 AuditAction = "open"
 	AuditActionClose                AuditAction = "close"
+	AuditActionBlocked              AuditAction = "blocked"
 )
 
 func (e *AuditAction) Scan(src interface{}) error {
@@ -202,7 +221,8 @@ func (e AuditAction) Valid() bool {
 		AuditActionConnect,
 		AuditActionDisconnect,
 		AuditActionOpen,
-		AuditActionClose:
+		AuditActionClose,
+		AuditActionBlocked:
 		return true
 	}
 	return false
@@ -223,6 +243,7 @@ func AllAuditActionValues() []AuditAction {
 		AuditActionDisconnect,
 		AuditActionOpen,
 		AuditActionClose,
+		AuditActionBlocked,
 	}
 }
 
@@ -1347,6 +1368,71 @@ func AllProvisionerDaemonStatusValues() []ProvisionerDaemonStatus {
 	}
 }
 
+// Priority of a provisioner job. Jobs with a higher priority are acquired before jobs with a lower priority, regardless of age.
+type ProvisionerJobPriority string
+
+const (
+	ProvisionerJobPriorityBackground  ProvisionerJobPriority = "background"
+	ProvisionerJobPriorityBulk        ProvisionerJobPriority = "bulk"
+	ProvisionerJobPriorityAutobuild   ProvisionerJobPriority = "autobuild"
+	ProvisionerJobPriorityInteractive ProvisionerJobPriority = "interactive"
+)
+
+func (e *ProvisionerJobPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProvisionerJobPriority(s)
+	case string:
+		*e = ProvisionerJobPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProvisionerJobPriority: %T", src)
+	}
+	return nil
+}
+
+type NullProvisionerJobPriority struct {
+	ProvisionerJobPriority ProvisionerJobPriority `json:"provisioner_job_priority"`
+	Valid                  bool                   `json:"valid"` // Valid is true if ProvisionerJobPriority is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProvisionerJobPriority) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProvisionerJobPriority, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProvisionerJobPriority.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProvisionerJobPriority) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProvisionerJobPriority), nil
+}
+
+func (e ProvisionerJobPriority) Valid() bool {
+	switch e {
+	case ProvisionerJobPriorityBackground,
+		ProvisionerJobPriorityBulk,
+		ProvisionerJobPriorityAutobuild,
+		ProvisionerJobPriorityInteractive:
+		return true
+	}
+	return false
+}
+
+func AllProvisionerJobPriorityValues() []ProvisionerJobPriority {
+	return []ProvisionerJobPriority{
+		ProvisionerJobPriorityBackground,
+		ProvisionerJobPriorityBulk,
+		ProvisionerJobPriorityAutobuild,
+		ProvisionerJobPriorityInteractive,
+	}
+}
+
 // Computed status of a provisioner job. Jobs could be stuck in a hung state, these states do not guarantee any transition to another state.
 type ProvisionerJobStatus string
 
@@ -1491,6 +1577,7 @@ const (
 	ProvisionerJobTypeTemplateVersionImport ProvisionerJobType = "template_version_import"
 	ProvisionerJobTypeWorkspaceBuild        ProvisionerJobType = "workspace_build"
 	ProvisionerJobTypeTemplateVersionDryRun ProvisionerJobType = "template_version_dry_run"
+	ProvisionerJobTypeWorkspaceDriftCheck   ProvisionerJobType = "workspace_drift_check"
 )
 
 func (e *ProvisionerJobType) Scan(src interface{}) error {
@@ -1532,7 +1619,8 @@ func (e ProvisionerJobType) Valid() bool {
 	switch e {
 	case ProvisionerJobTypeTemplateVersionImport,
 		ProvisionerJobTypeWorkspaceBuild,
-		ProvisionerJobTypeTemplateVersionDryRun:
+		ProvisionerJobTypeTemplateVersionDryRun,
+		ProvisionerJobTypeWorkspaceDriftCheck:
 		return true
 	}
 	return false
@@ -1543,6 +1631,7 @@ func AllProvisionerJobTypeValues() []ProvisionerJobType {
 		ProvisionerJobTypeTemplateVersionImport,
 		ProvisionerJobTypeWorkspaceBuild,
 		ProvisionerJobTypeTemplateVersionDryRun,
+		ProvisionerJobTypeWorkspaceDriftCheck,
 	}
 }
 
@@ -1606,6 +1695,7 @@ type ProvisionerType string
 const (
 	ProvisionerTypeEcho      ProvisionerType = "echo"
 	ProvisionerTypeTerraform ProvisionerType = "terraform"
+	ProvisionerTypePulumi    ProvisionerType = "pulumi"
 )
 
 func (e *ProvisionerType) Scan(src interface{}) error {
@@ -1646,7 +1736,8 @@ func (ns NullProvisionerType) Value() (driver.Value, error) {
 func (e ProvisionerType) Valid() bool {
 	switch e {
 	case ProvisionerTypeEcho,
-		ProvisionerTypeTerraform:
+		ProvisionerTypeTerraform,
+		ProvisionerTypePulumi:
 		return true
 	}
 	return false
@@ -1656,6 +1747,65 @@ func AllProvisionerTypeValues() []ProvisionerType {
 	return []ProvisionerType{
 		ProvisionerTypeEcho,
 		ProvisionerTypeTerraform,
+		ProvisionerTypePulumi,
+	}
+}
+
+type PTYShareMode string
+
+const (
+	PTYShareModeReadOnly  PTYShareMode = "read_only"
+	PTYShareModeReadWrite PTYShareMode = "read_write"
+)
+
+func (e *PTYShareMode) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PTYShareMode(s)
+	case string:
+		*e = PTYShareMode(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PTYShareMode: %T", src)
+	}
+	return nil
+}
+
+type NullPTYShareMode struct {
+	PTYShareMode PTYShareMode `json:"pty_share_mode"`
+	Valid        bool         `json:"valid"` // Valid is true if PTYShareMode is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPTYShareMode) Scan(value interface{}) error {
+	if value == nil {
+		ns.PTYShareMode, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PTYShareMode.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPTYShareMode) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PTYShareMode), nil
+}
+
+func (e PTYShareMode) Valid() bool {
+	switch e {
+	case PTYShareModeReadOnly,
+		PTYShareModeReadWrite:
+		return true
+	}
+	return false
+}
+
+func AllPTYShareModeValues() []PTYShareMode {
+	return []PTYShareMode{
+		PTYShareModeReadOnly,
+		PTYShareModeReadWrite,
 	}
 }
 
@@ -1783,6 +1933,64 @@ func AllResourceTypeValues() []ResourceType {
 	}
 }
 
+type SessionRecordingType string
+
+const (
+	SessionRecordingTypeSsh             SessionRecordingType = "ssh"
+	SessionRecordingTypeReconnectingPty SessionRecordingType = "reconnecting_pty"
+)
+
+func (e *SessionRecordingType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SessionRecordingType(s)
+	case string:
+		*e = SessionRecordingType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SessionRecordingType: %T", src)
+	}
+	return nil
+}
+
+type NullSessionRecordingType struct {
+	SessionRecordingType SessionRecordingType `json:"session_recording_type"`
+	Valid                bool                 `json:"valid"` // Valid is true if SessionRecordingType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSessionRecordingType) Scan(value interface{}) error {
+	if value == nil {
+		ns.SessionRecordingType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SessionRecordingType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSessionRecordingType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SessionRecordingType), nil
+}
+
+func (e SessionRecordingType) Valid() bool {
+	switch e {
+	case SessionRecordingTypeSsh,
+		SessionRecordingTypeReconnectingPty:
+		return true
+	}
+	return false
+}
+
+func AllSessionRecordingTypeValues() []SessionRecordingType {
+	return []SessionRecordingType{
+		SessionRecordingTypeSsh,
+		SessionRecordingTypeReconnectingPty,
+	}
+}
+
 type StartupScriptBehavior string
 
 const (
@@ -2550,6 +2758,8 @@ type APIKey struct {
 	IPAddress       pqtype.Inet `db:"ip_address" json:"ip_address"`
 	Scope           APIKeyScope `db:"scope" json:"scope"`
 	TokenName       string      `db:"token_name" json:"token_name"`
+	// allow_list contains the IDs of the only resources the key can access. If empty, the key can access every resource allowed by its scope.
+	AllowList []string `db:"allow_list" json:"allow_list"`
 }
 
 type AuditLog struct {
@@ -2652,6 +2862,8 @@ type Group struct {
 	DisplayName string `db:"display_name" json:"display_name"`
 	// Source indicates how the group was created. It can be created by a user manually, or through some system process like OIDC group sync.
 	Source GroupSource `db:"source" json:"source"`
+	// Restricts how data can be transferred out of or into the workspaces of members of this group.
+	DLPPolicy DLPPolicy `db:"dlp_policy" json:"dlp_policy"`
 }
 
 // Joins group members with user information, organization ID, group name. Includes both regular group members and organization members (as part of the "Everyone" group).
@@ -2869,6 +3081,10 @@ type ProvisionerDaemon struct {
 	APIVersion     string    `db:"api_version" json:"api_version"`
 	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
 	KeyID          uuid.UUID `db:"key_id" json:"key_id"`
+	// The binary the terraform provisioner of the daemon runs, terraform or opentofu. Empty if the daemon has no terraform provisioner.
+	TerraformEngine string `db:"terraform_engine" json:"terraform_engine"`
+	// When the daemon was asked to stop acquiring jobs and exit. Cleared when the daemon reconnects.
+	DrainRequestedAt sql.NullTime `db:"drain_requested_at" json:"drain_requested_at"`
 }
 
 type ProvisionerJob struct {
@@ -2891,7 +3107,8 @@ type ProvisionerJob struct {
 	ErrorCode      sql.NullString           `db:"error_code" json:"error_code"`
 	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
 	// Computed column to track the status of the job.
-	JobStatus ProvisionerJobStatus `db:"job_status" json:"job_status"`
+	JobStatus ProvisionerJobStatus   `db:"job_status" json:"job_status"`
+	Priority  ProvisionerJobPriority `db:"priority" json:"priority"`
 }
 
 type ProvisionerJobLog struct {
@@ -2940,6 +3157,14 @@ type ProvisionerKey struct {
 	Tags           StringMap `db:"tags" json:"tags"`
 }
 
+// Payloads of pubsub messages that exceed the NOTIFY size limit. Rows are deleted shortly after they are published.
+type PubsubSpillover struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Event     string    `db:"event" json:"event"`
+	Payload   []byte    `db:"payload" json:"payload"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
 type Replica struct {
 	ID              uuid.UUID    `db:"id" json:"id"`
 	CreatedAt       time.Time    `db:"created_at" json:"created_at"`
@@ -3039,6 +3264,10 @@ type Template struct {
 	Deprecated                    string          `db:"deprecated" json:"deprecated"`
 	ActivityBump                  int64           `db:"activity_bump" json:"activity_bump"`
 	MaxPortSharingLevel           AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	DriftDetectionInterval        int64           `db:"drift_detection_interval" json:"drift_detection_interval"`
+	SessionRecordingEnabled       bool            `db:"session_recording_enabled" json:"session_recording_enabled"`
+	SessionRecordingRetention     int64           `db:"session_recording_retention" json:"session_recording_retention"`
+	DLPPolicy                     DLPPolicy       `db:"dlp_policy" json:"dlp_policy"`
 	CreatedByAvatarURL            string          `db:"created_by_avatar_url" json:"created_by_avatar_url"`
 	CreatedByUsername             string          `db:"created_by_username" json:"created_by_username"`
 	OrganizationName              string          `db:"organization_name" json:"organization_name"`
@@ -3084,6 +3313,14 @@ type TemplateTable struct {
 	Deprecated          string          `db:"deprecated" json:"deprecated"`
 	ActivityBump        int64           `db:"activity_bump" json:"activity_bump"`
 	MaxPortSharingLevel AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	// How often running workspaces are checked for drift from their Terraform state, in nanoseconds. 0 disables drift detection.
+	DriftDetectionInterval int64 `db:"drift_detection_interval" json:"drift_detection_interval"`
+	// Record the terminal sessions of workspaces created from this template.
+	SessionRecordingEnabled bool `db:"session_recording_enabled" json:"session_recording_enabled"`
+	// How long terminal session recordings are kept, in nanoseconds. 0 keeps them forever.
+	SessionRecordingRetention int64 `db:"session_recording_retention" json:"session_recording_retention"`
+	// Restricts how data can be transferred out of or into workspaces created from this template.
+	DLPPolicy DLPPolicy `db:"dlp_policy" json:"dlp_policy"`
 }
 
 // Records aggregated usage statistics for templates/users. All usage is rounded up to the nearest minute.
@@ -3258,6 +3495,10 @@ type User struct {
 	OneTimePasscodeExpiresAt sql.NullTime `db:"one_time_passcode_expires_at" json:"one_time_passcode_expires_at"`
 	// Determines if a user is a system user, and therefore cannot login or perform normal actions
 	IsSystem bool `db:"is_system" json:"is_system"`
+	// Determines if a user is a service account. Service accounts cannot log in interactively, and only authenticate with scoped API tokens.
+	IsServiceAccount bool `db:"is_service_account" json:"is_service_account"`
+	// The organization that owns the service account.
+	ServiceAccountOrganizationID uuid.NullUUID `db:"service_account_organization_id" json:"service_account_organization_id"`
 }
 
 type UserConfig struct {
@@ -3288,6 +3529,24 @@ type UserLink struct {
 	Claims UserLinkClaims `db:"claims" json:"claims"`
 }
 
+type UserSecret struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	Value       string    `db:"value" json:"value"`
+	// The ID of the key used to encrypt the value. If this is NULL, the value is not encrypted
+	ValueKeyID sql.NullString `db:"value_key_id" json:"value_key_id"`
+	// The environment variable the secret is injected into workspaces as. Empty if it is not injected as an environment variable.
+	EnvName string `db:"env_name" json:"env_name"`
+	// The path of the file the secret is written to in workspaces. Relative paths are relative to the home directory. Empty if it is not written to a file.
+	FilePath string `db:"file_path" json:"file_path"`
+	// The templates of the workspaces the secret is injected into. Empty if it is injected into every workspace.
+	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
+	CreatedAt   time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time   `db:"updated_at" json:"updated_at"`
+}
+
 // Tracks the history of user status changes
 type UserStatusChange struct {
 	ID        uuid.UUID  `db:"id" json:"id"`
@@ -3452,6 +3711,18 @@ type WorkspaceAgentPortShare struct {
 	Protocol    PortShareProtocol `db:"protocol" json:"protocol"`
 }
 
+// Users other than the workspace owner that may attach to a reconnecting PTY of a workspace agent.
+type WorkspaceAgentPTYShare struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	// The ID of the reconnecting PTY, chosen by the client that creates it.
+	ReconnectID uuid.UUID    `db:"reconnect_id" json:"reconnect_id"`
+	UserID      uuid.UUID    `db:"user_id" json:"user_id"`
+	Mode        PTYShareMode `db:"mode" json:"mode"`
+	CreatedBy   uuid.UUID    `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+}
+
 type WorkspaceAgentScript struct {
 	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
 	LogSourceID      uuid.UUID `db:"log_source_id" json:"log_source_id"`
@@ -3636,6 +3907,17 @@ type WorkspaceBuildTable struct {
 	TemplateVersionPresetID uuid.NullUUID       `db:"template_version_preset_id" json:"template_version_preset_id"`
 }
 
+// Refresh-only plans run against running workspaces to detect infrastructure that changed outside of Coder.
+type WorkspaceDriftCheck struct {
+	JobID            uuid.UUID    `db:"job_id" json:"job_id"`
+	WorkspaceID      uuid.UUID    `db:"workspace_id" json:"workspace_id"`
+	WorkspaceBuildID uuid.UUID    `db:"workspace_build_id" json:"workspace_build_id"`
+	CreatedAt        time.Time    `db:"created_at" json:"created_at"`
+	CheckedAt        sql.NullTime `db:"checked_at" json:"checked_at"`
+	// The resources that drifted from the workspace build state.
+	Resources WorkspaceDriftResources `db:"resources" json:"resources"`
+}
+
 type WorkspaceLatestBuild struct {
 	ID                      uuid.UUID            `db:"id" json:"id"`
 	WorkspaceID             uuid.UUID            `db:"workspace_id" json:"workspace_id"`
@@ -3721,6 +4003,26 @@ type WorkspaceResourceMetadatum struct {
 	ID                  int64          `db:"id" json:"id"`
 }
 
+// Recordings of terminal sessions in workspaces, in the asciicast v2 format.
+type WorkspaceSessionRecording struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	WorkspaceID uuid.UUID            `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID            `db:"agent_id" json:"agent_id"`
+	Type        SessionRecordingType `db:"type" json:"type"`
+	StartedAt   time.Time            `db:"started_at" json:"started_at"`
+	EndedAt     sql.NullTime         `db:"ended_at" json:"ended_at"`
+	// The size of the recording in bytes, the sum of the size of its chunks.
+	Size      int64     `db:"size" json:"size"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// The contents of session recordings. Each chunk holds whole lines of the recording, so a recording stays readable if a chunk is lost.
+type WorkspaceSessionRecordingChunk struct {
+	RecordingID uuid.UUID `db:"recording_id" json:"recording_id"`
+	ChunkIndex  int32     `db:"chunk_index" json:"chunk_index"`
+	Data        []byte    `db:"data" json:"data"`
+}
+
 type WorkspaceTable struct {
 	ID                uuid.UUID        `db:"id" json:"id"`
 	CreatedAt         time.Time        `db:"created_at" json:"created_at"`
@@ -14,6 +14,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/xerrors"
+	"tailscale.com/util/singleflight"
 
 	"github.com/coder/coder/v2/coderd/database"
 
@@ -217,17 +218,11 @@ type PGPubsub struct {
 	closedListener   bool
 	closeListenerErr error
 
-	publishesTotal      *prometheus.CounterVec
-	subscribesTotal     *prometheus.CounterVec
-	messagesTotal       *prometheus.CounterVec
-	publishedBytesTotal prometheus.Counter
-	receivedBytesTotal  prometheus.Counter
-	disconnectionsTotal prometheus.Counter
-	connected           prometheus.Gauge
+	spilloverFetches  singleflight.Group[string, []byte]
+	cancelSpilloverGC context.CancelFunc
+	spilloverGCDone   chan struct{}
 
-	latencyMeasurer       *LatencyMeasurer
-	latencyMeasureCounter atomic.Int64
-	latencyErrCounter     atomic.Int64
+	*metrics
 }
 
 // BufferSize is the maximum number of unhandled messages we will buffer
@@ -236,11 +231,18 @@ const BufferSize = 2048
 
 // Subscribe calls the listener when an event matching the name is received.
 func (p *PGPubsub) Subscribe(event string, listener Listener) (cancel func(), err error) {
-	return p.subscribeQueue(event, newMsgQueue(context.Background(), listener, nil))
+	return p.subscribeQueue(event, newMsgQueue(context.Background(), nil, p.resolveSpillover(event, func(ctx context.Context, message []byte, err error) {
+		// Listener doesn't receive errors, so messages that failed to be
+		// fetched from the spillover table are skipped.
+		if err != nil {
+			return
+		}
+		listener(ctx, message)
+	})))
 }
 
 func (p *PGPubsub) SubscribeWithErr(event string, listener ListenerWithErr) (cancel func(), err error) {
-	return p.subscribeQueue(event, newMsgQueue(context.Background(), nil, listener))
+	return p.subscribeQueue(event, newMsgQueue(context.Background(), nil, p.resolveSpillover(event, listener)))
 }
 
 func (p *PGPubsub) subscribeQueue(event string, newQ *msgQueue) (cancel func(), err error) {
@@ -357,15 +359,26 @@ func (p *PGPubsub) subscribeQueue(event string, newQ *msgQueue) (cancel func(),
 	}, nil
 }
 
+// Publish sends the message to subscribers of the event. Messages that don't
+// fit in a NOTIFY payload are spilled to the database, and subscribers fetch
+// them from there.
 func (p *PGPubsub) Publish(event string, message []byte) error {
 	p.logger.Debug(context.Background(), "publish", slog.F("event", event), slog.F("message_len", len(message)))
-	// This is safe because we are calling pq.QuoteLiteral. pg_notify doesn't
-	// support the first parameter being a prepared statement.
-	//nolint:gosec
-	_, err := p.db.ExecContext(context.Background(), `select pg_notify(`+pq.QuoteLiteral(event)+`, $1)`, message)
+	var err error
+	if needsSpillover(message) {
+		err = p.publishSpillover(event, message)
+	} else {
+		// This is safe because we are calling pq.QuoteLiteral. pg_notify doesn't
+		// support the first parameter being a prepared statement.
+		//nolint:gosec
+		_, err = p.db.ExecContext(context.Background(), `select pg_notify(`+pq.QuoteLiteral(event)+`, $1)`, message)
+		if err != nil {
+			err = xerrors.Errorf("exec pg_notify: %w", err)
+		}
+	}
 	if err != nil {
 		p.publishesTotal.WithLabelValues("false").Inc()
-		return xerrors.Errorf("exec pg_notify: %w", err)
+		return err
 	}
 	p.publishesTotal.WithLabelValues("true").Inc()
 	p.publishedBytesTotal.Add(float64(len(message)))
@@ -377,6 +390,10 @@ func (p *PGPubsub) Close() error {
 	p.logger.Info(context.Background(), "pubsub is closing")
 	err := p.closeListener()
 	<-p.listenDone
+	if p.cancelSpilloverGC != nil {
+		p.cancelSpilloverGC()
+		<-p.spilloverGCDone
+	}
 	p.logger.Debug(context.Background(), "pubsub closed")
 	return err
 }
@@ -414,12 +431,7 @@ func (p *PGPubsub) listen() {
 }
 
 func (p *PGPubsub) listenReceive(notif *pq.Notification) {
-	sizeLabel := messageSizeNormal
-	if len(notif.Extra) >= colossalThreshold {
-		sizeLabel = messageSizeColossal
-	}
-	p.messagesTotal.WithLabelValues(sizeLabel).Inc()
-	p.receivedBytesTotal.Add(float64(len(notif.Extra)))
+	p.recordMessage(len(notif.Extra))
 
 	p.qMu.Lock()
 	defer p.qMu.Unlock()
@@ -617,39 +629,12 @@ const (
 // Describe implements, along with Collect, the prometheus.Collector interface
 // for metrics.
 func (p *PGPubsub) Describe(descs chan<- *prometheus.Desc) {
-	// explicit metrics
-	p.publishesTotal.Describe(descs)
-	p.subscribesTotal.Describe(descs)
-	p.messagesTotal.Describe(descs)
-	p.publishedBytesTotal.Describe(descs)
-	p.receivedBytesTotal.Describe(descs)
-	p.disconnectionsTotal.Describe(descs)
-	p.connected.Describe(descs)
-
-	// implicit metrics
-	descs <- currentSubscribersDesc
-	descs <- currentEventsDesc
-
-	// additional metrics
-	descs <- pubsubSendLatencyDesc
-	descs <- pubsubRecvLatencyDesc
-	descs <- pubsubLatencyMeasureCountDesc
-	descs <- pubsubLatencyMeasureErrDesc
+	p.describe(descs)
 }
 
 // Collect implements, along with Describe, the prometheus.Collector interface
 // for metrics
 func (p *PGPubsub) Collect(metrics chan<- prometheus.Metric) {
-	// explicit metrics
-	p.publishesTotal.Collect(metrics)
-	p.subscribesTotal.Collect(metrics)
-	p.messagesTotal.Collect(metrics)
-	p.publishedBytesTotal.Collect(metrics)
-	p.receivedBytesTotal.Collect(metrics)
-	p.disconnectionsTotal.Collect(metrics)
-	p.connected.Collect(metrics)
-
-	// implicit metrics
 	p.qMu.Lock()
 	events := len(p.queues)
 	subs := 0
@@ -657,42 +642,32 @@ func (p *PGPubsub) Collect(metrics chan<- prometheus.Metric) {
 		subs += len(qSet.m)
 	}
 	p.qMu.Unlock()
-	metrics <- prometheus.MustNewConstMetric(currentSubscribersDesc, prometheus.GaugeValue, float64(subs))
-	metrics <- prometheus.MustNewConstMetric(currentEventsDesc, prometheus.GaugeValue, float64(events))
+	p.collect(metrics, p, subs, events)
+}
 
-	// additional metrics
-	ctx, cancel := context.WithTimeout(context.Background(), LatencyMeasureTimeout)
-	defer cancel()
-	send, recv, err := p.latencyMeasurer.Measure(ctx, p)
+// metrics are the metrics shared by all Pubsub implementations that talk to
+// an external service.
+type metrics struct {
+	logger slog.Logger
 
-	metrics <- prometheus.MustNewConstMetric(pubsubLatencyMeasureCountDesc, prometheus.CounterValue, float64(p.latencyMeasureCounter.Add(1)))
-	if err != nil {
-		p.logger.Warn(context.Background(), "failed to measure latency", slog.Error(err))
-		metrics <- prometheus.MustNewConstMetric(pubsubLatencyMeasureErrDesc, prometheus.CounterValue, float64(p.latencyErrCounter.Add(1)))
-		return
-	}
-	metrics <- prometheus.MustNewConstMetric(pubsubSendLatencyDesc, prometheus.GaugeValue, send.Seconds())
-	metrics <- prometheus.MustNewConstMetric(pubsubRecvLatencyDesc, prometheus.GaugeValue, recv.Seconds())
-}
+	publishesTotal      *prometheus.CounterVec
+	subscribesTotal     *prometheus.CounterVec
+	messagesTotal       *prometheus.CounterVec
+	publishedBytesTotal prometheus.Counter
+	receivedBytesTotal  prometheus.Counter
+	disconnectionsTotal prometheus.Counter
+	connected           prometheus.Gauge
 
-// New creates a new Pubsub implementation using a PostgreSQL connection.
-func New(startCtx context.Context, logger slog.Logger, db *sql.DB, connectURL string) (*PGPubsub, error) {
-	p := newWithoutListener(logger, db)
-	if err := p.startListener(startCtx, connectURL); err != nil {
-		return nil, err
-	}
-	go p.listen()
-	logger.Debug(startCtx, "pubsub has started")
-	return p, nil
+	latencyMeasurer       *LatencyMeasurer
+	latencyMeasureCounter atomic.Int64
+	latencyErrCounter     atomic.Int64
 }
 
-// newWithoutListener creates a new PGPubsub without creating the pqListener.
-func newWithoutListener(logger slog.Logger, db *sql.DB) *PGPubsub {
-	return &PGPubsub{
+// newMetrics creates the pubsub metrics. backend is the name of the service
+// the pubsub is backed by, as it should appear in the metric help text.
+func newMetrics(logger slog.Logger, backend string) *metrics {
+	return &metrics{
 		logger:          logger,
-		listenDone:      make(chan struct{}),
-		db:              db,
-		queues:          make(map[string]*queueSet),
 		latencyMeasurer: NewLatencyMeasurer(logger.Named("latency-measurer")),
 
 		publishesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -711,7 +686,7 @@ func newWithoutListener(logger slog.Logger, db *sql.DB) *PGPubsub {
 			Namespace: "coder",
 			Subsystem: "pubsub",
 			Name:      "messages_total",
-			Help:      "Total number of messages received from postgres",
+			Help:      "Total number of messages received from " + backend,
 		}, []string{"size"}),
 		publishedBytesTotal: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: "coder",
@@ -729,13 +704,101 @@ func newWithoutListener(logger slog.Logger, db *sql.DB) *PGPubsub {
 			Namespace: "coder",
 			Subsystem: "pubsub",
 			Name:      "disconnections_total",
-			Help:      "Total number of times we disconnected unexpectedly from postgres",
+			Help:      "Total number of times we disconnected unexpectedly from " + backend,
 		}),
 		connected: prometheus.NewGauge(prometheus.GaugeOpts{
 			Namespace: "coder",
 			Subsystem: "pubsub",
 			Name:      "connected",
-			Help:      "Whether we are connected (1) or not connected (0) to postgres",
+			Help:      "Whether we are connected (1) or not connected (0) to " + backend,
 		}),
 	}
 }
+
+// recordMessage records the receipt of a message of the given size.
+func (m *metrics) recordMessage(size int) {
+	sizeLabel := messageSizeNormal
+	if size >= colossalThreshold {
+		sizeLabel = messageSizeColossal
+	}
+	m.messagesTotal.WithLabelValues(sizeLabel).Inc()
+	m.receivedBytesTotal.Add(float64(size))
+}
+
+func (m *metrics) describe(descs chan<- *prometheus.Desc) {
+	// explicit metrics
+	m.publishesTotal.Describe(descs)
+	m.subscribesTotal.Describe(descs)
+	m.messagesTotal.Describe(descs)
+	m.publishedBytesTotal.Describe(descs)
+	m.receivedBytesTotal.Describe(descs)
+	m.disconnectionsTotal.Describe(descs)
+	m.connected.Describe(descs)
+
+	// implicit metrics
+	descs <- currentSubscribersDesc
+	descs <- currentEventsDesc
+
+	// additional metrics
+	descs <- pubsubSendLatencyDesc
+	descs <- pubsubRecvLatencyDesc
+	descs <- pubsubLatencyMeasureCountDesc
+	descs <- pubsubLatencyMeasureErrDesc
+}
+
+// collect collects the metrics, measuring the latency of ps. subs and events
+// are the current number of subscribers and event channels of ps.
+func (m *metrics) collect(metrics chan<- prometheus.Metric, ps Pubsub, subs, events int) {
+	// explicit metrics
+	m.publishesTotal.Collect(metrics)
+	m.subscribesTotal.Collect(metrics)
+	m.messagesTotal.Collect(metrics)
+	m.publishedBytesTotal.Collect(metrics)
+	m.receivedBytesTotal.Collect(metrics)
+	m.disconnectionsTotal.Collect(metrics)
+	m.connected.Collect(metrics)
+
+	// implicit metrics
+	metrics <- prometheus.MustNewConstMetric(currentSubscribersDesc, prometheus.GaugeValue, float64(subs))
+	metrics <- prometheus.MustNewConstMetric(currentEventsDesc, prometheus.GaugeValue, float64(events))
+
+	// additional metrics
+	ctx, cancel := context.WithTimeout(context.Background(), LatencyMeasureTimeout)
+	defer cancel()
+	send, recv, err := m.latencyMeasurer.Measure(ctx, ps)
+
+	metrics <- prometheus.MustNewConstMetric(pubsubLatencyMeasureCountDesc, prometheus.CounterValue, float64(m.latencyMeasureCounter.Add(1)))
+	if err != nil {
+		m.logger.Warn(context.Background(), "failed to measure latency", slog.Error(err))
+		metrics <- prometheus.MustNewConstMetric(pubsubLatencyMeasureErrDesc, prometheus.CounterValue, float64(m.latencyErrCounter.Add(1)))
+		return
+	}
+	metrics <- prometheus.MustNewConstMetric(pubsubSendLatencyDesc, prometheus.GaugeValue, send.Seconds())
+	metrics <- prometheus.MustNewConstMetric(pubsubRecvLatencyDesc, prometheus.GaugeValue, recv.Seconds())
+}
+
+// New creates a new Pubsub implementation using a PostgreSQL connection.
+func New(startCtx context.Context, logger slog.Logger, db *sql.DB, connectURL string) (*PGPubsub, error) {
+	p := newWithoutListener(logger, db)
+	if err := p.startListener(startCtx, connectURL); err != nil {
+		return nil, err
+	}
+	go p.listen()
+	gcCtx, cancel := context.WithCancel(context.Background())
+	p.cancelSpilloverGC = cancel
+	p.spilloverGCDone = make(chan struct{})
+	go p.gcSpillover(gcCtx)
+	logger.Debug(startCtx, "pubsub has started")
+	return p, nil
+}
+
+// newWithoutListener creates a new PGPubsub without creating the pqListener.
+func newWithoutListener(logger slog.Logger, db *sql.DB) *PGPubsub {
+	return &PGPubsub{
+		logger:     logger,
+		listenDone: make(chan struct{}),
+		db:         db,
+		queues:     make(map[string]*queueSet),
+		metrics:    newMetrics(logger, "postgres"),
+	}
+}
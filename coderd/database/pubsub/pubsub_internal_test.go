@@ -1,11 +1,13 @@
 package pubsub
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sync"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -315,3 +317,31 @@ func (f *fakePqListener) requireIsListening(t testing.TB, s string) {
 	_, ok := f.channels[s]
 	require.True(t, ok, "should be listening for '%s', but isn't", s)
 }
+
+func TestSpilloverRef(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	ref := spilloverRef(id)
+	require.Less(t, len(ref), notifyPayloadLimit)
+	got, ok := parseSpilloverRef(ref)
+	require.True(t, ok)
+	require.Equal(t, id, got)
+
+	for _, message := range [][]byte{
+		nil,
+		[]byte("hello"),
+		[]byte(id.String()),
+		append(bytes.Clone(spilloverPrefix), "not-a-uuid"...),
+	} {
+		_, ok := parseSpilloverRef(message)
+		require.False(t, ok, "message %q", message)
+	}
+
+	require.False(t, needsSpillover([]byte("hello")))
+	require.False(t, needsSpillover(make([]byte, notifyPayloadLimit-1)))
+	require.True(t, needsSpillover(make([]byte, notifyPayloadLimit)))
+	// Messages that look like references must be spilled, so that they
+	// aren't mistaken for one by subscribers.
+	require.True(t, needsSpillover(ref))
+}
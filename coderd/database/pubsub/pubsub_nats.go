@@ -0,0 +1,287 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// natsSubjectPrefix is prepended to the subject of every event so that Coder
+// can share a NATS deployment with other applications.
+const natsSubjectPrefix = "coder.pubsub."
+
+// natsFlushTimeout is how long Subscribe waits for the NATS server to
+// acknowledge a new subscription.
+const natsFlushTimeout = 10 * time.Second
+
+type natsQueueSet struct {
+	sub *nats.Subscription
+	m   map[*msgQueue]struct{}
+}
+
+// NATSPubsub is a pubsub implementation using NATS. Unlike PGPubsub, payloads
+// are only limited by the max_payload setting of the NATS server (1MB by
+// default), and the server handles fan out to each subscribing replica.
+type NATSPubsub struct {
+	logger slog.Logger
+	conn   *nats.Conn
+
+	qMu    sync.Mutex
+	queues map[string]*natsQueueSet
+
+	*metrics
+}
+
+// NewNATS creates a new Pubsub implementation connected to the NATS server at
+// the given URL. Multiple comma-separated server URLs may be given.
+func NewNATS(ctx context.Context, logger slog.Logger, url string, opts ...nats.Option) (*NATSPubsub, error) {
+	p := &NATSPubsub{
+		logger:  logger,
+		queues:  make(map[string]*natsQueueSet),
+		metrics: newMetrics(logger, "nats"),
+	}
+
+	opts = append([]nats.Option{
+		nats.Name("coder"),
+		// Never give up reconnecting, the watchdog will terminate coderd
+		// if the pubsub is down for too long.
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(conn *nats.Conn, err error) {
+			if conn.IsClosed() {
+				// This is called when we close the connection ourselves.
+				p.connected.Set(0)
+				return
+			}
+			p.logger.Error(context.Background(), "pubsub disconnected from nats", slog.Error(err))
+			p.connected.Set(0)
+			p.disconnectionsTotal.Inc()
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			p.logger.Info(context.Background(), "pubsub reconnected to nats", slog.F("url", conn.ConnectedUrlRedacted()))
+			p.connected.Set(1)
+			// Messages published while we were disconnected are lost.
+			p.recordReconnect()
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			p.logger.Error(context.Background(), "pubsub nats error", slog.Error(err))
+			if sub != nil && xerrors.Is(err, nats.ErrSlowConsumer) {
+				p.recordDropped(sub)
+			}
+		}),
+	}, opts...)
+
+	type result struct {
+		conn *nats.Conn
+		err  error
+	}
+	// nats.Connect doesn't take a context, so abandon the connection if the
+	// context is canceled first.
+	resCh := make(chan result, 1)
+	go func() {
+		conn, err := nats.Connect(url, opts...)
+		resCh <- result{conn: conn, err: err}
+	}()
+	select {
+	case res := <-resCh:
+		if res.err != nil {
+			return nil, xerrors.Errorf("connect to nats: %w", res.err)
+		}
+		p.conn = res.conn
+	case <-ctx.Done():
+		go func() {
+			if res := <-resCh; res.conn != nil {
+				res.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+	p.connected.Set(1)
+	logger.Debug(ctx, "pubsub connected to nats", slog.F("url", p.conn.ConnectedUrlRedacted()))
+	return p, nil
+}
+
+// Subscribe calls the listener when an event matching the name is received.
+func (p *NATSPubsub) Subscribe(event string, listener Listener) (cancel func(), err error) {
+	return p.subscribeQueue(event, newMsgQueue(context.Background(), listener, nil))
+}
+
+func (p *NATSPubsub) SubscribeWithErr(event string, listener ListenerWithErr) (cancel func(), err error) {
+	return p.subscribeQueue(event, newMsgQueue(context.Background(), nil, listener))
+}
+
+func (p *NATSPubsub) subscribeQueue(event string, newQ *msgQueue) (cancel func(), err error) {
+	defer func() {
+		if err != nil {
+			// if we hit an error, we need to close the queue so we don't
+			// leak its goroutine.
+			newQ.close()
+			p.subscribesTotal.WithLabelValues("false").Inc()
+		} else {
+			p.subscribesTotal.WithLabelValues("true").Inc()
+		}
+	}()
+
+	// Subscribing and unsubscribing only buffers the request to the NATS
+	// server, so unlike PGPubsub it's safe to hold the lock while doing so.
+	p.qMu.Lock()
+	qs, ok := p.queues[event]
+	if !ok {
+		sub, err := p.conn.Subscribe(natsSubject(event), func(msg *nats.Msg) {
+			p.receive(event, msg.Sub, msg.Data)
+		})
+		if err != nil {
+			p.qMu.Unlock()
+			return nil, xerrors.Errorf("subscribe: %w", err)
+		}
+		// Messages are only buffered by the msgQueue of each subscriber,
+		// which reports dropped messages itself.
+		err = sub.SetPendingLimits(-1, -1)
+		if err != nil {
+			_ = sub.Unsubscribe()
+			p.qMu.Unlock()
+			return nil, xerrors.Errorf("set pending limits: %w", err)
+		}
+		qs = &natsQueueSet{sub: sub, m: make(map[*msgQueue]struct{})}
+		p.queues[event] = qs
+		p.logger.Debug(context.Background(), "started listening to event channel", slog.F("event", event))
+	}
+	qs.m[newQ] = struct{}{}
+	p.qMu.Unlock()
+
+	cancel = func() {
+		p.qMu.Lock()
+		defer p.qMu.Unlock()
+		newQ.close()
+		qSet, ok := p.queues[event]
+		if !ok {
+			return
+		}
+		delete(qSet.m, newQ)
+		if len(qSet.m) > 0 {
+			return
+		}
+		delete(p.queues, event)
+		err := qSet.sub.Unsubscribe()
+		if err != nil && !p.conn.IsClosed() {
+			p.logger.Warn(context.Background(), "failed to unsubscribe", slog.Error(err), slog.F("event", event))
+		} else {
+			p.logger.Debug(context.Background(), "stopped listening to event channel", slog.F("event", event))
+		}
+	}
+
+	// Wait for the server to process the subscription, so that messages
+	// published after we return are guaranteed to be received.
+	ctx, cancelFlush := context.WithTimeout(context.Background(), natsFlushTimeout)
+	defer cancelFlush()
+	err = p.conn.FlushWithContext(ctx)
+	if err != nil {
+		cancel()
+		return nil, xerrors.Errorf("flush subscription: %w", err)
+	}
+	return cancel, nil
+}
+
+func (p *NATSPubsub) Publish(event string, message []byte) error {
+	p.logger.Debug(context.Background(), "publish", slog.F("event", event), slog.F("message_len", len(message)))
+	err := p.conn.Publish(natsSubject(event), message)
+	if err != nil {
+		p.publishesTotal.WithLabelValues("false").Inc()
+		return xerrors.Errorf("publish to nats: %w", err)
+	}
+	p.publishesTotal.WithLabelValues("true").Inc()
+	p.publishedBytesTotal.Add(float64(len(message)))
+	return nil
+}
+
+// Close closes the pubsub instance.
+func (p *NATSPubsub) Close() error {
+	p.logger.Info(context.Background(), "pubsub is closing")
+	p.conn.Close()
+	p.logger.Debug(context.Background(), "pubsub closed")
+	return nil
+}
+
+func (p *NATSPubsub) receive(event string, sub *nats.Subscription, message []byte) {
+	p.recordMessage(len(message))
+
+	p.qMu.Lock()
+	defer p.qMu.Unlock()
+	qSet, ok := p.queues[event]
+	// If all subscribers canceled and a new subscriber arrived while this
+	// message was pending, it belongs to the old subscription.
+	if !ok || qSet.sub != sub {
+		return
+	}
+	for q := range qSet.m {
+		q.enqueue(message)
+	}
+}
+
+func (p *NATSPubsub) recordReconnect() {
+	p.qMu.Lock()
+	defer p.qMu.Unlock()
+	for _, qSet := range p.queues {
+		for q := range qSet.m {
+			q.dropped()
+		}
+	}
+}
+
+// recordDropped notifies the subscribers of sub that messages were dropped.
+func (p *NATSPubsub) recordDropped(sub *nats.Subscription) {
+	p.qMu.Lock()
+	defer p.qMu.Unlock()
+	for _, qSet := range p.queues {
+		if qSet.sub != sub {
+			continue
+		}
+		for q := range qSet.m {
+			q.dropped()
+		}
+	}
+}
+
+// Describe implements, along with Collect, the prometheus.Collector interface
+// for metrics.
+func (p *NATSPubsub) Describe(descs chan<- *prometheus.Desc) {
+	p.describe(descs)
+}
+
+// Collect implements, along with Describe, the prometheus.Collector interface
+// for metrics
+func (p *NATSPubsub) Collect(metrics chan<- prometheus.Metric) {
+	p.qMu.Lock()
+	events := len(p.queues)
+	subs := 0
+	for _, qSet := range p.queues {
+		subs += len(qSet.m)
+	}
+	p.qMu.Unlock()
+	p.collect(metrics, p, subs, events)
+}
+
+// natsSubject returns the NATS subject for an event. Events are arbitrary
+// strings, but NATS subjects can't contain whitespace and use '.', '*' and
+// '>' as token separators and wildcards, so those are percent-encoded.
+func natsSubject(event string) string {
+	var sb strings.Builder
+	sb.Grow(len(natsSubjectPrefix) + len(event))
+	_, _ = sb.WriteString(natsSubjectPrefix)
+	for i := 0; i < len(event); i++ {
+		c := event[i]
+		if c <= ' ' || c >= 0x7f || c == '.' || c == '*' || c == '>' || c == '%' {
+			_, _ = fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+		_ = sb.WriteByte(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,266 @@
+package pubsub_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/coderd/database/pubsub"
+	"github.com/coder/coder/v2/testutil"
+	"github.com/coder/quartz"
+)
+
+func TestNATSPubsub(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PublishSubscribe", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		srv := newNATSServer(t)
+		uut, err := pubsub.NewNATS(ctx, testutil.Logger(t), srv.url)
+		require.NoError(t, err)
+		defer uut.Close()
+
+		messages := make(chan []byte, 1)
+		cancel, err := uut.Subscribe("test", func(_ context.Context, message []byte) {
+			messages <- message
+		})
+		require.NoError(t, err)
+		defer cancel()
+
+		// Payloads over the PostgreSQL NOTIFY limit are fine.
+		big := []byte(strings.Repeat("q", 64<<10))
+		require.NoError(t, uut.Publish("test", []byte("hello")))
+		require.NoError(t, uut.Publish("test", big))
+		require.Equal(t, []byte("hello"), testutil.TryReceive(ctx, t, messages))
+		require.Equal(t, big, testutil.TryReceive(ctx, t, messages))
+	})
+
+	t.Run("Replicas", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		srv := newNATSServer(t)
+		publisher, err := pubsub.NewNATS(ctx, testutil.Logger(t), srv.url)
+		require.NoError(t, err)
+		defer publisher.Close()
+		subscriber, err := pubsub.NewNATS(ctx, testutil.Logger(t), srv.url)
+		require.NoError(t, err)
+		defer subscriber.Close()
+
+		messages := make(chan []byte, 1)
+		cancel, err := subscriber.Subscribe("test", func(_ context.Context, message []byte) {
+			messages <- message
+		})
+		require.NoError(t, err)
+		defer cancel()
+
+		require.NoError(t, publisher.Publish("test", []byte("hello")))
+		require.Equal(t, []byte("hello"), testutil.TryReceive(ctx, t, messages))
+	})
+
+	t.Run("EventNames", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		srv := newNATSServer(t)
+		uut, err := pubsub.NewNATS(ctx, testutil.Logger(t), srv.url)
+		require.NoError(t, err)
+		defer uut.Close()
+
+		// None of these must be interpreted as NATS wildcards or separators.
+		events := []string{"a.b", "a%2Eb", "a*", "a>", "a b", "a"}
+		received := make(chan string, len(events))
+		for _, event := range events {
+			cancel, err := uut.Subscribe(event, func(_ context.Context, message []byte) {
+				if string(message) != event {
+					received <- fmt.Sprintf("%q received message for %q", event, message)
+					return
+				}
+				received <- ""
+			})
+			require.NoError(t, err)
+			defer cancel()
+		}
+		for _, event := range events {
+			require.NoError(t, uut.Publish(event, []byte(event)))
+		}
+		for range events {
+			require.Empty(t, testutil.TryReceive(ctx, t, received))
+		}
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		srv := newNATSServer(t)
+		uut, err := pubsub.NewNATS(ctx, testutil.Logger(t), srv.url)
+		require.NoError(t, err)
+		defer uut.Close()
+
+		canceled := make(chan []byte, 1)
+		cancel, err := uut.Subscribe("test", func(_ context.Context, message []byte) {
+			canceled <- message
+		})
+		require.NoError(t, err)
+		cancel()
+
+		messages := make(chan []byte, 1)
+		cancel, err = uut.Subscribe("test", func(_ context.Context, message []byte) {
+			messages <- message
+		})
+		require.NoError(t, err)
+		defer cancel()
+
+		require.NoError(t, uut.Publish("test", []byte("hello")))
+		require.Equal(t, []byte("hello"), testutil.TryReceive(ctx, t, messages))
+		require.Empty(t, canceled)
+	})
+
+	t.Run("Reconnect", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		srv := newNATSServer(t)
+		logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}).Leveled(slog.LevelDebug)
+		uut, err := pubsub.NewNATS(ctx, logger, srv.url, nats.ReconnectWait(testutil.IntervalFast))
+		require.NoError(t, err)
+		defer uut.Close()
+
+		messages := make(chan []byte, 1)
+		errs := make(chan error, 1)
+		cancel, err := uut.SubscribeWithErr("test", func(_ context.Context, message []byte, err error) {
+			if err != nil {
+				errs <- err
+				return
+			}
+			messages <- message
+		})
+		require.NoError(t, err)
+		defer cancel()
+
+		srv.disconnectAll(t)
+		require.ErrorIs(t, testutil.TryReceive(ctx, t, errs), pubsub.ErrDroppedMessages)
+
+		// The subscription is restored after reconnecting.
+		require.NoError(t, uut.Publish("test", []byte("hello")))
+		require.Equal(t, []byte("hello"), testutil.TryReceive(ctx, t, messages))
+	})
+}
+
+func TestNATSPubsub_Metrics(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	srv := newNATSServer(t)
+	uut, err := pubsub.NewNATS(ctx, testutil.Logger(t), srv.url)
+	require.NoError(t, err)
+	defer uut.Close()
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(uut))
+
+	messages := make(chan []byte, 1)
+	cancel, err := uut.Subscribe("test", func(_ context.Context, message []byte) {
+		messages <- message
+	})
+	require.NoError(t, err)
+	defer cancel()
+	go func() {
+		assert.NoError(t, uut.Publish("test", []byte("testing")))
+	}()
+	_ = testutil.TryReceive(ctx, t, messages)
+
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+	require.True(t, testutil.PromGaugeHasValue(t, metrics, 1, "coder_pubsub_current_events"))
+	require.True(t, testutil.PromGaugeHasValue(t, metrics, 1, "coder_pubsub_current_subscribers"))
+	require.True(t, testutil.PromGaugeHasValue(t, metrics, 1, "coder_pubsub_connected"))
+	require.True(t, testutil.PromCounterHasValue(t, metrics, 1, "coder_pubsub_publishes_total", "true"))
+	require.True(t, testutil.PromCounterHasValue(t, metrics, 1, "coder_pubsub_messages_total", "normal"))
+	require.True(t, testutil.PromGaugeAssertion(t, metrics, func(in float64) bool { return in > 0 }, "coder_pubsub_send_latency_seconds"))
+	require.True(t, testutil.PromGaugeAssertion(t, metrics, func(in float64) bool { return in > 0 }, "coder_pubsub_receive_latency_seconds"))
+	require.True(t, testutil.PromCounterHasValue(t, metrics, 1, "coder_pubsub_latency_measures_total"))
+	require.False(t, testutil.PromCounterGathered(t, metrics, "coder_pubsub_latency_measure_errs_total"))
+}
+
+func TestNATSPubsub_Watchdog(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	srv := newNATSServer(t)
+	uut, err := pubsub.NewNATS(ctx, testutil.Logger(t), srv.url)
+	require.NoError(t, err)
+	defer uut.Close()
+
+	heartbeats := make(chan []byte, 1)
+	cancel, err := uut.Subscribe(pubsub.EventPubsubWatchdog, func(_ context.Context, message []byte) {
+		select {
+		case heartbeats <- message:
+		default:
+		}
+	})
+	require.NoError(t, err)
+	defer cancel()
+
+	mClock := quartz.NewMock(t)
+	pubTrap := mClock.Trap().TickerFunc("publish")
+	defer pubTrap.Close()
+	w := pubsub.NewWatchdogWithClock(ctx, testutil.Logger(t), uut, mClock)
+	defer w.Close()
+	pc, err := pubTrap.Wait(ctx)
+	require.NoError(t, err)
+	pc.Release()
+
+	// The heartbeat is published through NATS.
+	mClock.Advance(15 * time.Second).MustWait(ctx)
+	_ = testutil.TryReceive(ctx, t, heartbeats)
+	select {
+	case <-w.Timeout():
+		t.Fatal("watchdog timed out")
+	default:
+	}
+}
+
+// natsServer is an embedded NATS server.
+type natsServer struct {
+	*server.Server
+	url string
+}
+
+func newNATSServer(t testing.TB) *natsServer {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+	require.True(t, srv.ReadyForConnections(testutil.WaitShort), "NATS server did not start")
+	return &natsServer{Server: srv, url: srv.ClientURL()}
+}
+
+// disconnectAll closes the connections of all clients, which reconnect.
+func (s *natsServer) disconnectAll(t testing.TB) {
+	t.Helper()
+	connz, err := s.Connz(&server.ConnzOptions{})
+	require.NoError(t, err)
+	require.NotEmpty(t, connz.Conns)
+	for _, conn := range connz.Conns {
+		err := s.DisconnectClientByID(conn.Cid)
+		require.NoError(t, err)
+	}
+}
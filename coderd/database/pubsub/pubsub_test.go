@@ -3,9 +3,11 @@ package pubsub_test
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -120,6 +122,50 @@ func TestPGPubsub_Metrics(t *testing.T) {
 	}, testutil.WaitShort, testutil.IntervalFast)
 }
 
+func TestPGPubsub_Spillover(t *testing.T) {
+	t.Parallel()
+	if !dbtestutil.WillUsePostgres() {
+		t.Skip("test only with postgres")
+	}
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	logger := testutil.Logger(t)
+	connectionURL, err := dbtestutil.Open(t)
+	require.NoError(t, err)
+	db, err := sql.Open("postgres", connectionURL)
+	require.NoError(t, err)
+	defer db.Close()
+	uut, err := pubsub.New(ctx, logger, db, connectionURL)
+	require.NoError(t, err)
+	defer uut.Close()
+
+	messages := make(chan []byte, 3)
+	cancel, err := uut.Subscribe("test", func(_ context.Context, message []byte) {
+		messages <- message
+	})
+	require.NoError(t, err)
+	defer cancel()
+
+	// Too large for a NOTIFY payload.
+	large := []byte(strings.Repeat("q", 100_000))
+	// Looks like a reference to a spilled message, but isn't one.
+	fake := []byte("\x01coder_pubsub_spillover:" + uuid.NewString())
+
+	require.NoError(t, uut.Publish("test", []byte("small")))
+	require.NoError(t, uut.Publish("test", large))
+	require.NoError(t, uut.Publish("test", fake))
+
+	// Order is preserved.
+	require.Equal(t, []byte("small"), testutil.TryReceive(ctx, t, messages))
+	require.Equal(t, large, testutil.TryReceive(ctx, t, messages))
+	require.Equal(t, fake, testutil.TryReceive(ctx, t, messages))
+
+	var spilled int
+	err = db.QueryRowContext(ctx, `SELECT count(*) FROM pubsub_spillover WHERE event = 'test'`).Scan(&spilled)
+	require.NoError(t, err)
+	require.Equal(t, 2, spilled)
+}
+
 func TestPGPubsubDriver(t *testing.T) {
 	t.Parallel()
 	if !dbtestutil.WillUsePostgres() {
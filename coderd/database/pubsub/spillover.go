@@ -0,0 +1,141 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+const (
+	// notifyPayloadLimit is the maximum size of a NOTIFY payload in bytes.
+	// Larger messages are stored in the pubsub_spillover table, and only a
+	// reference to the row is sent.
+	notifyPayloadLimit = 8000
+	// spilloverRetention is how long spilled messages are kept. Subscribers
+	// that haven't read a message by then get ErrDroppedMessages instead.
+	spilloverRetention = 5 * time.Minute
+	// spilloverGCInterval is how often expired spilled messages are deleted.
+	spilloverGCInterval = time.Minute
+	// spilloverFetchTimeout is how long a subscriber waits for a spilled
+	// message to be read from the database.
+	spilloverFetchTimeout = 10 * time.Second
+)
+
+// spilloverPrefix prefixes the references to spilled messages that are sent
+// in place of the message. It starts with a control character so it's very
+// unlikely to be the prefix of a real message, but messages that do start
+// with it are spilled too so they can't be mistaken for references.
+var spilloverPrefix = []byte("\x01coder_pubsub_spillover:")
+
+func needsSpillover(message []byte) bool {
+	return len(message) >= notifyPayloadLimit || bytes.HasPrefix(message, spilloverPrefix)
+}
+
+func spilloverRef(id uuid.UUID) []byte {
+	return append(bytes.Clone(spilloverPrefix), id.String()...)
+}
+
+// parseSpilloverRef returns the ID of the spilled message if message is a
+// reference to one.
+func parseSpilloverRef(message []byte) (uuid.UUID, bool) {
+	rest, ok := bytes.CutPrefix(message, spilloverPrefix)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.ParseBytes(rest)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// publishSpillover stores the message in the database and notifies
+// subscribers of a reference to it. Both happen in a single statement, so the
+// message is committed before the notification is delivered.
+func (p *PGPubsub) publishSpillover(event string, message []byte) error {
+	id := uuid.New()
+	// This is safe because we are calling pq.QuoteLiteral. pg_notify doesn't
+	// support the first parameter being a prepared statement.
+	//nolint:gosec
+	_, err := p.db.ExecContext(context.Background(), `
+		WITH spilled AS (
+			INSERT INTO pubsub_spillover (id, event, payload) VALUES ($1, $2, $3) RETURNING id
+		)
+		SELECT pg_notify(`+pq.QuoteLiteral(event)+`, $4) FROM spilled`,
+		id, event, message, string(spilloverRef(id)))
+	if err != nil {
+		return xerrors.Errorf("spill message: %w", err)
+	}
+	return nil
+}
+
+// fetchSpillover reads a spilled message from the database. Subscribers of
+// the same event on this replica share a single query.
+func (p *PGPubsub) fetchSpillover(ctx context.Context, event string, id uuid.UUID) ([]byte, error) {
+	payload, err, _ := p.spilloverFetches.Do(id.String(), func() ([]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, spilloverFetchTimeout)
+		defer cancel()
+		var payload []byte
+		err := p.db.QueryRowContext(ctx,
+			`SELECT payload FROM pubsub_spillover WHERE id = $1 AND event = $2`, id, event,
+		).Scan(&payload)
+		return payload, err
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("fetch spilled message %s: %w", id, err)
+	}
+	return payload, nil
+}
+
+// resolveSpillover wraps the listener so that references to spilled messages
+// are replaced by the messages themselves. Messages that can't be fetched are
+// reported as dropped.
+func (p *PGPubsub) resolveSpillover(event string, listener ListenerWithErr) ListenerWithErr {
+	return func(ctx context.Context, message []byte, err error) {
+		if err == nil {
+			if id, ok := parseSpilloverRef(message); ok {
+				message, err = p.fetchSpillover(ctx, event, id)
+				if err != nil {
+					p.logger.Warn(ctx, "failed to fetch spilled pubsub message", slog.F("event", event), slog.Error(err))
+					message, err = nil, ErrDroppedMessages
+				}
+			}
+		}
+		listener(ctx, message, err)
+	}
+}
+
+// gcSpillover periodically deletes expired spilled messages. Every replica
+// does this, which is harmless since the deletes are idempotent.
+func (p *PGPubsub) gcSpillover(ctx context.Context) {
+	defer close(p.spilloverGCDone)
+	ticker := time.NewTicker(spilloverGCInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := p.deleteExpiredSpillover(ctx)
+		if err != nil && ctx.Err() == nil {
+			p.logger.Warn(ctx, "failed to delete expired spilled pubsub messages", slog.Error(err))
+		}
+	}
+}
+
+func (p *PGPubsub) deleteExpiredSpillover(ctx context.Context) error {
+	_, err := p.db.ExecContext(ctx,
+		`DELETE FROM pubsub_spillover WHERE created_at < now() - ($1 * interval '1 second')`,
+		int64(spilloverRetention/time.Second))
+	if err != nil {
+		return xerrors.Errorf("delete expired spilled messages: %w", err)
+	}
+	return nil
+}
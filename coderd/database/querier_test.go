@@ -1313,6 +1313,127 @@ func TestQueuePosition(t *testing.T) {
 	}
 }
 
+func TestAcquireProvisionerJobOrder(t *testing.T) {
+	t.Parallel()
+
+	acquire := func(ctx context.Context, t *testing.T, db database.Store, orgID uuid.UUID) database.ProvisionerJob {
+		t.Helper()
+		job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			OrganizationID: orgID,
+			StartedAt: sql.NullTime{
+				Time:  dbtime.Now(),
+				Valid: true,
+			},
+			Types: database.AllProvisionerTypeValues(),
+			WorkerID: uuid.NullUUID{
+				UUID:  uuid.New(),
+				Valid: true,
+			},
+			ProvisionerTags: json.RawMessage("{}"),
+		})
+		require.NoError(t, err)
+		return job
+	}
+
+	t.Run("Priority", func(t *testing.T) {
+		t.Parallel()
+		db, _ := dbtestutil.NewDB(t)
+		ctx := testutil.Context(t, testutil.WaitShort)
+		org := dbgen.Organization(t, db, database.Organization{})
+		now := dbtime.Now()
+
+		// Queue the lowest priority jobs first, so acquiring by age would
+		// return them in the opposite order.
+		var jobs []database.ProvisionerJob
+		for i, priority := range database.AllProvisionerJobPriorityValues() {
+			jobs = append(jobs, dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+				OrganizationID: org.ID,
+				CreatedAt:      now.Add(time.Duration(i) * time.Second),
+				Tags:           database.StringMap{},
+				Priority:       priority,
+			}))
+		}
+
+		for i := len(jobs) - 1; i >= 0; i-- {
+			job := acquire(ctx, t, db, org.ID)
+			require.Equal(t, jobs[i].ID, job.ID, "expected %s job", jobs[i].Priority)
+		}
+	})
+
+	t.Run("FairShare", func(t *testing.T) {
+		t.Parallel()
+		db, _ := dbtestutil.NewDB(t)
+		ctx := testutil.Context(t, testutil.WaitShort)
+		org := dbgen.Organization(t, db, database.Organization{})
+		busyUser := dbgen.User(t, db, database.User{})
+		idleUser := dbgen.User(t, db, database.User{})
+		now := dbtime.Now()
+
+		// The busy user already has a job running.
+		dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			InitiatorID:    busyUser.ID,
+			CreatedAt:      now.Add(-time.Minute),
+			StartedAt:      sql.NullTime{Time: now, Valid: true},
+		})
+		busyJob := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			InitiatorID:    busyUser.ID,
+			CreatedAt:      now,
+			Tags:           database.StringMap{},
+		})
+		idleJob := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			InitiatorID:    idleUser.ID,
+			CreatedAt:      now.Add(time.Second),
+			Tags:           database.StringMap{},
+		})
+
+		// The idle user's job is newer, but goes first because the busy user
+		// is already running a job.
+		job := acquire(ctx, t, db, org.ID)
+		require.Equal(t, idleJob.ID, job.ID)
+		job = acquire(ctx, t, db, org.ID)
+		require.Equal(t, busyJob.ID, job.ID)
+	})
+
+	t.Run("QueuePosition", func(t *testing.T) {
+		t.Parallel()
+		db, _ := dbtestutil.NewDB(t)
+		ctx := testutil.Context(t, testutil.WaitShort)
+		org := dbgen.Organization(t, db, database.Organization{})
+		now := dbtime.Now()
+
+		bulkJob := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			CreatedAt:      now,
+			Tags:           database.StringMap{},
+			Priority:       database.ProvisionerJobPriorityBulk,
+		})
+		interactiveJob := dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			CreatedAt:      now.Add(time.Second),
+			Tags:           database.StringMap{},
+			Priority:       database.ProvisionerJobPriorityInteractive,
+		})
+		dbgen.ProvisionerDaemon(t, db, database.ProvisionerDaemon{
+			OrganizationID: org.ID,
+			Provisioners:   []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Tags:           database.StringMap{},
+		})
+
+		queued, err := db.GetProvisionerJobsByIDsWithQueuePosition(ctx, []uuid.UUID{bulkJob.ID, interactiveJob.ID})
+		require.NoError(t, err)
+		require.Len(t, queued, 2)
+		positions := map[uuid.UUID]int64{}
+		for _, job := range queued {
+			positions[job.ProvisionerJob.ID] = job.QueuePosition
+		}
+		require.Equal(t, int64(1), positions[interactiveJob.ID])
+		require.Equal(t, int64(2), positions[bulkJob.ID])
+	})
+}
+
 func TestUserLastSeenFilter(t *testing.T) {
 	t.Parallel()
 	if testing.Short() {
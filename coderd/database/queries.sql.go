@@ -146,7 +146,7 @@ func (q *sqlQuerier) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context
 
 const getAPIKeyByID = `-- name: GetAPIKeyByID :one
 SELECT
-	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, allow_list
 FROM
 	api_keys
 WHERE
@@ -171,13 +171,14 @@ func (q *sqlQuerier) GetAPIKeyByID(ctx context.Context, id string) (APIKey, erro
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		pq.Array(&i.AllowList),
 	)
 	return i, err
 }
 
 const getAPIKeyByName = `-- name: GetAPIKeyByName :one
 SELECT
-	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, allow_list
 FROM
 	api_keys
 WHERE
@@ -210,12 +211,13 @@ func (q *sqlQuerier) GetAPIKeyByName(ctx context.Context, arg GetAPIKeyByNamePar
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		pq.Array(&i.AllowList),
 	)
 	return i, err
 }
 
 const getAPIKeysByLoginType = `-- name: GetAPIKeysByLoginType :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE login_type = $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, allow_list FROM api_keys WHERE login_type = $1
 `
 
 func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginType) ([]APIKey, error) {
@@ -240,6 +242,7 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			pq.Array(&i.AllowList),
 		); err != nil {
 			return nil, err
 		}
@@ -255,7 +258,7 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 }
 
 const getAPIKeysByUserID = `-- name: GetAPIKeysByUserID :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE login_type = $1 AND user_id = $2
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, allow_list FROM api_keys WHERE login_type = $1 AND user_id = $2
 `
 
 type GetAPIKeysByUserIDParams struct {
@@ -285,6 +288,7 @@ func (q *sqlQuerier) GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUse
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			pq.Array(&i.AllowList),
 		); err != nil {
 			return nil, err
 		}
@@ -300,7 +304,7 @@ func (q *sqlQuerier) GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUse
 }
 
 const getAPIKeysLastUsedAfter = `-- name: GetAPIKeysLastUsedAfter :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE last_used > $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, allow_list FROM api_keys WHERE last_used > $1
 `
 
 func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error) {
@@ -325,6 +329,7 @@ func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			pq.Array(&i.AllowList),
 		); err != nil {
 			return nil, err
 		}
@@ -353,7 +358,8 @@ INSERT INTO
 		updated_at,
 		login_type,
 		scope,
-		token_name
+		token_name,
+		allow_list
 	)
 VALUES
 	($1,
@@ -362,7 +368,7 @@ VALUES
 	     WHEN 0 THEN 86400
 		 ELSE $2::bigint
 	 END
-	 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::text[]) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, allow_list
 `
 
 type InsertAPIKeyParams struct {
@@ -378,6 +384,7 @@ type InsertAPIKeyParams struct {
 	LoginType       LoginType   `db:"login_type" json:"login_type"`
 	Scope           APIKeyScope `db:"scope" json:"scope"`
 	TokenName       string      `db:"token_name" json:"token_name"`
+	AllowList       []string    `db:"allow_list" json:"allow_list"`
 }
 
 func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error) {
@@ -394,6 +401,7 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		arg.LoginType,
 		arg.Scope,
 		arg.TokenName,
+		pq.Array(arg.AllowList),
 	)
 	var i APIKey
 	err := row.Scan(
@@ -409,6 +417,7 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		pq.Array(&i.AllowList),
 	)
 	return i, err
 }
@@ -458,6 +467,7 @@ SELECT
     users.avatar_url AS user_avatar_url,
     users.deleted AS user_deleted,
     users.quiet_hours_schedule AS user_quiet_hours_schedule,
+    users.is_service_account AS user_is_service_account,
     COALESCE(organizations.name, '') AS organization_name,
     COALESCE(organizations.display_name, '') AS organization_display_name,
     COALESCE(organizations.icon, '') AS organization_icon,
@@ -608,6 +618,7 @@ type GetAuditLogsOffsetRow struct {
 	UserAvatarUrl           sql.NullString `db:"user_avatar_url" json:"user_avatar_url"`
 	UserDeleted             sql.NullBool   `db:"user_deleted" json:"user_deleted"`
 	UserQuietHoursSchedule  sql.NullString `db:"user_quiet_hours_schedule" json:"user_quiet_hours_schedule"`
+	UserIsServiceAccount    sql.NullBool   `db:"user_is_service_account" json:"user_is_service_account"`
 	OrganizationName        string         `db:"organization_name" json:"organization_name"`
 	OrganizationDisplayName string         `db:"organization_display_name" json:"organization_display_name"`
 	OrganizationIcon        string         `db:"organization_icon" json:"organization_icon"`
@@ -668,6 +679,7 @@ func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOff
 			&i.UserAvatarUrl,
 			&i.UserDeleted,
 			&i.UserQuietHoursSchedule,
+			&i.UserIsServiceAccount,
 			&i.OrganizationName,
 			&i.OrganizationDisplayName,
 			&i.OrganizationIcon,
@@ -1902,7 +1914,7 @@ func (q *sqlQuerier) DeleteGroupByID(ctx context.Context, id uuid.UUID) error {
 
 const getGroupByID = `-- name: GetGroupByID :one
 SELECT
-	id, name, organization_id, avatar_url, quota_allowance, display_name, source
+	id, name, organization_id, avatar_url, quota_allowance, display_name, source, dlp_policy
 FROM
 	groups
 WHERE
@@ -1922,13 +1934,14 @@ func (q *sqlQuerier) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, err
 		&i.QuotaAllowance,
 		&i.DisplayName,
 		&i.Source,
+		&i.DLPPolicy,
 	)
 	return i, err
 }
 
 const getGroupByOrgAndName = `-- name: GetGroupByOrgAndName :one
 SELECT
-	id, name, organization_id, avatar_url, quota_allowance, display_name, source
+	id, name, organization_id, avatar_url, quota_allowance, display_name, source, dlp_policy
 FROM
 	groups
 WHERE
@@ -1955,13 +1968,14 @@ func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrg
 		&i.QuotaAllowance,
 		&i.DisplayName,
 		&i.Source,
+		&i.DLPPolicy,
 	)
 	return i, err
 }
 
 const getGroups = `-- name: GetGroups :many
 SELECT
-		groups.id, groups.name, groups.organization_id, groups.avatar_url, groups.quota_allowance, groups.display_name, groups.source,
+		groups.id, groups.name, groups.organization_id, groups.avatar_url, groups.quota_allowance, groups.display_name, groups.source, groups.dlp_policy,
 		organizations.name AS organization_name,
 		organizations.display_name AS organization_display_name
 FROM
@@ -2036,6 +2050,7 @@ func (q *sqlQuerier) GetGroups(ctx context.Context, arg GetGroupsParams) ([]GetG
 			&i.Group.QuotaAllowance,
 			&i.Group.DisplayName,
 			&i.Group.Source,
+			&i.Group.DLPPolicy,
 			&i.OrganizationName,
 			&i.OrganizationDisplayName,
 		); err != nil {
@@ -2059,7 +2074,7 @@ INSERT INTO groups (
 	organization_id
 )
 VALUES
-	($1, 'Everyone', $1) RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source
+	($1, 'Everyone', $1) RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source, dlp_policy
 `
 
 // We use the organization_id as the id
@@ -2076,6 +2091,7 @@ func (q *sqlQuerier) InsertAllUsersGroup(ctx context.Context, organizationID uui
 		&i.QuotaAllowance,
 		&i.DisplayName,
 		&i.Source,
+		&i.DLPPolicy,
 	)
 	return i, err
 }
@@ -2090,7 +2106,7 @@ INSERT INTO groups (
 	quota_allowance
 )
 VALUES
-	($1, $2, $3, $4, $5, $6) RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source
+	($1, $2, $3, $4, $5, $6) RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source, dlp_policy
 `
 
 type InsertGroupParams struct {
@@ -2120,6 +2136,7 @@ func (q *sqlQuerier) InsertGroup(ctx context.Context, arg InsertGroupParams) (Gr
 		&i.QuotaAllowance,
 		&i.DisplayName,
 		&i.Source,
+		&i.DLPPolicy,
 	)
 	return i, err
 }
@@ -2139,7 +2156,7 @@ SELECT
 FROM
 						UNNEST($3 :: text[]) AS group_name
 ON CONFLICT DO NOTHING
-RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source
+RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source, dlp_policy
 `
 
 type InsertMissingGroupsParams struct {
@@ -2169,6 +2186,7 @@ func (q *sqlQuerier) InsertMissingGroups(ctx context.Context, arg InsertMissingG
 			&i.QuotaAllowance,
 			&i.DisplayName,
 			&i.Source,
+			&i.DLPPolicy,
 		); err != nil {
 			return nil, err
 		}
@@ -2190,10 +2208,11 @@ SET
 	name = $1,
 	display_name = $2,
 	avatar_url = $3,
-	quota_allowance = $4
+	quota_allowance = $4,
+	dlp_policy = $5
 WHERE
-	id = $5
-RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source
+	id = $6
+RETURNING id, name, organization_id, avatar_url, quota_allowance, display_name, source, dlp_policy
 `
 
 type UpdateGroupByIDParams struct {
@@ -2201,6 +2220,7 @@ type UpdateGroupByIDParams struct {
 	DisplayName    string    `db:"display_name" json:"display_name"`
 	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
 	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
+	DLPPolicy      DLPPolicy `db:"dlp_policy" json:"dlp_policy"`
 	ID             uuid.UUID `db:"id" json:"id"`
 }
 
@@ -2210,6 +2230,7 @@ func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDPar
 		arg.DisplayName,
 		arg.AvatarURL,
 		arg.QuotaAllowance,
+		arg.DLPPolicy,
 		arg.ID,
 	)
 	var i Group
@@ -2221,6 +2242,7 @@ func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDPar
 		&i.QuotaAllowance,
 		&i.DisplayName,
 		&i.Source,
+		&i.DLPPolicy,
 	)
 	return i, err
 }
@@ -6546,7 +6568,7 @@ func (q *sqlQuerier) DeleteOldProvisionerDaemons(ctx context.Context) error {
 
 const getEligibleProvisionerDaemonsByProvisionerJobIDs = `-- name: GetEligibleProvisionerDaemonsByProvisionerJobIDs :many
 SELECT DISTINCT
-    provisioner_jobs.id as job_id, provisioner_daemons.id, provisioner_daemons.created_at, provisioner_daemons.name, provisioner_daemons.provisioners, provisioner_daemons.replica_id, provisioner_daemons.tags, provisioner_daemons.last_seen_at, provisioner_daemons.version, provisioner_daemons.api_version, provisioner_daemons.organization_id, provisioner_daemons.key_id
+    provisioner_jobs.id as job_id, provisioner_daemons.id, provisioner_daemons.created_at, provisioner_daemons.name, provisioner_daemons.provisioners, provisioner_daemons.replica_id, provisioner_daemons.tags, provisioner_daemons.last_seen_at, provisioner_daemons.version, provisioner_daemons.api_version, provisioner_daemons.organization_id, provisioner_daemons.key_id, provisioner_daemons.terraform_engine, provisioner_daemons.drain_requested_at
 FROM
     provisioner_jobs
 JOIN
@@ -6584,6 +6606,8 @@ func (q *sqlQuerier) GetEligibleProvisionerDaemonsByProvisionerJobIDs(ctx contex
 			&i.ProvisionerDaemon.APIVersion,
 			&i.ProvisionerDaemon.OrganizationID,
 			&i.ProvisionerDaemon.KeyID,
+			&i.ProvisionerDaemon.TerraformEngine,
+			&i.ProvisionerDaemon.DrainRequestedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -6598,9 +6622,39 @@ func (q *sqlQuerier) GetEligibleProvisionerDaemonsByProvisionerJobIDs(ctx contex
 	return items, nil
 }
 
+const getProvisionerDaemonByID = `-- name: GetProvisionerDaemonByID :one
+SELECT
+	id, created_at, name, provisioners, replica_id, tags, last_seen_at, version, api_version, organization_id, key_id, terraform_engine, drain_requested_at
+FROM
+	provisioner_daemons
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetProvisionerDaemonByID(ctx context.Context, id uuid.UUID) (ProvisionerDaemon, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerDaemonByID, id)
+	var i ProvisionerDaemon
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Name,
+		pq.Array(&i.Provisioners),
+		&i.ReplicaID,
+		&i.Tags,
+		&i.LastSeenAt,
+		&i.Version,
+		&i.APIVersion,
+		&i.OrganizationID,
+		&i.KeyID,
+		&i.TerraformEngine,
+		&i.DrainRequestedAt,
+	)
+	return i, err
+}
+
 const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
 SELECT
-	id, created_at, name, provisioners, replica_id, tags, last_seen_at, version, api_version, organization_id, key_id
+	id, created_at, name, provisioners, replica_id, tags, last_seen_at, version, api_version, organization_id, key_id, terraform_engine, drain_requested_at
 FROM
 	provisioner_daemons
 `
@@ -6626,6 +6680,8 @@ func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDa
 			&i.APIVersion,
 			&i.OrganizationID,
 			&i.KeyID,
+			&i.TerraformEngine,
+			&i.DrainRequestedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -6642,7 +6698,7 @@ func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDa
 
 const getProvisionerDaemonsByOrganization = `-- name: GetProvisionerDaemonsByOrganization :many
 SELECT
-	id, created_at, name, provisioners, replica_id, tags, last_seen_at, version, api_version, organization_id, key_id
+	id, created_at, name, provisioners, replica_id, tags, last_seen_at, version, api_version, organization_id, key_id, terraform_engine, drain_requested_at
 FROM
 	provisioner_daemons
 WHERE
@@ -6679,6 +6735,8 @@ func (q *sqlQuerier) GetProvisionerDaemonsByOrganization(ctx context.Context, ar
 			&i.APIVersion,
 			&i.OrganizationID,
 			&i.KeyID,
+			&i.TerraformEngine,
+			&i.DrainRequestedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -6695,7 +6753,7 @@ func (q *sqlQuerier) GetProvisionerDaemonsByOrganization(ctx context.Context, ar
 
 const getProvisionerDaemonsWithStatusByOrganization = `-- name: GetProvisionerDaemonsWithStatusByOrganization :many
 SELECT
-	pd.id, pd.created_at, pd.name, pd.provisioners, pd.replica_id, pd.tags, pd.last_seen_at, pd.version, pd.api_version, pd.organization_id, pd.key_id,
+	pd.id, pd.created_at, pd.name, pd.provisioners, pd.replica_id, pd.tags, pd.last_seen_at, pd.version, pd.api_version, pd.organization_id, pd.key_id, pd.terraform_engine, pd.drain_requested_at,
 	CASE
 		WHEN pd.last_seen_at IS NULL OR pd.last_seen_at < (NOW() - ($1::bigint || ' ms')::interval)
 		THEN 'offline'
@@ -6773,10 +6831,11 @@ WHERE
 	pd.organization_id = $2::uuid
 	AND (COALESCE(array_length($3::uuid[], 1), 0) = 0 OR pd.id = ANY($3::uuid[]))
 	AND ($4::tagset = 'null'::tagset OR provisioner_tagset_contains(pd.tags::tagset, $4::tagset))
+	AND ($5::text = '' OR pd.name = $5::text)
 ORDER BY
 	pd.created_at DESC
 LIMIT
-	$5::int
+	$6::int
 `
 
 type GetProvisionerDaemonsWithStatusByOrganizationParams struct {
@@ -6784,6 +6843,7 @@ type GetProvisionerDaemonsWithStatusByOrganizationParams struct {
 	OrganizationID  uuid.UUID     `db:"organization_id" json:"organization_id"`
 	IDs             []uuid.UUID   `db:"ids" json:"ids"`
 	Tags            StringMap     `db:"tags" json:"tags"`
+	Name            string        `db:"name" json:"name"`
 	Limit           sql.NullInt32 `db:"limit" json:"limit"`
 }
 
@@ -6811,6 +6871,7 @@ func (q *sqlQuerier) GetProvisionerDaemonsWithStatusByOrganization(ctx context.C
 		arg.OrganizationID,
 		pq.Array(arg.IDs),
 		arg.Tags,
+		arg.Name,
 		arg.Limit,
 	)
 	if err != nil {
@@ -6832,6 +6893,8 @@ func (q *sqlQuerier) GetProvisionerDaemonsWithStatusByOrganization(ctx context.C
 			&i.ProvisionerDaemon.APIVersion,
 			&i.ProvisionerDaemon.OrganizationID,
 			&i.ProvisionerDaemon.KeyID,
+			&i.ProvisionerDaemon.TerraformEngine,
+			&i.ProvisionerDaemon.DrainRequestedAt,
 			&i.Status,
 			&i.KeyName,
 			&i.CurrentJobID,
@@ -6858,6 +6921,24 @@ func (q *sqlQuerier) GetProvisionerDaemonsWithStatusByOrganization(ctx context.C
 	return items, nil
 }
 
+const updateProvisionerDaemonDrainRequestedAt = `-- name: UpdateProvisionerDaemonDrainRequestedAt :exec
+UPDATE provisioner_daemons
+SET
+	drain_requested_at = $1
+WHERE
+	id = $2
+`
+
+type UpdateProvisionerDaemonDrainRequestedAtParams struct {
+	DrainRequestedAt sql.NullTime `db:"drain_requested_at" json:"drain_requested_at"`
+	ID               uuid.UUID    `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerDaemonDrainRequestedAt(ctx context.Context, arg UpdateProvisionerDaemonDrainRequestedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerDaemonDrainRequestedAt, arg.DrainRequestedAt, arg.ID)
+	return err
+}
+
 const updateProvisionerDaemonLastSeenAt = `-- name: UpdateProvisionerDaemonLastSeenAt :exec
 UPDATE provisioner_daemons
 SET
@@ -6890,7 +6971,8 @@ INSERT INTO
 		"version",
 		organization_id,
 		api_version,
-		key_id
+		key_id,
+		terraform_engine
 	)
 VALUES (
 	gen_random_uuid(),
@@ -6902,7 +6984,8 @@ VALUES (
 	$6,
 	$7,
 	$8,
-	$9
+	$9,
+	$10
 ) ON CONFLICT("organization_id", "name", LOWER(COALESCE(tags ->> 'owner'::text, ''::text))) DO UPDATE SET
 	provisioners = $3,
 	tags = $4,
@@ -6910,20 +6993,25 @@ VALUES (
 	"version" = $6,
 	api_version = $8,
 	organization_id = $7,
-	key_id = $9
-RETURNING id, created_at, name, provisioners, replica_id, tags, last_seen_at, version, api_version, organization_id, key_id
+	key_id = $9,
+	terraform_engine = $10,
+	-- A reconnecting daemon is a new process, so a drain requested of the
+	-- previous one does not apply.
+	drain_requested_at = NULL
+RETURNING id, created_at, name, provisioners, replica_id, tags, last_seen_at, version, api_version, organization_id, key_id, terraform_engine, drain_requested_at
 `
 
 type UpsertProvisionerDaemonParams struct {
-	CreatedAt      time.Time         `db:"created_at" json:"created_at"`
-	Name           string            `db:"name" json:"name"`
-	Provisioners   []ProvisionerType `db:"provisioners" json:"provisioners"`
-	Tags           StringMap         `db:"tags" json:"tags"`
-	LastSeenAt     sql.NullTime      `db:"last_seen_at" json:"last_seen_at"`
-	Version        string            `db:"version" json:"version"`
-	OrganizationID uuid.UUID         `db:"organization_id" json:"organization_id"`
-	APIVersion     string            `db:"api_version" json:"api_version"`
-	KeyID          uuid.UUID         `db:"key_id" json:"key_id"`
+	CreatedAt       time.Time         `db:"created_at" json:"created_at"`
+	Name            string            `db:"name" json:"name"`
+	Provisioners    []ProvisionerType `db:"provisioners" json:"provisioners"`
+	Tags            StringMap         `db:"tags" json:"tags"`
+	LastSeenAt      sql.NullTime      `db:"last_seen_at" json:"last_seen_at"`
+	Version         string            `db:"version" json:"version"`
+	OrganizationID  uuid.UUID         `db:"organization_id" json:"organization_id"`
+	APIVersion      string            `db:"api_version" json:"api_version"`
+	KeyID           uuid.UUID         `db:"key_id" json:"key_id"`
+	TerraformEngine string            `db:"terraform_engine" json:"terraform_engine"`
 }
 
 func (q *sqlQuerier) UpsertProvisionerDaemon(ctx context.Context, arg UpsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
@@ -6937,6 +7025,7 @@ func (q *sqlQuerier) UpsertProvisionerDaemon(ctx context.Context, arg UpsertProv
 		arg.OrganizationID,
 		arg.APIVersion,
 		arg.KeyID,
+		arg.TerraformEngine,
 	)
 	var i ProvisionerDaemon
 	err := row.Scan(
@@ -6951,6 +7040,8 @@ func (q *sqlQuerier) UpsertProvisionerDaemon(ctx context.Context, arg UpsertProv
 		&i.APIVersion,
 		&i.OrganizationID,
 		&i.KeyID,
+		&i.TerraformEngine,
+		&i.DrainRequestedAt,
 	)
 	return i, err
 }
@@ -7084,12 +7175,22 @@ WHERE
 			-- they are aliases and the code that calls this query already relies on a different type
 			AND provisioner_tagset_contains($5 :: jsonb, potential_job.tags :: jsonb)
 		ORDER BY
+			potential_job.priority DESC,
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running_job
+				WHERE
+					running_job.initiator_id = potential_job.initiator_id
+					AND running_job.job_status = 'running'::provisioner_job_status
+			) ASC,
 			potential_job.created_at
 		FOR UPDATE
 		SKIP LOCKED
 		LIMIT
 			1
-	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 `
 
 type AcquireProvisionerJobParams struct {
@@ -7103,6 +7204,11 @@ type AcquireProvisionerJobParams struct {
 // Acquires the lock for a single job that isn't started, completed,
 // canceled, and that matches an array of provisioner types.
 //
+// Jobs are acquired in priority order. Within a priority, jobs initiated by
+// users with the fewest running jobs go first, so a single user queueing many
+// jobs can't starve everyone else. Provisioner daemons only serve a single
+// organization, so organizations never compete for the same daemons.
+//
 // SKIP LOCKED is used to jump over locked rows. This prevents
 // multiple provisioners from acquiring the same jobs. See:
 // https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
@@ -7135,13 +7241,14 @@ func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvi
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Priority,
 	)
 	return i, err
 }
 
 const getHungProvisionerJobs = `-- name: GetHungProvisionerJobs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -7179,6 +7286,62 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Priority,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingProvisionerJobCounts = `-- name: GetPendingProvisionerJobCounts :many
+SELECT
+	organizations.name AS organization_name,
+	provisioner_jobs.provisioner,
+	provisioner_jobs.tags,
+	COUNT(*) AS count
+FROM
+	provisioner_jobs
+JOIN
+	organizations ON organizations.id = provisioner_jobs.organization_id
+WHERE
+	provisioner_jobs.job_status = 'pending'
+GROUP BY
+	organizations.name,
+	provisioner_jobs.provisioner,
+	provisioner_jobs.tags
+`
+
+type GetPendingProvisionerJobCountsRow struct {
+	OrganizationName string          `db:"organization_name" json:"organization_name"`
+	Provisioner      ProvisionerType `db:"provisioner" json:"provisioner"`
+	Tags             StringMap       `db:"tags" json:"tags"`
+	Count            int64           `db:"count" json:"count"`
+}
+
+// Counts the jobs waiting for a provisioner daemon, grouped by the
+// provisioner and tags a daemon needs to acquire them.
+func (q *sqlQuerier) GetPendingProvisionerJobCounts(ctx context.Context) ([]GetPendingProvisionerJobCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingProvisionerJobCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPendingProvisionerJobCountsRow
+	for rows.Next() {
+		var i GetPendingProvisionerJobCountsRow
+		if err := rows.Scan(
+			&i.OrganizationName,
+			&i.Provisioner,
+			&i.Tags,
+			&i.Count,
 		); err != nil {
 			return nil, err
 		}
@@ -7195,7 +7358,7 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 
 const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -7225,6 +7388,7 @@ func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (P
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -7268,7 +7432,7 @@ func (q *sqlQuerier) GetProvisionerJobTimingsByJobID(ctx context.Context, jobID
 
 const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -7304,6 +7468,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -7331,7 +7496,7 @@ WITH filtered_provisioner_jobs AS (
 pending_jobs AS (
 	-- Step 2: Extract only pending jobs
 	SELECT
-		id, created_at, tags
+		id, created_at, priority, tags
 	FROM
 		provisioner_jobs
 	WHERE
@@ -7342,7 +7507,9 @@ ranked_jobs AS (
 	SELECT
 		pj.id,
 		pj.created_at,
-		ROW_NUMBER() OVER (PARTITION BY pd.id ORDER BY pj.created_at ASC) AS queue_position,
+		-- Fair-share acquisition can reorder jobs of the same priority, so
+		-- this is an estimate.
+		ROW_NUMBER() OVER (PARTITION BY pd.id ORDER BY pj.priority DESC, pj.created_at ASC) AS queue_position,
 		COUNT(*) OVER (PARTITION BY pd.id) AS queue_size
 	FROM
 		pending_jobs pj
@@ -7367,7 +7534,7 @@ SELECT
 	-- Step 5: Final SELECT with INNER JOIN provisioner_jobs
 	fj.id,
 	fj.created_at,
-	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status,
+	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status, pj.priority,
 	fj.queue_position,
 	fj.queue_size
 FROM
@@ -7418,6 +7585,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 			&i.ProvisionerJob.ErrorCode,
 			&i.ProvisionerJob.TraceMetadata,
 			&i.ProvisionerJob.JobStatus,
+			&i.ProvisionerJob.Priority,
 			&i.QueuePosition,
 			&i.QueueSize,
 		); err != nil {
@@ -7437,7 +7605,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 const getProvisionerJobsByOrganizationAndStatusWithQueuePositionAndProvisioner = `-- name: GetProvisionerJobsByOrganizationAndStatusWithQueuePositionAndProvisioner :many
 WITH pending_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, priority
     FROM
         provisioner_jobs
     WHERE
@@ -7452,7 +7620,7 @@ WITH pending_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (ORDER BY priority DESC, created_at ASC) AS queue_position
     FROM
         pending_jobs
 ),
@@ -7460,7 +7628,7 @@ queue_size AS (
 	SELECT COUNT(*) AS count FROM pending_jobs
 )
 SELECT
-	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status,
+	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status, pj.priority,
     COALESCE(qp.queue_position, 0) AS queue_position,
     COALESCE(qs.count, 0) AS queue_size,
 	-- Use subquery to utilize ORDER BY in array_agg since it cannot be
@@ -7589,6 +7757,7 @@ func (q *sqlQuerier) GetProvisionerJobsByOrganizationAndStatusWithQueuePositionA
 			&i.ProvisionerJob.ErrorCode,
 			&i.ProvisionerJob.TraceMetadata,
 			&i.ProvisionerJob.JobStatus,
+			&i.ProvisionerJob.Priority,
 			&i.QueuePosition,
 			&i.QueueSize,
 			pq.Array(&i.AvailableWorkers),
@@ -7646,6 +7815,7 @@ func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, created
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -7674,10 +7844,11 @@ INSERT INTO
 		"type",
 		"input",
 		tags,
-		trace_metadata
+		trace_metadata,
+		priority
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 `
 
 type InsertProvisionerJobParams struct {
@@ -7693,6 +7864,7 @@ type InsertProvisionerJobParams struct {
 	Input          json.RawMessage          `db:"input" json:"input"`
 	Tags           StringMap                `db:"tags" json:"tags"`
 	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+	Priority       ProvisionerJobPriority   `db:"priority" json:"priority"`
 }
 
 func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
@@ -7709,6 +7881,7 @@ func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisi
 		arg.Input,
 		arg.Tags,
 		arg.TraceMetadata,
+		arg.Priority,
 	)
 	var i ProvisionerJob
 	err := row.Scan(
@@ -7731,6 +7904,7 @@ func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisi
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -10182,7 +10356,7 @@ func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTem
 
 const getTemplateByID = `-- name: GetTemplateByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, drift_detection_interval, session_recording_enabled, session_recording_retention, dlp_policy, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names
 WHERE
@@ -10223,6 +10397,10 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.Deprecated,
 		&i.ActivityBump,
 		&i.MaxPortSharingLevel,
+		&i.DriftDetectionInterval,
+		&i.SessionRecordingEnabled,
+		&i.SessionRecordingRetention,
+		&i.DLPPolicy,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.OrganizationName,
@@ -10234,7 +10412,7 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 
 const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, drift_detection_interval, session_recording_enabled, session_recording_retention, dlp_policy, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names AS templates
 WHERE
@@ -10283,6 +10461,10 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 		&i.Deprecated,
 		&i.ActivityBump,
 		&i.MaxPortSharingLevel,
+		&i.DriftDetectionInterval,
+		&i.SessionRecordingEnabled,
+		&i.SessionRecordingRetention,
+		&i.DLPPolicy,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.OrganizationName,
@@ -10293,7 +10475,7 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 }
 
 const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, drift_detection_interval, session_recording_enabled, session_recording_retention, dlp_policy, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
 ORDER BY (name, id) ASC
 `
 
@@ -10335,6 +10517,10 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			&i.DriftDetectionInterval,
+			&i.SessionRecordingEnabled,
+			&i.SessionRecordingRetention,
+			&i.DLPPolicy,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -10356,7 +10542,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 
 const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, drift_detection_interval, session_recording_enabled, session_recording_retention, dlp_policy, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names AS templates
 WHERE
@@ -10456,6 +10642,10 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			&i.DriftDetectionInterval,
+			&i.SessionRecordingEnabled,
+			&i.SessionRecordingRetention,
+			&i.DLPPolicy,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -10632,7 +10822,11 @@ SET
 	display_name = $6,
 	allow_user_cancel_workspace_jobs = $7,
 	group_acl = $8,
-	max_port_sharing_level = $9
+	max_port_sharing_level = $9,
+	drift_detection_interval = $10,
+	session_recording_enabled = $11,
+	session_recording_retention = $12,
+	dlp_policy = $13
 WHERE
 	id = $1
 `
@@ -10647,6 +10841,10 @@ type UpdateTemplateMetaByIDParams struct {
 	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
 	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
 	MaxPortSharingLevel          AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	DriftDetectionInterval       int64           `db:"drift_detection_interval" json:"drift_detection_interval"`
+	SessionRecordingEnabled      bool            `db:"session_recording_enabled" json:"session_recording_enabled"`
+	SessionRecordingRetention    int64           `db:"session_recording_retention" json:"session_recording_retention"`
+	DLPPolicy                    DLPPolicy       `db:"dlp_policy" json:"dlp_policy"`
 }
 
 func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error {
@@ -10660,6 +10858,10 @@ func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTempl
 		arg.AllowUserCancelWorkspaceJobs,
 		arg.GroupACL,
 		arg.MaxPortSharingLevel,
+		arg.DriftDetectionInterval,
+		arg.SessionRecordingEnabled,
+		arg.SessionRecordingRetention,
+		arg.DLPPolicy,
 	)
 	return err
 }
@@ -12058,6 +12260,8 @@ FROM
 WHERE
 	status = 'active'::user_status AND deleted = false
 	AND CASE WHEN $1::bool THEN TRUE ELSE is_system = false END
+	-- Service accounts don't consume seats.
+	AND NOT is_service_account
 `
 
 func (q *sqlQuerier) GetActiveUserCount(ctx context.Context, includeSystem bool) (int64, error) {
@@ -12136,7 +12340,7 @@ func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.
 
 const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 FROM
 	users
 WHERE
@@ -12173,13 +12377,15 @@ func (q *sqlQuerier) GetUserByEmailOrUsername(ctx context.Context, arg GetUserBy
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 FROM
 	users
 WHERE
@@ -12210,6 +12416,8 @@ func (q *sqlQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -12267,7 +12475,7 @@ func (q *sqlQuerier) GetUserThemePreference(ctx context.Context, userID uuid.UUI
 
 const getUsers = `-- name: GetUsers :many
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, COUNT(*) OVER() AS count
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id, COUNT(*) OVER() AS count
 FROM
 	users
 WHERE
@@ -12383,25 +12591,27 @@ type GetUsersParams struct {
 }
 
 type GetUsersRow struct {
-	ID                       uuid.UUID      `db:"id" json:"id"`
-	Email                    string         `db:"email" json:"email"`
-	Username                 string         `db:"username" json:"username"`
-	HashedPassword           []byte         `db:"hashed_password" json:"hashed_password"`
-	CreatedAt                time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt                time.Time      `db:"updated_at" json:"updated_at"`
-	Status                   UserStatus     `db:"status" json:"status"`
-	RBACRoles                pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
-	LoginType                LoginType      `db:"login_type" json:"login_type"`
-	AvatarURL                string         `db:"avatar_url" json:"avatar_url"`
-	Deleted                  bool           `db:"deleted" json:"deleted"`
-	LastSeenAt               time.Time      `db:"last_seen_at" json:"last_seen_at"`
-	QuietHoursSchedule       string         `db:"quiet_hours_schedule" json:"quiet_hours_schedule"`
-	Name                     string         `db:"name" json:"name"`
-	GithubComUserID          sql.NullInt64  `db:"github_com_user_id" json:"github_com_user_id"`
-	HashedOneTimePasscode    []byte         `db:"hashed_one_time_passcode" json:"hashed_one_time_passcode"`
-	OneTimePasscodeExpiresAt sql.NullTime   `db:"one_time_passcode_expires_at" json:"one_time_passcode_expires_at"`
-	IsSystem                 bool           `db:"is_system" json:"is_system"`
-	Count                    int64          `db:"count" json:"count"`
+	ID                           uuid.UUID      `db:"id" json:"id"`
+	Email                        string         `db:"email" json:"email"`
+	Username                     string         `db:"username" json:"username"`
+	HashedPassword               []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt                    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time      `db:"updated_at" json:"updated_at"`
+	Status                       UserStatus     `db:"status" json:"status"`
+	RBACRoles                    pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType                    LoginType      `db:"login_type" json:"login_type"`
+	AvatarURL                    string         `db:"avatar_url" json:"avatar_url"`
+	Deleted                      bool           `db:"deleted" json:"deleted"`
+	LastSeenAt                   time.Time      `db:"last_seen_at" json:"last_seen_at"`
+	QuietHoursSchedule           string         `db:"quiet_hours_schedule" json:"quiet_hours_schedule"`
+	Name                         string         `db:"name" json:"name"`
+	GithubComUserID              sql.NullInt64  `db:"github_com_user_id" json:"github_com_user_id"`
+	HashedOneTimePasscode        []byte         `db:"hashed_one_time_passcode" json:"hashed_one_time_passcode"`
+	OneTimePasscodeExpiresAt     sql.NullTime   `db:"one_time_passcode_expires_at" json:"one_time_passcode_expires_at"`
+	IsSystem                     bool           `db:"is_system" json:"is_system"`
+	IsServiceAccount             bool           `db:"is_service_account" json:"is_service_account"`
+	ServiceAccountOrganizationID uuid.NullUUID  `db:"service_account_organization_id" json:"service_account_organization_id"`
+	Count                        int64          `db:"count" json:"count"`
 }
 
 // This will never return deleted users.
@@ -12447,6 +12657,8 @@ func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUse
 			&i.HashedOneTimePasscode,
 			&i.OneTimePasscodeExpiresAt,
 			&i.IsSystem,
+			&i.IsServiceAccount,
+			&i.ServiceAccountOrganizationID,
 			&i.Count,
 		); err != nil {
 			return nil, err
@@ -12463,7 +12675,7 @@ func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUse
 }
 
 const getUsersByIDs = `-- name: GetUsersByIDs :many
-SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system FROM users WHERE id = ANY($1 :: uuid [ ])
+SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id FROM users WHERE id = ANY($1 :: uuid [ ])
 `
 
 // This shouldn't check for deleted, because it's frequently used
@@ -12497,6 +12709,8 @@ func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User
 			&i.HashedOneTimePasscode,
 			&i.OneTimePasscodeExpiresAt,
 			&i.IsSystem,
+			&i.IsServiceAccount,
+			&i.ServiceAccountOrganizationID,
 		); err != nil {
 			return nil, err
 		}
@@ -12523,27 +12737,33 @@ INSERT INTO
 		updated_at,
 		rbac_roles,
 		login_type,
-		status
+		status,
+		is_service_account,
+		service_account_organization_id
 	)
 VALUES
 	($1, $2, $3, $4, $5, $6, $7, $8, $9,
 		-- if the status passed in is empty, fallback to dormant, which is what
 		-- we were doing before.
-		COALESCE(NULLIF($10::text, '')::user_status, 'dormant'::user_status)
-	) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+		COALESCE(NULLIF($10::text, '')::user_status, 'dormant'::user_status),
+		$11,
+		$12
+	) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 `
 
 type InsertUserParams struct {
-	ID             uuid.UUID      `db:"id" json:"id"`
-	Email          string         `db:"email" json:"email"`
-	Username       string         `db:"username" json:"username"`
-	Name           string         `db:"name" json:"name"`
-	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
-	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
-	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
-	LoginType      LoginType      `db:"login_type" json:"login_type"`
-	Status         string         `db:"status" json:"status"`
+	ID                           uuid.UUID      `db:"id" json:"id"`
+	Email                        string         `db:"email" json:"email"`
+	Username                     string         `db:"username" json:"username"`
+	Name                         string         `db:"name" json:"name"`
+	HashedPassword               []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt                    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time      `db:"updated_at" json:"updated_at"`
+	RBACRoles                    pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType                    LoginType      `db:"login_type" json:"login_type"`
+	Status                       string         `db:"status" json:"status"`
+	IsServiceAccount             bool           `db:"is_service_account" json:"is_service_account"`
+	ServiceAccountOrganizationID uuid.NullUUID  `db:"service_account_organization_id" json:"service_account_organization_id"`
 }
 
 func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
@@ -12558,6 +12778,8 @@ func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User
 		arg.RBACRoles,
 		arg.LoginType,
 		arg.Status,
+		arg.IsServiceAccount,
+		arg.ServiceAccountOrganizationID,
 	)
 	var i User
 	err := row.Scan(
@@ -12579,6 +12801,8 @@ func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -12593,6 +12817,8 @@ WHERE
     last_seen_at < $2 :: timestamp
     AND status = 'active'::user_status
 		AND NOT is_system
+		-- Service accounts are used by automation, so they never go dormant.
+		AND NOT is_service_account
 RETURNING id, email, username, last_seen_at
 `
 
@@ -12718,7 +12944,7 @@ SET
 	last_seen_at = $2,
 	updated_at = $3
 WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 `
 
 type UpdateUserLastSeenAtParams struct {
@@ -12749,6 +12975,8 @@ func (q *sqlQuerier) UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLas
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -12768,7 +12996,9 @@ SET
 WHERE
 	id = $2
 	AND NOT is_system
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+	-- Service accounts cannot log in interactively.
+	AND NOT is_service_account
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 `
 
 type UpdateUserLoginTypeParams struct {
@@ -12798,6 +13028,8 @@ func (q *sqlQuerier) UpdateUserLoginType(ctx context.Context, arg UpdateUserLogi
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -12813,7 +13045,7 @@ SET
 	name = $6
 WHERE
 	id = $1
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 `
 
 type UpdateUserProfileParams struct {
@@ -12854,6 +13086,8 @@ func (q *sqlQuerier) UpdateUserProfile(ctx context.Context, arg UpdateUserProfil
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -12865,7 +13099,7 @@ SET
 	quiet_hours_schedule = $2
 WHERE
 	id = $1
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 `
 
 type UpdateUserQuietHoursScheduleParams struct {
@@ -12895,6 +13129,8 @@ func (q *sqlQuerier) UpdateUserQuietHoursSchedule(ctx context.Context, arg Updat
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -12907,7 +13143,7 @@ SET
 	rbac_roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
 WHERE
 	id = $2
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 `
 
 type UpdateUserRolesParams struct {
@@ -12937,6 +13173,8 @@ func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesPar
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -12948,7 +13186,7 @@ SET
 	status = $2,
 	updated_at = $3
 WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, name, github_com_user_id, hashed_one_time_passcode, one_time_passcode_expires_at, is_system, is_service_account, service_account_organization_id
 `
 
 type UpdateUserStatusParams struct {
@@ -12979,6 +13217,8 @@ func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusP
 		&i.HashedOneTimePasscode,
 		&i.OneTimePasscodeExpiresAt,
 		&i.IsSystem,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -13037,6 +13277,245 @@ func (q *sqlQuerier) UpdateUserThemePreference(ctx context.Context, arg UpdateUs
 	return i, err
 }
 
+const deleteUserSecret = `-- name: DeleteUserSecret :exec
+DELETE FROM
+	user_secrets
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteUserSecret(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSecret, id)
+	return err
+}
+
+const getUserSecretByID = `-- name: GetUserSecretByID :one
+SELECT
+	id, user_id, name, description, value, value_key_id, env_name, file_path, template_ids, created_at, updated_at
+FROM
+	user_secrets
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetUserSecretByID(ctx context.Context, id uuid.UUID) (UserSecret, error) {
+	row := q.db.QueryRowContext(ctx, getUserSecretByID, id)
+	var i UserSecret
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Value,
+		&i.ValueKeyID,
+		&i.EnvName,
+		&i.FilePath,
+		pq.Array(&i.TemplateIDs),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserSecretByUserIDAndName = `-- name: GetUserSecretByUserIDAndName :one
+SELECT
+	id, user_id, name, description, value, value_key_id, env_name, file_path, template_ids, created_at, updated_at
+FROM
+	user_secrets
+WHERE
+	user_id = $1 AND name = $2
+`
+
+type GetUserSecretByUserIDAndNameParams struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetUserSecretByUserIDAndName(ctx context.Context, arg GetUserSecretByUserIDAndNameParams) (UserSecret, error) {
+	row := q.db.QueryRowContext(ctx, getUserSecretByUserIDAndName, arg.UserID, arg.Name)
+	var i UserSecret
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Value,
+		&i.ValueKeyID,
+		&i.EnvName,
+		&i.FilePath,
+		pq.Array(&i.TemplateIDs),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserSecrets = `-- name: GetUserSecrets :many
+SELECT
+	id, user_id, name, description, value, value_key_id, env_name, file_path, template_ids, created_at, updated_at
+FROM
+	user_secrets
+WHERE
+	user_id = $1
+ORDER BY
+	name ASC
+`
+
+func (q *sqlQuerier) GetUserSecrets(ctx context.Context, userID uuid.UUID) ([]UserSecret, error) {
+	rows, err := q.db.QueryContext(ctx, getUserSecrets, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserSecret
+	for rows.Next() {
+		var i UserSecret
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Value,
+			&i.ValueKeyID,
+			&i.EnvName,
+			&i.FilePath,
+			pq.Array(&i.TemplateIDs),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertUserSecret = `-- name: InsertUserSecret :one
+INSERT INTO
+	user_secrets (
+		id,
+		user_id,
+		name,
+		description,
+		value,
+		value_key_id,
+		env_name,
+		file_path,
+		template_ids,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, user_id, name, description, value, value_key_id, env_name, file_path, template_ids, created_at, updated_at
+`
+
+type InsertUserSecretParams struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	UserID      uuid.UUID      `db:"user_id" json:"user_id"`
+	Name        string         `db:"name" json:"name"`
+	Description string         `db:"description" json:"description"`
+	Value       string         `db:"value" json:"value"`
+	ValueKeyID  sql.NullString `db:"value_key_id" json:"value_key_id"`
+	EnvName     string         `db:"env_name" json:"env_name"`
+	FilePath    string         `db:"file_path" json:"file_path"`
+	TemplateIDs []uuid.UUID    `db:"template_ids" json:"template_ids"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) InsertUserSecret(ctx context.Context, arg InsertUserSecretParams) (UserSecret, error) {
+	row := q.db.QueryRowContext(ctx, insertUserSecret,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Value,
+		arg.ValueKeyID,
+		arg.EnvName,
+		arg.FilePath,
+		pq.Array(arg.TemplateIDs),
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i UserSecret
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Value,
+		&i.ValueKeyID,
+		&i.EnvName,
+		&i.FilePath,
+		pq.Array(&i.TemplateIDs),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateUserSecret = `-- name: UpdateUserSecret :one
+UPDATE
+	user_secrets
+SET
+	description = $2,
+	value = $3,
+	value_key_id = $4,
+	env_name = $5,
+	file_path = $6,
+	template_ids = $7,
+	updated_at = $8
+WHERE
+	id = $1
+RETURNING
+	id, user_id, name, description, value, value_key_id, env_name, file_path, template_ids, created_at, updated_at
+`
+
+type UpdateUserSecretParams struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	Description string         `db:"description" json:"description"`
+	Value       string         `db:"value" json:"value"`
+	ValueKeyID  sql.NullString `db:"value_key_id" json:"value_key_id"`
+	EnvName     string         `db:"env_name" json:"env_name"`
+	FilePath    string         `db:"file_path" json:"file_path"`
+	TemplateIDs []uuid.UUID    `db:"template_ids" json:"template_ids"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateUserSecret(ctx context.Context, arg UpdateUserSecretParams) (UserSecret, error) {
+	row := q.db.QueryRowContext(ctx, updateUserSecret,
+		arg.ID,
+		arg.Description,
+		arg.Value,
+		arg.ValueKeyID,
+		arg.EnvName,
+		arg.FilePath,
+		pq.Array(arg.TemplateIDs),
+		arg.UpdatedAt,
+	)
+	var i UserSecret
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Value,
+		&i.ValueKeyID,
+		&i.EnvName,
+		&i.FilePath,
+		pq.Array(&i.TemplateIDs),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getWorkspaceAgentDevcontainersByAgentID = `-- name: GetWorkspaceAgentDevcontainersByAgentID :many
 SELECT
 	id, workspace_agent_id, created_at, workspace_folder, config_path, name
@@ -13320,24 +13799,181 @@ func (q *sqlQuerier) UpsertWorkspaceAgentPortShare(ctx context.Context, arg Upse
 	return i, err
 }
 
-const fetchMemoryResourceMonitorsByAgentID = `-- name: FetchMemoryResourceMonitorsByAgentID :one
+const deleteWorkspaceAgentPTYShare = `-- name: DeleteWorkspaceAgentPTYShare :exec
+DELETE FROM
+	workspace_agent_pty_shares
+WHERE
+	agent_id = $1
+	AND reconnect_id = $2
+	AND user_id = $3
+`
+
+type DeleteWorkspaceAgentPTYShareParams struct {
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	ReconnectID uuid.UUID `db:"reconnect_id" json:"reconnect_id"`
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) DeleteWorkspaceAgentPTYShare(ctx context.Context, arg DeleteWorkspaceAgentPTYShareParams) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspaceAgentPTYShare, arg.AgentID, arg.ReconnectID, arg.UserID)
+	return err
+}
+
+const getWorkspaceAgentPTYShare = `-- name: GetWorkspaceAgentPTYShare :one
 SELECT
-	agent_id, enabled, threshold, created_at, updated_at, state, debounced_until
+	workspace_id, agent_id, reconnect_id, user_id, mode, created_by, created_at
 FROM
-	workspace_agent_memory_resource_monitors
+	workspace_agent_pty_shares
 WHERE
 	agent_id = $1
+	AND reconnect_id = $2
+	AND user_id = $3
 `
 
-func (q *sqlQuerier) FetchMemoryResourceMonitorsByAgentID(ctx context.Context, agentID uuid.UUID) (WorkspaceAgentMemoryResourceMonitor, error) {
-	row := q.db.QueryRowContext(ctx, fetchMemoryResourceMonitorsByAgentID, agentID)
-	var i WorkspaceAgentMemoryResourceMonitor
+type GetWorkspaceAgentPTYShareParams struct {
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	ReconnectID uuid.UUID `db:"reconnect_id" json:"reconnect_id"`
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) GetWorkspaceAgentPTYShare(ctx context.Context, arg GetWorkspaceAgentPTYShareParams) (WorkspaceAgentPTYShare, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceAgentPTYShare, arg.AgentID, arg.ReconnectID, arg.UserID)
+	var i WorkspaceAgentPTYShare
 	err := row.Scan(
+		&i.WorkspaceID,
 		&i.AgentID,
-		&i.Enabled,
-		&i.Threshold,
+		&i.ReconnectID,
+		&i.UserID,
+		&i.Mode,
+		&i.CreatedBy,
 		&i.CreatedAt,
-		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listWorkspaceAgentPTYShares = `-- name: ListWorkspaceAgentPTYShares :many
+SELECT
+	workspace_id, agent_id, reconnect_id, user_id, mode, created_by, created_at
+FROM
+	workspace_agent_pty_shares
+WHERE
+	agent_id = $1
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) ListWorkspaceAgentPTYShares(ctx context.Context, agentID uuid.UUID) ([]WorkspaceAgentPTYShare, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkspaceAgentPTYShares, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentPTYShare
+	for rows.Next() {
+		var i WorkspaceAgentPTYShare
+		if err := rows.Scan(
+			&i.WorkspaceID,
+			&i.AgentID,
+			&i.ReconnectID,
+			&i.UserID,
+			&i.Mode,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertWorkspaceAgentPTYShare = `-- name: UpsertWorkspaceAgentPTYShare :one
+INSERT INTO
+	workspace_agent_pty_shares (
+		workspace_id,
+		agent_id,
+		reconnect_id,
+		user_id,
+		mode,
+		created_by,
+		created_at
+	)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7
+)
+ON CONFLICT (
+	agent_id,
+	reconnect_id,
+	user_id
+)
+DO UPDATE SET
+	mode = $5
+RETURNING workspace_id, agent_id, reconnect_id, user_id, mode, created_by, created_at
+`
+
+type UpsertWorkspaceAgentPTYShareParams struct {
+	WorkspaceID uuid.UUID    `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID    `db:"agent_id" json:"agent_id"`
+	ReconnectID uuid.UUID    `db:"reconnect_id" json:"reconnect_id"`
+	UserID      uuid.UUID    `db:"user_id" json:"user_id"`
+	Mode        PTYShareMode `db:"mode" json:"mode"`
+	CreatedBy   uuid.UUID    `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) UpsertWorkspaceAgentPTYShare(ctx context.Context, arg UpsertWorkspaceAgentPTYShareParams) (WorkspaceAgentPTYShare, error) {
+	row := q.db.QueryRowContext(ctx, upsertWorkspaceAgentPTYShare,
+		arg.WorkspaceID,
+		arg.AgentID,
+		arg.ReconnectID,
+		arg.UserID,
+		arg.Mode,
+		arg.CreatedBy,
+		arg.CreatedAt,
+	)
+	var i WorkspaceAgentPTYShare
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.AgentID,
+		&i.ReconnectID,
+		&i.UserID,
+		&i.Mode,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const fetchMemoryResourceMonitorsByAgentID = `-- name: FetchMemoryResourceMonitorsByAgentID :one
+SELECT
+	agent_id, enabled, threshold, created_at, updated_at, state, debounced_until
+FROM
+	workspace_agent_memory_resource_monitors
+WHERE
+	agent_id = $1
+`
+
+func (q *sqlQuerier) FetchMemoryResourceMonitorsByAgentID(ctx context.Context, agentID uuid.UUID) (WorkspaceAgentMemoryResourceMonitor, error) {
+	row := q.db.QueryRowContext(ctx, fetchMemoryResourceMonitorsByAgentID, agentID)
+	var i WorkspaceAgentMemoryResourceMonitor
+	err := row.Scan(
+		&i.AgentID,
+		&i.Enabled,
+		&i.Threshold,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 		&i.State,
 		&i.DebouncedUntil,
 	)
@@ -16927,6 +17563,216 @@ func (q *sqlQuerier) UpdateWorkspaceBuildProvisionerStateByID(ctx context.Contex
 	return err
 }
 
+const getLatestWorkspaceDriftChecksByWorkspaceIDs = `-- name: GetLatestWorkspaceDriftChecksByWorkspaceIDs :many
+SELECT
+	DISTINCT ON (workspace_id) job_id, workspace_id, workspace_build_id, created_at, checked_at, resources
+FROM
+	workspace_drift_checks
+WHERE
+	workspace_id = ANY($1 :: uuid [ ])
+	AND checked_at IS NOT NULL
+ORDER BY
+	workspace_id, checked_at DESC
+`
+
+// Returns the most recent completed drift check of each workspace.
+func (q *sqlQuerier) GetLatestWorkspaceDriftChecksByWorkspaceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceDriftCheck, error) {
+	rows, err := q.db.QueryContext(ctx, getLatestWorkspaceDriftChecksByWorkspaceIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceDriftCheck
+	for rows.Next() {
+		var i WorkspaceDriftCheck
+		if err := rows.Scan(
+			&i.JobID,
+			&i.WorkspaceID,
+			&i.WorkspaceBuildID,
+			&i.CreatedAt,
+			&i.CheckedAt,
+			&i.Resources,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceDriftCheckByJobID = `-- name: GetWorkspaceDriftCheckByJobID :one
+SELECT
+	job_id, workspace_id, workspace_build_id, created_at, checked_at, resources
+FROM
+	workspace_drift_checks
+WHERE
+	job_id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceDriftCheckByJobID(ctx context.Context, jobID uuid.UUID) (WorkspaceDriftCheck, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceDriftCheckByJobID, jobID)
+	var i WorkspaceDriftCheck
+	err := row.Scan(
+		&i.JobID,
+		&i.WorkspaceID,
+		&i.WorkspaceBuildID,
+		&i.CreatedAt,
+		&i.CheckedAt,
+		&i.Resources,
+	)
+	return i, err
+}
+
+const getWorkspacesDueDriftCheck = `-- name: GetWorkspacesDueDriftCheck :many
+SELECT
+	workspaces.id AS workspace_id,
+	workspaces.owner_id,
+	workspace_latest_builds.id AS workspace_build_id
+FROM
+	workspaces
+INNER JOIN
+	templates ON templates.id = workspaces.template_id
+INNER JOIN
+	workspace_latest_builds ON workspace_latest_builds.workspace_id = workspaces.id
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_latest_builds.job_id
+WHERE
+	workspaces.deleted = false
+	AND workspaces.dormant_at IS NULL
+	AND workspaces.owner_id != 'c42fdf75-3097-471c-8c33-fb52454d81c0'::uuid -- The system user responsible for prebuilds.
+	AND templates.deleted = false
+	AND templates.drift_detection_interval > 0
+	AND workspace_latest_builds.transition = 'start'::workspace_transition
+	AND workspace_latest_builds.job_status = 'succeeded'::provisioner_job_status
+	AND provisioner_jobs.completed_at < $1 :: timestamptz - (templates.drift_detection_interval / 1000 / 1000 / 1000 || ' seconds')::interval
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_drift_checks
+		WHERE
+			workspace_drift_checks.workspace_id = workspaces.id
+			AND workspace_drift_checks.created_at > $1 :: timestamptz - (templates.drift_detection_interval / 1000 / 1000 / 1000 || ' seconds')::interval
+	)
+ORDER BY
+	(
+		SELECT
+			MAX(workspace_drift_checks.created_at)
+		FROM
+			workspace_drift_checks
+		WHERE
+			workspace_drift_checks.workspace_id = workspaces.id
+	) ASC NULLS FIRST,
+	provisioner_jobs.completed_at ASC,
+	workspaces.id ASC
+LIMIT
+	$2 :: int
+`
+
+type GetWorkspacesDueDriftCheckParams struct {
+	Now        time.Time `db:"now" json:"now"`
+	LimitCount int32     `db:"limit_count" json:"limit_count"`
+}
+
+type GetWorkspacesDueDriftCheckRow struct {
+	WorkspaceID      uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	OwnerID          uuid.UUID `db:"owner_id" json:"owner_id"`
+	WorkspaceBuildID uuid.UUID `db:"workspace_build_id" json:"workspace_build_id"`
+}
+
+// Returns the latest builds of running workspaces whose templates have drift
+// detection enabled, and that haven't had a drift check requested within the
+// template's interval. Failed checks count as requested, so they aren't retried
+// until the interval has passed. Workspaces that were checked least recently
+// come first, so that a limited batch doesn't starve any workspace.
+func (q *sqlQuerier) GetWorkspacesDueDriftCheck(ctx context.Context, arg GetWorkspacesDueDriftCheckParams) ([]GetWorkspacesDueDriftCheckRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacesDueDriftCheck, arg.Now, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspacesDueDriftCheckRow
+	for rows.Next() {
+		var i GetWorkspacesDueDriftCheckRow
+		if err := rows.Scan(&i.WorkspaceID, &i.OwnerID, &i.WorkspaceBuildID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceDriftCheck = `-- name: InsertWorkspaceDriftCheck :one
+INSERT INTO
+	workspace_drift_checks (
+		job_id,
+		workspace_id,
+		workspace_build_id,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4) RETURNING job_id, workspace_id, workspace_build_id, created_at, checked_at, resources
+`
+
+type InsertWorkspaceDriftCheckParams struct {
+	JobID            uuid.UUID `db:"job_id" json:"job_id"`
+	WorkspaceID      uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	WorkspaceBuildID uuid.UUID `db:"workspace_build_id" json:"workspace_build_id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceDriftCheck(ctx context.Context, arg InsertWorkspaceDriftCheckParams) (WorkspaceDriftCheck, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceDriftCheck,
+		arg.JobID,
+		arg.WorkspaceID,
+		arg.WorkspaceBuildID,
+		arg.CreatedAt,
+	)
+	var i WorkspaceDriftCheck
+	err := row.Scan(
+		&i.JobID,
+		&i.WorkspaceID,
+		&i.WorkspaceBuildID,
+		&i.CreatedAt,
+		&i.CheckedAt,
+		&i.Resources,
+	)
+	return i, err
+}
+
+const updateWorkspaceDriftCheckByJobID = `-- name: UpdateWorkspaceDriftCheckByJobID :exec
+UPDATE
+	workspace_drift_checks
+SET
+	checked_at = $2,
+	resources = $3
+WHERE
+	job_id = $1
+`
+
+type UpdateWorkspaceDriftCheckByJobIDParams struct {
+	JobID     uuid.UUID               `db:"job_id" json:"job_id"`
+	CheckedAt sql.NullTime            `db:"checked_at" json:"checked_at"`
+	Resources WorkspaceDriftResources `db:"resources" json:"resources"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceDriftCheckByJobID(ctx context.Context, arg UpdateWorkspaceDriftCheckByJobIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceDriftCheckByJobID, arg.JobID, arg.CheckedAt, arg.Resources)
+	return err
+}
+
 const getWorkspaceModulesByJobID = `-- name: GetWorkspaceModulesByJobID :many
 SELECT
 	id, job_id, transition, source, version, key, created_at
@@ -17838,7 +18684,7 @@ LEFT JOIN LATERAL (
 ) latest_build ON TRUE
 LEFT JOIN LATERAL (
 	SELECT
-		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level
+		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, drift_detection_interval, session_recording_enabled, session_recording_retention, dlp_policy
 	FROM
 		templates
 	WHERE
@@ -19040,3 +19886,285 @@ func (q *sqlQuerier) InsertWorkspaceAgentScripts(ctx context.Context, arg Insert
 	}
 	return items, nil
 }
+
+const deleteOldWorkspaceSessionRecordings = `-- name: DeleteOldWorkspaceSessionRecordings :exec
+DELETE FROM
+	workspace_session_recordings
+USING
+	workspaces, templates
+WHERE
+	workspace_session_recordings.workspace_id = workspaces.id
+	AND workspaces.template_id = templates.id
+	AND templates.session_recording_retention > 0
+	AND workspace_session_recordings.started_at < $1 :: timestamptz - (templates.session_recording_retention / 1000 / 1000 / 1000 || ' seconds')::interval
+`
+
+// Deletes recordings that are older than the retention of their template.
+// Templates with a retention of 0 keep their recordings forever.
+func (q *sqlQuerier) DeleteOldWorkspaceSessionRecordings(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldWorkspaceSessionRecordings, before)
+	return err
+}
+
+const getWorkspaceSessionRecordingByID = `-- name: GetWorkspaceSessionRecordingByID :one
+SELECT
+	workspace_session_recordings.id, workspace_session_recordings.workspace_id, workspace_session_recordings.agent_id, workspace_session_recordings.type, workspace_session_recordings.started_at, workspace_session_recordings.ended_at, workspace_session_recordings.size, workspace_session_recordings.created_at,
+	workspaces.name AS workspace_name,
+	workspaces.owner_id AS workspace_owner_id,
+	visible_users.username AS workspace_owner_username
+FROM
+	workspace_session_recordings
+INNER JOIN
+	workspaces ON workspaces.id = workspace_session_recordings.workspace_id
+INNER JOIN
+	visible_users ON visible_users.id = workspaces.owner_id
+WHERE
+	workspace_session_recordings.id = $1
+`
+
+type GetWorkspaceSessionRecordingByIDRow struct {
+	ID                     uuid.UUID            `db:"id" json:"id"`
+	WorkspaceID            uuid.UUID            `db:"workspace_id" json:"workspace_id"`
+	AgentID                uuid.UUID            `db:"agent_id" json:"agent_id"`
+	Type                   SessionRecordingType `db:"type" json:"type"`
+	StartedAt              time.Time            `db:"started_at" json:"started_at"`
+	EndedAt                sql.NullTime         `db:"ended_at" json:"ended_at"`
+	Size                   int64                `db:"size" json:"size"`
+	CreatedAt              time.Time            `db:"created_at" json:"created_at"`
+	WorkspaceName          string               `db:"workspace_name" json:"workspace_name"`
+	WorkspaceOwnerID       uuid.UUID            `db:"workspace_owner_id" json:"workspace_owner_id"`
+	WorkspaceOwnerUsername string               `db:"workspace_owner_username" json:"workspace_owner_username"`
+}
+
+func (q *sqlQuerier) GetWorkspaceSessionRecordingByID(ctx context.Context, id uuid.UUID) (GetWorkspaceSessionRecordingByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceSessionRecordingByID, id)
+	var i GetWorkspaceSessionRecordingByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.AgentID,
+		&i.Type,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Size,
+		&i.CreatedAt,
+		&i.WorkspaceName,
+		&i.WorkspaceOwnerID,
+		&i.WorkspaceOwnerUsername,
+	)
+	return i, err
+}
+
+const getWorkspaceSessionRecordingChunks = `-- name: GetWorkspaceSessionRecordingChunks :many
+SELECT
+	recording_id, chunk_index, data
+FROM
+	workspace_session_recording_chunks
+WHERE
+	recording_id = $1
+ORDER BY
+	chunk_index ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceSessionRecordingChunks(ctx context.Context, recordingID uuid.UUID) ([]WorkspaceSessionRecordingChunk, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceSessionRecordingChunks, recordingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceSessionRecordingChunk
+	for rows.Next() {
+		var i WorkspaceSessionRecordingChunk
+		if err := rows.Scan(
+			&i.RecordingID,
+			&i.ChunkIndex,
+			&i.Data,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceSessionRecordings = `-- name: GetWorkspaceSessionRecordings :many
+SELECT
+	workspace_session_recordings.id, workspace_session_recordings.workspace_id, workspace_session_recordings.agent_id, workspace_session_recordings.type, workspace_session_recordings.started_at, workspace_session_recordings.ended_at, workspace_session_recordings.size, workspace_session_recordings.created_at,
+	workspaces.name AS workspace_name,
+	workspaces.owner_id AS workspace_owner_id,
+	visible_users.username AS workspace_owner_username
+FROM
+	workspace_session_recordings
+INNER JOIN
+	workspaces ON workspaces.id = workspace_session_recordings.workspace_id
+INNER JOIN
+	visible_users ON visible_users.id = workspaces.owner_id
+WHERE
+	CASE
+		WHEN $1 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			workspace_session_recordings.workspace_id = $1
+		ELSE true
+	END
+ORDER BY
+	workspace_session_recordings.started_at DESC
+LIMIT
+	-- A limit of 0 means "no limit".
+	NULLIF($2 :: int, 0)
+OFFSET
+	$3
+`
+
+type GetWorkspaceSessionRecordingsParams struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	LimitOpt    int32     `db:"limit_opt" json:"limit_opt"`
+	OffsetOpt   int32     `db:"offset_opt" json:"offset_opt"`
+}
+
+type GetWorkspaceSessionRecordingsRow struct {
+	ID                     uuid.UUID            `db:"id" json:"id"`
+	WorkspaceID            uuid.UUID            `db:"workspace_id" json:"workspace_id"`
+	AgentID                uuid.UUID            `db:"agent_id" json:"agent_id"`
+	Type                   SessionRecordingType `db:"type" json:"type"`
+	StartedAt              time.Time            `db:"started_at" json:"started_at"`
+	EndedAt                sql.NullTime         `db:"ended_at" json:"ended_at"`
+	Size                   int64                `db:"size" json:"size"`
+	CreatedAt              time.Time            `db:"created_at" json:"created_at"`
+	WorkspaceName          string               `db:"workspace_name" json:"workspace_name"`
+	WorkspaceOwnerID       uuid.UUID            `db:"workspace_owner_id" json:"workspace_owner_id"`
+	WorkspaceOwnerUsername string               `db:"workspace_owner_username" json:"workspace_owner_username"`
+}
+
+func (q *sqlQuerier) GetWorkspaceSessionRecordings(ctx context.Context, arg GetWorkspaceSessionRecordingsParams) ([]GetWorkspaceSessionRecordingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceSessionRecordings, arg.WorkspaceID, arg.LimitOpt, arg.OffsetOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceSessionRecordingsRow
+	for rows.Next() {
+		var i GetWorkspaceSessionRecordingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.WorkspaceID,
+			&i.AgentID,
+			&i.Type,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.Size,
+			&i.CreatedAt,
+			&i.WorkspaceName,
+			&i.WorkspaceOwnerID,
+			&i.WorkspaceOwnerUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceSessionRecordingChunk = `-- name: InsertWorkspaceSessionRecordingChunk :exec
+WITH recording AS (
+	SELECT
+		id
+	FROM
+		workspace_session_recordings
+	WHERE
+		id = $1
+		AND agent_id = $2
+), inserted AS (
+	INSERT INTO
+		workspace_session_recording_chunks (recording_id, chunk_index, data)
+	SELECT
+		recording.id, $3, $4
+	FROM
+		recording
+	ON CONFLICT (recording_id, chunk_index) DO NOTHING
+	RETURNING recording_id, octet_length(data) AS size
+)
+UPDATE
+	workspace_session_recordings
+SET
+	size = workspace_session_recordings.size + inserted.size
+FROM
+	inserted
+WHERE
+	workspace_session_recordings.id = inserted.recording_id
+`
+
+type InsertWorkspaceSessionRecordingChunkParams struct {
+	RecordingID uuid.UUID `db:"recording_id" json:"recording_id"`
+	AgentID     uuid.UUID `db:"agent_id" json:"agent_id"`
+	ChunkIndex  int32     `db:"chunk_index" json:"chunk_index"`
+	Data        []byte    `db:"data" json:"data"`
+}
+
+// Chunks that were already inserted are ignored, so uploads can be retried.
+// Chunks are only inserted into recordings of the given agent.
+func (q *sqlQuerier) InsertWorkspaceSessionRecordingChunk(ctx context.Context, arg InsertWorkspaceSessionRecordingChunkParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceSessionRecordingChunk,
+		arg.RecordingID,
+		arg.AgentID,
+		arg.ChunkIndex,
+		arg.Data,
+	)
+	return err
+}
+
+const upsertWorkspaceSessionRecording = `-- name: UpsertWorkspaceSessionRecording :exec
+INSERT INTO
+	workspace_session_recordings (
+		id,
+		workspace_id,
+		agent_id,
+		type,
+		started_at,
+		ended_at,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (id) DO UPDATE
+SET
+	ended_at = COALESCE(EXCLUDED.ended_at, workspace_session_recordings.ended_at)
+WHERE
+	workspace_session_recordings.agent_id = EXCLUDED.agent_id
+`
+
+type UpsertWorkspaceSessionRecordingParams struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	WorkspaceID uuid.UUID            `db:"workspace_id" json:"workspace_id"`
+	AgentID     uuid.UUID            `db:"agent_id" json:"agent_id"`
+	Type        SessionRecordingType `db:"type" json:"type"`
+	StartedAt   time.Time            `db:"started_at" json:"started_at"`
+	EndedAt     sql.NullTime         `db:"ended_at" json:"ended_at"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+}
+
+// Recordings are uploaded in chunks, the first of which inserts the recording
+// and the last of which sets when it ended. Only the agent that inserted a
+// recording can update it.
+func (q *sqlQuerier) UpsertWorkspaceSessionRecording(ctx context.Context, arg UpsertWorkspaceSessionRecordingParams) error {
+	_, err := q.db.ExecContext(ctx, upsertWorkspaceSessionRecording,
+		arg.ID,
+		arg.WorkspaceID,
+		arg.AgentID,
+		arg.Type,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.CreatedAt,
+	)
+	return err
+}
@@ -17,6 +17,7 @@ SELECT
     users.avatar_url AS user_avatar_url,
     users.deleted AS user_deleted,
     users.quiet_hours_schedule AS user_quiet_hours_schedule,
+    users.is_service_account AS user_is_service_account,
     COALESCE(organizations.name, '') AS organization_name,
     COALESCE(organizations.display_name, '') AS organization_display_name,
     COALESCE(organizations.icon, '') AS organization_icon,
@@ -115,7 +115,8 @@ SET
 	name = @name,
 	display_name = @display_name,
 	avatar_url = @avatar_url,
-	quota_allowance = @quota_allowance
+	quota_allowance = @quota_allowance,
+	dlp_policy = @dlp_policy
 WHERE
 	id = @id
 RETURNING *;
@@ -4,6 +4,14 @@ SELECT
 FROM
 	provisioner_daemons;
 
+-- name: GetProvisionerDaemonByID :one
+SELECT
+	*
+FROM
+	provisioner_daemons
+WHERE
+	id = @id;
+
 -- name: GetProvisionerDaemonsByOrganization :many
 SELECT
 	*
@@ -110,6 +118,7 @@ WHERE
 	pd.organization_id = @organization_id::uuid
 	AND (COALESCE(array_length(@ids::uuid[], 1), 0) = 0 OR pd.id = ANY(@ids::uuid[]))
 	AND (@tags::tagset = 'null'::tagset OR provisioner_tagset_contains(pd.tags::tagset, @tags::tagset))
+	AND (@name::text = '' OR pd.name = @name::text)
 ORDER BY
 	pd.created_at DESC
 LIMIT
@@ -137,7 +146,8 @@ INSERT INTO
 		"version",
 		organization_id,
 		api_version,
-		key_id
+		key_id,
+		terraform_engine
 	)
 VALUES (
 	gen_random_uuid(),
@@ -149,7 +159,8 @@ VALUES (
 	@version,
 	@organization_id,
 	@api_version,
-	@key_id
+	@key_id,
+	@terraform_engine
 ) ON CONFLICT("organization_id", "name", LOWER(COALESCE(tags ->> 'owner'::text, ''::text))) DO UPDATE SET
 	provisioners = @provisioners,
 	tags = @tags,
@@ -157,9 +168,20 @@ VALUES (
 	"version" = @version,
 	api_version = @api_version,
 	organization_id = @organization_id,
-	key_id = @key_id
+	key_id = @key_id,
+	terraform_engine = @terraform_engine,
+	-- A reconnecting daemon is a new process, so a drain requested of the
+	-- previous one does not apply.
+	drain_requested_at = NULL
 RETURNING *;
 
+-- name: UpdateProvisionerDaemonDrainRequestedAt :exec
+UPDATE provisioner_daemons
+SET
+	drain_requested_at = @drain_requested_at
+WHERE
+	id = @id;
+
 -- name: UpdateProvisionerDaemonLastSeenAt :exec
 UPDATE provisioner_daemons
 SET
@@ -1,6 +1,11 @@
 -- Acquires the lock for a single job that isn't started, completed,
 -- canceled, and that matches an array of provisioner types.
 --
+-- Jobs are acquired in priority order. Within a priority, jobs initiated by
+-- users with the fewest running jobs go first, so a single user queueing many
+-- jobs can't starve everyone else. Provisioner daemons only serve a single
+-- organization, so organizations never compete for the same daemons.
+--
 -- SKIP LOCKED is used to jump over locked rows. This prevents
 -- multiple provisioners from acquiring the same jobs. See:
 -- https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
@@ -26,6 +31,16 @@ WHERE
 			-- they are aliases and the code that calls this query already relies on a different type
 			AND provisioner_tagset_contains(@provisioner_tags :: jsonb, potential_job.tags :: jsonb)
 		ORDER BY
+			potential_job.priority DESC,
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running_job
+				WHERE
+					running_job.initiator_id = potential_job.initiator_id
+					AND running_job.job_status = 'running'::provisioner_job_status
+			) ASC,
 			potential_job.created_at
 		FOR UPDATE
 		SKIP LOCKED
@@ -62,7 +77,7 @@ WITH filtered_provisioner_jobs AS (
 pending_jobs AS (
 	-- Step 2: Extract only pending jobs
 	SELECT
-		id, created_at, tags
+		id, created_at, priority, tags
 	FROM
 		provisioner_jobs
 	WHERE
@@ -73,7 +88,9 @@ ranked_jobs AS (
 	SELECT
 		pj.id,
 		pj.created_at,
-		ROW_NUMBER() OVER (PARTITION BY pd.id ORDER BY pj.created_at ASC) AS queue_position,
+		-- Fair-share acquisition can reorder jobs of the same priority, so
+		-- this is an estimate.
+		ROW_NUMBER() OVER (PARTITION BY pd.id ORDER BY pj.priority DESC, pj.created_at ASC) AS queue_position,
 		COUNT(*) OVER (PARTITION BY pd.id) AS queue_size
 	FROM
 		pending_jobs pj
@@ -112,7 +129,7 @@ ORDER BY
 -- name: GetProvisionerJobsByOrganizationAndStatusWithQueuePositionAndProvisioner :many
 WITH pending_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, priority
     FROM
         provisioner_jobs
     WHERE
@@ -127,7 +144,7 @@ WITH pending_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (ORDER BY priority DESC, created_at ASC) AS queue_position
     FROM
         pending_jobs
 ),
@@ -209,6 +226,25 @@ LIMIT
 -- name: GetProvisionerJobsCreatedAfter :many
 SELECT * FROM provisioner_jobs WHERE created_at > $1;
 
+-- name: GetPendingProvisionerJobCounts :many
+-- Counts the jobs waiting for a provisioner daemon, grouped by the
+-- provisioner and tags a daemon needs to acquire them.
+SELECT
+	organizations.name AS organization_name,
+	provisioner_jobs.provisioner,
+	provisioner_jobs.tags,
+	COUNT(*) AS count
+FROM
+	provisioner_jobs
+JOIN
+	organizations ON organizations.id = provisioner_jobs.organization_id
+WHERE
+	provisioner_jobs.job_status = 'pending'
+GROUP BY
+	organizations.name,
+	provisioner_jobs.provisioner,
+	provisioner_jobs.tags;
+
 -- name: InsertProvisionerJob :one
 INSERT INTO
 	provisioner_jobs (
@@ -223,10 +259,11 @@ INSERT INTO
 		"type",
 		"input",
 		tags,
-		trace_metadata
+		trace_metadata,
+		priority
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING *;
 
 -- name: UpdateProvisionerJobByID :exec
 UPDATE
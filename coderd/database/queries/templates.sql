@@ -124,7 +124,11 @@ SET
 	display_name = $6,
 	allow_user_cancel_workspace_jobs = $7,
 	group_acl = $8,
-	max_port_sharing_level = $9
+	max_port_sharing_level = $9,
+	drift_detection_interval = $10,
+	session_recording_enabled = $11,
+	session_recording_retention = $12,
+	dlp_policy = $13
 WHERE
 	id = $1
 ;
@@ -13,6 +13,8 @@ SET
 WHERE
 	id = @user_id
 	AND NOT is_system
+	-- Service accounts cannot log in interactively.
+	AND NOT is_service_account
 RETURNING *;
 
 -- name: GetUserByID :one
@@ -58,7 +60,9 @@ FROM
 	users
 WHERE
 	status = 'active'::user_status AND deleted = false
-	AND CASE WHEN @include_system::bool THEN TRUE ELSE is_system = false END;
+	AND CASE WHEN @include_system::bool THEN TRUE ELSE is_system = false END
+	-- Service accounts don't consume seats.
+	AND NOT is_service_account;
 
 -- name: InsertUser :one
 INSERT INTO
@@ -72,13 +76,17 @@ INSERT INTO
 		updated_at,
 		rbac_roles,
 		login_type,
-		status
+		status,
+		is_service_account,
+		service_account_organization_id
 	)
 VALUES
 	($1, $2, $3, $4, $5, $6, $7, $8, $9,
 		-- if the status passed in is empty, fallback to dormant, which is what
 		-- we were doing before.
-		COALESCE(NULLIF(@status::text, '')::user_status, 'dormant'::user_status)
+		COALESCE(NULLIF(@status::text, '')::user_status, 'dormant'::user_status),
+		@is_service_account,
+		@service_account_organization_id
 	) RETURNING *;
 
 -- name: UpdateUserProfile :one
@@ -359,6 +367,8 @@ WHERE
     last_seen_at < @last_seen_after :: timestamp
     AND status = 'active'::user_status
 		AND NOT is_system
+		-- Service accounts are used by automation, so they never go dormant.
+		AND NOT is_service_account
 RETURNING id, email, username, last_seen_at;
 
 -- AllUserIDs returns all UserIDs regardless of user status or deletion.
@@ -0,0 +1,65 @@
+-- name: GetUserSecrets :many
+SELECT
+	*
+FROM
+	user_secrets
+WHERE
+	user_id = $1
+ORDER BY
+	name ASC;
+
+-- name: GetUserSecretByID :one
+SELECT
+	*
+FROM
+	user_secrets
+WHERE
+	id = $1;
+
+-- name: GetUserSecretByUserIDAndName :one
+SELECT
+	*
+FROM
+	user_secrets
+WHERE
+	user_id = $1 AND name = $2;
+
+-- name: InsertUserSecret :one
+INSERT INTO
+	user_secrets (
+		id,
+		user_id,
+		name,
+		description,
+		value,
+		value_key_id,
+		env_name,
+		file_path,
+		template_ids,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *;
+
+-- name: UpdateUserSecret :one
+UPDATE
+	user_secrets
+SET
+	description = $2,
+	value = $3,
+	value_key_id = $4,
+	env_name = $5,
+	file_path = $6,
+	template_ids = $7,
+	updated_at = $8
+WHERE
+	id = $1
+RETURNING
+	*;
+
+-- name: DeleteUserSecret :exec
+DELETE FROM
+	user_secrets
+WHERE
+	id = $1;
@@ -0,0 +1,56 @@
+-- name: GetWorkspaceAgentPTYShare :one
+SELECT
+	*
+FROM
+	workspace_agent_pty_shares
+WHERE
+	agent_id = $1
+	AND reconnect_id = $2
+	AND user_id = $3;
+
+-- name: ListWorkspaceAgentPTYShares :many
+SELECT
+	*
+FROM
+	workspace_agent_pty_shares
+WHERE
+	agent_id = $1
+ORDER BY
+	created_at ASC;
+
+-- name: UpsertWorkspaceAgentPTYShare :one
+INSERT INTO
+	workspace_agent_pty_shares (
+		workspace_id,
+		agent_id,
+		reconnect_id,
+		user_id,
+		mode,
+		created_by,
+		created_at
+	)
+VALUES (
+	$1,
+	$2,
+	$3,
+	$4,
+	$5,
+	$6,
+	$7
+)
+ON CONFLICT (
+	agent_id,
+	reconnect_id,
+	user_id
+)
+DO UPDATE SET
+	mode = $5
+RETURNING *;
+
+-- name: DeleteWorkspaceAgentPTYShare :exec
+DELETE FROM
+	workspace_agent_pty_shares
+WHERE
+	agent_id = $1
+	AND reconnect_id = $2
+	AND user_id = $3;
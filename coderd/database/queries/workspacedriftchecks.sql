@@ -0,0 +1,89 @@
+-- name: InsertWorkspaceDriftCheck :one
+INSERT INTO
+	workspace_drift_checks (
+		job_id,
+		workspace_id,
+		workspace_build_id,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4) RETURNING *;
+
+-- name: GetWorkspaceDriftCheckByJobID :one
+SELECT
+	*
+FROM
+	workspace_drift_checks
+WHERE
+	job_id = $1;
+
+-- name: UpdateWorkspaceDriftCheckByJobID :exec
+UPDATE
+	workspace_drift_checks
+SET
+	checked_at = $2,
+	resources = $3
+WHERE
+	job_id = $1;
+
+-- name: GetLatestWorkspaceDriftChecksByWorkspaceIDs :many
+-- Returns the most recent completed drift check of each workspace.
+SELECT
+	DISTINCT ON (workspace_id) *
+FROM
+	workspace_drift_checks
+WHERE
+	workspace_id = ANY(@ids :: uuid [ ])
+	AND checked_at IS NOT NULL
+ORDER BY
+	workspace_id, checked_at DESC;
+
+-- name: GetWorkspacesDueDriftCheck :many
+-- Returns the latest builds of running workspaces whose templates have drift
+-- detection enabled, and that haven't had a drift check requested within the
+-- template's interval. Failed checks count as requested, so they aren't retried
+-- until the interval has passed. Workspaces that were checked least recently
+-- come first, so that a limited batch doesn't starve any workspace.
+SELECT
+	workspaces.id AS workspace_id,
+	workspaces.owner_id,
+	workspace_latest_builds.id AS workspace_build_id
+FROM
+	workspaces
+INNER JOIN
+	templates ON templates.id = workspaces.template_id
+INNER JOIN
+	workspace_latest_builds ON workspace_latest_builds.workspace_id = workspaces.id
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_latest_builds.job_id
+WHERE
+	workspaces.deleted = false
+	AND workspaces.dormant_at IS NULL
+	AND workspaces.owner_id != 'c42fdf75-3097-471c-8c33-fb52454d81c0'::uuid -- The system user responsible for prebuilds.
+	AND templates.deleted = false
+	AND templates.drift_detection_interval > 0
+	AND workspace_latest_builds.transition = 'start'::workspace_transition
+	AND workspace_latest_builds.job_status = 'succeeded'::provisioner_job_status
+	AND provisioner_jobs.completed_at < @now :: timestamptz - (templates.drift_detection_interval / 1000 / 1000 / 1000 || ' seconds')::interval
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_drift_checks
+		WHERE
+			workspace_drift_checks.workspace_id = workspaces.id
+			AND workspace_drift_checks.created_at > @now :: timestamptz - (templates.drift_detection_interval / 1000 / 1000 / 1000 || ' seconds')::interval
+	)
+ORDER BY
+	(
+		SELECT
+			MAX(workspace_drift_checks.created_at)
+		FROM
+			workspace_drift_checks
+		WHERE
+			workspace_drift_checks.workspace_id = workspaces.id
+	) ASC NULLS FIRST,
+	provisioner_jobs.completed_at ASC,
+	workspaces.id ASC
+LIMIT
+	@limit_count :: int;
@@ -0,0 +1,115 @@
+-- name: UpsertWorkspaceSessionRecording :exec
+-- Recordings are uploaded in chunks, the first of which inserts the recording
+-- and the last of which sets when it ended. Only the agent that inserted a
+-- recording can update it.
+INSERT INTO
+	workspace_session_recordings (
+		id,
+		workspace_id,
+		agent_id,
+		type,
+		started_at,
+		ended_at,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (id) DO UPDATE
+SET
+	ended_at = COALESCE(EXCLUDED.ended_at, workspace_session_recordings.ended_at)
+WHERE
+	workspace_session_recordings.agent_id = EXCLUDED.agent_id;
+
+-- name: InsertWorkspaceSessionRecordingChunk :exec
+-- Chunks that were already inserted are ignored, so uploads can be retried.
+-- Chunks are only inserted into recordings of the given agent.
+WITH recording AS (
+	SELECT
+		id
+	FROM
+		workspace_session_recordings
+	WHERE
+		id = @recording_id
+		AND agent_id = @agent_id
+), inserted AS (
+	INSERT INTO
+		workspace_session_recording_chunks (recording_id, chunk_index, data)
+	SELECT
+		recording.id, @chunk_index, @data
+	FROM
+		recording
+	ON CONFLICT (recording_id, chunk_index) DO NOTHING
+	RETURNING recording_id, octet_length(data) AS size
+)
+UPDATE
+	workspace_session_recordings
+SET
+	size = workspace_session_recordings.size + inserted.size
+FROM
+	inserted
+WHERE
+	workspace_session_recordings.id = inserted.recording_id;
+
+-- name: GetWorkspaceSessionRecordingByID :one
+SELECT
+	workspace_session_recordings.*,
+	workspaces.name AS workspace_name,
+	workspaces.owner_id AS workspace_owner_id,
+	visible_users.username AS workspace_owner_username
+FROM
+	workspace_session_recordings
+INNER JOIN
+	workspaces ON workspaces.id = workspace_session_recordings.workspace_id
+INNER JOIN
+	visible_users ON visible_users.id = workspaces.owner_id
+WHERE
+	workspace_session_recordings.id = $1;
+
+-- name: GetWorkspaceSessionRecordings :many
+SELECT
+	workspace_session_recordings.*,
+	workspaces.name AS workspace_name,
+	workspaces.owner_id AS workspace_owner_id,
+	visible_users.username AS workspace_owner_username
+FROM
+	workspace_session_recordings
+INNER JOIN
+	workspaces ON workspaces.id = workspace_session_recordings.workspace_id
+INNER JOIN
+	visible_users ON visible_users.id = workspaces.owner_id
+WHERE
+	CASE
+		WHEN @workspace_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			workspace_session_recordings.workspace_id = @workspace_id
+		ELSE true
+	END
+ORDER BY
+	workspace_session_recordings.started_at DESC
+LIMIT
+	-- A limit of 0 means "no limit".
+	NULLIF(@limit_opt :: int, 0)
+OFFSET
+	@offset_opt;
+
+-- name: GetWorkspaceSessionRecordingChunks :many
+SELECT
+	*
+FROM
+	workspace_session_recording_chunks
+WHERE
+	recording_id = $1
+ORDER BY
+	chunk_index ASC;
+
+-- name: DeleteOldWorkspaceSessionRecordings :exec
+-- Deletes recordings that are older than the retention of their template.
+-- Templates with a retention of 0 keep their recordings forever.
+DELETE FROM
+	workspace_session_recordings
+USING
+	workspaces, templates
+WHERE
+	workspace_session_recordings.workspace_id = workspaces.id
+	AND workspaces.template_id = templates.id
+	AND templates.session_recording_retention > 0
+	AND workspace_session_recordings.started_at < @before :: timestamptz - (templates.session_recording_retention / 1000 / 1000 / 1000 || ' seconds')::interval;
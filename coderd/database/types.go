@@ -70,6 +70,32 @@ func (t TemplateACL) Value() (driver.Value, error) {
 	return json.Marshal(t)
 }
 
+// DLPPolicy restricts how data can be transferred out of or into a
+// workspace. It's stored on templates and groups.
+type DLPPolicy struct {
+	BlockSFTP                 bool `json:"block_sftp,omitempty"`
+	BlockSCP                  bool `json:"block_scp,omitempty"`
+	BlockLocalPortForwarding  bool `json:"block_local_port_forwarding,omitempty"`
+	BlockRemotePortForwarding bool `json:"block_remote_port_forwarding,omitempty"`
+	BlockX11Forwarding        bool `json:"block_x11_forwarding,omitempty"`
+	BlockClipboardCopy        bool `json:"block_clipboard_copy,omitempty"`
+	BlockClipboardPaste       bool `json:"block_clipboard_paste,omitempty"`
+}
+
+func (p *DLPPolicy) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), &p)
+	case []byte:
+		return json.Unmarshal(v, &p)
+	}
+	return xerrors.Errorf("unexpected type %T", src)
+}
+
+func (p DLPPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
 type ExternalAuthProvider struct {
 	ID       string `json:"id"`
 	Optional bool   `json:"optional,omitempty"`
@@ -232,3 +258,32 @@ func (a *UserLinkClaims) Scan(src interface{}) error {
 func (a UserLinkClaims) Value() (driver.Value, error) {
 	return json.Marshal(a)
 }
+
+// WorkspaceDriftResource is a resource that a workspace drift check found had
+// changed outside of Coder.
+type WorkspaceDriftResource struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	// Action is "update" or "delete".
+	Action string `json:"action"`
+}
+
+type WorkspaceDriftResources []WorkspaceDriftResource
+
+func (r *WorkspaceDriftResources) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), &r)
+	case []byte:
+		return json.Unmarshal(v, &r)
+	}
+	return xerrors.Errorf("unexpected type %T", src)
+}
+
+func (r WorkspaceDriftResources) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
@@ -0,0 +1,199 @@
+// Package driftdetect periodically queues refresh-only plans against running
+// workspaces, so changes made to their infrastructure outside of Coder are
+// noticed.
+package driftdetect
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/database/provisionerjobs"
+	"github.com/coder/coder/v2/coderd/database/pubsub"
+	"github.com/coder/coder/v2/coderd/provisionerdserver"
+)
+
+// MaxChecksPerRun is the maximum number of drift checks queued in a single
+// run. Workspaces left over are picked up on the next tick.
+const MaxChecksPerRun = 100
+
+// Executor queues drift checks for running workspaces whose template has
+// drift detection enabled.
+type Executor struct {
+	ctx     context.Context
+	db      database.Store
+	ps      pubsub.Pubsub
+	log     slog.Logger
+	tick    <-chan time.Time
+	statsCh chan<- Stats
+}
+
+// Stats contains information about one run of Executor.
+type Stats struct {
+	// Jobs maps the IDs of workspaces checked in this run to the IDs of the
+	// provisioner jobs queued for them.
+	Jobs    map[uuid.UUID]uuid.UUID
+	Elapsed time.Duration
+	Error   error
+}
+
+// NewExecutor returns a new drift detection executor.
+func NewExecutor(ctx context.Context, db database.Store, ps pubsub.Pubsub, log slog.Logger, tick <-chan time.Time) *Executor {
+	return &Executor{
+		//nolint:gocritic // Drift detection queues jobs on behalf of workspace owners.
+		ctx:  dbauthz.AsSystemRestricted(ctx),
+		db:   db,
+		ps:   ps,
+		log:  log.Named("driftdetect"),
+		tick: tick,
+	}
+}
+
+// WithStatsChannel will cause Executor to push a Stats to ch after every
+// tick.
+func (e *Executor) WithStatsChannel(ch chan<- Stats) *Executor {
+	e.statsCh = ch
+	return e
+}
+
+// Run will cause the executor to queue drift checks on every tick from its
+// channel. It will stop when its context is Done, or when its channel is
+// closed.
+func (e *Executor) Run() {
+	go func() {
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case t, ok := <-e.tick:
+				if !ok {
+					return
+				}
+				stats := e.runOnce(t)
+				if stats.Error != nil {
+					e.log.Warn(e.ctx, "failed to queue drift checks", slog.Error(stats.Error))
+				}
+				if e.statsCh != nil {
+					select {
+					case <-e.ctx.Done():
+						return
+					case e.statsCh <- stats:
+					}
+				}
+				e.log.Debug(e.ctx, "run stats", slog.F("elapsed", stats.Elapsed), slog.F("jobs", len(stats.Jobs)))
+			}
+		}
+	}()
+}
+
+func (e *Executor) runOnce(t time.Time) Stats {
+	stats := Stats{
+		Jobs: make(map[uuid.UUID]uuid.UUID),
+	}
+	defer func() {
+		stats.Elapsed = time.Since(t)
+	}()
+
+	var jobs []database.ProvisionerJob
+	err := e.db.InTx(func(tx database.Store) error {
+		// Only one replica queues checks at a time, otherwise a workspace
+		// could be checked twice.
+		ok, err := tx.TryAcquireLock(e.ctx, database.LockIDDriftDetection)
+		if err != nil {
+			return xerrors.Errorf("try acquire drift detection lock: %w", err)
+		}
+		if !ok {
+			e.log.Debug(e.ctx, "unable to acquire drift detection lock, skipping")
+			return nil
+		}
+
+		due, err := tx.GetWorkspacesDueDriftCheck(e.ctx, database.GetWorkspacesDueDriftCheckParams{
+			Now:        t,
+			LimitCount: MaxChecksPerRun,
+		})
+		if err != nil {
+			return xerrors.Errorf("get workspaces due a drift check: %w", err)
+		}
+		for _, workspace := range due {
+			job, err := queueCheck(e.ctx, tx, workspace)
+			if err != nil {
+				return xerrors.Errorf("queue drift check for workspace %s: %w", workspace.WorkspaceID, err)
+			}
+			jobs = append(jobs, job)
+			stats.Jobs[workspace.WorkspaceID] = job.ID
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		stats.Jobs = make(map[uuid.UUID]uuid.UUID)
+		stats.Error = err
+		return stats
+	}
+
+	for _, job := range jobs {
+		err := provisionerjobs.PostJob(e.ps, job)
+		if err != nil {
+			// Client probably doesn't care about this error, so just log it.
+			e.log.Error(e.ctx, "failed to post provisioner job to pubsub", slog.F("job_id", job.ID), slog.Error(err))
+		}
+	}
+	return stats
+}
+
+// queueCheck inserts a drift check job that runs against the state of the
+// workspace's latest build, on the same provisioners that ran the build.
+func queueCheck(ctx context.Context, tx database.Store, workspace database.GetWorkspacesDueDriftCheckRow) (database.ProvisionerJob, error) {
+	build, err := tx.GetWorkspaceBuildByID(ctx, workspace.WorkspaceBuildID)
+	if err != nil {
+		return database.ProvisionerJob{}, xerrors.Errorf("get workspace build: %w", err)
+	}
+	buildJob, err := tx.GetProvisionerJobByID(ctx, build.JobID)
+	if err != nil {
+		return database.ProvisionerJob{}, xerrors.Errorf("get workspace build job: %w", err)
+	}
+	input, err := json.Marshal(provisionerdserver.WorkspaceDriftCheckJob{
+		WorkspaceBuildID: build.ID,
+	})
+	if err != nil {
+		return database.ProvisionerJob{}, xerrors.Errorf("marshal job input: %w", err)
+	}
+
+	now := dbtime.Now()
+	job, err := tx.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		InitiatorID:    workspace.OwnerID,
+		OrganizationID: buildJob.OrganizationID,
+		Provisioner:    buildJob.Provisioner,
+		Type:           database.ProvisionerJobTypeWorkspaceDriftCheck,
+		StorageMethod:  buildJob.StorageMethod,
+		FileID:         buildJob.FileID,
+		Input:          input,
+		Tags:           buildJob.Tags,
+		TraceMetadata:  pqtype.NullRawMessage{},
+		Priority:       database.ProvisionerJobPriorityBackground,
+	})
+	if err != nil {
+		return database.ProvisionerJob{}, xerrors.Errorf("insert provisioner job: %w", err)
+	}
+	_, err = tx.InsertWorkspaceDriftCheck(ctx, database.InsertWorkspaceDriftCheckParams{
+		JobID:            job.ID,
+		WorkspaceID:      workspace.WorkspaceID,
+		WorkspaceBuildID: build.ID,
+		CreatedAt:        now,
+	})
+	if err != nil {
+		return database.ProvisionerJob{}, xerrors.Errorf("insert workspace drift check: %w", err)
+	}
+	return job, nil
+}
@@ -0,0 +1,111 @@
+package driftdetect_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/driftdetect"
+	"github.com/coder/coder/v2/coderd/notifications"
+	"github.com/coder/coder/v2/coderd/notifications/notificationstest"
+	"github.com/coder/coder/v2/coderd/util/ptr"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/provisioner/echo"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m, testutil.GoleakOptions...)
+}
+
+func TestExecutor(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ticker    = make(chan time.Time)
+		statsCh   = make(chan driftdetect.Stats)
+		notifyEnq = notificationstest.FakeEnqueuer{}
+		client    = coderdtest.New(t, &coderdtest.Options{
+			DriftDetectionTicker:     ticker,
+			DriftDetectionStats:      statsCh,
+			IncludeProvisionerDaemon: true,
+			NotificationsEnqueuer:    &notifyEnq,
+		})
+		owner   = coderdtest.CreateFirstUser(t, client)
+		version = coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Response{{
+				Type: &proto.Response_Plan{
+					Plan: &proto.PlanComplete{
+						ResourceDrift: []*proto.ResourceDrift{{
+							Address: "docker_container.workspace[0]",
+							Type:    "docker_container",
+							Name:    "workspace",
+							Action:  "delete",
+						}},
+					},
+				},
+			}},
+			ProvisionApply: echo.ApplyComplete,
+		})
+	)
+	coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	userClient, user := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	workspace := coderdtest.CreateWorkspace(t, userClient, template.ID)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, userClient, workspace.LatestBuild.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	// Drift detection is disabled by default.
+	ticker <- time.Now().Add(24 * time.Hour)
+	stats := testutil.TryReceive(ctx, t, statsCh)
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Jobs)
+
+	template, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		DriftDetectionIntervalMillis: ptr.Ref(time.Hour.Milliseconds()),
+	})
+	require.NoError(t, err)
+	require.Equal(t, time.Hour.Milliseconds(), template.DriftDetectionIntervalMillis)
+
+	// The workspace was just built, so it isn't due a check yet.
+	ticker <- time.Now()
+	stats = testutil.TryReceive(ctx, t, statsCh)
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Jobs)
+
+	ticker <- time.Now().Add(2 * time.Hour)
+	stats = testutil.TryReceive(ctx, t, statsCh)
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Jobs, 1)
+	require.Contains(t, stats.Jobs, workspace.ID)
+
+	require.Eventually(t, func() bool {
+		workspace, err = userClient.Workspace(ctx, workspace.ID)
+		return err == nil && workspace.Drift != nil
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Equal(t, workspace.LatestBuild.ID, workspace.Drift.WorkspaceBuildID)
+	require.Equal(t, []codersdk.WorkspaceDriftResource{{
+		Address: "docker_container.workspace[0]",
+		Type:    "docker_container",
+		Name:    "workspace",
+		Action:  "delete",
+	}}, workspace.Drift.Resources)
+
+	sent := notifyEnq.Sent(notificationstest.WithTemplateID(notifications.TemplateWorkspaceDriftDetected))
+	require.Len(t, sent, 1)
+	require.Equal(t, user.ID, sent[0].UserID)
+	require.Equal(t, workspace.Name, sent[0].Labels["workspace"])
+
+	// Rebuilding the workspace clears the drift.
+	build := coderdtest.CreateWorkspaceBuild(t, userClient, workspace, database.WorkspaceTransitionStart)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, userClient, build.ID)
+	workspace = coderdtest.MustWorkspace(t, userClient, workspace.ID)
+	require.Nil(t, workspace.Drift)
+}
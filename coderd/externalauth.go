@@ -39,6 +39,12 @@ func (api *API) externalAuthByID(w http.ResponseWriter, r *http.Request) {
 		DisplayName:      config.DisplayName,
 		AppInstallations: []codersdk.ExternalAuthAppInstallation{},
 	}
+	// Brokers issue credentials without the user authenticating.
+	if config.Broker != nil {
+		res.Authenticated = true
+		httpapi.Write(ctx, w, http.StatusOK, res)
+		return
+	}
 
 	link, err := api.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
 		ProviderID: config.ID,
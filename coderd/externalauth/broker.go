@@ -0,0 +1,175 @@
+package externalauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/codersdk"
+)
+
+const (
+	// brokerExpiryLeeway is how long before its expiry a brokered credential
+	// is replaced, so callers never receive one that's about to expire.
+	brokerExpiryLeeway = time.Minute
+	// brokerTimeout bounds requests to a broker. Credentials are issued while
+	// provisioner jobs are acquired, which must not hang on a slow broker.
+	brokerTimeout = 30 * time.Second
+)
+
+// Broker issues short-lived credentials for an external auth provider from a
+// secrets backend. Providers with a broker don't use OAuth2: users never link
+// an account, and a credential is issued whenever one is needed.
+type Broker interface {
+	Issue(ctx context.Context, req BrokerRequest) (BrokerToken, error)
+}
+
+// BrokerRequest identifies the user a credential is issued for.
+type BrokerRequest struct {
+	ProviderID string    `json:"provider_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+}
+
+// BrokerToken is a credential issued by a broker.
+type BrokerToken struct {
+	AccessToken string `json:"access_token"`
+	// Expiry is zero if the credential doesn't expire.
+	Expiry time.Time `json:"expires_at"`
+	// Extra is returned to the workspace as the token extra of the
+	// credential. e.g. the key ID and session token of cloud credentials.
+	Extra map[string]interface{} `json:"token_extra,omitempty"`
+}
+
+// NewBroker returns the broker of the given type.
+func NewBroker(brokerType codersdk.ExternalAuthBrokerType, brokerURL, token, tokenField string) (Broker, error) {
+	if brokerURL == "" {
+		return nil, xerrors.New("broker url must be provided")
+	}
+	switch brokerType {
+	case codersdk.ExternalAuthBrokerTypeVault:
+		if tokenField == "" {
+			tokenField = "token"
+		}
+		return &vaultBroker{
+			client:     &http.Client{Timeout: brokerTimeout},
+			url:        brokerURL,
+			token:      token,
+			tokenField: tokenField,
+		}, nil
+	case codersdk.ExternalAuthBrokerTypeHTTP:
+		return &httpBroker{
+			client: &http.Client{Timeout: brokerTimeout},
+			url:    brokerURL,
+			token:  token,
+		}, nil
+	default:
+		return nil, xerrors.Errorf("unknown broker type %q, must be one of %q or %q", brokerType, codersdk.ExternalAuthBrokerTypeVault, codersdk.ExternalAuthBrokerTypeHTTP)
+	}
+}
+
+// vaultBroker reads credentials from a HashiCorp Vault compatible secrets
+// engine, e.g. "https://vault.example.com/v1/aws/sts/deploy".
+type vaultBroker struct {
+	client *http.Client
+	url    string
+	token  string
+	// tokenField is the key in the data of the secret that holds the
+	// access token. Every key of the data is returned as token extra.
+	tokenField string
+}
+
+func (b *vaultBroker) Issue(ctx context.Context, _ BrokerRequest) (BrokerToken, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url, nil)
+	if err != nil {
+		return BrokerToken{}, xerrors.Errorf("create request: %w", err)
+	}
+	if b.token != "" {
+		req.Header.Set("X-Vault-Token", b.token)
+	}
+	var secret struct {
+		LeaseDuration int64                  `json:"lease_duration"`
+		Data          map[string]interface{} `json:"data"`
+	}
+	err = doBrokerRequest(b.client, req, &secret)
+	if err != nil {
+		return BrokerToken{}, err
+	}
+	accessToken, ok := secret.Data[b.tokenField].(string)
+	if !ok || accessToken == "" {
+		return BrokerToken{}, xerrors.Errorf("secret has no %q string field", b.tokenField)
+	}
+	token := BrokerToken{
+		AccessToken: accessToken,
+		Extra:       secret.Data,
+	}
+	if secret.LeaseDuration > 0 {
+		token.Expiry = time.Now().Add(time.Duration(secret.LeaseDuration) * time.Second)
+	}
+	return token, nil
+}
+
+// httpBroker requests credentials from an HTTP endpoint. The BrokerRequest is
+// posted as JSON, and the endpoint responds with a BrokerToken.
+type httpBroker struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+func (b *httpBroker) Issue(ctx context.Context, brokerReq BrokerRequest) (BrokerToken, error) {
+	body, err := json.Marshal(brokerReq)
+	if err != nil {
+		return BrokerToken{}, xerrors.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return BrokerToken{}, xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	var token BrokerToken
+	err = doBrokerRequest(b.client, req, &token)
+	if err != nil {
+		return BrokerToken{}, err
+	}
+	if token.AccessToken == "" {
+		return BrokerToken{}, xerrors.New("response has no access token")
+	}
+	return token, nil
+}
+
+func doBrokerRequest(client *http.Client, req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("request credential: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return xerrors.Errorf("request credential: unexpected status %d: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+	err = json.NewDecoder(res.Body).Decode(v)
+	if err != nil {
+		return xerrors.Errorf("decode credential: %w", err)
+	}
+	return nil
+}
+
+// brokerTokenValid reports whether a stored brokered credential can still be
+// handed out.
+func brokerTokenValid(accessToken string, expiry time.Time, now time.Time) bool {
+	if accessToken == "" {
+		return false
+	}
+	return expiry.IsZero() || expiry.After(now.Add(brokerExpiryLeeway))
+}
@@ -37,6 +37,9 @@ type Config struct {
 	Type string
 	// DeviceAuth is set if the provider uses the device flow.
 	DeviceAuth *DeviceAuth
+	// Broker is set if the provider issues credentials from a secrets
+	// backend instead of OAuth2.
+	Broker Broker
 	// DisplayName is the name of the provider to display to the user.
 	DisplayName string
 	// DisplayIcon is the path to an image that will be displayed to the user.
@@ -111,6 +114,15 @@ func IsInvalidTokenError(err error) bool {
 // If an error is returned, the token is either invalid, or an error occurred.
 // Use 'IsInvalidTokenError(err)' to determine the difference.
 func (c *Config) RefreshToken(ctx context.Context, db database.Store, externalAuthLink database.ExternalAuthLink) (database.ExternalAuthLink, error) {
+	// Brokered credentials are replaced rather than refreshed, which
+	// requires the user. See IssueToken.
+	if c.Broker != nil {
+		if !brokerTokenValid(externalAuthLink.OAuthAccessToken, externalAuthLink.OAuthExpiry, dbtime.Now()) {
+			return externalAuthLink, InvalidTokenError("brokered credential expired, a new one is issued when it's next requested")
+		}
+		return externalAuthLink, nil
+	}
+
 	// If the token is expired and refresh is disabled, we prompt
 	// the user to authenticate again.
 	if c.NoRefresh &&
@@ -234,6 +246,76 @@ validate:
 	return externalAuthLink, nil
 }
 
+// IssueToken returns a credential from the broker of the provider for the
+// user. The credential is stored in the user's external auth link, and is
+// reused until it's about to expire.
+func (c *Config) IssueToken(ctx context.Context, db database.Store, req BrokerRequest) (database.ExternalAuthLink, error) {
+	if c.Broker == nil {
+		return database.ExternalAuthLink{}, xerrors.Errorf("external auth provider %q has no broker", c.ID)
+	}
+	existing, err := db.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
+		ProviderID: c.ID,
+		UserID:     req.UserID,
+	})
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return database.ExternalAuthLink{}, xerrors.Errorf("get external auth link: %w", err)
+	}
+	exists := err == nil
+	if exists && brokerTokenValid(existing.OAuthAccessToken, existing.OAuthExpiry, dbtime.Now()) {
+		return existing, nil
+	}
+
+	req.ProviderID = c.ID
+	token, err := c.Broker.Issue(ctx, req)
+	if err != nil {
+		return existing, xerrors.Errorf("issue credential: %w", err)
+	}
+	extra := pqtype.NullRawMessage{}
+	if len(token.Extra) > 0 {
+		data, err := json.Marshal(token.Extra)
+		if err != nil {
+			return existing, xerrors.Errorf("marshal token extra: %w", err)
+		}
+		extra = pqtype.NullRawMessage{RawMessage: data, Valid: true}
+	}
+
+	if !exists {
+		link, err := db.InsertExternalAuthLink(ctx, database.InsertExternalAuthLinkParams{
+			ProviderID:             c.ID,
+			UserID:                 req.UserID,
+			CreatedAt:              dbtime.Now(),
+			UpdatedAt:              dbtime.Now(),
+			OAuthAccessToken:       token.AccessToken,
+			OAuthAccessTokenKeyID:  sql.NullString{}, // dbcrypt will set as required
+			OAuthRefreshTokenKeyID: sql.NullString{}, // dbcrypt will set as required
+			OAuthExpiry:            token.Expiry,
+			OAuthExtra:             extra,
+		})
+		if err == nil {
+			return link, nil
+		}
+		// A credential was issued for the user concurrently, e.g. by
+		// builds of two workspaces. Replace it with this one instead.
+		if !database.IsUniqueViolation(err, database.UniqueGitAuthLinksProviderIDUserIDKey) {
+			return link, xerrors.Errorf("insert external auth link: %w", err)
+		}
+	}
+	link, err := db.UpdateExternalAuthLink(ctx, database.UpdateExternalAuthLinkParams{
+		ProviderID:             c.ID,
+		UserID:                 req.UserID,
+		UpdatedAt:              dbtime.Now(),
+		OAuthAccessToken:       token.AccessToken,
+		OAuthAccessTokenKeyID:  sql.NullString{}, // dbcrypt will update as required
+		OAuthRefreshTokenKeyID: sql.NullString{}, // dbcrypt will update as required
+		OAuthExpiry:            token.Expiry,
+		OAuthExtra:             extra,
+	})
+	if err != nil {
+		return link, xerrors.Errorf("update external auth link: %w", err)
+	}
+	return link, nil
+}
+
 // ValidateToken ensures the Git token provided is valid!
 // The user is optionally returned if the provider supports it.
 func (c *Config) ValidateToken(ctx context.Context, link *oauth2.Token) (bool, *codersdk.ExternalAuthUser, error) {
@@ -516,7 +598,7 @@ func ConvertConfig(instrument *promoauth.Factory, entries []codersdk.ExternalAut
 		if valid != nil {
 			return nil, xerrors.Errorf("external auth provider %q doesn't have a valid id: %w", entry.ID, valid)
 		}
-		if entry.ClientID == "" {
+		if entry.ClientID == "" && entry.BrokerType == "" {
 			return nil, xerrors.Errorf("%q external auth provider: client_id must be provided", entry.ID)
 		}
 
@@ -584,6 +666,16 @@ func ConvertConfig(instrument *promoauth.Factory, entries []codersdk.ExternalAut
 			ExtraTokenKeys:           entry.ExtraTokenKeys,
 		}
 
+		if entry.BrokerType != "" {
+			if entry.DeviceFlow {
+				return nil, xerrors.Errorf("external auth provider %q: device flow can't be used with a broker", entry.ID)
+			}
+			cfg.Broker, err = NewBroker(entry.BrokerType, entry.BrokerURL, entry.BrokerToken, entry.BrokerTokenField)
+			if err != nil {
+				return nil, xerrors.Errorf("external auth provider %q: %w", entry.ID, err)
+			}
+		}
+
 		if entry.DeviceFlow {
 			if entry.DeviceCodeURL == "" {
 				return nil, xerrors.Errorf("external auth provider %q: device auth url must be provided", entry.ID)
@@ -375,6 +375,143 @@ func TestRefreshToken(t *testing.T) {
 	})
 }
 
+func TestIssueToken(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Vault", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"lease_duration": 3600,
+				"data": map[string]interface{}{
+					"access_key":     "key",
+					"security_token": "session",
+				},
+			})
+		}))
+		t.Cleanup(srv.Close)
+
+		broker, err := externalauth.NewBroker(codersdk.ExternalAuthBrokerTypeVault, srv.URL, "vault-token", "security_token")
+		require.NoError(t, err)
+		config := &externalauth.Config{ID: "aws", Broker: broker}
+
+		db := dbmem.New()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		link, err := config.IssueToken(ctx, db, externalauth.BrokerRequest{UserID: uuid.New()})
+		require.NoError(t, err)
+		require.Equal(t, "session", link.OAuthAccessToken)
+		require.WithinDuration(t, time.Now().Add(time.Hour), link.OAuthExpiry, time.Minute)
+		var extra map[string]interface{}
+		require.NoError(t, json.Unmarshal(link.OAuthExtra.RawMessage, &extra))
+		require.Equal(t, "key", extra["access_key"])
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		t.Parallel()
+		userID := uuid.New()
+		issued := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "Bearer broker-token", r.Header.Get("Authorization"))
+			var req externalauth.BrokerRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "deploy", req.ProviderID)
+			assert.Equal(t, userID, req.UserID)
+			assert.Equal(t, "alice", req.Username)
+			issued++
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(externalauth.BrokerToken{
+				AccessToken: fmt.Sprintf("token-%d", issued),
+				Expiry:      time.Now().Add(time.Hour),
+			})
+		}))
+		t.Cleanup(srv.Close)
+
+		broker, err := externalauth.NewBroker(codersdk.ExternalAuthBrokerTypeHTTP, srv.URL, "broker-token", "")
+		require.NoError(t, err)
+		config := &externalauth.Config{ID: "deploy", Broker: broker}
+
+		db := dbmem.New()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		req := externalauth.BrokerRequest{UserID: userID, Username: "alice"}
+		link, err := config.IssueToken(ctx, db, req)
+		require.NoError(t, err)
+		require.Equal(t, "token-1", link.OAuthAccessToken)
+
+		// The credential is reused until it's about to expire.
+		link, err = config.IssueToken(ctx, db, req)
+		require.NoError(t, err)
+		require.Equal(t, "token-1", link.OAuthAccessToken)
+		_, err = config.RefreshToken(ctx, db, link)
+		require.NoError(t, err)
+
+		link, err = db.UpdateExternalAuthLink(ctx, database.UpdateExternalAuthLinkParams{
+			ProviderID:       link.ProviderID,
+			UserID:           link.UserID,
+			OAuthAccessToken: link.OAuthAccessToken,
+			OAuthExpiry:      time.Now().Add(30 * time.Second),
+		})
+		require.NoError(t, err)
+		_, err = config.RefreshToken(ctx, db, link)
+		require.True(t, externalauth.IsInvalidTokenError(err))
+
+		link, err = config.IssueToken(ctx, db, req)
+		require.NoError(t, err)
+		require.Equal(t, "token-2", link.OAuthAccessToken)
+	})
+
+	t.Run("ConcurrentInsert", func(t *testing.T) {
+		t.Parallel()
+		db := dbmem.New()
+		userID := uuid.New()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Another request stores a credential for the user while this
+			// one is being issued.
+			_, err := db.InsertExternalAuthLink(r.Context(), database.InsertExternalAuthLinkParams{
+				ProviderID:       "deploy",
+				UserID:           userID,
+				OAuthAccessToken: "concurrent",
+				OAuthExpiry:      time.Now().Add(time.Hour),
+			})
+			assert.NoError(t, err)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(externalauth.BrokerToken{
+				AccessToken: "issued",
+				Expiry:      time.Now().Add(time.Hour),
+			})
+		}))
+		t.Cleanup(srv.Close)
+
+		broker, err := externalauth.NewBroker(codersdk.ExternalAuthBrokerTypeHTTP, srv.URL, "", "")
+		require.NoError(t, err)
+		config := &externalauth.Config{ID: "deploy", Broker: broker}
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		link, err := config.IssueToken(ctx, db, externalauth.BrokerRequest{UserID: userID})
+		require.NoError(t, err)
+		require.Equal(t, "issued", link.OAuthAccessToken)
+	})
+
+	t.Run("BrokerError", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "permission denied", http.StatusForbidden)
+		}))
+		t.Cleanup(srv.Close)
+
+		broker, err := externalauth.NewBroker(codersdk.ExternalAuthBrokerTypeHTTP, srv.URL, "", "")
+		require.NoError(t, err)
+		config := &externalauth.Config{ID: "deploy", Broker: broker}
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err = config.IssueToken(ctx, dbmem.New(), externalauth.BrokerRequest{UserID: uuid.New()})
+		require.ErrorContains(t, err, "permission denied")
+	})
+}
+
 func TestExchangeWithClientSecret(t *testing.T) {
 	t.Parallel()
 	instrument := promoauth.NewFactory(prometheus.NewRegistry())
@@ -462,6 +599,21 @@ func TestConvertYAML(t *testing.T) {
 			DeviceFlow:   true,
 		}},
 		Error: "device auth url must be provided",
+	}, {
+		Name: "NoBrokerURL",
+		Input: []codersdk.ExternalAuthConfig{{
+			Type:       "vault",
+			BrokerType: codersdk.ExternalAuthBrokerTypeVault,
+		}},
+		Error: "broker url must be provided",
+	}, {
+		Name: "UnknownBrokerType",
+		Input: []codersdk.ExternalAuthConfig{{
+			Type:       "vault",
+			BrokerType: "aws",
+			BrokerURL:  "https://broker.example.com",
+		}},
+		Error: "unknown broker type",
 	}} {
 		tc := tc
 		t.Run(tc.Name, func(t *testing.T) {
@@ -49,6 +49,13 @@ func (api *API) postOrganizationMember(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if user.IsServiceAccount && user.ServiceAccountOrganizationID.UUID != organization.ID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Service accounts can only be members of the organization that owns them.",
+		})
+		return
+	}
+
 	member, err := api.Database.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
 		OrganizationID: organization.ID,
 		UserID:         user.ID,
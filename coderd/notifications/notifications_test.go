@@ -1216,6 +1216,34 @@ func TestNotificationTemplates_Golden(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "TemplateWorkspaceDriftDetected",
+			id:   notifications.TemplateWorkspaceDriftDetected,
+			payload: types.MessagePayload{
+				UserName:     "Bobby",
+				UserEmail:    "bobby@coder.com",
+				UserUsername: "bobby",
+				Labels: map[string]string{
+					"workspace": "bobby-workspace",
+				},
+				Data: map[string]any{
+					"resources": []map[string]any{
+						{
+							"address": "docker_container.workspace[0]",
+							"type":    "docker_container",
+							"name":    "workspace",
+							"action":  "delete",
+						},
+						{
+							"address": "docker_volume.home",
+							"type":    "docker_volume",
+							"name":    "home",
+							"action":  "update",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "TemplateTestNotification",
 			id:   notifications.TemplateTestNotification,
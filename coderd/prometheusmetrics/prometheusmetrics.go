@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -252,6 +253,72 @@ func Workspaces(ctx context.Context, logger slog.Logger, registerer prometheus.R
 	}, nil
 }
 
+// ProvisionerJobQueue tracks the number of provisioner jobs waiting for a
+// provisioner daemon, so that external daemons can be scaled on it.
+func ProvisionerJobQueue(ctx context.Context, logger slog.Logger, registerer prometheus.Registerer, db database.Store, duration time.Duration) (func(), error) {
+	if duration == 0 {
+		duration = defaultRefreshRate
+	}
+
+	queueDepth := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "coderd",
+		Subsystem: "provisionerd",
+		Name:      "job_queue_depth",
+		Help:      "The number of provisioner jobs waiting for a provisioner daemon, by the provisioner and tags a daemon needs to acquire them.",
+	}, []string{"organization_name", "provisioner", "tags"})
+	if err := registerer.Register(queueDepth); err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	// Use time.Nanosecond to force an initial tick. It will be reset to the
+	// correct duration after executing once.
+	ticker := time.NewTicker(time.Nanosecond)
+	doTick := func() {
+		defer ticker.Reset(duration)
+
+		counts, err := db.GetPendingProvisionerJobCounts(ctx)
+		if err != nil {
+			logger.Warn(ctx, "failed to load pending provisioner job counts", slog.Error(err))
+			return
+		}
+		queueDepth.Reset()
+		for _, count := range counts {
+			queueDepth.WithLabelValues(count.OrganizationName, string(count.Provisioner), formatTags(count.Tags)).Set(float64(count.Count))
+		}
+	}
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				doTick()
+			}
+		}
+	}()
+	return func() {
+		cancelFunc()
+		<-done
+	}, nil
+}
+
+// formatTags renders tags as a label value of sorted key=value pairs, e.g.
+// "owner=,scope=organization".
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
 // Agents tracks the total number of workspaces with labels on status.
 func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Registerer, db database.Store, coordinator *atomic.Pointer[tailnet.Coordinator], derpMapFn func() *tailcfg.DERPMap, agentInactiveDisconnectTimeout, duration time.Duration) (func(), error) {
 	if duration == 0 {
@@ -360,6 +360,59 @@ func TestWorkspaceLatestBuildStatuses(t *testing.T) {
 	}
 }
 
+func TestProvisionerJobQueue(t *testing.T) {
+	t.Parallel()
+
+	db := dbmem.New()
+	org := dbgen.Organization(t, db, database.Organization{Name: "acme"})
+	for _, tags := range []database.StringMap{
+		{"scope": "organization", "owner": ""},
+		{"scope": "organization", "owner": ""},
+		{"scope": "organization", "owner": "", "region": "eu"},
+	} {
+		dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			Tags:           tags,
+		})
+	}
+	// Running jobs are not queued.
+	dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		StartedAt:      sql.NullTime{Time: dbtime.Now(), Valid: true},
+		Tags:           database.StringMap{"scope": "organization", "owner": ""},
+	})
+
+	registry := prometheus.NewRegistry()
+	closeFunc, err := prometheusmetrics.ProvisionerJobQueue(context.Background(), testutil.Logger(t), registry, db, testutil.IntervalFast)
+	require.NoError(t, err)
+	t.Cleanup(closeFunc)
+
+	want := map[string]float64{
+		"owner=,scope=organization":           2,
+		"owner=,region=eu,scope=organization": 1,
+	}
+	require.Eventually(t, func() bool {
+		metrics, err := registry.Gather()
+		assert.NoError(t, err)
+		got := map[string]float64{}
+		for _, m := range metrics {
+			if m.GetName() != "coderd_provisionerd_job_queue_depth" {
+				continue
+			}
+			for _, metric := range m.Metric {
+				labels := map[string]string{}
+				for _, label := range metric.Label {
+					labels[label.GetName()] = label.GetValue()
+				}
+				assert.Equal(t, "acme", labels["organization_name"])
+				assert.Equal(t, string(database.ProvisionerTypeEcho), labels["provisioner"])
+				got[labels["tags"]] = metric.Gauge.GetValue()
+			}
+		}
+		return reflect.DeepEqual(want, got)
+	}, testutil.WaitShort, testutil.IntervalFast)
+}
+
 func TestAgents(t *testing.T) {
 	t.Parallel()
 
@@ -797,6 +850,7 @@ func insertRunning(t *testing.T, db database.Store) database.ProvisionerJob {
 		Provisioner:   database.ProvisionerTypeEcho,
 		StorageMethod: database.ProvisionerStorageMethodFile,
 		Type:          database.ProvisionerJobTypeWorkspaceBuild,
+		Priority:      database.ProvisionerJobPriorityInteractive,
 	})
 	require.NoError(t, err)
 	err = db.InsertWorkspaceBuild(context.Background(), database.InsertWorkspaceBuildParams{
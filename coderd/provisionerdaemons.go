@@ -4,8 +4,12 @@ import (
 	"database/sql"
 	"net/http"
 
+	"cdr.dev/slog"
+
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/db2sdk"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
 	"github.com/coder/coder/v2/coderd/provisionerdserver"
@@ -25,6 +29,7 @@ import (
 // @Param ids query []string false "Filter results by job IDs" format(uuid)
 // @Param status query codersdk.ProvisionerJobStatus false "Filter results by status" enums(pending,running,succeeded,canceling,canceled,failed)
 // @Param tags query object false "Provisioner tags to filter by (JSON of the form {'tag1':'value1','tag2':'value2'})"
+// @Param name query string false "Filter results by name"
 // @Success 200 {array} codersdk.ProvisionerDaemon
 // @Router /organizations/{organization}/provisionerdaemons [get]
 func (api *API) provisionerDaemons(rw http.ResponseWriter, r *http.Request) {
@@ -45,6 +50,7 @@ func (api *API) provisionerDaemons(rw http.ResponseWriter, r *http.Request) {
 	limit := p.PositiveInt32(qp, 50, "limit")
 	ids := p.UUIDs(qp, nil, "ids")
 	tags := p.JSONStringMap(qp, database.StringMap{}, "tags")
+	name := p.String(qp, "", "name")
 	p.ErrorExcessParams(qp)
 	if len(p.Errors) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -62,6 +68,7 @@ func (api *API) provisionerDaemons(rw http.ResponseWriter, r *http.Request) {
 			Limit:           sql.NullInt32{Int32: limit, Valid: limit > 0},
 			IDs:             ids,
 			Tags:            tags,
+			Name:            name,
 		},
 	)
 	if err != nil {
@@ -103,3 +110,71 @@ func (api *API) provisionerDaemons(rw http.ResponseWriter, r *http.Request) {
 		return pd
 	}))
 }
+
+// @Summary Drain provisioner daemon
+// @Description The daemon stops acquiring jobs, and exits once its current job completes.
+// @ID drain-provisioner-daemon
+// @Security CoderSessionToken
+// @Tags Provisioning
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param provisionerdaemon path string true "Provisioner daemon ID" format(uuid)
+// @Success 204
+// @Router /organizations/{organization}/provisionerdaemons/{provisionerdaemon}/drain [post]
+func (api *API) drainProvisionerDaemon(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx = r.Context()
+		org = httpmw.OrganizationParam(r)
+	)
+
+	daemonID, ok := httpmw.ParseUUIDParam(rw, r, "provisionerdaemon")
+	if !ok {
+		return
+	}
+	daemon, err := api.Database.GetProvisionerDaemonByID(ctx, daemonID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner daemon.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if daemon.OrganizationID != org.ID {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if daemon.KeyID == codersdk.ProvisionerKeyUUIDBuiltIn {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Built-in provisioner daemons run inside Coder server and cannot be drained.",
+		})
+		return
+	}
+
+	err = api.Database.UpdateProvisionerDaemonDrainRequestedAt(ctx, database.UpdateProvisionerDaemonDrainRequestedAtParams{
+		ID:               daemon.ID,
+		DrainRequestedAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error draining provisioner daemon.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// An idle daemon is waiting for a job, so wake it up. If this fails, the
+	// daemon still stops when it next tries to acquire a job.
+	err = api.Pubsub.Publish(provisionerdserver.DrainChannel(daemon.ID), nil)
+	if err != nil {
+		api.Logger.Warn(ctx, "failed to publish provisioner daemon drain", slog.F("daemon_id", daemon.ID), slog.Error(err))
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -3,6 +3,7 @@ package coderd_test
 import (
 	"database/sql"
 	"encoding/json"
+	"net/http"
 	"strconv"
 	"testing"
 	"time"
@@ -174,6 +175,17 @@ func TestProvisionerDaemons(t *testing.T) {
 		require.Equal(t, userDaemons[1].ID, daemons[0].ID)
 	})
 
+	t.Run("Name", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		daemons, err := templateAdminClient.OrganizationProvisionerDaemons(ctx, owner.OrganizationID, &codersdk.OrganizationProvisionerDaemonsOptions{
+			Name: "provisioner-3",
+		})
+		require.NoError(t, err)
+		require.Len(t, daemons, 1)
+		require.Equal(t, pd3.ID, daemons[0].ID)
+	})
+
 	t.Run("Limit", func(t *testing.T) {
 		t.Parallel()
 		ctx := testutil.Context(t, testutil.WaitMedium)
@@ -252,3 +264,63 @@ func TestProvisionerDaemons(t *testing.T) {
 		require.Len(t, daemons, 0)
 	})
 }
+
+func TestDrainProvisionerDaemon(t *testing.T) {
+	t.Parallel()
+
+	db, ps := dbtestutil.NewDB(t)
+	client, _, coderdAPI := coderdtest.NewWithAPI(t, &coderdtest.Options{
+		Database: db,
+		Pubsub:   ps,
+	})
+	owner := coderdtest.CreateFirstUser(t, client)
+	memberClient, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+	external := dbgen.ProvisionerDaemon(t, coderdAPI.Database, database.ProvisionerDaemon{
+		Name: "external",
+	})
+	builtin := dbgen.ProvisionerDaemon(t, coderdAPI.Database, database.ProvisionerDaemon{
+		Name:  "builtin",
+		KeyID: codersdk.ProvisionerKeyUUIDBuiltIn,
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		err := client.DrainProvisionerDaemon(ctx, owner.OrganizationID, external.ID)
+		require.NoError(t, err)
+		daemons, err := client.OrganizationProvisionerDaemons(ctx, owner.OrganizationID, &codersdk.OrganizationProvisionerDaemonsOptions{
+			IDs: []uuid.UUID{external.ID},
+		})
+		require.NoError(t, err)
+		require.Len(t, daemons, 1)
+		require.True(t, daemons[0].DrainRequestedAt.Valid)
+	})
+
+	t.Run("BuiltIn", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		err := client.DrainProvisionerDaemon(ctx, owner.OrganizationID, builtin.ID)
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		err := client.DrainProvisionerDaemon(ctx, owner.OrganizationID, uuid.New())
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+
+	t.Run("MemberDenied", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		err := memberClient.DrainProvisionerDaemon(ctx, owner.OrganizationID, external.ID)
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusForbidden, sdkErr.StatusCode())
+	})
+}
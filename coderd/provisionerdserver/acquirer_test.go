@@ -496,6 +496,7 @@ func TestAcquirer_MatchTags(t *testing.T) {
 				Input:          []byte("{}"),
 				Tags:           tt.provisionerJobTags,
 				TraceMetadata:  pqtype.NullRawMessage{},
+				Priority:       database.ProvisionerJobPriorityInteractive,
 			})
 			require.NoError(t, err)
 			ptypes := []database.ProvisionerType{database.ProvisionerTypeEcho}
@@ -83,6 +83,11 @@ type Options struct {
 	// The default function just calls UpdateProvisionerDaemonLastSeenAt.
 	// This is mainly used for testing.
 	HeartbeatFn func(context.Context) error
+
+	// OmitTemplateSourceArchive causes acquired jobs to reference the template
+	// source archive by file ID and hash only. It is set for daemons that fetch
+	// archives from a workspace proxy file cache.
+	OmitTemplateSourceArchive bool
 }
 
 type server struct {
@@ -117,6 +122,8 @@ type server struct {
 
 	heartbeatInterval time.Duration
 	heartbeatFn       func(ctx context.Context) error
+
+	omitTemplateSourceArchive bool
 }
 
 // We use the null byte (0x00) in generating a canonical map key for tags, so
@@ -227,6 +234,7 @@ func NewServer(
 		acquireJobLongPollDur:       options.AcquireJobLongPollDur,
 		heartbeatInterval:           options.HeartbeatInterval,
 		heartbeatFn:                 options.HeartbeatFn,
+		omitTemplateSourceArchive:   options.OmitTemplateSourceArchive,
 	}
 
 	if s.heartbeatFn == nil {
@@ -293,12 +301,36 @@ func (s *server) defaultHeartbeat(ctx context.Context) error {
 	})
 }
 
+// DrainChannel returns the pubsub channel that is published to when the
+// provisioner daemon with the given ID is asked to drain.
+func DrainChannel(daemonID uuid.UUID) string {
+	return "provisioner_daemon_drain:" + daemonID.String()
+}
+
+// drainRequested returns true if the daemon has been asked to stop acquiring
+// jobs.
+func (s *server) drainRequested(ctx context.Context) (bool, error) {
+	//nolint:gocritic // Provisionerd cannot read provisioner daemons.
+	daemon, err := s.Database.GetProvisionerDaemonByID(dbauthz.AsSystemRestricted(ctx), s.ID)
+	if err != nil {
+		return false, xerrors.Errorf("get provisioner daemon: %w", err)
+	}
+	return daemon.DrainRequestedAt.Valid, nil
+}
+
 // AcquireJob queries the database to lock a job.
 //
 // Deprecated: This method is only available for back-level provisioner daemons.
 func (s *server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
 	//nolint:gocritic // Provisionerd has specific authz rules.
 	ctx = dbauthz.AsProvisionerd(ctx)
+	drain, err := s.drainRequested(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if drain {
+		return &proto.AcquiredJob{Drain: true}, nil
+	}
 	// Since AcquireJob blocks until a job is available, we set a long (5s by default) timeout.  This allows back-level
 	// provisioner daemons to gracefully shut down within a few seconds, but keeps them from rapidly polling the
 	// database.
@@ -332,6 +364,28 @@ func (s *server) AcquireJobWithCancel(stream proto.DRPCProvisionerDaemon_Acquire
 			retErr = closeErr
 		}
 	}()
+	drain, err := s.drainRequested(streamCtx)
+	if err != nil {
+		return err
+	}
+	if drain {
+		s.Logger.Debug(streamCtx, "daemon is draining, not acquiring job")
+		return stream.Send(&proto.AcquiredJob{Drain: true})
+	}
+	// Wake up an idle daemon as soon as it is asked to drain, rather than
+	// when it next acquires a job.
+	drainCh := make(chan struct{}, 1)
+	cancelSub, err := s.Pubsub.Subscribe(DrainChannel(s.ID), func(_ context.Context, _ []byte) {
+		select {
+		case drainCh <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return xerrors.Errorf("subscribe to drain requests: %w", err)
+	}
+	defer cancelSub()
+
 	acqCtx, acqCancel := context.WithCancel(streamCtx)
 	defer acqCancel()
 	recvCh := make(chan error, 1)
@@ -346,15 +400,20 @@ func (s *server) AcquireJobWithCancel(stream proto.DRPCProvisionerDaemon_Acquire
 	}()
 	var recvErr error
 	var je jobAndErr
+	drained := false
 	select {
 	case recvErr = <-recvCh:
 		acqCancel()
 		je = <-jec
+	case <-drainCh:
+		drained = true
+		acqCancel()
+		je = <-jec
 	case je = <-jec:
 	}
 	if xerrors.Is(je.err, context.Canceled) {
-		s.Logger.Debug(streamCtx, "successful cancel")
-		err := stream.Send(&proto.AcquiredJob{})
+		s.Logger.Debug(streamCtx, "successful cancel", slog.F("drain", drained))
+		err := stream.Send(&proto.AcquiredJob{Drain: drained})
 		if err != nil {
 			// often this is just because the other side hangs up and doesn't wait for the cancel, so log at INFO
 			s.Logger.Info(streamCtx, "failed to send empty job", slog.Error(err))
@@ -461,184 +520,80 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("get workspace build: %s", err))
 		}
-		workspace, err := s.Database.GetWorkspaceByID(ctx, workspaceBuild.WorkspaceID)
+		data, err := s.loadWorkspaceBuildJobData(ctx, workspaceBuild, failJob)
 		if err != nil {
-			return nil, failJob(fmt.Sprintf("get workspace: %s", err))
-		}
-		templateVersion, err := s.Database.GetTemplateVersionByID(ctx, workspaceBuild.TemplateVersionID)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("get template version: %s", err))
-		}
-		templateVariables, err := s.Database.GetTemplateVersionVariables(ctx, templateVersion.ID)
-		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
-			return nil, failJob(fmt.Sprintf("get template version variables: %s", err))
-		}
-		template, err := s.Database.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("get template: %s", err))
-		}
-		owner, err := s.Database.GetUserByID(ctx, workspace.OwnerID)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("get owner: %s", err))
-		}
-		var ownerSSHPublicKey, ownerSSHPrivateKey string
-		if ownerSSHKey, err := s.Database.GetGitSSHKey(ctx, owner.ID); err != nil {
-			if !xerrors.Is(err, sql.ErrNoRows) {
-				return nil, failJob(fmt.Sprintf("get owner ssh key: %s", err))
-			}
-		} else {
-			ownerSSHPublicKey = ownerSSHKey.PublicKey
-			ownerSSHPrivateKey = ownerSSHKey.PrivateKey
-		}
-		ownerGroups, err := s.Database.GetGroups(ctx, database.GetGroupsParams{
-			HasMemberID:    owner.ID,
-			OrganizationID: s.OrganizationID,
-		})
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("get owner group names: %s", err))
-		}
-		ownerGroupNames := []string{}
-		for _, group := range ownerGroups {
-			ownerGroupNames = append(ownerGroupNames, group.Group.Name)
+			return nil, err
 		}
 
 		msg, err := json.Marshal(wspubsub.WorkspaceEvent{
 			Kind:        wspubsub.WorkspaceEventKindStateChange,
-			WorkspaceID: workspace.ID,
+			WorkspaceID: data.workspace.ID,
 		})
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("marshal workspace update event: %s", err))
 		}
-		err = s.Pubsub.Publish(wspubsub.WorkspaceEventChannel(workspace.OwnerID), msg)
+		err = s.Pubsub.Publish(wspubsub.WorkspaceEventChannel(data.workspace.OwnerID), msg)
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("publish workspace update: %s", err))
 		}
 
-		var workspaceOwnerOIDCAccessToken string
-		if s.OIDCConfig != nil {
-			workspaceOwnerOIDCAccessToken, err = obtainOIDCAccessToken(ctx, s.Database, s.OIDCConfig, owner.ID)
-			if err != nil {
-				return nil, failJob(fmt.Sprintf("obtain OIDC access token: %s", err))
-			}
-		}
-
 		var sessionToken string
 		switch workspaceBuild.Transition {
 		case database.WorkspaceTransitionStart:
-			sessionToken, err = s.regenerateSessionToken(ctx, owner, workspace)
+			sessionToken, err = s.regenerateSessionToken(ctx, data.owner, data.workspace)
 			if err != nil {
 				return nil, failJob(fmt.Sprintf("regenerate session token: %s", err))
 			}
 		case database.WorkspaceTransitionStop, database.WorkspaceTransitionDelete:
-			err = deleteSessionToken(ctx, s.Database, workspace)
+			err = deleteSessionToken(ctx, s.Database, data.workspace)
 			if err != nil {
 				return nil, failJob(fmt.Sprintf("delete session token: %s", err))
 			}
 		}
+		data.metadata.WorkspaceOwnerSessionToken = sessionToken
+		data.metadata.IsPrebuild = input.IsPrebuild
 
-		transition, err := convertWorkspaceTransition(workspaceBuild.Transition)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("convert workspace transition: %s", err))
+		protoJob.Type = &proto.AcquiredJob_WorkspaceBuild_{
+			WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+				WorkspaceBuildId:      workspaceBuild.ID.String(),
+				WorkspaceName:         data.workspace.Name,
+				State:                 workspaceBuild.ProvisionerState,
+				RichParameterValues:   convertRichParameterValues(data.parameters),
+				VariableValues:        asVariableValues(data.variables),
+				ExternalAuthProviders: data.externalAuthProviders,
+				Metadata:              data.metadata,
+				LogLevel:              input.LogLevel,
+			},
 		}
-
-		workspaceBuildParameters, err := s.Database.GetWorkspaceBuildParameters(ctx, workspaceBuild.ID)
+	case database.ProvisionerJobTypeWorkspaceDriftCheck:
+		var input WorkspaceDriftCheckJob
+		err = json.Unmarshal(job.Input, &input)
 		if err != nil {
-			return nil, failJob(fmt.Sprintf("get workspace build parameters: %s", err))
+			return nil, failJob(fmt.Sprintf("unmarshal job input %q: %s", job.Input, err))
 		}
-
-		dbExternalAuthProviders := []database.ExternalAuthProvider{}
-		err = json.Unmarshal(templateVersion.ExternalAuthProviders, &dbExternalAuthProviders)
+		workspaceBuild, err := s.Database.GetWorkspaceBuildByID(ctx, input.WorkspaceBuildID)
 		if err != nil {
-			return nil, xerrors.Errorf("failed to deserialize external_auth_providers value: %w", err)
+			return nil, failJob(fmt.Sprintf("get workspace build: %s", err))
 		}
-
-		externalAuthProviders := make([]*sdkproto.ExternalAuthProvider, 0, len(dbExternalAuthProviders))
-		for _, p := range dbExternalAuthProviders {
-			link, err := s.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
-				ProviderID: p.ID,
-				UserID:     owner.ID,
-			})
-			if errors.Is(err, sql.ErrNoRows) {
-				continue
-			}
-			if err != nil {
-				return nil, failJob(fmt.Sprintf("acquire external auth link: %s", err))
-			}
-			var config *externalauth.Config
-			for _, c := range s.ExternalAuthConfigs {
-				if c.ID != p.ID {
-					continue
-				}
-				config = c
-				break
-			}
-			// We weren't able to find a matching config for the ID!
-			if config == nil {
-				s.Logger.Warn(ctx, "workspace build job is missing external auth provider",
-					slog.F("provider_id", p.ID),
-					slog.F("template_version_id", templateVersion.ID),
-					slog.F("workspace_id", workspaceBuild.WorkspaceID))
-				continue
-			}
-
-			refreshed, err := config.RefreshToken(ctx, s.Database, link)
-			if err != nil && !externalauth.IsInvalidTokenError(err) {
-				return nil, failJob(fmt.Sprintf("refresh external auth link %q: %s", p.ID, err))
-			}
-			if err != nil {
-				// Invalid tokens are skipped
-				continue
-			}
-			externalAuthProviders = append(externalAuthProviders, &sdkproto.ExternalAuthProvider{
-				Id:          p.ID,
-				AccessToken: refreshed.OAuthAccessToken,
-			})
+		if workspaceBuild.Transition != database.WorkspaceTransitionStart {
+			return nil, failJob(fmt.Sprintf("workspace build has transition %q, drift is only checked for running workspaces", workspaceBuild.Transition))
 		}
-
-		roles, err := s.Database.GetAuthorizationUserRoles(ctx, owner.ID)
+		// The session token is left empty: a drift check only refreshes
+		// state, and must not revoke the token the running agent uses.
+		data, err := s.loadWorkspaceBuildJobData(ctx, workspaceBuild, failJob)
 		if err != nil {
-			return nil, failJob(fmt.Sprintf("get owner authorization roles: %s", err))
-		}
-		ownerRbacRoles := []*sdkproto.Role{}
-		for _, role := range roles.Roles {
-			if s.OrganizationID == uuid.Nil {
-				ownerRbacRoles = append(ownerRbacRoles, &sdkproto.Role{Name: role, OrgId: ""})
-				continue
-			}
-			ownerRbacRoles = append(ownerRbacRoles, &sdkproto.Role{Name: role, OrgId: s.OrganizationID.String()})
+			return nil, err
 		}
 
-		protoJob.Type = &proto.AcquiredJob_WorkspaceBuild_{
-			WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+		protoJob.Type = &proto.AcquiredJob_WorkspaceDriftCheck_{
+			WorkspaceDriftCheck: &proto.AcquiredJob_WorkspaceDriftCheck{
 				WorkspaceBuildId:      workspaceBuild.ID.String(),
-				WorkspaceName:         workspace.Name,
+				WorkspaceName:         data.workspace.Name,
 				State:                 workspaceBuild.ProvisionerState,
-				RichParameterValues:   convertRichParameterValues(workspaceBuildParameters),
-				VariableValues:        asVariableValues(templateVariables),
-				ExternalAuthProviders: externalAuthProviders,
-				Metadata: &sdkproto.Metadata{
-					CoderUrl:                      s.AccessURL.String(),
-					WorkspaceTransition:           transition,
-					WorkspaceName:                 workspace.Name,
-					WorkspaceOwner:                owner.Username,
-					WorkspaceOwnerEmail:           owner.Email,
-					WorkspaceOwnerName:            owner.Name,
-					WorkspaceOwnerGroups:          ownerGroupNames,
-					WorkspaceOwnerOidcAccessToken: workspaceOwnerOIDCAccessToken,
-					WorkspaceId:                   workspace.ID.String(),
-					WorkspaceOwnerId:              owner.ID.String(),
-					TemplateId:                    template.ID.String(),
-					TemplateName:                  template.Name,
-					TemplateVersion:               templateVersion.Name,
-					WorkspaceOwnerSessionToken:    sessionToken,
-					WorkspaceOwnerSshPublicKey:    ownerSSHPublicKey,
-					WorkspaceOwnerSshPrivateKey:   ownerSSHPrivateKey,
-					WorkspaceBuildId:              workspaceBuild.ID.String(),
-					WorkspaceOwnerLoginType:       string(owner.LoginType),
-					WorkspaceOwnerRbacRoles:       ownerRbacRoles,
-					IsPrebuild:                    input.IsPrebuild,
-				},
-				LogLevel: input.LogLevel,
+				RichParameterValues:   convertRichParameterValues(data.parameters),
+				VariableValues:        asVariableValues(data.variables),
+				ExternalAuthProviders: data.externalAuthProviders,
+				Metadata:              data.metadata,
 			},
 		}
 	case database.ProvisionerJobTypeTemplateVersionDryRun:
@@ -694,7 +649,11 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("get file by hash: %s", err))
 		}
-		protoJob.TemplateSourceArchive = file.Data
+		protoJob.TemplateSourceFileId = file.ID.String()
+		protoJob.TemplateSourceHash = file.Hash
+		if !s.omitTemplateSourceArchive {
+			protoJob.TemplateSourceArchive = file.Data
+		}
 	default:
 		return nil, failJob(fmt.Sprintf("unsupported storage method: %s", job.StorageMethod))
 	}
@@ -1067,6 +1026,9 @@ func (s *server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*proto.
 			return nil, xerrors.Errorf("publish workspace update: %w", err)
 		}
 	case *proto.FailedJob_TemplateImport_:
+	case *proto.FailedJob_WorkspaceDriftCheck_:
+		// A failed drift check is left unchecked, and is retried once the
+		// template's drift detection interval has elapsed again.
 	}
 
 	// if failed job is a workspace build, audit the outcome
@@ -1762,6 +1724,13 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 		}
 		s.Logger.Debug(ctx, "marked template dry-run job as completed", slog.F("job_id", jobID))
 
+	case *proto.CompletedJob_WorkspaceDriftCheck_:
+		err = s.completeWorkspaceDriftCheck(ctx, job, jobType.WorkspaceDriftCheck)
+		if err != nil {
+			return nil, err
+		}
+		s.Logger.Debug(ctx, "marked workspace drift check job as completed", slog.F("job_id", jobID))
+
 	default:
 		if completed.Type == nil {
 			return nil, xerrors.Errorf("type payload must be provided")
@@ -1784,6 +1753,101 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 	return &proto.Empty{}, nil
 }
 
+// completeWorkspaceDriftCheck records the drift a provisioner found for a
+// workspace, and notifies the owner when the drift is new.
+func (s *server) completeWorkspaceDriftCheck(ctx context.Context, job database.ProvisionerJob, completed *proto.CompletedJob_WorkspaceDriftCheck) error {
+	check, err := s.Database.GetWorkspaceDriftCheckByJobID(ctx, job.ID)
+	if err != nil {
+		return xerrors.Errorf("get workspace drift check: %w", err)
+	}
+	resources := make(database.WorkspaceDriftResources, 0, len(completed.ResourceDrift))
+	for _, drift := range completed.ResourceDrift {
+		resources = append(resources, database.WorkspaceDriftResource{
+			Address: drift.Address,
+			Type:    drift.Type,
+			Name:    drift.Name,
+			Action:  drift.Action,
+		})
+	}
+
+	var previous database.WorkspaceDriftCheck
+	err = s.Database.InTx(func(db database.Store) error {
+		previousChecks, err := db.GetLatestWorkspaceDriftChecksByWorkspaceIDs(ctx, []uuid.UUID{check.WorkspaceID})
+		if err != nil {
+			return xerrors.Errorf("get previous workspace drift check: %w", err)
+		}
+		if len(previousChecks) > 0 {
+			previous = previousChecks[0]
+		}
+		now := s.timeNow()
+		err = db.UpdateWorkspaceDriftCheckByJobID(ctx, database.UpdateWorkspaceDriftCheckByJobIDParams{
+			JobID:     job.ID,
+			CheckedAt: sql.NullTime{Time: now, Valid: true},
+			Resources: resources,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace drift check: %w", err)
+		}
+		err = db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+			ID:          job.ID,
+			UpdatedAt:   now,
+			CompletedAt: sql.NullTime{Time: now, Valid: true},
+		})
+		if err != nil {
+			return xerrors.Errorf("update provisioner job: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	workspace, err := s.Database.GetWorkspaceByID(ctx, check.WorkspaceID)
+	if err != nil {
+		return xerrors.Errorf("get workspace: %w", err)
+	}
+	msg, err := json.Marshal(wspubsub.WorkspaceEvent{
+		Kind:        wspubsub.WorkspaceEventKindStateChange,
+		WorkspaceID: workspace.ID,
+	})
+	if err != nil {
+		return xerrors.Errorf("marshal workspace update event: %s", err)
+	}
+	err = s.Pubsub.Publish(wspubsub.WorkspaceEventChannel(workspace.OwnerID), msg)
+	if err != nil {
+		return xerrors.Errorf("publish workspace update: %w", err)
+	}
+
+	// Only notify when the workspace starts drifting, not on every check
+	// that finds the same build still drifted.
+	alreadyDrifted := previous.WorkspaceBuildID == check.WorkspaceBuildID && len(previous.Resources) > 0
+	if len(resources) == 0 || alreadyDrifted {
+		return nil
+	}
+	data := make([]map[string]any, 0, len(resources))
+	for _, resource := range resources {
+		data = append(data, map[string]any{
+			"address": resource.Address,
+			"type":    resource.Type,
+			"name":    resource.Name,
+			"action":  resource.Action,
+		})
+	}
+	if _, err := s.NotificationsEnqueuer.EnqueueWithData(ctx, workspace.OwnerID, notifications.TemplateWorkspaceDriftDetected,
+		map[string]string{
+			"workspace": workspace.Name,
+		},
+		map[string]any{
+			"resources": data,
+		}, "provisionerdserver",
+		// Associate this notification with all the related entities.
+		workspace.ID, workspace.OwnerID, workspace.TemplateID, workspace.OrganizationID,
+	); err != nil {
+		s.Logger.Warn(ctx, "failed to notify of workspace drift", slog.Error(err))
+	}
+	return nil
+}
+
 func (s *server) notifyWorkspaceDeleted(ctx context.Context, workspace database.Workspace, build database.WorkspaceBuild) {
 	var reason string
 	initiator := build.InitiatorByUsername
@@ -2276,6 +2340,196 @@ func workspaceSessionTokenName(workspace database.Workspace) string {
 	return fmt.Sprintf("%s_%s_session_token", workspace.OwnerID, workspace.ID)
 }
 
+// workspaceBuildJobData is what a provisioner needs to run against the state
+// of a workspace build.
+type workspaceBuildJobData struct {
+	workspace             database.Workspace
+	owner                 database.User
+	parameters            []database.WorkspaceBuildParameter
+	variables             []database.TemplateVersionVariable
+	externalAuthProviders []*sdkproto.ExternalAuthProvider
+	metadata              *sdkproto.Metadata
+}
+
+// loadWorkspaceBuildJobData loads the workspace, owner and template data for a
+// workspace build. The session token and prebuild flag are left for the caller
+// to set on the returned metadata.
+func (s *server) loadWorkspaceBuildJobData(ctx context.Context, workspaceBuild database.WorkspaceBuild, failJob func(string) error) (workspaceBuildJobData, error) {
+	workspace, err := s.Database.GetWorkspaceByID(ctx, workspaceBuild.WorkspaceID)
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get workspace: %s", err))
+	}
+	templateVersion, err := s.Database.GetTemplateVersionByID(ctx, workspaceBuild.TemplateVersionID)
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get template version: %s", err))
+	}
+	templateVariables, err := s.Database.GetTemplateVersionVariables(ctx, templateVersion.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get template version variables: %s", err))
+	}
+	template, err := s.Database.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get template: %s", err))
+	}
+	owner, err := s.Database.GetUserByID(ctx, workspace.OwnerID)
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get owner: %s", err))
+	}
+	var ownerSSHPublicKey, ownerSSHPrivateKey string
+	if ownerSSHKey, err := s.Database.GetGitSSHKey(ctx, owner.ID); err != nil {
+		if !xerrors.Is(err, sql.ErrNoRows) {
+			return workspaceBuildJobData{}, failJob(fmt.Sprintf("get owner ssh key: %s", err))
+		}
+	} else {
+		ownerSSHPublicKey = ownerSSHKey.PublicKey
+		ownerSSHPrivateKey = ownerSSHKey.PrivateKey
+	}
+	ownerGroups, err := s.Database.GetGroups(ctx, database.GetGroupsParams{
+		HasMemberID:    owner.ID,
+		OrganizationID: s.OrganizationID,
+	})
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get owner group names: %s", err))
+	}
+	ownerGroupNames := []string{}
+	for _, group := range ownerGroups {
+		ownerGroupNames = append(ownerGroupNames, group.Group.Name)
+	}
+
+	var workspaceOwnerOIDCAccessToken string
+	if s.OIDCConfig != nil {
+		workspaceOwnerOIDCAccessToken, err = obtainOIDCAccessToken(ctx, s.Database, s.OIDCConfig, owner.ID)
+		if err != nil {
+			return workspaceBuildJobData{}, failJob(fmt.Sprintf("obtain OIDC access token: %s", err))
+		}
+	}
+
+	transition, err := convertWorkspaceTransition(workspaceBuild.Transition)
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("convert workspace transition: %s", err))
+	}
+
+	workspaceBuildParameters, err := s.Database.GetWorkspaceBuildParameters(ctx, workspaceBuild.ID)
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get workspace build parameters: %s", err))
+	}
+
+	dbExternalAuthProviders := []database.ExternalAuthProvider{}
+	err = json.Unmarshal(templateVersion.ExternalAuthProviders, &dbExternalAuthProviders)
+	if err != nil {
+		return workspaceBuildJobData{}, xerrors.Errorf("failed to deserialize external_auth_providers value: %w", err)
+	}
+
+	externalAuthProviders := make([]*sdkproto.ExternalAuthProvider, 0, len(dbExternalAuthProviders))
+	for _, p := range dbExternalAuthProviders {
+		var config *externalauth.Config
+		for _, c := range s.ExternalAuthConfigs {
+			if c.ID != p.ID {
+				continue
+			}
+			config = c
+			break
+		}
+		// We weren't able to find a matching config for the ID!
+		if config == nil {
+			s.Logger.Warn(ctx, "workspace build job is missing external auth provider",
+				slog.F("provider_id", p.ID),
+				slog.F("template_version_id", templateVersion.ID),
+				slog.F("workspace_id", workspaceBuild.WorkspaceID))
+			continue
+		}
+
+		if config.Broker != nil {
+			issued, err := config.IssueToken(ctx, s.Database, externalauth.BrokerRequest{
+				UserID:   owner.ID,
+				Username: owner.Username,
+			})
+			if err != nil && !p.Optional {
+				return workspaceBuildJobData{}, failJob(fmt.Sprintf("issue external auth credential %q: %s", p.ID, err))
+			}
+			if err != nil {
+				// Optional providers are skipped, like those the owner
+				// hasn't linked.
+				s.Logger.Warn(ctx, "failed to issue optional external auth credential",
+					slog.F("provider_id", p.ID),
+					slog.F("workspace_id", workspaceBuild.WorkspaceID),
+					slog.Error(err))
+				continue
+			}
+			externalAuthProviders = append(externalAuthProviders, &sdkproto.ExternalAuthProvider{
+				Id:          p.ID,
+				AccessToken: issued.OAuthAccessToken,
+			})
+			continue
+		}
+
+		link, err := s.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
+			ProviderID: p.ID,
+			UserID:     owner.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return workspaceBuildJobData{}, failJob(fmt.Sprintf("acquire external auth link: %s", err))
+		}
+
+		refreshed, err := config.RefreshToken(ctx, s.Database, link)
+		if err != nil && !externalauth.IsInvalidTokenError(err) {
+			return workspaceBuildJobData{}, failJob(fmt.Sprintf("refresh external auth link %q: %s", p.ID, err))
+		}
+		if err != nil {
+			// Invalid tokens are skipped
+			continue
+		}
+		externalAuthProviders = append(externalAuthProviders, &sdkproto.ExternalAuthProvider{
+			Id:          p.ID,
+			AccessToken: refreshed.OAuthAccessToken,
+		})
+	}
+
+	roles, err := s.Database.GetAuthorizationUserRoles(ctx, owner.ID)
+	if err != nil {
+		return workspaceBuildJobData{}, failJob(fmt.Sprintf("get owner authorization roles: %s", err))
+	}
+	ownerRbacRoles := []*sdkproto.Role{}
+	for _, role := range roles.Roles {
+		if s.OrganizationID == uuid.Nil {
+			ownerRbacRoles = append(ownerRbacRoles, &sdkproto.Role{Name: role, OrgId: ""})
+			continue
+		}
+		ownerRbacRoles = append(ownerRbacRoles, &sdkproto.Role{Name: role, OrgId: s.OrganizationID.String()})
+	}
+
+	return workspaceBuildJobData{
+		workspace:             workspace,
+		owner:                 owner,
+		parameters:            workspaceBuildParameters,
+		variables:             templateVariables,
+		externalAuthProviders: externalAuthProviders,
+		metadata: &sdkproto.Metadata{
+			CoderUrl:                      s.AccessURL.String(),
+			WorkspaceTransition:           transition,
+			WorkspaceName:                 workspace.Name,
+			WorkspaceOwner:                owner.Username,
+			WorkspaceOwnerEmail:           owner.Email,
+			WorkspaceOwnerName:            owner.Name,
+			WorkspaceOwnerGroups:          ownerGroupNames,
+			WorkspaceOwnerOidcAccessToken: workspaceOwnerOIDCAccessToken,
+			WorkspaceId:                   workspace.ID.String(),
+			WorkspaceOwnerId:              owner.ID.String(),
+			TemplateId:                    template.ID.String(),
+			TemplateName:                  template.Name,
+			TemplateVersion:               templateVersion.Name,
+			WorkspaceOwnerSshPublicKey:    ownerSSHPublicKey,
+			WorkspaceOwnerSshPrivateKey:   ownerSSHPrivateKey,
+			WorkspaceBuildId:              workspaceBuild.ID.String(),
+			WorkspaceOwnerLoginType:       string(owner.LoginType),
+			WorkspaceOwnerRbacRoles:       ownerRbacRoles,
+		},
+	}, nil
+}
+
 func (s *server) regenerateSessionToken(ctx context.Context, user database.User, workspace database.Workspace) (string, error) {
 	newkey, sessionToken, err := apikey.Generate(apikey.CreateParams{
 		UserID:          user.ID,
@@ -2468,6 +2722,11 @@ type WorkspaceProvisionJob struct {
 	LogLevel         string    `json:"log_level,omitempty"`
 }
 
+// WorkspaceDriftCheckJob is the payload for the "workspace_drift_check" job type.
+type WorkspaceDriftCheckJob struct {
+	WorkspaceBuildID uuid.UUID `json:"workspace_build_id"`
+}
+
 // TemplateVersionDryRunJob is the payload for the "template_version_dry_run" job type.
 type TemplateVersionDryRunJob struct {
 	TemplateVersionID   uuid.UUID                          `json:"template_version_id"`
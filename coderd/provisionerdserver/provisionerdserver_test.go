@@ -95,6 +95,69 @@ func TestAcquireJobWithCancel_Cancel(t *testing.T) {
 	require.Equal(t, "", job.JobId)
 }
 
+func TestAcquireJobWithCancel_Drain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Requested", func(t *testing.T) {
+		t.Parallel()
+		srv, db, _, daemon := setup(t, false, nil)
+		ctx := testutil.Context(t, testutil.WaitShort)
+		// A job is available, but the daemon must not be given it.
+		_ = dbgen.ProvisionerJob(t, db, nil, database.ProvisionerJob{
+			OrganizationID: daemon.OrganizationID,
+			Provisioner:    database.ProvisionerTypeEcho,
+			Tags:           daemon.Tags,
+		})
+		err := db.UpdateProvisionerDaemonDrainRequestedAt(ctx, database.UpdateProvisionerDaemonDrainRequestedAtParams{
+			ID:               daemon.ID,
+			DrainRequestedAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+
+		fs := newFakeStream(ctx)
+		err = srv.AcquireJobWithCancel(fs)
+		require.NoError(t, err)
+		job, err := fs.waitForJob()
+		require.NoError(t, err)
+		require.Equal(t, "", job.JobId)
+		require.True(t, job.Drain)
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		t.Parallel()
+		srv, db, ps, daemon := setup(t, false, nil)
+		ctx := testutil.Context(t, testutil.WaitShort)
+		fs := newFakeStream(ctx)
+		errCh := make(chan error)
+		go func() {
+			errCh <- srv.AcquireJobWithCancel(fs)
+		}()
+
+		err := db.UpdateProvisionerDaemonDrainRequestedAt(ctx, database.UpdateProvisionerDaemonDrainRequestedAtParams{
+			ID:               daemon.ID,
+			DrainRequestedAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+		})
+		require.NoError(t, err)
+		// The daemon may not have subscribed yet, so publish until it
+		// notices.
+		require.Eventually(t, func() bool {
+			err := ps.Publish(provisionerdserver.DrainChannel(daemon.ID), nil)
+			assert.NoError(t, err)
+			select {
+			case err := <-errCh:
+				assert.NoError(t, err)
+				return true
+			default:
+				return false
+			}
+		}, testutil.WaitShort, testutil.IntervalFast)
+		job, err := fs.waitForJob()
+		require.NoError(t, err)
+		require.Equal(t, "", job.JobId)
+		require.True(t, job.Drain)
+	})
+}
+
 func TestHeartbeat(t *testing.T) {
 	t.Parallel()
 
@@ -159,6 +222,7 @@ func TestAcquireJob(t *testing.T) {
 				Provisioner:    database.ProvisionerTypeEcho,
 				StorageMethod:  database.ProvisionerStorageMethodFile,
 				Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
+				Priority:       database.ProvisionerJobPriorityInteractive,
 			})
 			require.NoError(t, err)
 			_, err = tc.acquire(ctx, srv)
@@ -512,6 +576,33 @@ func TestAcquireJob(t *testing.T) {
 			require.NoError(t, err)
 			require.JSONEq(t, string(want), string(got))
 		})
+		t.Run(tc.name+"_OmitTemplateSourceArchive", func(t *testing.T) {
+			t.Parallel()
+			for _, omit := range []bool{false, true} {
+				srv, db, ps, _ := setup(t, false, &overrides{omitTemplateSourceArchive: omit})
+				ctx := testutil.Context(t, testutil.WaitShort)
+
+				user := dbgen.User(t, db, database.User{})
+				file := dbgen.File(t, db, database.File{CreatedBy: user.ID, Data: []byte("archive")})
+				_ = dbgen.ProvisionerJob(t, db, ps, database.ProvisionerJob{
+					FileID:        file.ID,
+					InitiatorID:   user.ID,
+					Provisioner:   database.ProvisionerTypeEcho,
+					StorageMethod: database.ProvisionerStorageMethodFile,
+					Type:          database.ProvisionerJobTypeTemplateVersionImport,
+				})
+
+				job, err := tc.acquire(ctx, srv)
+				require.NoError(t, err)
+				require.Equal(t, file.ID.String(), job.TemplateSourceFileId)
+				require.Equal(t, file.Hash, job.TemplateSourceHash)
+				if omit {
+					require.Empty(t, job.TemplateSourceArchive)
+				} else {
+					require.Equal(t, file.Data, job.TemplateSourceArchive)
+				}
+			}
+		})
 		t.Run(tc.name+"_TemplateVersionImportWithUserVariable", func(t *testing.T) {
 			t.Parallel()
 			srv, db, ps, _ := setup(t, false, nil)
@@ -582,6 +673,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.UpdateJob(ctx, &proto.UpdateJobRequest{
@@ -598,6 +690,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -620,6 +713,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -841,6 +935,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -864,6 +959,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -916,6 +1012,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		err = db.InsertWorkspaceBuild(ctx, database.InsertWorkspaceBuildParams{
@@ -1008,6 +1105,7 @@ func TestCompleteJob(t *testing.T) {
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
 			OrganizationID: pd.OrganizationID,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -1043,6 +1141,7 @@ func TestCompleteJob(t *testing.T) {
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
 			OrganizationID: pd.OrganizationID,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -1101,6 +1200,7 @@ func TestCompleteJob(t *testing.T) {
 			Input:          []byte(`{"template_version_id": "` + versionID.String() + `"}`),
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -1414,6 +1514,7 @@ func TestCompleteJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -1500,7 +1601,8 @@ func TestCompleteJob(t *testing.T) {
 					Transition: database.WorkspaceTransitionStart,
 				}},
 				provisionerJobParams: database.InsertProvisionerJobParams{
-					Type: database.ProvisionerJobTypeTemplateVersionDryRun,
+					Type:     database.ProvisionerJobTypeTemplateVersionDryRun,
+					Priority: database.ProvisionerJobPriorityInteractive,
 				},
 			},
 			{
@@ -1541,6 +1643,7 @@ func TestCompleteJob(t *testing.T) {
 					Input: must(json.Marshal(provisionerdserver.TemplateVersionImportJob{
 						TemplateVersionID: templateVersionID,
 					})),
+					Priority: database.ProvisionerJobPriorityInteractive,
 				},
 				expectedResources: []database.WorkspaceResource{{
 					Name: "something",
@@ -1623,6 +1726,7 @@ func TestCompleteJob(t *testing.T) {
 					Input: must(json.Marshal(provisionerdserver.WorkspaceProvisionJob{
 						WorkspaceBuildID: workspaceBuildID,
 					})),
+					Priority: database.ProvisionerJobPriorityInteractive,
 				},
 			},
 		}
@@ -2603,6 +2707,7 @@ type overrides struct {
 	heartbeatInterval           time.Duration
 	auditor                     audit.Auditor
 	notificationEnqueuer        notifications.Enqueuer
+	omitTemplateSourceArchive   bool
 }
 
 func setup(t *testing.T, ignoreLogErrors bool, ov *overrides) (proto.DRPCProvisionerDaemonServer, database.Store, pubsub.Pubsub, database.ProvisionerDaemon) {
@@ -2709,6 +2814,8 @@ func setup(t *testing.T, ignoreLogErrors bool, ov *overrides) (proto.DRPCProvisi
 			AcquireJobLongPollDur: pollDur,
 			HeartbeatInterval:     ov.heartbeatInterval,
 			HeartbeatFn:           ov.heartbeatFn,
+
+			OmitTemplateSourceArchive: ov.omitTemplateSourceArchive,
 		},
 		notifEnq,
 	)
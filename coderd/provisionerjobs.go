@@ -361,6 +361,7 @@ func convertProvisionerJob(pj database.GetProvisionerJobsByIDsWithQueuePositionR
 		ErrorCode:      codersdk.JobErrorCode(provisionerJob.ErrorCode.String),
 		FileID:         provisionerJob.FileID,
 		Tags:           provisionerJob.Tags,
+		Priority:       codersdk.ProvisionerJobPriority(provisionerJob.Priority),
 		QueuePosition:  int(pj.QueuePosition),
 		QueueSize:      int(pj.QueueSize),
 	}
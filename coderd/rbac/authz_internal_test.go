@@ -1053,6 +1053,48 @@ func TestAuthorizeScope(t *testing.T) {
 			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner("not-me"), actions: []policy.Action{policy.ActionCreate}, allow: false},
 		},
 	)
+	user = Subject{
+		ID: "me",
+		Roles: Roles{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(ScopedRoleOrgMember(defOrg))),
+		},
+		Scope: AllowListScope{
+			Scope:       ScopeWorkspaceStart,
+			AllowIDList: []string{workspaceID.String()},
+		},
+	}
+
+	testAuthorize(t, "User_ScopeWorkspaceStart", user,
+		// Actions outside the scope are never allowed.
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []policy.Action{policy.ActionDelete, policy.ActionWorkspaceStop, policy.ActionSSH}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.WithID(workspaceID).InOrg(defOrg).WithOwner(user.ID)},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.ID)},
+		}),
+		// Resources outside the allow list are never allowed.
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []policy.Action{policy.ActionRead, policy.ActionWorkspaceStart}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.WithID(uuid.New()).InOrg(defOrg).WithOwner(user.ID)},
+			{resource: ResourceTemplate.WithID(uuid.New()).InOrg(defOrg)},
+		}),
+		// Allowed by scope:
+		[]authTestCase{
+			{resource: ResourceWorkspace.WithID(workspaceID).InOrg(defOrg).WithOwner(user.ID), actions: []policy.Action{policy.ActionRead, policy.ActionUpdate, policy.ActionWorkspaceStart}, allow: true},
+			// The allow list only applies to workspaces and templates, so the
+			// resources needed to start a workspace are allowed.
+			{resource: ResourceOrganization.WithID(defOrg).InOrg(defOrg), actions: []policy.Action{policy.ActionRead}, allow: true},
+			{resource: ResourceProvisionerDaemon.WithID(uuid.New()).InOrg(defOrg), actions: []policy.Action{policy.ActionRead}, allow: true},
+			// The scope will return true, but the user perms return false for resources not owned by the user.
+			{resource: ResourceWorkspace.WithID(workspaceID).InOrg(defOrg).WithOwner("not-me"), actions: []policy.Action{policy.ActionWorkspaceStart}, allow: false},
+		},
+	)
 }
 
 // cases applies a given function to all test cases. This makes generalities easier to create.
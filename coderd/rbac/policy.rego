@@ -227,6 +227,15 @@ scope_allow_list if {
 	input.object.id in input.subject.scope.allow_list
 }
 
+# A scope can limit its allow_list to the resource types it acts on. Other
+# resources it grants access to, such as the provisioner jobs of a build, are
+# needed to act on the allowed resources and cannot be listed in advance.
+scope_allow_list if {
+	not "*" in input.subject.scope.allow_list
+	input.subject.scope.allow_list_types
+	not input.object.type in input.subject.scope.allow_list_types
+}
+
 # The allow block is quite simple. Any set with `-1` cascades down in levels.
 # Authorization looks for any `allow` statement that is true. Multiple can be true!
 # Note that the absence of `allow` means "unauthorized".
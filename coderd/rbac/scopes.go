@@ -2,6 +2,8 @@ package rbac
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -50,9 +52,45 @@ func WorkspaceAgentScope(params WorkspaceAgentScopeParams) Scope {
 const (
 	ScopeAll                ScopeName = "all"
 	ScopeApplicationConnect ScopeName = "application_connect"
+	ScopeWorkspaceRead      ScopeName = "workspace:read"
+	ScopeWorkspaceStart     ScopeName = "workspace:start"
+	ScopeWorkspaceStop      ScopeName = "workspace:stop"
+	ScopeWorkspaceSSH       ScopeName = "workspace:ssh"
+	ScopeTemplateRead       ScopeName = "template:read"
+	ScopeTemplatePush       ScopeName = "template:push"
 )
 
-// TODO: Support passing in scopeID list for allowlisting resources.
+// scopeBasePermissions are granted to every named resource scope so the
+// token can look up its own user and organizations, which most clients do
+// before anything else.
+var scopeBasePermissions = map[string][]policy.Action{
+	ResourceUser.Type:               {policy.ActionRead, policy.ActionReadPersonal},
+	ResourceOrganization.Type:       {policy.ActionRead},
+	ResourceOrganizationMember.Type: {policy.ActionRead},
+}
+
+// resourceScope returns a scope that only allows the given permissions, in
+// addition to scopeBasePermissions.
+func resourceScope(name ScopeName, displayName string, perms map[string][]policy.Action) Scope {
+	all := make(map[string][]policy.Action, len(scopeBasePermissions)+len(perms))
+	for resource, actions := range scopeBasePermissions {
+		all[resource] = slices.Clone(actions)
+	}
+	for resource, actions := range perms {
+		all[resource] = append(all[resource], actions...)
+	}
+	return Scope{
+		Role: Role{
+			Identifier:  RoleIdentifier{Name: fmt.Sprintf("Scope_%s", name)},
+			DisplayName: displayName,
+			Site:        Permissions(all),
+			Org:         map[string][]Permission{},
+			User:        []Permission{},
+		},
+		AllowIDList: []string{policy.WildcardSymbol},
+	}
+}
+
 var builtinScopes = map[ScopeName]Scope{
 	// ScopeAll is a special scope that allows access to all resources. During
 	// authorize checks it is usually not used directly and skips scope checks.
@@ -81,6 +119,37 @@ var builtinScopes = map[ScopeName]Scope{
 		},
 		AllowIDList: []string{policy.WildcardSymbol},
 	},
+
+	ScopeWorkspaceRead: resourceScope(ScopeWorkspaceRead, "Read workspaces", map[string][]policy.Action{
+		ResourceWorkspace.Type: {policy.ActionRead},
+		ResourceTemplate.Type:  {policy.ActionRead},
+	}),
+	// Starting and stopping a workspace also requires updating it, as the
+	// parameters of the build are stored with the workspace.
+	ScopeWorkspaceStart: resourceScope(ScopeWorkspaceStart, "Start workspaces", map[string][]policy.Action{
+		ResourceWorkspace.Type:         {policy.ActionRead, policy.ActionUpdate, policy.ActionWorkspaceStart},
+		ResourceTemplate.Type:          {policy.ActionRead},
+		ResourceProvisionerDaemon.Type: {policy.ActionRead},
+	}),
+	ScopeWorkspaceStop: resourceScope(ScopeWorkspaceStop, "Stop workspaces", map[string][]policy.Action{
+		ResourceWorkspace.Type:         {policy.ActionRead, policy.ActionUpdate, policy.ActionWorkspaceStop},
+		ResourceTemplate.Type:          {policy.ActionRead},
+		ResourceProvisionerDaemon.Type: {policy.ActionRead},
+	}),
+	ScopeWorkspaceSSH: resourceScope(ScopeWorkspaceSSH, "SSH into workspaces", map[string][]policy.Action{
+		ResourceWorkspace.Type: {policy.ActionRead, policy.ActionSSH},
+		ResourceTemplate.Type:  {policy.ActionRead},
+	}),
+	ScopeTemplateRead: resourceScope(ScopeTemplateRead, "Read templates", map[string][]policy.Action{
+		ResourceTemplate.Type: {policy.ActionRead},
+		ResourceFile.Type:     {policy.ActionRead},
+	}),
+	ScopeTemplatePush: resourceScope(ScopeTemplatePush, "Push template versions", map[string][]policy.Action{
+		ResourceTemplate.Type:          {policy.ActionRead, policy.ActionCreate, policy.ActionUpdate},
+		ResourceFile.Type:              {policy.ActionRead, policy.ActionCreate},
+		ResourceProvisionerDaemon.Type: {policy.ActionRead},
+		ResourceProvisionerJobs.Type:   {policy.ActionRead},
+	}),
 }
 
 type ExpandableScope interface {
@@ -105,9 +174,55 @@ func (name ScopeName) Name() RoleIdentifier {
 // reject any resource that is not in the AllowIDList.
 // To not use an AllowIDList to reject authorization, use a wildcard for the
 // AllowIDList. Eg: 'AllowIDList: []string{WildcardSymbol}'
+// If AllowListTypes is set, the AllowIDList only applies to resources of
+// those types.
 type Scope struct {
 	Role
-	AllowIDList []string `json:"allow_list"`
+	AllowIDList    []string `json:"allow_list"`
+	AllowListTypes []string `json:"allow_list_types,omitempty"`
+}
+
+// allowListTypes are the resource types an allow list restricts for each named
+// scope. The other resources of a scope, such as the provisioner jobs of a
+// build or the files of a template version, are needed to act on the allowed
+// ones.
+var allowListTypes = map[ScopeName][]string{
+	ScopeWorkspaceRead:  {ResourceWorkspace.Type, ResourceTemplate.Type},
+	ScopeWorkspaceStart: {ResourceWorkspace.Type, ResourceTemplate.Type},
+	ScopeWorkspaceStop:  {ResourceWorkspace.Type, ResourceTemplate.Type},
+	ScopeWorkspaceSSH:   {ResourceWorkspace.Type, ResourceTemplate.Type},
+	ScopeTemplateRead:   {ResourceTemplate.Type},
+	ScopeTemplatePush:   {ResourceTemplate.Type},
+}
+
+// ScopeSupportsAllowList reports whether a named scope can be restricted with
+// an allow list. Other scopes have no resource types to restrict, so an allow
+// list would apply to every resource.
+func ScopeSupportsAllowList(scope ScopeName) bool {
+	_, ok := allowListTypes[scope]
+	return ok
+}
+
+// AllowListScope restricts a named scope to the resources with the given IDs.
+type AllowListScope struct {
+	Scope       ScopeName `json:"scope"`
+	AllowIDList []string  `json:"allow_list"`
+}
+
+func (s AllowListScope) Expand() (Scope, error) {
+	scope, err := ExpandScope(s.Scope)
+	if err != nil {
+		return Scope{}, err
+	}
+	scope.AllowIDList = slices.Clone(s.AllowIDList)
+	scope.AllowListTypes = allowListTypes[s.Scope]
+	return scope, nil
+}
+
+// Name includes the allow list, so subjects with different allow lists are
+// never considered equal.
+func (s AllowListScope) Name() RoleIdentifier {
+	return RoleIdentifier{Name: fmt.Sprintf("%s[%s]", s.Scope, strings.Join(s.AllowIDList, ","))}
 }
 
 func (s Scope) Expand() (Scope, error) {
@@ -0,0 +1,167 @@
+package coderd
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
+	"github.com/coder/coder/v2/coderd/util/ptr"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// requireSessionRecordingRead only allows site-wide auditors to access session
+// recordings.
+func (api *API) requireSessionRecordingRead(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if !api.Authorize(r, policy.ActionRead, rbac.ResourceAuditLog) {
+			httpapi.Forbidden(rw)
+			return
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// @Summary Get session recordings
+// @ID get-session-recordings
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Audit
+// @Param workspace query string false "Workspace ID" format(uuid)
+// @Param limit query int false "Page limit"
+// @Param offset query int false "Page offset"
+// @Success 200 {array} codersdk.SessionRecording
+// @Router /session-recordings [get]
+func (api *API) sessionRecordings(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, ok := parsePagination(rw, r)
+	if !ok {
+		return
+	}
+	parser := httpapi.NewQueryParamParser()
+	workspaceID := parser.UUID(r.URL.Query(), uuid.Nil, "workspace")
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid query parameters.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+
+	rows, err := api.Database.GetWorkspaceSessionRecordings(ctx, database.GetWorkspaceSessionRecordingsParams{
+		WorkspaceID: workspaceID,
+		// #nosec G115 - Pagination limits are small and positive.
+		LimitOpt: int32(page.Limit),
+		// #nosec G115 - Pagination offsets are small and positive.
+		OffsetOpt: int32(page.Offset),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching session recordings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	res := make([]codersdk.SessionRecording, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, convertSessionRecording(database.GetWorkspaceSessionRecordingByIDRow(row)))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, res)
+}
+
+// @Summary Get session recording by ID
+// @ID get-session-recording-by-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Audit
+// @Param sessionrecording path string true "Session recording ID" format(uuid)
+// @Success 200 {object} codersdk.SessionRecording
+// @Router /session-recordings/{sessionrecording} [get]
+func (api *API) sessionRecording(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recording, ok := api.fetchSessionRecording(rw, r)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertSessionRecording(recording))
+}
+
+// @Summary Get session recording cast
+// @Description The recording is returned in the asciicast v2 format.
+// @ID get-session-recording-cast
+// @Security CoderSessionToken
+// @Tags Audit
+// @Param sessionrecording path string true "Session recording ID" format(uuid)
+// @Success 200
+// @Router /session-recordings/{sessionrecording}/cast [get]
+func (api *API) sessionRecordingCast(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	recording, ok := api.fetchSessionRecording(rw, r)
+	if !ok {
+		return
+	}
+	chunks, err := api.Database.GetWorkspaceSessionRecordingChunks(ctx, recording.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching session recording.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/x-asciicast")
+	rw.WriteHeader(http.StatusOK)
+	for _, chunk := range chunks {
+		if _, err := rw.Write(chunk.Data); err != nil {
+			return
+		}
+	}
+}
+
+func (api *API) fetchSessionRecording(rw http.ResponseWriter, r *http.Request) (database.GetWorkspaceSessionRecordingByIDRow, bool) {
+	ctx := r.Context()
+
+	id, ok := httpmw.ParseUUIDParam(rw, r, "sessionrecording")
+	if !ok {
+		return database.GetWorkspaceSessionRecordingByIDRow{}, false
+	}
+	recording, err := api.Database.GetWorkspaceSessionRecordingByID(ctx, id)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return database.GetWorkspaceSessionRecordingByIDRow{}, false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching session recording.",
+			Detail:  err.Error(),
+		})
+		return database.GetWorkspaceSessionRecordingByIDRow{}, false
+	}
+	return recording, true
+}
+
+func convertSessionRecording(recording database.GetWorkspaceSessionRecordingByIDRow) codersdk.SessionRecording {
+	res := codersdk.SessionRecording{
+		ID:                     recording.ID,
+		WorkspaceID:            recording.WorkspaceID,
+		WorkspaceName:          recording.WorkspaceName,
+		WorkspaceOwnerID:       recording.WorkspaceOwnerID,
+		WorkspaceOwnerUsername: recording.WorkspaceOwnerUsername,
+		AgentID:                recording.AgentID,
+		Type:                   codersdk.SessionRecordingType(recording.Type),
+		StartedAt:              recording.StartedAt,
+		Size:                   recording.Size,
+	}
+	if recording.EndedAt.Valid {
+		res.EndedAt = ptr.Ref(recording.EndedAt.Time)
+	}
+	return res
+}
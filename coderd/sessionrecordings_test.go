@@ -0,0 +1,137 @@
+package coderd_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	agentproto "github.com/coder/coder/v2/agent/proto"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/util/ptr"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/agentsdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestSessionRecordings(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	client, db := coderdtest.NewWithDatabase(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	member, memberUser := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	auditor, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleAuditor())
+
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        memberUser.ID,
+	}).WithAgent().Do()
+
+	template, err := client.UpdateTemplateMeta(ctx, r.Workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		SessionRecordingEnabled:         ptr.Ref(true),
+		SessionRecordingRetentionMillis: ptr.Ref((30 * 24 * time.Hour).Milliseconds()),
+	})
+	require.NoError(t, err)
+	require.True(t, template.SessionRecordingEnabled)
+	require.Equal(t, (30 * 24 * time.Hour).Milliseconds(), template.SessionRecordingRetentionMillis)
+
+	_, err = client.UpdateTemplateMeta(ctx, r.Workspace.TemplateID, codersdk.UpdateTemplateMeta{
+		SessionRecordingRetentionMillis: ptr.Ref(time.Minute.Milliseconds()),
+	})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(r.AgentToken)
+	aAPI, _, err := agentClient.ConnectRPC25(ctx)
+	require.NoError(t, err)
+	defer func() {
+		_ = aAPI.DRPCConn().Close()
+	}()
+
+	manifest := requireGetManifest(ctx, t, aAPI)
+	require.True(t, manifest.SessionRecordingEnabled)
+
+	id := uuid.New()
+	startedAt := time.Now().Add(-time.Minute)
+	chunks := []string{
+		"{\"version\": 2, \"width\": 80, \"height\": 24}\n[0.100000, \"o\", \"hello \"]\n",
+		"[0.200000, \"o\", \"world\"]\n",
+	}
+	for i, chunk := range chunks {
+		req := &agentproto.UploadSessionRecordingRequest{
+			Id:         id[:],
+			Type:       agentproto.Connection_SSH,
+			StartedAt:  timestamppb.New(startedAt),
+			ChunkIndex: int32(i), //nolint:gosec
+			Data:       []byte(chunk),
+		}
+		if i == len(chunks)-1 {
+			req.EndedAt = timestamppb.Now()
+		}
+		_, err = aAPI.UploadSessionRecording(ctx, req)
+		require.NoError(t, err)
+	}
+
+	t.Run("Auditor", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		recordings, err := auditor.SessionRecordings(ctx, codersdk.SessionRecordingsRequest{
+			WorkspaceID: r.Workspace.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, recordings, 1)
+		require.Equal(t, id, recordings[0].ID)
+		require.Equal(t, codersdk.SessionRecordingTypeSSH, recordings[0].Type)
+		require.Equal(t, r.Workspace.Name, recordings[0].WorkspaceName)
+		require.Equal(t, memberUser.Username, recordings[0].WorkspaceOwnerUsername)
+		require.NotNil(t, recordings[0].EndedAt)
+		require.EqualValues(t, len(chunks[0])+len(chunks[1]), recordings[0].Size)
+
+		recording, err := auditor.SessionRecording(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, recordings[0], recording)
+
+		cast, err := auditor.SessionRecordingCast(ctx, id)
+		require.NoError(t, err)
+		defer cast.Close()
+		data, err := io.ReadAll(cast)
+		require.NoError(t, err)
+		require.Equal(t, chunks[0]+chunks[1], string(data))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		_, err := client.SessionRecording(ctx, uuid.New())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		// Workspace owners can't access recordings of their own sessions.
+		_, err := member.SessionRecordings(ctx, codersdk.SessionRecordingsRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		_, err = member.SessionRecordingCast(ctx, id)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
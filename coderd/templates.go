@@ -705,6 +705,31 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			maxPortShareLevel = database.AppSharingLevel(*req.MaxPortShareLevel)
 		}
 	}
+	// Defaults to the existing.
+	driftDetectionInterval := time.Duration(template.DriftDetectionInterval)
+	if req.DriftDetectionIntervalMillis != nil {
+		driftDetectionInterval = time.Duration(*req.DriftDetectionIntervalMillis) * time.Millisecond
+		// Drift checks run a full plan against the workspace's
+		// infrastructure, so they're kept infrequent.
+		if driftDetectionInterval < 0 || (driftDetectionInterval > 0 && driftDetectionInterval < time.Hour) {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "drift_detection_interval_ms", Detail: "Value must be 0 or at least one hour."})
+		}
+	}
+	sessionRecordingEnabled := template.SessionRecordingEnabled
+	if req.SessionRecordingEnabled != nil {
+		sessionRecordingEnabled = *req.SessionRecordingEnabled
+	}
+	sessionRecordingRetention := time.Duration(template.SessionRecordingRetention)
+	if req.SessionRecordingRetentionMillis != nil {
+		sessionRecordingRetention = time.Duration(*req.SessionRecordingRetentionMillis) * time.Millisecond
+		if sessionRecordingRetention < 0 || (sessionRecordingRetention > 0 && sessionRecordingRetention < time.Hour) {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "session_recording_retention_ms", Detail: "Value must be 0 or at least one hour."})
+		}
+	}
+	dlpPolicy := template.DLPPolicy
+	if req.DLPPolicy != nil {
+		dlpPolicy = database.DLPPolicy(*req.DLPPolicy)
+	}
 
 	if len(validErrs) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -733,7 +758,11 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			req.TimeTilDormantAutoDeleteMillis == time.Duration(template.TimeTilDormantAutoDelete).Milliseconds() &&
 			req.RequireActiveVersion == template.RequireActiveVersion &&
 			(deprecationMessage == template.Deprecated) &&
-			maxPortShareLevel == template.MaxPortSharingLevel {
+			maxPortShareLevel == template.MaxPortSharingLevel &&
+			driftDetectionInterval == time.Duration(template.DriftDetectionInterval) &&
+			sessionRecordingEnabled == template.SessionRecordingEnabled &&
+			sessionRecordingRetention == time.Duration(template.SessionRecordingRetention) &&
+			dlpPolicy == template.DLPPolicy {
 			return nil
 		}
 
@@ -774,6 +803,10 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			AllowUserCancelWorkspaceJobs: req.AllowUserCancelWorkspaceJobs,
 			GroupACL:                     groupACL,
 			MaxPortSharingLevel:          maxPortShareLevel,
+			DriftDetectionInterval:       int64(driftDetectionInterval),
+			SessionRecordingEnabled:      sessionRecordingEnabled,
+			SessionRecordingRetention:    int64(sessionRecordingRetention),
+			DLPPolicy:                    dlpPolicy,
 		})
 		if err != nil {
 			return xerrors.Errorf("update template metadata: %w", err)
@@ -1056,6 +1089,11 @@ func (api *API) convertTemplate(
 		Deprecated:           templateAccessControl.IsDeprecated(),
 		DeprecationMessage:   templateAccessControl.Deprecated,
 		MaxPortShareLevel:    maxPortShareLevel,
+
+		DriftDetectionIntervalMillis:    time.Duration(template.DriftDetectionInterval).Milliseconds(),
+		SessionRecordingEnabled:         template.SessionRecordingEnabled,
+		SessionRecordingRetentionMillis: time.Duration(template.SessionRecordingRetention).Milliseconds(),
+		DLPPolicy:                       codersdk.DLPPolicy(template.DLPPolicy),
 	}
 }
 
@@ -1068,6 +1068,37 @@ func TestPatchTemplateMeta(t *testing.T) {
 		assert.Equal(t, updated.Icon, "")
 	})
 
+	t.Run("DLPPolicy", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Equal(t, codersdk.DLPPolicy{}, template.DLPPolicy)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		policy := codersdk.DLPPolicy{
+			BlockSFTP:          true,
+			BlockClipboardCopy: true,
+		}
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:      template.Name,
+			DLPPolicy: &policy,
+		})
+		require.NoError(t, err)
+		require.Equal(t, policy, updated.DLPPolicy)
+
+		// The policy is left unchanged if it's not set.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:        template.Name,
+			DisplayName: "Updated",
+		})
+		require.NoError(t, err)
+		require.Equal(t, policy, updated.DLPPolicy)
+	})
+
 	t.Run("AutostopRequirement", func(t *testing.T) {
 		t.Parallel()
 
@@ -505,6 +505,12 @@ func (api *API) templateVersionExternalAuth(rw http.ResponseWriter, r *http.Requ
 			DisplayIcon:     config.DisplayIcon,
 			Optional:        rawProvider.Optional,
 		}
+		// Brokers issue credentials without the user authenticating.
+		if config.Broker != nil {
+			provider.Authenticated = true
+			providers = append(providers, provider)
+			continue
+		}
 
 		authLink, err := api.Database.GetExternalAuthLink(ctx, database.GetExternalAuthLinkParams{
 			ProviderID: config.ID,
@@ -674,6 +680,7 @@ func (api *API) postTemplateVersionDryRun(rw http.ResponseWriter, r *http.Reques
 			Valid:      true,
 			RawMessage: metadataRaw,
 		},
+		Priority: database.ProvisionerJobPriorityInteractive,
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -1778,6 +1785,7 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 				Valid:      true,
 				RawMessage: traceMetadataRaw,
 			},
+			Priority: database.ProvisionerJobPriorityInteractive,
 		})
 		if err != nil {
 			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -356,6 +356,32 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ServiceAccount {
+		if req.UserLoginType != "" && req.UserLoginType != codersdk.LoginTypeNone {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Service accounts cannot log in interactively.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "login_type",
+					Detail: fmt.Sprintf("Must be %q or empty for service accounts.", codersdk.LoginTypeNone),
+				}},
+			})
+			return
+		}
+		// Service accounts only authenticate with API tokens.
+		req.UserLoginType = codersdk.LoginTypeNone
+
+		if len(req.OrganizationIDs) != 1 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Service accounts must be owned by exactly one organization.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "organization_ids",
+					Detail: "Must contain exactly one value for service accounts.",
+				}},
+			})
+			return
+		}
+	}
+
 	if req.UserLoginType == "" {
 		// Default to password auth
 		req.UserLoginType = codersdk.LoginTypePassword
@@ -1412,6 +1438,9 @@ func (api *API) CreateUser(ctx context.Context, store database.Store, req Create
 	if usernameValid := codersdk.NameValid(req.Username); usernameValid != nil {
 		return database.User{}, xerrors.Errorf("invalid username %q: %w", req.Username, usernameValid)
 	}
+	if req.ServiceAccount && len(req.OrganizationIDs) != 1 {
+		return database.User{}, xerrors.Errorf("service accounts must be owned by exactly one organization, got %d", len(req.OrganizationIDs))
+	}
 
 	// If the caller didn't specify rbac roles, default to
 	// a member of the site.
@@ -1440,6 +1469,10 @@ func (api *API) CreateUser(ctx context.Context, store database.Store, req Create
 			LoginType:      req.LoginType,
 			Status:         status,
 		}
+		if req.ServiceAccount {
+			params.IsServiceAccount = true
+			params.ServiceAccountOrganizationID = uuid.NullUUID{UUID: req.OrganizationIDs[0], Valid: true}
+		}
 		// If a user signs up with OAuth, they can have no password!
 		if req.Password != "" {
 			hashedPassword, err := userpassword.Hash(req.Password)
@@ -1567,6 +1600,14 @@ func userOrganizationIDs(ctx context.Context, api *API, user database.User) ([]u
 }
 
 func convertAPIKey(k database.APIKey) codersdk.APIKey {
+	allowList := make([]uuid.UUID, 0, len(k.AllowList))
+	for _, id := range k.AllowList {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		allowList = append(allowList, parsed)
+	}
 	return codersdk.APIKey{
 		ID:              k.ID,
 		UserID:          k.UserID,
@@ -1578,5 +1619,6 @@ func convertAPIKey(k database.APIKey) codersdk.APIKey {
 		Scope:           codersdk.APIKeyScope(k.Scope),
 		LifetimeSeconds: k.LifetimeSeconds,
 		TokenName:       k.TokenName,
+		AllowList:       allowList,
 	}
 }
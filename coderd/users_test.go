@@ -842,6 +842,106 @@ func TestPostUsers(t *testing.T) {
 	})
 }
 
+func TestServiceAccount(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create", func(t *testing.T) {
+		t.Parallel()
+		client, db := coderdtest.NewWithDatabase(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		sa, err := client.CreateUserWithOrgs(ctx, codersdk.CreateUserRequestWithOrgs{
+			OrganizationIDs: []uuid.UUID{first.OrganizationID},
+			Email:           "ci@coder.com",
+			Username:        "ci-bot",
+			ServiceAccount:  true,
+		})
+		require.NoError(t, err)
+		require.True(t, sa.IsServiceAccount)
+		require.Equal(t, codersdk.LoginTypeNone, sa.LoginType)
+		require.Equal(t, []uuid.UUID{first.OrganizationID}, sa.OrganizationIDs)
+
+		// Service accounts show up as such in the audit log.
+		dbgen.AuditLog(t, db, database.AuditLog{
+			UserID:         sa.ID,
+			OrganizationID: first.OrganizationID,
+		})
+		logs, err := client.AuditLogs(ctx, codersdk.AuditLogsRequest{
+			SearchQuery: "username:" + sa.Username,
+		})
+		require.NoError(t, err)
+		require.Len(t, logs.AuditLogs, 1)
+		require.NotNil(t, logs.AuditLogs[0].User)
+		require.True(t, logs.AuditLogs[0].User.IsServiceAccount)
+
+		// Only scoped tokens can be created.
+		_, err = client.CreateToken(ctx, sa.ID.String(), codersdk.CreateTokenRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		_, err = client.CreateAPIKey(ctx, sa.ID.String())
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		token, err := client.CreateToken(ctx, sa.ID.String(), codersdk.CreateTokenRequest{
+			Scope: codersdk.APIKeyScopeWorkspaceRead,
+		})
+		require.NoError(t, err)
+		saClient := codersdk.New(client.URL)
+		saClient.SetSessionToken(token.Key)
+		me, err := saClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, sa.ID, me.ID)
+		require.True(t, me.IsServiceAccount)
+
+		// Service accounts can't join other organizations.
+		other := dbgen.Organization(t, db, database.Organization{})
+		_, err = client.PostOrganizationMember(ctx, other.ID, sa.ID.String())
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MultipleOrganizations", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateUserWithOrgs(ctx, codersdk.CreateUserRequestWithOrgs{
+			OrganizationIDs: []uuid.UUID{first.OrganizationID, uuid.New()},
+			Email:           "ci@coder.com",
+			Username:        "ci-bot",
+			ServiceAccount:  true,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("PasswordLogin", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateUserWithOrgs(ctx, codersdk.CreateUserRequestWithOrgs{
+			OrganizationIDs: []uuid.UUID{first.OrganizationID},
+			Email:           "ci@coder.com",
+			Username:        "ci-bot",
+			Password:        "SomeSecurePassword!",
+			UserLoginType:   codersdk.LoginTypePassword,
+			ServiceAccount:  true,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
 func TestNotifyCreatedUser(t *testing.T) {
 	t.Parallel()
 
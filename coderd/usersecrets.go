@@ -0,0 +1,340 @@
+package coderd
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// @Summary Get user secrets
+// @ID get-user-secrets
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {array} codersdk.UserSecret
+// @Router /users/{user}/secrets [get]
+func (api *API) userSecrets(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	secrets, err := api.Database.GetUserSecrets(ctx, user.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user secrets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	res := make([]codersdk.UserSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		res = append(res, convertUserSecret(secret))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, res)
+}
+
+// @Summary Get user secret by name
+// @ID get-user-secret-by-name
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Secret name"
+// @Success 200 {object} codersdk.UserSecret
+// @Router /users/{user}/secrets/{name} [get]
+func (api *API) userSecretByName(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+		name = chi.URLParam(r, "name")
+	)
+
+	secret, err := api.Database.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserSecret(secret))
+}
+
+// @Summary Create user secret
+// @ID create-user-secret
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.CreateUserSecretRequest true "Create secret request"
+// @Success 201 {object} codersdk.UserSecret
+// @Router /users/{user}/secrets [post]
+func (api *API) postUserSecret(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	var req codersdk.CreateUserSecretRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validations []codersdk.ValidationError
+	if err := codersdk.NameValid(req.Name); err != nil {
+		validations = append(validations, codersdk.ValidationError{Field: "name", Detail: err.Error()})
+	}
+	validations = append(validations, validateUserSecret(req.EnvName, req.FilePath)...)
+	if len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid secret.",
+			Validations: validations,
+		})
+		return
+	}
+
+	templateIDs := req.TemplateIDs
+	if templateIDs == nil {
+		templateIDs = []uuid.UUID{}
+	}
+	now := dbtime.Now()
+	secret, err := api.Database.InsertUserSecret(ctx, database.InsertUserSecretParams{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Value:       req.Value,
+		EnvName:     req.EnvName,
+		FilePath:    req.FilePath,
+		TemplateIDs: templateIDs,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if database.IsUniqueViolation(err, database.UniqueUserSecretsUserIDNameKey) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("A secret with name %q already exists.", req.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "name",
+				Detail: "This value is already in use and should be unique.",
+			}},
+		})
+		return
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertUserSecret(secret))
+}
+
+// @Summary Update user secret
+// @ID update-user-secret
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Secret name"
+// @Param request body codersdk.UpdateUserSecretRequest true "Update secret request"
+// @Success 200 {object} codersdk.UserSecret
+// @Router /users/{user}/secrets/{name} [patch]
+func (api *API) patchUserSecret(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+		name = chi.URLParam(r, "name")
+	)
+
+	var req codersdk.UpdateUserSecretRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	secret, err := api.Database.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	params := database.UpdateUserSecretParams{
+		ID:          secret.ID,
+		Description: secret.Description,
+		Value:       secret.Value,
+		EnvName:     secret.EnvName,
+		FilePath:    secret.FilePath,
+		TemplateIDs: secret.TemplateIDs,
+		UpdatedAt:   dbtime.Now(),
+	}
+	if req.Description != nil {
+		params.Description = *req.Description
+	}
+	if req.Value != nil {
+		if *req.Value == "" {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid secret.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "value",
+					Detail: "Must not be empty.",
+				}},
+			})
+			return
+		}
+		params.Value = *req.Value
+	}
+	if req.EnvName != nil {
+		params.EnvName = *req.EnvName
+	}
+	if req.FilePath != nil {
+		params.FilePath = *req.FilePath
+	}
+	if req.TemplateIDs != nil {
+		params.TemplateIDs = *req.TemplateIDs
+		if params.TemplateIDs == nil {
+			params.TemplateIDs = []uuid.UUID{}
+		}
+	}
+	if validations := validateUserSecret(params.EnvName, params.FilePath); len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid secret.",
+			Validations: validations,
+		})
+		return
+	}
+
+	secret, err = api.Database.UpdateUserSecret(ctx, params)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserSecret(secret))
+}
+
+// @Summary Delete user secret
+// @ID delete-user-secret
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Secret name"
+// @Success 204
+// @Router /users/{user}/secrets/{name} [delete]
+func (api *API) deleteUserSecret(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+		name = chi.URLParam(r, "name")
+	)
+
+	secret, err := api.Database.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.Database.DeleteUserSecret(ctx, secret.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// validateUserSecret validates where a secret is injected into workspaces.
+func validateUserSecret(envName, filePath string) []codersdk.ValidationError {
+	var validations []codersdk.ValidationError
+	if envName != "" {
+		if err := codersdk.UserSecretEnvNameValid(envName); err != nil {
+			validations = append(validations, codersdk.ValidationError{Field: "env_name", Detail: err.Error()})
+		}
+	}
+	if filePath != "" {
+		cleaned := path.Clean(filePath)
+		if cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.HasSuffix(filePath, "/") {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  "file_path",
+				Detail: "Must be a file in or below the home directory, or an absolute path.",
+			})
+		}
+	}
+	return validations
+}
+
+func convertUserSecret(secret database.UserSecret) codersdk.UserSecret {
+	return codersdk.UserSecret{
+		ID:          secret.ID,
+		UserID:      secret.UserID,
+		Name:        secret.Name,
+		Description: secret.Description,
+		EnvName:     secret.EnvName,
+		FilePath:    secret.FilePath,
+		TemplateIDs: secret.TemplateIDs,
+		CreatedAt:   secret.CreatedAt,
+		UpdatedAt:   secret.UpdatedAt,
+	}
+}
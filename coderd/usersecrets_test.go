@@ -0,0 +1,115 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestUserSecrets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		owner := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		templateID := uuid.New()
+		secret, err := member.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+			Name:        "npm-token",
+			Value:       "hunter2",
+			EnvName:     "NPM_TOKEN",
+			TemplateIDs: []uuid.UUID{templateID},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "npm-token", secret.Name)
+		require.Equal(t, "NPM_TOKEN", secret.EnvName)
+		require.Equal(t, []uuid.UUID{templateID}, secret.TemplateIDs)
+
+		_, err = member.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+			Name:  "npm-token",
+			Value: "hunter2",
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusConflict, sdkErr.StatusCode())
+
+		filePath := ".npmrc"
+		secret, err = member.UpdateUserSecret(ctx, codersdk.Me, "npm-token", codersdk.UpdateUserSecretRequest{
+			FilePath:    &filePath,
+			TemplateIDs: &[]uuid.UUID{},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "NPM_TOKEN", secret.EnvName)
+		require.Equal(t, ".npmrc", secret.FilePath)
+		require.Empty(t, secret.TemplateIDs)
+
+		secrets, err := member.UserSecrets(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+
+		err = member.DeleteUserSecret(ctx, codersdk.Me, "npm-token")
+		require.NoError(t, err)
+		_, err = member.UserSecretByName(ctx, codersdk.Me, "npm-token")
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		for _, req := range []codersdk.CreateUserSecretRequest{
+			{Name: "bad-env", Value: "v", EnvName: "1FOO"},
+			{Name: "coder-env", Value: "v", EnvName: "CODER_AGENT_TOKEN"},
+			{Name: "bad-file", Value: "v", FilePath: "../outside"},
+			{Name: "Bad Name", Value: "v"},
+		} {
+			_, err := client.CreateUserSecret(ctx, codersdk.Me, req)
+			var sdkErr *codersdk.Error
+			require.ErrorAs(t, err, &sdkErr, req.Name)
+			require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode(), req.Name)
+		}
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		owner := coderdtest.CreateFirstUser(t, client)
+		member, user := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		otherMember, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := member.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+			Name:  "npm-token",
+			Value: "hunter2",
+		})
+		require.NoError(t, err)
+
+		_, err = otherMember.UserSecretByName(ctx, user.ID.String(), "npm-token")
+		require.Error(t, err)
+		_, err = otherMember.UserSecrets(ctx, user.ID.String())
+		require.Error(t, err)
+
+		// Template admins can see other users, but not write their secrets.
+		templateAdmin, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleTemplateAdmin())
+		_, err = templateAdmin.CreateUserSecret(ctx, user.ID.String(), codersdk.CreateUserSecretRequest{
+			Name:  "other-token",
+			Value: "hunter2",
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+}
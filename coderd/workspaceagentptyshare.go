@@ -0,0 +1,257 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
+	"github.com/coder/coder/v2/coderd/workspaceapps"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// @Summary Upsert workspace agent PTY share
+// @ID upsert-workspace-agent-pty-share
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Param request body codersdk.UpsertWorkspaceAgentPTYShareRequest true "Upsert PTY share request"
+// @Success 200 {object} codersdk.WorkspaceAgentPTYShare
+// @Router /workspaceagents/{workspaceagent}/pty-shares [post]
+func (api *API) postWorkspaceAgentPTYShare(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+	// Only users that can connect to the workspace may share its terminals.
+	apiKey, ok := httpmw.APIKeyOptional(r)
+	if !ok || !api.Authorize(r, policy.ActionSSH, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpsertWorkspaceAgentPTYShareRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.ReconnectID == uuid.Nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "A reconnect ID is required.",
+			Validations: []codersdk.ValidationError{
+				{Field: "reconnect_id", Detail: "A reconnect ID is required."},
+			},
+		})
+		return
+	}
+	if !req.Mode.Valid() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid PTY share mode.",
+			Validations: []codersdk.ValidationError{
+				{Field: "mode", Detail: "Mode must be read_only or read_write."},
+			},
+		})
+		return
+	}
+
+	var (
+		user database.User
+		err  error
+	)
+	// Members may not be allowed to read the users they share with.
+	// nolint:gocritic // Only the ID and username of the user are returned.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	if userID, uuidErr := uuid.Parse(req.User); uuidErr == nil {
+		user, err = api.Database.GetUserByID(sysCtx, userID)
+	} else {
+		user, err = api.Database.GetUserByEmailOrUsername(sysCtx, database.GetUserByEmailOrUsernameParams{
+			Username: req.User,
+		})
+	}
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "User not found.",
+			Validations: []codersdk.ValidationError{
+				{Field: "user", Detail: "User not found."},
+			},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if user.ID == workspace.OwnerID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The workspace owner can already access its terminals.",
+		})
+		return
+	}
+
+	share, err := api.Database.UpsertWorkspaceAgentPTYShare(ctx, database.UpsertWorkspaceAgentPTYShareParams{
+		WorkspaceID: workspace.ID,
+		AgentID:     workspaceAgent.ID,
+		ReconnectID: req.ReconnectID,
+		UserID:      user.ID,
+		Mode:        database.PTYShareMode(req.Mode),
+		CreatedBy:   apiKey.UserID,
+		CreatedAt:   dbtime.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	api.publishPTYShareEvent(ctx, workspaceapps.PTYShareEvent{
+		AgentID:     share.AgentID,
+		ReconnectID: share.ReconnectID,
+		UserID:      share.UserID,
+		Mode:        req.Mode,
+	})
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertPTYShare(share, user.Username))
+}
+
+// @Summary Get workspace agent PTY shares
+// @ID get-workspace-agent-pty-shares
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceAgentPTYShares
+// @Router /workspaceagents/{workspaceagent}/pty-shares [get]
+func (api *API) workspaceAgentPTYShares(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+
+	shares, err := api.Database.ListWorkspaceAgentPTYShares(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(shares))
+	for _, share := range shares {
+		userIDs = append(userIDs, share.UserID)
+	}
+	// nolint:gocritic // Only the usernames of the users are returned.
+	users, err := api.Database.GetUsersByIDs(dbauthz.AsSystemRestricted(ctx), userIDs)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	usernames := make(map[uuid.UUID]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	converted := make([]codersdk.WorkspaceAgentPTYShare, 0, len(shares))
+	for _, share := range shares {
+		converted = append(converted, convertPTYShare(share, usernames[share.UserID]))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspaceAgentPTYShares{
+		Shares: converted,
+	})
+}
+
+// @Summary Delete workspace agent PTY share
+// @ID delete-workspace-agent-pty-share
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Param request body codersdk.DeleteWorkspaceAgentPTYShareRequest true "Delete PTY share request"
+// @Success 200
+// @Router /workspaceagents/{workspaceagent}/pty-shares [delete]
+func (api *API) deleteWorkspaceAgentPTYShare(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+	var req codersdk.DeleteWorkspaceAgentPTYShareRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	_, err := api.Database.GetWorkspaceAgentPTYShare(ctx, database.GetWorkspaceAgentPTYShareParams{
+		AgentID:     workspaceAgent.ID,
+		ReconnectID: req.ReconnectID,
+		UserID:      req.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+				Message: "PTY share not found.",
+			})
+			return
+		}
+
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	err = api.Database.DeleteWorkspaceAgentPTYShare(ctx, database.DeleteWorkspaceAgentPTYShareParams{
+		AgentID:     workspaceAgent.ID,
+		ReconnectID: req.ReconnectID,
+		UserID:      req.UserID,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	api.publishPTYShareEvent(ctx, workspaceapps.PTYShareEvent{
+		AgentID:     workspaceAgent.ID,
+		ReconnectID: req.ReconnectID,
+		UserID:      req.UserID,
+	})
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// publishPTYShareEvent closes the sessions a changed PTY share no longer
+// allows, on this replica and all others, and on workspace proxies.
+func (api *API) publishPTYShareEvent(ctx context.Context, event workspaceapps.PTYShareEvent) {
+	// Don't wait for the event to be delivered back to this replica.
+	api.workspaceAppServer.UpdatePTYShare(event)
+
+	msg, err := json.Marshal(event)
+	if err != nil {
+		api.Logger.Error(ctx, "marshal pty share event", slog.Error(err))
+		return
+	}
+	err = api.Pubsub.Publish(workspaceapps.PTYShareEventChannel, msg)
+	if err != nil {
+		api.Logger.Warn(ctx, "publish pty share event", slog.Error(err))
+	}
+}
+
+func (api *API) handlePTYShareEvent(ctx context.Context, msg []byte) {
+	var event workspaceapps.PTYShareEvent
+	err := json.Unmarshal(msg, &event)
+	if err != nil {
+		api.Logger.Warn(ctx, "unmarshal pty share event", slog.Error(err))
+		return
+	}
+	api.workspaceAppServer.UpdatePTYShare(event)
+}
+
+func convertPTYShare(share database.WorkspaceAgentPTYShare, username string) codersdk.WorkspaceAgentPTYShare {
+	return codersdk.WorkspaceAgentPTYShare{
+		WorkspaceID: share.WorkspaceID,
+		AgentID:     share.AgentID,
+		ReconnectID: share.ReconnectID,
+		UserID:      share.UserID,
+		Username:    username,
+		Mode:        codersdk.WorkspaceAgentPTYShareMode(share.Mode),
+		CreatedBy:   share.CreatedBy,
+		CreatedAt:   share.CreatedAt,
+	}
+}
@@ -0,0 +1,206 @@
+package coderd_test
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/agent/agenttest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestWorkspaceAgentPTYShare(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses cat.")
+	}
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	ownerClient, db := coderdtest.NewWithDatabase(t, nil)
+	owner := coderdtest.CreateFirstUser(t, ownerClient)
+	client, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+	otherClient, other := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        user.ID,
+	}).WithAgent().Do()
+	_ = agenttest.New(t, client.URL, r.AgentToken)
+	resources := coderdtest.AwaitWorkspaceAgents(t, client, r.Workspace.ID)
+	agentID := resources[0].Agents[0].ID
+
+	connect := func(c *codersdk.Client, reconnect uuid.UUID) (net.Conn, error) {
+		return workspacesdk.New(c).AgentReconnectingPTY(ctx, workspacesdk.WorkspaceAgentReconnectingPTYOpts{
+			AgentID:   agentID,
+			Reconnect: reconnect,
+			Width:     80,
+			Height:    80,
+			Command:   "cat",
+		})
+	}
+	write := func(conn net.Conn, data string) {
+		t.Helper()
+		b, err := json.Marshal(workspacesdk.ReconnectingPTYRequest{Data: data})
+		require.NoError(t, err)
+		_, err = conn.Write(b)
+		require.NoError(t, err)
+	}
+	contains := func(s string) func(string) bool {
+		return func(line string) bool {
+			return strings.Contains(line, s)
+		}
+	}
+
+	reconnect := uuid.New()
+	//nolint:bodyclose // The connection fails.
+	_, err := connect(otherClient, reconnect)
+	require.Error(t, err, "terminal is not shared yet")
+
+	ownerConn, err := connect(client, reconnect)
+	require.NoError(t, err)
+	defer ownerConn.Close()
+	ownerReader := testutil.NewTerminalReader(t, ownerConn)
+
+	_, err = client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+		ReconnectID: reconnect,
+		User:        user.Username,
+		Mode:        codersdk.WorkspaceAgentPTYShareModeReadOnly,
+	})
+	require.Error(t, err, "sharing with the workspace owner")
+	_, err = client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+		ReconnectID: reconnect,
+		User:        other.Username,
+		Mode:        "invalid",
+	})
+	require.Error(t, err, "invalid mode")
+	_, err = otherClient.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+		ReconnectID: reconnect,
+		User:        other.Username,
+		Mode:        codersdk.WorkspaceAgentPTYShareModeReadWrite,
+	})
+	require.Error(t, err, "users without access can't share")
+
+	share, err := client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+		ReconnectID: reconnect,
+		User:        other.Username,
+		Mode:        codersdk.WorkspaceAgentPTYShareModeReadOnly,
+	})
+	require.NoError(t, err)
+	require.Equal(t, other.ID, share.UserID)
+	require.Equal(t, user.ID, share.CreatedBy)
+
+	//nolint:bodyclose // The connection fails.
+	_, err = connect(otherClient, uuid.New())
+	require.Error(t, err, "only the shared terminal can be attached to")
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		viewerConn, err := connect(otherClient, reconnect)
+		require.NoError(t, err)
+		defer viewerConn.Close()
+		viewerReader := testutil.NewTerminalReader(t, viewerConn)
+
+		// Input from the viewer never reaches the PTY.
+		write(viewerConn, "viewer-input\r")
+		write(ownerConn, "owner-input\r")
+		require.NoError(t, viewerReader.ReadUntil(ctx, contains("owner-input")))
+		require.NoError(t, ownerReader.ReadUntil(ctx, func(line string) bool {
+			assert.NotContains(t, line, "viewer-input")
+			return strings.Contains(line, "owner-input")
+		}))
+
+		presence, err := otherClient.WorkspaceAgentPTYPresence(ctx, agentID, reconnect)
+		require.NoError(t, err)
+		require.Len(t, presence.Attachments, 2)
+		assert.Equal(t, user.Username, presence.Attachments[0].Username)
+		assert.False(t, presence.Attachments[0].ReadOnly)
+		assert.Equal(t, other.Username, presence.Attachments[1].Username)
+		assert.True(t, presence.Attachments[1].ReadOnly)
+	})
+
+	t.Run("ReadWrite", func(t *testing.T) {
+		_, err := client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+			ReconnectID: reconnect,
+			User:        other.ID.String(),
+			Mode:        codersdk.WorkspaceAgentPTYShareModeReadWrite,
+		})
+		require.NoError(t, err)
+
+		conn, err := connect(otherClient, reconnect)
+		require.NoError(t, err)
+		defer conn.Close()
+		write(conn, "shared-input\r")
+		require.NoError(t, ownerReader.ReadUntil(ctx, contains("shared-input")))
+	})
+
+	t.Run("Downgrade", func(t *testing.T) {
+		conn, err := connect(otherClient, reconnect)
+		require.NoError(t, err)
+		defer conn.Close()
+		write(conn, "before-downgrade\r")
+		require.NoError(t, ownerReader.ReadUntil(ctx, contains("before-downgrade")))
+
+		// Sessions that could write are closed when the share becomes
+		// read-only.
+		_, err = client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+			ReconnectID: reconnect,
+			User:        other.Username,
+			Mode:        codersdk.WorkspaceAgentPTYShareModeReadOnly,
+		})
+		require.NoError(t, err)
+		require.ErrorIs(t, testutil.NewTerminalReader(t, conn).ReadUntil(ctx, nil), io.EOF)
+
+		_, err = client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+			ReconnectID: reconnect,
+			User:        other.Username,
+			Mode:        codersdk.WorkspaceAgentPTYShareModeReadWrite,
+		})
+		require.NoError(t, err)
+	})
+
+	shares, err := client.WorkspaceAgentPTYShares(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, shares.Shares, 1)
+	require.Equal(t, other.Username, shares.Shares[0].Username)
+	require.Equal(t, codersdk.WorkspaceAgentPTYShareModeReadWrite, shares.Shares[0].Mode)
+
+	// Users a PTY is shared with can't start it.
+	notStarted := uuid.New()
+	_, err = client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+		ReconnectID: notStarted,
+		User:        other.Username,
+		Mode:        codersdk.WorkspaceAgentPTYShareModeReadWrite,
+	})
+	require.NoError(t, err)
+	conn, err := connect(otherClient, notStarted)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.ErrorIs(t, testutil.NewTerminalReader(t, conn).ReadUntil(ctx, nil), io.EOF)
+
+	// Deleting the share cuts off the user while they are attached.
+	guestConn, err := connect(otherClient, reconnect)
+	require.NoError(t, err)
+	defer guestConn.Close()
+	write(guestConn, "before-delete\r")
+	require.NoError(t, ownerReader.ReadUntil(ctx, contains("before-delete")))
+	err = client.DeleteWorkspaceAgentPTYShare(ctx, agentID, codersdk.DeleteWorkspaceAgentPTYShareRequest{
+		ReconnectID: reconnect,
+		UserID:      other.ID,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, testutil.NewTerminalReader(t, guestConn).ReadUntil(ctx, nil), io.EOF)
+	//nolint:bodyclose // The connection fails.
+	_, err = connect(otherClient, reconnect)
+	require.Error(t, err, "share was deleted")
+}
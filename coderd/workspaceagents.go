@@ -1551,6 +1551,11 @@ func (api *API) workspaceAgentsExternalAuth(rw http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if externalAuthConfig.Broker != nil {
+		api.workspaceAgentsExternalAuthBroker(ctx, rw, externalAuthConfig, workspace)
+		return
+	}
+
 	var previousToken *database.ExternalAuthLink
 	// handleRetrying will attempt to continually check for a new token
 	// if listen is true. This is useful if an error is encountered in the
@@ -1625,6 +1630,32 @@ func (api *API) workspaceAgentsExternalAuth(rw http.ResponseWriter, r *http.Requ
 	httpapi.Write(ctx, rw, http.StatusOK, resp)
 }
 
+// workspaceAgentsExternalAuthBroker responds with a credential issued by the
+// broker of the provider. There's nothing for the user to authenticate, so
+// listening isn't required.
+func (api *API) workspaceAgentsExternalAuthBroker(ctx context.Context, rw http.ResponseWriter, externalAuthConfig *externalauth.Config, workspace database.Workspace) {
+	link, err := externalAuthConfig.IssueToken(ctx, api.Database, externalauth.BrokerRequest{
+		UserID:   workspace.OwnerID,
+		Username: workspace.OwnerUsername,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to issue external auth credential.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	resp, err := createExternalAuthResponse(externalAuthConfig.Type, link.OAuthAccessToken, link.OAuthExtra)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to create external auth response.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
 func (api *API) workspaceAgentsExternalAuthListen(ctx context.Context, rw http.ResponseWriter, previous *database.ExternalAuthLink, externalAuthConfig *externalauth.Config, workspace database.Workspace) {
 	// Since we're ticking frequently and this sign-in operation is rare,
 	// we are OK with polling to avoid the complexity of pubsub.
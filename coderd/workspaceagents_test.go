@@ -7,6 +7,7 @@ import (
 	"maps"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"runtime"
 	"strconv"
@@ -2178,6 +2179,60 @@ func TestWorkspaceAgentExternalAuthListen(t *testing.T) {
 	})
 }
 
+func TestWorkspaceAgentExternalAuthBroker(t *testing.T) {
+	t.Parallel()
+
+	issued := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(externalauth.BrokerToken{
+			AccessToken: "brokered",
+			Expiry:      time.Now().Add(time.Hour),
+			Extra:       map[string]interface{}{"role": "deploy"},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	broker, err := externalauth.NewBroker(codersdk.ExternalAuthBrokerTypeHTTP, srv.URL, "", "")
+	require.NoError(t, err)
+
+	ownerClient, db := coderdtest.NewWithDatabase(t, &coderdtest.Options{
+		ExternalAuthConfigs: []*externalauth.Config{{
+			InstrumentedOAuth2Config: &testutil.OAuth2Config{},
+			ID:                       "deploy",
+			Type:                     "deploy",
+			Broker:                   broker,
+		}},
+	})
+	first := coderdtest.CreateFirstUser(t, ownerClient)
+	client, user := coderdtest.CreateAnotherUser(t, ownerClient, first.OrganizationID)
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: first.OrganizationID,
+		OwnerID:        user.ID,
+	}).WithAgent().Do()
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(r.AgentToken)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	for i := 0; i < 2; i++ {
+		resp, err := agentClient.ExternalAuth(ctx, agentsdk.ExternalAuthRequest{
+			ID: "deploy",
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.URL)
+		require.Equal(t, "brokered", resp.AccessToken)
+		require.Equal(t, "deploy", resp.TokenExtra["role"])
+	}
+	// The second request reuses the credential of the first.
+	require.Equal(t, 1, issued)
+
+	// The user doesn't have to authenticate with a broker.
+	auth, err := client.ExternalAuthByID(ctx, "deploy")
+	require.NoError(t, err)
+	require.True(t, auth.Authenticated)
+}
+
 func TestOwnedWorkspacesCoordinate(t *testing.T) {
 	t.Parallel()
 
@@ -2416,7 +2471,7 @@ func requireGetManifest(ctx context.Context, t testing.TB, aAPI agentproto.DRPCA
 }
 
 func postStartup(ctx context.Context, t testing.TB, client agent.Client, startup *agentproto.Startup) error {
-	aAPI, _, err := client.ConnectRPC24(ctx)
+	aAPI, _, err := client.ConnectRPC25(ctx)
 	require.NoError(t, err)
 	defer func() {
 		cErr := aAPI.DRPCConn().Close()
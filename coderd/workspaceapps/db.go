@@ -159,6 +159,24 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "verify authz")
 		return nil, "", false
 	}
+	// Users that can't access the workspace may still be allowed to attach
+	// to a reconnecting PTY that was shared with them.
+	var shareMode codersdk.WorkspaceAgentPTYShareMode
+	if !authed && authz != nil && appReq.AccessMethod == AccessMethodTerminal && appReq.ReconnectID != "" {
+		shareMode, err = p.authorizeSharedTerminal(dangerousSystemCtx, r.Context(), authz, dbReq)
+		if err != nil {
+			WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "verify shared terminal authz")
+			return nil, "", false
+		}
+		authed = shareMode != ""
+	}
+	if appReq.AccessMethod == AccessMethodTerminal && apiKey != nil && authz != nil {
+		token.Terminal = &TerminalClaims{
+			UserID:    apiKey.UserID,
+			Username:  authz.FriendlyName,
+			ShareMode: shareMode,
+		}
+	}
 	if !authed {
 		if apiKey != nil {
 			// The request has a valid API key but insufficient permissions.
@@ -245,8 +263,12 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 		return nil, "", false
 	}
 
+	now := time.Now()
 	token.RegisteredClaims = jwtutils.RegisteredClaims{
-		Expiry: jwt.NewNumericDate(time.Now().Add(DefaultTokenExpiry)),
+		// The issue time lets servers refuse shared PTY tokens issued
+		// before the share changed.
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(DefaultTokenExpiry)),
 	}
 	// Sign the token.
 	tokenStr, err := jwtutils.Sign(ctx, p.Keycache, token)
@@ -365,6 +387,40 @@ func (p *DBTokenProvider) authorizeRequest(ctx context.Context, roles *rbac.Subj
 	return false, warnings, nil
 }
 
+// authorizeSharedTerminal returns the mode the reconnecting PTY of a terminal
+// request was shared with the user in, or an empty mode if it wasn't shared
+// with them. dbCtx is used for database calls.
+func (p *DBTokenProvider) authorizeSharedTerminal(dbCtx, ctx context.Context, roles *rbac.Subject, dbReq *databaseRequest) (codersdk.WorkspaceAgentPTYShareMode, error) {
+	// Like the "authenticated" sharing level, the API key must have
+	// permission to connect to the actor's own workspaces. This enforces
+	// scopes.
+	err := p.Authorizer.Authorize(ctx, *roles, policy.ActionSSH, rbac.ResourceWorkspace.WithOwner(roles.ID))
+	if err != nil {
+		return "", nil
+	}
+
+	userID, err := uuid.Parse(roles.ID)
+	if err != nil {
+		return "", xerrors.Errorf("parse user ID: %w", err)
+	}
+	reconnectID, err := uuid.Parse(dbReq.ReconnectID)
+	if err != nil {
+		return "", xerrors.Errorf("parse reconnect ID: %w", err)
+	}
+	share, err := p.Database.GetWorkspaceAgentPTYShare(dbCtx, database.GetWorkspaceAgentPTYShareParams{
+		AgentID:     dbReq.Agent.ID,
+		ReconnectID: reconnectID,
+		UserID:      userID,
+	})
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", xerrors.Errorf("get pty share: %w", err)
+	}
+	return codersdk.WorkspaceAgentPTYShareMode(share.Mode), nil
+}
+
 type auditRequest struct {
 	time   time.Time
 	apiKey *database.APIKey
@@ -48,17 +48,25 @@ func ResolveRequest(rw http.ResponseWriter, r *http.Request, opts ResolveRequest
 		return nil, false
 	}
 
+	sessionToken := AppConnectSessionTokenFromRequest(r, appReq.AccessMethod)
 	token, ok := opts.SignedTokenProvider.FromRequest(r)
 	if ok && token.MatchesRequest(appReq) {
-		// The request has a valid signed app token and it matches the request.
-		return token, true
+		// Terminal shares can be revoked at any time, so they are checked
+		// again whenever the user can be authenticated. Otherwise the Server
+		// refuses shared tokens issued before the share changed.
+		shared := token.Terminal != nil && token.Terminal.ShareMode != ""
+		if !shared || sessionToken == "" {
+			// The request has a valid signed app token and it matches the
+			// request.
+			return token, true
+		}
 	}
 
 	issueReq := IssueTokenRequest{
 		AppRequest:     appReq,
 		PathAppBaseURL: opts.PathAppBaseURL.String(),
 		AppHostname:    opts.AppHostname,
-		SessionToken:   AppConnectSessionTokenFromRequest(r, appReq.AccessMethod),
+		SessionToken:   sessionToken,
 		AppPath:        opts.AppPath,
 		AppQuery:       opts.AppQuery,
 	}
@@ -118,6 +118,17 @@ type Server struct {
 
 	websocketWaitMutex sync.Mutex
 	websocketWaitGroup sync.WaitGroup
+
+	sharedPTYsMutex sync.Mutex
+	sharedPTYs      map[sharedPTYKey]map[*sharedPTYSession]struct{}
+	// ptyShareChanges holds the recent changes to PTY shares that invalidate
+	// tokens issued before them.
+	ptyShareChanges map[sharedPTYKey]ptyShareChange
+	// ptySharesResetAt invalidates all shared PTY tokens issued before it.
+	ptySharesResetAt time.Time
+	// ptyShareEventsUnavailable is set while changes to PTY shares may be
+	// missed, and refuses all shared PTY sessions.
+	ptyShareEventsUnavailable bool
 }
 
 // Close waits for all reconnecting-pty WebSocket connections to drain before
@@ -149,6 +160,7 @@ func (s *Server) Attach(r chi.Router) {
 	r.Route("/@{user}/{workspace_and_agent}/apps/{workspaceapp}", servePathApps)
 
 	r.Get("/api/v2/workspaceagents/{workspaceagent}/pty", s.workspaceAgentPTY)
+	r.Get("/api/v2/workspaceagents/{workspaceagent}/pty-presence", s.workspaceAgentPTYPresence)
 }
 
 // handleAPIKeySmuggling is called by the proxy path and subdomain handlers to
@@ -628,6 +640,23 @@ func (s *Server) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 	s.websocketWaitMutex.Unlock()
 	defer s.websocketWaitGroup.Done()
 
+	values := r.URL.Query()
+	parser := httpapi.NewQueryParamParser()
+	reconnect := parser.RequiredNotEmpty("reconnect").UUID(values, uuid.New(), "reconnect")
+	height := parser.UInt(values, 80, "height")
+	width := parser.UInt(values, 80, "width")
+	command := parser.String(values, "", "command")
+	container := parser.String(values, "", "container")
+	containerUser := parser.String(values, "", "container_user")
+	backendType := parser.String(values, "", "backend_type")
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid query parameters.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+
 	appToken, ok := ResolveRequest(rw, r, ResolveRequestOptions{
 		Logger:              s.Logger,
 		CookieCfg:           s.Cookies,
@@ -639,6 +668,7 @@ func (s *Server) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 			AccessMethod:  AccessMethodTerminal,
 			BasePath:      r.URL.Path,
 			AgentNameOrID: chi.URLParam(r, "workspaceagent"),
+			ReconnectID:   reconnect.String(),
 		},
 		AppPath:  "",
 		AppQuery: "",
@@ -649,20 +679,37 @@ func (s *Server) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 	log := s.Logger.With(slog.F("agent_id", appToken.AgentID))
 	log.Debug(ctx, "resolved PTY request")
 
-	values := r.URL.Query()
-	parser := httpapi.NewQueryParamParser()
-	reconnect := parser.RequiredNotEmpty("reconnect").UUID(values, uuid.New(), "reconnect")
-	height := parser.UInt(values, 80, "height")
-	width := parser.UInt(values, 80, "width")
-	container := parser.String(values, "", "container")
-	containerUser := parser.String(values, "", "container_user")
-	backendType := parser.String(values, "", "backend_type")
-	if len(parser.Errors) > 0 {
-		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-			Message:     "Invalid query parameters.",
-			Validations: parser.Errors,
-		})
-		return
+	var (
+		username string
+		shared   bool
+		readOnly bool
+	)
+	if appToken.Terminal != nil {
+		username = appToken.Terminal.Username
+		shared = appToken.Terminal.ShareMode != ""
+		readOnly = appToken.Terminal.ShareMode == codersdk.WorkspaceAgentPTYShareModeReadOnly
+	}
+	if shared {
+		// Users the PTY was shared with attach to it as the owner started
+		// it, and can't choose what it runs.
+		command, container, containerUser, backendType = "", "", "", ""
+		log = log.With(slog.F("shared_with", username), slog.F("read_only", readOnly))
+
+		// Revoking or downgrading the share must cut off the session, not
+		// just the next connection.
+		untrack, ok := s.trackSharedPTY(sharedPTYKey{
+			AgentID:     appToken.AgentID,
+			ReconnectID: reconnect,
+			UserID:      appToken.Terminal.UserID,
+		}, appToken, cancel)
+		if !ok {
+			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+				Message: "The terminal share changed after the token was issued, or can't be checked right now.",
+				Detail:  "Request a new token and try again.",
+			})
+			return
+		}
+		defer untrack()
 	}
 
 	conn, err := websocket.Accept(rw, r, &websocket.AcceptOptions{
@@ -694,11 +741,24 @@ func (s *Server) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 	}
 	defer release()
 	log.Debug(ctx, "dialed workspace agent")
+	if shared {
+		// Agents that don't report presence predate shared terminals, and
+		// would start a new PTY as the owner instead of refusing to.
+		_, err = agentConn.ReconnectingPTYPresence(ctx, reconnect)
+		if err != nil {
+			log.Debug(ctx, "get reconnecting pty presence", slog.Error(err))
+			_ = conn.Close(websocket.StatusInternalError, httpapi.WebsocketCloseSprintf("the workspace agent does not support shared terminals: %s", err))
+			return
+		}
+	}
 	// #nosec G115 - Safe conversion for terminal height/width which are expected to be within uint16 range (0-65535)
-	ptNetConn, err := agentConn.ReconnectingPTY(ctx, reconnect, uint16(height), uint16(width), r.URL.Query().Get("command"), func(arp *workspacesdk.AgentReconnectingPTYInit) {
+	ptNetConn, err := agentConn.ReconnectingPTY(ctx, reconnect, uint16(height), uint16(width), command, func(arp *workspacesdk.AgentReconnectingPTYInit) {
 		arp.Container = container
 		arp.ContainerUser = containerUser
 		arp.BackendType = backendType
+		arp.Username = username
+		arp.ReadOnly = readOnly
+		arp.AttachOnly = shared
 	})
 	if err != nil {
 		log.Debug(ctx, "dial reconnecting pty server in workspace agent", slog.Error(err))
@@ -715,10 +775,251 @@ func (s *Server) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 		s.collectStats(report)
 	}()
 
+	if readOnly {
+		// Input from read-only users, including resizes, is dropped before
+		// it reaches the agent.
+		ptNetConn = readOnlyConn{Conn: ptNetConn}
+	}
 	agentssh.Bicopy(ctx, wsNetConn, ptNetConn)
 	log.Debug(ctx, "pty Bicopy finished")
 }
 
+// workspaceAgentPTYPresence returns the connections attached to a reconnecting
+// PTY. Like the PTY itself, it's available to the users the PTY is shared
+// with.
+//
+// @Summary Get workspace agent PTY presence
+// @ID get-workspace-agent-pty-presence
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Param reconnect query string true "Reconnecting PTY ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceAgentPTYPresence
+// @Router /workspaceagents/{workspaceagent}/pty-presence [get]
+func (s *Server) workspaceAgentPTYPresence(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	parser := httpapi.NewQueryParamParser()
+	reconnect := parser.RequiredNotEmpty("reconnect").UUID(r.URL.Query(), uuid.New(), "reconnect")
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid query parameters.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+
+	appToken, ok := ResolveRequest(rw, r, ResolveRequestOptions{
+		Logger:              s.Logger,
+		CookieCfg:           s.Cookies,
+		SignedTokenProvider: s.SignedTokenProvider,
+		DashboardURL:        s.DashboardURL,
+		PathAppBaseURL:      s.AccessURL,
+		AppHostname:         s.Hostname,
+		AppRequest: Request{
+			AccessMethod:  AccessMethodTerminal,
+			BasePath:      r.URL.Path,
+			AgentNameOrID: chi.URLParam(r, "workspaceagent"),
+			ReconnectID:   reconnect.String(),
+		},
+		AppPath:  "",
+		AppQuery: "",
+	})
+	if !ok {
+		return
+	}
+	if appToken.Terminal != nil && appToken.Terminal.ShareMode != "" && !s.sharedPTYTokenValid(sharedPTYKey{
+		AgentID:     appToken.AgentID,
+		ReconnectID: reconnect,
+		UserID:      appToken.Terminal.UserID,
+	}, appToken) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "The terminal share changed after the token was issued, or can't be checked right now.",
+			Detail:  "Request a new token and try again.",
+		})
+		return
+	}
+
+	// If the agent is unreachable, the request will hang. Assume that if we
+	// don't get a response after 30s that the agent is unreachable.
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	agentConn, release, err := s.AgentProvider.AgentConn(ctx, appToken.AgentID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error dialing workspace agent.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer release()
+
+	presence, err := agentConn.ReconnectingPTYPresence(ctx, reconnect)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching reconnecting PTY presence.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, presence)
+}
+
+// PTYShareEventChannel is published to when a PTY share changes, so every
+// coderd replica and workspace proxy can close the sessions the share no
+// longer allows.
+const PTYShareEventChannel = "workspace_agent_pty_share"
+
+// PTYShareEvent describes a change to a PTY share.
+type PTYShareEvent struct {
+	AgentID     uuid.UUID `json:"agent_id"`
+	ReconnectID uuid.UUID `json:"reconnect_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	// Mode is empty if the share was deleted.
+	Mode codersdk.WorkspaceAgentPTYShareMode `json:"mode,omitempty"`
+}
+
+// sharedPTYKey identifies the share a shared PTY session was opened with.
+type sharedPTYKey struct {
+	AgentID     uuid.UUID
+	ReconnectID uuid.UUID
+	UserID      uuid.UUID
+}
+
+type sharedPTYSession struct {
+	readOnly bool
+	cancel   context.CancelFunc
+}
+
+type ptyShareChange struct {
+	at   time.Time
+	mode codersdk.WorkspaceAgentPTYShareMode
+}
+
+// trackSharedPTY registers a session opened through a PTY share, so it can be
+// closed if the share is revoked. The returned function deregisters it. It
+// returns false if the share changed after the token was issued, since the
+// token may grant more than the share now does.
+func (s *Server) trackSharedPTY(key sharedPTYKey, token *SignedToken, cancel context.CancelFunc) (func(), bool) {
+	readOnly := token.Terminal.ShareMode == codersdk.WorkspaceAgentPTYShareModeReadOnly
+	session := &sharedPTYSession{readOnly: readOnly, cancel: cancel}
+
+	s.sharedPTYsMutex.Lock()
+	defer s.sharedPTYsMutex.Unlock()
+	if !s.sharedPTYTokenValidLocked(key, token) {
+		return nil, false
+	}
+	if s.sharedPTYs == nil {
+		s.sharedPTYs = make(map[sharedPTYKey]map[*sharedPTYSession]struct{})
+	}
+	sessions, ok := s.sharedPTYs[key]
+	if !ok {
+		sessions = make(map[*sharedPTYSession]struct{})
+		s.sharedPTYs[key] = sessions
+	}
+	sessions[session] = struct{}{}
+
+	return func() {
+		s.sharedPTYsMutex.Lock()
+		defer s.sharedPTYsMutex.Unlock()
+		delete(sessions, session)
+		if len(sessions) == 0 {
+			delete(s.sharedPTYs, key)
+		}
+	}, true
+}
+
+// sharedPTYTokenValid returns false if the PTY share changed after the token
+// was issued, or changes can't be received.
+func (s *Server) sharedPTYTokenValid(key sharedPTYKey, token *SignedToken) bool {
+	s.sharedPTYsMutex.Lock()
+	defer s.sharedPTYsMutex.Unlock()
+	return s.sharedPTYTokenValidLocked(key, token)
+}
+
+func (s *Server) sharedPTYTokenValidLocked(key sharedPTYKey, token *SignedToken) bool {
+	if s.ptyShareEventsUnavailable || token.issuedBefore(s.ptySharesResetAt) {
+		return false
+	}
+	change, ok := s.ptyShareChanges[key]
+	if !ok || !token.issuedBefore(change.at) {
+		return true
+	}
+	// Downgrading a share to read-only doesn't affect read-only tokens.
+	return change.mode == codersdk.WorkspaceAgentPTYShareModeReadOnly &&
+		token.Terminal.ShareMode == codersdk.WorkspaceAgentPTYShareModeReadOnly
+}
+
+// UpdatePTYShare closes the sessions a user has open through a PTY share that
+// the share no longer allows, and refuses the tokens issued before the change.
+// An empty mode means the share was deleted, and closes all of them. Read-only
+// shares close the sessions that can write.
+func (s *Server) UpdatePTYShare(event PTYShareEvent) {
+	key := sharedPTYKey{
+		AgentID:     event.AgentID,
+		ReconnectID: event.ReconnectID,
+		UserID:      event.UserID,
+	}
+	now := time.Now()
+
+	s.sharedPTYsMutex.Lock()
+	defer s.sharedPTYsMutex.Unlock()
+	if s.ptyShareChanges == nil {
+		s.ptyShareChanges = make(map[sharedPTYKey]ptyShareChange)
+	}
+	// Tokens issued before older changes have expired.
+	for k, change := range s.ptyShareChanges {
+		if now.Sub(change.at) > 2*DefaultTokenExpiry {
+			delete(s.ptyShareChanges, k)
+		}
+	}
+	if event.Mode == codersdk.WorkspaceAgentPTYShareModeReadWrite {
+		// Read-write shares allow whatever older tokens grant.
+		delete(s.ptyShareChanges, key)
+	} else {
+		s.ptyShareChanges[key] = ptyShareChange{at: now, mode: event.Mode}
+	}
+	for session := range s.sharedPTYs[key] {
+		switch {
+		case event.Mode == codersdk.WorkspaceAgentPTYShareModeReadWrite:
+		case event.Mode == codersdk.WorkspaceAgentPTYShareModeReadOnly && session.readOnly:
+		default:
+			session.cancel()
+		}
+	}
+}
+
+// SetPTYShareEventsAvailable must be called by servers that don't receive
+// PTY share events directly, such as workspace proxies. While they are
+// unavailable, all shared PTY sessions are closed and refused. Once they are
+// available again, the tokens issued in the meantime are refused, since
+// changes to them may have been missed.
+func (s *Server) SetPTYShareEventsAvailable(available bool) {
+	s.sharedPTYsMutex.Lock()
+	defer s.sharedPTYsMutex.Unlock()
+	s.ptyShareEventsUnavailable = !available
+	if available {
+		s.ptySharesResetAt = time.Now()
+		return
+	}
+	for _, sessions := range s.sharedPTYs {
+		for session := range sessions {
+			session.cancel()
+		}
+	}
+}
+
+// readOnlyConn discards writes to the underlying conn.
+type readOnlyConn struct {
+	net.Conn
+}
+
+func (c readOnlyConn) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
 func (s *Server) collectStats(stats StatsReport) {
 	if s.StatsCollector != nil {
 		s.StatsCollector.Collect(stats)
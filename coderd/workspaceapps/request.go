@@ -92,8 +92,8 @@ type Request struct {
 	Prefix string `json:"app_prefix"`
 
 	// For the following fields, if the AccessMethod is AccessMethodTerminal,
-	// then only AgentNameOrID may be set and it must be a UUID. The other
-	// fields must be left blank.
+	// then only AgentNameOrID and ReconnectID may be set and they must be
+	// UUIDs. The other fields must be left blank.
 	UsernameOrID string `json:"username_or_id"`
 	// WorkspaceAndAgent xor WorkspaceNameOrID are required.
 	WorkspaceAndAgent string `json:"-"` // "workspace" or "workspace.agent"
@@ -101,6 +101,10 @@ type Request struct {
 	// AgentNameOrID is not required if the workspace has only one agent.
 	AgentNameOrID string `json:"agent_name_or_id"`
 	AppSlugOrPort string `json:"app_slug_or_port"`
+	// ReconnectID is the ID of the reconnecting PTY for terminal requests. It
+	// is optional for the workspace owner, but is required to access a PTY
+	// that was shared with the user.
+	ReconnectID string `json:"reconnect_id,omitempty"`
 }
 
 // Normalize replaces WorkspaceAndAgent with WorkspaceNameOrID and
@@ -151,9 +155,17 @@ func (r Request) Check() error {
 		if _, err := uuid.Parse(r.AgentNameOrID); err != nil {
 			return xerrors.Errorf("invalid agent name or ID %q, must be a UUID: %w", r.AgentNameOrID, err)
 		}
+		if r.ReconnectID != "" {
+			if _, err := uuid.Parse(r.ReconnectID); err != nil {
+				return xerrors.Errorf("invalid reconnect ID %q, must be a UUID: %w", r.ReconnectID, err)
+			}
+		}
 
 		return nil
 	}
+	if r.ReconnectID != "" {
+		return xerrors.New("reconnect ID is only valid for terminal requests")
+	}
 
 	if r.UsernameOrID == "" {
 		return xerrors.New("username or ID is required")
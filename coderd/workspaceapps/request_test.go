@@ -269,6 +269,37 @@ func Test_RequestValidate(t *testing.T) {
 			},
 			errContains: `invalid agent name or ID "baz", must be a UUID`,
 		},
+		{
+			name: "Terminal/ReconnectID/OK",
+			req: workspaceapps.Request{
+				AccessMethod:  workspaceapps.AccessMethodTerminal,
+				BasePath:      "/",
+				AgentNameOrID: uuid.New().String(),
+				ReconnectID:   uuid.New().String(),
+			},
+		},
+		{
+			name: "Terminal/ReconnectID/NotUUID",
+			req: workspaceapps.Request{
+				AccessMethod:  workspaceapps.AccessMethodTerminal,
+				BasePath:      "/",
+				AgentNameOrID: uuid.New().String(),
+				ReconnectID:   "baz",
+			},
+			errContains: `invalid reconnect ID "baz", must be a UUID`,
+		},
+		{
+			name: "ReconnectID/NotTerminal",
+			req: workspaceapps.Request{
+				AccessMethod:      workspaceapps.AccessMethodPath,
+				BasePath:          "/app",
+				UsernameOrID:      "foo",
+				WorkspaceNameOrID: "bar",
+				AppSlugOrPort:     "baz",
+				ReconnectID:       uuid.New().String(),
+			},
+			errContains: "reconnect ID is only valid for terminal requests",
+		},
 	}
 
 	for _, c := range cases {
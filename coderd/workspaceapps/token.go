@@ -26,6 +26,28 @@ type SignedToken struct {
 	WorkspaceID uuid.UUID `json:"workspace_id"`
 	AgentID     uuid.UUID `json:"agent_id"`
 	AppURL      string    `json:"app_url"`
+	// Terminal is set for terminal requests by authenticated users.
+	Terminal *TerminalClaims `json:"terminal,omitempty"`
+}
+
+// TerminalClaims describes the user a terminal token was issued to.
+type TerminalClaims struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	// ShareMode is set when the user may not access the workspace, but the
+	// reconnecting PTY of the request was shared with them. The token is
+	// only valid for that PTY.
+	ShareMode codersdk.WorkspaceAgentPTYShareMode `json:"share_mode,omitempty"`
+}
+
+// issuedBefore returns true if the token was, or may have been, issued before
+// t. Tokens without an issue time are assumed to be older.
+func (t SignedToken) issuedBefore(at time.Time) bool {
+	if t.IssuedAt == nil {
+		return true
+	}
+	// Issue times are truncated to seconds.
+	return !t.IssuedAt.Time().After(at.Truncate(time.Second))
 }
 
 // MatchesRequest returns true if the token matches the request. Any token that
@@ -46,7 +68,10 @@ func (t SignedToken) MatchesRequest(req Request) bool {
 		t.UsernameOrID == req.UsernameOrID &&
 		t.WorkspaceNameOrID == req.WorkspaceNameOrID &&
 		t.AgentNameOrID == req.AgentNameOrID &&
-		t.AppSlugOrPort == req.AppSlugOrPort
+		t.AppSlugOrPort == req.AppSlugOrPort &&
+		// Tokens that aren't for a specific reconnecting PTY are valid for
+		// all of them.
+		(t.ReconnectID == "" || t.ReconnectID == req.ReconnectID)
 }
 
 type EncryptedAPIKeyPayload struct {
@@ -270,6 +270,41 @@ func Test_TokenMatchesRequest(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "TerminalAnyReconnectID",
+			req: workspaceapps.Request{
+				AccessMethod:  workspaceapps.AccessMethodTerminal,
+				BasePath:      "/pty",
+				AgentNameOrID: "baz",
+				ReconnectID:   "qux",
+			},
+			token: workspaceapps.SignedToken{
+				Request: workspaceapps.Request{
+					AccessMethod:  workspaceapps.AccessMethodTerminal,
+					BasePath:      "/pty",
+					AgentNameOrID: "baz",
+				},
+			},
+			want: true,
+		},
+		{
+			name: "TerminalDifferentReconnectID",
+			req: workspaceapps.Request{
+				AccessMethod:  workspaceapps.AccessMethodTerminal,
+				BasePath:      "/pty",
+				AgentNameOrID: "baz",
+				ReconnectID:   "qux",
+			},
+			token: workspaceapps.SignedToken{
+				Request: workspaceapps.Request{
+					AccessMethod:  workspaceapps.AccessMethodTerminal,
+					BasePath:      "/pty",
+					AgentNameOrID: "baz",
+					ReconnectID:   "quux",
+				},
+			},
+			want: false,
+		},
 	}
 
 	for _, c := range cases {
@@ -364,6 +364,10 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 			builder = builder.VersionID(createBuild.TemplateVersionID)
 		}
 
+		if createBuild.Priority != "" {
+			builder = builder.Priority(database.ProvisionerJobPriority(createBuild.Priority))
+		}
+
 		if createBuild.Orphan {
 			if createBuild.Transition != codersdk.WorkspaceTransitionDelete {
 				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
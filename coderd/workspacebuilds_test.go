@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"slices"
 	"strconv"
 	"testing"
@@ -921,6 +922,61 @@ func TestWorkspaceDeleteSuspendedUser(t *testing.T) {
 	require.Equal(t, codersdk.WorkspaceStatusDeleted, build.Status)
 }
 
+func TestWorkspaceBuildExternalAuthBrokerError(t *testing.T) {
+	t.Parallel()
+
+	for _, optional := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Optional=%t", optional), func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				http.Error(w, "broker unavailable", http.StatusServiceUnavailable)
+			}))
+			t.Cleanup(srv.Close)
+			broker, err := externalauth.NewBroker(codersdk.ExternalAuthBrokerTypeHTTP, srv.URL, "", "")
+			require.NoError(t, err)
+
+			client := coderdtest.New(t, &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+				ExternalAuthConfigs: []*externalauth.Config{{
+					InstrumentedOAuth2Config: &testutil.OAuth2Config{},
+					ID:                       "deploy",
+					Type:                     "deploy",
+					Broker:                   broker,
+				}},
+			})
+			first := coderdtest.CreateFirstUser(t, client)
+			version := coderdtest.CreateTemplateVersion(t, client, first.OrganizationID, &echo.Responses{
+				Parse:          echo.ParseComplete,
+				ProvisionApply: echo.ApplyComplete,
+				ProvisionPlan: []*proto.Response{{
+					Type: &proto.Response_Plan{
+						Plan: &proto.PlanComplete{
+							ExternalAuthProviders: []*proto.ExternalAuthProviderResource{{
+								Id:       "deploy",
+								Optional: optional,
+							}},
+						},
+					},
+				}},
+			})
+			coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+			template := coderdtest.CreateTemplate(t, client, first.OrganizationID, version.ID)
+			workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+			build := coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+
+			// Builds only fail if the template can't do without the
+			// credential.
+			if optional {
+				require.Equal(t, codersdk.ProvisionerJobSucceeded, build.Job.Status)
+			} else {
+				require.Equal(t, codersdk.ProvisionerJobFailed, build.Job.Status)
+				require.Contains(t, build.Job.Error, "broker unavailable")
+			}
+		})
+	}
+}
+
 func TestWorkspaceBuildDebugMode(t *testing.T) {
 	t.Parallel()
 
@@ -1273,6 +1329,36 @@ func TestPostWorkspaceBuild(t *testing.T) {
 		require.Equal(t, workspace.LatestBuild.BuildNumber+1, build.BuildNumber)
 	})
 
+	t.Run("Priority", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+		require.Equal(t, codersdk.ProvisionerJobPriorityInteractive, workspace.LatestBuild.Job.Priority)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+			Priority:   codersdk.ProvisionerJobPriorityBulk,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.ProvisionerJobPriorityBulk, build.Job.Priority)
+
+		// Other priorities are reserved for jobs Coder queues itself.
+		_, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStart,
+			Priority:   codersdk.ProvisionerJobPriorityBackground,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
 	t.Run("WithState", func(t *testing.T) {
 		t.Parallel()
 		client, closeDaemon := coderdtest.NewWithProvisionerCloser(t, &coderdtest.Options{
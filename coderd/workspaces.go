@@ -115,6 +115,7 @@ func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
 		data.templates[0],
 		api.Options.AllowWorkspaceRenames,
 		appStatus,
+		data.drift[workspace.ID],
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -319,6 +320,7 @@ func (api *API) workspaceByOwnerAndName(rw http.ResponseWriter, r *http.Request)
 		data.templates[0],
 		api.Options.AllowWorkspaceRenames,
 		appStatus,
+		data.drift[workspace.ID],
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -762,6 +764,7 @@ func createWorkspace(
 		template,
 		api.Options.AllowWorkspaceRenames,
 		codersdk.WorkspaceAppStatus{},
+		nil,
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -1324,6 +1327,7 @@ func (api *API) putWorkspaceDormant(rw http.ResponseWriter, r *http.Request) {
 		data.templates[0],
 		api.Options.AllowWorkspaceRenames,
 		appStatus,
+		data.drift[workspace.ID],
 	)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -1887,6 +1891,7 @@ func (api *API) watchWorkspace(
 			data.templates[0],
 			api.Options.AllowWorkspaceRenames,
 			appStatus,
+			data.drift[workspace.ID],
 		)
 		if err != nil {
 			_ = sendEvent(codersdk.ServerSentEvent{
@@ -1998,6 +2003,7 @@ type workspaceData struct {
 	templates    []database.Template
 	builds       []codersdk.WorkspaceBuild
 	appStatuses  []codersdk.WorkspaceAppStatus
+	drift        map[uuid.UUID]*codersdk.WorkspaceDrift
 	allowRenames bool
 }
 
@@ -2017,6 +2023,7 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 		templates   []database.Template
 		builds      []database.WorkspaceBuild
 		appStatuses []database.WorkspaceAppStatus
+		driftChecks []database.WorkspaceDriftCheck
 		eg          errgroup.Group
 	)
 	eg.Go(func() (err error) {
@@ -2046,6 +2053,15 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 		}
 		return nil
 	})
+	eg.Go(func() (err error) {
+		// This query must be run as system restricted to be efficient.
+		// nolint:gocritic
+		driftChecks, err = api.Database.GetLatestWorkspaceDriftChecksByWorkspaceIDs(dbauthz.AsSystemRestricted(ctx), workspaceIDs)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return xerrors.Errorf("get workspace drift checks: %w", err)
+		}
+		return nil
+	})
 	err := eg.Wait()
 	if err != nil {
 		return workspaceData{}, err
@@ -2078,10 +2094,42 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 		templates:    templates,
 		appStatuses:  db2sdk.WorkspaceAppStatuses(appStatuses),
 		builds:       apiBuilds,
+		drift:        convertWorkspaceDrift(driftChecks, builds),
 		allowRenames: api.Options.AllowWorkspaceRenames,
 	}, nil
 }
 
+// convertWorkspaceDrift maps workspace IDs to their last drift check, for
+// checks run against the workspace's latest build. Drift found in an older
+// build was fixed by rebuilding, so it's not returned.
+func convertWorkspaceDrift(checks []database.WorkspaceDriftCheck, latestBuilds []database.WorkspaceBuild) map[uuid.UUID]*codersdk.WorkspaceDrift {
+	latestBuildIDs := make(map[uuid.UUID]uuid.UUID, len(latestBuilds))
+	for _, build := range latestBuilds {
+		latestBuildIDs[build.WorkspaceID] = build.ID
+	}
+	drift := make(map[uuid.UUID]*codersdk.WorkspaceDrift, len(checks))
+	for _, check := range checks {
+		if latestBuildIDs[check.WorkspaceID] != check.WorkspaceBuildID {
+			continue
+		}
+		resources := make([]codersdk.WorkspaceDriftResource, 0, len(check.Resources))
+		for _, resource := range check.Resources {
+			resources = append(resources, codersdk.WorkspaceDriftResource{
+				Address: resource.Address,
+				Type:    resource.Type,
+				Name:    resource.Name,
+				Action:  resource.Action,
+			})
+		}
+		drift[check.WorkspaceID] = &codersdk.WorkspaceDrift{
+			CheckedAt:        check.CheckedAt.Time,
+			WorkspaceBuildID: check.WorkspaceBuildID,
+			Resources:        resources,
+		}
+	}
+	return drift
+}
+
 func convertWorkspaces(requesterID uuid.UUID, workspaces []database.Workspace, data workspaceData) ([]codersdk.Workspace, error) {
 	buildByWorkspaceID := map[uuid.UUID]codersdk.WorkspaceBuild{}
 	for _, workspaceBuild := range data.builds {
@@ -2120,6 +2168,7 @@ func convertWorkspaces(requesterID uuid.UUID, workspaces []database.Workspace, d
 			template,
 			data.allowRenames,
 			appStatus,
+			data.drift[workspace.ID],
 		)
 		if err != nil {
 			return nil, xerrors.Errorf("convert workspace: %w", err)
@@ -2137,6 +2186,7 @@ func convertWorkspace(
 	template database.Template,
 	allowRenames bool,
 	latestAppStatus codersdk.WorkspaceAppStatus,
+	drift *codersdk.WorkspaceDrift,
 ) (codersdk.Workspace, error) {
 	if requesterID == uuid.Nil {
 		return codersdk.Workspace{}, xerrors.Errorf("developer error: requesterID cannot be uuid.Nil!")
@@ -2217,6 +2267,7 @@ func convertWorkspace(
 		AllowRenames:     allowRenames,
 		Favorite:         requesterFavorite,
 		NextStartAt:      nextStartAt,
+		Drift:            drift,
 	}, nil
 }
 
@@ -54,6 +54,7 @@ type Builder struct {
 	richParameterValues     []codersdk.WorkspaceBuildParameter
 	initiator               uuid.UUID
 	reason                  database.BuildReason
+	priority                database.ProvisionerJobPriority
 	templateVersionPresetID uuid.UUID
 
 	// used during build, makes function arguments less verbose
@@ -166,6 +167,14 @@ func (b Builder) Reason(r database.BuildReason) Builder {
 	return b
 }
 
+// Priority sets the priority of the provisioner job. If unset, it is derived
+// from the build reason.
+func (b Builder) Priority(p database.ProvisionerJobPriority) Builder {
+	// nolint: revive
+	b.priority = p
+	return b
+}
+
 func (b Builder) RichParameterValues(p []codersdk.WorkspaceBuildParameter) Builder {
 	// nolint: revive
 	b.richParameterValues = p
@@ -306,6 +315,17 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 	if b.reason == "" {
 		b.reason = database.BuildReasonInitiator
 	}
+	// builds nobody is waiting on don't hold up interactive ones
+	if b.priority == "" {
+		switch {
+		case b.prebuild:
+			b.priority = database.ProvisionerJobPriorityBackground
+		case b.reason != database.BuildReasonInitiator:
+			b.priority = database.ProvisionerJobPriorityAutobuild
+		default:
+			b.priority = database.ProvisionerJobPriorityInteractive
+		}
+	}
 
 	workspaceBuildID := uuid.New()
 	input, err := json.Marshal(provisionerdserver.WorkspaceProvisionJob{
@@ -347,6 +367,7 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 			Valid:      true,
 			RawMessage: traceMetadataRaw,
 		},
+		Priority: b.priority,
 	})
 	if err != nil {
 		return nil, nil, nil, BuildError{http.StatusInternalServerError, "insert provisioner job", err}
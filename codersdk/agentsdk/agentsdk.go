@@ -122,6 +122,23 @@ type Manifest struct {
 	Metadata                 []codersdk.WorkspaceAgentMetadataDescription `json:"metadata"`
 	Scripts                  []codersdk.WorkspaceAgentScript              `json:"scripts"`
 	Devcontainers            []codersdk.WorkspaceAgentDevcontainer        `json:"devcontainers"`
+	// SecretFiles are secrets of the workspace owner that are written to
+	// files before startup scripts run.
+	SecretFiles []SecretFile `json:"secret_files"`
+	// SessionRecordingEnabled is true if the template requires SSH and
+	// reconnecting PTY sessions to be recorded.
+	SessionRecordingEnabled bool `json:"session_recording_enabled"`
+	// DLPPolicy is the data loss prevention policy the agent enforces. It's
+	// the merged policy of the template and of all groups of the workspace
+	// owner.
+	DLPPolicy codersdk.DLPPolicy `json:"dlp_policy"`
+}
+
+// SecretFile is a user secret that is written to a file in the workspace.
+type SecretFile struct {
+	// Path is relative to the home directory unless it's absolute.
+	Path    string `json:"path"`
+	Content []byte `json:"content"`
 }
 
 type LogSource struct {
@@ -254,6 +271,18 @@ func (c *Client) ConnectRPC24(ctx context.Context) (
 	return proto.NewDRPCAgentClient(conn), tailnetproto.NewDRPCTailnetClient(conn), nil
 }
 
+// ConnectRPC25 returns a dRPC client to the Agent API v2.5, which is required to upload session
+// recordings and report actions blocked by the DLP policy.
+func (c *Client) ConnectRPC25(ctx context.Context) (
+	proto.DRPCAgentClient25, tailnetproto.DRPCTailnetClient25, error,
+) {
+	conn, err := c.connectRPCVersion(ctx, apiversion.New(2, 5))
+	if err != nil {
+		return nil, nil, err
+	}
+	return proto.NewDRPCAgentClient(conn), tailnetproto.NewDRPCTailnetClient(conn), nil
+}
+
 // ConnectRPC connects to the workspace agent API and tailnet API
 func (c *Client) ConnectRPC(ctx context.Context) (drpc.Conn, error) {
 	return c.connectRPCVersion(ctx, proto.CurrentVersion)
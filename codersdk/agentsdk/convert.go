@@ -53,6 +53,9 @@ func ManifestFromProto(manifest *proto.Manifest) (Manifest, error) {
 		DisableDirectConnections: manifest.DisableDirectConnections,
 		Metadata:                 MetadataDescriptionsFromProto(manifest.Metadata),
 		Devcontainers:            devcontainers,
+		SecretFiles:              SecretFilesFromProto(manifest.SecretFiles),
+		SessionRecordingEnabled:  manifest.SessionRecordingEnabled,
+		DLPPolicy:                DLPPolicyFromProto(manifest.DlpPolicy),
 	}, nil
 }
 
@@ -80,9 +83,58 @@ func ProtoFromManifest(manifest Manifest) (*proto.Manifest, error) {
 		Apps:                     apps,
 		Metadata:                 ProtoFromMetadataDescriptions(manifest.Metadata),
 		Devcontainers:            ProtoFromDevcontainers(manifest.Devcontainers),
+		SecretFiles:              ProtoFromSecretFiles(manifest.SecretFiles),
+		SessionRecordingEnabled:  manifest.SessionRecordingEnabled,
+		DlpPolicy:                ProtoFromDLPPolicy(manifest.DLPPolicy),
 	}, nil
 }
 
+func DLPPolicyFromProto(policy *proto.DLPPolicy) codersdk.DLPPolicy {
+	return codersdk.DLPPolicy{
+		BlockSFTP:                 policy.GetBlockSftp(),
+		BlockSCP:                  policy.GetBlockScp(),
+		BlockLocalPortForwarding:  policy.GetBlockLocalPortForwarding(),
+		BlockRemotePortForwarding: policy.GetBlockRemotePortForwarding(),
+		BlockX11Forwarding:        policy.GetBlockX11Forwarding(),
+		BlockClipboardCopy:        policy.GetBlockClipboardCopy(),
+		BlockClipboardPaste:       policy.GetBlockClipboardPaste(),
+	}
+}
+
+func ProtoFromDLPPolicy(policy codersdk.DLPPolicy) *proto.DLPPolicy {
+	return &proto.DLPPolicy{
+		BlockSftp:                 policy.BlockSFTP,
+		BlockScp:                  policy.BlockSCP,
+		BlockLocalPortForwarding:  policy.BlockLocalPortForwarding,
+		BlockRemotePortForwarding: policy.BlockRemotePortForwarding,
+		BlockX11Forwarding:        policy.BlockX11Forwarding,
+		BlockClipboardCopy:        policy.BlockClipboardCopy,
+		BlockClipboardPaste:       policy.BlockClipboardPaste,
+	}
+}
+
+func SecretFilesFromProto(files []*proto.SecretFile) []SecretFile {
+	ret := make([]SecretFile, len(files))
+	for i, f := range files {
+		ret[i] = SecretFile{
+			Path:    f.Path,
+			Content: f.Content,
+		}
+	}
+	return ret
+}
+
+func ProtoFromSecretFiles(files []SecretFile) []*proto.SecretFile {
+	ret := make([]*proto.SecretFile, len(files))
+	for i, f := range files {
+		ret[i] = &proto.SecretFile{
+			Path:    f.Path,
+			Content: f.Content,
+		}
+	}
+	return ret
+}
+
 func MetadataDescriptionsFromProto(descriptions []*proto.WorkspaceAgentMetadata_Description) []codersdk.WorkspaceAgentMetadataDescription {
 	ret := make([]codersdk.WorkspaceAgentMetadataDescription, len(descriptions))
 	for i, description := range descriptions {
@@ -473,3 +525,45 @@ func ProtoFromDevcontainer(dc codersdk.WorkspaceAgentDevcontainer) *proto.Worksp
 		ConfigPath:      dc.ConfigPath,
 	}
 }
+
+func DLPActionFromProto(typ proto.BlockedAction_Type) (codersdk.DLPAction, error) {
+	switch typ {
+	case proto.BlockedAction_SFTP:
+		return codersdk.DLPActionSFTP, nil
+	case proto.BlockedAction_SCP:
+		return codersdk.DLPActionSCP, nil
+	case proto.BlockedAction_LOCAL_PORT_FORWARDING:
+		return codersdk.DLPActionLocalPortForwarding, nil
+	case proto.BlockedAction_REMOTE_PORT_FORWARDING:
+		return codersdk.DLPActionRemotePortForwarding, nil
+	case proto.BlockedAction_X11_FORWARDING:
+		return codersdk.DLPActionX11Forwarding, nil
+	case proto.BlockedAction_CLIPBOARD_COPY:
+		return codersdk.DLPActionClipboardCopy, nil
+	case proto.BlockedAction_CLIPBOARD_PASTE:
+		return codersdk.DLPActionClipboardPaste, nil
+	default:
+		return "", xerrors.Errorf("unknown blocked action type %q", typ)
+	}
+}
+
+func ProtoFromDLPAction(action codersdk.DLPAction) (proto.BlockedAction_Type, error) {
+	switch action {
+	case codersdk.DLPActionSFTP:
+		return proto.BlockedAction_SFTP, nil
+	case codersdk.DLPActionSCP:
+		return proto.BlockedAction_SCP, nil
+	case codersdk.DLPActionLocalPortForwarding:
+		return proto.BlockedAction_LOCAL_PORT_FORWARDING, nil
+	case codersdk.DLPActionRemotePortForwarding:
+		return proto.BlockedAction_REMOTE_PORT_FORWARDING, nil
+	case codersdk.DLPActionX11Forwarding:
+		return proto.BlockedAction_X11_FORWARDING, nil
+	case codersdk.DLPActionClipboardCopy:
+		return proto.BlockedAction_CLIPBOARD_COPY, nil
+	case codersdk.DLPActionClipboardPaste:
+		return proto.BlockedAction_CLIPBOARD_PASTE, nil
+	default:
+		return 0, xerrors.Errorf("unknown dlp action %q", action)
+	}
+}
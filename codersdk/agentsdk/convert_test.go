@@ -137,6 +137,17 @@ func TestManifest(t *testing.T) {
 				ConfigPath:      "/home/coder/coder/.devcontainer/devcontainer.json",
 			},
 		},
+		SecretFiles: []agentsdk.SecretFile{
+			{
+				Path:    ".npmrc",
+				Content: []byte("//registry.npmjs.org/:_authToken=secret"),
+			},
+		},
+		SessionRecordingEnabled: true,
+		DLPPolicy: codersdk.DLPPolicy{
+			BlockSFTP:           true,
+			BlockClipboardPaste: true,
+		},
 	}
 	p, err := agentsdk.ProtoFromManifest(manifest)
 	require.NoError(t, err)
@@ -160,6 +171,9 @@ func TestManifest(t *testing.T) {
 	require.Equal(t, manifest.Metadata, back.Metadata)
 	require.Equal(t, manifest.Scripts, back.Scripts)
 	require.Equal(t, manifest.Devcontainers, back.Devcontainers)
+	require.Equal(t, manifest.SecretFiles, back.SecretFiles)
+	require.Equal(t, manifest.SessionRecordingEnabled, back.SessionRecordingEnabled)
+	require.Equal(t, manifest.DLPPolicy, back.DLPPolicy)
 }
 
 func TestSubsystems(t *testing.T) {
@@ -192,6 +206,17 @@ func TestProtoFromLifecycle(t *testing.T) {
 	}
 }
 
+func TestProtoFromDLPAction(t *testing.T) {
+	t.Parallel()
+	for _, action := range codersdk.DLPActions {
+		typ, err := agentsdk.ProtoFromDLPAction(action)
+		require.NoError(t, err)
+		back, err := agentsdk.DLPActionFromProto(typ)
+		require.NoError(t, err)
+		require.Equal(t, action, back)
+	}
+}
+
 func TestProtoFromMetadataResult(t *testing.T) {
 	t.Parallel()
 	now := dbtime.Now()
@@ -19,9 +19,12 @@ type APIKey struct {
 	CreatedAt       time.Time   `json:"created_at" validate:"required" format:"date-time"`
 	UpdatedAt       time.Time   `json:"updated_at" validate:"required" format:"date-time"`
 	LoginType       LoginType   `json:"login_type" validate:"required" enums:"password,github,oidc,token"`
-	Scope           APIKeyScope `json:"scope" validate:"required" enums:"all,application_connect"`
+	Scope           APIKeyScope `json:"scope" validate:"required" enums:"all,application_connect,workspace:read,workspace:start,workspace:stop,workspace:ssh,template:read,template:push"`
 	TokenName       string      `json:"token_name" validate:"required"`
 	LifetimeSeconds int64       `json:"lifetime_seconds" validate:"required"`
+	// AllowList contains the IDs of the only resources the key can access.
+	// If empty, the key can access every resource allowed by its scope.
+	AllowList []uuid.UUID `json:"allow_list" format:"uuid"`
 }
 
 // LoginType is the type of login used to create the API key.
@@ -48,12 +51,46 @@ const (
 	// APIKeyScopeApplicationConnect is a scope that allows the user
 	// to connect to applications in a workspace.
 	APIKeyScopeApplicationConnect APIKeyScope = "application_connect"
+	// APIKeyScopeWorkspaceRead is a scope that allows the user to read
+	// workspaces.
+	APIKeyScopeWorkspaceRead APIKeyScope = "workspace:read"
+	// APIKeyScopeWorkspaceStart is a scope that allows the user to read and
+	// start workspaces.
+	APIKeyScopeWorkspaceStart APIKeyScope = "workspace:start"
+	// APIKeyScopeWorkspaceStop is a scope that allows the user to read and
+	// stop workspaces.
+	APIKeyScopeWorkspaceStop APIKeyScope = "workspace:stop"
+	// APIKeyScopeWorkspaceSSH is a scope that allows the user to read and
+	// connect to workspaces over SSH.
+	APIKeyScopeWorkspaceSSH APIKeyScope = "workspace:ssh"
+	// APIKeyScopeTemplateRead is a scope that allows the user to read
+	// templates and their source.
+	APIKeyScopeTemplateRead APIKeyScope = "template:read"
+	// APIKeyScopeTemplatePush is a scope that allows the user to create
+	// templates and push new template versions.
+	APIKeyScopeTemplatePush APIKeyScope = "template:push"
 )
 
+// APIKeyScopes are all the scopes an API key can have.
+var APIKeyScopes = []APIKeyScope{
+	APIKeyScopeAll,
+	APIKeyScopeApplicationConnect,
+	APIKeyScopeWorkspaceRead,
+	APIKeyScopeWorkspaceStart,
+	APIKeyScopeWorkspaceStop,
+	APIKeyScopeWorkspaceSSH,
+	APIKeyScopeTemplateRead,
+	APIKeyScopeTemplatePush,
+}
+
 type CreateTokenRequest struct {
 	Lifetime  time.Duration `json:"lifetime"`
-	Scope     APIKeyScope   `json:"scope" enums:"all,application_connect"`
+	Scope     APIKeyScope   `json:"scope" enums:"all,application_connect,workspace:read,workspace:start,workspace:stop,workspace:ssh,template:read,template:push"`
 	TokenName string        `json:"token_name"`
+	// AllowList restricts the token to the workspaces and templates with the
+	// given IDs. The templates of allowed workspaces and the user the token
+	// belongs to are allowed implicitly.
+	AllowList []uuid.UUID `json:"allow_list,omitempty" format:"uuid"`
 }
 
 // GenerateAPIKeyResponse contains an API key for a user.
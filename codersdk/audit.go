@@ -114,6 +114,7 @@ const (
 	AuditActionDisconnect           AuditAction = "disconnect"
 	AuditActionOpen                 AuditAction = "open"
 	AuditActionClose                AuditAction = "close"
+	AuditActionBlocked              AuditAction = "blocked"
 )
 
 func (a AuditAction) Friendly() string {
@@ -144,6 +145,8 @@ func (a AuditAction) Friendly() string {
 		return "opened"
 	case AuditActionClose:
 		return "closed"
+	case AuditActionBlocked:
+		return "blocked"
 	default:
 		return "unknown"
 	}
@@ -332,6 +332,18 @@ var PostgresAuthDrivers = []string{
 	string(PostgresAuthAWSIAMRDS),
 }
 
+type PubsubBackend string
+
+const (
+	PubsubBackendPostgres PubsubBackend = "postgres"
+	PubsubBackendNATS     PubsubBackend = "nats"
+)
+
+var PubsubBackends = []string{
+	string(PubsubBackendPostgres),
+	string(PubsubBackendNATS),
+}
+
 // DeploymentValues is the central configuration values the coder server.
 type DeploymentValues struct {
 	Verbose             serpent.Bool   `json:"verbose,omitempty"`
@@ -353,6 +365,8 @@ type DeploymentValues struct {
 	EphemeralDeployment             serpent.Bool                         `json:"ephemeral_deployment,omitempty" typescript:",notnull"`
 	PostgresURL                     serpent.String                       `json:"pg_connection_url,omitempty" typescript:",notnull"`
 	PostgresAuth                    string                               `json:"pg_auth,omitempty" typescript:",notnull"`
+	PubsubBackend                   string                               `json:"pubsub_backend,omitempty" typescript:",notnull"`
+	PubsubNATSURL                   serpent.String                       `json:"pubsub_nats_url,omitempty" typescript:",notnull"`
 	OAuth2                          OAuth2Config                         `json:"oauth2,omitempty" typescript:",notnull"`
 	OIDC                            OIDCConfig                           `json:"oidc,omitempty" typescript:",notnull"`
 	Telemetry                       TelemetryConfig                      `json:"telemetry,omitempty" typescript:",notnull"`
@@ -639,16 +653,43 @@ type ExternalAuthConfig struct {
 	DisplayName string `json:"display_name" yaml:"display_name"`
 	// DisplayIcon is a URL to an icon to display in the UI.
 	DisplayIcon string `json:"display_icon" yaml:"display_icon"`
+	// BrokerType makes the provider issue credentials from a secrets
+	// backend instead of OAuth2. The client and OAuth2 URLs are unused
+	// when it's set.
+	BrokerType  ExternalAuthBrokerType `json:"broker_type,omitempty" yaml:"broker_type,omitempty"`
+	BrokerURL   string                 `json:"broker_url,omitempty" yaml:"broker_url,omitempty"`
+	BrokerToken string                 `json:"-" yaml:"broker_token,omitempty"`
+	// BrokerTokenField is the key of the access token in the secrets
+	// returned by a vault broker. It defaults to "token".
+	BrokerTokenField string `json:"broker_token_field,omitempty" yaml:"broker_token_field,omitempty"`
 }
 
+// ExternalAuthBrokerType is the kind of secrets backend an external auth
+// provider issues credentials from.
+type ExternalAuthBrokerType string
+
+const (
+	// ExternalAuthBrokerTypeVault reads credentials from a HashiCorp Vault
+	// compatible secrets engine.
+	ExternalAuthBrokerTypeVault ExternalAuthBrokerType = "vault"
+	// ExternalAuthBrokerTypeHTTP requests credentials from an HTTP endpoint,
+	// e.g. a service that exchanges them with a cloud STS.
+	ExternalAuthBrokerTypeHTTP ExternalAuthBrokerType = "http"
+)
+
 type ProvisionerConfig struct {
 	// Daemons is the number of built-in terraform provisioners.
-	Daemons             serpent.Int64       `json:"daemons" typescript:",notnull"`
-	DaemonTypes         serpent.StringArray `json:"daemon_types" typescript:",notnull"`
-	DaemonPollInterval  serpent.Duration    `json:"daemon_poll_interval" typescript:",notnull"`
-	DaemonPollJitter    serpent.Duration    `json:"daemon_poll_jitter" typescript:",notnull"`
-	ForceCancelInterval serpent.Duration    `json:"force_cancel_interval" typescript:",notnull"`
-	DaemonPSK           serpent.String      `json:"daemon_psk" typescript:",notnull"`
+	Daemons         serpent.Int64       `json:"daemons" typescript:",notnull"`
+	DaemonTypes     serpent.StringArray `json:"daemon_types" typescript:",notnull"`
+	TerraformEngine serpent.String      `json:"terraform_engine" typescript:",notnull"`
+	// TerraformCacheSize is the size in megabytes of each cache of the
+	// built-in terraform provisioners.
+	TerraformCacheSize          serpent.Int64    `json:"terraform_cache_size" typescript:",notnull"`
+	TerraformDisableModuleCache serpent.Bool     `json:"terraform_disable_module_cache" typescript:",notnull"`
+	DaemonPollInterval          serpent.Duration `json:"daemon_poll_interval" typescript:",notnull"`
+	DaemonPollJitter            serpent.Duration `json:"daemon_poll_jitter" typescript:",notnull"`
+	ForceCancelInterval         serpent.Duration `json:"force_cancel_interval" typescript:",notnull"`
+	DaemonPSK                   serpent.String   `json:"daemon_psk" typescript:",notnull"`
 }
 
 type RateLimitConfig struct {
@@ -2090,7 +2131,7 @@ func (c *DeploymentValues) Options() serpent.OptionSet {
 			Name: "Provisioner Daemon Types",
 			Description: fmt.Sprintf("The supported job types for the built-in provisioners. By default, this is only the terraform type. Supported types: %s.",
 				strings.Join([]string{
-					string(ProvisionerTypeTerraform), string(ProvisionerTypeEcho),
+					string(ProvisionerTypeTerraform), string(ProvisionerTypePulumi), string(ProvisionerTypeEcho),
 				}, ",")),
 			Flag:    "provisioner-types",
 			Env:     "CODER_PROVISIONER_TYPES",
@@ -2112,6 +2153,40 @@ func (c *DeploymentValues) Options() serpent.OptionSet {
 			Group: &deploymentGroupProvisioning,
 			YAML:  "daemonTypes",
 		},
+		{
+			Name: "Provisioner Terraform Engine",
+			Description: fmt.Sprintf("The binary the built-in terraform provisioners run. If the binary isn't on the PATH, it's downloaded. Supported engines: %s.",
+				strings.Join([]string{
+					string(TerraformEngineTerraform), string(TerraformEngineOpenTofu),
+				}, ",")),
+			Flag:    "provisioner-terraform-engine",
+			Env:     "CODER_PROVISIONER_TERRAFORM_ENGINE",
+			Default: string(TerraformEngineTerraform),
+			Value: serpent.Validate(&c.Provisioner.TerraformEngine, func(value *serpent.String) error {
+				return TerraformEngineValid(value.String())
+			}),
+			Group: &deploymentGroupProvisioning,
+			YAML:  "terraformEngine",
+		},
+		{
+			Name:        "Provisioner Terraform Cache Size",
+			Description: "The size in megabytes that the provider cache and the module cache of each built-in terraform provisioner can grow to. The least recently used providers and modules are evicted first. Set to 0 to disable the limit.",
+			Flag:        "provisioner-terraform-cache-size",
+			Env:         "CODER_PROVISIONER_TERRAFORM_CACHE_SIZE",
+			Default:     "10240",
+			Value:       &c.Provisioner.TerraformCacheSize,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "terraformCacheSize",
+		},
+		{
+			Name:        "Provisioner Terraform Disable Module Cache",
+			Description: "Disable caching the modules of templates in the built-in terraform provisioners. Cached modules are reused by builds of the same template for a day.",
+			Flag:        "provisioner-terraform-disable-module-cache",
+			Env:         "CODER_PROVISIONER_TERRAFORM_DISABLE_MODULE_CACHE",
+			Value:       &c.Provisioner.TerraformDisableModuleCache,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "terraformDisableModuleCache",
+		},
 		{
 			Name:        "Poll Interval",
 			Description: "Deprecated and ignored.",
@@ -2395,6 +2470,23 @@ func (c *DeploymentValues) Options() serpent.OptionSet {
 			Value:       serpent.EnumOf(&c.PostgresAuth, PostgresAuthDrivers...),
 			YAML:        "pgAuth",
 		},
+		{
+			Name:        "Pubsub Backend",
+			Description: "The service used to send messages between Coder replicas. PostgreSQL notifications are limited to 8000 bytes per message and a single connection per replica, so large deployments may use NATS instead.",
+			Flag:        "pubsub-backend",
+			Env:         "CODER_PUBSUB_BACKEND",
+			Default:     string(PubsubBackendPostgres),
+			Value:       serpent.EnumOf(&c.PubsubBackend, PubsubBackends...),
+			YAML:        "pubsubBackend",
+		},
+		{
+			Name:        "Pubsub NATS URL",
+			Description: "URL of the NATS server to use when the pubsub backend is nats. Separate multiple server URLs of a cluster with commas.",
+			Flag:        "pubsub-nats-url",
+			Env:         "CODER_PUBSUB_NATS_URL",
+			Annotations: serpent.Annotations{}.Mark(annotationSecretKey, "true"),
+			Value:       &c.PubsubNATSURL,
+		},
 		{
 			Name:        "Secure Auth Cookie",
 			Description: "Controls if the 'Secure' property is set on browser session cookies.",
@@ -63,6 +63,9 @@ func TestDeploymentValues_HighlyConfigurable(t *testing.T) {
 		"Postgres Connection URL": {
 			yaml: true,
 		},
+		"Pubsub NATS URL": {
+			yaml: true,
+		},
 		"SCIM API Key": {
 			yaml: true,
 		},
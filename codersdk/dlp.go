@@ -0,0 +1,86 @@
+package codersdk
+
+// DLPAction is a way of moving data into or out of a workspace that a
+// DLPPolicy can block.
+type DLPAction string
+
+const (
+	DLPActionSFTP                 DLPAction = "sftp"
+	DLPActionSCP                  DLPAction = "scp"
+	DLPActionLocalPortForwarding  DLPAction = "local_port_forwarding"
+	DLPActionRemotePortForwarding DLPAction = "remote_port_forwarding"
+	DLPActionX11Forwarding        DLPAction = "x11_forwarding"
+	DLPActionClipboardCopy        DLPAction = "clipboard_copy"
+	DLPActionClipboardPaste       DLPAction = "clipboard_paste"
+)
+
+// DLPActions is every action a DLPPolicy can block.
+var DLPActions = []DLPAction{
+	DLPActionSFTP,
+	DLPActionSCP,
+	DLPActionLocalPortForwarding,
+	DLPActionRemotePortForwarding,
+	DLPActionX11Forwarding,
+	DLPActionClipboardCopy,
+	DLPActionClipboardPaste,
+}
+
+// DLPPolicy is a data loss prevention policy. It restricts how data can be
+// moved into or out of a workspace. Templates and groups have a policy, and
+// policies only ever restrict: an action is blocked in a workspace if the
+// policy of its template or of any group its owner is a member of blocks it.
+type DLPPolicy struct {
+	// BlockSFTP blocks the SFTP subsystem. OpenSSH 9.0 and later use SFTP
+	// for scp, so this also blocks those clients.
+	BlockSFTP bool `json:"block_sftp"`
+	// BlockSCP blocks the legacy scp protocol.
+	BlockSCP bool `json:"block_scp"`
+	// BlockLocalPortForwarding blocks SSH local port forwarding (ssh -L),
+	// which exposes ports of the workspace on the client.
+	BlockLocalPortForwarding bool `json:"block_local_port_forwarding"`
+	// BlockRemotePortForwarding blocks SSH remote port forwarding (ssh -R),
+	// which exposes ports of the client in the workspace.
+	BlockRemotePortForwarding bool `json:"block_remote_port_forwarding"`
+	// BlockX11Forwarding blocks SSH X11 forwarding.
+	BlockX11Forwarding bool `json:"block_x11_forwarding"`
+	// BlockClipboardCopy removes OSC 52 sequences, which programs use to set
+	// the clipboard of the client, from the output of web terminals.
+	BlockClipboardCopy bool `json:"block_clipboard_copy"`
+	// BlockClipboardPaste drops text pasted into web terminals.
+	BlockClipboardPaste bool `json:"block_clipboard_paste"`
+}
+
+// Blocks returns whether the policy blocks the action.
+func (p DLPPolicy) Blocks(action DLPAction) bool {
+	switch action {
+	case DLPActionSFTP:
+		return p.BlockSFTP
+	case DLPActionSCP:
+		return p.BlockSCP
+	case DLPActionLocalPortForwarding:
+		return p.BlockLocalPortForwarding
+	case DLPActionRemotePortForwarding:
+		return p.BlockRemotePortForwarding
+	case DLPActionX11Forwarding:
+		return p.BlockX11Forwarding
+	case DLPActionClipboardCopy:
+		return p.BlockClipboardCopy
+	case DLPActionClipboardPaste:
+		return p.BlockClipboardPaste
+	default:
+		return false
+	}
+}
+
+// Merge returns a policy that blocks every action either policy blocks.
+func (p DLPPolicy) Merge(other DLPPolicy) DLPPolicy {
+	return DLPPolicy{
+		BlockSFTP:                 p.BlockSFTP || other.BlockSFTP,
+		BlockSCP:                  p.BlockSCP || other.BlockSCP,
+		BlockLocalPortForwarding:  p.BlockLocalPortForwarding || other.BlockLocalPortForwarding,
+		BlockRemotePortForwarding: p.BlockRemotePortForwarding || other.BlockRemotePortForwarding,
+		BlockX11Forwarding:        p.BlockX11Forwarding || other.BlockX11Forwarding,
+		BlockClipboardCopy:        p.BlockClipboardCopy || other.BlockClipboardCopy,
+		BlockClipboardPaste:       p.BlockClipboardPaste || other.BlockClipboardPaste,
+	}
+}
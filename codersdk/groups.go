@@ -41,6 +41,9 @@ type Group struct {
 	Source                  GroupSource `json:"source"`
 	OrganizationName        string      `json:"organization_name"`
 	OrganizationDisplayName string      `json:"organization_display_name"`
+	// DLPPolicy restricts how data can be moved into or out of the
+	// workspaces of group members.
+	DLPPolicy DLPPolicy `json:"dlp_policy"`
 }
 
 func (g Group) IsEveryone() bool {
@@ -154,6 +157,9 @@ type PatchGroupRequest struct {
 	DisplayName    *string  `json:"display_name" validate:"omitempty,group_display_name"`
 	AvatarURL      *string  `json:"avatar_url"`
 	QuotaAllowance *int     `json:"quota_allowance"`
+	// DLPPolicy replaces the data loss prevention policy of the group. If
+	// nil, the policy is left unchanged.
+	DLPPolicy *DLPPolicy `json:"dlp_policy,omitempty"`
 }
 
 func (c *Client) PatchGroup(ctx context.Context, group uuid.UUID, req PatchGroupRequest) (Group, error) {
@@ -28,19 +28,39 @@ type ProvisionerType string
 const (
 	ProvisionerTypeEcho      ProvisionerType = "echo"
 	ProvisionerTypeTerraform ProvisionerType = "terraform"
+	ProvisionerTypePulumi    ProvisionerType = "pulumi"
 )
 
 // ProvisionerTypeValid accepts string or ProvisionerType for easier usage.
 // Will validate the enum is in the set.
 func ProvisionerTypeValid[T ProvisionerType | string](pt T) error {
 	switch string(pt) {
-	case string(ProvisionerTypeEcho), string(ProvisionerTypeTerraform):
+	case string(ProvisionerTypeEcho), string(ProvisionerTypeTerraform), string(ProvisionerTypePulumi):
 		return nil
 	default:
 		return xerrors.Errorf("provisioner type '%s' is not supported", pt)
 	}
 }
 
+// TerraformEngine is the binary a terraform provisioner runs.
+type TerraformEngine string
+
+const (
+	TerraformEngineTerraform TerraformEngine = "terraform"
+	TerraformEngineOpenTofu  TerraformEngine = "opentofu"
+)
+
+// TerraformEngineValid accepts string or TerraformEngine for easier usage.
+// Will validate the enum is in the set.
+func TerraformEngineValid[T TerraformEngine | string](engine T) error {
+	switch string(engine) {
+	case string(TerraformEngineTerraform), string(TerraformEngineOpenTofu):
+		return nil
+	default:
+		return xerrors.Errorf("terraform engine '%s' is not supported", engine)
+	}
+}
+
 type MinimalOrganization struct {
 	ID          uuid.UUID `table:"id" json:"id" validate:"required" format:"uuid"`
 	Name        string    `table:"name,default_sort" json:"name"`
@@ -115,7 +135,7 @@ type CreateTemplateVersionRequest struct {
 	StorageMethod   ProvisionerStorageMethod `json:"storage_method" validate:"oneof=file,required" enums:"file"`
 	FileID          uuid.UUID                `json:"file_id,omitempty" validate:"required_without=ExampleID" format:"uuid"`
 	ExampleID       string                   `json:"example_id,omitempty" validate:"required_without=FileID"`
-	Provisioner     ProvisionerType          `json:"provisioner" validate:"oneof=terraform echo,required"`
+	Provisioner     ProvisionerType          `json:"provisioner" validate:"oneof=terraform pulumi echo,required"`
 	ProvisionerTags map[string]string        `json:"tags"`
 
 	UserVariableValues []VariableValue `json:"user_variable_values,omitempty"`
@@ -334,10 +354,29 @@ func (c *Client) ProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, e
 	return daemons, json.NewDecoder(res.Body).Decode(&daemons)
 }
 
+// DrainProvisionerDaemon asks a provisioner daemon to stop acquiring jobs, and
+// to exit once its current job completes.
+func (c *Client) DrainProvisionerDaemon(ctx context.Context, organizationID, daemonID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/organizations/%s/provisionerdaemons/%s/drain", organizationID.String(), daemonID.String()),
+		nil,
+	)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
 type OrganizationProvisionerDaemonsOptions struct {
 	Limit int
 	IDs   []uuid.UUID
 	Tags  map[string]string
+	Name  string
 }
 
 func (c *Client) OrganizationProvisionerDaemons(ctx context.Context, organizationID uuid.UUID, opts *OrganizationProvisionerDaemonsOptions) ([]ProvisionerDaemon, error) {
@@ -356,6 +395,9 @@ func (c *Client) OrganizationProvisionerDaemons(ctx context.Context, organizatio
 			}
 			qp.Add("tags", string(tagsRaw))
 		}
+		if opts.Name != "" {
+			qp.Add("name", opts.Name)
+		}
 	}
 
 	res, err := c.Request(ctx, http.MethodGet,
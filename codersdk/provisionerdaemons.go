@@ -60,6 +60,12 @@ type ProvisionerDaemon struct {
 	APIVersion     string            `json:"api_version" table:"api version"`
 	Provisioners   []ProvisionerType `json:"provisioners" table:"-"`
 	Tags           map[string]string `json:"tags" table:"tags"`
+	// TerraformEngine is the binary the terraform provisioner of the daemon
+	// runs. It's empty if the daemon has no terraform provisioner.
+	TerraformEngine TerraformEngine `json:"terraform_engine,omitempty" enums:"terraform,opentofu" table:"terraform engine"`
+	// DrainRequestedAt is set when the daemon has been asked to stop
+	// acquiring jobs and exit.
+	DrainRequestedAt NullTime `json:"drain_requested_at,omitempty" format:"date-time" table:"drain requested at"`
 
 	// Optional fields.
 	KeyName     *string                  `json:"key_name" table:"key name"`
@@ -152,6 +158,27 @@ const (
 	ProvisionerJobTypeTemplateVersionImport ProvisionerJobType = "template_version_import"
 	ProvisionerJobTypeWorkspaceBuild        ProvisionerJobType = "workspace_build"
 	ProvisionerJobTypeTemplateVersionDryRun ProvisionerJobType = "template_version_dry_run"
+	ProvisionerJobTypeWorkspaceDriftCheck   ProvisionerJobType = "workspace_drift_check"
+)
+
+// ProvisionerJobPriority determines the order in which provisioner jobs are
+// acquired. Jobs with a higher priority are acquired first, regardless of
+// when they were queued.
+type ProvisionerJobPriority string
+
+const (
+	// ProvisionerJobPriorityInteractive is used for jobs a user is actively
+	// waiting on, such as starting a workspace or importing a template.
+	ProvisionerJobPriorityInteractive ProvisionerJobPriority = "interactive"
+	// ProvisionerJobPriorityAutobuild is used for builds started by the
+	// lifecycle executor, such as autostart and autostop.
+	ProvisionerJobPriorityAutobuild ProvisionerJobPriority = "autobuild"
+	// ProvisionerJobPriorityBulk is used for builds that are part of a bulk
+	// operation, such as updating many workspaces at once.
+	ProvisionerJobPriorityBulk ProvisionerJobPriority = "bulk"
+	// ProvisionerJobPriorityBackground is used for maintenance jobs, such as
+	// prebuilds and drift checks.
+	ProvisionerJobPriorityBackground ProvisionerJobPriority = "background"
 )
 
 // JobErrorCode defines the error code returned by job runner.
@@ -180,6 +207,7 @@ type ProvisionerJob struct {
 	WorkerID         *uuid.UUID             `json:"worker_id,omitempty" format:"uuid" table:"worker id"`
 	FileID           uuid.UUID              `json:"file_id" format:"uuid" table:"file id"`
 	Tags             map[string]string      `json:"tags" table:"tags"`
+	Priority         ProvisionerJobPriority `json:"priority" enums:"interactive,autobuild,bulk,background" table:"priority"`
 	QueuePosition    int                    `json:"queue_position" table:"queue position"`
 	QueueSize        int                    `json:"queue_size" table:"queue size"`
 	OrganizationID   uuid.UUID              `json:"organization_id" format:"uuid" table:"organization id"`
@@ -254,6 +282,12 @@ type ServeProvisionerDaemonRequest struct {
 	PreSharedKey string `json:"pre_shared_key"`
 	// ProvisionerKey is an authentication key to use on the API instead of the normal session token from the client.
 	ProvisionerKey string `json:"provisioner_key"`
+	// FileCache indicates the daemon fetches template source archives from a
+	// workspace proxy file cache, so they are omitted from acquired jobs.
+	FileCache bool `json:"file_cache"`
+	// TerraformEngine is the binary the terraform provisioner of the daemon
+	// runs. Defaults to terraform.
+	TerraformEngine TerraformEngine `json:"terraform_engine,omitempty"`
 }
 
 // ServeProvisionerDaemon returns the gRPC service for a provisioner daemon
@@ -280,6 +314,12 @@ func (c *Client) ServeProvisionerDaemon(ctx context.Context, req ServeProvisione
 	for key, value := range req.Tags {
 		query.Add("tag", fmt.Sprintf("%s=%s", key, value))
 	}
+	if req.FileCache {
+		query.Add("file_cache", "true")
+	}
+	if req.TerraformEngine != "" {
+		query.Add("terraform_engine", string(req.TerraformEngine))
+	}
 	serverURL.RawQuery = query.Encode()
 	httpClient := &http.Client{
 		Transport: c.HTTPClient.Transport,
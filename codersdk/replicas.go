@@ -3,6 +3,7 @@ package codersdk
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -42,3 +43,71 @@ func (c *Client) Replicas(ctx context.Context) ([]Replica, error) {
 	var replicas []Replica
 	return replicas, json.NewDecoder(res.Body).Decode(&replicas)
 }
+
+// TailnetCoordinatorReport describes the tailnet coordinators of all replicas
+// in a high-availability deployment.
+type TailnetCoordinatorReport struct {
+	Coordinators []TailnetCoordinator       `json:"coordinators"`
+	Tunnels      []TailnetCoordinatorTunnel `json:"tunnels"`
+}
+
+type TailnetCoordinator struct {
+	// ID is the unique identifier of the coordinator, which is the ID of the
+	// replica it runs on.
+	ID uuid.UUID `json:"id" table:"id" format:"uuid"`
+	// Hostname is the hostname of the replica, or empty if the coordinator
+	// doesn't belong to an active replica.
+	Hostname string `json:"hostname" table:"hostname,default_sort"`
+	// HeartbeatAt is the time of the last heartbeat of the coordinator.
+	HeartbeatAt time.Time `json:"heartbeat_at" table:"heartbeat at" format:"date-time"`
+	// HeartbeatLagMS is the time in milliseconds since the last heartbeat.
+	HeartbeatLagMS int64 `json:"heartbeat_lag_ms" table:"heartbeat lag ms"`
+	// Healthy is true if the coordinator hasn't missed any heartbeats.
+	Healthy bool `json:"healthy" table:"healthy"`
+	// Peers is the number of peers connected to the coordinator.
+	Peers int `json:"peers" table:"peers"`
+	// LostPeers is the number of peers that lost their connection to the
+	// coordinator and haven't reconnected to any coordinator yet.
+	LostPeers int `json:"lost_peers" table:"lost peers"`
+	// Tunnels is the number of tunnels from peers of the coordinator.
+	Tunnels int `json:"tunnels" table:"tunnels"`
+}
+
+type TailnetCoordinatorTunnel struct {
+	CoordinatorID uuid.UUID `json:"coordinator_id" format:"uuid"`
+	SrcID         uuid.UUID `json:"src_id" format:"uuid"`
+	DstID         uuid.UUID `json:"dst_id" format:"uuid"`
+	UpdatedAt     time.Time `json:"updated_at" format:"date-time"`
+}
+
+// TailnetCoordinators fetches the state of the tailnet coordinators of all
+// replicas.
+func (c *Client) TailnetCoordinators(ctx context.Context) (TailnetCoordinatorReport, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/replicas/coordinators", nil)
+	if err != nil {
+		return TailnetCoordinatorReport{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return TailnetCoordinatorReport{}, ReadBodyAsError(res)
+	}
+
+	var report TailnetCoordinatorReport
+	return report, json.NewDecoder(res.Body).Decode(&report)
+}
+
+// DrainReplica disconnects the tailnet peers of the replica, which reconnect
+// to other replicas. The replica rejects new peers until it's restarted.
+func (c *Client) DrainReplica(ctx context.Context, replicaID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/replicas/%s/drain", replicaID), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionRecordingType string
+
+const (
+	SessionRecordingTypeSSH             SessionRecordingType = "ssh"
+	SessionRecordingTypeReconnectingPTY SessionRecordingType = "reconnecting_pty"
+)
+
+// SessionRecording is a recorded SSH or reconnecting PTY session of a
+// workspace. The recording itself is stored in the asciicast v2 format.
+type SessionRecording struct {
+	ID                     uuid.UUID            `json:"id" format:"uuid"`
+	WorkspaceID            uuid.UUID            `json:"workspace_id" format:"uuid"`
+	WorkspaceName          string               `json:"workspace_name"`
+	WorkspaceOwnerID       uuid.UUID            `json:"workspace_owner_id" format:"uuid"`
+	WorkspaceOwnerUsername string               `json:"workspace_owner_username"`
+	AgentID                uuid.UUID            `json:"agent_id" format:"uuid"`
+	Type                   SessionRecordingType `json:"type" enums:"ssh,reconnecting_pty"`
+	StartedAt              time.Time            `json:"started_at" format:"date-time"`
+	// EndedAt is nil while the session is still active.
+	EndedAt *time.Time `json:"ended_at,omitempty" format:"date-time"`
+	// Size is the size of the recording in bytes.
+	Size int64 `json:"size"`
+}
+
+type SessionRecordingsRequest struct {
+	// WorkspaceID filters the recordings by workspace if set.
+	WorkspaceID uuid.UUID `json:"workspace_id,omitempty" format:"uuid"`
+	Pagination
+}
+
+// SessionRecordings lists recorded sessions, most recent first.
+func (c *Client) SessionRecordings(ctx context.Context, req SessionRecordingsRequest) ([]SessionRecording, error) {
+	opts := []RequestOption{req.Pagination.asRequestOption()}
+	if req.WorkspaceID != uuid.Nil {
+		opts = append(opts, WithQueryParam("workspace", req.WorkspaceID.String()))
+	}
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/session-recordings", nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var recordings []SessionRecording
+	return recordings, json.NewDecoder(res.Body).Decode(&recordings)
+}
+
+// SessionRecording returns a recorded session by ID.
+func (c *Client) SessionRecording(ctx context.Context, id uuid.UUID) (SessionRecording, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/session-recordings/%s", id), nil)
+	if err != nil {
+		return SessionRecording{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return SessionRecording{}, ReadBodyAsError(res)
+	}
+	var recording SessionRecording
+	return recording, json.NewDecoder(res.Body).Decode(&recording)
+}
+
+// SessionRecordingCast returns the recording of a session in the asciicast
+// v2 format. The caller must close the returned reader.
+func (c *Client) SessionRecordingCast(ctx context.Context, id uuid.UUID) (io.ReadCloser, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/session-recordings/%s/cast", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, ReadBodyAsError(res)
+	}
+	return res.Body, nil
+}
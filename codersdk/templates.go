@@ -24,7 +24,7 @@ type Template struct {
 	OrganizationIcon        string          `json:"organization_icon"`
 	Name                    string          `json:"name"`
 	DisplayName             string          `json:"display_name"`
-	Provisioner             ProvisionerType `json:"provisioner" enums:"terraform"`
+	Provisioner             ProvisionerType `json:"provisioner" enums:"terraform,pulumi"`
 	ActiveVersionID         uuid.UUID       `json:"active_version_id" format:"uuid"`
 	// ActiveUserCount is set to -1 when loading.
 	ActiveUserCount    int                    `json:"active_user_count"`
@@ -61,6 +61,19 @@ type Template struct {
 	// template version.
 	RequireActiveVersion bool                         `json:"require_active_version"`
 	MaxPortShareLevel    WorkspaceAgentPortShareLevel `json:"max_port_share_level"`
+	// DriftDetectionIntervalMillis is how often running workspaces are
+	// checked for infrastructure that changed outside of Coder. 0 means drift
+	// detection is disabled.
+	DriftDetectionIntervalMillis int64 `json:"drift_detection_interval_ms"`
+	// SessionRecordingEnabled records SSH and reconnecting PTY sessions of
+	// workspaces created from this template.
+	SessionRecordingEnabled bool `json:"session_recording_enabled"`
+	// SessionRecordingRetentionMillis is how long session recordings are
+	// kept. 0 means recordings are kept forever.
+	SessionRecordingRetentionMillis int64 `json:"session_recording_retention_ms"`
+	// DLPPolicy restricts how data can be moved into or out of workspaces
+	// created from this template.
+	DLPPolicy DLPPolicy `json:"dlp_policy"`
 }
 
 // WeekdaysToBitmap converts a list of weekdays to a bitmap in accordance with
@@ -250,6 +263,21 @@ type UpdateTemplateMeta struct {
 	// of the template.
 	DisableEveryoneGroupAccess bool                          `json:"disable_everyone_group_access"`
 	MaxPortShareLevel          *WorkspaceAgentPortShareLevel `json:"max_port_share_level,omitempty"`
+	// DriftDetectionIntervalMillis sets how often running workspaces are
+	// checked for drift from their Terraform state. It must be 0, which
+	// disables drift detection, or at least an hour. If nil, the interval is
+	// left unchanged.
+	DriftDetectionIntervalMillis *int64 `json:"drift_detection_interval_ms,omitempty"`
+	// SessionRecordingEnabled enables recording of SSH and reconnecting PTY
+	// sessions. If nil, the setting is left unchanged.
+	SessionRecordingEnabled *bool `json:"session_recording_enabled,omitempty"`
+	// SessionRecordingRetentionMillis sets how long session recordings are
+	// kept. It must be 0, which keeps recordings forever, or at least an
+	// hour. If nil, the retention is left unchanged.
+	SessionRecordingRetentionMillis *int64 `json:"session_recording_retention_ms,omitempty"`
+	// DLPPolicy replaces the data loss prevention policy of the template. If
+	// nil, the policy is left unchanged.
+	DLPPolicy *DLPPolicy `json:"dlp_policy,omitempty"`
 }
 
 type TemplateExample struct {
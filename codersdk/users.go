@@ -57,6 +57,9 @@ type ReducedUser struct {
 
 	Status    UserStatus `json:"status" table:"status" enums:"active,suspended"`
 	LoginType LoginType  `json:"login_type"`
+	// IsServiceAccount is true for non-human users that are used by
+	// automation. Service accounts cannot log in interactively.
+	IsServiceAccount bool `json:"is_service_account"`
 	// Deprecated: this value should be retrieved from
 	// `codersdk.UserPreferenceSettings` instead.
 	ThemePreference string `json:"theme_preference,omitempty"`
@@ -146,6 +149,10 @@ type CreateUserRequestWithOrgs struct {
 	UserStatus *UserStatus `json:"user_status"`
 	// OrganizationIDs is a list of organization IDs that the user should be a member of.
 	OrganizationIDs []uuid.UUID `json:"organization_ids" validate:"" format:"uuid"`
+	// ServiceAccount creates a non-human user that cannot log in
+	// interactively, and authenticates only with scoped API tokens. Service
+	// accounts are owned by the single organization in OrganizationIDs.
+	ServiceAccount bool `json:"service_account,omitempty"`
 }
 
 // UnmarshalJSON implements the unmarshal for the legacy param "organization_id".
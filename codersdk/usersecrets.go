@@ -0,0 +1,137 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// UserSecret is a secret a user has stored to have injected into their
+// workspaces. The value of a secret is never returned by the API.
+type UserSecret struct {
+	ID          uuid.UUID `json:"id" format:"uuid"`
+	UserID      uuid.UUID `json:"user_id" format:"uuid"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	// EnvName is the environment variable the secret is injected into
+	// workspaces as. Empty if it isn't injected as an environment variable.
+	EnvName string `json:"env_name"`
+	// FilePath is the path of the file the secret is written to in
+	// workspaces. Relative paths are relative to the home directory. Empty
+	// if it isn't written to a file.
+	FilePath string `json:"file_path"`
+	// TemplateIDs scope the secret to workspaces of the given templates.
+	// Empty if it is injected into every workspace.
+	TemplateIDs []uuid.UUID `json:"template_ids" format:"uuid"`
+	CreatedAt   time.Time   `json:"created_at" format:"date-time"`
+	UpdatedAt   time.Time   `json:"updated_at" format:"date-time"`
+}
+
+type CreateUserSecretRequest struct {
+	Name        string      `json:"name" validate:"required"`
+	Description string      `json:"description,omitempty"`
+	Value       string      `json:"value" validate:"required"`
+	EnvName     string      `json:"env_name,omitempty"`
+	FilePath    string      `json:"file_path,omitempty"`
+	TemplateIDs []uuid.UUID `json:"template_ids,omitempty" format:"uuid"`
+}
+
+// UpdateUserSecretRequest updates the fields of a secret that are set.
+type UpdateUserSecretRequest struct {
+	Description *string      `json:"description,omitempty"`
+	Value       *string      `json:"value,omitempty"`
+	EnvName     *string      `json:"env_name,omitempty"`
+	FilePath    *string      `json:"file_path,omitempty"`
+	TemplateIDs *[]uuid.UUID `json:"template_ids,omitempty" format:"uuid"`
+}
+
+var userSecretEnvNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// UserSecretEnvNameValid returns whether the input string is a valid
+// environment variable name for a secret. Variables that are set by Coder
+// can't be overridden.
+func UserSecretEnvNameValid(str string) error {
+	if !userSecretEnvNameRegex.MatchString(str) {
+		return xerrors.New("must start with a letter or underscore and contain only letters, digits, and underscores")
+	}
+	if strings.HasPrefix(str, "CODER_") {
+		return xerrors.New("must not start with CODER_")
+	}
+	return nil
+}
+
+// UserSecrets returns the secrets of a user.
+func (c *Client) UserSecrets(ctx context.Context, user string) ([]UserSecret, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/secrets", user), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var secrets []UserSecret
+	return secrets, json.NewDecoder(res.Body).Decode(&secrets)
+}
+
+// UserSecretByName returns a secret of a user by its name.
+func (c *Client) UserSecretByName(ctx context.Context, user string, name string) (UserSecret, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/secrets/%s", user, name), nil)
+	if err != nil {
+		return UserSecret{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserSecret{}, ReadBodyAsError(res)
+	}
+	var secret UserSecret
+	return secret, json.NewDecoder(res.Body).Decode(&secret)
+}
+
+// CreateUserSecret stores a new secret for a user.
+func (c *Client) CreateUserSecret(ctx context.Context, user string, req CreateUserSecretRequest) (UserSecret, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/secrets", user), req)
+	if err != nil {
+		return UserSecret{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return UserSecret{}, ReadBodyAsError(res)
+	}
+	var secret UserSecret
+	return secret, json.NewDecoder(res.Body).Decode(&secret)
+}
+
+// UpdateUserSecret updates a secret of a user.
+func (c *Client) UpdateUserSecret(ctx context.Context, user string, name string, req UpdateUserSecretRequest) (UserSecret, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/users/%s/secrets/%s", user, name), req)
+	if err != nil {
+		return UserSecret{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserSecret{}, ReadBodyAsError(res)
+	}
+	var secret UserSecret
+	return secret, json.NewDecoder(res.Body).Decode(&secret)
+}
+
+// DeleteUserSecret deletes a secret of a user.
+func (c *Client) DeleteUserSecret(ctx context.Context, user string, name string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/secrets/%s", user, name), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
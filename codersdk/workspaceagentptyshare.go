@@ -0,0 +1,118 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WorkspaceAgentPTYShareModeReadOnly  WorkspaceAgentPTYShareMode = "read_only"
+	WorkspaceAgentPTYShareModeReadWrite WorkspaceAgentPTYShareMode = "read_write"
+)
+
+type (
+	WorkspaceAgentPTYShareMode          string
+	UpsertWorkspaceAgentPTYShareRequest struct {
+		// ReconnectID is the ID of the reconnecting PTY to share. It is the
+		// "reconnect" query parameter of the PTY endpoint.
+		ReconnectID uuid.UUID `json:"reconnect_id" format:"uuid"`
+		// User is the username or ID of the user to share the PTY with.
+		User string                     `json:"user"`
+		Mode WorkspaceAgentPTYShareMode `json:"mode" enums:"read_only,read_write"`
+	}
+	WorkspaceAgentPTYShares struct {
+		Shares []WorkspaceAgentPTYShare `json:"shares"`
+	}
+	WorkspaceAgentPTYShare struct {
+		WorkspaceID uuid.UUID                  `json:"workspace_id" format:"uuid"`
+		AgentID     uuid.UUID                  `json:"agent_id" format:"uuid"`
+		ReconnectID uuid.UUID                  `json:"reconnect_id" format:"uuid"`
+		UserID      uuid.UUID                  `json:"user_id" format:"uuid"`
+		Username    string                     `json:"username"`
+		Mode        WorkspaceAgentPTYShareMode `json:"mode" enums:"read_only,read_write"`
+		CreatedBy   uuid.UUID                  `json:"created_by" format:"uuid"`
+		CreatedAt   time.Time                  `json:"created_at" format:"date-time"`
+	}
+	DeleteWorkspaceAgentPTYShareRequest struct {
+		ReconnectID uuid.UUID `json:"reconnect_id" format:"uuid"`
+		UserID      uuid.UUID `json:"user_id" format:"uuid"`
+	}
+	// WorkspaceAgentPTYPresence lists the connections attached to a
+	// reconnecting PTY.
+	WorkspaceAgentPTYPresence struct {
+		Attachments []WorkspaceAgentPTYAttachment `json:"attachments"`
+	}
+	WorkspaceAgentPTYAttachment struct {
+		// Username is empty for connections that were not made through
+		// coderd, such as ones made directly over the tailnet.
+		Username   string    `json:"username"`
+		ReadOnly   bool      `json:"read_only"`
+		AttachedAt time.Time `json:"attached_at" format:"date-time"`
+	}
+)
+
+func (m WorkspaceAgentPTYShareMode) Valid() bool {
+	return m == WorkspaceAgentPTYShareModeReadOnly ||
+		m == WorkspaceAgentPTYShareModeReadWrite
+}
+
+func (c *Client) WorkspaceAgentPTYShares(ctx context.Context, agentID uuid.UUID) (WorkspaceAgentPTYShares, error) {
+	var shares WorkspaceAgentPTYShares
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/pty-shares", agentID), nil)
+	if err != nil {
+		return shares, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return shares, ReadBodyAsError(res)
+	}
+
+	return shares, json.NewDecoder(res.Body).Decode(&shares)
+}
+
+func (c *Client) UpsertWorkspaceAgentPTYShare(ctx context.Context, agentID uuid.UUID, req UpsertWorkspaceAgentPTYShareRequest) (WorkspaceAgentPTYShare, error) {
+	var share WorkspaceAgentPTYShare
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaceagents/%s/pty-shares", agentID), req)
+	if err != nil {
+		return share, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return share, ReadBodyAsError(res)
+	}
+
+	return share, json.NewDecoder(res.Body).Decode(&share)
+}
+
+func (c *Client) DeleteWorkspaceAgentPTYShare(ctx context.Context, agentID uuid.UUID, req DeleteWorkspaceAgentPTYShareRequest) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/workspaceagents/%s/pty-shares", agentID), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// WorkspaceAgentPTYPresence returns the connections attached to the
+// reconnecting PTY with the given ID.
+func (c *Client) WorkspaceAgentPTYPresence(ctx context.Context, agentID, reconnectID uuid.UUID) (WorkspaceAgentPTYPresence, error) {
+	var presence WorkspaceAgentPTYPresence
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/pty-presence?reconnect=%s", agentID, reconnectID), nil)
+	if err != nil {
+		return presence, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return presence, ReadBodyAsError(res)
+	}
+
+	return presence, json.NewDecoder(res.Body).Decode(&presence)
+}
@@ -65,12 +65,32 @@ type Workspace struct {
 	AllowRenames     bool             `json:"allow_renames"`
 	Favorite         bool             `json:"favorite"`
 	NextStartAt      *time.Time       `json:"next_start_at" format:"date-time"`
+	// Drift is the result of the last drift check against the latest build,
+	// if its template has drift detection enabled and the build was checked.
+	Drift *WorkspaceDrift `json:"drift,omitempty"`
 }
 
 func (w Workspace) FullName() string {
 	return fmt.Sprintf("%s/%s", w.OwnerName, w.Name)
 }
 
+// WorkspaceDrift lists the resources of a workspace that were changed or
+// deleted outside of Coder.
+type WorkspaceDrift struct {
+	CheckedAt        time.Time                `json:"checked_at" format:"date-time"`
+	WorkspaceBuildID uuid.UUID                `json:"workspace_build_id" format:"uuid"`
+	Resources        []WorkspaceDriftResource `json:"resources"`
+}
+
+type WorkspaceDriftResource struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	// Action is "update" if the resource was changed, or "delete" if it no
+	// longer exists.
+	Action string `json:"action" enums:"update,delete"`
+}
+
 type WorkspaceHealth struct {
 	Healthy       bool        `json:"healthy" example:"false"`      // Healthy is true if the workspace is healthy.
 	FailingAgents []uuid.UUID `json:"failing_agents" format:"uuid"` // FailingAgents lists the IDs of the agents that are failing, if any.
@@ -109,6 +129,10 @@ type CreateWorkspaceBuildRequest struct {
 	LogLevel ProvisionerLogLevel `json:"log_level,omitempty" validate:"omitempty,oneof=debug"`
 	// TemplateVersionPresetID is the ID of the template version preset to use for the build.
 	TemplateVersionPresetID uuid.UUID `json:"template_version_preset_id,omitempty" format:"uuid"`
+	// Priority lowers the priority of the build's provisioner job, so it
+	// doesn't hold up builds users are waiting on. Use "bulk" when building
+	// many workspaces at once. Defaults to "interactive".
+	Priority ProvisionerJobPriority `json:"priority,omitempty" validate:"omitempty,oneof=interactive bulk" enums:"interactive,bulk"`
 }
 
 type WorkspaceOptions struct {
@@ -102,6 +102,15 @@ type AgentReconnectingPTYInit struct {
 	ContainerUser string
 
 	BackendType string
+
+	// Username is the user the connection is made for. It's reported in
+	// the presence of the PTY.
+	Username string `json:",omitempty"`
+	// ReadOnly is set when the connection may only view the PTY.
+	ReadOnly bool `json:",omitempty"`
+	// AttachOnly prevents a new PTY from being started if none exists with
+	// the ID.
+	AttachOnly bool `json:",omitempty"`
 }
 
 // AgentReconnectingPTYInitOption is a functional option for AgentReconnectingPTYInit.
@@ -279,6 +288,24 @@ func (c *AgentConn) ListeningPorts(ctx context.Context) (codersdk.WorkspaceAgent
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+// ReconnectingPTYPresence returns the connections attached to the
+// reconnecting PTY with the given ID.
+func (c *AgentConn) ReconnectingPTYPresence(ctx context.Context, id uuid.UUID) (codersdk.WorkspaceAgentPTYPresence, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, fmt.Sprintf("/api/v0/reconnecting-pty/%s/presence", id), nil)
+	if err != nil {
+		return codersdk.WorkspaceAgentPTYPresence{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return codersdk.WorkspaceAgentPTYPresence{}, codersdk.ReadBodyAsError(res)
+	}
+
+	var resp codersdk.WorkspaceAgentPTYPresence
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // Netcheck returns a network check report from the workspace agent.
 func (c *AgentConn) Netcheck(ctx context.Context) (healthsdk.AgentNetcheckReport, error) {
 	ctx, span := tracing.StartSpan(ctx)
@@ -296,6 +323,31 @@ func (c *AgentConn) Netcheck(ctx context.Context) (healthsdk.AgentNetcheckReport
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+// AgentPathHistoryResponse is the history of the paths used by the workspace
+// agent to reach its peers.
+type AgentPathHistoryResponse struct {
+	// Events are ordered oldest first.
+	Events []tailnet.PathEvent `json:"events"`
+}
+
+// PathHistory returns the history of the paths used by the workspace agent to
+// reach its peers, including whether they were direct or relayed through DERP.
+func (c *AgentConn) PathHistory(ctx context.Context) (AgentPathHistoryResponse, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/path-history", nil)
+	if err != nil {
+		return AgentPathHistoryResponse{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return AgentPathHistoryResponse{}, codersdk.ReadBodyAsError(res)
+	}
+
+	var resp AgentPathHistoryResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // DebugMagicsock makes a request to the workspace agent's magicsock debug endpoint.
 func (c *AgentConn) DebugMagicsock(ctx context.Context) ([]byte, error) {
 	ctx, span := tracing.StartSpan(ctx)
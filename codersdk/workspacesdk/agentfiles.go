@@ -0,0 +1,236 @@
+package workspacesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/coder/websocket"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/wsjson"
+)
+
+// FileInfo describes a file in the workspace.
+type FileInfo struct {
+	Name string `json:"name"`
+	// AbsolutePath is the path of the file. Paths sent to the agent may be
+	// relative to the home directory of the user the agent runs as.
+	AbsolutePath string      `json:"absolute_path"`
+	Size         int64       `json:"size"`
+	Mode         os.FileMode `json:"mode"`
+	ModTime      time.Time   `json:"mod_time"`
+	IsDir        bool        `json:"is_dir"`
+	// SymlinkTarget is the target of the link if the file is a symbolic
+	// link. The other fields describe the link, not its target.
+	SymlinkTarget string `json:"symlink_target,omitempty"`
+}
+
+// StatFileRequest is a request to describe a file.
+type StatFileRequest struct {
+	Path string `json:"path"`
+}
+
+// ReadFileRequest is a request to read a range of a file.
+type ReadFileRequest struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+	// Limit is the maximum number of bytes to read. Zero reads to the end
+	// of the file.
+	Limit int64 `json:"limit"`
+}
+
+// WriteFileRequest is a request to write a file. The contents of the file
+// are sent as the body of the request.
+type WriteFileRequest struct {
+	Path string `json:"path"`
+	// Mode is the permissions of the file if it's created. Existing files
+	// keep their permissions. Defaults to 0644.
+	Mode os.FileMode `json:"mode"`
+	// Atomic writes the contents to a temporary file and renames it over
+	// the file, so readers never see a partially written file.
+	Atomic bool `json:"atomic"`
+}
+
+// MakeDirectoryRequest is a request to create a directory.
+type MakeDirectoryRequest struct {
+	Path string `json:"path"`
+	// Mode is the permissions of the directory. Defaults to 0755.
+	Mode os.FileMode `json:"mode"`
+	// Parents creates missing parent directories, and doesn't fail if the
+	// directory exists.
+	Parents bool `json:"parents"`
+}
+
+// DeleteFileRequest is a request to delete a file or directory.
+type DeleteFileRequest struct {
+	Path string `json:"path"`
+	// Recursive deletes directories and their contents. Without it, only
+	// empty directories can be deleted.
+	Recursive bool `json:"recursive"`
+}
+
+// MoveFileRequest is a request to move or rename a file or directory.
+type MoveFileRequest struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	// Overwrite replaces the destination if it exists.
+	Overwrite bool `json:"overwrite"`
+}
+
+// WatchFilesRequest is a request to watch a file or directory for changes.
+type WatchFilesRequest struct {
+	Path string `json:"path"`
+	// Recursive watches every directory below Path, including the ones
+	// that are created while watching.
+	Recursive bool `json:"recursive"`
+}
+
+type FileEventOp string
+
+const (
+	FileEventOpCreate FileEventOp = "create"
+	FileEventOpWrite  FileEventOp = "write"
+	FileEventOpRemove FileEventOp = "remove"
+	FileEventOpRename FileEventOp = "rename"
+	FileEventOpChmod  FileEventOp = "chmod"
+	// FileEventOpOverflow means events were dropped because they arrived
+	// faster than they could be sent. Clients should rescan the watched
+	// files.
+	FileEventOpOverflow FileEventOp = "overflow"
+)
+
+// FileEvent is a change to a watched file.
+type FileEvent struct {
+	Path string      `json:"path"`
+	Op   FileEventOp `json:"op"`
+}
+
+// StatFile describes a file in the workspace.
+func (c *AgentConn) StatFile(ctx context.Context, req StatFileRequest) (FileInfo, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	var resp FileInfo
+	return resp, c.filesJSONRequest(ctx, "/api/v0/files/stat", req, &resp)
+}
+
+// ReadFile reads a range of a file in the workspace. The caller must close
+// the returned reader.
+func (c *AgentConn) ReadFile(ctx context.Context, req ReadFileRequest) (io.ReadCloser, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	q := url.Values{}
+	q.Set("path", req.Path)
+	q.Set("offset", strconv.FormatInt(req.Offset, 10))
+	q.Set("limit", strconv.FormatInt(req.Limit, 10))
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/files/read?"+q.Encode(), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, codersdk.ReadBodyAsError(res)
+	}
+	return res.Body, nil
+}
+
+// WriteFile writes the contents of r to a file in the workspace, replacing
+// the file if it exists.
+func (c *AgentConn) WriteFile(ctx context.Context, req WriteFileRequest, r io.Reader) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	q := url.Values{}
+	q.Set("path", req.Path)
+	q.Set("mode", strconv.FormatUint(uint64(req.Mode), 10))
+	q.Set("atomic", strconv.FormatBool(req.Atomic))
+	res, err := c.apiRequest(ctx, http.MethodPut, "/api/v0/files/write?"+q.Encode(), r)
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// MakeDirectory creates a directory in the workspace.
+func (c *AgentConn) MakeDirectory(ctx context.Context, req MakeDirectoryRequest) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	return c.filesJSONRequest(ctx, "/api/v0/files/mkdir", req, nil)
+}
+
+// DeleteFile deletes a file or directory in the workspace.
+func (c *AgentConn) DeleteFile(ctx context.Context, req DeleteFileRequest) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	return c.filesJSONRequest(ctx, "/api/v0/files/delete", req, nil)
+}
+
+// MoveFile moves or renames a file or directory in the workspace.
+func (c *AgentConn) MoveFile(ctx context.Context, req MoveFileRequest) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	return c.filesJSONRequest(ctx, "/api/v0/files/move", req, nil)
+}
+
+// WatchFiles streams changes to a file or directory in the workspace. The
+// returned channel is closed when the watch ends, and closing the returned
+// closer ends the watch.
+func (c *AgentConn) WatchFiles(ctx context.Context, req WatchFilesRequest) (<-chan FileEvent, io.Closer, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	q := url.Values{}
+	q.Set("path", req.Path)
+	q.Set("recursive", strconv.FormatBool(req.Recursive))
+	host := net.JoinHostPort(c.agentAddress().String(), strconv.Itoa(AgentHTTPAPIServerPort))
+	//nolint:bodyclose // The websocket library closes the body.
+	conn, res, err := websocket.Dial(ctx, fmt.Sprintf("http://%s/api/v0/files/watch?%s", host, q.Encode()), &websocket.DialOptions{
+		HTTPClient:      c.apiClient(),
+		CompressionMode: websocket.CompressionDisabled,
+	})
+	if err != nil {
+		if res == nil {
+			return nil, nil, xerrors.Errorf("dial: %w", err)
+		}
+		return nil, nil, codersdk.ReadBodyAsError(res)
+	}
+	d := wsjson.NewDecoder[FileEvent](conn, websocket.MessageText, slog.Make())
+	return d.Chan(), d, nil
+}
+
+// filesJSONRequest posts req to a JSON endpoint of the file system API, and
+// decodes the response into resp if it isn't nil.
+func (c *AgentConn) filesJSONRequest(ctx context.Context, path string, req any, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return xerrors.Errorf("marshal request: %w", err)
+	}
+	res, err := c.apiRequest(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if resp == nil {
+		if res.StatusCode != http.StatusNoContent {
+			return codersdk.ReadBodyAsError(res)
+		}
+		return nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return json.NewDecoder(res.Body).Decode(resp)
+}
@@ -0,0 +1,152 @@
+package workspacesdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// Process is a process running in the workspace.
+type Process struct {
+	PID  int32 `json:"pid"`
+	PPID int32 `json:"ppid"`
+	// User is empty if the owner of the process can't be read.
+	User    string   `json:"user"`
+	Name    string   `json:"name"`
+	Cmdline []string `json:"cmdline"`
+	// CPUPercent is the CPU usage of the process averaged over its lifetime,
+	// like ps reports it. It can exceed 100 on machines with multiple CPUs.
+	CPUPercent    float64   `json:"cpu_percent"`
+	MemoryRSS     uint64    `json:"memory_rss"`
+	MemoryPercent float32   `json:"memory_percent"`
+	CreatedAt     time.Time `json:"created_at"`
+	// SessionID is the ID of the SSH or reconnecting PTY session that
+	// started the process, or of the detached process it belongs to. It's
+	// only known for processes owned by the user the agent runs as.
+	SessionID uuid.NullUUID `json:"session_id"`
+}
+
+// ListProcessesResponse lists the processes running in the workspace.
+type ListProcessesResponse struct {
+	Processes []Process `json:"processes"`
+}
+
+// SignalProcessRequest is a request to send a signal to a process.
+type SignalProcessRequest struct {
+	// Signal is the name of the signal, like "TERM" or "SIGKILL". Only KILL
+	// is supported on Windows.
+	Signal string `json:"signal"`
+}
+
+// StartProcessRequest is a request to start a detached process. The process
+// isn't tied to a connection or session, and runs until it exits, is
+// signaled, or the agent shuts down.
+type StartProcessRequest struct {
+	// Command is run with the shell of the user, like SSH commands are.
+	Command string `json:"command"`
+	// Directory is the working directory of the process. Defaults to the
+	// working directory of SSH sessions.
+	Directory string            `json:"directory,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// StartedProcess is a detached process started through the agent.
+type StartedProcess struct {
+	ID      uuid.UUID `json:"id"`
+	PID     int32     `json:"pid"`
+	Command string    `json:"command"`
+	// LogPath is the file the output of the process is written to. Read it
+	// with AgentConn.ReadFile.
+	LogPath   string     `json:"log_path"`
+	StartedAt time.Time  `json:"started_at"`
+	ExitedAt  *time.Time `json:"exited_at,omitempty"`
+	ExitCode  *int       `json:"exit_code,omitempty"`
+}
+
+// ListStartedProcessesResponse lists the detached processes started through
+// the agent since it started. Only the most recent exited processes are kept,
+// the logs of older ones are removed.
+type ListStartedProcessesResponse struct {
+	Processes []StartedProcess `json:"processes"`
+}
+
+// ListProcesses lists the processes running in the workspace.
+func (c *AgentConn) ListProcesses(ctx context.Context) (ListProcessesResponse, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/processes", nil)
+	if err != nil {
+		return ListProcessesResponse{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ListProcessesResponse{}, codersdk.ReadBodyAsError(res)
+	}
+	var resp ListProcessesResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// SignalProcess sends a signal to a process in the workspace.
+func (c *AgentConn) SignalProcess(ctx context.Context, pid int32, req SignalProcessRequest) error {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	body, err := json.Marshal(req)
+	if err != nil {
+		return xerrors.Errorf("marshal request: %w", err)
+	}
+	res, err := c.apiRequest(ctx, http.MethodPost, fmt.Sprintf("/api/v0/processes/%d/signal", pid), bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// StartProcess starts a detached process in the workspace.
+func (c *AgentConn) StartProcess(ctx context.Context, req StartProcessRequest) (StartedProcess, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	body, err := json.Marshal(req)
+	if err != nil {
+		return StartedProcess{}, xerrors.Errorf("marshal request: %w", err)
+	}
+	res, err := c.apiRequest(ctx, http.MethodPost, "/api/v0/processes", bytes.NewReader(body))
+	if err != nil {
+		return StartedProcess{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return StartedProcess{}, codersdk.ReadBodyAsError(res)
+	}
+	var resp StartedProcess
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ListStartedProcesses lists the detached processes started through the
+// agent, including the ones that have exited.
+func (c *AgentConn) ListStartedProcesses(ctx context.Context) (ListStartedProcessesResponse, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/processes/started", nil)
+	if err != nil {
+		return ListStartedProcessesResponse{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ListStartedProcessesResponse{}, codersdk.ReadBodyAsError(res)
+	}
+	var resp ListStartedProcessesResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
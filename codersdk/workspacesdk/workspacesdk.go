@@ -330,7 +330,7 @@ type WorkspaceAgentReconnectingPTYOpts struct {
 
 	// BackendType is the type of backend to use for the PTY. If not set, the
 	// workspace agent will attempt to determine the preferred backend type.
-	// Supported values are "screen" and "buffered".
+	// Supported values are "screen", "tmux" and "buffered".
 	BackendType string
 }
 
@@ -27,7 +27,7 @@ var AuditActionMap = map[string][]codersdk.AuditAction{
 	"Group":           {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
 	"APIKey":          {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
 	"License":         {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
-	"WorkspaceAgent":  {codersdk.AuditActionConnect, codersdk.AuditActionDisconnect},
+	"WorkspaceAgent":  {codersdk.AuditActionConnect, codersdk.AuditActionDisconnect, codersdk.AuditActionBlocked},
 	"WorkspaceApp":    {codersdk.AuditActionOpen, codersdk.AuditActionClose},
 }
 
@@ -115,6 +115,10 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"deprecated":                        ActionTrack,
 		"max_port_sharing_level":            ActionTrack,
 		"activity_bump":                     ActionTrack,
+		"drift_detection_interval":          ActionTrack,
+		"session_recording_enabled":         ActionTrack,
+		"session_recording_retention":       ActionTrack,
+		"dlp_policy":                        ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                      ActionTrack,
@@ -134,24 +138,26 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"source_example_id":       ActionIgnore, // Never changes.
 	},
 	&database.User{}: {
-		"id":                           ActionTrack,
-		"email":                        ActionTrack,
-		"username":                     ActionTrack,
-		"hashed_password":              ActionSecret, // Do not expose a users hashed password.
-		"created_at":                   ActionIgnore, // Never changes.
-		"updated_at":                   ActionIgnore, // Changes, but is implicit and not helpful in a diff.
-		"status":                       ActionTrack,
-		"rbac_roles":                   ActionTrack,
-		"login_type":                   ActionTrack,
-		"avatar_url":                   ActionIgnore,
-		"last_seen_at":                 ActionIgnore,
-		"deleted":                      ActionTrack,
-		"quiet_hours_schedule":         ActionTrack,
-		"name":                         ActionTrack,
-		"github_com_user_id":           ActionIgnore,
-		"hashed_one_time_passcode":     ActionIgnore,
-		"one_time_passcode_expires_at": ActionTrack,
-		"is_system":                    ActionTrack, // Should never change, but track it anyway.
+		"id":                              ActionTrack,
+		"email":                           ActionTrack,
+		"username":                        ActionTrack,
+		"hashed_password":                 ActionSecret, // Do not expose a users hashed password.
+		"created_at":                      ActionIgnore, // Never changes.
+		"updated_at":                      ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"status":                          ActionTrack,
+		"rbac_roles":                      ActionTrack,
+		"login_type":                      ActionTrack,
+		"avatar_url":                      ActionIgnore,
+		"last_seen_at":                    ActionIgnore,
+		"deleted":                         ActionTrack,
+		"quiet_hours_schedule":            ActionTrack,
+		"name":                            ActionTrack,
+		"github_com_user_id":              ActionIgnore,
+		"hashed_one_time_passcode":        ActionIgnore,
+		"one_time_passcode_expires_at":    ActionTrack,
+		"is_system":                       ActionTrack, // Should never change, but track it anyway.
+		"is_service_account":              ActionTrack,
+		"service_account_organization_id": ActionTrack,
 	},
 	&database.WorkspaceTable{}: {
 		"id":                 ActionTrack,
@@ -199,6 +205,7 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"quota_allowance": ActionTrack,
 		"members":         ActionTrack,
 		"source":          ActionIgnore,
+		"dlp_policy":      ActionTrack,
 	},
 	&database.APIKey{}: {
 		"id":               ActionIgnore,
@@ -213,6 +220,7 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"ip_address":       ActionIgnore,
 		"scope":            ActionIgnore,
 		"token_name":       ActionIgnore,
+		"allow_list":       ActionIgnore,
 	},
 	&database.AuditOAuthConvertState{}: {
 		"created_at":      ActionTrack,
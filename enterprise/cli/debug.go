@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+)
+
+func (r *RootCmd) debug() *serpent.Command {
+	return &serpent.Command{
+		Use:   "debug",
+		Short: "Debug the Coder deployment",
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.debugCoordinator(),
+		},
+	}
+}
+
+func (r *RootCmd) debugCoordinator() *serpent.Command {
+	return &serpent.Command{
+		Use:   "coordinator",
+		Short: "Inspect and drain the tailnet coordinators of replicas",
+		Long: "Every replica of a high-availability deployment runs a tailnet coordinator, which workspace agents " +
+			"and clients connect to in order to exchange connection details.",
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.debugCoordinatorReport(),
+			r.debugCoordinatorDrain(),
+		},
+	}
+}
+
+func (r *RootCmd) debugCoordinatorReport() *serpent.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(
+			cliui.TableFormat([]codersdk.TailnetCoordinator{}, []string{"id", "hostname", "healthy", "heartbeat lag ms", "peers", "lost peers", "tunnels"}),
+			func(data any) (any, error) {
+				report, ok := data.(codersdk.TailnetCoordinatorReport)
+				if !ok {
+					return nil, xerrors.Errorf("expected type %T, got %T", report, data)
+				}
+				return report.Coordinators, nil
+			},
+		),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "report",
+		Short: "Show the peer counts, tunnels and heartbeat lag of the coordinator of each replica",
+		Long: "The table lists the coordinator of each replica. The JSON output also includes the tunnel " +
+			"mappings between peers.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			report, err := client.TailnetCoordinators(inv.Context())
+			if err != nil {
+				return xerrors.Errorf("get tailnet coordinators: %w", err)
+			}
+
+			out, err := formatter.Format(inv.Context(), report)
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				cliui.Infof(inv.Stderr, "No tailnet coordinators found. Is high availability enabled?")
+				return nil
+			}
+
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+
+	return cmd
+}
+
+func (r *RootCmd) debugCoordinatorDrain() *serpent.Command {
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "drain <replica-id>",
+		Short: "Disconnect the peers of a replica's coordinator so they reconnect to other replicas",
+		Long: "Run this before shutting down a replica to move its workspace agents and clients to other " +
+			"replicas. The replica rejects new peers until it's restarted.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			replicaID, err := uuid.Parse(inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("parse replica ID: %w", err)
+			}
+
+			_, err = cliui.Prompt(inv, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Are you sure you want to drain replica %s?", pretty.Sprint(cliui.DefaultStyles.Keyword, replicaID.String())),
+				IsConfirm: true,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = client.DrainReplica(inv.Context(), replicaID)
+			if err != nil {
+				return xerrors.Errorf("drain replica: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Draining replica %s! Its peers are reconnecting to other replicas.\n", pretty.Sprint(cliui.DefaultStyles.Keyword, replicaID.String()))
+			return nil
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		cliui.SkipPromptOption(),
+	}
+
+	return cmd
+}
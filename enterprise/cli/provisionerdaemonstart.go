@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"time"
 
@@ -26,6 +28,8 @@ import (
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/drpc"
+	"github.com/coder/coder/v2/enterprise/wsproxy/wsproxysdk"
+	"github.com/coder/coder/v2/provisioner/pulumi"
 	"github.com/coder/coder/v2/provisioner/terraform"
 	"github.com/coder/coder/v2/provisionerd"
 	provisionerdproto "github.com/coder/coder/v2/provisionerd/proto"
@@ -48,6 +52,11 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 		preSharedKey   string
 		provisionerKey string
 		verbose        bool
+		fileCacheURL   string
+		servePulumi    bool
+		tfEngine       string
+		tfCacheSize    int64
+		tfNoModCache   bool
 
 		prometheusEnable  bool
 		prometheusAddress string
@@ -173,6 +182,28 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				return err
 			}
 
+			var (
+				metrics               *provisionerd.Metrics
+				terraformCacheMetrics *terraform.CacheMetrics
+			)
+			if prometheusEnable {
+				logger.Info(ctx, "starting Prometheus endpoint", slog.F("address", prometheusAddress))
+
+				prometheusRegistry := prometheus.NewRegistry()
+				prometheusRegistry.MustRegister(collectors.NewGoCollector())
+				prometheusRegistry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+				m := provisionerd.NewMetrics(prometheusRegistry)
+				m.Runner.NumDaemons.Set(float64(1)) // Set numDaemons to 1 as this is standalone mode.
+				metrics = &m
+				terraformCacheMetrics = terraform.NewCacheMetrics(prometheusRegistry)
+
+				closeFunc := agpl.ServeHandler(ctx, logger, promhttp.InstrumentMetricHandler(
+					prometheusRegistry, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{}),
+				), prometheusAddress, "prometheus")
+				defer closeFunc()
+			}
+
 			terraformClient, terraformServer := drpc.MemTransportPipe()
 			go func() {
 				<-ctx.Done()
@@ -190,7 +221,11 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 						Logger:        logger.Named("terraform"),
 						WorkDirectory: tempDir,
 					},
-					CachePath: cacheDir,
+					CachePath:          cacheDir,
+					CacheSizeLimit:     tfCacheSize << 20,
+					DisableModuleCache: tfNoModCache,
+					CacheMetrics:       terraformCacheMetrics,
+					Engine:             codersdk.TerraformEngine(tfEngine),
 				})
 				if err != nil && !xerrors.Is(err, context.Canceled) {
 					select {
@@ -200,39 +235,62 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				}
 			}()
 
-			var metrics *provisionerd.Metrics
-			if prometheusEnable {
-				logger.Info(ctx, "starting Prometheus endpoint", slog.F("address", prometheusAddress))
-
-				prometheusRegistry := prometheus.NewRegistry()
-				prometheusRegistry.MustRegister(collectors.NewGoCollector())
-				prometheusRegistry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-
-				m := provisionerd.NewMetrics(prometheusRegistry)
-				m.Runner.NumDaemons.Set(float64(1)) // Set numDaemons to 1 as this is standalone mode.
-				metrics = &m
-
-				closeFunc := agpl.ServeHandler(ctx, logger, promhttp.InstrumentMetricHandler(
-					prometheusRegistry, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{}),
-				), prometheusAddress, "prometheus")
-				defer closeFunc()
+			connector := provisionerd.LocalProvisioners{
+				string(database.ProvisionerTypeTerraform): proto.NewDRPCProvisionerClient(terraformClient),
+			}
+			provisioners := []codersdk.ProvisionerType{codersdk.ProvisionerTypeTerraform}
+			if servePulumi {
+				pulumiClient, pulumiServer := drpc.MemTransportPipe()
+				go func() {
+					<-ctx.Done()
+					_ = pulumiClient.Close()
+					_ = pulumiServer.Close()
+				}()
+				go func() {
+					defer cancel()
+
+					err := pulumi.Serve(ctx, &pulumi.ServeOptions{
+						ServeOptions: &provisionersdk.ServeOptions{
+							Listener:      pulumiServer,
+							Logger:        logger.Named("pulumi"),
+							WorkDirectory: tempDir,
+						},
+						CachePath: filepath.Join(cacheDir, "pulumi"),
+					})
+					if err != nil && !xerrors.Is(err, context.Canceled) {
+						select {
+						case errCh <- err:
+						default:
+						}
+					}
+				}()
+				connector[string(database.ProvisionerTypePulumi)] = proto.NewDRPCProvisionerClient(pulumiClient)
+				provisioners = append(provisioners, codersdk.ProvisionerTypePulumi)
 			}
 
-			logger.Info(ctx, "starting provisioner daemon", slog.F("tags", displayedTags), slog.F("name", name))
+			logger.Info(ctx, "starting provisioner daemon", slog.F("tags", displayedTags), slog.F("name", name), slog.F("terraform_engine", tfEngine))
 
-			connector := provisionerd.LocalProvisioners{
-				string(database.ProvisionerTypeTerraform): proto.NewDRPCProvisionerClient(terraformClient),
+			var fileFetcher provisionerd.FileFetcher
+			if fileCacheURL != "" {
+				u, err := url.Parse(fileCacheURL)
+				if err != nil {
+					return xerrors.Errorf("parse file cache url: %w", err)
+				}
+				fileFetcher = &wsproxysdk.FileCacheClient{
+					URL:        u,
+					HTTPClient: client.HTTPClient,
+				}
 			}
 			srv := provisionerd.New(func(ctx context.Context) (provisionerdproto.DRPCProvisionerDaemonClient, error) {
 				return client.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
-					Name: name,
-					Provisioners: []codersdk.ProvisionerType{
-						codersdk.ProvisionerTypeTerraform,
-					},
-					Tags:           tags,
-					PreSharedKey:   preSharedKey,
-					Organization:   orgID,
-					ProvisionerKey: provisionerKey,
+					Name:            name,
+					Provisioners:    provisioners,
+					Tags:            tags,
+					PreSharedKey:    preSharedKey,
+					Organization:    orgID,
+					ProvisionerKey:  provisionerKey,
+					FileCache:       fileFetcher != nil,
+					TerraformEngine: codersdk.TerraformEngine(tfEngine),
 				})
 			}, &provisionerd.Options{
 				Logger:              logger,
@@ -240,6 +298,7 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				Connector:           connector,
 				Metrics:             metrics,
 				ExternalProvisioner: true,
+				FileFetcher:         fileFetcher,
 			})
 
 			waitForProvisionerJobs := false
@@ -256,6 +315,10 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				_, _ = fmt.Fprintln(inv.Stdout, cliui.Bold(
 					"Interrupt caught, gracefully exiting. Use ctrl+\\ to force quit",
 				))
+			case <-srv.Drained():
+				_, _ = fmt.Fprintln(inv.Stdout, cliui.Bold(
+					"Provisioner daemon drained, exiting.",
+				))
 			case exitErr = <-errCh:
 			}
 			if exitErr != nil && !xerrors.Is(exitErr, context.Canceled) {
@@ -332,6 +395,39 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 			Value:       serpent.StringOf(&name),
 			Default:     "",
 		},
+		{
+			Flag:        "file-cache-url",
+			Env:         "CODER_PROVISIONER_DAEMON_FILE_CACHE_URL",
+			Description: "URL of a workspace proxy with a file cache to fetch template source archives from, instead of receiving them from Coder server.",
+			Value:       serpent.StringOf(&fileCacheURL),
+		},
+		{
+			Flag:        "terraform-engine",
+			Env:         "CODER_PROVISIONER_DAEMON_TERRAFORM_ENGINE",
+			Description: "The binary to run terraform jobs with. If the binary isn't on the PATH, it's downloaded to the cache directory.",
+			Value:       serpent.EnumOf(&tfEngine, string(codersdk.TerraformEngineTerraform), string(codersdk.TerraformEngineOpenTofu)),
+			Default:     string(codersdk.TerraformEngineTerraform),
+		},
+		{
+			Flag:        "terraform-cache-size",
+			Env:         "CODER_PROVISIONER_DAEMON_TERRAFORM_CACHE_SIZE",
+			Description: "The size in megabytes that the provider cache and the module cache in the cache directory can each grow to. The least recently used providers and modules are evicted first. Set to 0 to disable the limit.",
+			Value:       serpent.Int64Of(&tfCacheSize),
+			Default:     "10240",
+		},
+		{
+			Flag:        "terraform-disable-module-cache",
+			Env:         "CODER_PROVISIONER_DAEMON_TERRAFORM_DISABLE_MODULE_CACHE",
+			Description: "Disable caching the modules of templates in the cache directory. Cached modules are reused by builds of the same template for a day.",
+			Value:       serpent.BoolOf(&tfNoModCache),
+		},
+		{
+			Flag:        "pulumi",
+			Env:         "CODER_PROVISIONER_DAEMON_PULUMI",
+			Description: "Also run Pulumi jobs. Requires the pulumi binary, and the language runtimes used by templates, to be installed.",
+			Value:       serpent.BoolOf(&servePulumi),
+			Default:     "false",
+		},
 		{
 			Flag:        "verbose",
 			Env:         "CODER_PROVISIONER_DAEMON_VERBOSE",
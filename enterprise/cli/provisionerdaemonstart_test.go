@@ -105,6 +105,42 @@ func TestProvisionerDaemon_PSK(t *testing.T) {
 	})
 }
 
+func TestProvisionerDaemon_Drain(t *testing.T) {
+	t.Parallel()
+
+	client, owner := coderdenttest.New(t, &coderdenttest.Options{
+		ProvisionerDaemonPSK: "provisionersftw",
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		},
+	})
+	inv, conf := newCLI(t, "provisionerd", "start", "--psk=provisionersftw", "--name=drain-daemon")
+	err := conf.URL().Write(client.URL.String())
+	require.NoError(t, err)
+	ctx := testutil.Context(t, testutil.WaitLong)
+	pty := ptytest.New(t).Attach(inv)
+	waiter := clitest.StartWithWaiter(t, inv.WithContext(ctx))
+	pty.ExpectMatchContext(ctx, "starting provisioner daemon")
+
+	require.Eventually(t, func() bool {
+		daemons, err := client.OrganizationProvisionerDaemons(ctx, owner.OrganizationID, &codersdk.OrganizationProvisionerDaemonsOptions{
+			Name: "drain-daemon",
+		})
+		return err == nil && len(daemons) == 1
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	drainInv, drainConf := newCLI(t, "provisioner", "drain", "drain-daemon")
+	clitest.SetupConfig(t, client, drainConf)
+	drainPty := ptytest.New(t).Attach(drainInv)
+	clitest.Run(t, drainInv.WithContext(ctx))
+	drainPty.ExpectMatchContext(ctx, "has drained")
+
+	pty.ExpectMatchContext(ctx, "Provisioner daemon drained, exiting.")
+	waiter.RequireSuccess()
+}
+
 func TestProvisionerDaemon_SessionToken(t *testing.T) {
 	t.Parallel()
 	t.Run("ScopeUser", func(t *testing.T) {
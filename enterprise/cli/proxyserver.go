@@ -12,6 +12,7 @@ import (
 	"net/http/pprof"
 	"regexp"
 	rpprof "runtime/pprof"
+	"strconv"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
@@ -59,6 +60,8 @@ func (r *RootCmd) proxyServer() *serpent.Command {
 		proxySessionToken serpent.String
 		primaryAccessURL  serpent.URL
 		derpOnly          serpent.Bool
+		fileCacheDir      serpent.String
+		fileCacheMaxBytes serpent.Int64
 	)
 	opts.Add(
 		// Options only for external workspace proxies
@@ -102,6 +105,28 @@ func (r *RootCmd) proxyServer() *serpent.Command {
 			Group:       &externalProxyOptionGroup,
 			Hidden:      false,
 		},
+		serpent.Option{
+			Name: "File Cache Directory",
+			Description: "Cache template source archives in this directory and serve them to external provisioner daemons " +
+				"configured with this proxy's URL as their file cache. Disabled when empty.",
+			Flag:   "file-cache-dir",
+			Env:    "CODER_PROXY_FILE_CACHE_DIR",
+			YAML:   "fileCacheDir",
+			Value:  &fileCacheDir,
+			Group:  &externalProxyOptionGroup,
+			Hidden: false,
+		},
+		serpent.Option{
+			Name:        "File Cache Max Bytes",
+			Description: "The maximum total size of the file cache in bytes. The least recently used files are evicted first.",
+			Flag:        "file-cache-max-bytes",
+			Env:         "CODER_PROXY_FILE_CACHE_MAX_BYTES",
+			YAML:        "fileCacheMaxBytes",
+			Default:     strconv.FormatInt(wsproxy.DefaultFileCacheMaxBytes, 10),
+			Value:       &fileCacheMaxBytes,
+			Group:       &externalProxyOptionGroup,
+			Hidden:      false,
+		},
 	)
 
 	cmd := &serpent.Command{
@@ -272,6 +297,8 @@ func (r *RootCmd) proxyServer() *serpent.Command {
 				DERPOnly:               derpOnly.Value(),
 				BlockDirect:            cfg.DERP.Config.BlockDirect.Value(),
 				DERPServerRelayAddress: cfg.DERP.Server.RelayURL.String(),
+				FileCacheDir:           fileCacheDir.Value(),
+				FileCacheMaxBytes:      fileCacheMaxBytes.Value(),
 			}
 			if httpServers.TLSConfig != nil {
 				options.TLSCertificates = httpServers.TLSConfig.Certificates
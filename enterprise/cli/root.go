@@ -18,6 +18,7 @@ func (r *RootCmd) enterpriseOnly() []*serpent.Command {
 		r.groups(),
 		r.provisionerDaemons(),
 		r.provisionerd(),
+		r.debug(),
 	}
 }
 
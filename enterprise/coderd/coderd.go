@@ -212,6 +212,8 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 		r.Route("/replicas", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
 			r.Get("/", api.replicas)
+			r.Get("/coordinators", api.replicaCoordinators)
+			r.Post("/{replica}/drain", api.postReplicaDrain)
 		})
 		r.Route("/licenses", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
@@ -248,6 +250,9 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Post("/register", api.workspaceProxyRegister)
 				r.Post("/deregister", api.workspaceProxyDeregister)
 				r.Get("/crypto-keys", api.workspaceProxyCryptoKeys)
+				r.Get("/files/{fileID}", api.workspaceProxyFile)
+				r.Head("/files/{fileID}", api.workspaceProxyFileHash)
+				r.Get("/pty-share-events", api.workspaceProxyPTYShareEvents)
 			})
 			r.Route("/{workspaceproxy}", func(r chi.Router) {
 				r.Use(
@@ -774,7 +779,7 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 				api.Logger.Warn(ctx, "high availability is enabled, but cannot be configured due to the database being set to in-memory")
 			}
 			if enabled && !api.DeploymentValues.InMemoryDatabase.Value() {
-				haCoordinator, err := tailnet.NewPGCoord(api.ctx, api.Logger, api.Pubsub, api.Database)
+				haCoordinator, err := tailnet.NewPGCoordWithID(api.ctx, api.Logger, api.Pubsub, api.Database, api.replicaManager.ID())
 				if err != nil {
 					api.Logger.Error(ctx, "unable to set up high availability coordinator", slog.Error(err))
 					// If we try to setup the HA coordinator and it fails, nothing
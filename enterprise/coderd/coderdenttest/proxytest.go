@@ -49,6 +49,10 @@ type ProxyOptions struct {
 
 	// FlushStats is optional
 	FlushStats chan chan<- struct{}
+
+	// FileCacheDir is optional. If set, the proxy caches template source
+	// archives in this directory.
+	FileCacheDir string
 }
 
 type WorkspaceProxy struct {
@@ -168,6 +172,7 @@ func NewWorkspaceProxyReplica(t *testing.T, coderdAPI *coderd.API, owner *coders
 		ReplicaErrCallback:     options.ReplicaPingCallback,
 		StatsCollectorOptions:  statsCollectorOptions,
 		BlockDirect:            options.BlockDirect,
+		FileCacheDir:           options.FileCacheDir,
 	})
 	require.NoError(t, err)
 	t.Cleanup(func() {
@@ -89,6 +89,53 @@ func TestCheckInactiveUsers(t *testing.T) {
 	require.ElementsMatch(t, allUsers, expectedUsers)
 }
 
+func TestCheckInactiveServiceAccounts(t *testing.T) {
+	t.Parallel()
+
+	interval := time.Millisecond
+	dormancyPeriod := 90 * 24 * time.Hour
+
+	logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+	db := dbmem.New()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	// Service accounts are used by automation, which might only run
+	// occasionally, so they must never go dormant.
+	serviceAccount, err := db.InsertUser(ctx, database.InsertUserParams{
+		ID:                           uuid.New(),
+		LoginType:                    database.LoginTypeNone,
+		Username:                     uuid.NewString()[:8],
+		Email:                        "service-account@coder.com",
+		IsServiceAccount:             true,
+		ServiceAccountOrganizationID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = db.UpdateUserStatus(ctx, database.UpdateUserStatusParams{ID: serviceAccount.ID, Status: database.UserStatusActive})
+	require.NoError(t, err)
+	_, err = db.UpdateUserLastSeenAt(ctx, database.UpdateUserLastSeenAtParams{ID: serviceAccount.ID, LastSeenAt: time.Now().Add(-2 * dormancyPeriod)})
+	require.NoError(t, err)
+	inactiveUser := setupUser(ctx, t, db, "dormant-user@coder.com", database.UserStatusActive, time.Now().Add(-2*dormancyPeriod))
+
+	mAudit := audit.NewMock()
+	mClock := quartz.NewMock(t)
+	closeFunc := dormancy.CheckInactiveUsersWithOptions(ctx, logger, mClock, db, mAudit, interval, dormancyPeriod)
+	t.Cleanup(closeFunc)
+
+	dur, w := mClock.AdvanceNext()
+	require.Equal(t, interval, dur)
+	w.MustWait(ctx)
+
+	got, err := db.GetUserByID(ctx, serviceAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.UserStatusActive, got.Status)
+	got, err = db.GetUserByID(ctx, inactiveUser.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.UserStatusDormant, got.Status)
+	require.Len(t, mAudit.AuditLogs(), 1)
+}
+
 func setupUser(ctx context.Context, t *testing.T, db database.Store, email string, status database.UserStatus, lastSeenAt time.Time) database.User {
 	t.Helper()
 
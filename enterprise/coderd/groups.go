@@ -213,6 +213,7 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 			Name:           group.Name,
 			DisplayName:    group.DisplayName,
 			QuotaAllowance: group.QuotaAllowance,
+			DLPPolicy:      group.DLPPolicy,
 		}
 
 		// TODO: Do we care about validating this?
@@ -229,6 +230,9 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 		if req.DisplayName != nil {
 			updateGroupParams.DisplayName = *req.DisplayName
 		}
+		if req.DLPPolicy != nil {
+			updateGroupParams.DLPPolicy = database.DLPPolicy(*req.DLPPolicy)
+		}
 
 		group, err = tx.UpdateGroupByID(ctx, updateGroupParams)
 		if err != nil {
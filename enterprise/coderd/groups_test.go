@@ -182,6 +182,36 @@ func TestPatchGroup(t *testing.T) {
 		require.Equal(t, 20, group.QuotaAllowance)
 	})
 
+	t.Run("DLPPolicy", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		}})
+		userAdminClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID, rbac.RoleUserAdmin())
+		ctx := testutil.Context(t, testutil.WaitLong)
+		group, err := userAdminClient.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "contractors",
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.DLPPolicy{}, group.DLPPolicy)
+
+		policy := codersdk.DLPPolicy{BlockClipboardPaste: true}
+		group, err = userAdminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			DLPPolicy: &policy,
+		})
+		require.NoError(t, err)
+		require.Equal(t, policy, group.DLPPolicy)
+
+		group, err = userAdminClient.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			QuotaAllowance: ptr.Ref(5),
+		})
+		require.NoError(t, err)
+		require.Equal(t, policy, group.DLPPolicy)
+	})
+
 	t.Run("DisplayNameUnchanged", func(t *testing.T) {
 		t.Parallel()
 
@@ -305,6 +305,43 @@ func TestEntitlements(t *testing.T) {
 		require.True(t, entitlements.HasLicense)
 		require.Contains(t, entitlements.Warnings, "Your deployment has 2 active users but is only licensed for 1.")
 	})
+	t.Run("ServiceAccountsExcludedFromUserLimit", func(t *testing.T) {
+		t.Parallel()
+		db := dbmem.New()
+		activeUser, err := db.InsertUser(context.Background(), database.InsertUserParams{
+			ID:        uuid.New(),
+			Username:  "active-user",
+			LoginType: database.LoginTypePassword,
+		})
+		require.NoError(t, err)
+		_, err = db.UpdateUserStatus(context.Background(), database.UpdateUserStatusParams{
+			ID:        activeUser.ID,
+			Status:    database.UserStatusActive,
+			UpdatedAt: dbtime.Now(),
+		})
+		require.NoError(t, err)
+		_, err = db.InsertUser(context.Background(), database.InsertUserParams{
+			ID:                           uuid.New(),
+			Username:                     "service-account",
+			LoginType:                    database.LoginTypeNone,
+			Status:                       string(database.UserStatusActive),
+			IsServiceAccount:             true,
+			ServiceAccountOrganizationID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		})
+		require.NoError(t, err)
+		db.InsertLicense(context.Background(), database.InsertLicenseParams{
+			JWT: coderdenttest.GenerateLicense(t, coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureUserLimit: 1,
+				},
+			}),
+			Exp: time.Now().Add(time.Hour),
+		})
+		entitlements, err := license.Entitlements(context.Background(), db, 1, 1, coderdenttest.Keys, empty)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), *entitlements.Features[codersdk.FeatureUserLimit].Actual)
+		require.NotContains(t, entitlements.Warnings, "Your deployment has 2 active users but is only licensed for 1.")
+	})
 	t.Run("MaximizeUserLimit", func(t *testing.T) {
 		t.Parallel()
 		db := dbmem.New()
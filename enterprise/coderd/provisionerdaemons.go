@@ -183,6 +183,8 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 			provisionersMap[codersdk.ProvisionerTypeEcho] = struct{}{}
 		case string(codersdk.ProvisionerTypeTerraform):
 			provisionersMap[codersdk.ProvisionerTypeTerraform] = struct{}{}
+		case string(codersdk.ProvisionerTypePulumi):
+			provisionersMap[codersdk.ProvisionerTypePulumi] = struct{}{}
 		default:
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 				Message: fmt.Sprintf("Unknown provisioner type %q", provisioner),
@@ -191,6 +193,25 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Daemons that don't report an engine predate OpenTofu support, so they
+	// run Terraform.
+	var terraformEngine codersdk.TerraformEngine
+	if _, ok := provisionersMap[codersdk.ProvisionerTypeTerraform]; ok {
+		terraformEngine = codersdk.TerraformEngineTerraform
+		if qv := r.URL.Query().Get("terraform_engine"); qv != "" {
+			if err := codersdk.TerraformEngineValid(qv); err != nil {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+					Message: fmt.Sprintf("Unknown terraform engine %q", qv),
+					Validations: []codersdk.ValidationError{
+						{Field: "terraform_engine", Detail: err.Error()},
+					},
+				})
+				return
+			}
+			terraformEngine = codersdk.TerraformEngine(qv)
+		}
+	}
+
 	name := namesgenerator.GetRandomName(10)
 	if vals, ok := r.URL.Query()["name"]; ok && len(vals) > 0 {
 		name = vals[0]
@@ -227,6 +248,8 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		switch p {
 		case codersdk.ProvisionerTypeTerraform:
 			provisioners = append(provisioners, database.ProvisionerTypeTerraform)
+		case codersdk.ProvisionerTypePulumi:
+			provisioners = append(provisioners, database.ProvisionerTypePulumi)
 		case codersdk.ProvisionerTypeEcho:
 			provisioners = append(provisioners, database.ProvisionerTypeEcho)
 		}
@@ -235,6 +258,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 	log := api.Logger.With(
 		slog.F("name", name),
 		slog.F("provisioners", provisioners),
+		slog.F("terraform_engine", terraformEngine),
 		slog.F("tags", tags),
 	)
 
@@ -265,15 +289,16 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 	// Create the daemon in the database.
 	now := dbtime.Now()
 	daemon, err := api.Database.UpsertProvisionerDaemon(authCtx, database.UpsertProvisionerDaemonParams{
-		Name:           name,
-		Provisioners:   provisioners,
-		Tags:           tags,
-		CreatedAt:      now,
-		LastSeenAt:     sql.NullTime{Time: now, Valid: true},
-		Version:        versionHdrVal,
-		APIVersion:     apiVersion,
-		OrganizationID: authRes.orgID,
-		KeyID:          authRes.keyID,
+		Name:            name,
+		Provisioners:    provisioners,
+		Tags:            tags,
+		CreatedAt:       now,
+		LastSeenAt:      sql.NullTime{Time: now, Valid: true},
+		Version:         versionHdrVal,
+		APIVersion:      apiVersion,
+		OrganizationID:  authRes.orgID,
+		KeyID:           authRes.keyID,
+		TerraformEngine: string(terraformEngine),
 	})
 	if err != nil {
 		if !xerrors.Is(err, context.Canceled) {
@@ -351,9 +376,10 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		api.AGPL.UserQuietHoursScheduleStore,
 		api.DeploymentValues,
 		provisionerdserver.Options{
-			ExternalAuthConfigs: api.ExternalAuthConfigs,
-			OIDCConfig:          api.OIDCConfig,
-			Clock:               api.Clock,
+			ExternalAuthConfigs:       api.ExternalAuthConfigs,
+			OIDCConfig:                api.OIDCConfig,
+			Clock:                     api.Clock,
+			OmitTemplateSourceArchive: r.URL.Query().Get("file_cache") == "true",
 		},
 		api.NotificationsEnqueuer,
 	)
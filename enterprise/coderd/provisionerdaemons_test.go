@@ -66,9 +66,53 @@ func TestProvisionerDaemonServe(t *testing.T) {
 			assert.Equal(t, daemonName, daemons[0].Name)
 			assert.Equal(t, buildinfo.Version(), daemons[0].Version)
 			assert.Equal(t, proto.CurrentVersion.String(), daemons[0].APIVersion)
+			assert.Empty(t, daemons[0].TerraformEngine)
 		}
 	})
 
+	t.Run("TerraformEngine", func(t *testing.T) {
+		t.Parallel()
+		client, user := coderdenttest.New(t, &coderdenttest.Options{LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		}})
+		templateAdminClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID, rbac.RoleTemplateAdmin())
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		serve := func(name string, engine codersdk.TerraformEngine) error {
+			srv, err := templateAdminClient.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+				Name:            name,
+				Organization:    user.OrganizationID,
+				Provisioners:    []codersdk.ProvisionerType{codersdk.ProvisionerTypeTerraform},
+				Tags:            map[string]string{},
+				TerraformEngine: engine,
+			})
+			if err != nil {
+				return err
+			}
+			return srv.DRPCConn().Close()
+		}
+		// Daemons that don't report an engine run Terraform.
+		require.NoError(t, serve("terraform", ""))
+		require.NoError(t, serve("opentofu", codersdk.TerraformEngineOpenTofu))
+		err := serve("unknown", "pulumi")
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+
+		daemons, err := client.ProvisionerDaemons(ctx) //nolint:gocritic // Test assertion.
+		require.NoError(t, err)
+		engines := map[string]codersdk.TerraformEngine{}
+		for _, daemon := range daemons {
+			engines[daemon.Name] = daemon.TerraformEngine
+		}
+		require.Equal(t, map[string]codersdk.TerraformEngine{
+			"terraform": codersdk.TerraformEngineTerraform,
+			"opentofu":  codersdk.TerraformEngineOpenTofu,
+		}, engines)
+	})
+
 	t.Run("NoVersion", func(t *testing.T) {
 		t.Parallel()
 		// In this test, we just send a HTTP request with minimal parameters to the provisionerdaemons
@@ -1,13 +1,21 @@
 package coderd
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
 	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/enterprise/tailnet"
 )
 
 // replicas returns the number of replicas that are active in Coder.
@@ -33,6 +41,143 @@ func (api *API) replicas(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(r.Context(), rw, http.StatusOK, res)
 }
 
+// @Summary Get tailnet coordinators of replicas
+// @ID get-tailnet-coordinators-of-replicas
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Success 200 {object} codersdk.TailnetCoordinatorReport
+// @Router /replicas/coordinators [get]
+func (api *API) replicaCoordinators(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.AGPL.Authorize(r, policy.ActionRead, rbac.ResourceTailnetCoordinator) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	report, err := api.tailnetCoordinatorReport(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching tailnet coordinators.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, report)
+}
+
+// @Summary Drain tailnet peers of replica
+// @ID drain-tailnet-peers-of-replica
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param replica path string true "Replica ID" format(uuid)
+// @Success 204
+// @Router /replicas/{replica}/drain [post]
+func (api *API) postReplicaDrain(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.AGPL.Authorize(r, policy.ActionUpdate, rbac.ResourceTailnetCoordinator) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	replicaID, ok := httpmw.ParseUUIDParam(rw, r, "replica")
+	if !ok {
+		return
+	}
+
+	report, err := api.tailnetCoordinatorReport(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching tailnet coordinators.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	found := false
+	for _, coord := range report.Coordinators {
+		if coord.ID == replicaID && coord.Healthy {
+			found = true
+			break
+		}
+	}
+	if !found {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "No healthy tailnet coordinator is running on the replica.",
+		})
+		return
+	}
+
+	err = tailnet.DrainCoordinator(api.Pubsub, replicaID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error draining replica.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (api *API) tailnetCoordinatorReport(ctx context.Context) (codersdk.TailnetCoordinatorReport, error) {
+	coords, err := api.Database.GetAllTailnetCoordinators(ctx)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return codersdk.TailnetCoordinatorReport{}, xerrors.Errorf("get coordinators: %w", err)
+	}
+	peers, err := api.Database.GetAllTailnetPeers(ctx)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return codersdk.TailnetCoordinatorReport{}, xerrors.Errorf("get peers: %w", err)
+	}
+	tunnels, err := api.Database.GetAllTailnetTunnels(ctx)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return codersdk.TailnetCoordinatorReport{}, xerrors.Errorf("get tunnels: %w", err)
+	}
+
+	hostnames := make(map[uuid.UUID]string)
+	for _, replica := range api.replicaManager.AllPrimary() {
+		hostnames[replica.ID] = replica.Hostname
+	}
+	// Call this once so all lags are on the same timebase.
+	now := time.Now()
+	report := codersdk.TailnetCoordinatorReport{
+		Coordinators: make([]codersdk.TailnetCoordinator, 0, len(coords)),
+		Tunnels:      make([]codersdk.TailnetCoordinatorTunnel, 0, len(tunnels)),
+	}
+	index := make(map[uuid.UUID]int, len(coords))
+	for i, coord := range coords {
+		lag := now.Sub(coord.HeartbeatAt)
+		index[coord.ID] = i
+		report.Coordinators = append(report.Coordinators, codersdk.TailnetCoordinator{
+			ID:             coord.ID,
+			Hostname:       hostnames[coord.ID],
+			HeartbeatAt:    coord.HeartbeatAt,
+			HeartbeatLagMS: lag.Milliseconds(),
+			Healthy:        lag < tailnet.HeartbeatPeriod*tailnet.MissedHeartbeats,
+		})
+	}
+	for _, peer := range peers {
+		i, ok := index[peer.CoordinatorID]
+		if !ok {
+			continue
+		}
+		if peer.Status == database.TailnetStatusLost {
+			report.Coordinators[i].LostPeers++
+		} else {
+			report.Coordinators[i].Peers++
+		}
+	}
+	for _, tun := range tunnels {
+		if i, ok := index[tun.CoordinatorID]; ok {
+			report.Coordinators[i].Tunnels++
+		}
+		report.Tunnels = append(report.Tunnels, codersdk.TailnetCoordinatorTunnel{
+			CoordinatorID: tun.CoordinatorID,
+			SrcID:         tun.SrcID,
+			DstID:         tun.DstID,
+			UpdatedAt:     tun.UpdatedAt,
+		})
+	}
+	return report, nil
+}
+
 func convertReplica(replica database.Replica) codersdk.Replica {
 	return codersdk.Replica{
 		ID:              replica.ID,
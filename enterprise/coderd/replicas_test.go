@@ -3,9 +3,12 @@ package coderd_test
 import (
 	"context"
 	"crypto/tls"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/v2/coderd/coderdtest"
@@ -181,4 +184,77 @@ func TestReplicas(t *testing.T) {
 			require.Empty(t, replica.Error)
 		}
 	})
+	t.Run("DrainCoordinator", func(t *testing.T) {
+		t.Parallel()
+		db, pubsub := dbtestutil.NewDB(t)
+		firstClient, firstUser := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+				Database:                 db,
+				Pubsub:                   pubsub,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureHighAvailability: 1,
+				},
+			},
+		})
+
+		secondClient, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				Database: db,
+				Pubsub:   pubsub,
+			},
+			DontAddLicense:   true,
+			DontAddFirstUser: true,
+		})
+		secondClient.SetSessionToken(firstClient.SessionToken())
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// The agent connects to the coordinator of the first replica.
+		_ = setupWorkspaceAgent(t, firstClient, firstUser, 0)
+		var drained codersdk.TailnetCoordinator
+		require.Eventually(t, func() bool {
+			report, err := secondClient.TailnetCoordinators(ctx)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			if len(report.Coordinators) != 2 {
+				return false
+			}
+			for _, coord := range report.Coordinators {
+				if coord.Peers == 1 {
+					drained = coord
+					return true
+				}
+			}
+			return false
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.True(t, drained.Healthy)
+		replicas, err := secondClient.Replicas(ctx)
+		require.NoError(t, err)
+		require.True(t, slices.ContainsFunc(replicas, func(r codersdk.Replica) bool {
+			return r.ID == drained.ID
+		}), "coordinator ID should match a replica")
+
+		err = secondClient.DrainReplica(ctx, uuid.New())
+		require.Error(t, err)
+		err = secondClient.DrainReplica(ctx, drained.ID)
+		require.NoError(t, err)
+
+		// The agent is disconnected, and can't reconnect to the drained
+		// coordinator.
+		require.Eventually(t, func() bool {
+			report, err := secondClient.TailnetCoordinators(ctx)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			for _, coord := range report.Coordinators {
+				if coord.ID == drained.ID {
+					return coord.Peers == 0 && coord.LostPeers == 1
+				}
+			}
+			return false
+		}, testutil.WaitLong, testutil.IntervalFast)
+	})
 }
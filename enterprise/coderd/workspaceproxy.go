@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
@@ -28,10 +30,12 @@ import (
 	"github.com/coder/coder/v2/coderd/workspaceapps"
 	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
 	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/wsjson"
 	"github.com/coder/coder/v2/cryptorand"
 	"github.com/coder/coder/v2/enterprise/coderd/proxyhealth"
 	"github.com/coder/coder/v2/enterprise/replicasync"
 	"github.com/coder/coder/v2/enterprise/wsproxy/wsproxysdk"
+	"github.com/coder/websocket"
 )
 
 // whitelistedCryptoKeyFeatures is a list of crypto key features that are
@@ -763,6 +767,142 @@ func (api *API) workspaceProxyCryptoKeys(rw http.ResponseWriter, r *http.Request
 	})
 }
 
+// workspaceProxyFile returns a file to a workspace proxy so it can be cached
+// close to external provisioner daemons. The SHA256 hash of the file is
+// returned in a header so the proxy can verify the contents.
+//
+// @Summary Get workspace proxy file
+// @ID get-workspace-proxy-file
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param fileID path string true "File ID" format(uuid)
+// @Success 200
+// @Router /workspaceproxies/me/files/{fileID} [get]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceProxyFile(rw http.ResponseWriter, r *http.Request) {
+	file, ok := api.workspaceProxyFileByParam(rw, r)
+	if !ok {
+		return
+	}
+
+	rw.Header().Set("Content-Type", file.Mimetype)
+	rw.Header().Set(wsproxysdk.FileHashHeader, file.Hash)
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(file.Data)
+}
+
+// workspaceProxyFileHash returns only the SHA256 hash of a file, allowing a
+// workspace proxy to confirm a file ID matches an entry it already cached.
+//
+// @Summary Get workspace proxy file hash
+// @ID get-workspace-proxy-file-hash
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param fileID path string true "File ID" format(uuid)
+// @Success 200
+// @Router /workspaceproxies/me/files/{fileID} [head]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceProxyFileHash(rw http.ResponseWriter, r *http.Request) {
+	file, ok := api.workspaceProxyFileByParam(rw, r)
+	if !ok {
+		return
+	}
+
+	rw.Header().Set(wsproxysdk.FileHashHeader, file.Hash)
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (api *API) workspaceProxyFileByParam(rw http.ResponseWriter, r *http.Request) (database.File, bool) {
+	ctx := r.Context()
+
+	fileID, err := uuid.Parse(chi.URLParam(r, "fileID"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "File id must be a valid UUID.",
+		})
+		return database.File{}, false
+	}
+
+	file, err := api.Database.GetFileByID(ctx, fileID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return database.File{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return database.File{}, false
+	}
+	return file, true
+}
+
+// workspaceProxyPTYShareEvents streams changes to PTY shares to the workspace
+// proxy, so it can close the shared terminal sessions they no longer allow.
+// The connection is closed if an event is dropped, since the proxy must then
+// assume it missed changes.
+//
+// @Summary Workspace proxy PTY share events
+// @ID workspace-proxy-pty-share-events
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Success 101
+// @Router /workspaceproxies/me/pty-share-events [get]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceProxyPTYShareEvents(rw http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events := make(chan workspaceapps.PTYShareEvent, 64)
+	unsubscribe, err := api.Pubsub.SubscribeWithErr(workspaceapps.PTYShareEventChannel, func(_ context.Context, msg []byte, err error) {
+		if err != nil {
+			api.Logger.Warn(ctx, "pty share event", slog.Error(err))
+			cancel()
+			return
+		}
+		var event workspaceapps.PTYShareEvent
+		err = json.Unmarshal(msg, &event)
+		if err != nil {
+			api.Logger.Warn(ctx, "unmarshal pty share event", slog.Error(err))
+			return
+		}
+		select {
+		case events <- event:
+		default:
+			api.Logger.Warn(ctx, "workspace proxy is too slow to receive pty share events")
+			cancel()
+		}
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	defer unsubscribe()
+
+	conn, err := websocket.Accept(rw, r, nil)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to accept websocket.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	go httpapi.HeartbeatClose(ctx, api.Logger, cancel, conn)
+
+	encoder := wsjson.NewEncoder[workspaceapps.PTYShareEvent](conn, websocket.MessageText)
+	defer encoder.Close(websocket.StatusNormalClosure)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			err := encoder.Encode(event)
+			if err != nil {
+				api.Logger.Debug(ctx, "send pty share event", slog.Error(err))
+				return
+			}
+		}
+	}
+}
+
 // @Summary Deregister workspace proxy
 // @ID deregister-workspace-proxy
 // @Security CoderSessionToken
@@ -884,6 +1024,18 @@ func (api *API) reconnectingPTYSignedToken(rw http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// The reconnect ID is only required for PTYs shared with the user.
+	reconnectID := u.Query().Get("reconnect")
+	if reconnectID != "" {
+		if _, err := uuid.Parse(reconnectID); err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid reconnect ID in URL.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
 	scheme, err := api.AGPL.ValidWorkspaceAppHostname(ctx, u.Host, agpl.ValidWorkspaceAppHostnameOpts{
 		// Only allow the proxy access URL as a hostname since we don't need a
 		// ticket for the primary dashboard URL terminal.
@@ -912,6 +1064,7 @@ func (api *API) reconnectingPTYSignedToken(rw http.ResponseWriter, r *http.Reque
 			AccessMethod:  workspaceapps.AccessMethodTerminal,
 			BasePath:      u.Path,
 			AgentNameOrID: req.AgentID.String(),
+			ReconnectID:   reconnectID,
 		},
 		SessionToken: httpmw.APITokenFromRequest(r),
 		// The following fields aren't required as long as the request is authed
@@ -73,6 +73,29 @@ func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciphe
 					return xerrors.Errorf("update external auth link user_id=%s provider_id=%s: %w", externalAuthLink.UserID, externalAuthLink.ProviderID, err)
 				}
 			}
+
+			userSecrets, err := cryptTx.GetUserSecrets(ctx, uid)
+			if err != nil {
+				return xerrors.Errorf("get secrets for user: %w", err)
+			}
+			for _, userSecret := range userSecrets {
+				if userSecret.ValueKeyID.String == ciphers[0].HexDigest() {
+					log.Debug(ctx, "skipping user secret", slog.F("user_id", uid), slog.F("current", idx+1), slog.F("cipher", ciphers[0].HexDigest()))
+					continue
+				}
+				if _, err := cryptTx.UpdateUserSecret(ctx, database.UpdateUserSecretParams{
+					ID:          userSecret.ID,
+					Description: userSecret.Description,
+					Value:       userSecret.Value,
+					ValueKeyID:  sql.NullString{}, // dbcrypt will update as required
+					EnvName:     userSecret.EnvName,
+					FilePath:    userSecret.FilePath,
+					TemplateIDs: userSecret.TemplateIDs,
+					UpdatedAt:   userSecret.UpdatedAt,
+				}); err != nil {
+					return xerrors.Errorf("update user secret user_id=%s name=%s: %w", userSecret.UserID, userSecret.Name, err)
+				}
+			}
 			return nil
 		}, &database.TxOptions{
 			Isolation: sql.LevelRepeatableRead,
@@ -163,6 +186,29 @@ func Decrypt(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciph
 					return xerrors.Errorf("update external auth link user_id=%s provider_id=%s: %w", externalAuthLink.UserID, externalAuthLink.ProviderID, err)
 				}
 			}
+
+			userSecrets, err := tx.GetUserSecrets(ctx, uid)
+			if err != nil {
+				return xerrors.Errorf("get secrets for user: %w", err)
+			}
+			for _, userSecret := range userSecrets {
+				if !userSecret.ValueKeyID.Valid {
+					log.Debug(ctx, "skipping user secret", slog.F("user_id", uid), slog.F("current", idx+1))
+					continue
+				}
+				if _, err := tx.UpdateUserSecret(ctx, database.UpdateUserSecretParams{
+					ID:          userSecret.ID,
+					Description: userSecret.Description,
+					Value:       userSecret.Value,
+					ValueKeyID:  sql.NullString{}, // we explicitly want to clear the key id
+					EnvName:     userSecret.EnvName,
+					FilePath:    userSecret.FilePath,
+					TemplateIDs: userSecret.TemplateIDs,
+					UpdatedAt:   userSecret.UpdatedAt,
+				}); err != nil {
+					return xerrors.Errorf("update user secret user_id=%s name=%s: %w", userSecret.UserID, userSecret.Name, err)
+				}
+			}
 			return nil
 		}, &database.TxOptions{
 			Isolation: sql.LevelRepeatableRead,
@@ -193,6 +239,8 @@ DELETE FROM user_links
 DELETE FROM external_auth_links
 	WHERE oauth_access_token_key_id IS NOT NULL
 	OR oauth_refresh_token_key_id IS NOT NULL;
+DELETE FROM user_secrets
+	WHERE value_key_id IS NOT NULL;
 COMMIT;
 `
 
@@ -401,6 +401,122 @@ func TestExternalAuthLinks(t *testing.T) {
 	})
 }
 
+func TestUserSecrets(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("InsertUserSecret", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		user := dbgen.User(t, crypt, database.User{})
+		secret := dbgen.UserSecret(t, crypt, database.UserSecret{
+			UserID: user.ID,
+			Value:  "value",
+		})
+		require.Equal(t, "value", secret.Value)
+
+		secret, err := db.GetUserSecretByID(ctx, secret.ID)
+		require.NoError(t, err)
+		requireEncryptedEquals(t, ciphers[0], secret.Value, "value")
+	})
+
+	t.Run("UpdateUserSecret", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		user := dbgen.User(t, crypt, database.User{})
+		secret := dbgen.UserSecret(t, crypt, database.UserSecret{UserID: user.ID})
+		updated, err := crypt.UpdateUserSecret(ctx, database.UpdateUserSecretParams{
+			ID:          secret.ID,
+			Value:       "value",
+			TemplateIDs: secret.TemplateIDs,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "value", updated.Value)
+
+		secret, err = db.GetUserSecretByID(ctx, secret.ID)
+		require.NoError(t, err)
+		requireEncryptedEquals(t, ciphers[0], secret.Value, "value")
+	})
+
+	t.Run("GetUserSecretByUserIDAndName", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("OK", func(t *testing.T) {
+			t.Parallel()
+			_, crypt, ciphers := setup(t)
+			user := dbgen.User(t, crypt, database.User{})
+			secret := dbgen.UserSecret(t, crypt, database.UserSecret{
+				UserID: user.ID,
+				Value:  "value",
+			})
+			secret, err := crypt.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+				UserID: user.ID,
+				Name:   secret.Name,
+			})
+			require.NoError(t, err)
+			require.Equal(t, "value", secret.Value)
+			require.Equal(t, ciphers[0].HexDigest(), secret.ValueKeyID.String)
+		})
+
+		t.Run("DecryptErr", func(t *testing.T) {
+			t.Parallel()
+			db, crypt, ciphers := setup(t)
+			user := dbgen.User(t, db, database.User{})
+			secret := dbgen.UserSecret(t, db, database.UserSecret{
+				UserID:     user.ID,
+				Value:      fakeBase64RandomData(t, 32),
+				ValueKeyID: sql.NullString{String: ciphers[0].HexDigest(), Valid: true},
+			})
+			_, err := crypt.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+				UserID: user.ID,
+				Name:   secret.Name,
+			})
+			require.Error(t, err, "expected an error")
+			var derr *DecryptFailedError
+			require.ErrorAs(t, err, &derr, "expected a decrypt error")
+		})
+	})
+
+	t.Run("GetUserSecrets", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("OK", func(t *testing.T) {
+			t.Parallel()
+			db, crypt, ciphers := setup(t)
+			user := dbgen.User(t, crypt, database.User{})
+			_ = dbgen.UserSecret(t, crypt, database.UserSecret{
+				UserID: user.ID,
+				Value:  "value",
+			})
+			secrets, err := crypt.GetUserSecrets(ctx, user.ID)
+			require.NoError(t, err)
+			require.Len(t, secrets, 1)
+			require.Equal(t, "value", secrets[0].Value)
+			require.Equal(t, ciphers[0].HexDigest(), secrets[0].ValueKeyID.String)
+
+			rawSecrets, err := db.GetUserSecrets(ctx, user.ID)
+			require.NoError(t, err)
+			require.Len(t, rawSecrets, 1)
+			requireEncryptedEquals(t, ciphers[0], rawSecrets[0].Value, "value")
+		})
+
+		t.Run("DecryptErr", func(t *testing.T) {
+			t.Parallel()
+			db, crypt, ciphers := setup(t)
+			user := dbgen.User(t, db, database.User{})
+			_ = dbgen.UserSecret(t, db, database.UserSecret{
+				UserID:     user.ID,
+				Value:      fakeBase64RandomData(t, 32),
+				ValueKeyID: sql.NullString{String: ciphers[0].HexDigest(), Valid: true},
+			})
+			_, err := crypt.GetUserSecrets(ctx, user.ID)
+			require.Error(t, err, "expected an error")
+			var derr *DecryptFailedError
+			require.ErrorAs(t, err, &derr, "expected a decrypt error")
+		})
+	})
+}
+
 func TestCryptoKeys(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
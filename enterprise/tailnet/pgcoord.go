@@ -26,6 +26,7 @@ import (
 
 const (
 	EventHeartbeats        = "tailnet_coordinator_heartbeat"
+	EventDrain             = "tailnet_coordinator_drain"
 	eventPeerUpdate        = "tailnet_peer_update"
 	eventTunnelUpdate      = "tailnet_tunnel_update"
 	eventReadyForHandshake = "tailnet_ready_for_handshake"
@@ -115,21 +116,46 @@ var pgCoordSubject = rbac.Subject{
 // NewPGCoord creates a high-availability coordinator that stores state in the PostgreSQL database and
 // receives notifications of updates via the pubsub.
 func NewPGCoord(ctx context.Context, logger slog.Logger, ps pubsub.Pubsub, store database.Store) (agpl.Coordinator, error) {
-	return newPGCoordInternal(ctx, logger, ps, store, quartz.NewReal())
+	return newPGCoordInternal(ctx, logger, ps, store, uuid.New(), quartz.NewReal())
+}
+
+// NewPGCoordWithID creates a high-availability coordinator like NewPGCoord, but identified by the given ID rather
+// than a random one.  Deployments use the ID of the replica, so that coordinators can be matched to replicas and
+// drained by replica ID.  Any state left behind by an earlier coordinator with the same ID is deleted.
+func NewPGCoordWithID(
+	ctx context.Context, logger slog.Logger, ps pubsub.Pubsub, store database.Store, id uuid.UUID,
+) (
+	agpl.Coordinator, error,
+) {
+	err := store.DeleteCoordinator(dbauthz.As(ctx, pgCoordSubject), id)
+	if err != nil {
+		return nil, xerrors.Errorf("delete previous coordinator: %w", err)
+	}
+	return newPGCoordInternal(ctx, logger, ps, store, id, quartz.NewReal())
 }
 
 // NewTestPGCoord is only used in testing to pass a clock.Clock in.
 func NewTestPGCoord(ctx context.Context, logger slog.Logger, ps pubsub.Pubsub, store database.Store, clk quartz.Clock) (agpl.Coordinator, error) {
-	return newPGCoordInternal(ctx, logger, ps, store, clk)
+	return newPGCoordInternal(ctx, logger, ps, store, uuid.New(), clk)
+}
+
+// DrainCoordinator asks the coordinator with the given ID to stop accepting peers and to disconnect its existing
+// peers, so that they reconnect to the coordinator of another replica.  The coordinator keeps rejecting peers until it
+// is closed.
+func DrainCoordinator(ps pubsub.Pubsub, id uuid.UUID) error {
+	err := ps.Publish(EventDrain, []byte(id.String()))
+	if err != nil {
+		return xerrors.Errorf("publish drain: %w", err)
+	}
+	return nil
 }
 
 func newPGCoordInternal(
-	ctx context.Context, logger slog.Logger, ps pubsub.Pubsub, store database.Store, clk quartz.Clock,
+	ctx context.Context, logger slog.Logger, ps pubsub.Pubsub, store database.Store, id uuid.UUID, clk quartz.Clock,
 ) (
 	*pgCoord, error,
 ) {
 	ctx, cancel := context.WithCancel(dbauthz.As(ctx, pgCoordSubject))
-	id := uuid.New()
 	logger = logger.Named("pgcoord").With(slog.F("coordinator_id", id))
 	bCh := make(chan binding)
 	// used for opening connections
@@ -227,12 +253,12 @@ func (c *pgCoord) Coordinate(
 	logger := c.logger.With(slog.F("peer_id", id))
 	reqs := make(chan *proto.CoordinateRequest, agpl.RequestBufferSize)
 	resps := make(chan *proto.CoordinateResponse, agpl.ResponseBufferSize)
-	if !c.querier.isHealthy() {
-		// If the coordinator is unhealthy, we don't want to hook this Coordinate call up to the
-		// binder, as that can cause an unnecessary call to DeleteTailnetPeer when the connIO is
+	if !c.querier.isAccepting() {
+		// If the coordinator is unhealthy or draining, we don't want to hook this Coordinate call up to
+		// the binder, as that can cause an unnecessary call to DeleteTailnetPeer when the connIO is
 		// closed.  Instead, we just close the response channel and bail out.
 		// c.f. https://github.com/coder/coder/issues/12923
-		c.logger.Info(ctx, "closed incoming coordinate call while unhealthy",
+		c.logger.Info(ctx, "closed incoming coordinate call while unhealthy or draining",
 			slog.F("peer_id", id),
 		)
 		close(resps)
@@ -814,6 +840,8 @@ type querier struct {
 	mu      sync.Mutex
 	mappers map[mKey]*mapper
 	healthy bool
+	// draining is set once we are asked to drain, and is never unset.
+	draining bool
 }
 
 func newQuerier(ctx context.Context,
@@ -881,14 +909,14 @@ func (q *querier) handleIncoming() {
 func (q *querier) newConn(c *connIO) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	if !q.healthy {
+	if !q.healthy || q.draining {
 		err := c.Close()
 		// This can only happen during a narrow window where we were healthy
 		// when pgCoord checked before accepting the connection, but now are
 		// unhealthy now that we get around to processing it. Seeing a small
 		// number of these logs is not worrying, but a large number probably
 		// indicates something is amiss.
-		q.logger.Warn(q.ctx, "closed incoming connection while unhealthy",
+		q.logger.Warn(q.ctx, "closed incoming connection while unhealthy or draining",
 			slog.Error(err),
 			slog.F("peer_id", c.UniqueID()),
 		)
@@ -917,6 +945,14 @@ func (q *querier) isHealthy() bool {
 	return q.healthy
 }
 
+// isAccepting returns whether new connections may be hooked up, which is the case when we are healthy and not
+// draining.
+func (q *querier) isAccepting() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.healthy && !q.draining
+}
+
 func (q *querier) cleanupConn(c *connIO) {
 	logger := q.logger.With(slog.F("peer_id", c.UniqueID()))
 	q.mu.Lock()
@@ -1120,6 +1156,28 @@ func (q *querier) subscribe() {
 		}()
 		q.logger.Info(q.ctx, "subscribed to ready for handshakes")
 
+		var cancelDrain context.CancelFunc
+		err = backoff.Retry(func() error {
+			cancelFn, err := q.pubsub.SubscribeWithErr(EventDrain, q.listenDrain)
+			if err != nil {
+				q.logger.Warn(q.ctx, "failed to subscribe to drain requests", slog.Error(err))
+				return err
+			}
+			cancelDrain = cancelFn
+			return nil
+		}, bkoff)
+		if err != nil {
+			if q.ctx.Err() == nil {
+				q.logger.Error(q.ctx, "code bug: retry failed before context canceled", slog.Error(err))
+			}
+			return
+		}
+		defer func() {
+			q.logger.Info(q.ctx, "canceling drain subscription")
+			cancelDrain()
+		}()
+		q.logger.Info(q.ctx, "subscribed to drain requests")
+
 		// unblock the outer function from returning
 		subscribed <- struct{}{}
 
@@ -1217,6 +1275,26 @@ func (q *querier) listenReadyForHandshake(_ context.Context, msg []byte, err err
 	})
 }
 
+func (q *querier) listenDrain(_ context.Context, msg []byte, err error) {
+	if xerrors.Is(err, pubsub.ErrDroppedMessages) {
+		q.logger.Warn(q.ctx, "pubsub may have dropped drain requests")
+		return
+	}
+	if err != nil {
+		q.logger.Warn(q.ctx, "unhandled pubsub error", slog.Error(err))
+		return
+	}
+	id, err := uuid.ParseBytes(msg)
+	if err != nil {
+		q.logger.Error(q.ctx, "failed to parse drain request", slog.F("msg", string(msg)), slog.Error(err))
+		return
+	}
+	if id != q.coordinatorID {
+		return
+	}
+	q.drainCloseAll()
+}
+
 func (q *querier) resyncPeerMappings() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -1280,6 +1358,29 @@ func (q *querier) unhealthyCloseAll() {
 	}
 }
 
+// drainCloseAll marks the coordinator as draining and closes all connections, so that peers reconnect to the
+// coordinator of another replica.  Unlike when we are unhealthy, we keep sending heartbeats, so peers stay reachable
+// through the mappings we stored until they have reconnected.
+func (q *querier) drainCloseAll() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.draining {
+		return
+	}
+	q.draining = true
+	q.logger.Info(q.ctx, "draining coordinator", slog.F("peers", len(q.mappers)))
+	for _, mpr := range q.mappers {
+		// close connections async so that we don't block the pubsub listener
+		go func(c *connIO) {
+			err := c.Close()
+			if err != nil {
+				q.logger.Debug(q.ctx, "error closing conn while draining", slog.Error(err))
+			}
+		}(mpr.c)
+		// NOTE: we don't need to remove the connection from the map, as that will happen async in q.cleanupConn()
+	}
+}
+
 func (q *querier) setHealthy() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
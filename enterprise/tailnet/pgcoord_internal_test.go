@@ -405,7 +405,7 @@ func TestPGCoordinatorUnhealthy(t *testing.T) {
 	mStore.EXPECT().CleanTailnetTunnels(gomock.Any()).AnyTimes().Return(nil)
 	mStore.EXPECT().UpdateTailnetPeerStatusByCoordinator(gomock.Any(), gomock.Any())
 
-	coordinator, err := newPGCoordInternal(ctx, logger, ps, mStore, mClock)
+	coordinator, err := newPGCoordInternal(ctx, logger, ps, mStore, uuid.New(), mClock)
 	require.NoError(t, err)
 
 	expectedPeriod := HeartbeatPeriod
@@ -437,3 +437,59 @@ func TestPGCoordinatorUnhealthy(t *testing.T) {
 	_ = coordinator.Close()
 	require.Eventually(t, ctrl.Satisfied, testutil.WaitShort, testutil.IntervalFast)
 }
+
+// TestPGCoordinatorDrain tests that when the coordinator is asked to drain it disconnects any peers and rejects new
+// ones, while ignoring requests to drain other coordinators.
+func TestPGCoordinatorDrain(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitShort)
+	logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}).Leveled(slog.LevelDebug)
+
+	ctrl := gomock.NewController(t)
+	mStore := dbmock.NewMockStore(ctrl)
+	ps := pubsub.NewInMemory()
+	coordID := uuid.New()
+
+	mStore.EXPECT().UpsertTailnetCoordinator(gomock.Any(), coordID).AnyTimes().
+		Return(database.TailnetCoordinator{ID: coordID}, nil)
+	mStore.EXPECT().GetTailnetTunnelPeerBindings(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, nil)
+	mStore.EXPECT().UpsertTailnetPeer(gomock.Any(), gomock.Any()).AnyTimes().Return(database.TailnetPeer{}, nil)
+	mStore.EXPECT().CleanTailnetCoordinators(gomock.Any()).AnyTimes().Return(nil)
+	mStore.EXPECT().CleanTailnetLostPeers(gomock.Any()).AnyTimes().Return(nil)
+	mStore.EXPECT().CleanTailnetTunnels(gomock.Any()).AnyTimes().Return(nil)
+	mStore.EXPECT().UpdateTailnetPeerStatusByCoordinator(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	coordinator, err := newPGCoordInternal(ctx, logger, ps, mStore, coordID, quartz.NewReal())
+	require.NoError(t, err)
+	defer coordinator.Close()
+
+	pID := uuid.UUID{5}
+	_, resps := coordinator.Coordinate(ctx, pID, "test", agpl.AgentCoordinateeAuth{ID: pID})
+	require.Eventually(t, func() bool {
+		coordinator.querier.mu.Lock()
+		defer coordinator.querier.mu.Unlock()
+		return len(coordinator.querier.mappers) == 1
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	// Draining another coordinator has no effect.
+	err = DrainCoordinator(ps, uuid.New())
+	require.NoError(t, err)
+	err = DrainCoordinator(ps, coordID)
+	require.NoError(t, err)
+
+	// The peer is disconnected, which closes the response channel.
+	for {
+		resp := testutil.TryReceive(ctx, t, resps)
+		if resp == nil {
+			break
+		}
+	}
+	require.True(t, coordinator.querier.isHealthy())
+	require.False(t, coordinator.querier.isAccepting())
+
+	// New peers are rejected.
+	pID2 := uuid.UUID{6}
+	_, resps = coordinator.Coordinate(ctx, pID2, "test", agpl.AgentCoordinateeAuth{ID: pID2})
+	resp := testutil.TryReceive(ctx, t, resps)
+	require.Nil(t, resp, "channel should be closed")
+}
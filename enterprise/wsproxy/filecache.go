@@ -0,0 +1,373 @@
+package wsproxy
+
+import (
+	"container/list"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/enterprise/wsproxy/wsproxysdk"
+)
+
+// DefaultFileCacheMaxBytes is the default size limit of the file cache.
+const DefaultFileCacheMaxBytes int64 = 1 << 30
+
+// errFileHashMismatch is returned when the requested file ID does not have the
+// requested hash on the primary.
+var errFileHashMismatch = xerrors.New("file does not have the requested hash")
+
+// fileCache is a content-addressed cache of template source archives stored
+// on the local disk of the workspace proxy. External provisioner daemons in
+// the proxy's region fetch archives from here instead of from the primary.
+//
+// Entries are keyed by their SHA256 hash. Files fetched from the primary are
+// verified against the hash it reports, and cached files are verified again
+// on every read, so a corrupted entry is refetched rather than served.
+//
+// Requests must provide both the file ID and its hash, which together act as
+// the capability to read the file. Both are only handed out by the primary to
+// provisioner daemons that acquired a job for the file. Different file IDs may
+// share the same contents, so the proxy confirms with the primary that a file
+// ID has the requested hash before serving a cached entry for it.
+type fileCache struct {
+	logger   slog.Logger
+	client   *wsproxysdk.Client
+	dir      string
+	maxBytes int64
+
+	fetches singleflight.Group
+
+	mu sync.Mutex
+	// entries maps hashes to elements of lru. The front of lru is the most
+	// recently used entry.
+	entries map[string]*list.Element
+	lru     *list.List
+	size    int64
+
+	requests  *prometheus.CounterVec
+	sizeBytes prometheus.Gauge
+}
+
+type fileCacheEntry struct {
+	hash string
+	size int64
+	// fileIDs are the file IDs confirmed by the primary to have this hash.
+	fileIDs map[uuid.UUID]struct{}
+}
+
+func newFileCache(logger slog.Logger, client *wsproxysdk.Client, dir string, maxBytes int64, reg prometheus.Registerer) (*fileCache, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultFileCacheMaxBytes
+	}
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, xerrors.Errorf("create file cache directory: %w", err)
+	}
+
+	c := &fileCache{
+		logger:   logger,
+		client:   client,
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coder",
+			Subsystem: "wsproxy",
+			Name:      "file_cache_requests_total",
+			Help:      "Total number of file cache requests, by whether the file was served from the cache.",
+		}, []string{"result"}),
+		sizeBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "coder",
+			Subsystem: "wsproxy",
+			Name:      "file_cache_size_bytes",
+			Help:      "Total size of the files stored in the file cache.",
+		}),
+	}
+	if reg != nil {
+		reg.MustRegister(c.requests, c.sizeBytes)
+	}
+
+	err = c.load()
+	if err != nil {
+		return nil, xerrors.Errorf("load file cache: %w", err)
+	}
+	return c, nil
+}
+
+// load populates the index from files already present in the cache
+// directory, so the cache survives restarts of the proxy.
+func (c *fileCache) load() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type existing struct {
+		hash    string
+		size    int64
+		modTime time.Time
+	}
+	files := make([]existing, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !validFileHash(dirEntry.Name()) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, existing{hash: dirEntry.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+	// Oldest files are inserted first so they end up at the back of the LRU.
+	slices.SortFunc(files, func(a, b existing) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, file := range files {
+		c.entries[file.hash] = c.lru.PushFront(&fileCacheEntry{
+			hash:    file.hash,
+			size:    file.size,
+			fileIDs: make(map[uuid.UUID]struct{}),
+		})
+		c.size += file.size
+	}
+	c.evictLocked()
+	return nil
+}
+
+// serveFile handles requests from provisioner daemons for template source
+// archives.
+func (c *fileCache) serveFile(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	hash := chi.URLParam(r, "hash")
+	if !validFileHash(hash) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "File hash must be a hex-encoded SHA256 hash.",
+		})
+		return
+	}
+	fileID, err := uuid.Parse(r.URL.Query().Get("file_id"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The file_id query parameter must be a valid UUID.",
+		})
+		return
+	}
+
+	data, err := c.get(ctx, fileID, hash)
+	if err != nil {
+		var sdkErr *codersdk.Error
+		if xerrors.Is(err, errFileHashMismatch) || (xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound) {
+			httpapi.ResourceNotFound(rw)
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusBadGateway, codersdk.Response{
+			Message: "Failed to fetch file from the primary.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/octet-stream")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(data)
+}
+
+// get returns the file with the given hash, fetching it from the primary if
+// it is not cached.
+func (c *fileCache) get(ctx context.Context, fileID uuid.UUID, hash string) ([]byte, error) {
+	if data, ok := c.read(ctx, hash); ok {
+		err := c.confirm(ctx, fileID, hash)
+		if err != nil {
+			return nil, err
+		}
+		c.requests.WithLabelValues("hit").Inc()
+		return data, nil
+	}
+	c.requests.WithLabelValues("miss").Inc()
+
+	// Only one request per file is sent to the primary at a time.
+	v, err, _ := c.fetches.Do(fileID.String()+"/"+hash, func() (any, error) {
+		data, primaryHash, err := c.client.File(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if primaryHash != hash {
+			return nil, errFileHashMismatch
+		}
+		err = c.store(fileID, hash, data)
+		if err != nil {
+			// The file can still be served, it just won't be cached.
+			c.logger.Warn(ctx, "failed to store file in cache", slog.F("hash", hash), slog.Error(err))
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	//nolint:forcetypeassert
+	return v.([]byte), nil
+}
+
+// confirm checks with the primary that the file ID has the given hash, unless
+// that was already confirmed for the cached entry.
+func (c *fileCache) confirm(ctx context.Context, fileID uuid.UUID, hash string) error {
+	c.mu.Lock()
+	elem, ok := c.entries[hash]
+	if ok {
+		//nolint:forcetypeassert
+		_, ok = elem.Value.(*fileCacheEntry).fileIDs[fileID]
+	}
+	c.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	primaryHash, err := c.client.FileHash(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if primaryHash != hash {
+		return errFileHashMismatch
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[hash]; ok {
+		//nolint:forcetypeassert
+		elem.Value.(*fileCacheEntry).fileIDs[fileID] = struct{}{}
+	}
+	return nil
+}
+
+// read returns the cached file with the given hash. Entries that fail
+// verification are removed.
+func (c *fileCache) read(ctx context.Context, hash string) ([]byte, bool) {
+	c.mu.Lock()
+	elem, ok := c.entries[hash]
+	if ok {
+		c.lru.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(c.path(hash))
+	if err == nil {
+		err = wsproxysdk.VerifyFileHash(data, hash)
+	}
+	if err != nil {
+		c.logger.Warn(ctx, "discarding invalid file cache entry", slog.F("hash", hash), slog.Error(err))
+		c.remove(hash)
+		return nil, false
+	}
+	return data, true
+}
+
+// store writes the file to disk and evicts the least recently used entries
+// until the cache fits within its size limit.
+func (c *fileCache) store(fileID uuid.UUID, hash string, data []byte) error {
+	size := int64(len(data))
+	if size > c.maxBytes {
+		return xerrors.Errorf("file of %d bytes exceeds the cache size limit of %d bytes", size, c.maxBytes)
+	}
+
+	// Write to a temporary file first so partially written files are never
+	// visible under their hash.
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return xerrors.Errorf("create temporary file: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(hash))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return xerrors.Errorf("write file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[hash]; ok {
+		//nolint:forcetypeassert
+		elem.Value.(*fileCacheEntry).fileIDs[fileID] = struct{}{}
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+	c.entries[hash] = c.lru.PushFront(&fileCacheEntry{
+		hash:    hash,
+		size:    size,
+		fileIDs: map[uuid.UUID]struct{}{fileID: {}},
+	})
+	c.size += size
+	c.evictLocked()
+	return nil
+}
+
+func (c *fileCache) remove(hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[hash]
+	if !ok {
+		return
+	}
+	c.removeLocked(elem)
+}
+
+func (c *fileCache) evictLocked() {
+	for c.size > c.maxBytes {
+		elem := c.lru.Back()
+		if elem == nil {
+			break
+		}
+		c.removeLocked(elem)
+	}
+	c.sizeBytes.Set(float64(c.size))
+}
+
+func (c *fileCache) removeLocked(elem *list.Element) {
+	//nolint:forcetypeassert
+	entry := c.lru.Remove(elem).(*fileCacheEntry)
+	delete(c.entries, entry.hash)
+	c.size -= entry.size
+	c.sizeBytes.Set(float64(c.size))
+	err := os.Remove(c.path(entry.hash))
+	if err != nil && !os.IsNotExist(err) {
+		c.logger.Warn(context.Background(), "failed to remove file cache entry", slog.F("hash", entry.hash), slog.Error(err))
+	}
+}
+
+func (c *fileCache) path(hash string) string {
+	return filepath.Join(c.dir, hash)
+}
+
+func validFileHash(hash string) bool {
+	if len(hash) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/coder/coder/v2/enterprise/wsproxy/wsproxysdk"
 	"github.com/coder/coder/v2/site"
 	"github.com/coder/coder/v2/tailnet"
+	"github.com/coder/retry"
 )
 
 type Options struct {
@@ -93,6 +94,13 @@ type Options struct {
 	AllowAllCors bool
 
 	StatsCollectorOptions workspaceapps.StatsCollectorOptions
+
+	// FileCacheDir enables caching of template source archives for external
+	// provisioner daemons when set. Archives are stored in this directory.
+	FileCacheDir string
+	// FileCacheMaxBytes limits the total size of the file cache. Defaults to
+	// DefaultFileCacheMaxBytes.
+	FileCacheMaxBytes int64
 }
 
 func (o *Options) Validate() error {
@@ -144,11 +152,16 @@ type Server struct {
 	replicaErrMut           sync.Mutex
 	replicaErr              string
 
+	// fileCache is nil unless Options.FileCacheDir is set.
+	fileCache *fileCache
+
 	// Used for graceful shutdown. Required for the dialer.
 	ctx           context.Context
 	cancel        context.CancelFunc
 	derpCloseFunc func()
 	registerLoop  *wsproxysdk.RegisterWorkspaceProxyLoop
+	// ptyShareEventsDone is closed when ptyShareEventsLoop exits.
+	ptyShareEventsDone chan struct{}
 }
 
 // New creates a new workspace proxy server. This requires a primary coderd
@@ -319,6 +332,19 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		APIKeyEncryptionKeycache: encryptionCache,
 	}
 
+	// Shared terminals are refused until changes to PTY shares are received
+	// from the primary, so revoked shares can't be used on the proxy.
+	s.AppServer.SetPTYShareEventsAvailable(false)
+	s.ptyShareEventsDone = make(chan struct{})
+	go s.ptyShareEventsLoop()
+
+	if opts.FileCacheDir != "" {
+		s.fileCache, err = newFileCache(s.Logger.Named("file_cache"), client, opts.FileCacheDir, opts.FileCacheMaxBytes, s.PrometheusRegistry)
+		if err != nil {
+			return nil, xerrors.Errorf("create file cache: %w", err)
+		}
+	}
+
 	derpHandler := derphttp.Handler(derpServer)
 	derpHandler, s.derpCloseFunc = tailnet.WithWebsocketSupport(derpServer, derpHandler)
 
@@ -413,6 +439,16 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		})
 	}
 
+	if s.fileCache != nil {
+		r.With(apiRateLimiter).Get("/api/v2/filecache/{hash}", s.fileCache.serveFile)
+	} else {
+		r.Get("/api/v2/filecache/{hash}", func(rw http.ResponseWriter, r *http.Request) {
+			httpapi.Write(r.Context(), rw, http.StatusNotFound, codersdk.Response{
+				Message: "The file cache is disabled on this proxy.",
+			})
+		})
+	}
+
 	r.Get("/api/v2/buildinfo", s.buildInfo)
 	r.Get("/healthz", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte("OK")) })
 	// TODO: @emyrk should this be authenticated or debounced?
@@ -456,6 +492,7 @@ func (s *Server) Close() error {
 	if appServerErr != nil {
 		err = multierror.Append(err, appServerErr)
 	}
+	<-s.ptyShareEventsDone
 	agentProviderErr := s.AppServer.AgentProvider.Close()
 	if agentProviderErr != nil {
 		err = multierror.Append(err, agentProviderErr)
@@ -466,6 +503,40 @@ func (s *Server) Close() error {
 	return err
 }
 
+// ptyShareEventsLoop passes changes to PTY shares from the primary on to the
+// app server, which refuses shared terminals while it can't receive them.
+func (s *Server) ptyShareEventsLoop() {
+	defer close(s.ptyShareEventsDone)
+	for retrier := retry.New(100*time.Millisecond, 10*time.Second); retrier.Wait(s.ctx); {
+		events, closer, err := s.SDKClient.PTYShareEvents(s.ctx)
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.Logger.Warn(s.ctx, "failed to receive pty share events, shared terminals are unavailable", slog.Error(err))
+			}
+			continue
+		}
+		retrier.Reset()
+		s.AppServer.SetPTYShareEventsAvailable(true)
+		s.handlePTYShareEvents(events)
+		_ = closer.Close()
+		s.AppServer.SetPTYShareEventsAvailable(false)
+	}
+}
+
+func (s *Server) handlePTYShareEvents(events <-chan workspaceapps.PTYShareEvent) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			s.AppServer.UpdatePTYShare(event)
+		}
+	}
+}
+
 func (s *Server) mutateRegister(req *wsproxysdk.RegisterWorkspaceProxyRequest) {
 	s.replicaErrMut.Lock()
 	defer s.replicaErrMut.Unlock()
@@ -1,13 +1,21 @@
 package wsproxy_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,6 +34,7 @@ import (
 	"github.com/coder/coder/v2/buildinfo"
 	"github.com/coder/coder/v2/coderd/coderdtest"
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
 	"github.com/coder/coder/v2/coderd/database/dbgen"
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
 	"github.com/coder/coder/v2/coderd/healthcheck/derphealth"
@@ -1157,3 +1166,238 @@ func testDERPSend(t *testing.T, ctx context.Context, dstKey key.NodePublic, dstC
 		require.NoError(t, err, "send message via DERP")
 	}
 }
+
+func TestWorkspaceProxyFileCache(t *testing.T) {
+	t.Parallel()
+
+	client, closer, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+	t.Cleanup(func() {
+		_ = closer.Close()
+	})
+
+	cacheDir := t.TempDir()
+	proxy := coderdenttest.NewWorkspaceProxyReplica(t, api, client, &coderdenttest.ProxyOptions{
+		Name:         "best-proxy",
+		FileCacheDir: cacheDir,
+	})
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	data := testutil.CreateTar(t, map[string]string{"main.tf": "# hello"})
+	upload, err := client.Upload(ctx, codersdk.ContentTypeTar, bytes.NewReader(data))
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	fetcher := &wsproxysdk.FileCacheClient{URL: proxy.ServerURL}
+
+	t.Run("Fetch", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		got, err := fetcher.FetchFile(ctx, upload.ID, hash)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+
+		// The file is now cached on disk by its hash.
+		cached, err := os.ReadFile(filepath.Join(cacheDir, hash))
+		require.NoError(t, err)
+		require.Equal(t, data, cached)
+
+		got, err = fetcher.FetchFile(ctx, upload.ID, hash)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	})
+
+	t.Run("HashMismatch", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		wrong := sha256.Sum256([]byte("wrong"))
+		_, err := fetcher.FetchFile(ctx, upload.ID, hex.EncodeToString(wrong[:]))
+		require.Error(t, err)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := fetcher.FetchFile(ctx, uuid.New(), hash)
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+}
+
+func TestWorkspaceProxyFileCacheCorrupted(t *testing.T) {
+	t.Parallel()
+
+	client, closer, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+	t.Cleanup(func() {
+		_ = closer.Close()
+	})
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	data := testutil.CreateTar(t, map[string]string{"main.tf": "# hello"})
+	upload, err := client.Upload(ctx, codersdk.ContentTypeTar, bytes.NewReader(data))
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	// Entries already on disk are loaded on startup and verified on read.
+	cacheDir := t.TempDir()
+	err = os.WriteFile(filepath.Join(cacheDir, hash), []byte("corrupted"), 0o600)
+	require.NoError(t, err)
+
+	proxy := coderdenttest.NewWorkspaceProxyReplica(t, api, client, &coderdenttest.ProxyOptions{
+		Name:         "best-proxy",
+		FileCacheDir: cacheDir,
+	})
+	fetcher := &wsproxysdk.FileCacheClient{URL: proxy.ServerURL}
+
+	got, err := fetcher.FetchFile(ctx, upload.ID, hash)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	cached, err := os.ReadFile(filepath.Join(cacheDir, hash))
+	require.NoError(t, err)
+	require.Equal(t, data, cached)
+}
+
+func TestWorkspaceProxyPTYShare(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Test uses cat.")
+	}
+
+	db, pubsub := dbtestutil.NewDB(t)
+	ownerClient, closer, api, owner := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			Database: db,
+			Pubsub:   pubsub,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+	t.Cleanup(func() {
+		_ = closer.Close()
+	})
+	client, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+	otherClient, other := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+	r := dbfake.WorkspaceBuild(t, db, database.WorkspaceTable{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        user.ID,
+	}).WithAgent().Do()
+	_ = agenttest.New(t, client.URL, r.AgentToken)
+	resources := coderdtest.AwaitWorkspaceAgents(t, client, r.Workspace.ID)
+	agentID := resources[0].Agents[0].ID
+
+	proxy := coderdenttest.NewWorkspaceProxyReplica(t, api, ownerClient, &coderdenttest.ProxyOptions{
+		Name: "best-proxy",
+	})
+	proxyClient := workspacesdk.New(codersdk.New(proxy.ServerURL))
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	reconnect := uuid.New()
+	ownerConn, err := workspacesdk.New(client).AgentReconnectingPTY(ctx, workspacesdk.WorkspaceAgentReconnectingPTYOpts{
+		AgentID:   agentID,
+		Reconnect: reconnect,
+		Width:     80,
+		Height:    80,
+		Command:   "cat",
+	})
+	require.NoError(t, err)
+	defer ownerConn.Close()
+	ownerReader := testutil.NewTerminalReader(t, ownerConn)
+	write := func(conn net.Conn, data string) {
+		t.Helper()
+		b, err := json.Marshal(workspacesdk.ReconnectingPTYRequest{Data: data})
+		require.NoError(t, err)
+		_, err = conn.Write(b)
+		require.NoError(t, err)
+	}
+	contains := func(s string) func(string) bool {
+		return func(line string) bool {
+			return strings.Contains(line, s)
+		}
+	}
+	// Wait for the PTY to start, since it can only be attached to after.
+	write(ownerConn, "owner-input\r")
+	require.NoError(t, ownerReader.ReadUntil(ctx, contains("owner-input")))
+
+	_, err = client.UpsertWorkspaceAgentPTYShare(ctx, agentID, codersdk.UpsertWorkspaceAgentPTYShareRequest{
+		ReconnectID: reconnect,
+		User:        other.Username,
+		Mode:        codersdk.WorkspaceAgentPTYShareModeReadWrite,
+	})
+	require.NoError(t, err)
+
+	signedToken := func() (string, error) {
+		u := *proxy.ServerURL
+		u.Scheme = "ws"
+		u.Path = fmt.Sprintf("/api/v2/workspaceagents/%s/pty", agentID)
+		u.RawQuery = url.Values{"reconnect": {reconnect.String()}}.Encode()
+		res, err := otherClient.IssueReconnectingPTYSignedToken(ctx, codersdk.IssueReconnectingPTYSignedTokenRequest{
+			URL:     u.String(),
+			AgentID: agentID,
+		})
+		return res.SignedToken, err
+	}
+	connect := func(token string) (net.Conn, error) {
+		return proxyClient.AgentReconnectingPTY(ctx, workspacesdk.WorkspaceAgentReconnectingPTYOpts{
+			AgentID:     agentID,
+			Reconnect:   reconnect,
+			Width:       80,
+			Height:      80,
+			SignedToken: token,
+		})
+	}
+
+	// Shared terminals are refused until the proxy receives share events.
+	var (
+		token string
+		conn  net.Conn
+	)
+	require.Eventually(t, func() bool {
+		token, err = signedToken()
+		if !assert.NoError(t, err) {
+			return false
+		}
+		//nolint:bodyclose // The connection fails.
+		conn, err = connect(token)
+		return err == nil
+	}, testutil.WaitLong, testutil.IntervalMedium)
+	defer conn.Close()
+
+	write(conn, "through-proxy\r")
+	require.NoError(t, ownerReader.ReadUntil(ctx, contains("through-proxy")))
+
+	// Revoking the share closes the session on the proxy, and the token it
+	// was opened with can't be reused.
+	err = client.DeleteWorkspaceAgentPTYShare(ctx, agentID, codersdk.DeleteWorkspaceAgentPTYShareRequest{
+		ReconnectID: reconnect,
+		UserID:      other.ID,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, testutil.NewTerminalReader(t, conn).ReadUntil(ctx, nil), io.EOF)
+	//nolint:bodyclose // The connection fails.
+	_, err = connect(token)
+	require.Error(t, err)
+	_, err = signedToken()
+	require.Error(t, err)
+}
@@ -2,7 +2,10 @@ package wsproxysdk
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -17,6 +20,7 @@ import (
 	"github.com/coder/coder/v2/coderd/workspaceapps"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/codersdk/wsjson"
 	agpl "github.com/coder/coder/v2/tailnet"
 	"github.com/coder/websocket"
 )
@@ -519,6 +523,36 @@ func (c *Client) TailnetDialer() (*workspacesdk.WebsocketDialer, error) {
 	}), nil
 }
 
+// PTYShareEvents streams changes to PTY shares from the primary. The returned
+// channel is closed when the connection is lost, after which changes may have
+// been missed.
+func (c *Client) PTYShareEvents(ctx context.Context) (<-chan workspaceapps.PTYShareEvent, io.Closer, error) {
+	eventsURL, err := c.SDKClient.URL.Parse("/api/v2/workspaceproxies/me/pty-share-events")
+	if err != nil {
+		return nil, nil, xerrors.Errorf("parse url: %w", err)
+	}
+	headers := make(http.Header)
+	tokenHeader := codersdk.SessionTokenHeader
+	if c.SDKClient.SessionTokenHeader != "" {
+		tokenHeader = c.SDKClient.SessionTokenHeader
+	}
+	headers.Set(tokenHeader, c.SessionToken())
+
+	conn, res, err := websocket.Dial(ctx, eventsURL.String(), &websocket.DialOptions{
+		HTTPClient:      c.SDKClient.HTTPClient,
+		HTTPHeader:      headers,
+		CompressionMode: websocket.CompressionDisabled,
+	})
+	if err != nil {
+		if res == nil {
+			return nil, nil, err
+		}
+		return nil, nil, codersdk.ReadBodyAsError(res)
+	}
+	d := wsjson.NewDecoder[workspaceapps.PTYShareEvent](conn, websocket.MessageText, c.SDKClient.Logger())
+	return d.Chan(), d, nil
+}
+
 type CryptoKeysResponse struct {
 	CryptoKeys []codersdk.CryptoKey `json:"crypto_keys"`
 }
@@ -539,3 +573,105 @@ func (c *Client) CryptoKeys(ctx context.Context, feature codersdk.CryptoKeyFeatu
 	var resp CryptoKeysResponse
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
+
+// FileHashHeader is set by the primary on file responses to workspace proxies,
+// allowing the proxy to verify the contents before caching them.
+const FileHashHeader = "X-Coder-File-Hash"
+
+// File fetches a file by ID from the primary. The returned contents are
+// verified against the SHA256 hash reported by the primary.
+func (c *Client) File(ctx context.Context, fileID uuid.UUID) (data []byte, hash string, err error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/workspaceproxies/me/files/%s", fileID), nil,
+	)
+	if err != nil {
+		return nil, "", xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", codersdk.ReadBodyAsError(res)
+	}
+	data, err = io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", xerrors.Errorf("read file: %w", err)
+	}
+	hash = res.Header.Get(FileHashHeader)
+	if err := VerifyFileHash(data, hash); err != nil {
+		return nil, "", err
+	}
+	return data, hash, nil
+}
+
+// FileHash returns the SHA256 hash of a file on the primary without
+// downloading its contents.
+func (c *Client) FileHash(ctx context.Context, fileID uuid.UUID) (string, error) {
+	res, err := c.Request(ctx, http.MethodHead,
+		fmt.Sprintf("/api/v2/workspaceproxies/me/files/%s", fileID), nil,
+	)
+	if err != nil {
+		return "", xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", codersdk.ReadBodyAsError(res)
+	}
+	return res.Header.Get(FileHashHeader), nil
+}
+
+// VerifyFileHash returns an error if the hex-encoded SHA256 hash of data does
+// not match hash.
+func VerifyFileHash(data []byte, hash string) error {
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != hash {
+		return xerrors.Errorf("file hash mismatch: expected %q, got %q", hash, got)
+	}
+	return nil
+}
+
+// FileCacheClient fetches template source archives from the file cache of a
+// workspace proxy. It is used by external provisioner daemons running in the
+// same region as the proxy.
+type FileCacheClient struct {
+	// URL is the access URL of the workspace proxy.
+	URL        *url.URL
+	HTTPClient *http.Client
+}
+
+// FetchFile implements provisionerd.FileFetcher.
+func (c *FileCacheClient) FetchFile(ctx context.Context, fileID uuid.UUID, hash string) ([]byte, error) {
+	fileURL, err := c.URL.Parse(fmt.Sprintf("/api/v2/filecache/%s", hash))
+	if err != nil {
+		return nil, xerrors.Errorf("parse url: %w", err)
+	}
+	query := fileURL.Query()
+	query.Set("file_id", fileID.String())
+	fileURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL.String(), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("create request: %w", err)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, codersdk.ReadBodyAsError(res)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("read file: %w", err)
+	}
+	if err := VerifyFileHash(data, hash); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,472 @@
+package pulumi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/provisioner"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+const (
+	// stackName is the stack every build runs in. Each build gets a fresh
+	// working directory, so there's only ever one stack in the backend.
+	stackName = "coder"
+	// backendDirName is the directory in the working directory used as the
+	// local Pulumi backend. The state lives in the build, not here.
+	backendDirName = ".pulumi-backend"
+)
+
+type executor struct {
+	logger     slog.Logger
+	server     *server
+	mut        *sync.Mutex
+	binaryPath string
+	// cachePath and workdir must not be used by multiple processes at once.
+	cachePath string
+	workdir   string
+	stage     database.ProvisionerJobTimingStage
+}
+
+// configValue is a stack config value set before a preview.
+type configValue struct {
+	key    string
+	value  string
+	secret bool
+}
+
+func (e *executor) basicEnv() []string {
+	env := provisioner.SafeEnviron()
+	env = append(env,
+		"PULUMI_BACKEND_URL=file://"+filepath.ToSlash(filepath.Join(e.workdir, backendDirName)),
+		// The local backend requires a passphrase to encrypt secrets, but
+		// the state is exported with secrets in plaintext anyway.
+		"PULUMI_CONFIG_PASSPHRASE=",
+		"PULUMI_SKIP_UPDATE_CHECK=true",
+		"PULUMI_SKIP_CONFIRMATIONS=true",
+	)
+	if e.cachePath != "" {
+		env = append(env, "PULUMI_HOME="+e.cachePath)
+	}
+	return env
+}
+
+func (e *executor) buildEnvPath() string {
+	return filepath.Join(e.workdir, backendDirName, "build.env.json")
+}
+
+func (e *executor) saveBuildEnv(env []string) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(e.buildEnvPath(), data, 0o600)
+}
+
+func (e *executor) loadBuildEnv() ([]string, error) {
+	data, err := os.ReadFile(e.buildEnvPath())
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	err = json.Unmarshal(data, &env)
+	if err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func (e *executor) eventLogPath() string {
+	return filepath.Join(e.workdir, backendDirName, fmt.Sprintf("events-%s.json", e.stage))
+}
+
+// execWriteOutput must only be called while the lock is held.
+func (e *executor) execWriteOutput(ctx, killCtx context.Context, args, env []string, stdOutWriter, stdErrWriter io.WriteCloser) (err error) {
+	ctx, span := e.server.startTrace(ctx, fmt.Sprintf("exec - pulumi %s", args[0]))
+	defer span.End()
+	span.SetAttributes(attribute.StringSlice("args", redactArgs(args)))
+
+	defer func() {
+		closeErr := stdOutWriter.Close()
+		if err == nil && closeErr != nil {
+			err = closeErr
+		}
+		closeErr = stdErrWriter.Close()
+		if err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if provisioner.IsCanarySet(env) {
+		return xerrors.New("environment variables not sanitized, this is a bug within Coder")
+	}
+
+	// #nosec
+	cmd := exec.CommandContext(killCtx, e.binaryPath, args...)
+	cmd.Dir = e.workdir
+	if env == nil {
+		// We don't want to passthrough host env when unset.
+		env = []string{}
+	}
+	cmd.Env = env
+
+	// We want logs to be written in the correct order, so we wrap all logging
+	// in a sync.Mutex.
+	mut := &sync.Mutex{}
+	cmd.Stdout = syncWriter{mut, stdOutWriter}
+	cmd.Stderr = syncWriter{mut, stdErrWriter}
+
+	e.logger.Debug(ctx, "executing pulumi command",
+		slog.F("binary_path", e.binaryPath),
+		slog.F("args", redactArgs(args)),
+	)
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+	interruptCommandOnCancel(ctx, killCtx, e.logger, cmd)
+
+	err = cmd.Wait()
+	e.logger.Debug(ctx, "command done", slog.F("args", redactArgs(args)), slog.Error(err))
+	return err
+}
+
+// execOutput must only be called while the lock is held.
+func (e *executor) execOutput(ctx, killCtx context.Context, args, env []string) ([]byte, error) {
+	return e.execOutputStdin(ctx, killCtx, args, env, nil)
+}
+
+// execOutputStdin is like execOutput, but passes stdin to the command.
+//
+// execOutputStdin must only be called while the lock is held.
+func (e *executor) execOutputStdin(ctx, killCtx context.Context, args, env []string, stdin io.Reader) ([]byte, error) {
+	ctx, span := e.server.startTrace(ctx, fmt.Sprintf("exec - pulumi %s", args[0]))
+	defer span.End()
+	span.SetAttributes(attribute.StringSlice("args", redactArgs(args)))
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if provisioner.IsCanarySet(env) {
+		return nil, xerrors.New("environment variables not sanitized, this is a bug within Coder")
+	}
+
+	// #nosec
+	cmd := exec.CommandContext(killCtx, e.binaryPath, args...)
+	cmd.Dir = e.workdir
+	cmd.Env = env
+	cmd.Stdin = stdin
+	out := &bytes.Buffer{}
+	stdErr := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = stdErr
+
+	e.logger.Debug(ctx, "executing pulumi command with output",
+		slog.F("binary_path", e.binaryPath),
+		slog.F("args", redactArgs(args)),
+	)
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	interruptCommandOnCancel(ctx, killCtx, e.logger, cmd)
+
+	err = cmd.Wait()
+	if err != nil {
+		return nil, xerrors.Errorf("%s: %w", strings.TrimSpace(stdErr.String()), err)
+	}
+	return out.Bytes(), nil
+}
+
+// setupStack creates the stack, imports the state of the previous build and
+// sets the stack config.
+func (e *executor) setupStack(ctx, killCtx context.Context, state []byte, config []configValue) error {
+	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	err := os.MkdirAll(filepath.Join(e.workdir, backendDirName), 0o700)
+	if err != nil {
+		return xerrors.Errorf("create backend directory: %w", err)
+	}
+	env := e.basicEnv()
+	_, err = e.execOutput(ctx, killCtx, []string{"stack", "select", "--create", "--non-interactive", stackName}, env)
+	if err != nil {
+		return xerrors.Errorf("create stack: %w", err)
+	}
+
+	if len(state) > 0 {
+		statePath := filepath.Join(e.workdir, backendDirName, "import.json")
+		err = os.WriteFile(statePath, state, 0o600)
+		if err != nil {
+			return xerrors.Errorf("write state %q: %w", statePath, err)
+		}
+		_, err = e.execOutput(ctx, killCtx, []string{"stack", "import", "--stack", stackName, "--non-interactive", "--file", statePath}, env)
+		if err != nil {
+			return xerrors.Errorf("import state: %w", err)
+		}
+	}
+
+	args := []string{"config", "set-all", "--stack", stackName, "--non-interactive"}
+	plaintext := false
+	for _, c := range config {
+		if c.secret {
+			// Secrets are read from stdin, so they don't show up in the
+			// arguments of the process.
+			_, err = e.execOutputStdin(ctx, killCtx, []string{"config", "set", "--stack", stackName, "--non-interactive", "--secret", c.key}, env, strings.NewReader(c.value))
+			if err != nil {
+				return xerrors.Errorf("set secret config %q: %w", c.key, err)
+			}
+			continue
+		}
+		plaintext = true
+		args = append(args, "--plaintext", c.key+"="+c.value)
+	}
+	if !plaintext {
+		return nil
+	}
+	_, err = e.execOutput(ctx, killCtx, args, env)
+	if err != nil {
+		return xerrors.Errorf("set config: %w", err)
+	}
+	return nil
+}
+
+// redactArgs returns the arguments of a command with config values removed,
+// so they can be logged.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && args[i-1] == "--plaintext" {
+			key, _, _ := strings.Cut(arg, "=")
+			arg = key + "=[redacted]"
+		}
+		redacted[i] = arg
+	}
+	return redacted
+}
+
+// run runs an operation that streams its output as logs and reports engine
+// events to the event log.
+//
+// run must only be called while the lock is held.
+func (e *executor) run(ctx, killCtx context.Context, args, env []string, logr logSink) (*eventLog, error) {
+	eventLogPath := e.eventLogPath()
+	_ = os.Remove(eventLogPath)
+	args = append(args,
+		"--stack", stackName,
+		"--non-interactive",
+		"--color", "never",
+		"--event-log", eventLogPath,
+	)
+
+	outWriter, doneOut := logWriter(logr, proto.LogLevel_INFO)
+	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
+	defer func() {
+		_ = outWriter.Close()
+		_ = errWriter.Close()
+		<-doneOut
+		<-doneErr
+	}()
+
+	runErr := e.execWriteOutput(ctx, killCtx, args, env, outWriter, errWriter)
+
+	// The event log is still useful for timings if the operation failed.
+	events := &eventLog{}
+	f, err := os.Open(eventLogPath)
+	if err == nil {
+		defer f.Close()
+		events, err = parseEventLog(f, e.stage)
+	}
+	if err != nil {
+		e.logger.Warn(ctx, "failed to read pulumi event log", slog.Error(err))
+		events = &eventLog{}
+	}
+	if runErr != nil {
+		return events, xerrors.Errorf("pulumi %s: %w", args[0], runErr)
+	}
+	return events, nil
+}
+
+func (e *executor) preview(ctx, killCtx context.Context, env []string, logr logSink, destroy bool) (*proto.PlanComplete, error) {
+	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	args := []string{"preview"}
+	if destroy {
+		args = []string{"destroy", "--preview-only"}
+	}
+	events, err := e.run(ctx, killCtx, args, env, logr)
+	if err != nil {
+		return nil, err
+	}
+	if destroy {
+		return &proto.PlanComplete{Timings: events.Timings}, nil
+	}
+	state, err := ConvertState(events.Resources, events.Outputs)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.PlanComplete{
+		Resources:  state.Resources,
+		Parameters: state.Parameters,
+		Timings:    events.Timings,
+	}, nil
+}
+
+func (e *executor) update(ctx, killCtx context.Context, env []string, logr logSink, destroy bool) (*proto.ApplyComplete, error) {
+	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	args := []string{"up", "--yes", "--skip-preview"}
+	if destroy {
+		args = []string{"destroy", "--yes", "--skip-preview"}
+	}
+	events, err := e.run(ctx, killCtx, args, env, logr)
+	if err != nil {
+		return nil, err
+	}
+	stateContent, err := e.exportState(ctx, killCtx)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := parseDeploymentResources(stateContent)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := e.stackOutputs(ctx, killCtx)
+	if err != nil {
+		return nil, err
+	}
+	state, err := ConvertState(resources, outputs)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.ApplyComplete{
+		State:      stateContent,
+		Resources:  state.Resources,
+		Parameters: state.Parameters,
+		Timings:    events.Timings,
+	}, nil
+}
+
+// exportState returns the state of the stack, with secrets in plaintext so
+// it can be imported into a stack with a different encryption salt.
+//
+// exportState must only be called while the lock is held.
+func (e *executor) exportState(ctx, killCtx context.Context) ([]byte, error) {
+	out, err := e.execOutput(ctx, killCtx, []string{"stack", "export", "--show-secrets", "--stack", stackName}, e.basicEnv())
+	if err != nil {
+		return nil, xerrors.Errorf("export state: %w", err)
+	}
+	return out, nil
+}
+
+// stackOutputs must only be called while the lock is held.
+func (e *executor) stackOutputs(ctx, killCtx context.Context) (map[string]any, error) {
+	out, err := e.execOutput(ctx, killCtx, []string{"stack", "output", "--json", "--show-secrets", "--stack", stackName}, e.basicEnv())
+	if err != nil {
+		return nil, xerrors.Errorf("stack outputs: %w", err)
+	}
+	outputs := map[string]any{}
+	err = json.Unmarshal(out, &outputs)
+	if err != nil {
+		return nil, xerrors.Errorf("decode stack outputs: %w", err)
+	}
+	return outputs, nil
+}
+
+// parseDeploymentResources returns the resources in a stack export.
+func parseDeploymentResources(state []byte) ([]pulumiResource, error) {
+	var export struct {
+		Deployment struct {
+			Resources []pulumiResource `json:"resources"`
+		} `json:"deployment"`
+	}
+	err := json.Unmarshal(state, &export)
+	if err != nil {
+		return nil, xerrors.Errorf("decode state: %w", err)
+	}
+	return export.Deployment.Resources, nil
+}
+
+func interruptCommandOnCancel(ctx, killCtx context.Context, logger slog.Logger, cmd *exec.Cmd) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			var err error
+			switch runtime.GOOS {
+			case "windows":
+				// Interrupts aren't supported by Windows.
+				err = cmd.Process.Kill()
+			default:
+				err = cmd.Process.Signal(os.Interrupt)
+			}
+			logger.Debug(ctx, "interrupted command", slog.F("args", redactArgs(cmd.Args)), slog.Error(err))
+
+		case <-killCtx.Done():
+			logger.Debug(ctx, "kill context ended", slog.F("args", redactArgs(cmd.Args)))
+		}
+	}()
+}
+
+type logSink interface {
+	ProvisionLog(l proto.LogLevel, o string)
+}
+
+// logWriter creates a WriteCloser that will log each line of text at the given level.  The WriteCloser must be closed
+// by the caller to end logging, after which the returned channel will be closed to indicate that logging of the written
+// data has finished.  Failure to close the WriteCloser will leak a goroutine.
+func logWriter(sink logSink, level proto.LogLevel) (io.WriteCloser, <-chan any) {
+	r, w := io.Pipe()
+	done := make(chan any)
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+			sink.ProvisionLog(level, scanner.Text())
+		}
+	}()
+	return w, done
+}
+
+type syncWriter struct {
+	mut *sync.Mutex
+	w   io.Writer
+}
+
+func (sw syncWriter) Write(p []byte) (n int, err error) {
+	sw.mut.Lock()
+	defer sw.mut.Unlock()
+	return sw.w.Write(p)
+}
@@ -0,0 +1,149 @@
+package pulumi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/provisionersdk"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+// projectFileNames are the names Pulumi accepts for the project file, in the
+// order it looks for them.
+var projectFileNames = []string{"Pulumi.yaml", "Pulumi.yml"}
+
+// project is the subset of the Pulumi project file we care about.
+type project struct {
+	Name    string               `yaml:"name"`
+	Runtime any                  `yaml:"runtime"`
+	Config  map[string]yaml.Node `yaml:"config"`
+}
+
+// projectConfigType is a typed config declaration in the project file.
+type projectConfigType struct {
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+	Default     any    `yaml:"default"`
+	Value       any    `yaml:"value"`
+	Secret      bool   `yaml:"secret"`
+}
+
+// Parse extracts template variables from the config declared in the Pulumi
+// project file.
+func (s *server) Parse(sess *provisionersdk.Session, _ *proto.ParseRequest, _ <-chan struct{}) *proto.ParseComplete {
+	ctx := sess.Context()
+	_, span := s.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	proj, err := readProject(sess.WorkDirectory)
+	if err != nil {
+		return provisionersdk.ParseErrorf("%s", err)
+	}
+	templateVariables, err := proj.templateVariables()
+	if err != nil {
+		return provisionersdk.ParseErrorf("can't load template variables: %v", err)
+	}
+	return &proto.ParseComplete{
+		TemplateVariables: templateVariables,
+	}
+}
+
+func readProject(dir string) (*project, error) {
+	for _, name := range projectFileNames {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("read %s: %w", name, err)
+		}
+		var proj project
+		err = yaml.Unmarshal(data, &proj)
+		if err != nil {
+			return nil, xerrors.Errorf("parse %s: %w", name, err)
+		}
+		if proj.Name == "" {
+			return nil, xerrors.Errorf("%s: project name is required", name)
+		}
+		if proj.Runtime == nil {
+			return nil, xerrors.Errorf("%s: project runtime is required", name)
+		}
+		return &proj, nil
+	}
+	return nil, xerrors.New("no Pulumi.yaml project file found in the template")
+}
+
+// templateVariables converts project level config into template variables.
+// Config is either a typed declaration or a bare default value.
+func (p *project) templateVariables() ([]*proto.TemplateVariable, error) {
+	names := make([]string, 0, len(p.Config))
+	for name := range p.Config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	variables := make([]*proto.TemplateVariable, 0, len(names))
+	for _, name := range names {
+		node := p.Config[name]
+		decl := projectConfigType{}
+		if node.Kind == yaml.MappingNode {
+			err := node.Decode(&decl)
+			if err != nil {
+				return nil, xerrors.Errorf("config %q: %w", name, err)
+			}
+		} else {
+			var value any
+			err := node.Decode(&value)
+			if err != nil {
+				return nil, xerrors.Errorf("config %q: %w", name, err)
+			}
+			decl.Default = value
+		}
+		if decl.Default == nil {
+			decl.Default = decl.Value
+		}
+
+		variable := &proto.TemplateVariable{
+			Name:        name,
+			Description: decl.Description,
+			Sensitive:   decl.Secret,
+			Required:    decl.Default == nil,
+		}
+		switch decl.Type {
+		case "", "string":
+			variable.Type = "string"
+		case "integer":
+			variable.Type = "number"
+		case "boolean":
+			variable.Type = "bool"
+		default:
+			return nil, xerrors.Errorf("config %q: type %q is not supported, use string, integer or boolean", name, decl.Type)
+		}
+		if decl.Default != nil {
+			variable.DefaultValue = configString(decl.Default)
+		}
+		variables = append(variables, variable)
+	}
+	return variables, nil
+}
+
+// configString formats a config value the way `pulumi config set` expects it.
+func configString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool, int, int64, float64:
+		return fmt.Sprint(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,260 @@
+package pulumi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/terraform-provider-coder/v2/provider"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/provisionersdk"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+// stateExportTimeout bounds exporting the state of a failed update.
+const stateExportTimeout = time.Minute
+
+func (s *server) setupContexts(parent context.Context, canceledOrComplete <-chan struct{}) (
+	ctx context.Context, cancel func(), killCtx context.Context, kill func(),
+) {
+	// Create a context for graceful cancellation bound to the session
+	// context. This ensures that we will perform graceful cancellation
+	// even on connection loss.
+	ctx, cancel = context.WithCancel(parent)
+
+	// Create a separate context for forceful cancellation not tied to
+	// the stream so that we can control when to terminate the process.
+	killCtx, kill = context.WithCancel(context.Background())
+
+	// Ensure processes are eventually cleaned up on graceful
+	// cancellation or disconnect.
+	go func() {
+		<-ctx.Done()
+		t := time.NewTimer(s.exitTimeout)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			kill()
+		case <-killCtx.Done():
+		}
+	}()
+
+	// Process cancel
+	go func() {
+		<-canceledOrComplete
+		cancel()
+	}()
+	return ctx, cancel, killCtx, kill
+}
+
+func (s *server) Plan(
+	sess *provisionersdk.Session, request *proto.PlanRequest, canceledOrComplete <-chan struct{},
+) *proto.PlanComplete {
+	ctx, span := s.startTrace(sess.Context(), tracing.FuncName())
+	defer span.End()
+	ctx, cancel, killCtx, kill := s.setupContexts(ctx, canceledOrComplete)
+	defer cancel()
+	defer kill()
+
+	if request.RefreshOnly {
+		return provisionersdk.PlanErrorf("drift detection is not supported by the pulumi provisioner")
+	}
+
+	destroy := request.Metadata.GetWorkspaceTransition() == proto.WorkspaceTransition_DESTROY
+	// If we're destroying, exit early if there's no state, so a workspace
+	// that never built can always be deleted.
+	if destroy && len(sess.Config.State) == 0 {
+		sess.ProvisionLog(proto.LogLevel_INFO, "The pulumi state does not exist, there is nothing to do")
+		return &proto.PlanComplete{}
+	}
+
+	e := s.executor(sess.WorkDirectory, database.ProvisionerJobTimingStagePlan)
+	config, err := stackConfig(request)
+	if err != nil {
+		return provisionersdk.PlanErrorf("stack config: %s", err)
+	}
+	err = e.setupStack(ctx, killCtx, sess.Config.State, config)
+	if err != nil {
+		return provisionersdk.PlanErrorf("setup stack: %s", err)
+	}
+
+	// Unlike a Terraform plan, a preview doesn't capture the values the
+	// program read, so parameters and external auth tokens are saved for
+	// the update to read them again.
+	buildEnv := buildEnv(request.RichParameterValues, request.ExternalAuthProviders)
+	err = e.saveBuildEnv(buildEnv)
+	if err != nil {
+		return provisionersdk.PlanErrorf("save env: %s", err)
+	}
+	env, err := provisionEnv(e.basicEnv(), request.Metadata)
+	if err != nil {
+		return provisionersdk.PlanErrorf("setup env: %s", err)
+	}
+	env = append(env, buildEnv...)
+	resp, err := e.preview(ctx, killCtx, env, sess, destroy)
+	if err != nil {
+		return provisionersdk.PlanErrorf("%s", err.Error())
+	}
+	return resp
+}
+
+func (s *server) Apply(
+	sess *provisionersdk.Session, request *proto.ApplyRequest, canceledOrComplete <-chan struct{},
+) *proto.ApplyComplete {
+	ctx, span := s.startTrace(sess.Context(), tracing.FuncName())
+	defer span.End()
+	ctx, cancel, killCtx, kill := s.setupContexts(ctx, canceledOrComplete)
+	defer cancel()
+	defer kill()
+
+	destroy := request.Metadata.GetWorkspaceTransition() == proto.WorkspaceTransition_DESTROY
+	if destroy && len(sess.Config.State) == 0 {
+		sess.ProvisionLog(proto.LogLevel_INFO, "The pulumi state does not exist, there is nothing to do")
+		return &proto.ApplyComplete{}
+	}
+
+	// Earlier in the session, Plan() will have created the stack and set
+	// its config.
+	e := s.executor(sess.WorkDirectory, database.ProvisionerJobTimingStageApply)
+	env, err := provisionEnv(e.basicEnv(), request.Metadata)
+	if err != nil {
+		return provisionersdk.ApplyErrorf("provision env: %s", err)
+	}
+	buildEnv, err := e.loadBuildEnv()
+	if err != nil {
+		return provisionersdk.ApplyErrorf("load env: %s", err)
+	}
+	env = append(env, buildEnv...)
+	resp, err := e.update(ctx, killCtx, env, sess, destroy)
+	if err != nil {
+		// Pulumi can fail an update and still need to store its state. The
+		// update may have failed because it was canceled, so the state is
+		// exported with a fresh context.
+		exportCtx, cancelExport := context.WithTimeout(context.Background(), stateExportTimeout)
+		defer cancelExport()
+		e.mut.Lock()
+		state, exportErr := e.exportState(exportCtx, exportCtx)
+		e.mut.Unlock()
+		if exportErr != nil {
+			s.logger.Warn(ctx, "failed to export state of failed update", slog.Error(exportErr))
+		}
+		return &proto.ApplyComplete{
+			State: state,
+			Error: err.Error(),
+		}
+	}
+	return resp
+}
+
+// stackConfig returns the config to set on the stack before a preview.
+// Template variables are set in the project namespace. Workspace metadata,
+// agent init scripts and parameter values are set in the "coder" namespace,
+// so programs in any language, including YAML, can read them.
+func stackConfig(request *proto.PlanRequest) ([]configValue, error) {
+	metadata := request.Metadata
+	config := make([]configValue, 0, len(request.VariableValues)+len(request.RichParameterValues)+16)
+	for _, v := range request.VariableValues {
+		config = append(config, configValue{key: v.Name, value: v.Value, secret: v.Sensitive})
+	}
+
+	coderValues := map[string]string{
+		"url":                 metadata.GetCoderUrl(),
+		"transition":          strings.ToLower(metadata.GetWorkspaceTransition().String()),
+		"workspaceId":         metadata.GetWorkspaceId(),
+		"workspaceName":       metadata.GetWorkspaceName(),
+		"workspaceOwner":      metadata.GetWorkspaceOwner(),
+		"workspaceOwnerId":    metadata.GetWorkspaceOwnerId(),
+		"workspaceOwnerName":  metadata.GetWorkspaceOwnerName(),
+		"workspaceOwnerEmail": metadata.GetWorkspaceOwnerEmail(),
+		"templateId":          metadata.GetTemplateId(),
+		"templateName":        metadata.GetTemplateName(),
+		"templateVersion":     metadata.GetTemplateVersion(),
+		"buildId":             metadata.GetWorkspaceBuildId(),
+		"isPrebuild":          strconv.FormatBool(metadata.GetIsPrebuild()),
+	}
+	// The init scripts are templated the same way the Coder Terraform
+	// provider does for an agent with token auth.
+	accessURL := ""
+	if metadata.GetCoderUrl() != "" {
+		u, err := url.Parse(metadata.GetCoderUrl())
+		if err != nil {
+			return nil, xerrors.Errorf("parse coder url: %w", err)
+		}
+		accessURL = u.JoinPath("/").String()
+	}
+	for key, script := range provisionersdk.AgentScriptEnv() {
+		// CODER_AGENT_SCRIPT_<os>_<arch> becomes initScript-<os>-<arch>.
+		platform := strings.ReplaceAll(strings.TrimPrefix(key, "CODER_AGENT_SCRIPT_"), "_", "-")
+		script = strings.ReplaceAll(script, "${ACCESS_URL}", accessURL)
+		script = strings.ReplaceAll(script, "${AUTH_TYPE}", "token")
+		coderValues["initScript-"+platform] = script
+	}
+	for _, p := range request.RichParameterValues {
+		coderValues["parameter-"+p.Name] = p.Value
+	}
+	keys := make([]string, 0, len(coderValues))
+	for key := range coderValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		config = append(config, configValue{key: coderOutputName + ":" + key, value: coderValues[key]})
+	}
+	return config, nil
+}
+
+// provisionEnv sets the same environment variables as the terraform
+// provisioner, for programs that prefer them to stack config.
+func provisionEnv(env []string, metadata *proto.Metadata) ([]string, error) {
+	ownerGroups, err := json.Marshal(metadata.GetWorkspaceOwnerGroups())
+	if err != nil {
+		return nil, xerrors.Errorf("marshal owner groups: %w", err)
+	}
+
+	env = append(env,
+		"CODER_AGENT_URL="+metadata.GetCoderUrl(),
+		"CODER_WORKSPACE_TRANSITION="+strings.ToLower(metadata.GetWorkspaceTransition().String()),
+		"CODER_WORKSPACE_NAME="+metadata.GetWorkspaceName(),
+		"CODER_WORKSPACE_OWNER="+metadata.GetWorkspaceOwner(),
+		"CODER_WORKSPACE_OWNER_EMAIL="+metadata.GetWorkspaceOwnerEmail(),
+		"CODER_WORKSPACE_OWNER_NAME="+metadata.GetWorkspaceOwnerName(),
+		"CODER_WORKSPACE_OWNER_OIDC_ACCESS_TOKEN="+metadata.GetWorkspaceOwnerOidcAccessToken(),
+		"CODER_WORKSPACE_OWNER_GROUPS="+string(ownerGroups),
+		"CODER_WORKSPACE_OWNER_SSH_PUBLIC_KEY="+metadata.GetWorkspaceOwnerSshPublicKey(),
+		"CODER_WORKSPACE_OWNER_SSH_PRIVATE_KEY="+metadata.GetWorkspaceOwnerSshPrivateKey(),
+		"CODER_WORKSPACE_OWNER_LOGIN_TYPE="+metadata.GetWorkspaceOwnerLoginType(),
+		"CODER_WORKSPACE_ID="+metadata.GetWorkspaceId(),
+		"CODER_WORKSPACE_OWNER_ID="+metadata.GetWorkspaceOwnerId(),
+		"CODER_WORKSPACE_OWNER_SESSION_TOKEN="+metadata.GetWorkspaceOwnerSessionToken(),
+		"CODER_WORKSPACE_TEMPLATE_ID="+metadata.GetTemplateId(),
+		"CODER_WORKSPACE_TEMPLATE_NAME="+metadata.GetTemplateName(),
+		"CODER_WORKSPACE_TEMPLATE_VERSION="+metadata.GetTemplateVersion(),
+		"CODER_WORKSPACE_BUILD_ID="+metadata.GetWorkspaceBuildId(),
+	)
+	for key, value := range provisionersdk.AgentScriptEnv() {
+		env = append(env, key+"="+value)
+	}
+	return env, nil
+}
+
+// buildEnv returns the environment variables for the parameter values and
+// external auth tokens of a build.
+func buildEnv(richParams []*proto.RichParameterValue, externalAuth []*proto.ExternalAuthProvider) []string {
+	env := make([]string, 0, len(richParams)+len(externalAuth))
+	for _, param := range richParams {
+		env = append(env, provider.ParameterEnvironmentVariable(param.Name)+"="+param.Value)
+	}
+	for _, extAuth := range externalAuth {
+		env = append(env, provider.ExternalAuthAccessTokenEnvironmentVariable(extAuth.Id)+"="+extAuth.AccessToken)
+	}
+	return env
+}
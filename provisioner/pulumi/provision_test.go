@@ -0,0 +1,218 @@
+//go:build linux || darwin
+
+package pulumi_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/codersdk/drpc"
+	"github.com/coder/coder/v2/provisioner/pulumi"
+	"github.com/coder/coder/v2/provisionersdk"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+	"github.com/coder/coder/v2/testutil"
+)
+
+const projectFile = `name: test
+runtime: yaml
+config:
+  image:
+    type: string
+    default: ubuntu
+    description: The container image.
+`
+
+func setupProvisioner(t *testing.T) (context.Context, proto.DRPCProvisionerClient) {
+	t.Helper()
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	client, server := drpc.MemTransportPipe()
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	serverErr := make(chan error, 1)
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+		cancelFunc()
+		err := <-serverErr
+		if !errors.Is(err, context.Canceled) {
+			assert.NoError(t, err)
+		}
+	})
+	go func() {
+		serverErr <- pulumi.Serve(ctx, &pulumi.ServeOptions{
+			ServeOptions: &provisionersdk.ServeOptions{
+				Listener:      server,
+				Logger:        testutil.Logger(t),
+				WorkDirectory: t.TempDir(),
+			},
+			BinaryPath: filepath.Join(cwd, "testdata", "fake_pulumi.sh"),
+			CachePath:  t.TempDir(),
+		})
+	}()
+	return ctx, proto.NewDRPCProvisionerClient(client)
+}
+
+func configure(ctx context.Context, t *testing.T, client proto.DRPCProvisionerClient, state []byte) proto.DRPCProvisioner_SessionClient {
+	t.Helper()
+	sess, err := client.Session(ctx)
+	require.NoError(t, err)
+	err = sess.Send(&proto.Request{Type: &proto.Request_Config{Config: &proto.Config{
+		TemplateSourceArchive: testutil.CreateTar(t, map[string]string{"Pulumi.yaml": projectFile}),
+		State:                 state,
+	}}})
+	require.NoError(t, err)
+	return sess
+}
+
+// recv returns the next response that isn't a log, and the logs before it.
+func recv(t *testing.T, sess proto.DRPCProvisioner_SessionClient) (*proto.Response, string) {
+	t.Helper()
+	var logs strings.Builder
+	for {
+		msg, err := sess.Recv()
+		require.NoError(t, err)
+		if log := msg.GetLog(); log != nil {
+			_, _ = logs.WriteString(log.Output + "\n")
+			continue
+		}
+		return msg, logs.String()
+	}
+}
+
+func plan(t *testing.T, sess proto.DRPCProvisioner_SessionClient, transition proto.WorkspaceTransition) (*proto.PlanComplete, string) {
+	t.Helper()
+	return planWorkspace(t, sess, transition, "dev-workspace")
+}
+
+func planWorkspace(t *testing.T, sess proto.DRPCProvisioner_SessionClient, transition proto.WorkspaceTransition, workspaceName string) (*proto.PlanComplete, string) {
+	t.Helper()
+	err := sess.Send(&proto.Request{Type: &proto.Request_Plan{Plan: &proto.PlanRequest{
+		Metadata: &proto.Metadata{
+			WorkspaceTransition: transition,
+			WorkspaceName:       workspaceName,
+		},
+		VariableValues:      []*proto.VariableValue{{Name: "image", Value: "s3cret", Sensitive: true}},
+		RichParameterValues: []*proto.RichParameterValue{{Name: "region", Value: "eu"}},
+	}}})
+	require.NoError(t, err)
+	msg, logs := recv(t, sess)
+	require.NotNil(t, msg.GetPlan())
+	return msg.GetPlan(), logs
+}
+
+func apply(t *testing.T, sess proto.DRPCProvisioner_SessionClient, transition proto.WorkspaceTransition) (*proto.ApplyComplete, string) {
+	t.Helper()
+	err := sess.Send(&proto.Request{Type: &proto.Request_Apply{Apply: &proto.ApplyRequest{
+		Metadata: &proto.Metadata{WorkspaceTransition: transition},
+	}}})
+	require.NoError(t, err)
+	msg, logs := recv(t, sess)
+	require.NotNil(t, msg.GetApply())
+	return msg.GetApply(), logs
+}
+
+// nolint: paralleltest // Executing the fake binary concurrently can fail with "text file busy".
+func TestProvision(t *testing.T) {
+	ctx, client := setupProvisioner(t)
+
+	t.Run("Parse", func(t *testing.T) {
+		sess := configure(ctx, t, client, nil)
+		err := sess.Send(&proto.Request{Type: &proto.Request_Parse{Parse: &proto.ParseRequest{}}})
+		require.NoError(t, err)
+		msg, _ := recv(t, sess)
+		require.Empty(t, msg.GetParse().GetError())
+		require.Len(t, msg.GetParse().TemplateVariables, 1)
+		require.Equal(t, "image", msg.GetParse().TemplateVariables[0].Name)
+		require.Equal(t, "ubuntu", msg.GetParse().TemplateVariables[0].DefaultValue)
+	})
+
+	var state []byte
+	t.Run("Start", func(t *testing.T) {
+		sess := configure(ctx, t, client, nil)
+		planned, logs := plan(t, sess, proto.WorkspaceTransition_START)
+		require.Empty(t, planned.Error)
+		require.Contains(t, logs, "Previewing update")
+		require.Contains(t, logs, "Workspace name set")
+		// Sensitive variables are set without passing them as arguments.
+		require.Contains(t, logs, "Secret set")
+		require.NotContains(t, logs, "Secret in arguments")
+		require.NotContains(t, logs, "Existing state imported")
+		require.Len(t, planned.Resources, 1)
+		require.Equal(t, "dev", planned.Resources[0].Name)
+		require.Len(t, planned.Resources[0].Agents, 1)
+		require.Equal(t, "main", planned.Resources[0].Agents[0].Name)
+		require.Len(t, planned.Parameters, 1)
+		require.Equal(t, "region", planned.Parameters[0].Name)
+		require.Len(t, planned.Timings, 1)
+		require.Equal(t, "plan", planned.Timings[0].Stage)
+
+		applied, logs := apply(t, sess, proto.WorkspaceTransition_START)
+		require.Empty(t, applied.Error)
+		require.Contains(t, logs, "Updating")
+		require.Len(t, applied.Resources, 1)
+		require.Len(t, applied.Resources[0].Agents, 1)
+		agent := applied.Resources[0].Agents[0]
+		require.Equal(t, "secret-token", agent.GetToken())
+		require.Len(t, agent.Apps, 1)
+		require.Equal(t, proto.AppSharingLevel_AUTHENTICATED, agent.Apps[0].SharingLevel)
+		require.Len(t, applied.Timings, 1)
+		require.Equal(t, "apply", applied.Timings[0].Stage)
+		require.Equal(t, proto.TimingState_COMPLETED, applied.Timings[0].State)
+		require.Contains(t, string(applied.State), "docker:index/container:Container")
+		state = applied.State
+	})
+
+	t.Run("Destroy", func(t *testing.T) {
+		require.NotEmpty(t, state)
+		sess := configure(ctx, t, client, state)
+		planned, logs := plan(t, sess, proto.WorkspaceTransition_DESTROY)
+		require.Empty(t, planned.Error)
+		require.Contains(t, logs, "Previewing destroy")
+
+		applied, _ := apply(t, sess, proto.WorkspaceTransition_DESTROY)
+		require.Empty(t, applied.Error)
+		require.Empty(t, applied.Resources)
+		require.NotContains(t, string(applied.State), "docker:index/container:Container")
+	})
+
+	t.Run("CanceledUpdate", func(t *testing.T) {
+		sess := configure(ctx, t, client, nil)
+		planned, _ := planWorkspace(t, sess, proto.WorkspaceTransition_START, "slow-workspace")
+		require.Empty(t, planned.Error)
+
+		err := sess.Send(&proto.Request{Type: &proto.Request_Apply{Apply: &proto.ApplyRequest{
+			Metadata: &proto.Metadata{WorkspaceTransition: proto.WorkspaceTransition_START},
+		}}})
+		require.NoError(t, err)
+		for {
+			msg, err := sess.Recv()
+			require.NoError(t, err)
+			if strings.Contains(msg.GetLog().GetOutput(), "Waiting for cancel") {
+				break
+			}
+		}
+		err = sess.Send(&proto.Request{Type: &proto.Request_Cancel{Cancel: &proto.CancelRequest{}}})
+		require.NoError(t, err)
+
+		// The state is still exported after the update was canceled.
+		msg, _ := recv(t, sess)
+		applied := msg.GetApply()
+		require.NotNil(t, applied)
+		require.NotEmpty(t, applied.Error)
+		require.Contains(t, string(applied.State), "docker:index/container:Container")
+	})
+
+	t.Run("DestroyNoState", func(t *testing.T) {
+		sess := configure(ctx, t, client, nil)
+		_, logs := plan(t, sess, proto.WorkspaceTransition_DESTROY)
+		require.Contains(t, logs, "nothing to do")
+	})
+}
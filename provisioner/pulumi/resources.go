@@ -0,0 +1,332 @@
+package pulumi
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/provisionersdk"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+// coderOutputName is the stack output templates use to describe their
+// agents and parameters, since there's no Coder provider for Pulumi.
+const coderOutputName = "coder"
+
+const (
+	// unknownValue is the sentinel Pulumi uses for values that aren't known
+	// until the update runs.
+	unknownValue = "04da6b54-80e4-46f7-96ec-b56ff0331ba9"
+	// secretSigKey and secretSig mark an object as a secret value.
+	secretSigKey = "4dabf18193072939515e22adb298388d"
+	secretSig    = "1b47061264138c4ac30d75fd1eb44270"
+)
+
+// coderOutput is the shape of the "coder" stack output.
+type coderOutput struct {
+	Agents     []agentOutput     `json:"agents"`
+	Parameters []parameterOutput `json:"parameters"`
+}
+
+type agentOutput struct {
+	Name string `json:"name"`
+	// Resource is the logical name of the resource the agent runs in.
+	Resource string `json:"resource"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	// Token must be passed to the agent as CODER_AGENT_TOKEN. If InstanceID
+	// is set instead, the agent authenticates with its instance identity.
+	Token                    string            `json:"token"`
+	InstanceID               string            `json:"instanceId"`
+	Directory                string            `json:"directory"`
+	Env                      map[string]string `json:"env"`
+	Apps                     []appOutput       `json:"apps"`
+	ConnectionTimeoutSeconds int32             `json:"connectionTimeoutSeconds"`
+	TroubleshootingURL       string            `json:"troubleshootingUrl"`
+	MOTDFile                 string            `json:"motdFile"`
+	Order                    int64             `json:"order"`
+}
+
+type appOutput struct {
+	Slug        string `json:"slug"`
+	DisplayName string `json:"displayName"`
+	Command     string `json:"command"`
+	URL         string `json:"url"`
+	Icon        string `json:"icon"`
+	Subdomain   bool   `json:"subdomain"`
+	Share       string `json:"share"`
+	External    bool   `json:"external"`
+	Hidden      bool   `json:"hidden"`
+	Order       int64  `json:"order"`
+}
+
+type parameterOutput struct {
+	Name        string                  `json:"name"`
+	DisplayName string                  `json:"displayName"`
+	Description string                  `json:"description"`
+	Type        string                  `json:"type"`
+	Default     any                     `json:"default"`
+	Mutable     bool                    `json:"mutable"`
+	Ephemeral   bool                    `json:"ephemeral"`
+	Icon        string                  `json:"icon"`
+	Order       int32                   `json:"order"`
+	Options     []parameterOptionOutput `json:"options"`
+	Validation  *struct {
+		Regex     string `json:"regex"`
+		Error     string `json:"error"`
+		Min       *int32 `json:"min"`
+		Max       *int32 `json:"max"`
+		Monotonic string `json:"monotonic"`
+	} `json:"validation"`
+}
+
+type parameterOptionOutput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Value       any    `json:"value"`
+	Icon        string `json:"icon"`
+}
+
+// pulumiResource is the subset of a resource in a stack export, or in the
+// step metadata of an engine event.
+type pulumiResource struct {
+	URN     string         `json:"urn"`
+	Type    string         `json:"type"`
+	Custom  bool           `json:"custom"`
+	Delete  bool           `json:"delete"`
+	Outputs map[string]any `json:"outputs"`
+}
+
+// State is the Coder view of a Pulumi stack.
+type State struct {
+	Resources  []*proto.Resource
+	Parameters []*proto.RichParameter
+}
+
+// urnName returns the logical name of a resource from its URN, which has the
+// form urn:pulumi:<stack>::<project>::<qualified type>::<name>.
+func urnName(urn string) string {
+	parts := strings.SplitN(urn, "::", 4)
+	if len(parts) != 4 {
+		return urn
+	}
+	return parts[3]
+}
+
+// isInternalType reports whether the type is one of Pulumi's own resources,
+// like the stack or a provider, which aren't shown in Coder.
+func isInternalType(typ string) bool {
+	return strings.HasPrefix(typ, "pulumi:")
+}
+
+// ConvertState maps the resources of a stack and its outputs to the
+// resources, agents and parameters of a workspace.
+func ConvertState(resources []pulumiResource, outputs map[string]any) (*State, error) {
+	coder, err := decodeCoderOutput(outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{}
+	byName := map[string]*proto.Resource{}
+	for _, r := range resources {
+		if !r.Custom || r.Delete || isInternalType(r.Type) {
+			continue
+		}
+		resource := &proto.Resource{
+			Name: urnName(r.URN),
+			Type: r.Type,
+		}
+		// Names are only unique per type, so agents attach to the first
+		// resource with the name.
+		if _, ok := byName[resource.Name]; !ok {
+			byName[resource.Name] = resource
+		}
+		state.Resources = append(state.Resources, resource)
+	}
+
+	agentNames := map[string]struct{}{}
+	for _, a := range coder.Agents {
+		if a.Name == "" {
+			return nil, xerrors.New("agent name is required")
+		}
+		if _, ok := agentNames[a.Name]; ok {
+			return nil, xerrors.Errorf("duplicate agent name: %s", a.Name)
+		}
+		agentNames[a.Name] = struct{}{}
+		resource, ok := byName[a.Resource]
+		if !ok {
+			return nil, xerrors.Errorf("agent %q references resource %q, which is not in the stack", a.Name, a.Resource)
+		}
+		agent, err := convertAgent(a)
+		if err != nil {
+			return nil, err
+		}
+		resource.Agents = append(resource.Agents, agent)
+	}
+
+	for _, p := range coder.Parameters {
+		param, err := convertParameter(p)
+		if err != nil {
+			return nil, err
+		}
+		state.Parameters = append(state.Parameters, param)
+	}
+	return state, nil
+}
+
+func convertAgent(a agentOutput) (*proto.Agent, error) {
+	agent := &proto.Agent{
+		Id:                       uuid.NewString(),
+		Name:                     a.Name,
+		Env:                      a.Env,
+		OperatingSystem:          a.OS,
+		Architecture:             a.Arch,
+		Directory:                a.Directory,
+		ConnectionTimeoutSeconds: a.ConnectionTimeoutSeconds,
+		TroubleshootingUrl:       a.TroubleshootingURL,
+		MotdFile:                 a.MOTDFile,
+		DisplayApps:              provisionersdk.DefaultDisplayApps(),
+		Order:                    a.Order,
+	}
+	if agent.OperatingSystem == "" {
+		agent.OperatingSystem = "linux"
+	}
+	if agent.Architecture == "" {
+		agent.Architecture = "amd64"
+	}
+	if agent.ConnectionTimeoutSeconds == 0 {
+		agent.ConnectionTimeoutSeconds = 120
+	}
+	if a.InstanceID != "" {
+		agent.Auth = &proto.Agent_InstanceId{InstanceId: a.InstanceID}
+	} else {
+		agent.Auth = &proto.Agent_Token{Token: a.Token}
+	}
+	for _, app := range a.Apps {
+		if app.Slug == "" {
+			return nil, xerrors.Errorf("agent %q: app slug is required", a.Name)
+		}
+		sharingLevel := proto.AppSharingLevel_OWNER
+		switch app.Share {
+		case "", "owner":
+		case "authenticated":
+			sharingLevel = proto.AppSharingLevel_AUTHENTICATED
+		case "public":
+			sharingLevel = proto.AppSharingLevel_PUBLIC
+		default:
+			return nil, xerrors.Errorf("agent %q: app %q: unknown share level %q", a.Name, app.Slug, app.Share)
+		}
+		agent.Apps = append(agent.Apps, &proto.App{
+			Slug:         app.Slug,
+			DisplayName:  app.DisplayName,
+			Command:      app.Command,
+			Url:          app.URL,
+			Icon:         app.Icon,
+			Subdomain:    app.Subdomain,
+			SharingLevel: sharingLevel,
+			External:     app.External,
+			Hidden:       app.Hidden,
+			Order:        app.Order,
+		})
+	}
+	return agent, nil
+}
+
+func convertParameter(p parameterOutput) (*proto.RichParameter, error) {
+	if p.Name == "" {
+		return nil, xerrors.New("parameter name is required")
+	}
+	param := &proto.RichParameter{
+		Name:        p.Name,
+		DisplayName: p.DisplayName,
+		Description: p.Description,
+		Type:        p.Type,
+		Mutable:     p.Mutable,
+		Ephemeral:   p.Ephemeral,
+		Icon:        p.Icon,
+		Order:       p.Order,
+		Required:    p.Default == nil,
+	}
+	switch param.Type {
+	case "":
+		param.Type = "string"
+	case "string", "number", "bool", "list(string)":
+	default:
+		return nil, xerrors.Errorf("parameter %q: unsupported type %q", p.Name, p.Type)
+	}
+	if p.Default != nil {
+		param.DefaultValue = configString(p.Default)
+	}
+	for _, o := range p.Options {
+		param.Options = append(param.Options, &proto.RichParameterOption{
+			Name:        o.Name,
+			Description: o.Description,
+			Value:       configString(o.Value),
+			Icon:        o.Icon,
+		})
+	}
+	if v := p.Validation; v != nil {
+		param.ValidationRegex = v.Regex
+		param.ValidationError = v.Error
+		param.ValidationMin = v.Min
+		param.ValidationMax = v.Max
+		param.ValidationMonotonic = v.Monotonic
+	}
+	return param, nil
+}
+
+// decodeCoderOutput decodes the "coder" stack output. Secrets are unwrapped,
+// and values that are unknown during a preview are treated as unset.
+func decodeCoderOutput(outputs map[string]any) (coderOutput, error) {
+	var out coderOutput
+	raw, ok := outputs[coderOutputName]
+	if !ok {
+		return out, nil
+	}
+	data, err := json.Marshal(resolveValue(raw))
+	if err != nil {
+		return out, xerrors.Errorf("marshal %q output: %w", coderOutputName, err)
+	}
+	err = json.Unmarshal(data, &out)
+	if err != nil {
+		return out, xerrors.Errorf("decode %q output: %w", coderOutputName, err)
+	}
+	return out, nil
+}
+
+func resolveValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		if v == unknownValue {
+			return nil
+		}
+		return v
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, e := range v {
+			out = append(out, resolveValue(e))
+		}
+		return out
+	case map[string]any:
+		if v[secretSigKey] == secretSig {
+			if plaintext, ok := v["plaintext"].(string); ok {
+				var value any
+				if json.Unmarshal([]byte(plaintext), &value) == nil {
+					return resolveValue(value)
+				}
+				return nil
+			}
+			return resolveValue(v["value"])
+		}
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = resolveValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
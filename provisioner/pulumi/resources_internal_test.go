@@ -0,0 +1,190 @@
+package pulumi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+func TestConvertState(t *testing.T) {
+	t.Parallel()
+
+	resources := []pulumiResource{
+		{URN: "urn:pulumi:coder::proj::pulumi:pulumi:Stack::proj-coder", Type: "pulumi:pulumi:Stack"},
+		{URN: "urn:pulumi:coder::proj::pulumi:providers:aws::default", Type: "pulumi:providers:aws", Custom: true},
+		{URN: "urn:pulumi:coder::proj::aws:ec2/instance:Instance::dev", Type: "aws:ec2/instance:Instance", Custom: true},
+		{URN: "urn:pulumi:coder::proj::aws:ebs/volume:Volume::home", Type: "aws:ebs/volume:Volume", Custom: true},
+		{URN: "urn:pulumi:coder::proj::aws:ebs/volume:Volume::old", Type: "aws:ebs/volume:Volume", Custom: true, Delete: true},
+	}
+
+	t.Run("Agents", func(t *testing.T) {
+		t.Parallel()
+
+		var outputs map[string]any
+		err := json.Unmarshal([]byte(`{"coder": {"agents": [{
+			"name": "main",
+			"resource": "dev",
+			"arch": "arm64",
+			"token": {"4dabf18193072939515e22adb298388d": "1b47061264138c4ac30d75fd1eb44270", "plaintext": "\"secret\""},
+			"env": {"FOO": "bar"},
+			"apps": [{"slug": "code-server", "url": "http://localhost:8080", "share": "public"}]
+		}, {
+			"name": "other",
+			"resource": "home",
+			"instanceId": "i-123"
+		}]}}`), &outputs)
+		require.NoError(t, err)
+
+		state, err := ConvertState(resources, outputs)
+		require.NoError(t, err)
+		require.Len(t, state.Resources, 2)
+		require.Equal(t, "dev", state.Resources[0].Name)
+		require.Equal(t, "aws:ec2/instance:Instance", state.Resources[0].Type)
+		require.Equal(t, "home", state.Resources[1].Name)
+
+		require.Len(t, state.Resources[0].Agents, 1)
+		agent := state.Resources[0].Agents[0]
+		require.NotEmpty(t, agent.Id)
+		require.Equal(t, "main", agent.Name)
+		require.Equal(t, "linux", agent.OperatingSystem)
+		require.Equal(t, "arm64", agent.Architecture)
+		require.Equal(t, "secret", agent.GetToken())
+		require.Equal(t, map[string]string{"FOO": "bar"}, agent.Env)
+		require.True(t, agent.DisplayApps.WebTerminal)
+		require.Len(t, agent.Apps, 1)
+		require.Equal(t, proto.AppSharingLevel_PUBLIC, agent.Apps[0].SharingLevel)
+
+		require.Len(t, state.Resources[1].Agents, 1)
+		require.Equal(t, "i-123", state.Resources[1].Agents[0].GetInstanceId())
+	})
+
+	t.Run("Preview", func(t *testing.T) {
+		t.Parallel()
+
+		// Values that are only known after the update are left unset.
+		var outputs map[string]any
+		err := json.Unmarshal([]byte(`{"coder": {
+			"agents": [{"name": "main", "resource": "dev", "token": "04da6b54-80e4-46f7-96ec-b56ff0331ba9"}],
+			"parameters": [{"name": "size", "type": "number", "default": 10, "mutable": true, "validation": {"min": 1, "max": 100}}, {"name": "region"}]
+		}}`), &outputs)
+		require.NoError(t, err)
+
+		state, err := ConvertState(resources, outputs)
+		require.NoError(t, err)
+		require.Empty(t, state.Resources[0].Agents[0].GetToken())
+		require.Len(t, state.Parameters, 2)
+		require.Equal(t, "10", state.Parameters[0].DefaultValue)
+		require.False(t, state.Parameters[0].Required)
+		require.EqualValues(t, 1, state.Parameters[0].GetValidationMin())
+		require.EqualValues(t, 100, state.Parameters[0].GetValidationMax())
+		require.Equal(t, "string", state.Parameters[1].Type)
+		require.True(t, state.Parameters[1].Required)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct {
+			name    string
+			outputs string
+			err     string
+		}{
+			{"MissingResource", `{"coder": {"agents": [{"name": "main", "resource": "nope"}]}}`, "not in the stack"},
+			{"DeletedResource", `{"coder": {"agents": [{"name": "main", "resource": "old"}]}}`, "not in the stack"},
+			{"DuplicateAgent", `{"coder": {"agents": [{"name": "main", "resource": "dev"}, {"name": "main", "resource": "home"}]}}`, "duplicate agent name"},
+			{"BadShare", `{"coder": {"agents": [{"name": "main", "resource": "dev", "apps": [{"slug": "x", "share": "everyone"}]}]}}`, "unknown share level"},
+			{"BadParameterType", `{"coder": {"parameters": [{"name": "x", "type": "map"}]}}`, "unsupported type"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+				var outputs map[string]any
+				require.NoError(t, json.Unmarshal([]byte(tc.outputs), &outputs))
+				_, err := ConvertState(resources, outputs)
+				require.ErrorContains(t, err, tc.err)
+			})
+		}
+	})
+}
+
+func TestTemplateVariables(t *testing.T) {
+	t.Parallel()
+
+	var proj project
+	err := yaml.Unmarshal([]byte(`name: test
+runtime: go
+config:
+  region: us-east-1
+  instanceType:
+    type: string
+    default: t3.micro
+    description: The instance type.
+  count:
+    type: integer
+    default: 2
+  password:
+    type: string
+    secret: true
+  public:
+    type: boolean
+    value: false
+`), &proj)
+	require.NoError(t, err)
+
+	variables, err := proj.templateVariables()
+	require.NoError(t, err)
+	require.Equal(t, []*proto.TemplateVariable{
+		{Name: "count", Type: "number", DefaultValue: "2"},
+		{Name: "instanceType", Type: "string", DefaultValue: "t3.micro", Description: "The instance type."},
+		{Name: "password", Type: "string", Required: true, Sensitive: true},
+		{Name: "public", Type: "bool", DefaultValue: "false"},
+		{Name: "region", Type: "string", DefaultValue: "us-east-1"},
+	}, variables)
+
+	err = yaml.Unmarshal([]byte(`name: test
+runtime: go
+config:
+  tags:
+    type: array
+`), &proj)
+	require.NoError(t, err)
+	_, err = proj.templateVariables()
+	require.ErrorContains(t, err, "not supported")
+}
+
+func TestParseEventLog(t *testing.T) {
+	t.Parallel()
+
+	const events = `{"sequence":0,"timestamp":100,"preludeEvent":{"config":{}}}
+{"sequence":1,"timestamp":100,"resourcePreEvent":{"metadata":{"op":"same","urn":"urn:pulumi:coder::p::pulumi:pulumi:Stack::p-coder","type":"pulumi:pulumi:Stack","new":{"urn":"urn:pulumi:coder::p::pulumi:pulumi:Stack::p-coder","type":"pulumi:pulumi:Stack"}}}}
+{"sequence":2,"timestamp":101,"resourcePreEvent":{"metadata":{"op":"create","urn":"urn:pulumi:coder::p::random:index/randomPet:RandomPet::pet","type":"random:index/randomPet:RandomPet","new":{"urn":"urn:pulumi:coder::p::random:index/randomPet:RandomPet::pet","type":"random:index/randomPet:RandomPet","custom":true}}}}
+{"sequence":3,"timestamp":102,"resourcePreEvent":{"metadata":{"op":"update","urn":"urn:pulumi:coder::p::aws:ec2/instance:Instance::dev","type":"aws:ec2/instance:Instance","new":{"urn":"urn:pulumi:coder::p::aws:ec2/instance:Instance::dev","type":"aws:ec2/instance:Instance","custom":true}}}}
+{"sequence":4,"timestamp":104,"resOutputsEvent":{"metadata":{"op":"create","urn":"urn:pulumi:coder::p::random:index/randomPet:RandomPet::pet","type":"random:index/randomPet:RandomPet","new":{"urn":"urn:pulumi:coder::p::random:index/randomPet:RandomPet::pet","type":"random:index/randomPet:RandomPet","custom":true}}}}
+{"sequence":5,"timestamp":110,"resOpFailedEvent":{"metadata":{"op":"update","urn":"urn:pulumi:coder::p::aws:ec2/instance:Instance::dev","type":"aws:ec2/instance:Instance"},"status":0,"steps":1}}
+{"sequence":6,"timestamp":111,"resOutputsEvent":{"metadata":{"op":"same","urn":"urn:pulumi:coder::p::pulumi:pulumi:Stack::p-coder","type":"pulumi:pulumi:Stack","new":{"urn":"urn:pulumi:coder::p::pulumi:pulumi:Stack::p-coder","type":"pulumi:pulumi:Stack","outputs":{"name":"pet"}}}}}
+{"sequence":7,"timestamp":111,"summaryEvent":{"maybeCorrupt":false}}
+`
+	log, err := parseEventLog(strings.NewReader(events), "apply")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "pet"}, log.Outputs)
+	require.Len(t, log.Resources, 3)
+
+	require.Len(t, log.Timings, 2)
+	pet := log.Timings[0]
+	require.Equal(t, "create", pet.Action)
+	require.Equal(t, "random", pet.Source)
+	require.Equal(t, "random:index/randomPet:RandomPet::pet", pet.Resource)
+	require.Equal(t, "apply", pet.Stage)
+	require.Equal(t, proto.TimingState_COMPLETED, pet.State)
+	require.EqualValues(t, 101, pet.Start.Seconds)
+	require.EqualValues(t, 104, pet.End.Seconds)
+
+	dev := log.Timings[1]
+	require.Equal(t, "update", dev.Action)
+	require.Equal(t, proto.TimingState_FAILED, dev.State)
+	require.EqualValues(t, 110, dev.End.Seconds)
+}
@@ -0,0 +1,95 @@
+package pulumi
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cli/safeexec"
+	semconv "go.opentelemetry.io/otel/semconv/v1.14.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/unhanger"
+	"github.com/coder/coder/v2/provisionersdk"
+)
+
+type ServeOptions struct {
+	*provisionersdk.ServeOptions
+
+	// BinaryPath specifies the "pulumi" binary to use.
+	// If omitted, the $PATH will attempt to find it.
+	BinaryPath string
+	// CachePath is used as PULUMI_HOME, so plugins downloaded by one
+	// build are reused by the next. It must not be used by multiple
+	// processes at once.
+	CachePath string
+	Tracer    trace.Tracer
+
+	// ExitTimeout defines how long we will wait for a running Pulumi
+	// command to exit (cleanly) if the provision was stopped.
+	//
+	// Default value: 3 minutes (unhanger.HungJobExitTimeout).
+	ExitTimeout time.Duration
+}
+
+// Serve starts a dRPC server on the provided transport speaking the Pulumi
+// provisioner. Unlike Terraform, Pulumi isn't installed automatically, since
+// programs also need their language runtime to be present.
+func Serve(ctx context.Context, options *ServeOptions) error {
+	if options.BinaryPath == "" {
+		binaryPath, err := safeexec.LookPath("pulumi")
+		if err != nil {
+			return xerrors.Errorf("Pulumi binary not found: %w", err)
+		}
+		options.BinaryPath, err = filepath.Abs(binaryPath)
+		if err != nil {
+			return xerrors.Errorf("Pulumi binary absolute path not found: %w", err)
+		}
+	}
+	if options.Tracer == nil {
+		options.Tracer = trace.NewNoopTracerProvider().Tracer("noop")
+	}
+	if options.ExitTimeout == 0 {
+		options.ExitTimeout = unhanger.HungJobExitTimeout
+	}
+	return provisionersdk.Serve(ctx, &server{
+		execMut:     &sync.Mutex{},
+		binaryPath:  options.BinaryPath,
+		cachePath:   options.CachePath,
+		logger:      options.Logger,
+		tracer:      options.Tracer,
+		exitTimeout: options.ExitTimeout,
+	}, options.ServeOptions)
+}
+
+type server struct {
+	execMut     *sync.Mutex
+	binaryPath  string
+	cachePath   string
+	logger      slog.Logger
+	tracer      trace.Tracer
+	exitTimeout time.Duration
+}
+
+func (s *server) startTrace(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name, append(opts, trace.WithAttributes(
+		semconv.ServiceNameKey.String("coderd.provisionerd.pulumi"),
+	))...)
+}
+
+func (s *server) executor(workdir string, stage database.ProvisionerJobTimingStage) *executor {
+	return &executor{
+		server:     s,
+		mut:        s.execMut,
+		binaryPath: s.binaryPath,
+		cachePath:  s.cachePath,
+		workdir:    workdir,
+		logger:     s.logger.Named("executor"),
+		stage:      stage,
+	}
+}
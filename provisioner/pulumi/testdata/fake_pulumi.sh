@@ -0,0 +1,112 @@
+#!/usr/bin/env bash
+# A fake pulumi binary that runs a stack with a single container resource and
+# a "coder" output describing one agent.
+
+set -euo pipefail
+
+backend="${PULUMI_BACKEND_URL#file://}"
+state="$backend/state.json"
+printf '%s\n' "$*" >>"$backend/args.txt"
+
+event_log=""
+args=("$@")
+for ((i = 0; i < ${#args[@]}; i++)); do
+	if [[ "${args[$i]}" == "--event-log" ]]; then
+		event_log="${args[$((i + 1))]}"
+	fi
+done
+
+stack_urn="urn:pulumi:coder::test::pulumi:pulumi:Stack::test-coder"
+container_urn="urn:pulumi:coder::test::docker:index/container:Container::dev"
+
+outputs() {
+	cat <<JSON
+{"coder": {"agents": [{"name": "main", "resource": "dev", "os": "linux", "arch": "amd64", "token": $1, "apps": [{"slug": "code-server", "url": "http://localhost:8080", "share": "authenticated"}]}], "parameters": [{"name": "region", "default": "us", "options": [{"name": "US", "value": "us"}, {"name": "EU", "value": "eu"}]}]}}
+JSON
+}
+
+write_events() {
+	local op="$1" token="$2"
+	{
+		echo "{\"sequence\":0,\"timestamp\":1700000000,\"preludeEvent\":{\"config\":{}}}"
+		echo "{\"sequence\":1,\"timestamp\":1700000000,\"resourcePreEvent\":{\"metadata\":{\"op\":\"same\",\"urn\":\"$stack_urn\",\"type\":\"pulumi:pulumi:Stack\",\"new\":{\"urn\":\"$stack_urn\",\"type\":\"pulumi:pulumi:Stack\",\"custom\":false}}}}"
+		echo "{\"sequence\":2,\"timestamp\":1700000001,\"resourcePreEvent\":{\"metadata\":{\"op\":\"$op\",\"urn\":\"$container_urn\",\"type\":\"docker:index/container:Container\",\"new\":{\"urn\":\"$container_urn\",\"type\":\"docker:index/container:Container\",\"custom\":true}}}}"
+		echo "{\"sequence\":3,\"timestamp\":1700000005,\"resOutputsEvent\":{\"metadata\":{\"op\":\"$op\",\"urn\":\"$container_urn\",\"type\":\"docker:index/container:Container\",\"new\":{\"urn\":\"$container_urn\",\"type\":\"docker:index/container:Container\",\"custom\":true}}}}"
+		echo "{\"sequence\":4,\"timestamp\":1700000006,\"resOutputsEvent\":{\"metadata\":{\"op\":\"same\",\"urn\":\"$stack_urn\",\"type\":\"pulumi:pulumi:Stack\",\"new\":{\"urn\":\"$stack_urn\",\"type\":\"pulumi:pulumi:Stack\",\"custom\":false,\"outputs\":$(outputs "$token" | tr -d '\n')}}}}"
+	} >"$event_log"
+}
+
+write_state() {
+	local resources="$1"
+	cat >"$state" <<JSON
+{"version": 3, "deployment": {"manifest": {}, "resources": [$resources]}}
+JSON
+}
+
+case "$1 ${2:-}" in
+"stack select")
+	;;
+"stack import")
+	cp "${args[$((${#args[@]} - 1))]}" "$state"
+	;;
+"config set-all")
+	printf '%s\n' "$@" >"$backend/config.txt"
+	;;
+"config set")
+	printf '%s=%s\n' "${args[$((${#args[@]} - 1))]}" "$(cat)" >>"$backend/secrets.txt"
+	;;
+"stack export")
+	if [[ -f "$state" ]]; then
+		cat "$state"
+	else
+		echo '{"version": 3, "deployment": {"manifest": {}}}'
+	fi
+	;;
+"stack output")
+	if grep -q Container "$state"; then
+		outputs '"secret-token"'
+	else
+		echo '{}'
+	fi
+	;;
+"preview "* | "preview")
+	echo "Previewing update (coder):"
+	if [[ -f "$state" ]]; then
+		echo "Existing state imported"
+	fi
+	if grep -q "coder:workspaceName=dev-workspace" "$backend/config.txt"; then
+		echo "Workspace name set"
+	fi
+	if grep -q "image=s3cret" "$backend/secrets.txt"; then
+		echo "Secret set"
+	fi
+	if grep -q "s3cret" "$backend/args.txt"; then
+		echo "Secret in arguments"
+	fi
+	write_events create '"04da6b54-80e4-46f7-96ec-b56ff0331ba9"'
+	;;
+"up "*)
+	echo "Updating (coder):"
+	write_events create '"[secret]"'
+	write_state "{\"urn\": \"$stack_urn\", \"type\": \"pulumi:pulumi:Stack\", \"custom\": false}, {\"urn\": \"$container_urn\", \"type\": \"docker:index/container:Container\", \"custom\": true}"
+	if grep -q "coder:workspaceName=slow-workspace" "$backend/config.txt"; then
+		# Wait to be canceled after the state was written.
+		sleep 60 &
+		sleep_pid=$!
+		trap 'kill "$sleep_pid"; echo "Update canceled" >&2; exit 1' INT
+		echo "Waiting for cancel"
+		wait "$sleep_pid"
+	fi
+	;;
+"destroy --preview-only")
+	echo "Previewing destroy (coder):"
+	;;
+"destroy --yes")
+	echo "Destroying (coder):"
+	write_state ""
+	;;
+*)
+	echo "unexpected command: $*" >&2
+	exit 1
+	;;
+esac
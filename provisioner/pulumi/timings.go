@@ -0,0 +1,138 @@
+package pulumi
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+const stackType = "pulumi:pulumi:Stack"
+
+// engineEvent is the subset of an event written by `pulumi --event-log`.
+type engineEvent struct {
+	Timestamp        int64      `json:"timestamp"`
+	ResourcePreEvent *stepEvent `json:"resourcePreEvent,omitempty"`
+	ResOutputsEvent  *stepEvent `json:"resOutputsEvent,omitempty"`
+	ResOpFailedEvent *stepEvent `json:"resOpFailedEvent,omitempty"`
+}
+
+type stepEvent struct {
+	Metadata stepEventMetadata `json:"metadata"`
+}
+
+type stepEventMetadata struct {
+	Op   string          `json:"op"`
+	URN  string          `json:"urn"`
+	Type string          `json:"type"`
+	New  *pulumiResource `json:"new,omitempty"`
+}
+
+// eventLog is what we learn from the engine events of an operation.
+type eventLog struct {
+	// Resources is the state of each resource after the operation, in the
+	// order they were first seen.
+	Resources []pulumiResource
+	// Outputs are the outputs of the stack.
+	Outputs map[string]any
+	Timings []*proto.Timing
+}
+
+type timingKey struct {
+	urn string
+	op  string
+}
+
+// parseEventLog reads the engine events of an operation in the given stage.
+// Each step on a resource becomes a timing, from its pre event to its outputs
+// or failure event.
+func parseEventLog(r io.Reader, stage database.ProvisionerJobTimingStage) (*eventLog, error) {
+	log := &eventLog{}
+	resources := map[string]int{}
+	timings := map[timingKey]*proto.Timing{}
+	var order []timingKey
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var event engineEvent
+		err := json.Unmarshal(line, &event)
+		if err != nil {
+			return nil, xerrors.Errorf("decode engine event: %w", err)
+		}
+		ts := timestamppb.New(time.Unix(event.Timestamp, 0))
+
+		var (
+			step  *stepEvent
+			state proto.TimingState
+		)
+		switch {
+		case event.ResourcePreEvent != nil:
+			step, state = event.ResourcePreEvent, proto.TimingState_STARTED
+		case event.ResOutputsEvent != nil:
+			step, state = event.ResOutputsEvent, proto.TimingState_COMPLETED
+		case event.ResOpFailedEvent != nil:
+			step, state = event.ResOpFailedEvent, proto.TimingState_FAILED
+		default:
+			continue
+		}
+		md := step.Metadata
+
+		if md.New != nil {
+			if md.New.Type == stackType && state == proto.TimingState_COMPLETED {
+				log.Outputs = md.New.Outputs
+			}
+			if idx, ok := resources[md.URN]; ok {
+				log.Resources[idx] = *md.New
+			} else {
+				resources[md.URN] = len(log.Resources)
+				log.Resources = append(log.Resources, *md.New)
+			}
+		}
+		if md.Op == "delete" || md.Op == "discard" {
+			if idx, ok := resources[md.URN]; ok {
+				log.Resources[idx].Delete = true
+			}
+		}
+
+		// Unchanged resources and Pulumi's own resources aren't interesting.
+		if md.Op == "same" || isInternalType(md.Type) {
+			continue
+		}
+		key := timingKey{urn: md.URN, op: md.Op}
+		timing, ok := timings[key]
+		if !ok {
+			source, _, _ := strings.Cut(md.Type, ":")
+			timing = &proto.Timing{
+				Start:    ts,
+				Action:   md.Op,
+				Source:   source,
+				Resource: md.Type + "::" + urnName(md.URN),
+				Stage:    string(stage),
+			}
+			timings[key] = timing
+			order = append(order, key)
+		}
+		timing.End = ts
+		timing.State = state
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, xerrors.Errorf("read engine events: %w", err)
+	}
+
+	for _, key := range order {
+		log.Timings = append(log.Timings, timings[key])
+	}
+	return log, nil
+}
@@ -1,4 +1,4 @@
-package terraform
+package provisioner
 
 import (
 	"os"
@@ -9,9 +9,9 @@ import (
 // secrets like the Postgres connection string. See
 // https://github.com/coder/coder/issues/4635.
 //
-// safeEnviron() is provided as an os.Environ() alternative that strips CODER_
-// variables. As an additional precaution, we check a canary variable before
-// provisioner exec.
+// SafeEnviron() is provided as an os.Environ() alternative that strips CODER_
+// variables. As an additional precaution, provisioners check a canary variable
+// with IsCanarySet before they exec.
 //
 // We cannot strip all CODER_ variables at exec because some are used to
 // configure the provisioner.
@@ -23,14 +23,13 @@ func init() {
 }
 
 func envName(env string) string {
-	parts := strings.SplitN(env, "=", 1)
-	if len(parts) > 0 {
-		return parts[0]
-	}
-	return ""
+	name, _, _ := strings.Cut(env, "=")
+	return name
 }
 
-func isCanarySet(env []string) bool {
+// IsCanarySet reports whether env contains the variables of the provisioner
+// process without going through SafeEnviron.
+func IsCanarySet(env []string) bool {
 	for _, e := range env {
 		if envName(e) == unsafeEnvCanary {
 			return true
@@ -39,8 +38,8 @@ func isCanarySet(env []string) bool {
 	return false
 }
 
-// safeEnviron wraps os.Environ but removes CODER_ environment variables.
-func safeEnviron() []string {
+// SafeEnviron wraps os.Environ but removes CODER_ environment variables.
+func SafeEnviron() []string {
 	env := os.Environ()
 	strippedEnv := make([]string, 0, len(env))
 
@@ -0,0 +1,577 @@
+package terraform
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+// Builds run `terraform init` in a fresh work directory, so providers and
+// modules would be downloaded for every build. Two caches in the cache path
+// avoid that:
+//
+//   - The provider cache is Terraform's own plugin cache directory
+//     (TF_PLUGIN_CACHE_DIR), which is only used on Linux.
+//   - The module cache stores the `.terraform/modules` directory that init
+//     produced for a template, keyed by a hash of the template's files.
+//     Module version constraints can match newer versions later on, so
+//     entries expire moduleCacheTTL after they were saved. The dependency
+//     lock file init generates is never cached, so provider versions aren't
+//     pinned beyond what the template itself pins.
+//
+// Both caches are limited in size, and the least recently used entries are
+// evicted first. The module cache can be disabled with
+// ServeOptions.DisableModuleCache.
+
+const (
+	// moduleCacheDirName is the module cache directory in the cache path.
+	// A leading dot can't appear in a provider host name, so it can't clash
+	// with the plugin cache.
+	moduleCacheDirName = ".module-cache"
+	// moduleCacheTempPrefix prefixes entries that are still being written.
+	moduleCacheTempPrefix = ".tmp-"
+	// moduleCacheTTL is how long a module cache entry is used after it was
+	// saved. Later builds run init again, and pick up new module versions.
+	moduleCacheTTL = 24 * time.Hour
+
+	cacheProvider = "provider"
+	cacheModule   = "module"
+)
+
+// CacheMetrics count the lookups in the provider and module caches.
+// A nil *CacheMetrics records nothing.
+type CacheMetrics struct {
+	Requests  *prometheus.CounterVec
+	Evictions *prometheus.CounterVec
+}
+
+func NewCacheMetrics(reg prometheus.Registerer) *CacheMetrics {
+	auto := promauto.With(reg)
+
+	return &CacheMetrics{
+		Requests: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "provisionerd",
+			Name:      "terraform_cache_requests_total",
+			Help:      "The number of providers and modules looked up in the Terraform cache, by result (hit or miss).",
+		}, []string{"cache", "result"}),
+		Evictions: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "provisionerd",
+			Name:      "terraform_cache_evictions_total",
+			Help:      "The number of providers and modules evicted from the Terraform cache.",
+		}, []string{"cache"}),
+	}
+}
+
+func (m *CacheMetrics) request(cache string, hit bool, n int) {
+	if m == nil || n == 0 {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.Requests.WithLabelValues(cache, result).Add(float64(n))
+}
+
+func (m *CacheMetrics) evicted(cache string) {
+	if m == nil {
+		return
+	}
+	m.Evictions.WithLabelValues(cache).Inc()
+}
+
+// pluginCacheEnabled matches basicEnv, which only sets TF_PLUGIN_CACHE_DIR
+// on Linux.
+func (s *server) pluginCacheEnabled() bool {
+	return s.cachePath != "" && runtime.GOOS == "linux"
+}
+
+func (s *server) moduleCachePath() string {
+	return filepath.Join(s.cachePath, moduleCacheDirName)
+}
+
+// initCacheEntry is the module cache entry of the template in a work
+// directory.
+type initCacheEntry struct {
+	// key is empty when the module cache isn't used for the work directory.
+	key string
+	// hit is true when the entry was restored to the work directory.
+	hit bool
+}
+
+// restoreInitCache copies the modules cached for the template in workdir, so
+// `terraform init` doesn't download them again. It returns nil when there's
+// no cache path.
+func (s *server) restoreInitCache(ctx context.Context, workdir string, logr logSink) *initCacheEntry {
+	if s.cachePath == "" {
+		return nil
+	}
+	if s.disableModuleCache {
+		return &initCacheEntry{}
+	}
+	key, err := templateCacheKey(workdir, s.engine)
+	if err != nil {
+		s.logger.Warn(ctx, "unable to compute template cache key", slog.Error(err))
+		return &initCacheEntry{}
+	}
+	entry := &initCacheEntry{key: key}
+
+	entryPath := filepath.Join(s.moduleCachePath(), key)
+	modulesPath := filepath.Join(entryPath, "modules")
+	// The mtime of the entry changes whenever it's used, but its modules
+	// directory keeps the time it was saved.
+	info, err := os.Stat(modulesPath)
+	if err != nil {
+		return entry
+	}
+	if info.ModTime().Add(moduleCacheTTL).Before(time.Now()) {
+		s.logger.Debug(ctx, "cached modules expired", slog.F("key", key), slog.F("saved_at", info.ModTime()))
+		err = os.RemoveAll(entryPath)
+		if err != nil {
+			s.logger.Warn(ctx, "unable to remove expired modules", slog.F("key", key), slog.Error(err))
+		}
+		s.metrics.evicted(cacheModule)
+		return entry
+	}
+
+	dst := filepath.Join(workdir, ".terraform", "modules")
+	err = os.MkdirAll(filepath.Dir(dst), 0o700)
+	if err == nil {
+		err = copyDir(modulesPath, dst)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, "unable to restore cached modules", slog.F("key", key), slog.Error(err))
+		// Start over without the partially restored entry.
+		_ = os.RemoveAll(filepath.Join(workdir, ".terraform"))
+		return entry
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(entryPath, now, now)
+	entry.hit = true
+	logr.ProvisionLog(proto.LogLevel_DEBUG, "Restored modules from the cache")
+	s.logger.Debug(ctx, "restored cached modules", slog.F("key", key))
+	return entry
+}
+
+// updateInitCache runs after a successful `terraform init`. It stores the
+// modules of a template that wasn't cached yet, records which providers were
+// used, and evicts the least recently used entries of both caches.
+func (s *server) updateInitCache(ctx context.Context, workdir string, entry *initCacheEntry, providers *providerInstallRecorder) {
+	if entry == nil {
+		return
+	}
+	if s.pluginCacheEnabled() {
+		s.metrics.request(cacheProvider, true, int(providers.hits.Load()))
+		s.metrics.request(cacheProvider, false, int(providers.misses.Load()))
+
+		used, err := touchUsedPlugins(s.cachePath, workdir)
+		if err != nil {
+			s.logger.Warn(ctx, "unable to mark used plugins", slog.Error(err))
+		}
+		err = evictPlugins(ctx, afero.NewOsFs(), s.cachePath, s.cacheSizeLimit, used, s.metrics, s.logger)
+		if err != nil {
+			s.logger.Warn(ctx, "unable to evict plugins", slog.Error(err))
+		}
+	}
+
+	if entry.key == "" {
+		return
+	}
+	hasModules := hasRemoteModules(workdir)
+	if entry.hit {
+		if hasModules {
+			s.metrics.request(cacheModule, true, 1)
+		}
+	} else if hasModules {
+		s.metrics.request(cacheModule, false, 1)
+		err := s.saveInitCache(workdir, entry)
+		if err != nil {
+			s.logger.Warn(ctx, "unable to cache modules", slog.F("key", entry.key), slog.Error(err))
+		}
+	}
+
+	err := evictModules(ctx, s.moduleCachePath(), s.cacheSizeLimit, entry.key, time.Now(), s.metrics, s.logger)
+	if err != nil {
+		s.logger.Warn(ctx, "unable to evict cached modules", slog.Error(err))
+	}
+}
+
+func (s *server) saveInitCache(workdir string, entry *initCacheEntry) error {
+	cacheDir := s.moduleCachePath()
+	err := os.MkdirAll(cacheDir, 0o700)
+	if err != nil {
+		return xerrors.Errorf("create module cache: %w", err)
+	}
+	// Write to a temporary directory first, so an entry is never seen half
+	// written.
+	tmp, err := os.MkdirTemp(cacheDir, moduleCacheTempPrefix)
+	if err != nil {
+		return xerrors.Errorf("create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	tmpModules := filepath.Join(tmp, "modules")
+	err = copyDir(filepath.Join(workdir, ".terraform", "modules"), tmpModules)
+	if err != nil {
+		return xerrors.Errorf("copy modules: %w", err)
+	}
+	now := time.Now()
+	err = os.Chtimes(tmpModules, now, now)
+	if err != nil {
+		return xerrors.Errorf("set save time: %w", err)
+	}
+
+	entryPath := filepath.Join(cacheDir, entry.key)
+	err = os.Rename(tmp, entryPath)
+	if err != nil {
+		return xerrors.Errorf("rename entry: %w", err)
+	}
+	_ = os.Chtimes(entryPath, now, now)
+	return nil
+}
+
+// templateCacheKey hashes the files of the template in workdir, which are
+// everything in it except for the state and the files Terraform creates.
+func templateCacheKey(workdir string, eng engine) (string, error) {
+	h := sha256.New()
+	_, _ = io.WriteString(h, string(eng.name)+"\x00")
+	skip := map[string]bool{
+		".terraform":        true,
+		"terraform.tfstate": true,
+		"terraform.tfplan":  true,
+	}
+	err := filepath.WalkDir(workdir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(workdir, path)
+		if err != nil {
+			return err
+		}
+		if skip[filepath.ToSlash(rel)] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			_, _ = io.WriteString(h, "L"+filepath.ToSlash(rel)+"\x00"+target+"\x00")
+		case d.IsDir():
+			_, _ = io.WriteString(h, "D"+filepath.ToSlash(rel)+"\x00")
+		case d.Type().IsRegular():
+			_, _ = io.WriteString(h, "F"+filepath.ToSlash(rel)+"\x00")
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(h, f)
+			_ = f.Close()
+			if err != nil {
+				return err
+			}
+			_, _ = io.WriteString(h, "\x00")
+		}
+		return nil
+	})
+	if err != nil {
+		return "", xerrors.Errorf("hash template files: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hasRemoteModules returns true when init downloaded modules. Local modules
+// are only listed in the manifest, and aren't copied into the directory.
+func hasRemoteModules(workdir string) bool {
+	entries, err := os.ReadDir(filepath.Join(workdir, ".terraform", "modules"))
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(entries, func(e os.DirEntry) bool {
+		return e.IsDir()
+	})
+}
+
+// touchUsedPlugins updates the mtime of the cached plugins that the work
+// directory uses, so they're the last to be evicted. It returns their paths
+// in the cache.
+func touchUsedPlugins(cachePath, workdir string) (map[string]bool, error) {
+	providersPath := filepath.Join(workdir, ".terraform", "providers")
+	if _, err := os.Stat(providersPath); err != nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	touched := map[string]bool{}
+	// The plugins in the work directory are symbolic links to the cache, so
+	// this doesn't use findPluginDirs.
+	err := filepath.WalkDir(providersPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(providersPath, path)
+		if err != nil {
+			return err
+		}
+		if len(strings.Split(rel, string(filepath.Separator))) != 5 {
+			return nil
+		}
+
+		cached := filepath.Join(cachePath, rel)
+		err = os.Chtimes(cached, now, now)
+		if err == nil {
+			touched[cached] = true
+		} else if !os.IsNotExist(err) {
+			return xerrors.Errorf("touch plugin %q: %w", cached, err)
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return touched, nil
+}
+
+type cacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// evictLeastRecentlyUsed returns the entries to remove, oldest first, to fit
+// the entries in limit bytes. Entries in keep are never evicted.
+func evictLeastRecentlyUsed(entries []cacheEntry, limit int64, keep func(string) bool) []cacheEntry {
+	if limit <= 0 {
+		return nil
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	var evict []cacheEntry
+	for _, e := range entries {
+		if total <= limit {
+			break
+		}
+		if keep(e.path) {
+			continue
+		}
+		evict = append(evict, e)
+		total -= e.size
+	}
+	return evict
+}
+
+// evictPlugins removes the least recently used plugins when the plugin cache
+// is larger than limit bytes. The plugins in used are kept.
+func evictPlugins(ctx context.Context, afs afero.Fs, cachePath string, limit int64, used map[string]bool, metrics *CacheMetrics, logger slog.Logger) error {
+	if limit <= 0 {
+		return nil
+	}
+	pluginPaths, err := findPluginDirs(ctx, afs, cachePath, logger)
+	if err != nil {
+		return err
+	}
+	entries := make([]cacheEntry, 0, len(pluginPaths))
+	for _, p := range pluginPaths {
+		modTime, err := latestModTime(afs, p)
+		if err != nil {
+			return xerrors.Errorf("unable to evaluate latest mtime for directory %q: %w", p, err)
+		}
+		size, err := dirSize(afs, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, cacheEntry{path: p, size: size, modTime: modTime})
+	}
+
+	for _, e := range evictLeastRecentlyUsed(entries, limit, func(p string) bool { return used[p] }) {
+		logger.Info(ctx, "plugin cache is full, evicting plugin", slog.F("plugin_path", e.path), slog.F("size", e.size), slog.F("mtime", e.modTime))
+		err = removePluginDir(ctx, afs, e.path, logger)
+		if err != nil {
+			return err
+		}
+		metrics.evicted(cacheProvider)
+	}
+	return nil
+}
+
+// evictModules removes module cache entries that are stale, and then the
+// least recently used entries when the module cache is larger than limit
+// bytes. The entry with the key keep is never removed.
+func evictModules(ctx context.Context, cacheDir string, limit int64, keep string, now time.Time, metrics *CacheMetrics, logger slog.Logger) error {
+	dirEntries, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return xerrors.Errorf("read module cache: %w", err)
+	}
+
+	afs := afero.NewOsFs()
+	entries := make([]cacheEntry, 0, len(dirEntries))
+	for _, d := range dirEntries {
+		p := filepath.Join(cacheDir, d.Name())
+		if strings.HasPrefix(d.Name(), moduleCacheTempPrefix) {
+			// Left behind by a provisioner that stopped while saving.
+			_ = os.RemoveAll(p)
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			return xerrors.Errorf("stat %q: %w", p, err)
+		}
+		if d.Name() != keep && info.ModTime().Add(staleTerraformPluginRetention).Before(now) {
+			logger.Info(ctx, "cached modules are stale and will be removed", slog.F("path", p), slog.F("mtime", info.ModTime()))
+			err = os.RemoveAll(p)
+			if err != nil {
+				return xerrors.Errorf("remove %q: %w", p, err)
+			}
+			metrics.evicted(cacheModule)
+			continue
+		}
+		size, err := dirSize(afs, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, cacheEntry{path: p, size: size, modTime: info.ModTime()})
+	}
+
+	keepPath := filepath.Join(cacheDir, keep)
+	for _, e := range evictLeastRecentlyUsed(entries, limit, func(p string) bool { return p == keepPath }) {
+		logger.Info(ctx, "module cache is full, evicting modules", slog.F("path", e.path), slog.F("size", e.size), slog.F("mtime", e.modTime))
+		err = os.RemoveAll(e.path)
+		if err != nil {
+			return xerrors.Errorf("remove %q: %w", e.path, err)
+		}
+		metrics.evicted(cacheModule)
+	}
+	return nil
+}
+
+func dirSize(afs afero.Fs, path string) (int64, error) {
+	var size int64
+	err := afero.Walk(afs, path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, xerrors.Errorf("unable to compute the size of %q: %w", path, err)
+	}
+	return size, nil
+}
+
+// copyDir copies the directory tree at src to dst, keeping file modes and
+// symbolic links.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		default:
+			return copyFile(path, target)
+		}
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+var (
+	// Printed by `terraform init` for each provider, depending on where it
+	// comes from.
+	providerFromCacheRe   = regexp.MustCompile(`^- Using \S+ v\S+ from the shared cache directory`)
+	providerDownloadingRe = regexp.MustCompile(`^- Installing \S+ v\S+\.\.\.`)
+)
+
+// providerInstallRecorder passes logs through to a sink, and counts the
+// providers `terraform init` installs from the plugin cache and from their
+// registries.
+type providerInstallRecorder struct {
+	logSink
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func (r *providerInstallRecorder) ProvisionLog(l proto.LogLevel, o string) {
+	switch {
+	case providerFromCacheRe.MatchString(o):
+		r.hits.Add(1)
+	case providerDownloadingRe.MatchString(o):
+		r.misses.Add(1)
+	}
+	r.logSink.ProvisionLog(l, o)
+}
@@ -0,0 +1,213 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/provisionersdk/proto"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestInitCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	metrics := NewCacheMetrics(prometheus.NewRegistry())
+	srv := &server{
+		engine:    engineTerraform,
+		cachePath: t.TempDir(),
+		metrics:   metrics,
+		logger:    testutil.Logger(t).Leveled(slog.LevelDebug),
+	}
+	writeFile := func(t *testing.T, path, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	newWorkdir := func(t *testing.T, mainTF string) string {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "main.tf"), mainTF)
+		writeFile(t, filepath.Join(dir, "terraform.tfstate"), "state")
+		return dir
+	}
+	// fakeInit writes what `terraform init` would for a template with a
+	// registry module.
+	fakeInit := func(t *testing.T, dir string) {
+		writeFile(t, filepath.Join(dir, ".terraform", "modules", "modules.json"), `{"Modules":[]}`)
+		writeFile(t, filepath.Join(dir, ".terraform", "modules", "vpc", "main.tf"), "# vpc")
+		writeFile(t, filepath.Join(dir, ".terraform.lock.hcl"), "# lock")
+	}
+
+	// The first build misses, and caches what init downloaded.
+	first := newWorkdir(t, `module "vpc" {}`)
+	entry := srv.restoreInitCache(ctx, first, &mockLogger{})
+	require.NotNil(t, entry)
+	require.False(t, entry.hit)
+	fakeInit(t, first)
+	srv.updateInitCache(ctx, first, entry, &providerInstallRecorder{logSink: &mockLogger{}})
+	require.Equal(t, 1.0, promtest.ToFloat64(metrics.Requests.WithLabelValues(cacheModule, "miss")))
+
+	// A build of the same template, with different state, hits.
+	second := newWorkdir(t, `module "vpc" {}`)
+	writeFile(t, filepath.Join(second, "terraform.tfstate"), "other state")
+	logs := &mockLogger{}
+	entry = srv.restoreInitCache(ctx, second, logs)
+	require.True(t, entry.hit)
+	require.Len(t, logs.logs, 1)
+	content, err := os.ReadFile(filepath.Join(second, ".terraform", "modules", "vpc", "main.tf"))
+	require.NoError(t, err)
+	require.Equal(t, "# vpc", string(content))
+	// The generated lock file isn't cached, so init picks the newest
+	// provider versions the template allows.
+	_, err = os.Stat(filepath.Join(second, ".terraform.lock.hcl"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	srv.updateInitCache(ctx, second, entry, &providerInstallRecorder{logSink: &mockLogger{}})
+	require.Equal(t, 1.0, promtest.ToFloat64(metrics.Requests.WithLabelValues(cacheModule, "hit")))
+
+	// A template that ships a lock file is a different template.
+	third := newWorkdir(t, `module "vpc" {}`)
+	writeFile(t, filepath.Join(third, ".terraform.lock.hcl"), "# template lock")
+	entry = srv.restoreInitCache(ctx, third, &mockLogger{})
+	require.False(t, entry.hit)
+	fakeInit(t, third)
+	writeFile(t, filepath.Join(third, ".terraform", "modules", "vpc", "modules", "a", "b", "c", "main.tf"), "# nested")
+	srv.updateInitCache(ctx, third, entry, &providerInstallRecorder{logSink: &mockLogger{}})
+
+	// Cleaning stale plugins leaves the module cache alone, even though its
+	// entries are nested as deep as plugins.
+	err = CleanStaleTerraformPlugins(ctx, srv.cachePath, afero.NewOsFs(), time.Now().Add(2*staleTerraformPluginRetention), srv.logger)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(srv.moduleCachePath(), entry.key))
+	require.NoError(t, err)
+
+	// A changed template misses.
+	changed := newWorkdir(t, `module "vpc" { version = "2.0.0" }`)
+	entry = srv.restoreInitCache(ctx, changed, &mockLogger{})
+	require.False(t, entry.hit)
+	_, err = os.Stat(filepath.Join(changed, ".terraform"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Entries expire a while after they were saved, even when they're used,
+	// so new versions of modules are picked up.
+	entryPath := filepath.Join(srv.moduleCachePath(), entry.key)
+	fakeInit(t, changed)
+	srv.updateInitCache(ctx, changed, entry, &providerInstallRecorder{logSink: &mockLogger{}})
+	savedAt := time.Now().Add(-moduleCacheTTL - time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(entryPath, "modules"), savedAt, savedAt))
+	expired := newWorkdir(t, `module "vpc" { version = "2.0.0" }`)
+	entry = srv.restoreInitCache(ctx, expired, &mockLogger{})
+	require.False(t, entry.hit)
+	_, err = os.Stat(entryPath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Equal(t, 1.0, promtest.ToFloat64(metrics.Evictions.WithLabelValues(cacheModule)))
+	fakeInit(t, expired)
+	srv.updateInitCache(ctx, expired, entry, &providerInstallRecorder{logSink: &mockLogger{}})
+	_, err = os.Stat(entryPath)
+	require.NoError(t, err)
+
+	// Nothing is cached when the module cache is disabled.
+	disabled := &server{
+		engine:             engineTerraform,
+		cachePath:          t.TempDir(),
+		disableModuleCache: true,
+		logger:             srv.logger,
+	}
+	dir := newWorkdir(t, `module "vpc" {}`)
+	entry = disabled.restoreInitCache(ctx, dir, &mockLogger{})
+	require.NotNil(t, entry)
+	require.False(t, entry.hit)
+	fakeInit(t, dir)
+	disabled.updateInitCache(ctx, dir, entry, &providerInstallRecorder{logSink: &mockLogger{}})
+	_, err = os.Stat(disabled.moduleCachePath())
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestEvictModules(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	logger := testutil.Logger(t)
+	metrics := NewCacheMetrics(prometheus.NewRegistry())
+	now := time.Now()
+	dir := t.TempDir()
+	addEntry := func(key string, size int, age time.Duration) {
+		path := filepath.Join(dir, key)
+		require.NoError(t, os.MkdirAll(path, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(path, "file"), make([]byte, size), 0o600))
+		mtime := now.Add(-age)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	addEntry("stale", 10, staleTerraformPluginRetention+time.Hour)
+	addEntry("oldest", 100, 3*time.Hour)
+	addEntry("older", 100, 2*time.Hour)
+	addEntry("current", 100, 4*time.Hour)
+	addEntry("newest", 100, time.Hour)
+	addEntry(moduleCacheTempPrefix+"abc", 10, 0)
+
+	err := evictModules(ctx, dir, 250, "current", now, metrics, logger)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	// "current" is in use, so it's kept even though it's the least recently
+	// used.
+	require.ElementsMatch(t, []string{"current", "newest"}, names)
+	require.Equal(t, 3.0, promtest.ToFloat64(metrics.Evictions.WithLabelValues(cacheModule)))
+}
+
+func TestEvictLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	entries := []cacheEntry{
+		{path: "b", size: 10, modTime: now.Add(-2 * time.Hour)},
+		{path: "a", size: 10, modTime: now.Add(-3 * time.Hour)},
+		{path: "c", size: 10, modTime: now.Add(-time.Hour)},
+	}
+	keepNone := func(string) bool { return false }
+
+	require.Empty(t, evictLeastRecentlyUsed(entries, 0, keepNone), "no limit")
+	require.Empty(t, evictLeastRecentlyUsed(entries, 30, keepNone), "fits")
+
+	evicted := evictLeastRecentlyUsed(entries, 15, keepNone)
+	require.Len(t, evicted, 2)
+	require.Equal(t, "a", evicted[0].path)
+	require.Equal(t, "b", evicted[1].path)
+
+	evicted = evictLeastRecentlyUsed(entries, 15, func(p string) bool { return p == "a" })
+	require.Len(t, evicted, 2)
+	require.Equal(t, "b", evicted[0].path)
+	require.Equal(t, "c", evicted[1].path)
+}
+
+func TestProviderInstallRecorder(t *testing.T) {
+	t.Parallel()
+
+	logs := &mockLogger{}
+	rec := &providerInstallRecorder{logSink: logs}
+	for _, line := range []string{
+		"Initializing provider plugins...",
+		"- Finding coder/coder versions matching \"~> 2.0\"...",
+		"- Using coder/coder v2.4.0 from the shared cache directory",
+		"- Installing kreuzwerker/docker v3.0.2...",
+		"- Installed kreuzwerker/docker v3.0.2 (signed by a HashiCorp partner, key ID BD080C4571C6104C)",
+	} {
+		rec.ProvisionLog(proto.LogLevel_DEBUG, line)
+	}
+	require.Equal(t, int64(1), rec.hits.Load())
+	require.Equal(t, int64(1), rec.misses.Load())
+	require.Len(t, logs.logs, 5)
+}
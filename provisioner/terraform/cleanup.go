@@ -37,7 +37,41 @@ func CleanStaleTerraformPlugins(ctx context.Context, cachePath string, fs afero.
 
 	logger.Info(ctx, "clean stale Terraform plugins", slog.F("cache_path", cachePath))
 
-	// Filter directory trees matching pattern: <repositoryURL>/<company>/<plugin>/<version>/<distribution>
+	pluginPaths, err := findPluginDirs(ctx, fs, cachePath, logger)
+	if err != nil {
+		return err
+	}
+
+	// Identify stale plugins
+	var stalePlugins []string
+	for _, pluginPath := range pluginPaths {
+		modTime, err := latestModTime(fs, pluginPath)
+		if err != nil {
+			return xerrors.Errorf("unable to evaluate latest mtime for directory %q: %w", pluginPath, err)
+		}
+
+		if modTime.Add(staleTerraformPluginRetention).Before(now) {
+			logger.Info(ctx, "plugin directory is stale and will be removed", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
+			stalePlugins = append(stalePlugins, pluginPath)
+		} else {
+			logger.Debug(ctx, "plugin directory is not stale", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
+		}
+	}
+
+	// Remove stale plugins
+	for _, stalePluginPath := range stalePlugins {
+		err = removePluginDir(ctx, fs, stalePluginPath, logger)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findPluginDirs returns the plugin directories in the cache, the directory
+// trees matching <repositoryURL>/<company>/<plugin>/<version>/<distribution>.
+// The module cache is skipped.
+func findPluginDirs(ctx context.Context, fs afero.Fs, cachePath string, logger slog.Logger) ([]string, error) {
 	filterFunc := func(path string, info os.FileInfo) bool {
 		if !info.IsDir() {
 			return false
@@ -53,13 +87,16 @@ func CleanStaleTerraformPlugins(ctx context.Context, cachePath string, fs afero.
 		return len(parts) == 5
 	}
 
-	// Review cached Terraform plugins
 	var pluginPaths []string
-	err = afero.Walk(fs, cachePath, func(path string, info os.FileInfo, err error) error {
+	err := afero.Walk(fs, cachePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		if info.IsDir() && path == filepath.Join(cachePath, moduleCacheDirName) {
+			return filepath.SkipDir
+		}
+
 		if !filterFunc(path, info) {
 			return nil
 		}
@@ -69,58 +106,43 @@ func CleanStaleTerraformPlugins(ctx context.Context, cachePath string, fs afero.
 		return nil
 	})
 	if err != nil {
-		return xerrors.Errorf("unable to walk through cache directory %q: %w", cachePath, err)
+		return nil, xerrors.Errorf("unable to walk through cache directory %q: %w", cachePath, err)
 	}
+	return pluginPaths, nil
+}
 
-	// Identify stale plugins
-	var stalePlugins []string
-	for _, pluginPath := range pluginPaths {
-		modTime, err := latestModTime(fs, pluginPath)
-		if err != nil {
-			return xerrors.Errorf("unable to evaluate latest mtime for directory %q: %w", pluginPath, err)
-		}
+// removePluginDir removes a plugin directory, and then its parent directories
+// that are left empty.
+func removePluginDir(ctx context.Context, fs afero.Fs, pluginPath string, logger slog.Logger) error {
+	err := fs.RemoveAll(pluginPath)
+	if err != nil {
+		return xerrors.Errorf("unable to remove plugin %q: %w", pluginPath, err)
+	}
 
-		if modTime.Add(staleTerraformPluginRetention).Before(now) {
-			logger.Info(ctx, "plugin directory is stale and will be removed", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
-			stalePlugins = append(stalePlugins, pluginPath)
-		} else {
-			logger.Debug(ctx, "plugin directory is not stale", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
+	// Compact the plugin structure by removing empty directories.
+	wd := pluginPath
+	level := 5 // <repositoryURL>/<company>/<plugin>/<version>/<distribution>
+	for {
+		level--
+		if level == 0 {
+			break // do not compact further
 		}
-	}
 
-	// Remove stale plugins
-	for _, stalePluginPath := range stalePlugins {
-		// Remove the plugin directory
-		err = fs.RemoveAll(stalePluginPath)
+		wd = filepath.Dir(wd)
+
+		files, err := afero.ReadDir(fs, wd)
 		if err != nil {
-			return xerrors.Errorf("unable to remove stale plugin %q: %w", stalePluginPath, err)
+			return xerrors.Errorf("unable to read directory content %q: %w", wd, err)
 		}
 
-		// Compact the plugin structure by removing empty directories.
-		wd := stalePluginPath
-		level := 5 // <repositoryURL>/<company>/<plugin>/<version>/<distribution>
-		for {
-			level--
-			if level == 0 {
-				break // do not compact further
-			}
-
-			wd = filepath.Dir(wd)
-
-			files, err := afero.ReadDir(fs, wd)
-			if err != nil {
-				return xerrors.Errorf("unable to read directory content %q: %w", wd, err)
-			}
-
-			if len(files) > 0 {
-				break // there are still other plugins
-			}
-
-			logger.Debug(ctx, "remove empty directory", slog.F("path", wd))
-			err = fs.Remove(wd)
-			if err != nil {
-				return xerrors.Errorf("unable to remove directory %q: %w", wd, err)
-			}
+		if len(files) > 0 {
+			break // there are still other plugins
+		}
+
+		logger.Debug(ctx, "remove empty directory", slog.F("path", wd))
+		err = fs.Remove(wd)
+		if err != nil {
+			return xerrors.Errorf("unable to remove directory %q: %w", wd, err)
 		}
 	}
 	return nil
@@ -0,0 +1,65 @@
+package terraform
+
+import (
+	"runtime"
+
+	"github.com/hashicorp/go-version"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// engine is a binary the provisioner runs. OpenTofu is a fork of Terraform
+// with the same CLI and JSON output, so the engines only differ in how the
+// binary is found and installed, and in which versions are supported.
+type engine struct {
+	name codersdk.TerraformEngine
+	// displayName is used in messages shown to users.
+	displayName string
+	binaryName  string
+	// defaultVersion is installed to the cache when the binary on the
+	// $PATH is missing or too old.
+	defaultVersion *version.Version
+	minVersion     *version.Version
+	maxVersion     *version.Version
+}
+
+var (
+	engineTerraform = engine{
+		name:           codersdk.TerraformEngineTerraform,
+		displayName:    "Terraform",
+		binaryName:     "terraform",
+		defaultVersion: TerraformVersion,
+		minVersion:     minTerraformVersion,
+		maxVersion:     maxTerraformVersion,
+	}
+	engineOpenTofu = engine{
+		name:           codersdk.TerraformEngineOpenTofu,
+		displayName:    "OpenTofu",
+		binaryName:     "tofu",
+		defaultVersion: OpenTofuVersion,
+		minVersion:     minOpenTofuVersion,
+		maxVersion:     maxOpenTofuVersion,
+	}
+)
+
+// engineByName returns the engine with the given name. An empty name is
+// Terraform.
+func engineByName(name codersdk.TerraformEngine) (engine, error) {
+	switch name {
+	case "", codersdk.TerraformEngineTerraform:
+		return engineTerraform, nil
+	case codersdk.TerraformEngineOpenTofu:
+		return engineOpenTofu, nil
+	default:
+		return engine{}, xerrors.Errorf("unknown terraform engine %q", name)
+	}
+}
+
+// executable returns the file name of the binary on this platform.
+func (e engine) executable() string {
+	if runtime.GOOS == "windows" {
+		return e.binaryName + ".exe"
+	}
+	return e.binaryName
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/provisioner"
 	"github.com/coder/coder/v2/provisionersdk/proto"
 )
 
@@ -44,7 +45,7 @@ type executor struct {
 func (e *executor) basicEnv() []string {
 	// Required for "terraform init" to find "git" to
 	// clone Terraform modules.
-	env := safeEnviron()
+	env := provisioner.SafeEnviron()
 	// Only Linux reliably works with the Terraform plugin
 	// cache directory. It's unknown why this is.
 	if e.cachePath != "" && runtime.GOOS == "linux" {
@@ -76,7 +77,7 @@ func (e *executor) execWriteOutput(ctx, killCtx context.Context, args, env []str
 		return ctx.Err()
 	}
 
-	if isCanarySet(env) {
+	if provisioner.IsCanarySet(env) {
 		return xerrors.New("environment variables not sanitized, this is a bug within Coder")
 	}
 
@@ -160,11 +161,12 @@ func (e *executor) checkMinVersion(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	if !v.GreaterThanOrEqual(minTerraformVersion) {
+	if !v.GreaterThanOrEqual(e.server.engine.minVersion) {
 		return xerrors.Errorf(
-			"terraform version %q is too old. required >= %q",
+			"%s version %q is too old. required >= %q",
+			e.server.engine.binaryName,
 			v.String(),
-			minTerraformVersion.String())
+			e.server.engine.minVersion.String())
 	}
 	return nil
 }
@@ -313,6 +315,75 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 	}, nil
 }
 
+// refreshPlan runs a refresh-only plan, which compares the state to the real
+// infrastructure without planning any changes to it.
+func (e *executor) refreshPlan(ctx, killCtx context.Context, env, vars []string, logr logSink) (*proto.PlanComplete, error) {
+	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	planfilePath := getPlanFilePath(e.workdir)
+	args := []string{
+		"plan",
+		"-no-color",
+		"-input=false",
+		"-json",
+		"-refresh-only",
+		"-out=" + planfilePath,
+	}
+	for _, variable := range vars {
+		args = append(args, "-var", variable)
+	}
+
+	outWriter, doneOut := e.provisionLogWriter(logr)
+	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
+	defer func() {
+		_ = outWriter.Close()
+		_ = errWriter.Close()
+		<-doneOut
+		<-doneErr
+	}()
+
+	err := e.execWriteOutput(ctx, killCtx, args, env, outWriter, errWriter)
+	if err != nil {
+		return nil, xerrors.Errorf("terraform plan: %w", err)
+	}
+
+	plan, err := e.showPlan(ctx, killCtx, planfilePath)
+	if err != nil {
+		return nil, xerrors.Errorf("show terraform plan file: %w", err)
+	}
+
+	return &proto.PlanComplete{
+		Timings:       e.timings.aggregate(),
+		ResourceDrift: convertResourceDrift(plan.ResourceDrift),
+	}, nil
+}
+
+// convertResourceDrift converts the managed resources terraform found to
+// have changed outside of it.
+func convertResourceDrift(changes []*tfjson.ResourceChange) []*proto.ResourceDrift {
+	drift := []*proto.ResourceDrift{}
+	for _, change := range changes {
+		if change.Mode != tfjson.ManagedResourceMode || change.Change == nil || change.Change.Actions.NoOp() {
+			continue
+		}
+		action := "update"
+		if change.Change.Actions.Delete() {
+			action = "delete"
+		}
+		drift = append(drift, &proto.ResourceDrift{
+			Address: change.Address,
+			Type:    change.Type,
+			Name:    change.Name,
+			Action:  action,
+		})
+	}
+	return drift
+}
+
 func onlyDataResources(sm tfjson.StateModule) tfjson.StateModule {
 	filtered := sm
 	filtered.Resources = []*tfjson.StateResource{}
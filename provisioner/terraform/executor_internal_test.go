@@ -173,3 +173,27 @@ func TestOnlyDataResources(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertResourceDrift(t *testing.T) {
+	t.Parallel()
+
+	var plan tfjson.Plan
+	err := json.Unmarshal([]byte(`{
+		"format_version": "1.2",
+		"resource_drift": [
+			{"address": "docker_container.workspace[0]", "mode": "managed", "type": "docker_container", "name": "workspace", "change": {"actions": ["delete"]}},
+			{"address": "docker_volume.home", "mode": "managed", "type": "docker_volume", "name": "home", "change": {"actions": ["update"]}},
+			{"address": "data.coder_workspace.me", "mode": "data", "type": "coder_workspace", "name": "me", "change": {"actions": ["update"]}},
+			{"address": "coder_agent.main", "mode": "managed", "type": "coder_agent", "name": "main", "change": {"actions": ["no-op"]}}
+		]
+	}`), &plan)
+	require.NoError(t, err)
+
+	drift := convertResourceDrift(plan.ResourceDrift)
+	require.Equal(t, []*proto.ResourceDrift{
+		{Address: "docker_container.workspace[0]", Type: "docker_container", Name: "workspace", Action: "delete"},
+		{Address: "docker_volume.home", Type: "docker_volume", Name: "home", Action: "update"},
+	}, drift)
+	require.Empty(t, convertResourceDrift(nil))
+	require.NotNil(t, convertResourceDrift(nil))
+}
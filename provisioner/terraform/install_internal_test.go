@@ -0,0 +1,147 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestDownloadOpenTofu(t *testing.T) {
+	t.Parallel()
+
+	v := version.Must(version.NewVersion("1.9.1"))
+	archiveName := fmt.Sprintf("tofu_1.9.1_%s_%s.zip", runtime.GOOS, runtime.GOARCH)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"LICENSE":                   "MPL",
+		engineOpenTofu.executable(): "#!/bin/sh\necho tofu\n",
+	} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	sum := sha256.Sum256(archive.Bytes())
+
+	signingKey, err := openpgp.NewEntity("OpenTofu", "", "core@opentofu.org", nil)
+	require.NoError(t, err)
+	var armoredKey bytes.Buffer
+	aw, err := armor.Encode(&armoredKey, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, signingKey.Serialize(aw))
+	require.NoError(t, aw.Close())
+	sign := func(t *testing.T, key *openpgp.Entity, sums string) []byte {
+		var sig bytes.Buffer
+		require.NoError(t, openpgp.DetachSign(&sig, key, strings.NewReader(sums), nil))
+		return sig.Bytes()
+	}
+
+	// serve serves a release with the given checksums, signed by signer.
+	serve := func(t *testing.T, sums string, signer *openpgp.Entity) openTofuSource {
+		sig := sign(t, signer, sums)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/opentofu.asc":
+				_, _ = w.Write(armoredKey.Bytes())
+			case "/v1.9.1/tofu_1.9.1_SHA256SUMS":
+				_, _ = w.Write([]byte(sums))
+			case "/v1.9.1/tofu_1.9.1_SHA256SUMS.gpgsig":
+				_, _ = w.Write(sig)
+			case "/v1.9.1/" + archiveName:
+				_, _ = w.Write(archive.Bytes())
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return openTofuSource{
+			ReleasesURL:    srv.URL,
+			KeyURL:         srv.URL + "/opentofu.asc",
+			KeyFingerprint: hex.EncodeToString(signingKey.PrimaryKey.Fingerprint),
+		}
+	}
+	validSums := fmt.Sprintf("0000  tofu_1.9.1_other.zip\n%s  %s\n", hex.EncodeToString(sum[:]), archiveName)
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		dir := t.TempDir()
+		src := serve(t, validSums, signingKey)
+
+		binPath, err := downloadOpenTofu(ctx, src, dir, v)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, engineOpenTofu.executable()), binPath)
+		content, err := os.ReadFile(binPath)
+		require.NoError(t, err)
+		require.Equal(t, "#!/bin/sh\necho tofu\n", string(content))
+
+		// The archive isn't left behind.
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		dir := t.TempDir()
+		src := serve(t, fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte("other")), archiveName), signingKey)
+
+		_, err := downloadOpenTofu(ctx, src, dir, v)
+		require.ErrorContains(t, err, "checksum mismatch")
+		_, err = os.Stat(filepath.Join(dir, engineOpenTofu.executable()))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("MissingChecksum", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		src := serve(t, "0000  tofu_1.9.1_other.zip\n", signingKey)
+
+		_, err := downloadOpenTofu(ctx, src, t.TempDir(), v)
+		require.ErrorContains(t, err, "no checksum for "+archiveName)
+	})
+
+	t.Run("UntrustedSignature", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		dir := t.TempDir()
+		otherKey, err := openpgp.NewEntity("Other", "", "other@example.com", nil)
+		require.NoError(t, err)
+		src := serve(t, validSums, otherKey)
+
+		_, err = downloadOpenTofu(ctx, src, dir, v)
+		require.ErrorContains(t, err, "verify signature")
+		_, err = os.Stat(filepath.Join(dir, engineOpenTofu.executable()))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("WrongKeyFingerprint", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		src := serve(t, validSums, signingKey)
+		src.KeyFingerprint = openTofuRelease.KeyFingerprint
+
+		_, err := downloadOpenTofu(ctx, src, t.TempDir(), v)
+		require.ErrorContains(t, err, "doesn't have the fingerprint")
+	})
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/provisioner"
 	"github.com/coder/coder/v2/provisionersdk"
 	"github.com/coder/coder/v2/provisionersdk/proto"
 )
@@ -105,6 +106,8 @@ func (s *server) Plan(
 		return provisionersdk.PlanErrorf("unable to clean stale Terraform plugins: %s", err)
 	}
 
+	cacheEntry := s.restoreInitCache(ctx, sess.WorkDirectory, sess)
+
 	s.logger.Debug(ctx, "running initialization")
 
 	// The JSON output of `terraform init` doesn't include discrete fields for capturing timings of each plugin,
@@ -112,7 +115,8 @@ func (s *server) Plan(
 	initTimings := newTimingAggregator(database.ProvisionerJobTimingStageInit)
 	initTimings.ingest(createInitTimingsEvent(timingInitStart))
 
-	err = e.init(ctx, killCtx, sess)
+	initLogs := &providerInstallRecorder{logSink: sess}
+	err = e.init(ctx, killCtx, initLogs)
 	if err != nil {
 		initTimings.ingest(createInitTimingsEvent(timingInitErrored))
 
@@ -140,6 +144,7 @@ func (s *server) Plan(
 		}
 		return provisionersdk.PlanErrorf("initialize terraform: %s", err)
 	}
+	s.updateInitCache(ctx, sess.WorkDirectory, cacheEntry, initLogs)
 
 	modules, err := getModules(sess.WorkDirectory)
 	if err != nil {
@@ -163,10 +168,15 @@ func (s *server) Plan(
 		return provisionersdk.PlanErrorf("plan vars: %s", err)
 	}
 
-	resp, err := e.plan(
-		ctx, killCtx, env, vars, sess,
-		request.Metadata.GetWorkspaceTransition() == proto.WorkspaceTransition_DESTROY,
-	)
+	var resp *proto.PlanComplete
+	if request.RefreshOnly {
+		resp, err = e.refreshPlan(ctx, killCtx, env, vars, sess)
+	} else {
+		resp, err = e.plan(
+			ctx, killCtx, env, vars, sess,
+			request.Metadata.GetWorkspaceTransition() == proto.WorkspaceTransition_DESTROY,
+		)
+	}
 	if err != nil {
 		return provisionersdk.PlanErrorf("%s", err.Error())
 	}
@@ -238,7 +248,7 @@ func provisionEnv(
 	config *proto.Config, metadata *proto.Metadata,
 	richParams []*proto.RichParameterValue, externalAuth []*proto.ExternalAuthProvider,
 ) ([]string, error) {
-	env := safeEnviron()
+	env := provisioner.SafeEnviron()
 	ownerGroups, err := json.Marshal(metadata.GetWorkspaceOwnerGroups())
 	if err != nil {
 		return nil, xerrors.Errorf("marshal owner groups: %w", err)
@@ -309,12 +319,12 @@ var tfEnvSafeToPrint = map[string]bool{
 }
 
 func logTerraformEnvVars(sink logSink) {
-	env := safeEnviron()
+	env := provisioner.SafeEnviron()
 	for _, e := range env {
 		if strings.HasPrefix(e, "TF_") {
 			parts := strings.SplitN(e, "=", 2)
 			if len(parts) != 2 {
-				panic("safeEnviron() returned vars not in key=value form")
+				panic("SafeEnviron() returned vars not in key=value form")
 			}
 			if !tfEnvSafeToPrint[parts[0]] {
 				parts[1] = "<value redacted>"
@@ -17,18 +17,31 @@ import (
 
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/unhanger"
+	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/provisionersdk"
 )
 
 type ServeOptions struct {
 	*provisionersdk.ServeOptions
 
-	// BinaryPath specifies the "terraform" binary to use.
+	// Engine is the binary to run, Terraform or OpenTofu.
+	// Defaults to Terraform.
+	Engine codersdk.TerraformEngine
+	// BinaryPath specifies the "terraform" or "tofu" binary to use.
 	// If omitted, the $PATH will attempt to find it.
 	BinaryPath string
 	// CachePath must not be used by multiple processes at once.
 	CachePath string
-	Tracer    trace.Tracer
+	// CacheSizeLimit is the size in bytes the provider cache and the module
+	// cache in CachePath may each grow to before the least recently used
+	// entries are evicted. Zero means no limit.
+	CacheSizeLimit int64
+	// DisableModuleCache stops the modules of templates from being cached in
+	// CachePath. The provider cache is still used.
+	DisableModuleCache bool
+	// CacheMetrics counts hits and misses of the caches. Optional.
+	CacheMetrics *CacheMetrics
+	Tracer       trace.Tracer
 
 	// ExitTimeout defines how long we will wait for a running Terraform
 	// command to exit (cleanly) if the provision was stopped. This
@@ -48,10 +61,10 @@ type systemBinaryDetails struct {
 	version      *version.Version
 }
 
-func systemBinary(ctx context.Context) (*systemBinaryDetails, error) {
-	binaryPath, err := safeexec.LookPath("terraform")
+func systemBinary(ctx context.Context, eng engine) (*systemBinaryDetails, error) {
+	binaryPath, err := safeexec.LookPath(eng.binaryName)
 	if err != nil {
-		return nil, xerrors.Errorf("Terraform binary not found: %w", err)
+		return nil, xerrors.Errorf("%s binary not found: %w", eng.displayName, err)
 	}
 
 	// If the "coder" binary is in the same directory as
@@ -61,13 +74,13 @@ func systemBinary(ctx context.Context) (*systemBinaryDetails, error) {
 	// to execute this properly!
 	absoluteBinary, err := filepath.Abs(binaryPath)
 	if err != nil {
-		return nil, xerrors.Errorf("Terraform binary absolute path not found: %w", err)
+		return nil, xerrors.Errorf("%s binary absolute path not found: %w", eng.displayName, err)
 	}
 
 	// Checking the installed version of Terraform.
 	installedVersion, err := versionFromBinaryPath(ctx, absoluteBinary)
 	if err != nil {
-		return nil, xerrors.Errorf("Terraform binary get version failed: %w", err)
+		return nil, xerrors.Errorf("%s binary get version failed: %w", eng.displayName, err)
 	}
 
 	details := &systemBinaryDetails{
@@ -75,7 +88,7 @@ func systemBinary(ctx context.Context) (*systemBinaryDetails, error) {
 		version:      installedVersion,
 	}
 
-	if installedVersion.LessThan(minTerraformVersion) {
+	if installedVersion.LessThan(eng.minVersion) {
 		return details, errTerraformMinorVersionMismatch
 	}
 
@@ -84,8 +97,12 @@ func systemBinary(ctx context.Context) (*systemBinaryDetails, error) {
 
 // Serve starts a dRPC server on the provided transport speaking Terraform provisioner.
 func Serve(ctx context.Context, options *ServeOptions) error {
+	eng, err := engineByName(options.Engine)
+	if err != nil {
+		return err
+	}
 	if options.BinaryPath == "" {
-		binaryDetails, err := systemBinary(ctx)
+		binaryDetails, err := systemBinary(ctx, eng)
 		if err != nil {
 			// This is an early exit to prevent extra execution in case the context is canceled.
 			// It generally happens in unit tests since this method is asynchronous and
@@ -95,14 +112,18 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 			}
 
 			if errors.Is(err, errTerraformMinorVersionMismatch) {
-				options.Logger.Warn(ctx, "installed terraform version too old, will download known good version to cache, or use a previously cached version",
+				options.Logger.Warn(ctx, "installed "+string(eng.name)+" version too old, will download known good version to cache, or use a previously cached version",
 					slog.F("installed_version", binaryDetails.version.String()),
-					slog.F("min_version", minTerraformVersion.String()))
+					slog.F("min_version", eng.minVersion.String()))
 			}
 
-			binPath, err := Install(ctx, options.Logger, options.ExternalProvisioner, options.CachePath, TerraformVersion)
+			install := Install
+			if eng.name == codersdk.TerraformEngineOpenTofu {
+				install = InstallOpenTofu
+			}
+			binPath, err := install(ctx, options.Logger, options.ExternalProvisioner, options.CachePath, eng.defaultVersion)
 			if err != nil {
-				return xerrors.Errorf("install terraform: %w", err)
+				return xerrors.Errorf("install %s: %w", eng.name, err)
 			}
 			options.BinaryPath = binPath
 		} else {
@@ -110,17 +131,17 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 			if options.ExternalProvisioner {
 				logVersion = options.Logger.Info
 			}
-			logVersion(ctx, "detected terraform version",
+			logVersion(ctx, "detected "+string(eng.name)+" version",
 				slog.F("installed_version", binaryDetails.version.String()),
-				slog.F("min_version", minTerraformVersion.String()),
-				slog.F("max_version", maxTerraformVersion.String()))
+				slog.F("min_version", eng.minVersion.String()),
+				slog.F("max_version", eng.maxVersion.String()))
 			// Warn if the installed version is newer than what we've decided is the max.
 			// We used to ignore it and download our own version but this makes it easier
 			// to test out newer versions of Terraform.
-			if binaryDetails.version.GreaterThanOrEqual(maxTerraformVersion) {
-				options.Logger.Warn(ctx, "installed terraform version newer than expected, you may experience bugs",
+			if binaryDetails.version.GreaterThanOrEqual(eng.maxVersion) {
+				options.Logger.Warn(ctx, "installed "+string(eng.name)+" version newer than expected, you may experience bugs",
 					slog.F("installed_version", binaryDetails.version.String()),
-					slog.F("max_version", maxTerraformVersion.String()))
+					slog.F("max_version", eng.maxVersion.String()))
 			}
 			options.BinaryPath = binaryDetails.absolutePath
 		}
@@ -132,22 +153,32 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 		options.ExitTimeout = unhanger.HungJobExitTimeout
 	}
 	return provisionersdk.Serve(ctx, &server{
-		execMut:     &sync.Mutex{},
-		binaryPath:  options.BinaryPath,
-		cachePath:   options.CachePath,
-		logger:      options.Logger,
-		tracer:      options.Tracer,
-		exitTimeout: options.ExitTimeout,
+		execMut:            &sync.Mutex{},
+		engine:             eng,
+		binaryPath:         options.BinaryPath,
+		cachePath:          options.CachePath,
+		cacheSizeLimit:     options.CacheSizeLimit,
+		disableModuleCache: options.DisableModuleCache,
+		metrics:            options.CacheMetrics,
+		logger:             options.Logger,
+		tracer:             options.Tracer,
+		exitTimeout:        options.ExitTimeout,
 	}, options.ServeOptions)
 }
 
 type server struct {
-	execMut     *sync.Mutex
-	binaryPath  string
-	cachePath   string
-	logger      slog.Logger
-	tracer      trace.Tracer
-	exitTimeout time.Duration
+	execMut    *sync.Mutex
+	engine     engine
+	binaryPath string
+	cachePath  string
+	// cacheSizeLimit, disableModuleCache and metrics are used by the provider
+	// and module caches, see cache.go.
+	cacheSizeLimit     int64
+	disableModuleCache bool
+	metrics            *CacheMetrics
+	logger             slog.Logger
+	tracer             trace.Tracer
+	exitTimeout        time.Duration
 }
 
 func (s *server) startTrace(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
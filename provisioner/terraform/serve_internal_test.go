@@ -18,6 +18,7 @@ import (
 func Test_absoluteBinaryPath(t *testing.T) {
 	tests := []struct {
 		name             string
+		engine           engine
 		terraformVersion string
 		expectedErr      error
 	}{
@@ -46,6 +47,20 @@ func Test_absoluteBinaryPath(t *testing.T) {
 			terraformVersion: "version",
 			expectedErr:      xerrors.Errorf("Terraform binary get version failed: Malformed version: version"),
 		},
+		{
+			name:             "TestOpenTofu",
+			engine:           engineOpenTofu,
+			terraformVersion: "1.9.1",
+			expectedErr:      nil,
+		},
+		{
+			// OpenTofu 1.6 is newer than the minimum Terraform version, but
+			// it's the first OpenTofu version.
+			name:             "TestOpenTofuOldVersion",
+			engine:           engineOpenTofu,
+			terraformVersion: "1.5.7",
+			expectedErr:      errTerraformMinorVersionMismatch,
+		},
 	}
 	// nolint:paralleltest
 	for _, tt := range tests {
@@ -54,6 +69,11 @@ func Test_absoluteBinaryPath(t *testing.T) {
 				t.Skip("Dummy terraform executable on Windows requires sh which isn't very practical.")
 			}
 
+			eng := tt.engine
+			if eng.name == "" {
+				eng = engineTerraform
+			}
+
 			// Create a temp dir with the binary
 			tempDir := t.TempDir()
 			terraformBinaryOutput := fmt.Sprintf(`#!/bin/sh
@@ -68,7 +88,7 @@ func Test_absoluteBinaryPath(t *testing.T) {
 
 			// #nosec
 			err := os.WriteFile(
-				filepath.Join(tempDir, "terraform"),
+				filepath.Join(tempDir, eng.binaryName),
 				[]byte(terraformBinaryOutput),
 				0o770,
 			)
@@ -80,11 +100,11 @@ func Test_absoluteBinaryPath(t *testing.T) {
 
 			var expectedAbsoluteBinary string
 			if tt.expectedErr == nil {
-				expectedAbsoluteBinary = filepath.Join(tempDir, "terraform")
+				expectedAbsoluteBinary = filepath.Join(tempDir, eng.binaryName)
 			}
 
 			ctx := testutil.Context(t, testutil.WaitShort)
-			actualBinaryDetails, actualErr := systemBinary(ctx)
+			actualBinaryDetails, actualErr := systemBinary(ctx, eng)
 
 			if tt.expectedErr == nil {
 				require.NoError(t, actualErr)
@@ -12,9 +12,22 @@ import "github.com/coder/coder/v2/apiversion"
 //
 // API v1.4:
 //   - Add new field named `devcontainers` in the Agent.
+//
+// API v1.5:
+//   - Add new fields named `template_source_file_id` and `template_source_hash`
+//     in the AcquiredJob, allowing daemons to fetch template source archives
+//     from a workspace proxy file cache.
+//
+// API v1.6:
+//   - Add `workspace_drift_check` jobs, and the `refresh_only` plan option
+//     and `resource_drift` plan result they rely on.
+//
+// API v1.7:
+//   - Add the `drain` field in the AcquiredJob, asking the daemon to stop
+//     acquiring jobs and exit.
 const (
 	CurrentMajor = 1
-	CurrentMinor = 4
+	CurrentMinor = 7
 )
 
 // CurrentVersion is the current provisionerd API version.
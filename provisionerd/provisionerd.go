@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/yamux"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -51,6 +52,14 @@ type Connector interface {
 	Connect(ctx context.Context, job *proto.AcquiredJob, respCh chan<- ConnectResponse)
 }
 
+// FileFetcher retrieves template source archives that were not inlined in
+// an acquired job.
+type FileFetcher interface {
+	// FetchFile returns the contents of the file with the given ID. The
+	// implementation must verify the contents match the given SHA256 hash.
+	FetchFile(ctx context.Context, fileID uuid.UUID, hash string) ([]byte, error)
+}
+
 // Options provides customizations to the behavior of a provisioner daemon.
 type Options struct {
 	Logger         slog.Logger
@@ -62,7 +71,11 @@ type Options struct {
 	UpdateInterval      time.Duration
 	LogBufferInterval   time.Duration
 	Connector           Connector
-	InitConnectionCh    chan struct{} // only to be used in tests
+	// FileFetcher is used to retrieve template source archives for jobs
+	// that only reference them by file ID and hash. It must be set if the
+	// daemon asked coderd to omit archives from acquired jobs.
+	FileFetcher      FileFetcher
+	InitConnectionCh chan struct{} // only to be used in tests
 }
 
 // New creates and starts a provisioner daemon.
@@ -104,6 +117,7 @@ func New(clientDialer Dialer, opts *Options) *Server {
 		closedCh:            make(chan struct{}),
 		shuttingDownCh:      make(chan struct{}),
 		acquireDoneCh:       make(chan struct{}),
+		drainedCh:           make(chan struct{}),
 		initConnectionCh:    opts.InitConnectionCh,
 		externalProvisioner: opts.ExternalProvisioner,
 	}
@@ -144,7 +158,11 @@ type Server struct {
 	// shuttingDownCh will receive when we start graceful shutdown
 	shuttingDownCh chan struct{}
 	// acquireDoneCh will receive when the acquireLoop exits
-	acquireDoneCh       chan struct{}
+	acquireDoneCh chan struct{}
+	// drainingB is set to true when coderd asks us to drain
+	drainingB bool
+	// drainedCh will receive when we have drained
+	drainedCh           chan struct{}
 	activeJob           *runner.Runner
 	externalProvisioner bool
 }
@@ -326,9 +344,20 @@ func (p *Server) acquireExit() bool {
 		p.opts.Logger.Debug(p.closeContext, "exiting acquire; provisionerd is shutting down")
 		return true
 	}
+	if p.drainingB {
+		p.opts.Logger.Debug(p.closeContext, "exiting acquire; provisionerd is draining")
+		return true
+	}
 	return false
 }
 
+// Drained returns a channel that is closed once coderd has asked the daemon to
+// drain and it has no active job. The daemon acquires no more jobs, so the
+// caller should shut it down.
+func (p *Server) Drained() <-chan struct{} {
+	return p.drainedCh
+}
+
 func (p *Server) acquireAndRunOne(client proto.DRPCProvisionerDaemonClient) error {
 	ctx := p.closeContext
 	p.opts.Logger.Debug(ctx, "start of acquireAndRunOne")
@@ -344,6 +373,16 @@ func (p *Server) acquireAndRunOne(client proto.DRPCProvisionerDaemonClient) erro
 		p.opts.Logger.Warn(ctx, "provisionerd was unable to acquire job", slog.Error(err))
 		return xerrors.Errorf("failed to acquire job: %w", err)
 	}
+	if job.Drain {
+		p.opts.Logger.Info(ctx, "provisionerd was asked to drain, no longer acquiring jobs")
+		p.mutex.Lock()
+		if !p.drainingB {
+			p.drainingB = true
+			close(p.drainedCh)
+		}
+		p.mutex.Unlock()
+		return nil
+	}
 	if job.JobId == "" {
 		p.opts.Logger.Debug(ctx, "acquire job successfully canceled")
 		return nil
@@ -352,6 +391,22 @@ func (p *Server) acquireAndRunOne(client proto.DRPCProvisionerDaemonClient) erro
 	if len(job.TraceMetadata) > 0 {
 		ctx = tracing.MetadataToContext(ctx, job.TraceMetadata)
 	}
+
+	if len(job.TemplateSourceArchive) == 0 && job.TemplateSourceHash != "" {
+		archive, err := p.fetchTemplateSource(ctx, job)
+		if err != nil {
+			p.opts.Logger.Error(ctx, "failed to fetch template source archive", slog.F("job_id", job.JobId), slog.Error(err))
+			failErr := p.FailJob(ctx, &proto.FailedJob{
+				JobId: job.JobId,
+				Error: fmt.Sprintf("failed to fetch template source archive: %s", err),
+			})
+			if failErr != nil {
+				p.opts.Logger.Error(ctx, "failed to report provisioner job failed", slog.F("job_id", job.JobId), slog.Error(failErr))
+			}
+			return xerrors.Errorf("fetch template source archive: %w", err)
+		}
+		job.TemplateSourceArchive = archive
+	}
 	ctx, span := p.tracer.Start(ctx, tracing.FuncName(), trace.WithAttributes(
 		semconv.ServiceNameKey.String("coderd.provisionerd"),
 		attribute.String("job_id", job.JobId),
@@ -460,6 +515,19 @@ func (p *Server) acquireGraceful(client proto.DRPCProvisionerDaemonClient) (*pro
 	return job, err
 }
 
+// fetchTemplateSource retrieves the template source archive for a job that
+// only references it by file ID and hash.
+func (p *Server) fetchTemplateSource(ctx context.Context, job *proto.AcquiredJob) ([]byte, error) {
+	if p.opts.FileFetcher == nil {
+		return nil, xerrors.New("job did not include a template source archive and no file fetcher is configured")
+	}
+	fileID, err := uuid.Parse(job.TemplateSourceFileId)
+	if err != nil {
+		return nil, xerrors.Errorf("parse template source file id: %w", err)
+	}
+	return p.opts.FileFetcher.FetchFile(ctx, fileID, job.TemplateSourceHash)
+}
+
 func retryable(err error) bool {
 	return xerrors.Is(err, yamux.ErrSessionShutdown) || xerrors.Is(err, io.EOF) || xerrors.Is(err, fasthttputil.ErrInmemoryListenerClosed) ||
 		// annoyingly, dRPC sometimes returns context.Canceled if the transport was closed, even if the context for
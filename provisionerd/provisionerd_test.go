@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hashicorp/yamux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -385,6 +386,76 @@ func TestProvisionerd(t *testing.T) {
 		assert.True(t, didComplete.Load(), "should complete the job")
 	})
 
+	t.Run("FetchTemplateSourceArchive", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			didComplete atomic.Bool
+			didRead     atomic.Bool
+			fileID      = uuid.New()
+			fetcher     = &fakeFileFetcher{
+				files: map[uuid.UUID][]byte{
+					fileID: testutil.CreateTar(t, map[string]string{
+						"test.txt": "content",
+					}),
+				},
+			}
+			acq = newAcquireOne(t, &proto.AcquiredJob{
+				JobId:                "test",
+				Provisioner:          "someprovisioner",
+				TemplateSourceFileId: fileID.String(),
+				TemplateSourceHash:   "hash",
+				Type: &proto.AcquiredJob_TemplateDryRun_{
+					TemplateDryRun: &proto.AcquiredJob_TemplateDryRun{
+						Metadata: &sdkproto.Metadata{},
+					},
+				},
+			})
+		)
+
+		closer := provisionerd.New(func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: acq.acquireWithCancel,
+				updateJob:            noopUpdateJob,
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					didComplete.Store(true)
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, &provisionerd.Options{
+			Logger:         slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}).Named("provisionerd").Leveled(slog.LevelDebug),
+			UpdateInterval: 50 * time.Millisecond,
+			Connector: provisionerd.LocalProvisioners{
+				"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+					plan: func(
+						s *provisionersdk.Session,
+						_ *sdkproto.PlanRequest,
+						_ <-chan struct{},
+					) *sdkproto.PlanComplete {
+						data, err := os.ReadFile(filepath.Join(s.WorkDirectory, "test.txt"))
+						if assert.NoError(t, err) {
+							didRead.Store(string(data) == "content")
+						}
+						return &sdkproto.PlanComplete{}
+					},
+				}),
+			},
+			FileFetcher: fetcher,
+		})
+		t.Cleanup(func() {
+			_ = closer.Close()
+		})
+
+		require.Condition(t, closedWithin(acq.complete, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		assert.True(t, didComplete.Load(), "should complete the job")
+		assert.True(t, didRead.Load(), "should extract the fetched archive")
+		assert.Equal(t, []string{fileID.String() + "/hash"}, fetcher.fetched)
+	})
+
 	t.Run("WorkspaceBuild", func(t *testing.T) {
 		t.Parallel()
 		done := make(chan struct{})
@@ -600,6 +671,62 @@ func TestProvisionerd(t *testing.T) {
 
 	// Simulates when there is no coderd to connect to. So the client connection
 	// will never be established.
+	t.Run("Drain", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			acquired    atomic.Int64
+			didComplete atomic.Bool
+		)
+		server := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: func(stream proto.DRPCProvisionerDaemon_AcquireJobWithCancelStream) error {
+					// Drain once the first job has run.
+					if acquired.Add(1) > 1 {
+						return stream.Send(&proto.AcquiredJob{Drain: true})
+					}
+					return stream.Send(&proto.AcquiredJob{
+						JobId:       "test",
+						Provisioner: "someprovisioner",
+						TemplateSourceArchive: testutil.CreateTar(t, map[string]string{
+							"test.txt": "content",
+						}),
+						Type: &proto.AcquiredJob_WorkspaceBuild_{
+							WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+								Metadata: &sdkproto.Metadata{},
+							},
+						},
+					})
+				},
+				updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+					return &proto.UpdateJobResponse{}, nil
+				},
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					didComplete.Store(true)
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.LocalProvisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				plan: func(_ *provisionersdk.Session, _ *sdkproto.PlanRequest, _ <-chan struct{}) *sdkproto.PlanComplete {
+					return &sdkproto.PlanComplete{}
+				},
+				apply: func(_ *provisionersdk.Session, _ *sdkproto.ApplyRequest, _ <-chan struct{}) *sdkproto.ApplyComplete {
+					return &sdkproto.ApplyComplete{}
+				},
+			}),
+		})
+		ctx := testutil.Context(t, testutil.WaitShort)
+		testutil.TryReceive(ctx, t, server.Drained())
+		assert.True(t, didComplete.Load(), "should complete the job before draining")
+		require.NoError(t, server.Shutdown(ctx, false))
+		require.NoError(t, server.Close())
+		assert.EqualValues(t, 2, acquired.Load(), "should not acquire after draining")
+	})
+
 	t.Run("ShutdownNoCoderd", func(t *testing.T) {
 		t.Parallel()
 		done := make(chan struct{})
@@ -1176,6 +1303,23 @@ func createProvisionerClient(t *testing.T, done <-chan struct{}, server provisio
 	return sdkproto.NewDRPCProvisionerClient(clientPipe)
 }
 
+type fakeFileFetcher struct {
+	mu      sync.Mutex
+	files   map[uuid.UUID][]byte
+	fetched []string
+}
+
+func (f *fakeFileFetcher) FetchFile(_ context.Context, fileID uuid.UUID, hash string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetched = append(f.fetched, fileID.String()+"/"+hash)
+	data, ok := f.files[fileID]
+	if !ok {
+		return nil, xerrors.New("file not found")
+	}
+	return data, nil
+}
+
 type provisionerTestServer struct {
 	parse func(s *provisionersdk.Session, r *sdkproto.ParseRequest, canceledOrComplete <-chan struct{}) *sdkproto.ParseComplete
 	plan  func(s *provisionersdk.Session, r *sdkproto.PlanRequest, canceledOrComplete <-chan struct{}) *sdkproto.PlanComplete
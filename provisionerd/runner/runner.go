@@ -442,6 +442,12 @@ func (r *Runner) do(ctx context.Context) (*proto.CompletedJob, *proto.FailedJob)
 			slog.F("variable_values", redactVariableValues(jobType.WorkspaceBuild.VariableValues)),
 		)
 		return r.runWorkspaceBuild(ctx)
+	case *proto.AcquiredJob_WorkspaceDriftCheck_:
+		r.logger.Debug(context.Background(), "acquired job is workspace drift check",
+			slog.F("workspace_name", jobType.WorkspaceDriftCheck.WorkspaceName),
+			slog.F("state_length", len(jobType.WorkspaceDriftCheck.State)),
+		)
+		return r.runWorkspaceDriftCheck(ctx)
 	default:
 		return nil, r.failedJobf("unknown job type %q; ensure your provisioner daemon is up-to-date",
 			reflect.TypeOf(r.job.Type).String())
@@ -826,7 +832,7 @@ func (r *Runner) buildWorkspace(ctx context.Context, stage string, req *sdkproto
 	// will still be available for us to send the cancel to the provisioner
 	err := r.session.Send(req)
 	if err != nil {
-		return nil, r.failedWorkspaceBuildf("start provision: %s", err)
+		return nil, r.failedWorkspaceJobf("start provision: %s", err)
 	}
 	nevermind := make(chan struct{})
 	defer close(nevermind)
@@ -848,14 +854,14 @@ func (r *Runner) buildWorkspace(ctx context.Context, stage string, req *sdkproto
 	for {
 		msg, err := r.session.Recv()
 		if err != nil {
-			return nil, r.failedWorkspaceBuildf("recv workspace provision: %s", err)
+			return nil, r.failedWorkspaceJobf("recv workspace provision: %s", err)
 		}
 		switch msgType := msg.Type.(type) {
 		case *sdkproto.Response_Log:
 			r.logProvisionerJobLog(context.Background(), msgType.Log.Level, "workspace provisioner job logged",
 				slog.F("level", msgType.Log.Level),
 				slog.F("output", msgType.Log.Output),
-				slog.F("workspace_build_id", r.job.GetWorkspaceBuild().WorkspaceBuildId),
+				slog.F("workspace_build_id", r.workspaceBuildID()),
 			)
 
 			r.queueLog(ctx, &proto.Log{
@@ -1064,6 +1070,68 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 	}, nil
 }
 
+// runWorkspaceDriftCheck refreshes the state of a running workspace, and
+// reports the resources that changed outside of Coder. Nothing is applied.
+func (r *Runner) runWorkspaceDriftCheck(ctx context.Context) (*proto.CompletedJob, *proto.FailedJob) {
+	ctx, span := r.startTrace(ctx, tracing.FuncName())
+	defer span.End()
+
+	job := r.job.GetWorkspaceDriftCheck()
+	failedJob := r.configure(&sdkproto.Config{
+		TemplateSourceArchive: r.job.GetTemplateSourceArchive(),
+		State:                 job.State,
+	})
+	if failedJob != nil {
+		return nil, failedJob
+	}
+
+	resp, failed := r.buildWorkspace(ctx, "Checking for drift", &sdkproto.Request{
+		Type: &sdkproto.Request_Plan{
+			Plan: &sdkproto.PlanRequest{
+				Metadata:              job.Metadata,
+				RichParameterValues:   job.RichParameterValues,
+				VariableValues:        job.VariableValues,
+				ExternalAuthProviders: job.ExternalAuthProviders,
+				RefreshOnly:           true,
+			},
+		},
+	})
+	if failed != nil {
+		return nil, failed
+	}
+	planComplete := resp.GetPlan()
+	if planComplete == nil {
+		return nil, r.failedWorkspaceJobf("invalid message type %T received from provisioner", resp.Type)
+	}
+	if planComplete.Error != "" {
+		r.logger.Warn(context.Background(), "drift check failed",
+			slog.F("error", planComplete.Error),
+		)
+
+		return nil, &proto.FailedJob{
+			JobId: r.job.JobId,
+			Error: planComplete.Error,
+			Type: &proto.FailedJob_WorkspaceDriftCheck_{
+				WorkspaceDriftCheck: &proto.FailedJob_WorkspaceDriftCheck{},
+			},
+		}
+	}
+
+	r.logger.Info(context.Background(), "drift check successful",
+		slog.F("drifted_resource_count", len(planComplete.ResourceDrift)),
+	)
+	r.flushQueuedLogs(ctx)
+
+	return &proto.CompletedJob{
+		JobId: r.job.JobId,
+		Type: &proto.CompletedJob_WorkspaceDriftCheck_{
+			WorkspaceDriftCheck: &proto.CompletedJob_WorkspaceDriftCheck{
+				ResourceDrift: planComplete.ResourceDrift,
+			},
+		},
+	}, nil
+}
+
 func resourceNames(rs []*sdkproto.Resource) []string {
 	var sb strings.Builder
 	names := make([]string, 0, len(rs))
@@ -1083,6 +1151,26 @@ func (r *Runner) failedWorkspaceBuildf(format string, args ...interface{}) *prot
 	return failedJob
 }
 
+// failedWorkspaceJobf fails a job that runs against workspace state, which is
+// either a build or a drift check.
+func (r *Runner) failedWorkspaceJobf(format string, args ...interface{}) *proto.FailedJob {
+	if r.job.GetWorkspaceDriftCheck() == nil {
+		return r.failedWorkspaceBuildf(format, args...)
+	}
+	failedJob := r.failedJobf(format, args...)
+	failedJob.Type = &proto.FailedJob_WorkspaceDriftCheck_{}
+	return failedJob
+}
+
+// workspaceBuildID returns the ID of the workspace build the job runs
+// against, if any.
+func (r *Runner) workspaceBuildID() string {
+	if check := r.job.GetWorkspaceDriftCheck(); check != nil {
+		return check.WorkspaceBuildId
+	}
+	return r.job.GetWorkspaceBuild().GetWorkspaceBuildId()
+}
+
 func (r *Runner) failedJobf(format string, args ...interface{}) *proto.FailedJob {
 	message := fmt.Sprintf(format, args...)
 	var code string
@@ -707,6 +707,7 @@ const createTemplateVersionTar = async (
 			modules: [],
 			presets: [],
 			plan: emptyPlan,
+			resourceDrift: [],
 			...response.plan,
 		} as PlanComplete;
 		response.plan.resources = response.plan.resources?.map(fillResource);
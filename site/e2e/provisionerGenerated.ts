@@ -343,6 +343,21 @@ export interface PlanRequest {
   richParameterValues: RichParameterValue[];
   variableValues: VariableValue[];
   externalAuthProviders: ExternalAuthProvider[];
+  /**
+   * refresh_only plans without proposing changes, only reporting where the
+   * real infrastructure has drifted from the state. The plan must not be
+   * applied.
+   */
+  refreshOnly: boolean;
+}
+
+/** ResourceDrift is a resource that changed outside of the provisioner. */
+export interface ResourceDrift {
+  address: string;
+  type: string;
+  name: string;
+  /** action is "update" or "delete". */
+  action: string;
 }
 
 /** PlanComplete indicates a request to plan completed. */
@@ -355,6 +370,7 @@ export interface PlanComplete {
   modules: Module[];
   presets: Preset[];
   plan: Uint8Array;
+  resourceDrift: ResourceDrift[];
 }
 
 /**
@@ -1102,6 +1118,27 @@ export const PlanRequest = {
     for (const v of message.externalAuthProviders) {
       ExternalAuthProvider.encode(v!, writer.uint32(34).fork()).ldelim();
     }
+    if (message.refreshOnly === true) {
+      writer.uint32(40).bool(message.refreshOnly);
+    }
+    return writer;
+  },
+};
+
+export const ResourceDrift = {
+  encode(message: ResourceDrift, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
+    if (message.address !== "") {
+      writer.uint32(10).string(message.address);
+    }
+    if (message.type !== "") {
+      writer.uint32(18).string(message.type);
+    }
+    if (message.name !== "") {
+      writer.uint32(26).string(message.name);
+    }
+    if (message.action !== "") {
+      writer.uint32(34).string(message.action);
+    }
     return writer;
   },
 };
@@ -1132,6 +1169,9 @@ export const PlanComplete = {
     if (message.plan.length !== 0) {
       writer.uint32(74).bytes(message.plan);
     }
+    for (const v of message.resourceDrift) {
+      ResourceDrift.encode(v!, writer.uint32(82).fork()).ldelim();
+    }
     return writer;
   },
 };
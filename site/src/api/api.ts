@@ -2159,6 +2159,7 @@ class ApiMethods {
 	updateWorkspace = async (
 		workspace: TypesGen.Workspace,
 		newBuildParameters: TypesGen.WorkspaceBuildParameter[] = [],
+		priority?: TypesGen.ProvisionerJobPriority,
 	): Promise<TypesGen.WorkspaceBuild> => {
 		const [template, oldBuildParameters] = await Promise.all([
 			this.getTemplate(workspace.template_id),
@@ -2183,6 +2184,7 @@ class ApiMethods {
 			transition: "start",
 			template_version_id: activeVersionId,
 			rich_parameter_values: newBuildParameters,
+			priority,
 		});
 	};
 
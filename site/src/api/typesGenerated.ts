@@ -18,12 +18,30 @@ export interface APIKey {
 	readonly scope: APIKeyScope;
 	readonly token_name: string;
 	readonly lifetime_seconds: number;
+	readonly allow_list: readonly string[];
 }
 
 // From codersdk/apikey.go
-export type APIKeyScope = "all" | "application_connect";
-
-export const APIKeyScopes: APIKeyScope[] = ["all", "application_connect"];
+export type APIKeyScope =
+	| "all"
+	| "application_connect"
+	| "template:push"
+	| "template:read"
+	| "workspace:read"
+	| "workspace:ssh"
+	| "workspace:start"
+	| "workspace:stop";
+
+export const APIKeyScopes: APIKeyScope[] = [
+	"all",
+	"application_connect",
+	"template:push",
+	"template:read",
+	"workspace:read",
+	"workspace:ssh",
+	"workspace:start",
+	"workspace:stop",
+];
 
 // From codersdk/apikey.go
 export interface APIKeyWithOwner extends APIKey {
@@ -115,6 +133,7 @@ export interface AssignableRoles extends Role {
 
 // From codersdk/audit.go
 export type AuditAction =
+	| "blocked"
 	| "close"
 	| "connect"
 	| "create"
@@ -130,6 +149,7 @@ export type AuditAction =
 	| "write";
 
 export const AuditActions: AuditAction[] = [
+	"blocked",
 	"close",
 	"connect",
 	"create",
@@ -425,6 +445,7 @@ export interface CreateTokenRequest {
 	readonly lifetime: number;
 	readonly scope: APIKeyScope;
 	readonly token_name: string;
+	readonly allow_list?: readonly string[];
 }
 
 // From codersdk/users.go
@@ -436,6 +457,17 @@ export interface CreateUserRequestWithOrgs {
 	readonly login_type: LoginType;
 	readonly user_status: UserStatus | null;
 	readonly organization_ids: readonly string[];
+	readonly service_account?: boolean;
+}
+
+// From codersdk/usersecrets.go
+export interface CreateUserSecretRequest {
+	readonly name: string;
+	readonly description?: string;
+	readonly value: string;
+	readonly env_name?: string;
+	readonly file_path?: string;
+	readonly template_ids?: readonly string[];
 }
 
 // From codersdk/workspaces.go
@@ -448,6 +480,7 @@ export interface CreateWorkspaceBuildRequest {
 	readonly rich_parameter_values?: readonly WorkspaceBuildParameter[];
 	readonly log_level?: ProvisionerLogLevel;
 	readonly template_version_preset_id?: string;
+	readonly priority?: ProvisionerJobPriority;
 }
 
 // From codersdk/workspaceproxy.go
@@ -584,6 +617,37 @@ export interface DERPServerConfig {
 	readonly relay_url: string;
 }
 
+// From codersdk/dlp.go
+export type DLPAction =
+	| "clipboard_copy"
+	| "clipboard_paste"
+	| "local_port_forwarding"
+	| "remote_port_forwarding"
+	| "scp"
+	| "sftp"
+	| "x11_forwarding";
+
+export const DLPActions: DLPAction[] = [
+	"clipboard_copy",
+	"clipboard_paste",
+	"local_port_forwarding",
+	"remote_port_forwarding",
+	"scp",
+	"sftp",
+	"x11_forwarding",
+];
+
+// From codersdk/dlp.go
+export interface DLPPolicy {
+	readonly block_sftp: boolean;
+	readonly block_scp: boolean;
+	readonly block_local_port_forwarding: boolean;
+	readonly block_remote_port_forwarding: boolean;
+	readonly block_x11_forwarding: boolean;
+	readonly block_clipboard_copy: boolean;
+	readonly block_clipboard_paste: boolean;
+}
+
 // From codersdk/deployment.go
 export interface DangerousConfig {
 	readonly allow_path_app_sharing: boolean;
@@ -608,6 +672,12 @@ export interface DeleteWebpushSubscription {
 	readonly endpoint: string;
 }
 
+// From codersdk/workspaceagentptyshare.go
+export interface DeleteWorkspaceAgentPTYShareRequest {
+	readonly reconnect_id: string;
+	readonly user_id: string;
+}
+
 // From codersdk/workspaceagentportshare.go
 export interface DeleteWorkspaceAgentPortShareRequest {
 	readonly agent_name: string;
@@ -649,6 +719,8 @@ export interface DeploymentValues {
 	readonly ephemeral_deployment?: boolean;
 	readonly pg_connection_url?: string;
 	readonly pg_auth?: string;
+	readonly pubsub_backend?: string;
+	readonly pubsub_nats_url?: string;
 	readonly oauth2?: OAuth2Config;
 	readonly oidc?: OIDCConfig;
 	readonly telemetry?: TelemetryConfig;
@@ -795,6 +867,14 @@ export interface ExternalAuthAppInstallation {
 	readonly configure_url: string;
 }
 
+// From codersdk/deployment.go
+export type ExternalAuthBrokerType = "http" | "vault";
+
+export const ExternalAuthBrokerTypes: ExternalAuthBrokerType[] = [
+	"http",
+	"vault",
+];
+
 // From codersdk/deployment.go
 export interface ExternalAuthConfig {
 	readonly type: string;
@@ -812,6 +892,9 @@ export interface ExternalAuthConfig {
 	readonly regex: string;
 	readonly display_name: string;
 	readonly display_icon: string;
+	readonly broker_type?: ExternalAuthBrokerType;
+	readonly broker_url?: string;
+	readonly broker_token_field?: string;
 }
 
 // From codersdk/externalauth.go
@@ -978,6 +1061,7 @@ export interface Group {
 	readonly source: GroupSource;
 	readonly organization_name: string;
 	readonly organization_display_name: string;
+	readonly dlp_policy: DLPPolicy;
 }
 
 // From codersdk/groups.go
@@ -1621,6 +1705,7 @@ export interface PatchGroupRequest {
 	readonly display_name: string | null;
 	readonly avatar_url: string | null;
 	readonly quota_allowance: number | null;
+	readonly dlp_policy?: DLPPolicy;
 }
 
 // From codersdk/idpsync.go
@@ -1774,6 +1859,9 @@ export interface PrometheusConfig {
 export interface ProvisionerConfig {
 	readonly daemons: number;
 	readonly daemon_types: string;
+	readonly terraform_engine: string;
+	readonly terraform_cache_size: number;
+	readonly terraform_disable_module_cache: boolean;
 	readonly daemon_poll_interval: number;
 	readonly daemon_poll_jitter: number;
 	readonly force_cancel_interval: number;
@@ -1792,6 +1880,8 @@ export interface ProvisionerDaemon {
 	readonly api_version: string;
 	readonly provisioners: readonly ProvisionerType[];
 	readonly tags: Record<string, string>;
+	readonly terraform_engine?: TerraformEngine;
+	readonly drain_requested_at?: string;
 	readonly key_name: string | null;
 	readonly status: ProvisionerDaemonStatus | null;
 	readonly current_job: ProvisionerDaemonJob | null;
@@ -1846,6 +1936,7 @@ export interface ProvisionerJob {
 	readonly worker_id?: string;
 	readonly file_id: string;
 	readonly tags: Record<string, string>;
+	readonly priority: ProvisionerJobPriority;
 	readonly queue_position: number;
 	readonly queue_size: number;
 	readonly organization_id: string;
@@ -1883,6 +1974,20 @@ export interface ProvisionerJobMetadata {
 	readonly workspace_name?: string;
 }
 
+// From codersdk/provisionerdaemons.go
+export type ProvisionerJobPriority =
+	| "autobuild"
+	| "background"
+	| "bulk"
+	| "interactive";
+
+export const ProvisionerJobPrioritys: ProvisionerJobPriority[] = [
+	"autobuild",
+	"background",
+	"bulk",
+	"interactive",
+];
+
 // From codersdk/provisionerdaemons.go
 export type ProvisionerJobStatus =
 	| "canceled"
@@ -1907,12 +2012,14 @@ export const ProvisionerJobStatuses: ProvisionerJobStatus[] = [
 export type ProvisionerJobType =
 	| "template_version_dry_run"
 	| "template_version_import"
-	| "workspace_build";
+	| "workspace_build"
+	| "workspace_drift_check";
 
 export const ProvisionerJobTypes: ProvisionerJobType[] = [
 	"template_version_dry_run",
 	"template_version_import",
 	"workspace_build",
+	"workspace_drift_check",
 ];
 
 // From codersdk/provisionerdaemons.go
@@ -1973,9 +2080,13 @@ export interface ProvisionerTiming {
 }
 
 // From codersdk/organizations.go
-export type ProvisionerType = "echo" | "terraform";
+export type ProvisionerType = "echo" | "pulumi" | "terraform";
 
-export const ProvisionerTypes: ProvisionerType[] = ["echo", "terraform"];
+export const ProvisionerTypes: ProvisionerType[] = [
+	"echo",
+	"pulumi",
+	"terraform",
+];
 
 // From codersdk/workspaceproxy.go
 export interface ProxyHealthReport {
@@ -1997,6 +2108,11 @@ export const ProxyHealthStatuses: ProxyHealthStatus[] = [
 	"unregistered",
 ];
 
+// From codersdk/deployment.go
+export type PubsubBackend = "nats" | "postgres";
+
+export const PubsubBackends: PubsubBackend[] = ["nats", "postgres"];
+
 // From codersdk/workspaces.go
 export interface PutExtendWorkspaceRequest {
 	readonly deadline: string;
@@ -2136,6 +2252,7 @@ export interface ReducedUser extends MinimalUser {
 	readonly last_seen_at: string;
 	readonly status: UserStatus;
 	readonly login_type: LoginType;
+	readonly is_service_account: boolean;
 	readonly theme_preference?: string;
 }
 
@@ -2395,6 +2512,33 @@ export interface SessionLifetime {
 	readonly max_token_lifetime?: number;
 }
 
+// From codersdk/sessionrecordings.go
+export interface SessionRecording {
+	readonly id: string;
+	readonly workspace_id: string;
+	readonly workspace_name: string;
+	readonly workspace_owner_id: string;
+	readonly workspace_owner_username: string;
+	readonly agent_id: string;
+	readonly type: SessionRecordingType;
+	readonly started_at: string;
+	readonly ended_at?: string;
+	readonly size: number;
+}
+
+// From codersdk/sessionrecordings.go
+export type SessionRecordingType = "reconnecting_pty" | "ssh";
+
+export const SessionRecordingTypes: SessionRecordingType[] = [
+	"reconnecting_pty",
+	"ssh",
+];
+
+// From codersdk/sessionrecordings.go
+export interface SessionRecordingsRequest extends Pagination {
+	readonly workspace_id?: string;
+}
+
 // From codersdk/client.go
 export const SessionTokenCookie = "coder_session_token";
 
@@ -2471,6 +2615,32 @@ export interface TailDERPRegion {
 	readonly Nodes: readonly TailDERPNode[];
 }
 
+// From codersdk/replicas.go
+export interface TailnetCoordinator {
+	readonly id: string;
+	readonly hostname: string;
+	readonly heartbeat_at: string;
+	readonly heartbeat_lag_ms: number;
+	readonly healthy: boolean;
+	readonly peers: number;
+	readonly lost_peers: number;
+	readonly tunnels: number;
+}
+
+// From codersdk/replicas.go
+export interface TailnetCoordinatorReport {
+	readonly coordinators: readonly TailnetCoordinator[];
+	readonly tunnels: readonly TailnetCoordinatorTunnel[];
+}
+
+// From codersdk/replicas.go
+export interface TailnetCoordinatorTunnel {
+	readonly coordinator_id: string;
+	readonly src_id: string;
+	readonly dst_id: string;
+	readonly updated_at: string;
+}
+
 // From codersdk/deployment.go
 export interface TelemetryConfig {
 	readonly enable: boolean;
@@ -2511,6 +2681,10 @@ export interface Template {
 	readonly time_til_dormant_autodelete_ms: number;
 	readonly require_active_version: boolean;
 	readonly max_port_share_level: WorkspaceAgentPortShareLevel;
+	readonly drift_detection_interval_ms: number;
+	readonly session_recording_enabled: boolean;
+	readonly session_recording_retention_ms: number;
+	readonly dlp_policy: DLPPolicy;
 }
 
 // From codersdk/templates.go
@@ -2753,6 +2927,11 @@ export const TerminalFontNames: TerminalFontName[] = [
 	"",
 ];
 
+// From codersdk/organizations.go
+export type TerraformEngine = "opentofu" | "terraform";
+
+export const TerraformEngines: TerraformEngine[] = ["opentofu", "terraform"];
+
 // From codersdk/workspacebuilds.go
 export type TimingStage =
 	| "apply"
@@ -2881,6 +3060,10 @@ export interface UpdateTemplateMeta {
 	readonly deprecation_message?: string;
 	readonly disable_everyone_group_access: boolean;
 	readonly max_port_share_level?: WorkspaceAgentPortShareLevel;
+	readonly drift_detection_interval_ms?: number;
+	readonly session_recording_enabled?: boolean;
+	readonly session_recording_retention_ms?: number;
+	readonly dlp_policy?: DLPPolicy;
 }
 
 // From codersdk/users.go
@@ -2911,6 +3094,15 @@ export interface UpdateUserQuietHoursScheduleRequest {
 	readonly schedule: string;
 }
 
+// From codersdk/usersecrets.go
+export interface UpdateUserSecretRequest {
+	readonly description?: string;
+	readonly value?: string;
+	readonly env_name?: string;
+	readonly file_path?: string;
+	readonly template_ids?: readonly string[];
+}
+
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceAutomaticUpdatesRequest {
 	readonly automatic_updates: AutomaticUpdates;
@@ -2947,6 +3139,13 @@ export interface UploadResponse {
 	readonly hash: string;
 }
 
+// From codersdk/workspaceagentptyshare.go
+export interface UpsertWorkspaceAgentPTYShareRequest {
+	readonly reconnect_id: string;
+	readonly user: string;
+	readonly mode: WorkspaceAgentPTYShareMode;
+}
+
 // From codersdk/workspaceagentportshare.go
 export interface UpsertWorkspaceAgentPortShareRequest {
 	readonly agent_name: string;
@@ -3068,6 +3267,19 @@ export interface UserRoles {
 	readonly organization_roles: Record<string, string[]>;
 }
 
+// From codersdk/usersecrets.go
+export interface UserSecret {
+	readonly id: string;
+	readonly user_id: string;
+	readonly name: string;
+	readonly description: string;
+	readonly env_name: string;
+	readonly file_path: string;
+	readonly template_ids: readonly string[];
+	readonly created_at: string;
+	readonly updated_at: string;
+}
+
 // From codersdk/users.go
 export type UserStatus = "active" | "dormant" | "suspended";
 
@@ -3174,6 +3386,7 @@ export interface Workspace {
 	readonly allow_renames: boolean;
 	readonly favorite: boolean;
 	readonly next_start_at: string | null;
+	readonly drift?: WorkspaceDrift;
 }
 
 // From codersdk/workspaceagents.go
@@ -3337,6 +3550,43 @@ export interface WorkspaceAgentMetadataResult {
 	readonly error: string;
 }
 
+// From codersdk/workspaceagentptyshare.go
+export interface WorkspaceAgentPTYAttachment {
+	readonly username: string;
+	readonly read_only: boolean;
+	readonly attached_at: string;
+}
+
+// From codersdk/workspaceagentptyshare.go
+export interface WorkspaceAgentPTYPresence {
+	readonly attachments: readonly WorkspaceAgentPTYAttachment[];
+}
+
+// From codersdk/workspaceagentptyshare.go
+export interface WorkspaceAgentPTYShare {
+	readonly workspace_id: string;
+	readonly agent_id: string;
+	readonly reconnect_id: string;
+	readonly user_id: string;
+	readonly username: string;
+	readonly mode: WorkspaceAgentPTYShareMode;
+	readonly created_by: string;
+	readonly created_at: string;
+}
+
+// From codersdk/workspaceagentptyshare.go
+export type WorkspaceAgentPTYShareMode = "read_only" | "read_write";
+
+export const WorkspaceAgentPTYShareModes: WorkspaceAgentPTYShareMode[] = [
+	"read_only",
+	"read_write",
+];
+
+// From codersdk/workspaceagentptyshare.go
+export interface WorkspaceAgentPTYShares {
+	readonly shares: readonly WorkspaceAgentPTYShare[];
+}
+
 // From codersdk/workspaceagentportshare.go
 export interface WorkspaceAgentPortShare {
 	readonly workspace_id: string;
@@ -3533,6 +3783,21 @@ export interface WorkspaceDeploymentStats {
 	readonly tx_bytes: number;
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceDrift {
+	readonly checked_at: string;
+	readonly workspace_build_id: string;
+	readonly resources: readonly WorkspaceDriftResource[];
+}
+
+// From codersdk/workspaces.go
+export interface WorkspaceDriftResource {
+	readonly address: string;
+	readonly type: string;
+	readonly name: string;
+	readonly action: string;
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceFilter {
 	readonly q?: string;
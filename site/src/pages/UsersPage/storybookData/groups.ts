@@ -1,4 +1,5 @@
 import type { Group, GroupSource, User } from "api/typesGenerated";
+import { MockDLPPolicy } from "testHelpers/entities";
 import { MockUsers } from "./users";
 
 function findMockedUserById(id: string): User | undefined {
@@ -22,6 +23,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "data-platform",
 		organization_display_name: "Data Platform",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "d6f9d037-b9cd-4716-b424-64ef8f81e7f7",
@@ -38,6 +40,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "82773b01-c3b3-4747-a956-d824293dd857",
@@ -54,6 +57,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "4522d478-04b2-4ced-9cf2-8f2ecbe73188",
@@ -73,6 +77,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "d00ea21b-3eec-4f63-a0cd-acb00e3a2116",
@@ -86,6 +91,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "f7200783-62c0-4bfa-ae30-a542fc82dc8d",
@@ -104,6 +110,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "e690870a-b24f-48fd-b75b-eee89098d588",
@@ -117,6 +124,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "fd6ea877-06b6-4c03-b7c0-ea8f0707425b",
@@ -136,6 +144,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "8b1539c5-6f5c-4630-a715-53b74c444e86",
@@ -149,6 +158,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "a26c335f-1498-48e2-bd66-c6a5783fa5f2",
@@ -162,6 +172,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "900074db-46ce-4165-9938-3f719348fd36",
@@ -175,6 +186,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "85020b78-2a36-4bd4-b00c-7b88dab6e333",
@@ -188,6 +200,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "5f8996c6-b3ba-4e39-97a1-dd44a73951de",
@@ -201,6 +214,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "0c6c1222-b65e-44c1-8bff-078d27fc10e6",
@@ -214,6 +228,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "394222ed-7559-47a8-a57c-1bc7998c0357",
@@ -227,6 +242,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "703f72a1-76f6-4f89-9de6-8a3989693fe5",
@@ -358,6 +374,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "0b912bbd-743e-4415-9036-8217269de663",
@@ -389,6 +406,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "4f7d2a59-3999-4d03-b346-8070a4d601dc",
@@ -402,6 +420,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "125b6423-a3c4-4419-abf9-dfcbbd7e8749",
@@ -415,6 +434,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "cab1a90d-15be-4efe-8ddf-4dc83f3e547e",
@@ -431,6 +451,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "1446698f-4f73-49e9-9dd2-e282a58f58e4",
@@ -447,6 +468,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "coder",
 		organization_display_name: "Coder",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "21dfa187-531e-4a77-acb8-1ac6af314703",
@@ -460,6 +482,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "test-test",
 		organization_display_name: "test-test",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "163a803e-1c99-44ec-927e-5a5ccb37c6aa",
@@ -473,6 +496,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "test-test",
 		organization_display_name: "test-test",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "cbdcf774-9412-4118-8cd9-b3f502c84dfb",
@@ -486,6 +510,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "shared-compute",
 		organization_display_name: "Shared Compute",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "e2815018-6d5a-4751-a18d-e64641dee559",
@@ -499,6 +524,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "docs-team",
 		organization_display_name: "Documentors",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "d79144d9-b30a-448a-9af8-7dac83b2e4ec",
@@ -512,6 +538,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "data-science",
 		organization_display_name: "Data Science",
+		dlp_policy: MockDLPPolicy,
 	},
 	{
 		id: "8efa9208-656a-422d-842d-b9dec0cf1bf3",
@@ -532,6 +559,7 @@ export const MockGroups: Group[] = [
 		source: "user",
 		organization_name: "rabbit",
 		organization_display_name: "rabbit",
+		dlp_policy: MockDLPPolicy,
 	},
 ].map((g) => ({
 	...g,
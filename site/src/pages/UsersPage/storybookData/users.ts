@@ -567,4 +567,5 @@ export const MockUsers: User[] = [
 	avatar_url: "",
 	status: u.status as UserStatus,
 	login_type: u.login_type as LoginType,
+	is_service_account: false,
 }));
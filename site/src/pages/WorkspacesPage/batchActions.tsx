@@ -49,7 +49,8 @@ export function useBatchActions(options: UseBatchActionsProps) {
 			return Promise.all(
 				workspaces
 					.filter((w) => w.outdated && !w.dormant_at)
-					.map((w) => API.updateWorkspace(w)),
+					// Queue behind builds that users are actively waiting on.
+					.map((w) => API.updateWorkspace(w, [], "bulk")),
 			);
 		},
 		onSuccess,
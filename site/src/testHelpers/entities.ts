@@ -496,6 +496,7 @@ export const MockUser: TypesGen.User = {
 	avatar_url: "https://avatars.githubusercontent.com/u/95932066?s=200&v=4",
 	last_seen_at: "",
 	login_type: "password",
+	is_service_account: false,
 	name: "",
 };
 
@@ -516,6 +517,7 @@ export const MockUser2: TypesGen.User = {
 	avatar_url: "",
 	last_seen_at: "2022-09-14T19:12:21Z",
 	login_type: "oidc",
+	is_service_account: false,
 	name: "Mock User The Second",
 };
 
@@ -531,6 +533,7 @@ export const SuspendedMockUser: TypesGen.User = {
 	avatar_url: "",
 	last_seen_at: "",
 	login_type: "password",
+	is_service_account: false,
 	name: "",
 };
 
@@ -676,6 +679,7 @@ export const MockProvisionerJob: TypesGen.ProvisionerJob = {
 		department: "engineering",
 		dreaming: "true",
 	},
+	priority: "interactive",
 	queue_position: 0,
 	queue_size: 0,
 	input: {
@@ -769,6 +773,16 @@ At the beginning of your end step, choose one —
 `,
 	};
 
+export const MockDLPPolicy: TypesGen.DLPPolicy = {
+	block_sftp: false,
+	block_scp: false,
+	block_local_port_forwarding: false,
+	block_remote_port_forwarding: false,
+	block_x11_forwarding: false,
+	block_clipboard_copy: false,
+	block_clipboard_paste: false,
+};
+
 export const MockTemplate: TypesGen.Template = {
 	id: "test-template",
 	created_at: "2022-05-17T17:39:01.382927298Z",
@@ -827,6 +841,10 @@ export const MockTemplate: TypesGen.Template = {
 	deprecated: false,
 	deprecation_message: "",
 	max_port_share_level: "public",
+	drift_detection_interval_ms: 0,
+	session_recording_enabled: false,
+	session_recording_retention_ms: 0,
+	dlp_policy: MockDLPPolicy,
 };
 
 export const MockTemplateVersionFiles: TemplateVersionFiles = {
@@ -2791,6 +2809,7 @@ export const MockGroup: TypesGen.Group = {
 	quota_allowance: 5,
 	source: "user",
 	total_member_count: 2,
+	dlp_policy: MockDLPPolicy,
 };
 
 export const MockGroup2: TypesGen.Group = {
@@ -2805,6 +2824,7 @@ export const MockGroup2: TypesGen.Group = {
 	quota_allowance: 5,
 	source: "user",
 	total_member_count: 2,
+	dlp_policy: MockDLPPolicy,
 };
 
 const MockEveryoneGroup: TypesGen.Group = {
@@ -2821,6 +2841,7 @@ const MockEveryoneGroup: TypesGen.Group = {
 	quota_allowance: 0,
 	source: "user",
 	total_member_count: 0,
+	dlp_policy: MockDLPPolicy,
 };
 
 export const MockTemplateACL: TypesGen.TemplateACL = {
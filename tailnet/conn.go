@@ -123,6 +123,9 @@ type Options struct {
 	// DNSMatchDomain is the DNS suffix to use as a match domain. Only relevant for TUN connections that configure the
 	// OS DNS resolver.
 	DNSMatchDomain string
+	// RecordPathHistory records the path used to reach each peer, and
+	// periodic latency samples. See Conn.PathHistory.
+	RecordPathHistory bool
 }
 
 // TelemetrySink allows tailnet.Conn to send network telemetry to the Coder
@@ -326,6 +329,9 @@ func NewConn(options *Options) (conn *Conn, err error) {
 		watchCtx:        ctx,
 		watchCancel:     ctxCancel,
 	}
+	if options.RecordPathHistory {
+		server.pathHistory = newPathHistory()
+	}
 	defer func() {
 		if err != nil {
 			_ = server.Close()
@@ -353,6 +359,9 @@ func NewConn(options *Options) (conn *Conn, err error) {
 			nodeUp.setNetInfo(ni)
 		})
 	}
+	if server.pathHistory != nil {
+		go server.watchPaths()
+	}
 	server.wireguardEngine.SetStatusCallback(nodeUp.setStatus)
 	server.magicConn.SetDERPForcedWebsocketCallback(nodeUp.setDERPForcedWebsocket)
 
@@ -450,6 +459,8 @@ type Conn struct {
 
 	watchCtx    context.Context
 	watchCancel func()
+	// pathHistory will be nil unless Options.RecordPathHistory is set.
+	pathHistory *pathHistory
 
 	trafficStats *connstats.Statistics
 	lastNetInfo  *tailcfg.NetInfo
@@ -0,0 +1,301 @@
+package tailnet
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"tailscale.com/ipn/ipnstate"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+const (
+	// pathHistorySize is the number of path events kept by a Conn, across all
+	// peers.
+	pathHistorySize = 512
+	// pathCheckInterval is how often the path to each peer is checked.
+	pathCheckInterval = 5 * time.Second
+	// pathLatencyInterval is how often the latency to each active peer is
+	// sampled.
+	pathLatencyInterval = time.Minute
+	// pathPingTimeout is how long to wait for a latency sample.
+	pathPingTimeout = 5 * time.Second
+)
+
+type PathEventType string
+
+const (
+	// PathEventTypePath is recorded when the path to a peer is first seen or
+	// changes.
+	PathEventTypePath PathEventType = "path"
+	// PathEventTypeLatency is recorded when the latency to a peer is sampled.
+	PathEventTypeLatency PathEventType = "latency"
+	// PathEventTypeGone is recorded when a peer is removed.
+	PathEventTypeGone PathEventType = "gone"
+)
+
+type NATType string
+
+const (
+	NATTypeUnknown NATType = "unknown"
+	// NATTypeUDPBlocked means UDP is blocked, so direct connections are
+	// impossible.
+	NATTypeUDPBlocked NATType = "udp_blocked"
+	// NATTypeHard means the public port depends on the destination
+	// (endpoint-dependent mapping), which usually prevents direct
+	// connections unless the peer has an easy NAT.
+	NATTypeHard NATType = "hard"
+	// NATTypeEasy means the public port is the same for every destination.
+	NATTypeEasy NATType = "easy"
+)
+
+// PathEvent records the path used to reach a peer at some point in time.
+type PathEvent struct {
+	Time   time.Time     `json:"time" format:"date-time"`
+	PeerID uuid.UUID     `json:"peer_id" format:"uuid"`
+	Type   PathEventType `json:"type"`
+	// P2P is true if the peer is reached directly, rather than through a
+	// DERP relay.
+	P2P bool `json:"p2p"`
+	// Endpoint is the address of the peer when P2P is true.
+	Endpoint string `json:"endpoint,omitempty"`
+	// DERPRegionID is the DERP region that relays traffic when P2P is false.
+	DERPRegionID   int    `json:"derp_region_id,omitempty"`
+	DERPRegionName string `json:"derp_region_name,omitempty"`
+	// LatencyMilliseconds is only set for PathEventTypeLatency.
+	LatencyMilliseconds float64 `json:"latency_ms,omitempty"`
+	// NATType is the type of NAT we are behind when the event is recorded.
+	NATType NATType `json:"nat_type"`
+}
+
+// natType classifies our NAT from a netcheck report.
+func natType(ni *tailcfg.NetInfo) NATType {
+	switch {
+	case ni == nil:
+		return NATTypeUnknown
+	case !ni.UDP:
+		return NATTypeUDPBlocked
+	}
+	varies, ok := ni.MappingVariesByDestIP.Get()
+	switch {
+	case !ok:
+		return NATTypeUnknown
+	case varies:
+		return NATTypeHard
+	default:
+		return NATTypeEasy
+	}
+}
+
+type peerPath struct {
+	p2p          bool
+	endpoint     string
+	derpRegionID int
+	lastLatency  time.Time
+}
+
+// pathHistory records the path to each peer in a ring buffer.
+type pathHistory struct {
+	mu     sync.Mutex
+	events []PathEvent
+	// next is the index in events to write next, once events is full.
+	next  int
+	peers map[uuid.UUID]*peerPath
+}
+
+func newPathHistory() *pathHistory {
+	return &pathHistory{
+		events: make([]PathEvent, 0, pathHistorySize),
+		peers:  make(map[uuid.UUID]*peerPath),
+	}
+}
+
+func (h *pathHistory) recordLocked(e PathEvent) {
+	if len(h.events) < pathHistorySize {
+		h.events = append(h.events, e)
+		return
+	}
+	h.events[h.next] = e
+	h.next = (h.next + 1) % pathHistorySize
+}
+
+// list returns the recorded events, oldest first.
+func (h *pathHistory) list() []PathEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]PathEvent, 0, len(h.events))
+	out = append(out, h.events[h.next:]...)
+	return append(out, h.events[:h.next]...)
+}
+
+// observePeer records a path event if the path to the peer changed. It
+// returns true if the latency to the peer should be sampled.
+func (h *pathHistory) observePeer(now time.Time, id uuid.UUID, ps *ipnstate.PeerStatus, derpMap *tailcfg.DERPMap, nat NATType) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cur := &peerPath{
+		p2p:      ps.CurAddr != "",
+		endpoint: ps.CurAddr,
+	}
+	if !cur.p2p {
+		cur.derpRegionID = derpRegionIDByCode(derpMap, ps.Relay)
+	}
+	prev, ok := h.peers[id]
+	if ok && prev.p2p == cur.p2p && prev.endpoint == cur.endpoint && prev.derpRegionID == cur.derpRegionID {
+		return ps.Active && now.Sub(prev.lastLatency) >= pathLatencyInterval
+	}
+	h.peers[id] = cur
+	h.recordLocked(PathEvent{
+		Time:           now,
+		PeerID:         id,
+		Type:           PathEventTypePath,
+		P2P:            cur.p2p,
+		Endpoint:       cur.endpoint,
+		DERPRegionID:   cur.derpRegionID,
+		DERPRegionName: derpRegionName(derpMap, cur.derpRegionID),
+		NATType:        nat,
+	})
+	return true
+}
+
+// recordLatency records a latency sample taken by pinging the peer.
+func (h *pathHistory) recordLatency(now time.Time, id uuid.UUID, pr *ipnstate.PingResult, derpMap *tailcfg.DERPMap, nat NATType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if p, ok := h.peers[id]; ok {
+		p.lastLatency = now
+	}
+	e := PathEvent{
+		Time:                now,
+		PeerID:              id,
+		Type:                PathEventTypeLatency,
+		P2P:                 pr.Endpoint != "",
+		Endpoint:            pr.Endpoint,
+		LatencyMilliseconds: pr.LatencySeconds * 1000,
+		NATType:             nat,
+	}
+	if !e.P2P {
+		e.DERPRegionID = pr.DERPRegionID
+		e.DERPRegionName = derpRegionName(derpMap, pr.DERPRegionID)
+	}
+	h.recordLocked(e)
+}
+
+// removeMissing records that peers not in seen are gone.
+func (h *pathHistory) removeMissing(now time.Time, seen map[uuid.UUID]bool, nat NATType) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id := range h.peers {
+		if seen[id] {
+			continue
+		}
+		delete(h.peers, id)
+		h.recordLocked(PathEvent{
+			Time:    now,
+			PeerID:  id,
+			Type:    PathEventTypeGone,
+			NATType: nat,
+		})
+	}
+}
+
+func derpRegionIDByCode(derpMap *tailcfg.DERPMap, code string) int {
+	if derpMap == nil || code == "" {
+		return 0
+	}
+	for id, region := range derpMap.Regions {
+		if region.RegionCode == code {
+			return id
+		}
+	}
+	return 0
+}
+
+func derpRegionName(derpMap *tailcfg.DERPMap, id int) string {
+	if derpMap == nil {
+		return ""
+	}
+	region, ok := derpMap.Regions[id]
+	if !ok {
+		return ""
+	}
+	return region.RegionName
+}
+
+// PathHistory returns the history of the paths used to reach peers, oldest
+// first. It's empty unless Options.RecordPathHistory is set.
+func (c *Conn) PathHistory() []PathEvent {
+	if c.pathHistory == nil {
+		return []PathEvent{}
+	}
+	return c.pathHistory.list()
+}
+
+// watchPaths periodically records the path to each peer, and samples the
+// latency to active peers.
+func (c *Conn) watchPaths() {
+	ticker := time.NewTicker(pathCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.watchCtx.Done():
+			return
+		case <-ticker.C:
+		}
+		c.checkPaths()
+	}
+}
+
+func (c *Conn) checkPaths() {
+	now := time.Now()
+	nat := natType(c.GetNetInfo())
+	derpMap := c.DERPMap()
+	ids := c.configMaps.peerIDsByKey()
+	status := c.Status()
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, k := range status.Peers() {
+		ps := status.Peer[k]
+		id, ok := ids[k]
+		// Only peers we have completed a handshake with have a path.
+		if !ok || ps.LastHandshake.IsZero() {
+			continue
+		}
+		seen[id] = true
+		if !c.pathHistory.observePeer(now, id, ps, derpMap, nat) || len(ps.TailscaleIPs) == 0 {
+			continue
+		}
+		go c.samplePathLatency(id, ps.TailscaleIPs[0], derpMap, nat)
+	}
+	c.pathHistory.removeMissing(now, seen, nat)
+}
+
+func (c *Conn) samplePathLatency(id uuid.UUID, ip netip.Addr, derpMap *tailcfg.DERPMap, nat NATType) {
+	ctx, cancel := context.WithTimeout(c.watchCtx, pathPingTimeout)
+	defer cancel()
+	_, _, pr, err := c.pingWithType(ctx, ip, tailcfg.PingDisco)
+	if err != nil {
+		return
+	}
+	c.pathHistory.recordLatency(time.Now(), id, pr, derpMap, nat)
+}
+
+// peerIDsByKey returns the IDs of the peers we have a node for, by node key.
+func (c *configMaps) peerIDsByKey() map[key.NodePublic]uuid.UUID {
+	c.L.Lock()
+	defer c.L.Unlock()
+	out := make(map[key.NodePublic]uuid.UUID, len(c.peers))
+	for id, lc := range c.peers {
+		if lc.node == nil {
+			continue
+		}
+		out[lc.node.Key] = id
+	}
+	return out
+}
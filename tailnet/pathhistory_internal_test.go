@@ -0,0 +1,107 @@
+package tailnet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/ipn/ipnstate"
+	"tailscale.com/tailcfg"
+)
+
+func TestPathHistory(t *testing.T) {
+	t.Parallel()
+
+	derpMap := &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1: {RegionID: 1, RegionCode: "nyc", RegionName: "New York"},
+			2: {RegionID: 2, RegionCode: "sfo", RegionName: "San Francisco"},
+		},
+	}
+
+	t.Run("PathChanges", func(t *testing.T) {
+		t.Parallel()
+
+		h := newPathHistory()
+		peer := uuid.New()
+		now := time.Now()
+
+		// First sighting is recorded and sampled.
+		sample := h.observePeer(now, peer, &ipnstate.PeerStatus{Relay: "nyc", Active: true}, derpMap, NATTypeHard)
+		require.True(t, sample)
+		// Unchanged path isn't recorded again, and was sampled recently.
+		h.recordLatency(now, peer, &ipnstate.PingResult{DERPRegionID: 1, LatencySeconds: 0.05}, derpMap, NATTypeHard)
+		sample = h.observePeer(now.Add(time.Second), peer, &ipnstate.PeerStatus{Relay: "nyc", Active: true}, derpMap, NATTypeHard)
+		require.False(t, sample)
+		// Unchanged path is sampled again after the interval.
+		sample = h.observePeer(now.Add(pathLatencyInterval), peer, &ipnstate.PeerStatus{Relay: "nyc", Active: true}, derpMap, NATTypeHard)
+		require.True(t, sample)
+		// Idle peers aren't sampled.
+		sample = h.observePeer(now.Add(pathLatencyInterval), peer, &ipnstate.PeerStatus{Relay: "nyc"}, derpMap, NATTypeHard)
+		require.False(t, sample)
+		// Going direct is recorded.
+		sample = h.observePeer(now.Add(2*time.Second), peer, &ipnstate.PeerStatus{Relay: "nyc", CurAddr: "1.2.3.4:5678"}, derpMap, NATTypeEasy)
+		require.True(t, sample)
+		// Gone is recorded once.
+		h.removeMissing(now.Add(3*time.Second), map[uuid.UUID]bool{}, NATTypeEasy)
+		h.removeMissing(now.Add(4*time.Second), map[uuid.UUID]bool{}, NATTypeEasy)
+
+		events := h.list()
+		require.Len(t, events, 4)
+		require.Equal(t, PathEvent{
+			Time:           now,
+			PeerID:         peer,
+			Type:           PathEventTypePath,
+			DERPRegionID:   1,
+			DERPRegionName: "New York",
+			NATType:        NATTypeHard,
+		}, events[0])
+		require.Equal(t, PathEventTypeLatency, events[1].Type)
+		require.Equal(t, 1, events[1].DERPRegionID)
+		require.InDelta(t, 50, events[1].LatencyMilliseconds, 0.001)
+		require.Equal(t, PathEvent{
+			Time:     now.Add(2 * time.Second),
+			PeerID:   peer,
+			Type:     PathEventTypePath,
+			P2P:      true,
+			Endpoint: "1.2.3.4:5678",
+			NATType:  NATTypeEasy,
+		}, events[2])
+		require.Equal(t, PathEventTypeGone, events[3].Type)
+		require.Equal(t, peer, events[3].PeerID)
+	})
+
+	t.Run("RingBuffer", func(t *testing.T) {
+		t.Parallel()
+
+		h := newPathHistory()
+		peer := uuid.New()
+		now := time.Now()
+		for i := 0; i < pathHistorySize+10; i++ {
+			region := "nyc"
+			if i%2 == 1 {
+				region = "sfo"
+			}
+			h.observePeer(now.Add(time.Duration(i)*time.Second), peer, &ipnstate.PeerStatus{Relay: region}, derpMap, NATTypeUnknown)
+		}
+
+		events := h.list()
+		require.Len(t, events, pathHistorySize)
+		require.Equal(t, now.Add(10*time.Second), events[0].Time)
+		require.Equal(t, now.Add(time.Duration(pathHistorySize+9)*time.Second), events[len(events)-1].Time)
+		for i := 1; i < len(events); i++ {
+			require.True(t, events[i].Time.After(events[i-1].Time))
+		}
+	})
+}
+
+func TestNATType(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, NATTypeUnknown, natType(nil))
+	require.Equal(t, NATTypeUDPBlocked, natType(&tailcfg.NetInfo{}))
+	require.Equal(t, NATTypeUnknown, natType(&tailcfg.NetInfo{UDP: true}))
+	require.Equal(t, NATTypeHard, natType(&tailcfg.NetInfo{UDP: true, MappingVariesByDestIP: "true"}))
+	require.Equal(t, NATTypeEasy, natType(&tailcfg.NetInfo{UDP: true, MappingVariesByDestIP: "false"}))
+}
@@ -45,9 +45,20 @@ import (
 //     PushResourcesMonitoringUsage RPCs on the Agent API.
 //   - Added support for reporting connection events for auditing via the
 //     ReportConnection RPC on the Agent API.
+//
+// API v2.5:
+//   - Added secret files of the workspace owner to the Manifest on the Agent
+//     API.
+//   - Added support for uploading SSH and reconnecting PTY session recordings
+//     via the UploadSessionRecording RPC on the Agent API, and whether
+//     recording is enabled to the Manifest.
+//   - Added the DLP policy of the workspace to the Manifest, and support for
+//     auditing actions it blocked via the ReportBlockedAction RPC on the Agent
+//     API.
+//   - No changes to the Tailnet API.
 const (
 	CurrentMajor = 2
-	CurrentMinor = 4
+	CurrentMinor = 5
 )
 
 var CurrentVersion = apiversion.New(CurrentMajor, CurrentMinor)